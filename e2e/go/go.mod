@@ -2,7 +2,7 @@ module send_currency_tx
 
 go 1.18
 
-require github.com/Constellation-Labs/metakit-sdk/packages/go v0.0.0
+require github.com/Constellation-Labs/metakit-sdk/packages/go/v2 v2.0.0
 
 require (
 	github.com/btcsuite/btcd/btcec/v2 v2.3.2 // indirect
@@ -10,4 +10,4 @@ require (
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
 )
 
-replace github.com/Constellation-Labs/metakit-sdk/packages/go => ../../packages/go
+replace github.com/Constellation-Labs/metakit-sdk/packages/go/v2 => ../../packages/go
@@ -8,6 +8,10 @@
 //	go run send_currency_tx.go
 //	go run send_currency_tx.go -config other_config.json
 //	go run send_currency_tx.go -generate-keypair
+//	KEYSTORE_PASSWORD=... go run send_currency_tx.go -generate-keypair -keystore ../wallet.json
+//
+// Instead of private_key, the config may name an encrypted V3 keystore in
+// "keystore", unlocked with the KEYSTORE_PASSWORD environment variable.
 package main
 
 import (
@@ -17,11 +21,16 @@ import (
 	"os"
 	"path/filepath"
 
-	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go"
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/Constellation-Labs/metakit-sdk/packages/go/v2/network"
 )
 
+// keystorePasswordEnv holds the password of the config's keystore
+const keystorePasswordEnv = "KEYSTORE_PASSWORD"
+
 type Config struct {
 	PrivateKey    string  `json:"private_key"`
+	Keystore      string  `json:"keystore"`
 	Destination   string  `json:"destination"`
 	Amount        float64 `json:"amount"`
 	Fee           float64 `json:"fee"`
@@ -38,17 +47,66 @@ func loadConfig(configPath string) (*Config, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	if config.Keystore != "" && !filepath.IsAbs(config.Keystore) {
+		config.Keystore = filepath.Join(filepath.Dir(configPath), config.Keystore)
+	}
 
 	return &config, nil
 }
 
-func generateKeypairCommand() {
+// loadKeystore decrypts the private key from the config's keystore
+func loadKeystore(path string) (string, error) {
+	password := os.Getenv(keystorePasswordEnv)
+	if password == "" {
+		return "", fmt.Errorf("set %s to unlock %s", keystorePasswordEnv, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	keypair, err := constellation.DecryptKeyStore(data, password)
+	if err != nil {
+		return "", err
+	}
+	return keypair.PrivateKey, nil
+}
+
+func generateKeypairCommand(keystorePath string) {
 	keypair, err := constellation.GenerateKeyPair()
 	if err != nil {
 		fmt.Printf("Error generating keypair: %v\n", err)
 		os.Exit(1)
 	}
 
+	if keystorePath != "" {
+		password := os.Getenv(keystorePasswordEnv)
+		if password == "" {
+			fmt.Printf("Error: Set %s to encrypt the keystore\n", keystorePasswordEnv)
+			os.Exit(1)
+		}
+		keystore, err := constellation.EncryptKeyPair(keypair, password)
+		if err != nil {
+			fmt.Printf("Error encrypting keypair: %v\n", err)
+			os.Exit(1)
+		}
+		file, err := os.OpenFile(keystorePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_, err = file.Write(keystore)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			fmt.Printf("Error writing keystore: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Generated new keypair:")
+		fmt.Printf("  DAG Address: %s\n", keypair.Address)
+		fmt.Printf("  Keystore:    %s\n", keystorePath)
+		fmt.Println("\nSet \"keystore\" in your config.json to use it for transactions.")
+		return
+	}
+
 	fmt.Println("Generated new keypair:")
 	fmt.Printf("  Private Key: %s\n", keypair.PrivateKey)
 	fmt.Printf("  Public Key:  %s\n", keypair.PublicKey)
@@ -58,8 +116,16 @@ func generateKeypairCommand() {
 
 func sendTransaction(config *Config) {
 	// Validate config
+	if config.Keystore != "" {
+		privateKey, err := loadKeystore(config.Keystore)
+		if err != nil {
+			fmt.Printf("Error loading keystore: %v\n", err)
+			os.Exit(1)
+		}
+		config.PrivateKey = privateKey
+	}
 	if config.PrivateKey == "" {
-		fmt.Println("Error: Missing required field 'private_key' in config")
+		fmt.Println("Error: Missing required field 'private_key' (or 'keystore') in config")
 		os.Exit(1)
 	}
 	if config.Destination == "" {
@@ -105,7 +171,7 @@ func sendTransaction(config *Config) {
 	fmt.Println()
 
 	// Create client
-	client, err := constellation.NewCurrencyL1Client(constellation.NetworkConfig{L1URL: currencyL1URL})
+	client, err := network.NewCurrencyL1Client(constellation.NetworkConfig{L1URL: currencyL1URL})
 	if err != nil {
 		fmt.Printf("Error creating client: %v\n", err)
 		os.Exit(1)
@@ -184,10 +250,11 @@ func sendTransaction(config *Config) {
 func main() {
 	configFile := flag.String("config", "config.json", "Path to config file")
 	generateKeypair := flag.Bool("generate-keypair", false, "Generate a new keypair and exit")
+	keystorePath := flag.String("keystore", "", "With -generate-keypair, write an encrypted keystore instead of printing the key")
 	flag.Parse()
 
 	if *generateKeypair {
-		generateKeypairCommand()
+		generateKeypairCommand(*keystorePath)
 		return
 	}
 
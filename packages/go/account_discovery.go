@@ -0,0 +1,180 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultGapLimit is the number of consecutive unused addresses after which
+// discovery stops scanning an account, as in BIP44
+const DefaultGapLimit = 20
+
+// ErrDiscoverySourceRequired indicates a DiscoveryConfig without a balance
+// source
+var ErrDiscoverySourceRequired = errors.New("account discovery needs a balance source")
+
+// ReferenceSource looks up the last transaction reference of an address.
+// CurrencyL1Client, PooledCurrencyL1Client and SimulatedLedger implement
+// this interface.
+type ReferenceSource interface {
+	GetLastReference(address string) (*TransactionReference, error)
+}
+
+// DiscoveryConfig holds configuration for DiscoverAccounts
+type DiscoveryConfig struct {
+	// Balances looks up balances, e.g. a CurrencyL0Client
+	Balances BalanceSource
+	// References, if set, looks up last references, e.g. a
+	// CurrencyL1Client. Without it an address that sent everything it
+	// received looks unused.
+	References ReferenceSource
+	// Passphrase is the mnemonic's BIP39 passphrase, if any
+	Passphrase string
+	// GapLimit is how many consecutive unused addresses end an account
+	// (default: DefaultGapLimit)
+	GapLimit int
+	// MaxAccounts bounds how many accounts are scanned (default: until the
+	// first account with no used address)
+	MaxAccounts int
+}
+
+// DiscoveredAddress is a used address found by DiscoverAccounts
+type DiscoveredAddress struct {
+	Index   uint32
+	Address string
+	// Balance in smallest units (1e-8)
+	Balance int64
+	// LastReference is the address's last transaction, the genesis
+	// reference if it never sent one or References is not set
+	LastReference TransactionReference
+}
+
+// DiscoveredAccount is an account with at least one used address
+type DiscoveredAccount struct {
+	Account   uint32
+	Addresses []DiscoveredAddress
+	// NextIndex is the index after the last used address, where new
+	// receive addresses should continue
+	NextIndex uint32
+}
+
+// DiscoverAccounts finds the used addresses of a restored mnemonic. It walks
+// the receive addresses m/44'/1137'/account'/0/index of account 0, 1, ...
+// and counts an address as used when it holds a balance or has sent a
+// transaction. An account ends after GapLimit consecutive unused addresses,
+// and the scan ends at the first account with no used address, so wallets
+// that kept within the gap limit are found completely.
+//
+// Addresses are derived from the account's public key, so no private key is
+// held during the scan. A failed lookup or a cancelled ctx stops the scan;
+// the accounts found so far are returned with the error.
+//
+// Example:
+//
+//	accounts, err := constellation.DiscoverAccounts(ctx, phrase, constellation.DiscoveryConfig{
+//	    Balances:   l0Client,
+//	    References: l1Client,
+//	})
+//	for _, account := range accounts {
+//	    for _, found := range account.Addresses {
+//	        fmt.Println(account.Account, found.Index, found.Address, constellation.FormatTokenAmount(found.Balance))
+//	    }
+//	}
+func DiscoverAccounts(ctx context.Context, mnemonic string, config DiscoveryConfig) ([]DiscoveredAccount, error) {
+	if config.Balances == nil {
+		return nil, ErrDiscoverySourceRequired
+	}
+	if config.GapLimit <= 0 {
+		config.GapLimit = DefaultGapLimit
+	}
+	seed, err := MnemonicToSeed(mnemonic, config.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(seed)
+
+	var accounts []DiscoveredAccount
+	for account := uint32(0); account < hardenedKeyOffset; account++ {
+		if config.MaxAccounts > 0 && int(account) >= config.MaxAccounts {
+			break
+		}
+		found, err := discoverAccount(ctx, seed, account, config)
+		if err != nil {
+			return accounts, fmt.Errorf("discovering account %d: %w", account, err)
+		}
+		if found == nil {
+			break
+		}
+		accounts = append(accounts, *found)
+	}
+	return accounts, nil
+}
+
+// discoverAccount scans one account, returning nil when none of its
+// addresses is used
+func discoverAccount(ctx context.Context, seed []byte, account uint32, config DiscoveryConfig) (*DiscoveredAccount, error) {
+	path := dagBIP44Path(account, 0)
+	accountKey, err := deriveHDPublicKey(seed, path[:accountDepth]...)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := accountKey.child(0)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *DiscoveredAccount
+	for index, gap := uint32(0), 0; gap < config.GapLimit && index < hardenedKeyOffset; index++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		key, err := chain.child(index)
+		if err == errInvalidHDKey {
+			// BIP32 skips indexes without a valid key
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		address, used, err := lookupDiscoveredAddress(key.address(), config)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", index, err)
+		}
+		if !used {
+			gap++
+			continue
+		}
+		gap = 0
+		if found == nil {
+			found = &DiscoveredAccount{Account: account}
+		}
+		address.Index = index
+		found.Addresses = append(found.Addresses, address)
+		found.NextIndex = index + 1
+	}
+	return found, nil
+}
+
+// lookupDiscoveredAddress reports whether address holds a balance or has
+// sent a transaction
+func lookupDiscoveredAddress(address string, config DiscoveryConfig) (DiscoveredAddress, bool, error) {
+	found := DiscoveredAddress{Address: address, LastReference: GenesisReference}
+	balance, err := config.Balances.GetBalance(address)
+	if err != nil {
+		return found, false, err
+	}
+	if balance != nil {
+		found.Balance = balance.Balance
+	}
+	if config.References != nil {
+		ref, err := config.References.GetLastReference(address)
+		if err != nil {
+			return found, false, err
+		}
+		if ref != nil {
+			found.LastReference = *ref
+		}
+	}
+	return found, found.Balance > 0 || found.LastReference.Ordinal > 0, nil
+}
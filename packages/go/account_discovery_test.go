@@ -0,0 +1,112 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ReferenceSource = CurrencyL1API(nil)
+
+// fakeDiscoverySource serves balances and last references from maps and
+// counts lookups
+type fakeDiscoverySource struct {
+	balances map[string]int64
+	refs     map[string]TransactionReference
+	lookups  int
+	failOn   string
+}
+
+func (f *fakeDiscoverySource) GetBalance(address string) (*BalanceResponse, error) {
+	f.lookups++
+	if address == f.failOn {
+		return nil, errors.New("node unavailable")
+	}
+	return &BalanceResponse{Balance: f.balances[address]}, nil
+}
+
+func (f *fakeDiscoverySource) GetLastReference(address string) (*TransactionReference, error) {
+	ref, ok := f.refs[address]
+	if !ok {
+		return &GenesisReference, nil
+	}
+	return &ref, nil
+}
+
+func TestDiscoverAccounts(t *testing.T) {
+	phrase, err := GenerateMnemonic()
+	require.NoError(t, err)
+	address := func(account uint32, index uint32) string {
+		keyPair, err := DeriveKeyPair(phrase, account, index)
+		require.NoError(t, err)
+		return keyPair.Address
+	}
+
+	spent := TransactionReference{Hash: "abc", Ordinal: 4}
+	source := &fakeDiscoverySource{
+		balances: map[string]int64{
+			address(0, 0):  100,
+			address(0, 19): 5,
+			// 20 unused addresses after index 19 hide this one
+			address(0, 40): 7,
+			address(1, 2):  1,
+			// account 3 is never reached: account 2 is unused
+			address(3, 0): 9,
+		},
+		refs: map[string]TransactionReference{address(0, 3): spent},
+	}
+
+	t.Run("finds used addresses within the gap limit", func(t *testing.T) {
+		accounts, err := DiscoverAccounts(context.Background(), phrase, DiscoveryConfig{Balances: source, References: source})
+		require.NoError(t, err)
+		require.Len(t, accounts, 2)
+
+		assert.Equal(t, uint32(0), accounts[0].Account)
+		assert.Equal(t, []DiscoveredAddress{
+			{Index: 0, Address: address(0, 0), Balance: 100, LastReference: GenesisReference},
+			{Index: 3, Address: address(0, 3), LastReference: spent},
+			{Index: 19, Address: address(0, 19), Balance: 5, LastReference: GenesisReference},
+		}, accounts[0].Addresses)
+		assert.Equal(t, uint32(20), accounts[0].NextIndex)
+
+		assert.Equal(t, uint32(1), accounts[1].Account)
+		require.Len(t, accounts[1].Addresses, 1)
+		assert.Equal(t, uint32(3), accounts[1].NextIndex)
+	})
+
+	t.Run("a wider gap limit finds more", func(t *testing.T) {
+		accounts, err := DiscoverAccounts(context.Background(), phrase, DiscoveryConfig{Balances: source, GapLimit: 25, MaxAccounts: 1})
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		assert.Equal(t, uint32(41), accounts[0].NextIndex)
+		// without references the spent address looks unused
+		for _, found := range accounts[0].Addresses {
+			assert.NotEqual(t, uint32(3), found.Index)
+		}
+	})
+
+	t.Run("stops on lookup errors", func(t *testing.T) {
+		failing := &fakeDiscoverySource{balances: source.balances, failOn: address(1, 1)}
+		accounts, err := DiscoverAccounts(context.Background(), phrase, DiscoveryConfig{Balances: failing})
+		assert.ErrorContains(t, err, "account 1: index 1: node unavailable")
+		require.Len(t, accounts, 1)
+		assert.Equal(t, uint32(0), accounts[0].Account)
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		counting := &fakeDiscoverySource{}
+		_, err := DiscoverAccounts(ctx, phrase, DiscoveryConfig{Balances: counting})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, counting.lookups)
+	})
+
+	t.Run("needs a balance source", func(t *testing.T) {
+		_, err := DiscoverAccounts(context.Background(), phrase, DiscoveryConfig{})
+		assert.ErrorIs(t, err, ErrDiscoverySourceRequired)
+	})
+}
@@ -0,0 +1,41 @@
+package constellation
+
+// Base58Alphabet is the character set of the DAG address body: digits and
+// letters without 0, O, I and l
+const Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// DAGAddressLength is the length of a DAG address: "DAG", a parity digit
+// and 36 base58 characters
+const DAGAddressLength = 40
+
+// base58Chars marks the bytes that appear in Base58Alphabet
+var base58Chars = func() (table [256]bool) {
+	for i := 0; i < len(Base58Alphabet); i++ {
+		table[Base58Alphabet[i]] = true
+	}
+	return table
+}()
+
+// IsBase58Char reports whether c is in Base58Alphabet
+func IsBase58Char(c byte) bool {
+	return base58Chars[c]
+}
+
+// IsValidDAGAddress validates a DAG address format
+func IsValidDAGAddress(address string) bool {
+	// DAG addresses: DAG + parity digit (0-8) + 36 base58 chars = 40 chars total
+	if len(address) != DAGAddressLength || address[:3] != "DAG" {
+		return false
+	}
+	// Position 3 (after DAG) must be parity digit 0-8
+	if address[3] < '0' || address[3] > '8' {
+		return false
+	}
+	// Remaining 36 characters must be base58 (no 0, O, I, l)
+	for i := 4; i < len(address); i++ {
+		if !base58Chars[address[i]] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,290 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrInvalidShardConfig indicates an AddressShardsConfig without a member
+// name for this instance
+var ErrInvalidShardConfig = errors.New("invalid shard config")
+
+// AddressShardsConfig holds configuration for AddressShards
+type AddressShardsConfig struct {
+	// Self names this scanner instance, e.g. its pod name
+	Self string
+	// Members names every scanner instance, including Self (default: Self
+	// alone)
+	Members []string
+	// Addresses are the watched addresses to split among the members
+	Addresses []string
+	// OnRebalance, if set, is called with the addresses this instance
+	// gained and lost whenever they change, one rebalance at a time and in
+	// the order they happened. It must not change the shards itself.
+	OnRebalance func(rebalance ShardRebalance)
+}
+
+// ShardRebalance describes how this instance's share of the watched
+// addresses changed
+type ShardRebalance struct {
+	// Members is the new membership, sorted
+	Members []string
+	// Added are the addresses this instance now scans, sorted
+	Added []string
+	// Removed are the addresses this instance stopped scanning, sorted
+	Removed []string
+}
+
+// AddressShards splits watched addresses among scanner instances by
+// consistent hashing, so each address is scanned by exactly one instance
+// and deposit scanning scales out across nodes. When an instance joins or
+// leaves, only the addresses it owned or takes over move. Every instance
+// must be given the same members and addresses to agree on the split. It is
+// safe for concurrent use.
+//
+// Example:
+//
+//	shards, err := NewAddressShards(AddressShardsConfig{
+//	    Self:      os.Getenv("POD_NAME"),
+//	    Members:   scannerPods,
+//	    Addresses: depositAddresses,
+//	    OnRebalance: func(r ShardRebalance) {
+//	        scanner.Watch(r.Added...)
+//	        scanner.Unwatch(r.Removed...)
+//	    },
+//	})
+//	scanner.Watch(shards.Owned()...)
+//	// on a membership change from service discovery
+//	shards.SetMembers(scannerPods)
+type AddressShards struct {
+	self        string
+	onRebalance func(rebalance ShardRebalance)
+
+	// rebalanceMu serializes changes from computing a rebalance through
+	// notifying it, so OnRebalance sees rebalances in order
+	rebalanceMu sync.Mutex
+
+	mu        sync.Mutex
+	members   []string
+	ring      *hashRing
+	addresses map[string]bool
+	owned     map[string]bool
+}
+
+// NewAddressShards creates the shard map for config. Returns
+// ErrInvalidAddress for an invalid watched address.
+func NewAddressShards(config AddressShardsConfig) (*AddressShards, error) {
+	if config.Self == "" {
+		return nil, fmt.Errorf("%w: no member name for this instance", ErrInvalidShardConfig)
+	}
+	members := config.Members
+	if len(members) == 0 {
+		members = []string{config.Self}
+	}
+	for _, address := range config.Addresses {
+		if !IsValidDAGAddress(address) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, RedactAddress(address))
+		}
+	}
+
+	s := &AddressShards{
+		self:        config.Self,
+		onRebalance: config.OnRebalance,
+		addresses:   map[string]bool{},
+		owned:       map[string]bool{},
+	}
+	s.members, s.ring = shardRing(members)
+	for _, address := range config.Addresses {
+		s.addresses[address] = true
+		if s.ownerLocked(address) == s.self {
+			s.owned[address] = true
+		}
+	}
+	return s, nil
+}
+
+// shardRing sorts and deduplicates members and builds their ring
+func shardRing(members []string) ([]string, *hashRing) {
+	unique := make([]string, 0, len(members))
+	seen := map[string]bool{}
+	for _, member := range members {
+		if member != "" && !seen[member] {
+			seen[member] = true
+			unique = append(unique, member)
+		}
+	}
+	sort.Strings(unique)
+	return unique, newHashRing(unique)
+}
+
+// Members returns the current membership, sorted
+func (s *AddressShards) Members() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.members...)
+}
+
+// Owner returns the member that scans address, watched or not; empty when
+// there are no members
+func (s *AddressShards) Owner(address string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ownerLocked(address)
+}
+
+func (s *AddressShards) ownerLocked(address string) string {
+	owners := s.ring.lookup(address)
+	if len(owners) == 0 {
+		return ""
+	}
+	return owners[0]
+}
+
+// Owns reports whether this instance scans address
+func (s *AddressShards) Owns(address string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.owned[address]
+}
+
+// Owned returns the watched addresses this instance scans, sorted
+func (s *AddressShards) Owned() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.owned)
+}
+
+// SetMembers replaces the membership and rebalances. An instance left out
+// of members scans nothing until it is added back.
+func (s *AddressShards) SetMembers(members []string) ShardRebalance {
+	s.rebalanceMu.Lock()
+	defer s.rebalanceMu.Unlock()
+
+	s.mu.Lock()
+	s.members, s.ring = shardRing(members)
+	owned := map[string]bool{}
+	for address := range s.addresses {
+		if s.ownerLocked(address) == s.self {
+			owned[address] = true
+		}
+	}
+	rebalance := s.replaceOwnedLocked(owned)
+	s.mu.Unlock()
+
+	s.notify(rebalance)
+	return rebalance
+}
+
+// Watch adds addresses to the watched set and returns those this instance
+// newly scans. Returns ErrInvalidAddress, adding none, if any address is
+// invalid.
+func (s *AddressShards) Watch(addresses ...string) ([]string, error) {
+	for _, address := range addresses {
+		if !IsValidDAGAddress(address) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, RedactAddress(address))
+		}
+	}
+
+	s.rebalanceMu.Lock()
+	defer s.rebalanceMu.Unlock()
+
+	s.mu.Lock()
+	owned := copyBoolSet(s.owned)
+	for _, address := range addresses {
+		s.addresses[address] = true
+		if s.ownerLocked(address) == s.self {
+			owned[address] = true
+		}
+	}
+	rebalance := s.replaceOwnedLocked(owned)
+	s.mu.Unlock()
+
+	s.notify(rebalance)
+	return rebalance.Added, nil
+}
+
+// Unwatch removes addresses from the watched set
+func (s *AddressShards) Unwatch(addresses ...string) {
+	s.rebalanceMu.Lock()
+	defer s.rebalanceMu.Unlock()
+
+	s.mu.Lock()
+	owned := copyBoolSet(s.owned)
+	for _, address := range addresses {
+		delete(s.addresses, address)
+		delete(owned, address)
+	}
+	rebalance := s.replaceOwnedLocked(owned)
+	s.mu.Unlock()
+
+	s.notify(rebalance)
+}
+
+// Filter returns an EventHandler passing on only the address events of
+// addresses this instance scans: DepositDetected and DepositFinalized by
+// destination, BalanceChanged by address. Other events always pass. Use it
+// when every instance scans everything but only the owner should act.
+func (s *AddressShards) Filter(next EventHandler) EventHandler {
+	return func(event Event) {
+		switch e := event.(type) {
+		case DepositDetected:
+			if !s.Owns(e.Destination) {
+				return
+			}
+		case DepositFinalized:
+			if !s.Owns(e.Destination) {
+				return
+			}
+		case BalanceChanged:
+			if !s.Owns(e.Address) {
+				return
+			}
+		}
+		next(event)
+	}
+}
+
+// replaceOwnedLocked installs owned and returns the difference from the
+// previous set; callers hold s.mu
+func (s *AddressShards) replaceOwnedLocked(owned map[string]bool) ShardRebalance {
+	rebalance := ShardRebalance{Members: append([]string(nil), s.members...)}
+	for address := range owned {
+		if !s.owned[address] {
+			rebalance.Added = append(rebalance.Added, address)
+		}
+	}
+	for address := range s.owned {
+		if !owned[address] {
+			rebalance.Removed = append(rebalance.Removed, address)
+		}
+	}
+	sort.Strings(rebalance.Added)
+	sort.Strings(rebalance.Removed)
+	s.owned = owned
+	return rebalance
+}
+
+func (s *AddressShards) notify(rebalance ShardRebalance) {
+	if s.onRebalance != nil && (len(rebalance.Added) > 0 || len(rebalance.Removed) > 0) {
+		s.onRebalance(rebalance)
+	}
+}
+
+func copyBoolSet(set map[string]bool) map[string]bool {
+	copied := make(map[string]bool, len(set))
+	for key := range set {
+		copied[key] = true
+	}
+	return copied
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
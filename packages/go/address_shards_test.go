@@ -0,0 +1,136 @@
+package constellation
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressShards(t *testing.T) {
+	addresses := make([]string, 60)
+	for i := range addresses {
+		keyPair, err := GenerateKeyPair()
+		require.NoError(t, err)
+		addresses[i] = keyPair.Address
+	}
+	members := []string{"scanner-a", "scanner-b", "scanner-c"}
+
+	shards := map[string]*AddressShards{}
+	rebalances := map[string][]ShardRebalance{}
+	for _, member := range members {
+		member := member
+		s, err := NewAddressShards(AddressShardsConfig{
+			Self:        member,
+			Members:     members,
+			Addresses:   addresses,
+			OnRebalance: func(r ShardRebalance) { rebalances[member] = append(rebalances[member], r) },
+		})
+		require.NoError(t, err)
+		shards[member] = s
+	}
+
+	t.Run("every address has exactly one owner", func(t *testing.T) {
+		total := 0
+		for _, member := range members {
+			owned := shards[member].Owned()
+			assert.NotEmpty(t, owned, "%s owns nothing", member)
+			total += len(owned)
+			for _, address := range owned {
+				assert.Equal(t, member, shards["scanner-a"].Owner(address))
+			}
+		}
+		assert.Equal(t, len(addresses), total)
+	})
+
+	t.Run("a leaving member's addresses move to the others", func(t *testing.T) {
+		lost := shards["scanner-c"].Owned()
+		remaining := []string{"scanner-b", "scanner-a"}
+		gained := 0
+		for _, member := range []string{"scanner-a", "scanner-b"} {
+			before := shards[member].Owned()
+			rebalance := shards[member].SetMembers(remaining)
+			assert.Empty(t, rebalance.Removed, "addresses of remaining members stay put")
+			assert.Equal(t, []string{"scanner-a", "scanner-b"}, rebalance.Members)
+			for _, address := range rebalance.Added {
+				assert.Contains(t, lost, address)
+			}
+			assert.Len(t, shards[member].Owned(), len(before)+len(rebalance.Added))
+			gained += len(rebalance.Added)
+		}
+		assert.Equal(t, len(lost), gained)
+
+		rebalance := shards["scanner-c"].SetMembers(remaining)
+		assert.Equal(t, lost, rebalance.Removed)
+		assert.Empty(t, shards["scanner-c"].Owned())
+		require.Len(t, rebalances["scanner-c"], 1)
+	})
+
+	t.Run("watching and filtering", func(t *testing.T) {
+		s, err := NewAddressShards(AddressShardsConfig{Self: "solo"})
+		require.NoError(t, err)
+		added, err := s.Watch(addresses[0], addresses[1])
+		require.NoError(t, err)
+		assert.Len(t, added, 2)
+		_, err = s.Watch("DAGnope")
+		assert.ErrorIs(t, err, ErrInvalidAddress)
+		s.Unwatch(addresses[1])
+		assert.Equal(t, []string{addresses[0]}, s.Owned())
+
+		var handled []Event
+		filter := s.Filter(func(e Event) { handled = append(handled, e) })
+		filter(DepositDetected{Hash: "a", Destination: addresses[0]})
+		filter(DepositDetected{Hash: "b", Destination: addresses[1]})
+		filter(BalanceChanged{Address: addresses[1]})
+		filter(SnapshotAdvanced{Ordinal: 1})
+		assert.Equal(t, []Event{DepositDetected{Hash: "a", Destination: addresses[0]}, SnapshotAdvanced{Ordinal: 1}}, handled)
+	})
+
+	t.Run("notifies concurrent changes in order", func(t *testing.T) {
+		var mu sync.Mutex
+		scanned := map[string]bool{}
+		s, err := NewAddressShards(AddressShardsConfig{
+			Self: "solo",
+			OnRebalance: func(r ShardRebalance) {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, address := range r.Added {
+					scanned[address] = true
+				}
+				for _, address := range r.Removed {
+					delete(scanned, address)
+				}
+			},
+		})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					address := addresses[(i+j)%4]
+					if j%2 == 0 {
+						s.Watch(address)
+					} else {
+						s.Unwatch(address)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, s.Owned(), sortedKeys(scanned))
+	})
+
+	t.Run("validates its config", func(t *testing.T) {
+		_, err := NewAddressShards(AddressShardsConfig{Members: members})
+		assert.ErrorIs(t, err, ErrInvalidShardConfig)
+		_, err = NewAddressShards(AddressShardsConfig{Self: "solo", Addresses: []string{"DAGnope"}})
+		assert.ErrorIs(t, err, ErrInvalidAddress)
+	})
+}
@@ -0,0 +1,135 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidDAGAddress(t *testing.T) {
+	t.Run("agrees with the reference pattern", func(t *testing.T) {
+		pattern := regexp.MustCompile(`^DAG[0-8][123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz]{36}$`)
+		const noise = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!+/ \xff"
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 5000; i++ {
+			var b strings.Builder
+			b.WriteString("DAG")
+			b.WriteByte(byte('0' + rng.Intn(10)))
+			n := 34 + rng.Intn(4)
+			for j := 0; j < n; j++ {
+				if rng.Intn(50) == 0 {
+					b.WriteByte(noise[rng.Intn(len(noise))])
+				} else {
+					b.WriteByte(Base58Alphabet[rng.Intn(len(Base58Alphabet))])
+				}
+			}
+			s := b.String()
+			assert.Equal(t, pattern.MatchString(s), IsValidDAGAddress(s), s)
+		}
+	})
+
+	t.Run("accepts generated addresses", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			kp, err := GenerateKeyPair()
+			assert.NoError(t, err)
+			assert.True(t, IsValidDAGAddress(kp.Address), kp.Address)
+		}
+	})
+
+	t.Run("exposes the character set", func(t *testing.T) {
+		assert.Len(t, Base58Alphabet, 58)
+		for _, c := range []byte("0OIl") {
+			assert.False(t, IsBase58Char(c), string(c))
+		}
+		assert.True(t, IsBase58Char('z'))
+	})
+}
+
+// compressedSigner reports its public key compressed, as keys exported from
+// other tools often are
+type compressedSigner struct{ *PrivateKeySigner }
+
+func (s compressedSigner) PublicKey() string {
+	uncompressed, _ := hex.DecodeString(s.PrivateKeySigner.PublicKey())
+	publicKey, _ := btcec.ParsePubKey(uncompressed)
+	return hex.EncodeToString(publicKey.SerializeCompressed())
+}
+
+func TestCompressedPublicKeys(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	compressed, err := GetPublicKeyHex(kp.PrivateKey, true)
+	require.NoError(t, err)
+	id, err := GetPublicKeyID(kp.PrivateKey)
+	require.NoError(t, err)
+	other, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	t.Run("derives the same address", func(t *testing.T) {
+		assert.Equal(t, kp.Address, GetAddress(compressed))
+		assert.Equal(t, kp.Address, GetAddress(strings.ToUpper(compressed[:2])+compressed[2:]))
+	})
+
+	t.Run("normalizes to the uncompressed key and ID", func(t *testing.T) {
+		assert.Equal(t, kp.PublicKey, NormalizePublicKey(compressed))
+		assert.Equal(t, id, NormalizePublicKeyToID(compressed))
+	})
+
+	t.Run("validates compressed keys", func(t *testing.T) {
+		assert.True(t, IsValidPublicKey(compressed))
+		assert.False(t, IsValidPublicKey("04"+compressed[2:]))
+		// x = 5 is not on secp256k1
+		assert.False(t, IsValidPublicKey("02"+strings.Repeat("0", 63)+"5"))
+		assert.False(t, IsValidPublicKey("02"+strings.Repeat("g", 64)))
+	})
+
+	t.Run("verifies proofs with compressed IDs", func(t *testing.T) {
+		tx, err := CreateCurrencyTransaction(TransferParams{Destination: other.Address, Amount: 1}, kp.PrivateKey, GenesisReference)
+		require.NoError(t, err)
+		tx.Proofs[0].ID = compressed
+
+		assert.True(t, VerifyCurrencyTransaction(tx).IsValid)
+		assert.True(t, VerifyCurrencyTransactionStrict(tx))
+
+		tx.Proofs[0].ID, _ = GetPublicKeyHex(other.PrivateKey, true)
+		assert.False(t, VerifyCurrencyTransaction(tx).IsValid)
+	})
+
+	t.Run("counts a key signing under both IDs as one signer", func(t *testing.T) {
+		tx, err := CreateCurrencyTransaction(TransferParams{Destination: other.Address, Amount: 1}, kp.PrivateKey, GenesisReference)
+		require.NoError(t, err)
+		tx.Proofs = append(tx.Proofs, SignatureProof{ID: compressed, Signature: tx.Proofs[0].Signature})
+		require.Len(t, VerifyCurrencyTransaction(tx).ValidProofs, 2)
+
+		assert.Equal(t, []string{id}, DistinctSigners(tx.Proofs))
+		assert.Equal(t, []string{id}, NewStatement(tx, SnapshotMetadata{}).Signers)
+	})
+
+	t.Run("verifies data signed under a compressed ID", func(t *testing.T) {
+		data := map[string]interface{}{"id": "123"}
+		proof, err := Sign(data, kp.PrivateKey)
+		require.NoError(t, err)
+		proof.ID = compressed
+
+		valid, err := VerifySignature(data, proof, false)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("signers with compressed keys emit uncompressed IDs", func(t *testing.T) {
+		signer, err := NewPrivateKeySigner(kp.PrivateKey)
+		require.NoError(t, err)
+
+		tx, err := CreateCurrencyTransactionWithSigner(TransferParams{Destination: other.Address, Amount: 1}, compressedSigner{signer}, GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, kp.Address, tx.Value.Source)
+		assert.Equal(t, id, tx.Proofs[0].ID)
+		assert.True(t, VerifyCurrencyTransaction(tx).IsValid)
+	})
+}
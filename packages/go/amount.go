@@ -0,0 +1,138 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrDivisionByZero indicates dividing an Amount by zero
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Amount is an exact token amount of any size, held as a whole number of
+// smallest units (1e-8) in a big.Int. Unlike float64 token amounts it never
+// rounds: parsing, arithmetic and formatting are exact. Amounts are
+// immutable; arithmetic returns a new Amount. The zero value is zero.
+//
+// Amounts marshal to and from JSON as decimal token strings such as
+// "100.5", which keeps them exact in JavaScript consumers too.
+//
+// Example:
+//
+//	price, err := ParseAmount("100.5")
+//	total := price.Mul(3).Add(AmountFromUnits(fee))
+//	units, err := total.Units() // 30150000000 + fee
+type Amount struct {
+	units *big.Int
+}
+
+// AmountFromUnits returns the Amount of units smallest units
+func AmountFromUnits(units int64) Amount {
+	return Amount{units: big.NewInt(units)}
+}
+
+// AmountFromBigUnits returns the Amount of units smallest units. units is
+// copied.
+func AmountFromBigUnits(units *big.Int) Amount {
+	return Amount{units: new(big.Int).Set(units)}
+}
+
+// ParseAmount parses a decimal token amount such as "100.5" or "-0.00000001"
+// exactly. Returns ErrInvalidTokenAmount for malformed input or more than 8
+// decimal places.
+func ParseAmount(amount string) (Amount, error) {
+	units, err := parseTokenUnits(amount)
+	if err != nil {
+		return Amount{}, err
+	}
+	return Amount{units: units}, nil
+}
+
+// bigUnits returns the units without copying; callers must not modify them
+func (a Amount) bigUnits() *big.Int {
+	if a.units == nil {
+		return new(big.Int)
+	}
+	return a.units
+}
+
+// BigUnits returns a copy of the amount in smallest units
+func (a Amount) BigUnits() *big.Int {
+	return new(big.Int).Set(a.bigUnits())
+}
+
+// Units returns the amount in smallest units, or ErrAmountOutOfRange if it
+// does not fit in an int64
+func (a Amount) Units() (int64, error) {
+	units := a.bigUnits()
+	if !units.IsInt64() {
+		return 0, fmt.Errorf("%w: %s", ErrAmountOutOfRange, a)
+	}
+	return units.Int64(), nil
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return Amount{units: new(big.Int).Add(a.bigUnits(), b.bigUnits())}
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{units: new(big.Int).Sub(a.bigUnits(), b.bigUnits())}
+}
+
+// Mul returns a * n
+func (a Amount) Mul(n int64) Amount {
+	return Amount{units: new(big.Int).Mul(a.bigUnits(), big.NewInt(n))}
+}
+
+// Div returns a / n truncated toward zero to whole units, and the remainder
+// in units, so a payout split n ways can hand out the remainder. Returns
+// ErrDivisionByZero when n is zero.
+func (a Amount) Div(n int64) (quotient Amount, remainder Amount, err error) {
+	if n == 0 {
+		return Amount{}, Amount{}, ErrDivisionByZero
+	}
+	q, r := new(big.Int).QuoRem(a.bigUnits(), big.NewInt(n), new(big.Int))
+	return Amount{units: q}, Amount{units: r}, nil
+}
+
+// Neg returns -a
+func (a Amount) Neg() Amount {
+	return Amount{units: new(big.Int).Neg(a.bigUnits())}
+}
+
+// Cmp compares a and b, returning -1, 0 or +1
+func (a Amount) Cmp(b Amount) int {
+	return a.bigUnits().Cmp(b.bigUnits())
+}
+
+// Sign returns -1, 0 or +1 for a negative, zero or positive amount
+func (a Amount) Sign() int {
+	return a.bigUnits().Sign()
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// String formats the amount as an exact decimal token amount, e.g. "100.5"
+func (a Amount) String() string {
+	return formatTokenUnits(a.bigUnits())
+}
+
+// MarshalText encodes the amount as its decimal token string
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes a decimal token string
+func (a *Amount) UnmarshalText(text []byte) error {
+	parsed, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
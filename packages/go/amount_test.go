@@ -0,0 +1,107 @@
+package constellation
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmount(t *testing.T) {
+	t.Run("parses and formats exactly", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"100.5":                      "100.5",
+			"0.00000001":                 "0.00000001",
+			"-1.25":                      "-1.25",
+			"0":                          "0",
+			"123456789012345678.9876543": "123456789012345678.9876543",
+		} {
+			amount, err := ParseAmount(input)
+			require.NoError(t, err, input)
+			assert.Equal(t, expected, amount.String())
+		}
+		for _, input := range []string{"", "1.123456789", "abc", "1e5"} {
+			_, err := ParseAmount(input)
+			assert.ErrorIs(t, err, ErrInvalidTokenAmount, input)
+		}
+		assert.Equal(t, "0", Amount{}.String())
+	})
+
+	t.Run("does arithmetic without rounding", func(t *testing.T) {
+		a, err := ParseAmount("0.1")
+		require.NoError(t, err)
+		b, err := ParseAmount("0.2")
+		require.NoError(t, err)
+		assert.Equal(t, "0.3", a.Add(b).String())
+		assert.Equal(t, "-0.1", a.Sub(b).String())
+		assert.Equal(t, "0.3", a.Mul(3).String())
+		assert.Equal(t, 0, a.Add(b).Cmp(AmountFromUnits(30000000)))
+		assert.Equal(t, -1, a.Neg().Sign())
+		assert.True(t, Amount{}.IsZero())
+		assert.Equal(t, "0.1", Amount{}.Add(a).String())
+
+		quotient, remainder, err := AmountFromUnits(100).Div(3)
+		require.NoError(t, err)
+		assert.Equal(t, "0.00000033", quotient.String())
+		assert.Equal(t, "0.00000001", remainder.String())
+		_, _, err = a.Div(0)
+		assert.ErrorIs(t, err, ErrDivisionByZero)
+	})
+
+	t.Run("does not share its units", func(t *testing.T) {
+		units := big.NewInt(5)
+		amount := AmountFromBigUnits(units)
+		units.SetInt64(7)
+		amount.BigUnits().SetInt64(9)
+		assert.Equal(t, "0.00000005", amount.String())
+	})
+
+	t.Run("reports amounts too large for int64", func(t *testing.T) {
+		units, err := AmountFromUnits(1 << 62).Units()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1<<62), units)
+		_, err = AmountFromUnits(1 << 62).Mul(4).Units()
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	})
+
+	t.Run("round-trips through JSON as a string", func(t *testing.T) {
+		type payout struct {
+			Amount Amount `json:"amount"`
+		}
+		data, err := json.Marshal(payout{Amount: AmountFromUnits(10050000000)})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"amount":"100.5"}`, string(data))
+
+		var decoded payout
+		require.NoError(t, json.Unmarshal([]byte(`{"amount":"90071992.54740993"}`), &decoded))
+		assert.Equal(t, "90071992.54740993", decoded.Amount.String())
+		assert.Error(t, json.Unmarshal([]byte(`{"amount":"1.000000001"}`), &decoded))
+	})
+
+	t.Run("signs exact transfer amounts", func(t *testing.T) {
+		sender, err := GenerateKeyPair()
+		require.NoError(t, err)
+		recipient, err := GenerateKeyPair()
+		require.NoError(t, err)
+		amount, err := ParseAmount("90071992.54740993") // 2^53 + 1 units
+		require.NoError(t, err)
+
+		tx, err := CreateCurrencyTransaction(TransferParams{
+			Destination: recipient.Address,
+			Amount:      1,
+			ExactAmount: amount,
+			ExactFee:    AmountFromUnits(1),
+		}, sender.PrivateKey, GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, int64(9007199254740993), tx.Value.Amount)
+		assert.Equal(t, int64(1), tx.Value.Fee)
+
+		_, err = CreateCurrencyTransaction(TransferParams{
+			Destination: recipient.Address,
+			ExactAmount: AmountFromUnits(1 << 62).Mul(4),
+		}, sender.PrivateKey, GenesisReference)
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	})
+}
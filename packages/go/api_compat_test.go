@@ -0,0 +1,201 @@
+package constellation
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateAPI = flag.Bool("update-api", false, "rewrite testdata/api.txt from the current exported API")
+
+const apiFile = "testdata/api.txt"
+
+// TestPublicAPICompatibility fails when an exported identifier recorded in
+// testdata/api.txt is removed or changes signature. Additions are allowed;
+// record them with:
+//
+//	go test -run TestPublicAPICompatibility -update-api
+//
+// The file starts with the module path it was recorded for. -update-api
+// refuses to drop recorded lines until that path changes, i.e. in a new
+// major version.
+func TestPublicAPICompatibility(t *testing.T) {
+	current := exportedAPI(t)
+	module := modulePath(t)
+
+	recordedModule, recorded := readAPIFile(t)
+	have := make(map[string]bool, len(current))
+	for _, line := range current {
+		have[line] = true
+	}
+	var missing []string
+	for _, line := range recorded {
+		if !have[line] {
+			missing = append(missing, line)
+		}
+	}
+
+	if *updateAPI {
+		if recordedModule == module && len(missing) > 0 {
+			t.Fatalf("refusing to drop recorded API within %s; add a deprecated shim instead, or bump the major version:\n  %s",
+				module, strings.Join(missing, "\n  "))
+		}
+		content := "module " + module + "\n" + strings.Join(current, "\n") + "\n"
+		require.NoError(t, os.WriteFile(apiFile, []byte(content), 0o644))
+		return
+	}
+
+	if recordedModule != module {
+		t.Fatalf("%s records the API of %s, not %s; record the new major version with -update-api", apiFile, recordedModule, module)
+	}
+	if len(missing) > 0 {
+		t.Errorf("exported API removed or changed; add a deprecated shim instead, or bump the major version:\n  %s",
+			strings.Join(missing, "\n  "))
+	}
+}
+
+// readAPIFile returns the module path and API lines recorded in apiFile. A
+// file without a module line was recorded before major versions were
+// tracked, for the unversioned module path.
+func readAPIFile(t *testing.T) (string, []string) {
+	t.Helper()
+	data, err := os.ReadFile(apiFile)
+	require.NoError(t, err)
+
+	module := majorVersionSuffix.ReplaceAllString(modulePath(t), "")
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		switch {
+		case strings.HasPrefix(line, "module "):
+			module = strings.TrimPrefix(line, "module ")
+		case line != "":
+			lines = append(lines, line)
+		}
+	}
+	return module, lines
+}
+
+var majorVersionSuffix = regexp.MustCompile(`/v[0-9]+$`)
+
+// modulePath reads the module path from go.mod
+func modulePath(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile("go.mod")
+	require.NoError(t, err)
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		}
+	}
+	t.Fatal("go.mod has no module line")
+	return ""
+}
+
+// exportedAPI lists one line per exported declaration in the package,
+// across all build tags
+func exportedAPI(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	var lines []string
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+		lines = append(lines, fileAPI(fset, file)...)
+	}
+
+	sort.Strings(lines)
+	return lines
+}
+
+func fileAPI(fset *token.FileSet, file *ast.File) []string {
+	var lines []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv != nil {
+				recv := render(fset, d.Recv.List[0].Type)
+				if !ast.IsExported(strings.TrimLeft(strings.SplitN(recv, "[", 2)[0], "*")) {
+					continue
+				}
+				lines = append(lines, "method ("+recv+") "+d.Name.Name+strings.TrimPrefix(render(fset, d.Type), "func"))
+				continue
+			}
+			lines = append(lines, "func "+d.Name.Name+strings.TrimPrefix(render(fset, d.Type), "func"))
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						lines = append(lines, typeAPI(fset, s)...)
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.IsExported() {
+							lines = append(lines, d.Tok.String()+" "+n.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+func typeAPI(fset *token.FileSet, spec *ast.TypeSpec) []string {
+	name := spec.Name.Name
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		lines := []string{"type " + name + " struct"}
+		for _, field := range typ.Fields.List {
+			for _, n := range field.Names {
+				if n.IsExported() {
+					lines = append(lines, "field "+name+"."+n.Name+" "+render(fset, field.Type))
+				}
+			}
+			if embedded := render(fset, field.Type); len(field.Names) == 0 && ast.IsExported(strings.TrimLeft(embedded, "*")) {
+				lines = append(lines, "embed "+name+" "+embedded)
+			}
+		}
+		return lines
+	case *ast.InterfaceType:
+		lines := []string{"type " + name + " interface"}
+		for _, method := range typ.Methods.List {
+			for _, n := range method.Names {
+				lines = append(lines, "method "+name+"."+n.Name+strings.TrimPrefix(render(fset, method.Type), "func"))
+			}
+		}
+		return lines
+	}
+
+	sep := " "
+	if spec.Assign.IsValid() {
+		sep = " = "
+	}
+	return []string{"type " + name + sep + render(fset, spec.Type)}
+}
+
+func render(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, fset, node)
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
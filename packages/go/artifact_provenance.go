@@ -0,0 +1,142 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// InTotoStatementType is the in-toto v1 statement type
+const InTotoStatementType = "https://in-toto.io/Statement/v1"
+
+// SLSAProvenancePredicateType is the SLSA v1 provenance predicate type
+const SLSAProvenancePredicateType = "https://slsa.dev/provenance/v1"
+
+var (
+	// ErrInvalidArtifactDigest indicates a digest not in "algorithm:hex" form
+	ErrInvalidArtifactDigest = errors.New("invalid artifact digest")
+	// ErrArtifactSignatureInvalid indicates a statement with a missing or bad signature
+	ErrArtifactSignatureInvalid = errors.New("artifact statement signature is invalid")
+	// ErrArtifactUntrustedSigner indicates a statement not signed by any
+	// trusted key, or no trusted keys given
+	ErrArtifactUntrustedSigner = errors.New("artifact statement is not signed by a trusted key")
+	// ErrArtifactNotCovered indicates a statement that does not name the artifact
+	ErrArtifactNotCovered = errors.New("artifact is not a subject of the statement")
+)
+
+// ArtifactSubject names an artifact and its digests, keyed by algorithm
+// (e.g. "sha256")
+type ArtifactSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ArtifactStatement is an in-toto style attestation about one or more
+// artifacts. Signed with a DAG key as a Signed[ArtifactStatement], it lets
+// teams attest releases with their metagraph identities.
+type ArtifactStatement struct {
+	Type          string            `json:"_type"`
+	Subject       []ArtifactSubject `json:"subject"`
+	PredicateType string            `json:"predicateType"`
+	// Predicate is the predicate document, e.g. SLSA provenance
+	Predicate json.RawMessage `json:"predicate,omitempty"`
+}
+
+// NewArtifactSubject creates a subject from a digest in "algorithm:hex" form,
+// as used for container images (e.g. "sha256:9f86d0...")
+func NewArtifactSubject(name string, digest string) (ArtifactSubject, error) {
+	algorithm, value, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || value == "" {
+		return ArtifactSubject{}, ErrInvalidArtifactDigest
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return ArtifactSubject{}, ErrInvalidArtifactDigest
+	}
+	return ArtifactSubject{
+		Name:   name,
+		Digest: map[string]string{strings.ToLower(algorithm): strings.ToLower(value)},
+	}, nil
+}
+
+// ArtifactSubjectFromReader creates a subject from the SHA-256 of r
+func ArtifactSubjectFromReader(name string, r io.Reader) (ArtifactSubject, error) {
+	digest, err := sha256Hex(r)
+	if err != nil {
+		return ArtifactSubject{}, err
+	}
+	return ArtifactSubject{
+		Name:   name,
+		Digest: map[string]string{"sha256": digest},
+	}, nil
+}
+
+// NewArtifactStatement creates a statement about subjects. predicate is
+// marshaled to JSON; pass nil for a statement without one.
+func NewArtifactStatement(predicateType string, predicate interface{}, subjects ...ArtifactSubject) (*ArtifactStatement, error) {
+	statement := &ArtifactStatement{
+		Type:          InTotoStatementType,
+		Subject:       subjects,
+		PredicateType: predicateType,
+	}
+	if predicate != nil {
+		raw, err := json.Marshal(predicate)
+		if err != nil {
+			return nil, err
+		}
+		statement.Predicate = raw
+	}
+	return statement, nil
+}
+
+// Covers reports whether the statement names an artifact with the digest,
+// given in "algorithm:hex" form
+func (s *ArtifactStatement) Covers(digest string) bool {
+	want, err := NewArtifactSubject("", digest)
+	if err != nil {
+		return false
+	}
+	for algorithm, value := range want.Digest {
+		for _, subject := range s.Subject {
+			if strings.EqualFold(subject.Digest[algorithm], value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SignArtifactStatement signs a statement with a DAG key
+//
+// Example:
+//
+//	subject, _ := ArtifactSubjectFromReader("metakit-linux-amd64", file)
+//	statement, _ := NewArtifactStatement(SLSAProvenancePredicateType, provenance, subject)
+//	signer, _ := NewPrivateKeySigner(releaseKey)
+//	attestation, err := SignArtifactStatement(statement, signer)
+func SignArtifactStatement(statement *ArtifactStatement, signer Signer) (*Signed[ArtifactStatement], error) {
+	return CreateSignedObjectWithSigner(*statement, signer, false)
+}
+
+// VerifyArtifactStatement checks that every signature on the statement is
+// valid, that one was made by a trusted DAG address, and, if digest is not
+// empty, that the statement covers it. An empty trustedSigners returns
+// ErrArtifactUntrustedSigner: anyone can sign a statement, so a signature
+// alone proves nothing.
+func VerifyArtifactStatement(signed *Signed[ArtifactStatement], digest string, trustedSigners []string) error {
+	if signed == nil || !Verify(signed, false).IsValid {
+		return ErrArtifactSignatureInvalid
+	}
+	if len(trustedSigners) == 0 {
+		return fmt.Errorf("%w: no trusted signers given", ErrArtifactUntrustedSigner)
+	}
+	if !signedByAny(signed.Proofs, trustedSigners) {
+		return ErrArtifactUntrustedSigner
+	}
+	if digest != "" && !signed.Value.Covers(digest) {
+		return ErrArtifactNotCovered
+	}
+	return nil
+}
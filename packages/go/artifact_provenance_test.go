@@ -0,0 +1,59 @@
+package constellation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifactProvenance(t *testing.T) {
+	release, err := GenerateKeyPair()
+	require.NoError(t, err)
+	other, _ := GenerateKeyPair()
+	signer, err := NewPrivateKeySigner(release.PrivateKey)
+	require.NoError(t, err)
+
+	binary, err := ArtifactSubjectFromReader("metakit-linux-amd64", strings.NewReader("test"))
+	require.NoError(t, err)
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", binary.Digest["sha256"])
+
+	image, err := NewArtifactSubject("ghcr.io/example/app", "SHA256:ABCDEF")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"sha256": "abcdef"}, image.Digest)
+
+	statement, err := NewArtifactStatement(SLSAProvenancePredicateType, map[string]string{"builder": "ci"}, binary, image)
+	require.NoError(t, err)
+	attestation, err := SignArtifactStatement(statement, signer)
+	require.NoError(t, err)
+
+	t.Run("verifies signer and subject", func(t *testing.T) {
+		assert.NoError(t, VerifyArtifactStatement(attestation, "sha256:abcdef", []string{release.Address}))
+		assert.ErrorIs(t, VerifyArtifactStatement(attestation, "", nil), ErrArtifactUntrustedSigner, "a signature alone is not trusted")
+		assert.ErrorIs(t, VerifyArtifactStatement(attestation, "sha256:0123", []string{release.Address}), ErrArtifactNotCovered)
+		assert.ErrorIs(t, VerifyArtifactStatement(attestation, "", []string{other.Address}), ErrArtifactUntrustedSigner)
+	})
+
+	t.Run("survives a JSON round trip", func(t *testing.T) {
+		data, err := json.Marshal(attestation)
+		require.NoError(t, err)
+		var decoded Signed[ArtifactStatement]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.NoError(t, VerifyArtifactStatement(&decoded, "sha256:"+binary.Digest["sha256"], []string{release.Address}))
+	})
+
+	t.Run("detects tampering", func(t *testing.T) {
+		tampered := *attestation
+		tampered.Value.Subject = []ArtifactSubject{{Name: "evil", Digest: map[string]string{"sha256": "00"}}}
+		assert.ErrorIs(t, VerifyArtifactStatement(&tampered, "", nil), ErrArtifactSignatureInvalid)
+	})
+
+	t.Run("rejects malformed digests", func(t *testing.T) {
+		for _, digest := range []string{"abcdef", "sha256:", ":abcdef", "sha256:xyz"} {
+			_, err := NewArtifactSubject("x", digest)
+			assert.ErrorIs(t, err, ErrInvalidArtifactDigest, digest)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package awskms
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+var (
+	// ErrRegionRequired indicates a client without a region
+	ErrRegionRequired = errors.New("AWS region is required")
+	// ErrCredentialsRequired indicates a client without an access key
+	ErrCredentialsRequired = errors.New("AWS credentials are required")
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Config configures a KMS Client. Empty fields fall back to the standard
+// AWS environment variables: AWS_REGION (or AWS_DEFAULT_REGION),
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+type Config struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint overrides https://kms.<region>.amazonaws.com, e.g. for a VPC
+	// endpoint or LocalStack
+	Endpoint string
+	// Timeout per request (default: 10s)
+	Timeout time.Duration
+}
+
+// Client calls the KMS JSON API over HTTPS, signing requests with AWS
+// Signature Version 4. It only reads static credentials; for instance
+// roles or SSO, adapt the AWS SDK's kms.Client to API instead.
+type Client struct {
+	config     Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+var _ API = (*Client)(nil)
+
+// NewClient creates a KMS client
+func NewClient(config Config) (*Client, error) {
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if config.Region == "" {
+		return nil, ErrRegionRequired
+	}
+	if config.AccessKeyID == "" && config.SecretAccessKey == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if config.SessionToken == "" {
+			config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, ErrCredentialsRequired
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", config.Region)
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid KMS endpoint %q", config.Endpoint)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	return &Client{
+		config:     config,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		now:        time.Now,
+	}, nil
+}
+
+// Sign calls kms:Sign on a digest with ECDSA_SHA_256
+func (c *Client) Sign(keyID string, digest []byte) ([]byte, error) {
+	var result struct {
+		Signature []byte `json:"Signature"`
+	}
+	err := c.call("Sign", map[string]interface{}{
+		"KeyId":            keyID,
+		"Message":          digest,
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": "ECDSA_SHA_256",
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Signature, nil
+}
+
+// GetPublicKey calls kms:GetPublicKey. Returns ErrUnsupportedKey for a key
+// that is not ECC_SECG_P256K1.
+func (c *Client) GetPublicKey(keyID string) ([]byte, error) {
+	var result struct {
+		PublicKey []byte `json:"PublicKey"`
+		KeySpec   string `json:"KeySpec"`
+	}
+	if err := c.call("GetPublicKey", map[string]interface{}{"KeyId": keyID}, &result); err != nil {
+		return nil, err
+	}
+	if result.KeySpec != "" && result.KeySpec != "ECC_SECG_P256K1" {
+		return nil, fmt.Errorf("%w: key spec %s", ErrUnsupportedKey, result.KeySpec)
+	}
+	return result.PublicKey, nil
+}
+
+// call posts one KMS action. Error responses are returned as a
+// *constellation.NetworkError carrying the AWS error body.
+func (c *Client) call(action string, input interface{}, result interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	c.sign(req, body, c.now().UTC())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("KMS %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("KMS %s: %w", action, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return constellation.NewNetworkError(fmt.Sprintf("KMS %s failed", action), resp.StatusCode, string(respBody))
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// sign adds AWS Signature Version 4 headers for the kms service
+func (c *Client) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.config.SessionToken)
+	}
+	req.Header.Set("Authorization", authorization(req, body, signingScope{
+		accessKeyID:     c.config.AccessKeyID,
+		secretAccessKey: c.config.SecretAccessKey,
+		region:          c.config.Region,
+		service:         "kms",
+		date:            amzDate,
+	}))
+}
+
+// signingScope holds the credentials and scope of a SigV4 signature
+type signingScope struct {
+	accessKeyID     string
+	secretAccessKey string
+	region          string
+	service         string
+	// date is the X-Amz-Date timestamp, e.g. 20150830T123600Z
+	date string
+}
+
+// authorization computes the SigV4 Authorization header over the request's
+// host and headers
+func authorization(req *http.Request, body []byte, scope signingScope) string {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	delete(headers, "authorization")
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	day := scope.date[:8]
+	credentialScope := day + "/" + scope.region + "/" + scope.service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + scope.date + "\n" + credentialScope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+scope.secretAccessKey), day)
+	key = hmacSHA256(key, scope.region)
+	key = hmacSHA256(key, scope.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		scope.accessKeyID, credentialScope, signedHeaders, signature)
+}
+
+// canonicalQuery encodes query parameters sorted by name, spaces as %20
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,118 @@
+package awskms
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureV4(t *testing.T) {
+	// example request from the AWS Signature Version 4 documentation
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("X-Amz-Date", "20150830T123600Z")
+
+	header := authorization(req, nil, signingScope{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:          "us-east-1",
+		service:         "iam",
+		date:            "20150830T123600Z",
+	})
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", header)
+}
+
+func TestClient(t *testing.T) {
+	kms := newFakeKMS(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request")
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+
+		var input struct {
+			KeyId       string
+			Message     []byte
+			MessageType string
+		}
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &input))
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			spki, err := kms.GetPublicKey(input.KeyId)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"NotFoundException","message":"Alias not found"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"KeySpec":   "ECC_SECG_P256K1",
+				"PublicKey": base64.StdEncoding.EncodeToString(spki),
+			})
+		case "TrentService.Sign":
+			assert.Equal(t, "DIGEST", input.MessageType)
+			signature, _ := kms.Sign(input.KeyId, input.Message)
+			_ = json.NewEncoder(w).Encode(map[string]string{"Signature": base64.StdEncoding.EncodeToString(signature)})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        server.URL,
+		Timeout:         time.Second,
+	})
+	require.NoError(t, err)
+
+	kmsSigner, err := NewSigner(client, "alias/hot-wallet")
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(kms.privateKey.PubKey().SerializeUncompressed()), kmsSigner.PublicKey())
+
+	digest := make([]byte, 32)
+	signature, err := kmsSigner.SignDigest(digest)
+	require.NoError(t, err)
+	assert.NotEmpty(t, signature)
+
+	_, err = NewSigner(client, "alias/missing")
+	var networkErr *constellation.NetworkError
+	require.ErrorAs(t, err, &networkErr)
+	assert.Equal(t, http.StatusBadRequest, networkErr.StatusCode)
+	assert.Contains(t, networkErr.Response, "NotFoundException")
+}
+
+func TestNewClientConfig(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	_, err := NewClient(Config{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	assert.ErrorIs(t, err, ErrRegionRequired)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err = NewClient(Config{Region: "us-east-1"})
+	assert.ErrorIs(t, err, ErrCredentialsRequired)
+
+	t.Setenv("AWS_DEFAULT_REGION", "ap-south-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	client, err := NewClient(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://kms.ap-south-1.amazonaws.com", client.endpoint.String())
+}
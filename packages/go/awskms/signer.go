@@ -0,0 +1,150 @@
+// Package awskms signs Constellation transactions with an asymmetric
+// secp256k1 key held in AWS KMS, so the private key never enters process
+// memory.
+//
+// The KMS key must have key spec ECC_SECG_P256K1 and key usage
+// SIGN_VERIFY. The signing principal needs kms:Sign and kms:GetPublicKey.
+package awskms
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+var (
+	// ErrKeyIDRequired indicates a signer without a KMS key ID
+	ErrKeyIDRequired = errors.New("KMS key ID is required")
+	// ErrUnsupportedKey indicates a KMS key that is not secp256k1
+	ErrUnsupportedKey = errors.New("KMS key is not an ECC_SECG_P256K1 key")
+	// ErrInvalidSignature indicates a KMS response that is not a DER ECDSA
+	// signature
+	ErrInvalidSignature = errors.New("invalid KMS signature")
+)
+
+var (
+	oidECPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1   = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// API is the part of the KMS API the signer uses. Client implements it over
+// HTTPS; to reuse credentials from the AWS SDK (instance roles, SSO), adapt
+// its kms.Client instead:
+//
+//	type sdkKMS struct{ client *kms.Client }
+//
+//	func (k sdkKMS) Sign(keyID string, digest []byte) ([]byte, error) {
+//	    out, err := k.client.Sign(context.TODO(), &kms.SignInput{
+//	        KeyId: &keyID, Message: digest,
+//	        MessageType: types.MessageTypeDigest, SigningAlgorithm: types.SigningAlgorithmSpecEcdsaSha256,
+//	    })
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return out.Signature, nil
+//	}
+type API interface {
+	// Sign signs a 32-byte digest with ECDSA_SHA_256 and returns the DER
+	// signature
+	Sign(keyID string, digest []byte) ([]byte, error)
+	// GetPublicKey returns the key's DER SubjectPublicKeyInfo
+	GetPublicKey(keyID string) ([]byte, error)
+}
+
+// Signer is a constellation.DigestSigner backed by a KMS key. Wrap it with
+// constellation.NewDigestSigner wherever a constellation.Signer is taken.
+//
+// Example:
+//
+//	client, err := awskms.NewClient(awskms.Config{Region: "us-east-1"})
+//	kmsSigner, err := awskms.NewSigner(client, "alias/hot-wallet")
+//	address := constellation.GetAddress(kmsSigner.PublicKey())
+//
+//	signer := constellation.NewDigestSigner(kmsSigner)
+//	tx, err := constellation.CreateCurrencyTransactionWithSigner(params, signer, lastRef)
+type Signer struct {
+	api       API
+	keyID     string
+	publicKey string
+}
+
+var _ constellation.DigestSigner = (*Signer)(nil)
+
+// NewSigner fetches the public key of keyID (a key ID, ARN or alias) and
+// returns a signer for it. Returns ErrUnsupportedKey unless the key is
+// secp256k1.
+func NewSigner(api API, keyID string) (*Signer, error) {
+	if keyID == "" {
+		return nil, ErrKeyIDRequired
+	}
+	spki, err := api.GetPublicKey(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get KMS public key: %w", err)
+	}
+	publicKey, err := parsePublicKey(spki)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{api: api, keyID: keyID, publicKey: hex.EncodeToString(publicKey.SerializeUncompressed())}, nil
+}
+
+// KeyID returns the KMS key the signer uses
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the uncompressed public key hex (with 04 prefix), from
+// which the address and proof ID are derived
+func (s *Signer) PublicKey() string {
+	return s.publicKey
+}
+
+// SignDigest signs the 32-byte Constellation signing digest in KMS. KMS may
+// return a high-S signature; it is normalized to low S, as the SDK's own
+// signatures are.
+func (s *Signer) SignDigest(digest []byte) ([]byte, error) {
+	if err := constellation.CheckSigningAllowed(); err != nil {
+		return nil, err
+	}
+	der, err := s.api.Sign(s.keyID, digest)
+	if err != nil {
+		return nil, fmt.Errorf("KMS sign: %w", err)
+	}
+	signature, err := ecdsa.ParseDERSignature(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	// Serialize always encodes the low-S form
+	return signature.Serialize(), nil
+}
+
+// parsePublicKey reads a secp256k1 SubjectPublicKeyInfo, which the standard
+// library's x509 parser does not support
+func parsePublicKey(der []byte) (*btcec.PublicKey, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	rest, err := asn1.Unmarshal(der, &spki)
+	if err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("%w: malformed public key", ErrUnsupportedKey)
+	}
+	var curve asn1.ObjectIdentifier
+	if !spki.Algorithm.Algorithm.Equal(oidECPublicKey) {
+		return nil, ErrUnsupportedKey
+	}
+	if _, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve); err != nil || !curve.Equal(oidSecp256k1) {
+		return nil, ErrUnsupportedKey
+	}
+	publicKey, err := btcec.ParsePubKey(spki.PublicKey.RightAlign())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+	}
+	return publicKey, nil
+}
@@ -0,0 +1,130 @@
+package awskms
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS holds a secp256k1 key the way KMS does, returning high-S
+// signatures when highS is set
+type fakeKMS struct {
+	privateKey *btcec.PrivateKey
+	curve      asn1.ObjectIdentifier
+	highS      bool
+	signed     int
+}
+
+func newFakeKMS(t *testing.T) *fakeKMS {
+	privateKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	return &fakeKMS{privateKey: privateKey, curve: oidSecp256k1}
+}
+
+func (k *fakeKMS) GetPublicKey(keyID string) ([]byte, error) {
+	if keyID != "alias/hot-wallet" {
+		return nil, errors.New("NotFoundException")
+	}
+	params, _ := asn1.Marshal(k.curve)
+	point := k.privateKey.PubKey().SerializeUncompressed()
+	return asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidECPublicKey, Parameters: asn1.RawValue{FullBytes: params}},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+}
+
+func (k *fakeKMS) Sign(keyID string, digest []byte) ([]byte, error) {
+	k.signed++
+	der := ecdsa.Sign(k.privateKey, digest).Serialize()
+	if !k.highS {
+		return der, nil
+	}
+	var signature struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &signature); err != nil {
+		return nil, err
+	}
+	signature.S.Sub(btcec.S256().N, signature.S)
+	return asn1.Marshal(signature)
+}
+
+func TestSigner(t *testing.T) {
+	t.Run("signs transactions with the KMS key", func(t *testing.T) {
+		kms := newFakeKMS(t)
+		kmsSigner, err := NewSigner(kms, "alias/hot-wallet")
+		require.NoError(t, err)
+
+		publicKey := kmsSigner.PublicKey()
+		assert.Len(t, publicKey, 130)
+		assert.Equal(t, "04", publicKey[:2])
+		source := constellation.GetAddress(publicKey)
+
+		destination, _ := constellation.GenerateKeyPair()
+		tx, err := constellation.CreateCurrencyTransactionWithSigner(constellation.TransferParams{
+			Destination: destination.Address,
+			Amount:      1,
+		}, constellation.NewDigestSigner(kmsSigner), constellation.GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, source, tx.Value.Source)
+		assert.Equal(t, publicKey[2:], tx.Proofs[0].ID)
+		assert.True(t, constellation.VerifyCurrencyTransaction(tx).IsValid)
+		assert.Equal(t, 1, kms.signed)
+	})
+
+	t.Run("normalizes high-S signatures", func(t *testing.T) {
+		kms := newFakeKMS(t)
+		kms.highS = true
+		kmsSigner, err := NewSigner(kms, "alias/hot-wallet")
+		require.NoError(t, err)
+
+		digest := make([]byte, 32)
+		der, err := kmsSigner.SignDigest(digest)
+		require.NoError(t, err)
+
+		var signature struct{ R, S *big.Int }
+		_, err = asn1.Unmarshal(der, &signature)
+		require.NoError(t, err)
+		halfOrder := new(big.Int).Rsh(btcec.S256().N, 1)
+		assert.True(t, signature.S.Cmp(halfOrder) <= 0)
+
+		parsed, err := ecdsa.ParseDERSignature(der)
+		require.NoError(t, err)
+		assert.True(t, parsed.Verify(digest, kms.privateKey.PubKey()))
+	})
+
+	t.Run("rejects keys on other curves", func(t *testing.T) {
+		kms := newFakeKMS(t)
+		kms.curve = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7} // P-256
+		_, err := NewSigner(kms, "alias/hot-wallet")
+		assert.ErrorIs(t, err, ErrUnsupportedKey)
+	})
+
+	t.Run("reports KMS errors", func(t *testing.T) {
+		_, err := NewSigner(newFakeKMS(t), "alias/missing")
+		assert.ErrorContains(t, err, "NotFoundException")
+		_, err = NewSigner(newFakeKMS(t), "")
+		assert.ErrorIs(t, err, ErrKeyIDRequired)
+	})
+
+	t.Run("does not call KMS while signing is frozen", func(t *testing.T) {
+		kms := newFakeKMS(t)
+		signer, err := NewSigner(kms, "alias/hot-wallet")
+		require.NoError(t, err)
+
+		constellation.FreezeSigning("incident")
+		defer constellation.UnfreezeSigning()
+		_, err = signer.SignDigest(make([]byte, 32))
+		assert.ErrorIs(t, err, constellation.ErrSigningFrozen)
+		assert.Zero(t, kms.signed)
+	})
+}
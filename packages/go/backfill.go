@@ -0,0 +1,241 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const defaultBackfillHistoryLimit = 1000
+
+var (
+	// ErrInvalidBackfill indicates a BackfillConfig that cannot run
+	ErrInvalidBackfill = errors.New("invalid backfill config")
+	// ErrBackfillIncomplete indicates history the explorer could not
+	// provide: a snapshot it has not indexed, or address history older than
+	// it returns
+	ErrBackfillIncomplete = errors.New("backfill history incomplete")
+)
+
+// BackfillConfig holds configuration for Backfill
+type BackfillConfig struct {
+	// Explorer provides the history. If it implements ExplorerBlockLookup,
+	// Backfill walks each snapshot's blocks; otherwise it reads each
+	// address's transaction history.
+	Explorer ExplorerAPI
+	// Addresses are the watched addresses to replay deposits to
+	Addresses []string
+	// FromOrdinal is the first global snapshot to replay
+	FromOrdinal int64
+	// ToOrdinal is the last global snapshot to replay (default: the latest)
+	ToOrdinal int64
+	// Handler receives the replayed events, e.g. the EventBus.Publish or
+	// ConfirmationTracker.Handle the live scanner feeds
+	Handler EventHandler
+	// Checkpoints, if set, records progress under CheckpointName after every
+	// snapshot. A backfill finding a checkpoint within its range resumes
+	// after it instead of replaying snapshots already done.
+	Checkpoints    CheckpointStore
+	CheckpointName string
+	// HistoryLimit caps the transactions read per address when the explorer
+	// has no block lookup (default: 1000)
+	HistoryLimit int
+}
+
+// BackfillResult summarizes a backfill
+type BackfillResult struct {
+	// FromOrdinal is the first snapshot replayed, after any checkpoint
+	FromOrdinal int64
+	// ToOrdinal is the last snapshot replayed
+	ToOrdinal int64
+	// Snapshots is how many snapshots were replayed
+	Snapshots int
+	// Deposits is how many DepositDetected events were replayed
+	Deposits int
+}
+
+// Backfill replays the deposits to addresses confirmed in global snapshots
+// FromOrdinal through ToOrdinal, to recover what a scanner missed during an
+// outage. Events go to Handler in snapshot order, as live scanning emits
+// them: a DepositDetected per deposit, then the snapshot's
+// SnapshotAdvanced, so a ConfirmationTracker finalizes replayed deposits as
+// it would live ones.
+//
+// Deposits already handled live are replayed again unless a checkpoint
+// shows their snapshot is done; wrap Handler in ReplayFilter to drop them
+// by hash. Backfill stops at the first error or when ctx is cancelled, and
+// returns what it replayed so far; with Checkpoints set, running it again
+// resumes there.
+//
+// Example:
+//
+//	result, err := Backfill(ctx, BackfillConfig{
+//	    Explorer:       explorer,
+//	    Addresses:      depositAddresses,
+//	    FromOrdinal:    outageStart,
+//	    Handler:        ReplayFilter(registry, tracker.Handle, logError),
+//	    Checkpoints:    store,
+//	    CheckpointName: "deposit-backfill",
+//	})
+func Backfill(ctx context.Context, config BackfillConfig) (*BackfillResult, error) {
+	switch {
+	case config.Explorer == nil:
+		return nil, fmt.Errorf("%w: no explorer", ErrInvalidBackfill)
+	case config.Handler == nil:
+		return nil, fmt.Errorf("%w: no handler", ErrInvalidBackfill)
+	case len(config.Addresses) == 0:
+		return nil, fmt.Errorf("%w: no addresses", ErrInvalidBackfill)
+	case config.FromOrdinal < 0:
+		return nil, fmt.Errorf("%w: negative start ordinal", ErrInvalidBackfill)
+	case config.Checkpoints != nil && config.CheckpointName == "":
+		return nil, fmt.Errorf("%w: no checkpoint name", ErrInvalidBackfill)
+	}
+	watched := make(map[string]bool, len(config.Addresses))
+	for _, address := range config.Addresses {
+		if !IsValidDAGAddress(address) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, RedactAddress(address))
+		}
+		watched[address] = true
+	}
+	if config.HistoryLimit <= 0 {
+		config.HistoryLimit = defaultBackfillHistoryLimit
+	}
+
+	if config.ToOrdinal <= 0 {
+		latest, err := config.Explorer.GetLatestSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		if latest == nil {
+			return nil, fmt.Errorf("%w: no latest snapshot", ErrBackfillIncomplete)
+		}
+		config.ToOrdinal = latest.Ordinal
+	}
+	if config.ToOrdinal < config.FromOrdinal {
+		return nil, fmt.Errorf("%w: end ordinal before start ordinal", ErrInvalidBackfill)
+	}
+
+	from := config.FromOrdinal
+	if config.Checkpoints != nil {
+		checkpoint, err := config.Checkpoints.Load(config.CheckpointName)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil && checkpoint.Ordinal >= from {
+			from = checkpoint.Ordinal + 1
+		}
+	}
+	result := &BackfillResult{FromOrdinal: from, ToOrdinal: from - 1}
+	if from > config.ToOrdinal {
+		return result, nil
+	}
+
+	deposits := backfillBlocks(config.Explorer, watched)
+	if deposits == nil {
+		byOrdinal, err := backfillHistory(config, watched, from)
+		if err != nil {
+			return result, err
+		}
+		deposits = func(ordinal int64) ([]ExplorerTransaction, error) {
+			return byOrdinal[ordinal], nil
+		}
+	}
+
+	for ordinal := from; ordinal <= config.ToOrdinal; ordinal++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		snapshot, err := config.Explorer.GetSnapshot(ordinal)
+		if err != nil {
+			return result, err
+		}
+		if snapshot == nil {
+			return result, fmt.Errorf("%w: snapshot %d not indexed", ErrBackfillIncomplete, ordinal)
+		}
+		txs, err := deposits(ordinal)
+		if err != nil {
+			return result, err
+		}
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Hash < txs[j].Hash })
+
+		for _, tx := range txs {
+			config.Handler(DepositDetected{
+				Hash:        tx.Hash,
+				Source:      tx.Source,
+				Destination: tx.Destination,
+				Amount:      tx.Amount,
+				Ordinal:     ordinal,
+				At:          tx.Timestamp,
+			})
+		}
+		config.Handler(SnapshotAdvanced{Ordinal: ordinal, Hash: snapshot.Hash, At: snapshot.Timestamp})
+		result.ToOrdinal = ordinal
+		result.Snapshots++
+		result.Deposits += len(txs)
+
+		if config.Checkpoints != nil {
+			checkpoint := Checkpoint{Ordinal: ordinal, Hash: snapshot.Hash, UpdatedAt: time.Now().UTC()}
+			if err := config.Checkpoints.Save(config.CheckpointName, checkpoint); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// backfillBlocks returns a lookup of the deposits to watched confirmed in a
+// snapshot, walking its blocks, or nil if explorer has no block lookup
+func backfillBlocks(explorer ExplorerAPI, watched map[string]bool) func(ordinal int64) ([]ExplorerTransaction, error) {
+	blocks, ok := explorer.(ExplorerBlockLookup)
+	if !ok {
+		return nil
+	}
+	return func(ordinal int64) ([]ExplorerTransaction, error) {
+		snapshotBlocks, err := blocks.GetSnapshotBlocks(ordinal)
+		if err != nil {
+			return nil, err
+		}
+		var deposits []ExplorerTransaction
+		for _, block := range snapshotBlocks {
+			txs, err := blocks.GetBlockTransactions(block.Hash)
+			if err != nil {
+				return nil, err
+			}
+			for _, tx := range txs {
+				if watched[tx.Destination] {
+					deposits = append(deposits, tx)
+				}
+			}
+		}
+		return deposits, nil
+	}
+}
+
+// backfillHistory reads each watched address's history and groups the
+// deposits to it by snapshot ordinal. A history that fills HistoryLimit
+// without reaching back to from is incomplete.
+func backfillHistory(config BackfillConfig, watched map[string]bool, from int64) (map[int64][]ExplorerTransaction, error) {
+	byOrdinal := map[int64][]ExplorerTransaction{}
+	for address := range watched {
+		history, err := config.Explorer.GetTransactionsByAddress(address, config.HistoryLimit)
+		if err != nil {
+			return nil, err
+		}
+		oldest := int64(-1)
+		for _, tx := range history {
+			if oldest < 0 || tx.SnapshotOrdinal < oldest {
+				oldest = tx.SnapshotOrdinal
+			}
+			if tx.Destination != address || tx.SnapshotOrdinal < from || tx.SnapshotOrdinal > config.ToOrdinal {
+				continue
+			}
+			byOrdinal[tx.SnapshotOrdinal] = append(byOrdinal[tx.SnapshotOrdinal], tx)
+		}
+		if len(history) >= config.HistoryLimit && oldest >= from {
+			return nil, fmt.Errorf("%w: history of %s does not reach ordinal %d", ErrBackfillIncomplete, RedactAddress(address), from)
+		}
+	}
+	return byOrdinal, nil
+}
@@ -0,0 +1,149 @@
+package constellation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfill(t *testing.T) {
+	alice, err := GenerateKeyPair()
+	require.NoError(t, err)
+	bob, err := GenerateKeyPair()
+	require.NoError(t, err)
+	carol, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	newExplorer := func() *fakeExplorer {
+		toAlice := ExplorerTransaction{Hash: "tx1", Source: carol.Address, Destination: alice.Address, Amount: 100, SnapshotOrdinal: 10}
+		toBob := ExplorerTransaction{Hash: "tx2", Source: carol.Address, Destination: bob.Address, Amount: 200, SnapshotOrdinal: 11}
+		fromAlice := ExplorerTransaction{Hash: "tx3", Source: alice.Address, Destination: carol.Address, Amount: 50, SnapshotOrdinal: 12}
+		toAliceAgain := ExplorerTransaction{Hash: "tx4", Source: bob.Address, Destination: alice.Address, Amount: 300, SnapshotOrdinal: 12}
+		return &fakeExplorer{
+			transactions: map[string][]ExplorerTransaction{
+				alice.Address: {toAliceAgain, fromAlice, toAlice},
+				bob.Address:   {toBob},
+			},
+			snapshots: map[int64]*ExplorerSnapshot{
+				9:  {Hash: "s9", Ordinal: 9},
+				10: {Hash: "s10", Ordinal: 10},
+				11: {Hash: "s11", Ordinal: 11},
+				12: {Hash: "s12", Ordinal: 12},
+			},
+			blocks: map[int64][]ExplorerBlock{
+				10: {{Hash: "b1", Transactions: []string{"tx1"}}},
+				11: {{Hash: "b2", Transactions: []string{"tx2"}}},
+				12: {{Hash: "b3", Transactions: []string{"tx3", "tx4"}}},
+			},
+			latest: 12,
+		}
+	}
+	summarize := func(events []Event) []string {
+		var summary []string
+		for _, event := range events {
+			switch e := event.(type) {
+			case DepositDetected:
+				summary = append(summary, e.Hash)
+			case SnapshotAdvanced:
+				summary = append(summary, e.Hash)
+			}
+		}
+		return summary
+	}
+
+	for name, explorer := range map[string]ExplorerAPI{
+		"walks snapshot blocks": newExplorer(),
+		"reads address history": struct{ ExplorerAPI }{newExplorer()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var events []Event
+			result, err := Backfill(context.Background(), BackfillConfig{
+				Explorer:    explorer,
+				Addresses:   []string{alice.Address},
+				FromOrdinal: 10,
+				Handler:     func(e Event) { events = append(events, e) },
+			})
+			require.NoError(t, err)
+			assert.Equal(t, &BackfillResult{FromOrdinal: 10, ToOrdinal: 12, Snapshots: 3, Deposits: 2}, result)
+			assert.Equal(t, []string{"tx1", "s10", "s11", "tx4", "s12"}, summarize(events))
+			assert.Equal(t, DepositDetected{Hash: "tx1", Source: carol.Address, Destination: alice.Address, Amount: 100, Ordinal: 10}, events[0])
+		})
+	}
+
+	t.Run("resumes after its checkpoint", func(t *testing.T) {
+		explorer := newExplorer()
+		store := NewMemoryCheckpointStore()
+		config := BackfillConfig{
+			Explorer:       explorer,
+			Addresses:      []string{alice.Address, bob.Address},
+			FromOrdinal:    9,
+			ToOrdinal:      11,
+			Checkpoints:    store,
+			CheckpointName: "backfill",
+		}
+		var events []Event
+		config.Handler = func(e Event) { events = append(events, e) }
+
+		result, err := Backfill(context.Background(), config)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Snapshots)
+		assert.Equal(t, []string{"s9", "tx1", "s10", "tx2", "s11"}, summarize(events))
+		checkpoint, err := store.Load("backfill")
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), checkpoint.Ordinal)
+
+		events = nil
+		config.ToOrdinal = 0
+		result, err = Backfill(context.Background(), config)
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), result.FromOrdinal)
+		assert.Equal(t, []string{"tx4", "s12"}, summarize(events))
+
+		events = nil
+		result, err = Backfill(context.Background(), config)
+		require.NoError(t, err)
+		assert.Zero(t, result.Snapshots)
+		assert.Empty(t, events)
+	})
+
+	t.Run("stops at missing history", func(t *testing.T) {
+		explorer := newExplorer()
+		var events []Event
+		result, err := Backfill(context.Background(), BackfillConfig{
+			Explorer:    explorer,
+			Addresses:   []string{alice.Address},
+			FromOrdinal: 12,
+			ToOrdinal:   13,
+			Handler:     func(e Event) { events = append(events, e) },
+		})
+		assert.ErrorIs(t, err, ErrBackfillIncomplete)
+		assert.Equal(t, int64(12), result.ToOrdinal)
+		assert.Len(t, events, 2)
+
+		_, err = Backfill(context.Background(), BackfillConfig{
+			Explorer:     struct{ ExplorerAPI }{explorer},
+			Addresses:    []string{alice.Address},
+			FromOrdinal:  10,
+			HistoryLimit: 2,
+			Handler:      func(Event) {},
+		})
+		assert.ErrorIs(t, err, ErrBackfillIncomplete)
+	})
+
+	t.Run("validates its config", func(t *testing.T) {
+		explorer := newExplorer()
+		handler := func(Event) {}
+		for _, config := range []BackfillConfig{
+			{Addresses: []string{alice.Address}, Handler: handler},
+			{Explorer: explorer, Addresses: []string{alice.Address}},
+			{Explorer: explorer, Handler: handler},
+			{Explorer: explorer, Addresses: []string{alice.Address}, Handler: handler, FromOrdinal: 5, ToOrdinal: 4},
+			{Explorer: explorer, Addresses: []string{alice.Address}, Handler: handler, Checkpoints: NewMemoryCheckpointStore()},
+		} {
+			_, err := Backfill(context.Background(), config)
+			assert.ErrorIs(t, err, ErrInvalidBackfill)
+		}
+	})
+}
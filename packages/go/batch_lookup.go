@@ -0,0 +1,36 @@
+package constellation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchLookupError reports the addresses a bulk lookup could not fetch. The
+// lookup's result map still holds every address that succeeded.
+type BatchLookupError struct {
+	// Op names the lookup, e.g. "balance"
+	Op string
+	// Errors maps each failed address to its error
+	Errors map[string]error
+}
+
+func (e *BatchLookupError) Error() string {
+	addresses := e.Addresses()
+	first := addresses[0]
+	msg := fmt.Sprintf("%s lookup failed for %d address(es): %s: %v", e.Op, len(addresses), first, e.Errors[first])
+	if len(addresses) > 1 {
+		msg += fmt.Sprintf(" (and %s)", strings.Join(addresses[1:], ", "))
+	}
+	return msg
+}
+
+// Addresses returns the failed addresses in sorted order
+func (e *BatchLookupError) Addresses() []string {
+	addresses := make([]string, 0, len(e.Errors))
+	for address := range e.Errors {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
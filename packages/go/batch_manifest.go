@@ -0,0 +1,116 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrManifestSignatureInvalid indicates a manifest with a missing or bad signature
+	ErrManifestSignatureInvalid = errors.New("batch manifest signature is invalid")
+	// ErrManifestUntrustedSigner indicates a manifest not signed by any
+	// trusted ops key, or no trusted keys given
+	ErrManifestUntrustedSigner = errors.New("batch manifest is not signed by a trusted key")
+	// ErrManifestMismatch indicates a manifest that does not describe the batch
+	ErrManifestMismatch = errors.New("batch does not match its manifest")
+)
+
+// BatchManifestEntry describes one transaction of a payout batch
+type BatchManifestEntry struct {
+	Hash        string `json:"hash"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	// Amount in smallest units (1e-8)
+	Amount int64 `json:"amount"`
+	// Fee in smallest units (1e-8)
+	Fee int64 `json:"fee"`
+	// Ordinal is the transaction's position in the source's chain
+	Ordinal int `json:"ordinal"`
+}
+
+// BatchManifest lists the transactions of a payout batch with their totals.
+// Signed by an ops key when the batch is approved, it lets reconciliation
+// prove the submitted batch is the approved one.
+type BatchManifest struct {
+	// BatchID is the caller's reference for the batch
+	BatchID      string               `json:"batchId"`
+	Transactions []BatchManifestEntry `json:"transactions"`
+	Count        int                  `json:"count"`
+	// TotalAmount in smallest units (1e-8)
+	TotalAmount int64 `json:"totalAmount"`
+	// TotalFee in smallest units (1e-8)
+	TotalFee int64 `json:"totalFee"`
+}
+
+// NewBatchManifest builds the manifest of a batch of signed transactions
+func NewBatchManifest(batchID string, transactions []*CurrencyTransaction) *BatchManifest {
+	manifest := &BatchManifest{
+		BatchID:      batchID,
+		Transactions: make([]BatchManifestEntry, 0, len(transactions)),
+		Count:        len(transactions),
+	}
+	for _, tx := range transactions {
+		manifest.Transactions = append(manifest.Transactions, BatchManifestEntry{
+			Hash:        HashCurrencyTransaction(tx).Value,
+			Source:      tx.Value.Source,
+			Destination: tx.Value.Destination,
+			Amount:      tx.Value.Amount,
+			Fee:         tx.Value.Fee,
+			Ordinal:     tx.Value.Parent.Ordinal + 1,
+		})
+		manifest.TotalAmount += tx.Value.Amount
+		manifest.TotalFee += tx.Value.Fee
+	}
+	return manifest
+}
+
+// SignBatchManifest builds and signs the manifest of a batch with an ops key
+//
+// Example:
+//
+//	batch, _ := CreateCurrencyTransactionBatch(transfers, hotWallet.PrivateKey, lastRef)
+//	manifest, err := SignBatchManifest("payout-2024-06-01", batch, opsKey)
+//	// store manifest with the approval; later:
+//	err = VerifyBatchManifest(manifest, batch, []string{opsAddress})
+func SignBatchManifest(batchID string, transactions []*CurrencyTransaction, opsPrivateKey string) (*Signed[BatchManifest], error) {
+	return CreateSignedObject(*NewBatchManifest(batchID, transactions), opsPrivateKey, false)
+}
+
+// VerifyBatchManifest checks that a manifest's signatures are valid, that at
+// least one was made by a trusted ops key (by DAG address), and that
+// transactions are exactly the batch it describes, in order. An empty
+// trustedSigners returns ErrManifestUntrustedSigner: anyone can sign a
+// manifest, so a signature alone proves nothing.
+func VerifyBatchManifest(signed *Signed[BatchManifest], transactions []*CurrencyTransaction, trustedSigners []string) error {
+	if signed == nil || !Verify(signed, false).IsValid {
+		return ErrManifestSignatureInvalid
+	}
+
+	if len(trustedSigners) == 0 {
+		return fmt.Errorf("%w: no trusted signers given", ErrManifestUntrustedSigner)
+	}
+	if !signedByAny(signed.Proofs, trustedSigners) {
+		return ErrManifestUntrustedSigner
+	}
+
+	manifest := signed.Value
+	actual := NewBatchManifest(manifest.BatchID, transactions)
+	switch {
+	case manifest.Count != len(manifest.Transactions):
+		return fmt.Errorf("%w: manifest lists %d transactions but counts %d", ErrManifestMismatch, len(manifest.Transactions), manifest.Count)
+	case actual.Count != manifest.Count:
+		return fmt.Errorf("%w: batch has %d transactions, manifest %d", ErrManifestMismatch, actual.Count, manifest.Count)
+	}
+	var totalAmount, totalFee int64
+	for i, entry := range manifest.Transactions {
+		if actual.Transactions[i] != entry {
+			return fmt.Errorf("%w: transaction %d is %s, manifest lists %s", ErrManifestMismatch, i, actual.Transactions[i].Hash, entry.Hash)
+		}
+		totalAmount += entry.Amount
+		totalFee += entry.Fee
+	}
+	if totalAmount != manifest.TotalAmount || totalFee != manifest.TotalFee {
+		return fmt.Errorf("%w: totals do not add up", ErrManifestMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package constellation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchManifest(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	ops, err := GenerateKeyPair()
+	require.NoError(t, err)
+	alice, _ := GenerateKeyPair()
+
+	batch, err := CreateCurrencyTransactionBatch([]TransferParams{
+		{Destination: alice.Address, Amount: 10, Fee: 0.1},
+		{Destination: alice.Address, Amount: 20},
+	}, hot.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+
+	signed, err := SignBatchManifest("payout-1", batch, ops.PrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, 2, signed.Value.Count)
+	assert.Equal(t, TokenToUnits(30), signed.Value.TotalAmount)
+	assert.Equal(t, TokenToUnits(0.1), signed.Value.TotalFee)
+	assert.Equal(t, 2, signed.Value.Transactions[1].Ordinal)
+
+	t.Run("accepts the approved batch", func(t *testing.T) {
+		assert.NoError(t, VerifyBatchManifest(signed, batch, []string{ops.Address}))
+		assert.ErrorIs(t, VerifyBatchManifest(signed, batch, nil), ErrManifestUntrustedSigner, "a signature alone is not trusted")
+	})
+
+	t.Run("rejects an untrusted signer", func(t *testing.T) {
+		assert.ErrorIs(t, VerifyBatchManifest(signed, batch, []string{alice.Address}), ErrManifestUntrustedSigner)
+	})
+
+	t.Run("rejects an edited manifest", func(t *testing.T) {
+		edited := *signed
+		edited.Value.TotalAmount++
+		assert.ErrorIs(t, VerifyBatchManifest(&edited, batch, nil), ErrManifestSignatureInvalid)
+	})
+
+	t.Run("rejects an altered batch", func(t *testing.T) {
+		altered, err := CreateCurrencyTransactionBatch([]TransferParams{
+			{Destination: alice.Address, Amount: 10, Fee: 0.1},
+			{Destination: ops.Address, Amount: 20},
+		}, hot.PrivateKey, GenesisReference)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifyBatchManifest(signed, altered, []string{ops.Address}), ErrManifestMismatch)
+		assert.ErrorIs(t, VerifyBatchManifest(signed, batch[:1], []string{ops.Address}), ErrManifestMismatch)
+	})
+}
@@ -0,0 +1,171 @@
+package constellation
+
+import (
+	"testing"
+)
+
+// Benchmarks for the transaction hot path. Run with:
+//
+//	go test -run '^$' -bench . -benchmem
+//
+// Baselines are recorded in BENCHMARKS.md; compare against them with benchstat
+// before merging changes to encoding, hashing or signing.
+
+var (
+	benchKeyPair *KeyPair
+	benchTx      *CurrencyTransaction
+	benchLastRef = TransactionReference{
+		Hash:    "a1b2c3d4e5f6789012345678901234567890123456789012345678901234abcd",
+		Ordinal: 5,
+	}
+	benchData = map[string]interface{}{"id": "bench", "value": 42, "tags": []string{"a", "b"}}
+)
+
+func benchSetup(b *testing.B) (*KeyPair, *CurrencyTransaction) {
+	b.Helper()
+	if benchKeyPair == nil {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			b.Fatal(err)
+		}
+		dest, err := GenerateKeyPair()
+		if err != nil {
+			b.Fatal(err)
+		}
+		tx, err := CreateCurrencyTransaction(TransferParams{Destination: dest.Address, Amount: 100, Fee: 0}, kp.PrivateKey, benchLastRef)
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchKeyPair, benchTx = kp, tx
+	}
+	return benchKeyPair, benchTx
+}
+
+func BenchmarkCreateCurrencyTransaction(b *testing.B) {
+	kp, tx := benchSetup(b)
+	params := TransferParams{Destination: tx.Value.Destination, Amount: 100}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CreateCurrencyTransaction(params, kp.PrivateKey, benchLastRef); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeCurrencyTransaction(b *testing.B) {
+	_, tx := benchSetup(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EncodeCurrencyTransaction(tx)
+	}
+}
+
+func BenchmarkHashCurrencyTransaction(b *testing.B) {
+	_, tx := benchSetup(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		HashCurrencyTransaction(tx)
+	}
+}
+
+func BenchmarkSignHash(b *testing.B) {
+	kp, tx := benchSetup(b)
+	hash := HashCurrencyTransaction(tx).Value
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := SignHash(hash, kp.PrivateKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifyCurrencyTransaction(b *testing.B) {
+	_, tx := benchSetup(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !VerifyCurrencyTransaction(tx).IsValid {
+			b.Fatal("signature did not verify")
+		}
+	}
+}
+
+func BenchmarkCreateCurrencyTransactionBatch(b *testing.B) {
+	kp, tx := benchSetup(b)
+	transfers := make([]TransferParams, 100)
+	for i := range transfers {
+		transfers[i] = TransferParams{Destination: tx.Value.Destination, Amount: 1}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CreateCurrencyTransactionBatch(transfers, kp.PrivateKey, benchLastRef); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSignData(b *testing.B) {
+	kp, _ := benchSetup(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Sign(benchData, kp.PrivateKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifySignedObject(b *testing.B) {
+	kp, _ := benchSetup(b)
+	signed, err := CreateSignedObject(benchData, kp.PrivateKey, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !Verify(signed, false).IsValid {
+			b.Fatal("signature did not verify")
+		}
+	}
+}
+
+func BenchmarkVerifyScratchCurrencyTransaction(b *testing.B) {
+	_, tx := benchSetup(b)
+	scratch := NewVerifyScratch()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !scratch.VerifyCurrencyTransaction(tx).IsValid {
+			b.Fatal("signature did not verify")
+		}
+	}
+}
+
+func BenchmarkVerifyCurrencyTransactionStrictGarbage(b *testing.B) {
+	_, tx := benchSetup(b)
+	garbage := &CurrencyTransaction{Value: tx.Value, Proofs: []SignatureProof{{ID: tx.Proofs[0].ID, Signature: "deadbeef"}}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if VerifyCurrencyTransactionStrict(garbage) {
+			b.Fatal("garbage accepted")
+		}
+	}
+}
+
+func BenchmarkIsValidDAGAddress(b *testing.B) {
+	kp, _ := benchSetup(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !IsValidDAGAddress(kp.Address) {
+			b.Fatal("address rejected")
+		}
+	}
+}
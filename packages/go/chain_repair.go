@@ -0,0 +1,201 @@
+package constellation
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrRepairKeyMismatch indicates the repair key does not own the diagnosed address
+var ErrRepairKeyMismatch = errors.New("private key does not match the diagnosed address")
+
+// ChainTxStatus classifies a submitted transaction against the node's view
+// of its address's chain
+type ChainTxStatus string
+
+const (
+	// ChainAccepted means the node's last reference is at or past the
+	// transaction's ordinal and, at that ordinal, is this transaction
+	ChainAccepted ChainTxStatus = "Accepted"
+	// ChainPending means the transaction is in the mempool and links,
+	// possibly through other pending transactions, to the last reference
+	ChainPending ChainTxStatus = "Pending"
+	// ChainOrphaned means the transaction is in the mempool but its parent
+	// is neither accepted nor pending, so it can never be accepted
+	ChainOrphaned ChainTxStatus = "Orphaned"
+	// ChainDropped means the node knows nothing of the transaction
+	ChainDropped ChainTxStatus = "Dropped"
+	// ChainConflict means a different transaction took the ordinal; whether
+	// this transfer happened needs a human decision
+	ChainConflict ChainTxStatus = "Conflict"
+)
+
+// ChainTxDiagnosis is the status of one submitted transaction
+type ChainTxDiagnosis struct {
+	Transaction *CurrencyTransaction
+	Hash        string
+	Ordinal     int
+	Status      ChainTxStatus
+}
+
+// ChainDiagnosis describes an address's transaction chain as seen by a node
+type ChainDiagnosis struct {
+	Address string
+	// LastReference is the node's last accepted reference for Address
+	LastReference TransactionReference
+	// Tip is the end of the pending chain that links to LastReference; new
+	// and repair transactions must chain from it
+	Tip TransactionReference
+	// Transactions are the submitted transactions in ordinal order
+	Transactions []ChainTxDiagnosis
+	// MissingOrdinals are ordinals after LastReference, up to the highest
+	// submitted one, that no submitted transaction occupies
+	MissingOrdinals []int
+}
+
+// Healthy reports whether every submitted transaction is accepted or pending
+func (d *ChainDiagnosis) Healthy() bool {
+	for _, tx := range d.Transactions {
+		if tx.Status != ChainAccepted && tx.Status != ChainPending {
+			return false
+		}
+	}
+	return len(d.MissingOrdinals) == 0
+}
+
+// NeedsRepair returns the orphaned and dropped transactions, whose transfers
+// have to be re-signed for the chain to move on. Conflicts are excluded;
+// resolve them by hand.
+func (d *ChainDiagnosis) NeedsRepair() []ChainTxDiagnosis {
+	var repair []ChainTxDiagnosis
+	for _, tx := range d.Transactions {
+		if tx.Status == ChainOrphaned || tx.Status == ChainDropped {
+			repair = append(repair, tx)
+		}
+	}
+	return repair
+}
+
+// DiagnoseChain inspects the transactions an address submitted, e.g. the
+// output of a batch that partly failed, against the node's last reference
+// and mempool. It finds missing ordinals, orphaned parents and dropped
+// transactions.
+//
+// Transactions from other addresses are ignored.
+//
+// Example:
+//
+//	diagnosis, err := DiagnoseChain(client, wallet.Address, batch)
+//	if err != nil {
+//	    return err
+//	}
+//	if !diagnosis.Healthy() {
+//	    repairs, err := BuildChainRepair(diagnosis, wallet.PrivateKey)
+//	    // review, then submit repairs in order
+//	}
+func DiagnoseChain(l1 CurrencyL1API, address string, submitted []*CurrencyTransaction) (*ChainDiagnosis, error) {
+	lastRef, err := l1.GetLastReference(address)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis := &ChainDiagnosis{Address: address, LastReference: *lastRef, Tip: *lastRef}
+
+	pending := map[string]bool{}
+	for _, tx := range submitted {
+		if tx.Value.Source != address {
+			continue
+		}
+		entry := ChainTxDiagnosis{
+			Transaction: tx,
+			Hash:        HashCurrencyTransaction(tx).Value,
+			Ordinal:     tx.Value.Parent.Ordinal + 1,
+		}
+
+		switch {
+		case entry.Ordinal < lastRef.Ordinal:
+			// the node only reports its head; trust earlier ordinals
+			entry.Status = ChainAccepted
+		case entry.Ordinal == lastRef.Ordinal && entry.Hash == lastRef.Hash:
+			entry.Status = ChainAccepted
+		case entry.Ordinal == lastRef.Ordinal:
+			entry.Status = ChainConflict
+		default:
+			found, err := l1.GetPendingTransaction(entry.Hash)
+			if err != nil {
+				return nil, err
+			}
+			if found == nil {
+				entry.Status = ChainDropped
+			} else {
+				entry.Status = ChainOrphaned
+				pending[entry.Hash] = true
+			}
+		}
+		diagnosis.Transactions = append(diagnosis.Transactions, entry)
+	}
+
+	sort.SliceStable(diagnosis.Transactions, func(i, j int) bool {
+		return diagnosis.Transactions[i].Ordinal < diagnosis.Transactions[j].Ordinal
+	})
+
+	// walk the pending chain forward from the last reference
+	for linked := true; linked; {
+		linked = false
+		for i := range diagnosis.Transactions {
+			tx := &diagnosis.Transactions[i]
+			if tx.Status == ChainOrphaned && tx.Transaction.Value.Parent == diagnosis.Tip {
+				tx.Status = ChainPending
+				diagnosis.Tip = TransactionReference{Hash: tx.Hash, Ordinal: tx.Ordinal}
+				linked = true
+			}
+		}
+	}
+
+	occupied := map[int]bool{}
+	highest := lastRef.Ordinal
+	for _, tx := range diagnosis.Transactions {
+		if tx.Status != ChainDropped {
+			occupied[tx.Ordinal] = true
+		}
+		if tx.Ordinal > highest {
+			highest = tx.Ordinal
+		}
+	}
+	for ordinal := lastRef.Ordinal + 1; ordinal <= highest; ordinal++ {
+		if !occupied[ordinal] {
+			diagnosis.MissingOrdinals = append(diagnosis.MissingOrdinals, ordinal)
+		}
+	}
+
+	return diagnosis, nil
+}
+
+// BuildChainRepair re-signs the transfers of every orphaned and dropped
+// transaction, in their original order, chained from the diagnosis Tip.
+// Destinations, amounts and fees are kept; salts are new, so the repair
+// transactions have new hashes.
+//
+// Returns ErrRepairKeyMismatch if privateKeyHex does not own the address.
+func BuildChainRepair(diagnosis *ChainDiagnosis, privateKeyHex string) ([]*CurrencyTransaction, error) {
+	keyPair, err := KeyPairFromPrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if keyPair.Address != diagnosis.Address {
+		return nil, ErrRepairKeyMismatch
+	}
+
+	repairs := diagnosis.NeedsRepair()
+	transactions := make([]*CurrencyTransaction, 0, len(repairs))
+	parent := diagnosis.Tip
+	for _, repair := range repairs {
+		value := repair.Transaction.Value
+		tx, err := createCurrencyTransactionUnits(value.Destination, value.Amount, value.Fee, "", privateKeyHex, parent)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, tx)
+		parent = *GetTransactionReference(tx, parent.Ordinal+1)
+	}
+	return transactions, nil
+}
@@ -0,0 +1,100 @@
+package constellation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mempoolL1 serves a fixed last reference and mempool
+type mempoolL1 struct {
+	CurrencyL1API
+	last    TransactionReference
+	mempool map[string]bool
+}
+
+func (m *mempoolL1) GetLastReference(address string) (*TransactionReference, error) {
+	ref := m.last
+	return &ref, nil
+}
+
+func (m *mempoolL1) GetPendingTransaction(hash string) (*PendingTransaction, error) {
+	if !m.mempool[hash] {
+		return nil, nil
+	}
+	return &PendingTransaction{Hash: hash, Status: StatusWaiting}, nil
+}
+
+func TestChainRepair(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	genesis := TransactionReference{Hash: "0000000000000000000000000000000000000000000000000000000000000000", Ordinal: 4}
+	transfers := []TransferParams{
+		{Destination: destination.Address, Amount: 1},
+		{Destination: destination.Address, Amount: 2},
+		{Destination: destination.Address, Amount: 3},
+		{Destination: destination.Address, Amount: 4},
+	}
+	batch, err := CreateCurrencyTransactionBatch(transfers, keyPair.PrivateKey, genesis)
+	require.NoError(t, err)
+	hashes := make([]string, len(batch))
+	for i, tx := range batch {
+		hashes[i] = HashCurrencyTransaction(tx).Value
+	}
+
+	t.Run("healthy chain", func(t *testing.T) {
+		l1 := &mempoolL1{last: TransactionReference{Hash: hashes[0], Ordinal: 5}, mempool: map[string]bool{hashes[1]: true, hashes[2]: true, hashes[3]: true}}
+		diagnosis, err := DiagnoseChain(l1, keyPair.Address, batch)
+		require.NoError(t, err)
+		assert.True(t, diagnosis.Healthy())
+		assert.Equal(t, ChainAccepted, diagnosis.Transactions[0].Status)
+		assert.Equal(t, TransactionReference{Hash: hashes[3], Ordinal: 8}, diagnosis.Tip)
+		assert.Empty(t, diagnosis.NeedsRepair())
+	})
+
+	t.Run("dropped middle orphans the rest", func(t *testing.T) {
+		l1 := &mempoolL1{last: genesis, mempool: map[string]bool{hashes[0]: true, hashes[2]: true, hashes[3]: true}}
+		diagnosis, err := DiagnoseChain(l1, keyPair.Address, batch)
+		require.NoError(t, err)
+		assert.False(t, diagnosis.Healthy())
+
+		statuses := []ChainTxStatus{}
+		for _, tx := range diagnosis.Transactions {
+			statuses = append(statuses, tx.Status)
+		}
+		assert.Equal(t, []ChainTxStatus{ChainPending, ChainDropped, ChainOrphaned, ChainOrphaned}, statuses)
+		assert.Equal(t, []int{6}, diagnosis.MissingOrdinals)
+		assert.Equal(t, TransactionReference{Hash: hashes[0], Ordinal: 5}, diagnosis.Tip)
+
+		repairs, err := BuildChainRepair(diagnosis, keyPair.PrivateKey)
+		require.NoError(t, err)
+		require.Len(t, repairs, 3)
+		parent := diagnosis.Tip
+		for i, tx := range repairs {
+			assert.Equal(t, parent, tx.Value.Parent)
+			assert.Equal(t, TokenToUnits(float64(i+2)), tx.Value.Amount)
+			assert.Equal(t, destination.Address, tx.Value.Destination)
+			assert.True(t, VerifyCurrencyTransaction(tx).IsValid)
+			parent = *GetTransactionReference(tx, parent.Ordinal+1)
+		}
+	})
+
+	t.Run("conflicting ordinal is reported but not repaired", func(t *testing.T) {
+		l1 := &mempoolL1{last: TransactionReference{Hash: "other", Ordinal: 5}}
+		diagnosis, err := DiagnoseChain(l1, keyPair.Address, batch[:1])
+		require.NoError(t, err)
+		assert.Equal(t, ChainConflict, diagnosis.Transactions[0].Status)
+		assert.False(t, diagnosis.Healthy())
+		assert.Empty(t, diagnosis.NeedsRepair())
+	})
+
+	t.Run("rejects a key for another address", func(t *testing.T) {
+		diagnosis := &ChainDiagnosis{Address: destination.Address}
+		_, err := BuildChainRepair(diagnosis, keyPair.PrivateKey)
+		assert.ErrorIs(t, err, ErrRepairKeyMismatch)
+	})
+}
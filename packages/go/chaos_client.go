@@ -0,0 +1,209 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidChaosConfig indicates a fault probability outside 0 to 1
+	ErrInvalidChaosConfig = errors.New("invalid chaos config")
+	// ErrChaosClientRequired indicates NewChaosClient was given no client
+	// to wrap
+	ErrChaosClientRequired = errors.New("chaos client requires a client to wrap")
+)
+
+// chaosServerErrorStatus is the status code of injected server errors
+const chaosServerErrorStatus = 503
+
+// ChaosConfig sets which faults a ChaosClient injects. Each rate is the
+// probability, from 0 to 1, that a call draws the fault; every fault is
+// drawn independently. A call drawing both a timeout and a server error
+// times out, and a failing call is not duplicated.
+type ChaosConfig struct {
+	// LatencyRate delays calls by a random duration up to Latency
+	LatencyRate float64
+	Latency     time.Duration
+	// TimeoutRate fails calls with ErrRequestTimeout after blocking for
+	// Timeout (default: fail immediately)
+	TimeoutRate float64
+	Timeout     time.Duration
+	// DeliverOnTimeout still delivers timed-out transactions to the node,
+	// as when only the response is lost. This is the case idempotent retry
+	// logic must handle.
+	DeliverOnTimeout bool
+	// ServerErrorRate fails calls with a 503 NetworkError without reaching
+	// the node
+	ServerErrorRate float64
+	// DuplicateRate delivers a transaction to the node twice; it applies to
+	// PostTransaction only
+	DuplicateRate float64
+	// Seed makes the faults reproducible (default: seeded from the clock)
+	Seed int64
+}
+
+// ChaosStats counts the faults a ChaosClient has injected. A fault drawn
+// but overridden, such as a server error on a call that timed out, is not
+// counted.
+type ChaosStats struct {
+	Calls        int
+	Delayed      int
+	TimedOut     int
+	ServerErrors int
+	Duplicates   int
+}
+
+// ChaosClient is a CurrencyL1API decorator that injects latency, timeouts,
+// server errors and duplicate deliveries, for testing retry and idempotency
+// logic against realistic failures. It wraps any CurrencyL1API, including a
+// SimulatedLedger, and is safe for concurrent use.
+//
+// Example:
+//
+//	chaos, err := NewChaosClient(ledger, ChaosConfig{
+//	    TimeoutRate:      0.1,
+//	    DeliverOnTimeout: true,
+//	    ServerErrorRate:  0.05,
+//	    DuplicateRate:    0.05,
+//	    Seed:             42,
+//	})
+//	queue, err := NewWithdrawalQueue(ctx, WithdrawalQueueConfig{L1: chaos, ...})
+type ChaosClient struct {
+	inner  CurrencyL1API
+	config ChaosConfig
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	stats ChaosStats
+}
+
+// chaosFault is the outcome drawn for one call
+type chaosFault struct {
+	delay       time.Duration
+	timeout     bool
+	serverError bool
+	duplicate   bool
+}
+
+// NewChaosClient wraps inner with fault injection
+func NewChaosClient(inner CurrencyL1API, config ChaosConfig) (*ChaosClient, error) {
+	if inner == nil {
+		return nil, ErrChaosClientRequired
+	}
+	rates := map[string]float64{
+		"LatencyRate":     config.LatencyRate,
+		"TimeoutRate":     config.TimeoutRate,
+		"ServerErrorRate": config.ServerErrorRate,
+		"DuplicateRate":   config.DuplicateRate,
+	}
+	for name, rate := range rates {
+		if !(rate >= 0 && rate <= 1) {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidChaosConfig, name, rate)
+		}
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosClient{inner: inner, config: config, rng: rand.New(rand.NewSource(seed))}, nil
+}
+
+// Stats returns the faults injected so far
+func (c *ChaosClient) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// GetLastReference calls the wrapped client, subject to latency, timeouts
+// and server errors
+func (c *ChaosClient) GetLastReference(address string) (*TransactionReference, error) {
+	if err := c.before(c.draw(false)); err != nil {
+		return nil, err
+	}
+	return c.inner.GetLastReference(address)
+}
+
+// GetPendingTransaction calls the wrapped client, subject to latency,
+// timeouts and server errors
+func (c *ChaosClient) GetPendingTransaction(hash string) (*PendingTransaction, error) {
+	if err := c.before(c.draw(false)); err != nil {
+		return nil, err
+	}
+	return c.inner.GetPendingTransaction(hash)
+}
+
+// PostTransaction calls the wrapped client, subject to every fault. A
+// duplicated transaction is posted twice and the first response returned.
+func (c *ChaosClient) PostTransaction(transaction *CurrencyTransaction) (*PostTransactionResponse, error) {
+	fault := c.draw(true)
+	err := c.before(fault)
+	if errors.Is(err, ErrRequestTimeout) && c.config.DeliverOnTimeout {
+		_, _ = c.inner.PostTransaction(transaction)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.inner.PostTransaction(transaction)
+	if fault.duplicate {
+		_, _ = c.inner.PostTransaction(transaction)
+	}
+	return response, err
+}
+
+// draw picks the faults for one call and counts those that apply; only
+// posts, which deliver a transaction, can be duplicated
+func (c *ChaosClient) draw(post bool) chaosFault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var fault chaosFault
+	c.stats.Calls++
+	delayed := c.hit(c.config.LatencyRate)
+	timeout := c.hit(c.config.TimeoutRate)
+	serverError := c.hit(c.config.ServerErrorRate)
+	duplicate := post && c.hit(c.config.DuplicateRate)
+
+	if delayed && c.config.Latency > 0 {
+		fault.delay = time.Duration(c.rng.Int63n(int64(c.config.Latency)) + 1)
+		c.stats.Delayed++
+	}
+	switch {
+	case timeout:
+		fault.timeout = true
+		c.stats.TimedOut++
+	case serverError:
+		fault.serverError = true
+		c.stats.ServerErrors++
+	case duplicate:
+		fault.duplicate = true
+		c.stats.Duplicates++
+	}
+	return fault
+}
+
+// hit draws a fault with probability rate; callers hold c.mu
+func (c *ChaosClient) hit(rate float64) bool {
+	return rate > 0 && c.rng.Float64() < rate
+}
+
+// before applies the delay and returns the injected error, if any
+func (c *ChaosClient) before(fault chaosFault) error {
+	if fault.delay > 0 {
+		time.Sleep(fault.delay)
+	}
+	switch {
+	case fault.timeout:
+		if c.config.Timeout > 0 {
+			time.Sleep(c.config.Timeout)
+		}
+		return ErrRequestTimeout
+	case fault.serverError:
+		return NewNetworkError("chaos: injected server error", chaosServerErrorStatus, "")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package constellation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingL1 counts the transactions that reach the ledger
+type countingL1 struct {
+	*SimulatedLedger
+	posts int
+}
+
+func (c *countingL1) PostTransaction(tx *CurrencyTransaction) (*PostTransactionResponse, error) {
+	c.posts++
+	return c.SimulatedLedger.PostTransaction(tx)
+}
+
+func newChaosTestTransaction(t *testing.T, ledger *SimulatedLedger) *CurrencyTransaction {
+	t.Helper()
+	source, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, _ := GenerateKeyPair()
+	require.NoError(t, ledger.Fund(source.Address, 10))
+
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: destination.Address, Amount: 1}, source.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+	return tx
+}
+
+func TestChaosClient(t *testing.T) {
+	t.Run("rejects invalid rates", func(t *testing.T) {
+		_, err := NewChaosClient(NewSimulatedLedger(), ChaosConfig{TimeoutRate: 1.5})
+		assert.ErrorIs(t, err, ErrInvalidChaosConfig)
+		_, err = NewChaosClient(nil, ChaosConfig{})
+		assert.ErrorIs(t, err, ErrChaosClientRequired)
+	})
+
+	t.Run("passes calls through with no faults", func(t *testing.T) {
+		inner := &countingL1{SimulatedLedger: NewSimulatedLedger()}
+		chaos, err := NewChaosClient(inner, ChaosConfig{})
+		require.NoError(t, err)
+
+		response, err := chaos.PostTransaction(newChaosTestTransaction(t, inner.SimulatedLedger))
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.Hash)
+		assert.Equal(t, 1, inner.posts)
+		assert.Equal(t, ChaosStats{Calls: 1}, chaos.Stats())
+	})
+
+	t.Run("server errors do not reach the node", func(t *testing.T) {
+		inner := &countingL1{SimulatedLedger: NewSimulatedLedger()}
+		chaos, _ := NewChaosClient(inner, ChaosConfig{ServerErrorRate: 1})
+
+		_, err := chaos.PostTransaction(newChaosTestTransaction(t, inner.SimulatedLedger))
+		var networkErr *NetworkError
+		require.True(t, errors.As(err, &networkErr))
+		assert.Equal(t, 503, networkErr.StatusCode)
+		assert.Equal(t, 0, inner.posts)
+		assert.Equal(t, 1, chaos.Stats().ServerErrors)
+	})
+
+	t.Run("timeouts can lose only the response", func(t *testing.T) {
+		inner := &countingL1{SimulatedLedger: NewSimulatedLedger()}
+		chaos, _ := NewChaosClient(inner, ChaosConfig{TimeoutRate: 1, DeliverOnTimeout: true})
+		tx := newChaosTestTransaction(t, inner.SimulatedLedger)
+
+		_, err := chaos.PostTransaction(tx)
+		assert.ErrorIs(t, err, ErrRequestTimeout)
+		assert.Equal(t, 1, inner.posts)
+
+		// the node accepted it despite the error
+		pending, err := inner.GetPendingTransaction(HashCurrencyTransaction(tx).Value)
+		require.NoError(t, err)
+		assert.NotNil(t, pending)
+	})
+
+	t.Run("timeouts block for Timeout", func(t *testing.T) {
+		chaos, _ := NewChaosClient(NewSimulatedLedger(), ChaosConfig{TimeoutRate: 1, Timeout: 20 * time.Millisecond})
+		start := time.Now()
+		_, err := chaos.GetLastReference("DAG0")
+		assert.ErrorIs(t, err, ErrRequestTimeout)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("duplicates deliver twice", func(t *testing.T) {
+		inner := &countingL1{SimulatedLedger: NewSimulatedLedger()}
+		chaos, _ := NewChaosClient(inner, ChaosConfig{DuplicateRate: 1})
+
+		response, err := chaos.PostTransaction(newChaosTestTransaction(t, inner.SimulatedLedger))
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.Hash)
+		assert.Equal(t, 2, inner.posts)
+		assert.Equal(t, 1, chaos.Stats().Duplicates)
+
+		_, err = chaos.GetLastReference("DAG0")
+		require.NoError(t, err)
+		assert.Equal(t, 1, chaos.Stats().Duplicates, "reads are not duplicated")
+	})
+
+	t.Run("draws each fault independently", func(t *testing.T) {
+		chaos, _ := NewChaosClient(NewSimulatedLedger(), ChaosConfig{TimeoutRate: 0.5, ServerErrorRate: 0.5, Seed: 3})
+		for i := 0; i < 2000; i++ {
+			chaos.GetLastReference("DAG0")
+		}
+		stats := chaos.Stats()
+		// timeouts hit half the calls, and server errors half of the rest
+		assert.InDelta(t, 1000, stats.TimedOut, 100)
+		assert.InDelta(t, 500, stats.ServerErrors, 100)
+	})
+
+	t.Run("seeded faults are reproducible", func(t *testing.T) {
+		config := ChaosConfig{ServerErrorRate: 0.5, Seed: 7}
+		outcomes := func() []bool {
+			chaos, _ := NewChaosClient(NewSimulatedLedger(), config)
+			var failed []bool
+			for i := 0; i < 20; i++ {
+				_, err := chaos.GetLastReference("DAG0")
+				failed = append(failed, err != nil)
+			}
+			return failed
+		}
+		first := outcomes()
+		assert.Equal(t, first, outcomes())
+		assert.Contains(t, first, true)
+		assert.Contains(t, first, false)
+	})
+}
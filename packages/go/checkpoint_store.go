@@ -0,0 +1,236 @@
+package constellation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Checkpoint records how far a follower or scanner has processed
+type Checkpoint struct {
+	// Ordinal is the last fully processed snapshot ordinal
+	Ordinal int64 `json:"ordinal"`
+	// Hash is the hash of that snapshot, used to detect forks on resume
+	Hash string `json:"hash"`
+	// UpdatedAt is when the checkpoint was written
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CheckpointStore persists checkpoints per named consumer (e.g., "deposit-scanner")
+// so restarts resume exactly where they left off
+type CheckpointStore interface {
+	// Load returns the checkpoint for name, or nil if none has been saved
+	Load(name string) (*Checkpoint, error)
+	// Save records the checkpoint for name, replacing any previous one
+	Save(name string, checkpoint Checkpoint) error
+}
+
+// ErrInvalidTableName indicates an SQL table name that is not a plain identifier
+var ErrInvalidTableName = errors.New("invalid checkpoint table name")
+
+// MemoryCheckpointStore keeps checkpoints in memory. Useful for tests and
+// short-lived processes.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryCheckpointStore creates an empty MemoryCheckpointStore
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: map[string]Checkpoint{}}
+}
+
+// Load returns the checkpoint for name, or nil if none has been saved
+func (s *MemoryCheckpointStore) Load(name string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint, ok := s.checkpoints[name]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+// Save records the checkpoint for name
+func (s *MemoryCheckpointStore) Save(name string, checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[name] = checkpoint
+	return nil
+}
+
+// FileCheckpointStore keeps all checkpoints in a single JSON file. Writes go
+// to a temporary file that is renamed into place, so a crash mid-write never
+// leaves a truncated checkpoint.
+type FileCheckpointStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileCheckpointStore creates a store backed by the file at path. The file
+// is created on the first Save.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// Load returns the checkpoint for name, or nil if none has been saved
+func (s *FileCheckpointStore) Load(name string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, ok := checkpoints[name]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+// Save records the checkpoint for name
+func (s *FileCheckpointStore) Save(name string, checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return err
+	}
+	checkpoints[name] = checkpoint
+
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileCheckpointStore) read() (map[string]Checkpoint, error) {
+	checkpoints := map[string]Checkpoint{}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoints: %w", err)
+	}
+	return checkpoints, nil
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLCheckpointStore keeps checkpoints in a database table via database/sql.
+// Bring your own driver; the queries use only portable SQL.
+//
+// Table layout:
+//
+//	CREATE TABLE checkpoints (
+//	    name       VARCHAR(255) PRIMARY KEY,
+//	    ordinal    BIGINT       NOT NULL,
+//	    hash       VARCHAR(64)  NOT NULL,
+//	    updated_at TIMESTAMP    NOT NULL
+//	)
+type SQLCheckpointStore struct {
+	db    *sql.DB
+	table string
+	// numbered selects $1-style placeholders (PostgreSQL) instead of ?
+	numbered bool
+}
+
+// NewSQLCheckpointStore creates a store on table. Set numberedPlaceholders for
+// drivers that use $1, $2 (PostgreSQL); leave it false for ? (MySQL, SQLite).
+func NewSQLCheckpointStore(db *sql.DB, table string, numberedPlaceholders bool) (*SQLCheckpointStore, error) {
+	if !sqlIdentifier.MatchString(table) {
+		return nil, ErrInvalidTableName
+	}
+	return &SQLCheckpointStore{db: db, table: table, numbered: numberedPlaceholders}, nil
+}
+
+// CreateTable creates the checkpoint table if it does not exist
+func (s *SQLCheckpointStore) CreateTable() error {
+	_, err := s.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name VARCHAR(255) PRIMARY KEY,
+		ordinal BIGINT NOT NULL,
+		hash VARCHAR(64) NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`, s.table))
+	return err
+}
+
+// Load returns the checkpoint for name, or nil if none has been saved
+func (s *SQLCheckpointStore) Load(name string) (*Checkpoint, error) {
+	query := fmt.Sprintf("SELECT ordinal, hash, updated_at FROM %s WHERE name = %s", s.table, s.bind(1))
+
+	var checkpoint Checkpoint
+	err := s.db.QueryRow(query, name).Scan(&checkpoint.Ordinal, &checkpoint.Hash, &checkpoint.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// Save records the checkpoint for name using update-then-insert in one transaction
+func (s *SQLCheckpointStore) Save(name string, checkpoint Checkpoint) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	update := fmt.Sprintf("UPDATE %s SET ordinal = %s, hash = %s, updated_at = %s WHERE name = %s",
+		s.table, s.bind(1), s.bind(2), s.bind(3), s.bind(4))
+	result, err := tx.Exec(update, checkpoint.Ordinal, checkpoint.Hash, checkpoint.UpdatedAt, name)
+	if err != nil {
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		insert := fmt.Sprintf("INSERT INTO %s (name, ordinal, hash, updated_at) VALUES (%s, %s, %s, %s)",
+			s.table, s.bind(1), s.bind(2), s.bind(3), s.bind(4))
+		if _, err := tx.Exec(insert, name, checkpoint.Ordinal, checkpoint.Hash, checkpoint.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLCheckpointStore) bind(n int) string {
+	if s.numbered {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
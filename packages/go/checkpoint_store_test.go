@@ -0,0 +1,338 @@
+package constellation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCheckpointStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	store := NewFileCheckpointStore(path)
+
+	t.Run("missing checkpoint loads as nil", func(t *testing.T) {
+		checkpoint, err := store.Load("scanner")
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint)
+	})
+
+	t.Run("saved checkpoints survive reopening", func(t *testing.T) {
+		at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		require.NoError(t, store.Save("scanner", Checkpoint{Ordinal: 42, Hash: "abc", UpdatedAt: at}))
+		require.NoError(t, store.Save("follower", Checkpoint{Ordinal: 7, Hash: "def", UpdatedAt: at}))
+		require.NoError(t, store.Save("scanner", Checkpoint{Ordinal: 43, Hash: "abd", UpdatedAt: at}))
+
+		reopened := NewFileCheckpointStore(path)
+		scanner, err := reopened.Load("scanner")
+		require.NoError(t, err)
+		assert.Equal(t, &Checkpoint{Ordinal: 43, Hash: "abd", UpdatedAt: at}, scanner)
+
+		follower, err := reopened.Load("follower")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), follower.Ordinal)
+	})
+
+	t.Run("leaves no temporary files behind", func(t *testing.T) {
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
+
+func TestMemoryCheckpointStore(t *testing.T) {
+	store := NewMemoryCheckpointStore()
+	require.NoError(t, store.Save("scanner", Checkpoint{Ordinal: 1}))
+
+	checkpoint, err := store.Load("scanner")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), checkpoint.Ordinal)
+}
+
+func TestSQLCheckpointStoreRejectsBadTableNames(t *testing.T) {
+	_, err := NewSQLCheckpointStore(nil, "checkpoints; DROP TABLE x", false)
+	assert.ErrorIs(t, err, ErrInvalidTableName)
+
+	store, err := NewSQLCheckpointStore(nil, "checkpoints", true)
+	require.NoError(t, err)
+	assert.Equal(t, "$2", store.bind(2))
+}
+
+func TestSQLCheckpointStore(t *testing.T) {
+	fake := newFakeCheckpointDB()
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	store, err := NewSQLCheckpointStore(db, "checkpoints", false)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateTable())
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("missing checkpoint loads as nil", func(t *testing.T) {
+		checkpoint, err := store.Load("scanner")
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint)
+	})
+
+	t.Run("inserts, then updates in place", func(t *testing.T) {
+		require.NoError(t, store.Save("scanner", Checkpoint{Ordinal: 42, Hash: "abc", UpdatedAt: at}))
+		require.NoError(t, store.Save("follower", Checkpoint{Ordinal: 7, Hash: "def", UpdatedAt: at}))
+		require.NoError(t, store.Save("scanner", Checkpoint{Ordinal: 43, Hash: "abd", UpdatedAt: at}))
+
+		scanner, err := store.Load("scanner")
+		require.NoError(t, err)
+		assert.Equal(t, &Checkpoint{Ordinal: 43, Hash: "abd", UpdatedAt: at}, scanner)
+		follower, err := store.Load("follower")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), follower.Ordinal)
+		assert.Len(t, fake.rows, 2)
+	})
+
+	t.Run("a failed save changes nothing", func(t *testing.T) {
+		fake.failOn("INSERT")
+		defer fake.failOn("")
+
+		assert.Error(t, store.Save("watcher", Checkpoint{Ordinal: 1, Hash: "aaa", UpdatedAt: at}))
+		watcher, err := store.Load("watcher")
+		require.NoError(t, err)
+		assert.Nil(t, watcher)
+
+		// an existing checkpoint is updated without an insert
+		require.NoError(t, store.Save("scanner", Checkpoint{Ordinal: 44, Hash: "abe", UpdatedAt: at}))
+		scanner, err := store.Load("scanner")
+		require.NoError(t, err)
+		assert.Equal(t, int64(44), scanner.Ordinal)
+	})
+
+	t.Run("rolls back an insert when the commit fails", func(t *testing.T) {
+		fake.failOn("COMMIT")
+		defer fake.failOn("")
+
+		assert.Error(t, store.Save("watcher", Checkpoint{Ordinal: 1, Hash: "aaa", UpdatedAt: at}))
+		watcher, err := store.Load("watcher")
+		require.NoError(t, err)
+		assert.Nil(t, watcher)
+	})
+
+	t.Run("uses the driver's placeholders", func(t *testing.T) {
+		numbered, err := NewSQLCheckpointStore(db, "checkpoints", true)
+		require.NoError(t, err)
+
+		fake.queries = nil
+		require.NoError(t, numbered.Save("pg", Checkpoint{Ordinal: 9, Hash: "fff", UpdatedAt: at}))
+		checkpoint, err := numbered.Load("pg")
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), checkpoint.Ordinal)
+
+		require.Len(t, fake.queries, 3)
+		assert.Equal(t, "UPDATE checkpoints SET ordinal = $1, hash = $2, updated_at = $3 WHERE name = $4", fake.queries[0])
+		assert.Equal(t, "INSERT INTO checkpoints (name, ordinal, hash, updated_at) VALUES ($1, $2, $3, $4)", fake.queries[1])
+		assert.Equal(t, "SELECT ordinal, hash, updated_at FROM checkpoints WHERE name = $1", fake.queries[2])
+
+		fake.queries = nil
+		_, err = store.Load("pg")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SELECT ordinal, hash, updated_at FROM checkpoints WHERE name = ?"}, fake.queries)
+	})
+
+	t.Run("fails on a missing table", func(t *testing.T) {
+		other, err := NewSQLCheckpointStore(db, "other_checkpoints", false)
+		require.NoError(t, err)
+		_, err = other.Load("scanner")
+		assert.ErrorContains(t, err, "no such table")
+	})
+}
+
+// fakeCheckpointDB is a database/sql driver that understands the
+// statements SQLCheckpointStore issues, with transactions that apply their
+// writes on commit. It records every statement except CREATE TABLE, BEGIN
+// and COMMIT.
+type fakeCheckpointDB struct {
+	mu      sync.Mutex
+	tables  map[string]bool
+	rows    map[string]Checkpoint
+	queries []string
+	fail    string
+}
+
+func newFakeCheckpointDB() *fakeCheckpointDB {
+	return &fakeCheckpointDB{tables: map[string]bool{}, rows: map[string]Checkpoint{}}
+}
+
+// failOn makes statements starting with prefix fail; "" fails nothing
+func (f *fakeCheckpointDB) failOn(prefix string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = prefix
+}
+
+func (f *fakeCheckpointDB) Connect(context.Context) (driver.Conn, error) {
+	return &fakeCheckpointConn{db: f}, nil
+}
+
+func (f *fakeCheckpointDB) Driver() driver.Driver {
+	return nil
+}
+
+type fakeCheckpointConn struct {
+	db *fakeCheckpointDB
+	// pending holds the rows of an open transaction
+	pending map[string]Checkpoint
+}
+
+func (c *fakeCheckpointConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeCheckpointStmt{conn: c, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeCheckpointConn) Close() error {
+	return nil
+}
+
+func (c *fakeCheckpointConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.pending = make(map[string]Checkpoint, len(c.db.rows))
+	for name, row := range c.db.rows {
+		c.pending[name] = row
+	}
+	return c, nil
+}
+
+func (c *fakeCheckpointConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	pending := c.pending
+	c.pending = nil
+	if c.db.fail == "COMMIT" {
+		return errors.New("commit failed")
+	}
+	c.db.rows = pending
+	return nil
+}
+
+func (c *fakeCheckpointConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+type fakeCheckpointStmt struct {
+	conn  *fakeCheckpointConn
+	query string
+}
+
+func (s *fakeCheckpointStmt) Close() error {
+	return nil
+}
+
+func (s *fakeCheckpointStmt) NumInput() int {
+	return strings.Count(s.query, "?") + strings.Count(s.query, "$")
+}
+
+func (s *fakeCheckpointStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	fields := strings.Fields(s.query)
+	if strings.HasPrefix(s.query, "CREATE TABLE IF NOT EXISTS ") {
+		db.tables[fields[5]] = true
+		return driver.RowsAffected(0), nil
+	}
+	rows, err := s.begin(fields)
+	if err != nil {
+		return nil, err
+	}
+	switch fields[0] {
+	case "UPDATE":
+		name := args[3].(string)
+		if _, ok := rows[name]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		rows[name] = Checkpoint{Ordinal: args[0].(int64), Hash: args[1].(string), UpdatedAt: args[2].(time.Time)}
+		return driver.RowsAffected(1), nil
+	case "INSERT":
+		name := args[0].(string)
+		if _, ok := rows[name]; ok {
+			return nil, fmt.Errorf("duplicate key %q", name)
+		}
+		rows[name] = Checkpoint{Ordinal: args[1].(int64), Hash: args[2].(string), UpdatedAt: args[3].(time.Time)}
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", s.query)
+}
+
+func (s *fakeCheckpointStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	rows, err := s.begin(strings.Fields(s.query))
+	if err != nil {
+		return nil, err
+	}
+	result := &fakeCheckpointRows{}
+	if row, ok := rows[args[0].(string)]; ok {
+		result.rows = append(result.rows, row)
+	}
+	return result, nil
+}
+
+// begin records the statement, checks its table and returns the rows it
+// works on: the transaction's, or the committed ones outside a transaction
+func (s *fakeCheckpointStmt) begin(fields []string) (map[string]Checkpoint, error) {
+	db := s.conn.db
+	db.queries = append(db.queries, s.query)
+	if db.fail != "" && strings.HasPrefix(s.query, db.fail) {
+		return nil, fmt.Errorf("%s failed", db.fail)
+	}
+
+	var table string
+	switch fields[0] {
+	case "UPDATE":
+		table = fields[1]
+	case "INSERT":
+		table = fields[2]
+	case "SELECT":
+		table = fields[5]
+	}
+	if !db.tables[table] {
+		return nil, fmt.Errorf("no such table: %s", table)
+	}
+	if s.conn.pending != nil {
+		return s.conn.pending, nil
+	}
+	return db.rows, nil
+}
+
+type fakeCheckpointRows struct {
+	rows []Checkpoint
+}
+
+func (r *fakeCheckpointRows) Columns() []string {
+	return []string{"ordinal", "hash", "updated_at"}
+}
+
+func (r *fakeCheckpointRows) Close() error {
+	return nil
+}
+
+func (r *fakeCheckpointRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	dest[0], dest[1], dest[2] = row.Ordinal, row.Hash, row.UpdatedAt
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// DataUpdateVectors is the layout of shared/data_update_vectors.json
+type DataUpdateVectors struct {
+	Version      string             `json:"version"`
+	GeneratedBy  string             `json:"generatedBy"`
+	Description  string             `json:"description"`
+	CryptoParams CryptoParams       `json:"cryptoParams"`
+	Signer       Signer             `json:"signer"`
+	Vectors      []DataUpdateVector `json:"vectors"`
+}
+
+// DataUpdateVector is a payload with its canonical JSON and, per encoder,
+// the bytes that are hashed and the signer's proof over them
+type DataUpdateVector struct {
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	Data          json.RawMessage `json:"data"`
+	CanonicalJSON string          `json:"canonicalJson"`
+	// Encoders holds the "regular" encoding (canonical JSON, as Sign) and
+	// the "dataUpdate" one (prefixed base64, as SignDataUpdate)
+	Encoders map[string]EncodedPayload `json:"encoders"`
+}
+
+// EncodedPayload is a payload under one encoder
+type EncodedPayload struct {
+	BytesHex string                       `json:"bytesHex"`
+	Hash     string                       `json:"hash"`
+	Proof    constellation.SignatureProof `json:"proof"`
+}
+
+// payloadSpec is a vector before encoding. data is kept as written, key
+// order included, so SDKs also exercise canonical key sorting.
+type payloadSpec struct {
+	name        string
+	description string
+	data        string
+}
+
+var payloads = []payloadSpec{
+	{
+		name:        "flat-object",
+		description: "Two fields given out of canonical order",
+		data:        `{"value":42,"id":"sensor-1"}`,
+	},
+	{
+		name:        "nested-object",
+		description: "Objects and arrays several levels deep, including an empty object",
+		data:        `{"update":{"type":"Reading","sensor":{"id":"s-7","location":{"lon":13.405,"lat":52.52}},"readings":[{"v":-3.5,"t":1700000000},{"v":0,"t":1700000060}],"tags":["b","a"]},"metadata":{}}`,
+	},
+	{
+		name:        "nested-arrays",
+		description: "Nested and empty arrays, null and booleans",
+		data:        `{"matrix":[[1,2],[3,[4,[5,[]]]]],"empty":[],"missing":null,"flags":[true,false]}`,
+	},
+	{
+		name:        "metagraph-update",
+		description: "A data application update wrapped in its type name, as metagraphs send it",
+		data:        `{"CreatePoll":{"name":"favorite color","owner":"DAG4o41NzhfX6DyYBTTXu6sJa6awm36abJpv89jB","pollOptions":["red","green","blue"],"startSnapshotOrdinal":1000,"endSnapshotOrdinal":2000}}`,
+	},
+	{
+		name:        "unicode-strings",
+		description: "Accented, CJK, right-to-left, emoji and combining characters, which are hashed as UTF-8",
+		data:        `{"name":"Zoë Ångström","greeting":"こんにちは世界","rtl":"مرحبا","emoji":"🚀✨","combining":"e\u0301"}`,
+	},
+	{
+		name:        "unicode-keys",
+		description: "Keys sorted by UTF-16 code units as RFC 8785 requires; UTF-8 byte order would put \ufb01 before \U0001F600",
+		data:        `{"ﬁ":"ligature","😀":"smile","€":"euro","é":"e-acute","a":"lower","Z":"upper"}`,
+	},
+	{
+		name:        "escapes",
+		description: "Quotes, backslashes and control characters, which are escaped, next to slashes and HTML characters, which are not",
+		data:        `{"quote":"say \"hi\"","backslash":"C:\\path","newline":"line1\nline2","tab":"a\tb","control":"\u0001\u001f","slash":"a/b","html":"<b>&amp;</b>"}`,
+	},
+	{
+		name:        "numbers",
+		description: "Numbers in their ECMAScript form: the largest safe integer, negatives, fractions, exponents and negative zero",
+		data:        `{"maxSafe":9007199254740991,"negative":-42,"fraction":3.14159,"large":1e21,"small":1e-7,"zero":0,"negativeZero":-0}`,
+	},
+	{
+		name:        "long-string",
+		description: "A 2000-character value, so the DataUpdate length header has four digits",
+		data:        fmt.Sprintf(`{"blob":%q}`, strings.Repeat("metakit-", 250)),
+	},
+}
+
+// generateDataUpdates builds shared/data_update_vectors.json
+func generateDataUpdates() (interface{}, error) {
+	kp, signer, err := deriveSigner("metakit-sdk data update vector signer")
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := DataUpdateVectors{
+		Version:      "v1",
+		GeneratedBy:  "go/cmd/gen-vectors",
+		Description:  "Data application payloads under each encoder, with deterministic signatures. " + regenerate,
+		CryptoParams: cryptoParams,
+		Signer:       *signer,
+	}
+	for _, spec := range payloads {
+		vector, err := encodePayload(spec, kp)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.name, err)
+		}
+		vectors.Vectors = append(vectors.Vectors, *vector)
+	}
+	return vectors, nil
+}
+
+// encodePayload canonicalizes, encodes and signs spec's data
+func encodePayload(spec payloadSpec, kp *constellation.KeyPair) (*DataUpdateVector, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(spec.data), &data); err != nil {
+		return nil, err
+	}
+	canonical, err := constellation.Canonicalize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	vector := &DataUpdateVector{
+		Name:          spec.name,
+		Description:   spec.description,
+		Data:          json.RawMessage(spec.data),
+		CanonicalJSON: canonical,
+		Encoders:      map[string]EncodedPayload{},
+	}
+	for encoder, isDataUpdate := range map[string]bool{"regular": false, "dataUpdate": true} {
+		encoded, err := constellation.ToBytes(data, isDataUpdate)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := kp.Signer()
+		if err != nil {
+			return nil, err
+		}
+		proof, err := constellation.SignWithSigner(data, signer, isDataUpdate)
+		if err != nil {
+			return nil, err
+		}
+		vector.Encoders[encoder] = EncodedPayload{
+			BytesHex: hex.EncodeToString(encoded),
+			Hash:     constellation.HashBytes(encoded).Value,
+			Proof:    *proof,
+		}
+	}
+	return vector, nil
+}
@@ -0,0 +1,140 @@
+// Command gen-vectors generates the test vectors shared by the SDKs:
+//
+//   - multisig_vectors.json: multi-signature currency transactions (2-of-2,
+//     3-of-5, a duplicate signer, out-of-order proofs, one signer under both
+//     public key forms)
+//   - data_update_vectors.json: data-application payloads signed with the
+//     regular and the DataUpdate encoding
+//
+// Signer keys are derived from fixed seeds and ECDSA nonces follow RFC 6979,
+// so every run produces byte-identical output; any SDK signing the same
+// hash with the same key must reproduce each signature exactly. With -check
+// the files in -dir are compared with a fresh generation instead of being
+// written, and a difference exits non-zero.
+//
+// Usage:
+//
+//	go run ./cmd/gen-vectors
+//	go run ./cmd/gen-vectors -check
+//	go run ./cmd/gen-vectors -dir /tmp/vectors
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// regenerate tells readers of a vectors file how to rebuild it
+const regenerate = "Regenerate from packages/go with: go run ./cmd/gen-vectors"
+
+// vectorFiles maps each file gen-vectors writes to its generator
+var vectorFiles = []struct {
+	name     string
+	generate func() (interface{}, error)
+}{
+	{"multisig_vectors.json", generateMultisig},
+	{"data_update_vectors.json", generateDataUpdates},
+}
+
+// cryptoParams is shared by every vectors file
+var cryptoParams = CryptoParams{
+	Curve:              "secp256k1",
+	SignatureAlgorithm: "SHA512withECDSA",
+	HashAlgorithm:      "SHA-256",
+	Nonces:             "RFC6979",
+}
+
+// CryptoParams describes how the vectors were signed
+type CryptoParams struct {
+	Curve              string `json:"curve"`
+	SignatureAlgorithm string `json:"signatureAlgorithm"`
+	HashAlgorithm      string `json:"hashAlgorithm"`
+	Nonces             string `json:"nonces"`
+}
+
+// Signer is a key pair derived from Seed, whose SHA-256 is the private key
+type Signer struct {
+	Seed          string `json:"seed"`
+	PrivateKeyHex string `json:"privateKeyHex"`
+	PublicKeyHex  string `json:"publicKeyHex"`
+	SignerID      string `json:"signerId"`
+	Address       string `json:"address"`
+}
+
+func main() {
+	dir := flag.String("dir", filepath.Join("..", "..", "shared"), "Directory of the vectors files")
+	check := flag.Bool("check", false, "Compare the files in -dir with a fresh generation instead of writing them")
+	flag.Parse()
+
+	stale := false
+	for _, file := range vectorFiles {
+		path := filepath.Join(*dir, file.name)
+		data, err := generate(file.generate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", file.name, err)
+			os.Exit(1)
+		}
+
+		if !*check {
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			continue
+		}
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !bytes.Equal(existing, data) {
+			fmt.Fprintf(os.Stderr, "%s is out of date\n", path)
+			stale = true
+		}
+	}
+	if stale {
+		fmt.Fprintln(os.Stderr, regenerate)
+		os.Exit(1)
+	}
+}
+
+// generate runs a generator and encodes its vectors as indented JSON,
+// leaving non-ASCII and HTML characters unescaped so payloads stay readable
+func generate(generator func() (interface{}, error)) ([]byte, error) {
+	vectors, err := generator()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(vectors); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deriveSigner derives a key pair from seed
+func deriveSigner(seed string) (*constellation.KeyPair, *Signer, error) {
+	digest := sha256.Sum256([]byte(seed))
+	kp, err := constellation.KeyPairFromPrivateKey(hex.EncodeToString(digest[:]))
+	if err != nil {
+		return nil, nil, err
+	}
+	return kp, &Signer{
+		Seed:          seed,
+		PrivateKeyHex: kp.PrivateKey,
+		PublicKeyHex:  kp.PublicKey,
+		SignerID:      constellation.NormalizePublicKeyToID(kp.PublicKey),
+		Address:       kp.Address,
+	}, nil
+}
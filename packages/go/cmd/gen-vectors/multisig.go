@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// signerCount is how many signers the vectors define; scenarios pick
+// signers from them by index
+const signerCount = 5
+
+// destination receives every scenario's transaction
+const destination = "DAG4o41NzhfX6DyYBTTXu6sJa6awm36abJpv89jB"
+
+// MultisigVectors is the layout of shared/multisig_vectors.json
+type MultisigVectors struct {
+	Version      string               `json:"version"`
+	GeneratedBy  string               `json:"generatedBy"`
+	Description  string               `json:"description"`
+	CryptoParams MultisigCryptoParams `json:"cryptoParams"`
+	Signers      []Signer             `json:"signers"`
+	Scenarios    []Scenario           `json:"scenarios"`
+}
+
+// MultisigCryptoParams adds the transaction encoding to CryptoParams
+type MultisigCryptoParams struct {
+	CryptoParams
+	KryoSetReferences bool `json:"kryoSetReferences"`
+}
+
+// Scenario is a transaction signed by a subset of Authorized, which must
+// include at least Threshold distinct signers. SignedBy lists the signer
+// indexes in proof order. CompressedProofs lists the positions of proofs
+// whose ID is the signer's compressed public key instead of its SignerID.
+type Scenario struct {
+	Name             string                                 `json:"name"`
+	Description      string                                 `json:"description"`
+	Threshold        int                                    `json:"threshold"`
+	Authorized       []int                                  `json:"authorized"`
+	SignedBy         []int                                  `json:"signedBy"`
+	CompressedProofs []int                                  `json:"compressedProofs,omitempty"`
+	Transaction      constellation.CurrencyTransactionValue `json:"transaction"`
+	TransactionHash  string                                 `json:"transactionHash"`
+	Proofs           []constellation.SignatureProof         `json:"proofs"`
+	Expected         Expected                               `json:"expected"`
+}
+
+// Expected is the outcome a policy verifier must report for a scenario
+type Expected struct {
+	// AllProofsValid is true when every proof verifies against the hash
+	AllProofsValid bool `json:"allProofsValid"`
+	// ValidProofs counts valid proofs, duplicates included
+	ValidProofs int `json:"validProofs"`
+	// DistinctSigners counts authorized signers with a valid proof, by
+	// normalized public key ID
+	DistinctSigners int `json:"distinctSigners"`
+	// MeetsThreshold is DistinctSigners >= Threshold
+	MeetsThreshold bool `json:"meetsThreshold"`
+}
+
+// scenarioSpec is a scenario before signing
+type scenarioSpec struct {
+	name        string
+	description string
+	threshold   int
+	authorized  []int
+	signedBy    []int
+	compressed  []int
+	amount      int64
+}
+
+var scenarios = []scenarioSpec{
+	{
+		name:        "2-of-2",
+		description: "Both of two authorized signers sign",
+		threshold:   2,
+		authorized:  []int{0, 1},
+		signedBy:    []int{0, 1},
+		amount:      100000000,
+	},
+	{
+		name:        "3-of-5",
+		description: "Three of five authorized signers sign, in signer order",
+		threshold:   3,
+		authorized:  []int{0, 1, 2, 3, 4},
+		signedBy:    []int{0, 2, 4},
+		amount:      200000000,
+	},
+	{
+		name:        "duplicate-signer",
+		description: "One signer's proof appears twice; every proof is valid but only two distinct signers signed a 3-of-5",
+		threshold:   3,
+		authorized:  []int{0, 1, 2, 3, 4},
+		signedBy:    []int{0, 1, 0},
+		amount:      300000000,
+	},
+	{
+		name:        "out-of-order-proofs",
+		description: "A 3-of-5 whose proofs are not in signer or ID order; proof order does not affect validity",
+		threshold:   3,
+		authorized:  []int{0, 1, 2, 3, 4},
+		signedBy:    []int{4, 0, 3},
+		amount:      400000000,
+	},
+	{
+		name:        "compressed-duplicate-signer",
+		description: "One signer signs under both its uncompressed and its compressed public key ID; every proof is valid but only one distinct signer signed a 2-of-5",
+		threshold:   2,
+		authorized:  []int{0, 1, 2, 3, 4},
+		signedBy:    []int{0, 0},
+		compressed:  []int{1},
+		amount:      500000000,
+	},
+}
+
+// generateMultisig builds shared/multisig_vectors.json
+func generateMultisig() (interface{}, error) {
+	signers := make([]Signer, signerCount)
+	keyPairs := make([]*constellation.KeyPair, signerCount)
+	for i := range signers {
+		kp, signer, err := deriveSigner(fmt.Sprintf("metakit-sdk multisig vector signer %d", i))
+		if err != nil {
+			return nil, err
+		}
+		keyPairs[i], signers[i] = kp, *signer
+	}
+
+	vectors := MultisigVectors{
+		Version:      "v2",
+		GeneratedBy:  "go/cmd/gen-vectors",
+		Description:  "Multi-signature currency transactions with deterministic signatures. " + regenerate,
+		CryptoParams: MultisigCryptoParams{CryptoParams: cryptoParams},
+		Signers:      signers,
+	}
+
+	for i, spec := range scenarios {
+		scenario, err := sign(spec, int64(i), keyPairs, signers)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.name, err)
+		}
+		vectors.Scenarios = append(vectors.Scenarios, *scenario)
+	}
+
+	return vectors, nil
+}
+
+// sign signs spec's transaction by each signer in spec.signedBy, in order
+func sign(spec scenarioSpec, index int64, keyPairs []*constellation.KeyPair, signers []Signer) (*Scenario, error) {
+	tx := &constellation.CurrencyTransaction{
+		Value: constellation.CurrencyTransactionValue{
+			Source:      keyPairs[0].Address,
+			Destination: destination,
+			Amount:      spec.amount,
+			Fee:         0,
+			Parent:      constellation.GenesisReference,
+			Salt:        fmt.Sprint(int64(1)<<53 + index),
+		},
+	}
+	for _, i := range spec.signedBy {
+		signer, err := keyPairs[i].Signer()
+		if err != nil {
+			return nil, err
+		}
+		if tx, err = constellation.CosignTransaction(tx, signer); err != nil {
+			return nil, err
+		}
+	}
+	for _, position := range spec.compressed {
+		compressed, err := constellation.GetPublicKeyHex(keyPairs[spec.signedBy[position]].PrivateKey, true)
+		if err != nil {
+			return nil, err
+		}
+		tx.Proofs[position].ID = compressed
+	}
+
+	result := constellation.VerifyCurrencyTransaction(tx)
+	authorized := map[string]bool{}
+	for _, i := range spec.authorized {
+		authorized[signers[i].SignerID] = true
+	}
+	distinct := map[string]bool{}
+	for _, id := range constellation.DistinctSigners(result.ValidProofs) {
+		if authorized[id] {
+			distinct[id] = true
+		}
+	}
+
+	return &Scenario{
+		Name:             spec.name,
+		Description:      spec.description,
+		Threshold:        spec.threshold,
+		Authorized:       spec.authorized,
+		SignedBy:         spec.signedBy,
+		CompressedProofs: spec.compressed,
+		Transaction:      tx.Value,
+		TransactionHash:  constellation.HashCurrencyTransaction(tx).Value,
+		Proofs:           tx.Proofs,
+		Expected: Expected{
+			AllProofsValid:  result.IsValid,
+			ValidProofs:     len(result.ValidProofs),
+			DistinctSigners: len(distinct),
+			MeetsThreshold:  len(distinct) >= spec.threshold,
+		},
+	}, nil
+}
@@ -0,0 +1,388 @@
+// Command loadtest generates sustained currency transaction load against a
+// Currency L1 node and reports latency percentiles and rejection reasons.
+//
+// Each key in the key set is driven by one worker so transactions chain
+// correctly per address; -rate is shared across all workers. With
+// -concurrency below the number of keys, each worker takes turns between
+// several keys.
+//
+// Usage:
+//
+//	go run ./cmd/loadtest -l1 http://localhost:9300 -keys keys.txt -destination DAG... -rate 50 -duration 2m
+//	go run ./cmd/loadtest -simulate -generate 20 -rate 200 -duration 30s -json
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/Constellation-Labs/metakit-sdk/packages/go/v2/network"
+)
+
+type options struct {
+	l1URL       string
+	keysFile    string
+	generate    int
+	destination string
+	amount      float64
+	rate        float64
+	concurrency int
+	duration    time.Duration
+	timeout     int
+	simulate    bool
+	jsonOutput  bool
+}
+
+// sample is the outcome of one submission
+type sample struct {
+	latency time.Duration
+	reason  string
+}
+
+// Report is the load test summary
+type Report struct {
+	Duration   string         `json:"duration"`
+	Submitted  int            `json:"submitted"`
+	Accepted   int            `json:"accepted"`
+	Rejected   int            `json:"rejected"`
+	Throughput float64        `json:"throughputPerSecond"`
+	LatencyMs  Percentiles    `json:"latencyMs"`
+	Rejections map[string]int `json:"rejections"`
+}
+
+// Percentiles summarises submission latency in milliseconds
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.l1URL, "l1", "http://localhost:9300", "Currency L1 URL")
+	flag.StringVar(&opts.keysFile, "keys", "", "File with one hex private key per line")
+	flag.IntVar(&opts.generate, "generate", 0, "Generate this many throwaway keys instead of -keys")
+	flag.StringVar(&opts.destination, "destination", "", "Destination address (default: a generated address)")
+	flag.Float64Var(&opts.amount, "amount", 0.00000001, "Amount per transaction in tokens")
+	flag.Float64Var(&opts.rate, "rate", 10, "Target transactions per second across all workers")
+	flag.IntVar(&opts.concurrency, "concurrency", 0, "Number of concurrent workers, at most one per key; keys are shared out between them (default: one per key)")
+	flag.DurationVar(&opts.duration, "duration", time.Minute, "How long to generate load")
+	flag.IntVar(&opts.timeout, "timeout", 30, "Request timeout in seconds")
+	flag.BoolVar(&opts.simulate, "simulate", false, "Run against an in-memory SimulatedLedger")
+	flag.BoolVar(&opts.jsonOutput, "json", false, "Print the report as JSON")
+	flag.Parse()
+
+	report, err := run(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if opts.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return
+	}
+	printReport(report)
+}
+
+func run(opts options) (*Report, error) {
+	keys, err := loadKeys(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+
+	destination := opts.destination
+	if destination == "" {
+		sink, err := constellation.GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		destination = sink.Address
+	}
+
+	var l1 constellation.CurrencyL1API
+	if opts.simulate {
+		ledger := constellation.NewSimulatedLedger()
+		for _, key := range keys {
+			if err := ledger.Fund(key.Address, 1e9); err != nil {
+				return nil, err
+			}
+		}
+		l1 = ledger
+	} else {
+		client, err := network.NewCurrencyL1Client(constellation.NetworkConfig{
+			L1URL:     opts.l1URL,
+			Timeout:   opts.timeout,
+			UserAgent: "metakit-sdk-go/loadtest",
+		})
+		if err != nil {
+			return nil, err
+		}
+		l1 = client
+	}
+
+	workers := len(keys)
+	if opts.concurrency > workers {
+		return nil, fmt.Errorf("-concurrency %d needs at least %d keys, got %d: each key's transactions must chain from one worker", opts.concurrency, opts.concurrency, workers)
+	}
+	if opts.concurrency > 0 {
+		workers = opts.concurrency
+	}
+	// worker i drives keys i, i+workers, i+2*workers, ...
+	shares := make([][]*constellation.KeyPair, workers)
+	for i, key := range keys {
+		shares[i%workers] = append(shares[i%workers], key)
+	}
+
+	tokens := make(chan struct{})
+	samples := make(chan sample, 1024)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for _, share := range shares {
+		wg.Add(1)
+		go func(share []*constellation.KeyPair) {
+			defer wg.Done()
+			worker(l1, share, destination, opts.amount, tokens, stop, samples)
+		}(share)
+	}
+
+	var collected []sample
+	collectorDone := make(chan struct{})
+	go func() {
+		for s := range samples {
+			collected = append(collected, s)
+		}
+		close(collectorDone)
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.rate))
+	deadline := time.After(opts.duration)
+loop:
+	for {
+		select {
+		case <-deadline:
+			break loop
+		case <-ticker.C:
+			select {
+			case tokens <- struct{}{}:
+			default:
+				// every worker is busy; the target rate exceeds what the node sustains
+			}
+		}
+	}
+	ticker.Stop()
+	close(stop)
+	wg.Wait()
+	close(samples)
+	<-collectorDone
+
+	return summarize(collected, time.Since(start)), nil
+}
+
+// worker submits one chained transaction per token until stop is closed
+// worker submits one transaction per token, taking turns between its keys
+// and chaining each key's transactions
+func worker(l1 constellation.CurrencyL1API, keys []*constellation.KeyPair, destination string, amount float64,
+	tokens <-chan struct{}, stop <-chan struct{}, samples chan<- sample) {
+	lastRefs := make([]*constellation.TransactionReference, len(keys))
+
+	for turn := 0; ; turn = (turn + 1) % len(keys) {
+		select {
+		case <-stop:
+			return
+		case <-tokens:
+		}
+
+		key := keys[turn]
+		if lastRefs[turn] == nil {
+			ref, err := l1.GetLastReference(key.Address)
+			if err != nil {
+				samples <- sample{reason: rejectionReason(err)}
+				continue
+			}
+			lastRefs[turn] = ref
+		}
+		lastRef := lastRefs[turn]
+
+		tx, err := constellation.CreateCurrencyTransaction(
+			constellation.TransferParams{Destination: destination, Amount: amount},
+			key.PrivateKey,
+			*lastRef,
+		)
+		if err != nil {
+			samples <- sample{reason: rejectionReason(err)}
+			continue
+		}
+
+		started := time.Now()
+		_, err = l1.PostTransaction(tx)
+		latency := time.Since(started)
+		if err != nil {
+			lastRefs[turn] = nil
+			samples <- sample{latency: latency, reason: rejectionReason(err)}
+			continue
+		}
+
+		lastRefs[turn] = constellation.GetTransactionReference(tx, lastRef.Ordinal+1)
+		samples <- sample{latency: latency}
+	}
+}
+
+// rejectionReason buckets an error into a short, aggregatable reason
+func rejectionReason(err error) string {
+	if errors.Is(err, constellation.ErrRequestTimeout) {
+		return "timeout"
+	}
+	var netErr *constellation.NetworkError
+	if errors.As(err, &netErr) {
+		body := strings.TrimSpace(netErr.Response)
+		if len(body) > 80 {
+			body = body[:80]
+		}
+		if netErr.StatusCode > 0 {
+			return fmt.Sprintf("HTTP %d %s", netErr.StatusCode, body)
+		}
+		return netErr.Message
+	}
+	return err.Error()
+}
+
+func summarize(samples []sample, elapsed time.Duration) *Report {
+	report := &Report{
+		Duration:   elapsed.Round(time.Millisecond).String(),
+		Submitted:  len(samples),
+		Rejections: map[string]int{},
+	}
+
+	var latencies []time.Duration
+	for _, s := range samples {
+		if s.reason != "" {
+			report.Rejected++
+			report.Rejections[s.reason]++
+		} else {
+			report.Accepted++
+		}
+		if s.latency > 0 {
+			latencies = append(latencies, s.latency)
+		}
+	}
+
+	if elapsed > 0 {
+		report.Throughput = float64(report.Accepted) / elapsed.Seconds()
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.LatencyMs = Percentiles{
+		P50: percentile(latencies, 0.50),
+		P90: percentile(latencies, 0.90),
+		P99: percentile(latencies, 0.99),
+		Max: percentile(latencies, 1),
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies in milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return float64(sorted[rank].Microseconds()) / 1000
+}
+
+func loadKeys(opts options) ([]*constellation.KeyPair, error) {
+	var keys []*constellation.KeyPair
+
+	if opts.generate > 0 {
+		for i := 0; i < opts.generate; i++ {
+			kp, err := constellation.GenerateKeyPair()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, kp)
+		}
+		return keys, nil
+	}
+
+	if opts.keysFile == "" {
+		return nil, errors.New("either -keys or -generate is required")
+	}
+
+	file, err := os.Open(opts.keysFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		kp, err := constellation.KeyPairFromPrivateKey(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", opts.keysFile, line, err)
+		}
+		keys = append(keys, kp)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no keys found", opts.keysFile)
+	}
+	return keys, nil
+}
+
+func printReport(r *Report) {
+	fmt.Printf("Duration:    %s\n", r.Duration)
+	fmt.Printf("Submitted:   %d\n", r.Submitted)
+	fmt.Printf("Accepted:    %d\n", r.Accepted)
+	fmt.Printf("Rejected:    %d\n", r.Rejected)
+	fmt.Printf("Throughput:  %.2f tx/s\n", r.Throughput)
+	fmt.Println()
+	fmt.Println("Latency (ms):")
+	fmt.Printf("  p50  %8.2f\n", r.LatencyMs.P50)
+	fmt.Printf("  p90  %8.2f\n", r.LatencyMs.P90)
+	fmt.Printf("  p99  %8.2f\n", r.LatencyMs.P99)
+	fmt.Printf("  max  %8.2f\n", r.LatencyMs.Max)
+
+	if len(r.Rejections) > 0 {
+		fmt.Println()
+		fmt.Println("Rejections:")
+		reasons := make([]string, 0, len(r.Rejections))
+		for reason := range r.Rejections {
+			reasons = append(reasons, reason)
+		}
+		sort.Slice(reasons, func(i, j int) bool { return r.Rejections[reasons[i]] > r.Rejections[reasons[j]] })
+		for _, reason := range reasons {
+			fmt.Printf("  %6d  %s\n", r.Rejections[reason], reason)
+		}
+	}
+}
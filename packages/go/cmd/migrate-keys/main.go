@@ -0,0 +1,298 @@
+// Command migrate-keys converts private keys between the formats the SDK
+// understands and checks that the DAG address survives the conversion.
+//
+// Keys are read one per line from -in (or stdin) and written to -out (or
+// stdout). With -dry-run nothing is written; each key is converted, decoded
+// again and its address compared, and a per-key report is printed.
+//
+// Supported formats: hex (raw 32-byte private key), wif, pem (one SEC1 or
+// PKCS#8 key per input file, as written by openssl; written as PKCS#8), and
+// as input only mnemonic (BIP39 phrase, first key of the dag4.js BIP44 path,
+// with the BIP39 passphrase taken from the environment variable named by
+// -passphrase-env, if set), p12 (the key under -alias in the PKCS#12
+// keystore -in) and dag4-keystore (the V3 JSON keystore -in written by
+// dag4.js). Keystore passwords are taken from the environment variable named
+// by -password-env.
+//
+// Usage:
+//
+//	go run ./cmd/migrate-keys -from hex -to wif -in keys.txt -out keys.wif
+//	go run ./cmd/migrate-keys -from wif -to hex -in keys.wif -dry-run
+//	go run ./cmd/migrate-keys -from hex -to wif -in keys.txt -expect DAG0...
+//	go run ./cmd/migrate-keys -from mnemonic -to hex -in phrases.txt -out keys.txt
+//	CL_PASSPHRASE=... go run ./cmd/migrate-keys -from mnemonic -passphrase-env CL_PASSPHRASE -to hex -in phrases.txt
+//	go run ./cmd/migrate-keys -from pem -to hex -in node.pem
+//	CL_PASSWORD=... go run ./cmd/migrate-keys -from p12 -alias alias -to wif -in node.p12
+//	CL_PASSWORD=... go run ./cmd/migrate-keys -from dag4-keystore -to hex -in wallet.json
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const (
+	formatHex      = "hex"
+	formatWIF      = "wif"
+	formatKeystore = "dag4-keystore"
+	formatP12      = "p12"
+	formatPEM      = "pem"
+	formatMnemonic = "mnemonic"
+)
+
+type options struct {
+	from          string
+	to            string
+	in            string
+	out           string
+	compressed    bool
+	expect        string
+	dryRun        bool
+	alias         string
+	passwordEnv   string
+	passphraseEnv string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.from, "from", formatHex, "Input format: hex, wif, pem, mnemonic, p12 or dag4-keystore")
+	flag.StringVar(&opts.to, "to", formatWIF, "Output format: hex, wif or pem")
+	flag.StringVar(&opts.in, "in", "", "Input file with one key per line, a PEM key, a p12 keystore or a dag4.js keystore (default: stdin)")
+	flag.StringVar(&opts.out, "out", "", "Output file (default: stdout)")
+	flag.BoolVar(&opts.compressed, "compressed", false, "Write WIF keys with the compressed-public-key suffix")
+	flag.StringVar(&opts.expect, "expect", "", "Comma-separated addresses the keys must derive, in order")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "Verify the conversion without writing any keys")
+	flag.StringVar(&opts.alias, "alias", "", "Key alias in a p12 keystore (default: its only key)")
+	flag.StringVar(&opts.passwordEnv, "password-env", "CL_PASSWORD", "Environment variable holding the p12 or dag4.js keystore password")
+	flag.StringVar(&opts.passphraseEnv, "passphrase-env", "", "Environment variable holding the BIP39 passphrase of mnemonic input (default: no passphrase)")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	for _, format := range []string{opts.from, opts.to} {
+		if format != formatHex && format != formatWIF && format != formatPEM && format != formatMnemonic && format != formatP12 && format != formatKeystore {
+			return fmt.Errorf("unknown format %q", format)
+		}
+	}
+	if opts.to == formatMnemonic {
+		return errors.New("a mnemonic cannot be recovered from a private key; mnemonic is an input format only")
+	}
+	if opts.to == formatP12 {
+		return errors.New("p12 is an input format only; use constellation.ExportP12 to write keystores")
+	}
+	if opts.to == formatKeystore {
+		return errors.New("dag4-keystore is an input format only; use constellation.EncryptKeyPair to write keystores")
+	}
+
+	name := "stdin"
+	if opts.in != "" {
+		name = opts.in
+	}
+	sources, err := readKeys(name, opts)
+	if err != nil {
+		return err
+	}
+
+	var expected []string
+	if opts.expect != "" {
+		expected = strings.Split(opts.expect, ",")
+	}
+
+	var converted []string
+	for _, source := range sources {
+		key, err := migrate(source.keyPair, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source.position, err)
+		}
+		if n := len(converted); n < len(expected) && strings.TrimSpace(expected[n]) != key.address {
+			return fmt.Errorf("%s: derived address %s, expected %s", source.position,
+				constellation.RedactAddress(key.address), constellation.RedactAddress(strings.TrimSpace(expected[n])))
+		}
+		if opts.dryRun {
+			fmt.Printf("%s: ok %s\n", source.position, key.address)
+		}
+		converted = append(converted, key.encoded)
+	}
+	if len(converted) == 0 {
+		return fmt.Errorf("%s: no keys found", name)
+	}
+	if len(converted) < len(expected) {
+		return fmt.Errorf("%s: %d keys found, %d addresses expected", name, len(converted), len(expected))
+	}
+
+	if opts.dryRun {
+		fmt.Printf("%d keys verified, nothing written\n", len(converted))
+		return nil
+	}
+	return writeKeys(opts.out, converted)
+}
+
+// sourceKey is one decoded input key and where it was read from, as
+// file:line or file:alias
+type sourceKey struct {
+	position string
+	keyPair  *constellation.KeyPair
+}
+
+// readKeys decodes the input keys: the key under -alias from a p12
+// keystore, the key in a PEM file or a dag4.js keystore, or one key per line
+// otherwise
+func readKeys(name string, opts options) ([]sourceKey, error) {
+	if opts.from == formatP12 {
+		if opts.in == "" {
+			return nil, errors.New("-from p12 reads a keystore file, set -in")
+		}
+		keyPair, err := constellation.LoadP12(opts.in, opts.alias, os.Getenv(opts.passwordEnv))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		position := name
+		if opts.alias != "" {
+			position += ":" + opts.alias
+		}
+		return []sourceKey{{position: position, keyPair: keyPair}}, nil
+	}
+
+	input := io.Reader(os.Stdin)
+	if opts.in != "" {
+		file, err := os.Open(opts.in)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	if opts.from == formatPEM || opts.from == formatKeystore {
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		var keyPair *constellation.KeyPair
+		if opts.from == formatKeystore {
+			keyPair, err = constellation.DecryptKeyStore(data, os.Getenv(opts.passwordEnv))
+		} else {
+			keyPair, err = constellation.KeyPairFromPEM(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return []sourceKey{{position: name, keyPair: keyPair}}, nil
+	}
+
+	var sources []sourceKey
+	scanner := bufio.NewScanner(input)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		position := fmt.Sprintf("%s:%d", name, line)
+		keyPair, err := decode(text, opts.from, passphrase(opts))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", position, err)
+		}
+		sources = append(sources, sourceKey{position: position, keyPair: keyPair})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// migratedKey is one key in the output format and the address it derives
+type migratedKey struct {
+	encoded string
+	address string
+}
+
+// migrate decodes a key, re-encodes it and decodes the result again, failing
+// unless both decodings derive the same address
+func migrate(original *constellation.KeyPair, opts options) (*migratedKey, error) {
+	encoded, err := encode(original, opts.to, opts.compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	roundTrip, err := decode(encoded, opts.to, "")
+	if err != nil {
+		return nil, fmt.Errorf("converted key does not decode: %w", err)
+	}
+	if roundTrip.Address != original.Address {
+		return nil, errors.New("converted key derives a different address")
+	}
+	return &migratedKey{encoded: encoded, address: original.Address}, nil
+}
+
+// passphrase returns the BIP39 passphrase for mnemonic input
+func passphrase(opts options) string {
+	if opts.passphraseEnv == "" {
+		return ""
+	}
+	return os.Getenv(opts.passphraseEnv)
+}
+
+func decode(text string, format string, passphrase string) (*constellation.KeyPair, error) {
+	switch format {
+	case formatWIF:
+		return constellation.KeyPairFromWIF(text)
+	case formatMnemonic:
+		return constellation.KeyPairFromMnemonicWithPassphrase(text, passphrase)
+	case formatPEM:
+		return constellation.KeyPairFromPEM([]byte(text))
+	}
+	return constellation.KeyPairFromPrivateKey(strings.TrimPrefix(text, "0x"))
+}
+
+func encode(kp *constellation.KeyPair, format string, compressed bool) (string, error) {
+	if format == formatPEM {
+		encoded, err := constellation.ExportPEM(kp)
+		return strings.TrimSuffix(string(encoded), "\n"), err
+	}
+	// imported key pairs hold no hex copy of the key
+	privateKey, err := kp.PrivateKeyBytes()
+	if err != nil {
+		return "", err
+	}
+	defer constellation.Zeroize(privateKey)
+	if format == formatWIF {
+		return constellation.EncodeWIF(hex.EncodeToString(privateKey), compressed)
+	}
+	return hex.EncodeToString(privateKey), nil
+}
+
+func writeKeys(path string, keys []string) error {
+	output := io.Writer(os.Stdout)
+	if path != "" {
+		// keys are secrets: keep the file private to the current user
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	w := bufio.NewWriter(output)
+	for _, key := range keys {
+		if _, err := fmt.Fprintln(w, key); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,254 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidConfirmationPolicy indicates a policy with a negative depth
+	// or amount, or an invalid rule address
+	ErrInvalidConfirmationPolicy = errors.New("invalid confirmation policy")
+	// ErrNotFinal indicates a transaction that did not reach its required
+	// confirmation depth before the wait timed out
+	ErrNotFinal = errors.New("transaction has not reached its confirmation depth")
+)
+
+// ConfirmationRule requires a confirmation depth for the deposits it
+// matches. Both conditions must hold for a rule to match.
+type ConfirmationRule struct {
+	// Address matches deposits to this destination; empty matches any
+	Address string
+	// MinAmount matches deposits of at least this many smallest units
+	// (1e-8); zero matches any amount
+	MinAmount int64
+	// Depth is how many global snapshots must follow the one that included
+	// the deposit
+	Depth int64
+}
+
+// ConfirmationPolicy decides how deep a deposit must be before it is final.
+// DefaultDepth is the floor; when rules match, the deepest of them and the
+// default wins, so rule order does not matter and adding a rule can only
+// make the policy stricter.
+//
+// Example:
+//
+//	policy := ConfirmationPolicy{
+//	    DefaultDepth: 1,
+//	    Rules: []ConfirmationRule{
+//	        {MinAmount: TokenToUnits(10000), Depth: 10},
+//	        {Address: coldWallet, Depth: 5},
+//	    },
+//	}
+type ConfirmationPolicy struct {
+	// DefaultDepth applies to every deposit; rules can only raise it
+	DefaultDepth int64
+	Rules        []ConfirmationRule
+}
+
+// Validate checks depths and amounts are not negative and rule addresses
+// are valid DAG addresses
+func (p ConfirmationPolicy) Validate() error {
+	if p.DefaultDepth < 0 {
+		return fmt.Errorf("%w: negative default depth", ErrInvalidConfirmationPolicy)
+	}
+	for i, rule := range p.Rules {
+		switch {
+		case rule.Depth < 0:
+			return fmt.Errorf("%w: rule %d has a negative depth", ErrInvalidConfirmationPolicy, i)
+		case rule.MinAmount < 0:
+			return fmt.Errorf("%w: rule %d has a negative amount", ErrInvalidConfirmationPolicy, i)
+		case rule.Address != "" && !IsValidDAGAddress(rule.Address):
+			return fmt.Errorf("%w: rule %d has an invalid address", ErrInvalidConfirmationPolicy, i)
+		}
+	}
+	return nil
+}
+
+// Depth returns the confirmation depth required for amount sent to
+// destination: the deepest of DefaultDepth and every matching rule
+func (p ConfirmationPolicy) Depth(destination string, amount int64) int64 {
+	depth := p.DefaultDepth
+	for _, rule := range p.Rules {
+		if rule.Address != "" && rule.Address != destination {
+			continue
+		}
+		if amount < rule.MinAmount {
+			continue
+		}
+		if rule.Depth > depth {
+			depth = rule.Depth
+		}
+	}
+	return depth
+}
+
+// IsFinal reports whether a transaction included in snapshot ordinal has
+// its required depth once latestOrdinal is the newest snapshot
+func (p ConfirmationPolicy) IsFinal(destination string, amount int64, ordinal int64, latestOrdinal int64) bool {
+	return ordinal > 0 && latestOrdinal-ordinal >= p.Depth(destination, amount)
+}
+
+// ConfirmationTracker turns DepositDetected events into DepositFinalized
+// events once each deposit reaches the depth its ConfirmationPolicy
+// requires. Feed it a deposit scanner's events and a
+// network.SnapshotSubscriber's SnapshotAdvanced events by subscribing Handle
+// to both; finalized deposits are published on the embedded EventBus.
+//
+// Pending deposits are kept in memory only; after a restart, replay the
+// deposits not yet credited.
+//
+// Example:
+//
+//	tracker, err := NewConfirmationTracker(policy)
+//	if err != nil {
+//	    return err
+//	}
+//	scanner.Subscribe(tracker.Handle)
+//	subscriber.Subscribe(tracker.Handle)
+//	tracker.Subscribe(func(e Event) {
+//	    credit(e.(DepositFinalized))
+//	})
+type ConfirmationTracker struct {
+	*EventBus
+
+	policy  ConfirmationPolicy
+	mu      sync.Mutex
+	latest  int64
+	pending map[string]DepositDetected
+}
+
+// NewConfirmationTracker creates a tracker applying policy
+func NewConfirmationTracker(policy ConfirmationPolicy) (*ConfirmationTracker, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &ConfirmationTracker{
+		EventBus: NewEventBus(),
+		policy:   policy,
+		pending:  map[string]DepositDetected{},
+	}, nil
+}
+
+// Handle is the tracker's EventHandler. DepositDetected events start
+// tracking a deposit, finalizing it at once if it is already deep enough;
+// SnapshotAdvanced events finalize every deposit that now is. A deposit
+// with no snapshot ordinal yet waits until it is detected again with one.
+// Other events are ignored, as are deposits already pending.
+func (t *ConfirmationTracker) Handle(event Event) {
+	t.mu.Lock()
+	switch e := event.(type) {
+	case DepositDetected:
+		// a deposit seen again is ignored, unless it was first seen before
+		// any snapshot included it
+		if existing, ok := t.pending[e.Hash]; ok && (existing.Ordinal > 0 || e.Ordinal <= 0) {
+			t.mu.Unlock()
+			return
+		}
+		t.pending[e.Hash] = e
+		// the deposit's snapshot exists even if no SnapshotAdvanced said so
+		if e.Ordinal > t.latest {
+			t.latest = e.Ordinal
+		}
+	case SnapshotAdvanced:
+		if e.Ordinal <= t.latest {
+			t.mu.Unlock()
+			return
+		}
+		t.latest = e.Ordinal
+	default:
+		t.mu.Unlock()
+		return
+	}
+	finalized := t.finalize(event.OccurredAt())
+	t.mu.Unlock()
+
+	for _, deposit := range finalized {
+		t.Publish(deposit)
+	}
+}
+
+// Pending returns how many deposits are waiting for confirmations
+func (t *ConfirmationTracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// finalize removes and returns the pending deposits that are final at
+// t.latest, oldest first. Callers hold t.mu.
+func (t *ConfirmationTracker) finalize(at time.Time) []DepositFinalized {
+	var finalized []DepositFinalized
+	for hash, deposit := range t.pending {
+		if !t.policy.IsFinal(deposit.Destination, deposit.Amount, deposit.Ordinal, t.latest) {
+			continue
+		}
+		depth := t.policy.Depth(deposit.Destination, deposit.Amount)
+		delete(t.pending, hash)
+		finalized = append(finalized, DepositFinalized{
+			Hash:         deposit.Hash,
+			Source:       deposit.Source,
+			Destination:  deposit.Destination,
+			Amount:       deposit.Amount,
+			Ordinal:      deposit.Ordinal,
+			Depth:        depth,
+			FinalOrdinal: t.latest,
+			At:           at,
+		})
+	}
+	sort.Slice(finalized, func(i, j int) bool {
+		if finalized[i].Ordinal != finalized[j].Ordinal {
+			return finalized[i].Ordinal < finalized[j].Ordinal
+		}
+		return finalized[i].Hash < finalized[j].Hash
+	})
+	return finalized
+}
+
+// WaitForFinality polls explorer until the transaction hash is confirmed to
+// the depth policy requires for its destination and amount. The explorer
+// must implement ExplorerTransactionLookup.
+//
+// Returns the transaction once final, or the transaction as last seen (nil
+// if not yet indexed) together with ErrNotFinal when timeout passes first.
+//
+// Example:
+//
+//	tx, err := WaitForFinality(explorer, hash, policy, 10*time.Minute, 5*time.Second)
+//	if errors.Is(err, ErrNotFinal) {
+//	    // not deep enough yet; check again later
+//	}
+func WaitForFinality(explorer ExplorerAPI, hash string, policy ConfirmationPolicy, timeout time.Duration, interval time.Duration) (*ExplorerTransaction, error) {
+	lookup, ok := explorer.(ExplorerTransactionLookup)
+	if !ok {
+		return nil, ErrTransactionLookupUnsupported
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		tx, err := lookup.GetTransaction(hash)
+		if err != nil {
+			return nil, err
+		}
+		if tx != nil && tx.SnapshotOrdinal > 0 {
+			latest, err := explorer.GetLatestSnapshot()
+			if err != nil {
+				return nil, err
+			}
+			if latest != nil && policy.IsFinal(tx.Destination, tx.Amount, tx.SnapshotOrdinal, latest.Ordinal) {
+				return tx, nil
+			}
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return tx, ErrNotFinal
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,141 @@
+package constellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmationPolicy(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	cold, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	policy := ConfirmationPolicy{
+		DefaultDepth: 3,
+		Rules: []ConfirmationRule{
+			{MinAmount: 0, Depth: 1},
+			{MinAmount: TokenToUnits(1000), Depth: 10},
+			{Address: cold.Address, Depth: 5},
+		},
+	}
+	require.NoError(t, policy.Validate())
+
+	t.Run("picks the deepest matching rule", func(t *testing.T) {
+		assert.Equal(t, int64(3), policy.Depth(hot.Address, TokenToUnits(5)), "a shallower rule does not lower the default")
+		assert.Equal(t, int64(10), policy.Depth(hot.Address, TokenToUnits(1000)))
+		assert.Equal(t, int64(5), policy.Depth(cold.Address, TokenToUnits(5)))
+		assert.Equal(t, int64(10), policy.Depth(cold.Address, TokenToUnits(2000)))
+	})
+
+	t.Run("falls back to the default depth", func(t *testing.T) {
+		strict := ConfirmationPolicy{DefaultDepth: 3, Rules: []ConfirmationRule{{Address: cold.Address, Depth: 5}}}
+		assert.Equal(t, int64(3), strict.Depth(hot.Address, 1))
+	})
+
+	t.Run("is final at its depth", func(t *testing.T) {
+		assert.False(t, policy.IsFinal(hot.Address, TokenToUnits(1000), 100, 109))
+		assert.True(t, policy.IsFinal(hot.Address, TokenToUnits(1000), 100, 110))
+		assert.False(t, ConfirmationPolicy{}.IsFinal(hot.Address, 1, 0, 100), "not in a snapshot")
+	})
+
+	t.Run("rejects invalid policies", func(t *testing.T) {
+		for _, invalid := range []ConfirmationPolicy{
+			{DefaultDepth: -1},
+			{Rules: []ConfirmationRule{{Depth: -1}}},
+			{Rules: []ConfirmationRule{{MinAmount: -1}}},
+			{Rules: []ConfirmationRule{{Address: "DAGnope"}}},
+		} {
+			assert.ErrorIs(t, invalid.Validate(), ErrInvalidConfirmationPolicy)
+		}
+	})
+}
+
+func TestConfirmationTracker(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	policy := ConfirmationPolicy{
+		DefaultDepth: 1,
+		Rules:        []ConfirmationRule{{MinAmount: TokenToUnits(1000), Depth: 10}},
+	}
+	tracker, err := NewConfirmationTracker(policy)
+	require.NoError(t, err)
+
+	var finalized []DepositFinalized
+	tracker.Subscribe(func(e Event) { finalized = append(finalized, e.(DepositFinalized)) })
+	snapshot := func(ordinal int64) { tracker.Handle(SnapshotAdvanced{Ordinal: ordinal, At: time.Now()}) }
+	deposit := func(hash string, amount int64, ordinal int64) {
+		tracker.Handle(DepositDetected{Hash: hash, Destination: kp.Address, Amount: amount, Ordinal: ordinal, At: time.Now()})
+	}
+
+	snapshot(100)
+	deposit("small", TokenToUnits(5), 100)
+	deposit("large", TokenToUnits(5000), 100)
+	deposit("unconfirmed", TokenToUnits(5), 0)
+	assert.Empty(t, finalized)
+	assert.Equal(t, 3, tracker.Pending())
+
+	snapshot(101)
+	require.Len(t, finalized, 1)
+	assert.Equal(t, "small", finalized[0].Hash)
+	assert.Equal(t, int64(1), finalized[0].Depth)
+	assert.Equal(t, int64(101), finalized[0].FinalOrdinal)
+
+	// seen again once included, and already deep enough
+	deposit("unconfirmed", TokenToUnits(5), 100)
+	require.Len(t, finalized, 2)
+	assert.Equal(t, "unconfirmed", finalized[1].Hash)
+
+	// duplicates and stale snapshots change nothing
+	deposit("large", TokenToUnits(5000), 100)
+	snapshot(101)
+	assert.Len(t, finalized, 2)
+
+	snapshot(110)
+	require.Len(t, finalized, 3)
+	assert.Equal(t, "large", finalized[2].Hash)
+	assert.Equal(t, int64(10), finalized[2].Depth)
+	assert.Zero(t, tracker.Pending())
+
+	t.Run("rejects an invalid policy", func(t *testing.T) {
+		_, err := NewConfirmationTracker(ConfirmationPolicy{DefaultDepth: -1})
+		assert.ErrorIs(t, err, ErrInvalidConfirmationPolicy)
+	})
+}
+
+func TestWaitForFinality(t *testing.T) {
+	explorer := newFakeExplorer()
+	explorer.snapshots[20] = &ExplorerSnapshot{Ordinal: 20}
+
+	t.Run("returns a transaction deep enough", func(t *testing.T) {
+		explorer.latest = 20
+		tx, err := WaitForFinality(explorer, "tx1", ConfirmationPolicy{DefaultDepth: 10}, time.Second, time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, "tx1", tx.Hash)
+	})
+
+	t.Run("times out when not deep enough", func(t *testing.T) {
+		policy := ConfirmationPolicy{Rules: []ConfirmationRule{{MinAmount: 200, Depth: 10}}}
+		tx, err := WaitForFinality(explorer, "tx2", policy, 20*time.Millisecond, 5*time.Millisecond)
+		assert.ErrorIs(t, err, ErrNotFinal)
+		require.NotNil(t, tx)
+		assert.Equal(t, int64(12), tx.SnapshotOrdinal)
+	})
+
+	t.Run("times out while no snapshot is indexed", func(t *testing.T) {
+		explorer.latest = 99
+		tx, err := WaitForFinality(explorer, "tx1", ConfirmationPolicy{}, 10*time.Millisecond, 5*time.Millisecond)
+		assert.ErrorIs(t, err, ErrNotFinal)
+		require.NotNil(t, tx)
+		explorer.latest = 20
+	})
+
+	t.Run("times out on unknown transactions", func(t *testing.T) {
+		tx, err := WaitForFinality(explorer, "missing", ConfirmationPolicy{}, 10*time.Millisecond, 5*time.Millisecond)
+		assert.ErrorIs(t, err, ErrNotFinal)
+		assert.Nil(t, tx)
+	})
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"math"
 	"math/big"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -28,35 +27,118 @@ var (
 	ErrSameAddress = errors.New("source and destination addresses cannot be the same")
 	// ErrInvalidAddress indicates an invalid DAG address
 	ErrInvalidAddress = errors.New("invalid DAG address")
+	// ErrInvalidSalt indicates a salt that is not a signed 64-bit integer
+	ErrInvalidSalt = errors.New("invalid salt")
+	// ErrAmountOutOfRange indicates a token amount that does not fit in int64 units
+	ErrAmountOutOfRange = errors.New("token amount out of range")
+	// ErrInvalidTokenAmount indicates a malformed decimal token amount
+	ErrInvalidTokenAmount = errors.New("invalid token amount")
+	// ErrMissingLastReference indicates a sender with no last transaction
+	// reference to chain from
+	ErrMissingLastReference = errors.New("missing last transaction reference")
+	// ErrMissingSigner indicates a SenderTransfer without a Signer
+	ErrMissingSigner = errors.New("transfer has no signer")
 )
 
+// tokenDecimalPlaces is the number of decimal places of a token (1e-8)
+const tokenDecimalPlaces = 8
+
 // TokenToUnits converts token amount to smallest units
+//
+// Amounts beyond the int64 range, NaN and infinities give undefined results;
+// use TokenToUnitsChecked or ParseTokenAmount for untrusted input.
 func TokenToUnits(amount float64) int64 {
 	return int64(math.Floor(amount * 1e8))
 }
 
-// UnitsToToken converts smallest units to token amount
-func UnitsToToken(units int64) float64 {
-	return float64(units) * TokenDecimals
+// TokenToUnitsChecked converts a token amount to smallest units, returning
+// ErrAmountOutOfRange for NaN, infinities and amounts that overflow int64
+func TokenToUnitsChecked(amount float64) (int64, error) {
+	units := math.Floor(amount * 1e8)
+	// float64(math.MaxInt64) rounds up to 2^63, which no longer fits
+	if math.IsNaN(units) || units >= math.MaxInt64 || units < math.MinInt64 {
+		return 0, fmt.Errorf("%w: %v", ErrAmountOutOfRange, amount)
+	}
+	return int64(units), nil
 }
 
-// IsValidDAGAddress validates a DAG address format
-func IsValidDAGAddress(address string) bool {
-	// DAG addresses: DAG + parity digit (0-8) + 36 base58 chars = 40 chars total
-	if !strings.HasPrefix(address, "DAG") {
-		return false
+// ParseTokenAmount converts a decimal token amount such as "100.5" to
+// smallest units exactly, without float rounding. Returns
+// ErrInvalidTokenAmount for malformed input or more than 8 decimal places,
+// and ErrAmountOutOfRange when the result overflows int64.
+func ParseTokenAmount(amount string) (int64, error) {
+	units, err := parseTokenUnits(amount)
+	if err != nil {
+		return 0, err
 	}
-	// Exact length check
-	if len(address) != 40 {
-		return false
+	if !units.IsInt64() {
+		return 0, fmt.Errorf("%w: %q", ErrAmountOutOfRange, amount)
 	}
-	// Position 3 (after DAG) must be parity digit 0-8
-	if address[3] < '0' || address[3] > '8' {
-		return false
+	return units.Int64(), nil
+}
+
+// parseTokenUnits converts a decimal token amount to smallest units of any
+// magnitude
+func parseTokenUnits(amount string) (*big.Int, error) {
+	digits := amount
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" && fraction == "" || hasPoint && fraction == "" || len(fraction) > tokenDecimalPlaces {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTokenAmount, amount)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	for _, part := range []string{whole, fraction} {
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidTokenAmount, amount)
+			}
+		}
+	}
+
+	units, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", tokenDecimalPlaces-len(fraction)), 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTokenAmount, amount)
+	}
+	if negative {
+		units.Neg(units)
+	}
+	return units, nil
+}
+
+// FormatTokenAmount formats smallest units as an exact decimal token amount,
+// the inverse of ParseTokenAmount (e.g. 10050000000 -> "100.5")
+func FormatTokenAmount(units int64) string {
+	return formatTokenUnits(new(big.Int).SetInt64(units))
+}
+
+// formatTokenUnits formats smallest units of any magnitude as a decimal
+// token amount
+func formatTokenUnits(units *big.Int) string {
+	value := new(big.Int).Set(units)
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+		value.Neg(value)
+	}
+	digits := value.String()
+	if len(digits) <= tokenDecimalPlaces {
+		digits = strings.Repeat("0", tokenDecimalPlaces-len(digits)+1) + digits
 	}
-	// Remaining 36 characters must be base58 (no 0, O, I, l)
-	pattern := regexp.MustCompile(`^[123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz]{36}$`)
-	return pattern.MatchString(address[4:])
+	whole, fraction := digits[:len(digits)-tokenDecimalPlaces], strings.TrimRight(digits[len(digits)-tokenDecimalPlaces:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// UnitsToToken converts smallest units to token amount
+func UnitsToToken(units int64) float64 {
+	return float64(units) * TokenDecimals
 }
 
 // generateSalt generates a random salt for transaction uniqueness
@@ -71,268 +153,306 @@ func generateSalt() string {
 	return salt.String()
 }
 
+// ParseSalt parses a transaction salt: decimal digits, or hex digits after a
+// 0x prefix, optionally preceded by a minus sign. The network stores salts
+// as signed 64-bit integers, so the value must be between math.MinInt64 and
+// math.MaxInt64.
+func ParseSalt(salt string) (*big.Int, error) {
+	negative, digits, base := splitSalt(salt)
+	if !saltDigitsValid(digits, base) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSalt, salt)
+	}
+
+	value, ok := new(big.Int).SetString(digits, base)
+	if negative && ok {
+		value.Neg(value)
+	}
+	if !ok || !value.IsInt64() {
+		return nil, fmt.Errorf("%w: %q out of range", ErrInvalidSalt, salt)
+	}
+	return value, nil
+}
+
+// validSalt reports whether ParseSalt accepts salt, without allocating
+func validSalt(salt string) bool {
+	negative, digits, base := splitSalt(salt)
+	if !saltDigitsValid(digits, base) {
+		return false
+	}
+	var err error
+	if negative {
+		_, err = strconv.ParseInt(salt[:1]+digits, base, 64)
+	} else {
+		_, err = strconv.ParseInt(digits, base, 64)
+	}
+	return err == nil
+}
+
+// splitSalt splits a salt into its sign, digits and base
+func splitSalt(salt string) (negative bool, digits string, base int) {
+	if strings.HasPrefix(salt, "-") {
+		negative, salt = true, salt[1:]
+	}
+	if strings.HasPrefix(salt, "0x") || strings.HasPrefix(salt, "0X") {
+		return negative, salt[2:], 16
+	}
+	return negative, salt, 10
+}
+
+func saltDigitsValid(digits string, base int) bool {
+	if digits == "" {
+		return false
+	}
+	for i := 0; i < len(digits); i++ {
+		c := digits[i]
+		switch {
+		case c >= '0' && c <= '9':
+		case base == 16 && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // encodeTransaction encodes a currency transaction for hashing
 // Matches TransactionV2.getEncoded() from dag4.js
 func encodeTransaction(tx *CurrencyTransaction) string {
 	parentCount := "2" // Always 2 parents for v2
-	source := tx.Value.Source
-	destination := tx.Value.Destination
-	amountHex := strconv.FormatInt(tx.Value.Amount, 16)
-	parentHash := tx.Value.Parent.Hash
-	ordinal := strconv.Itoa(tx.Value.Parent.Ordinal)
-	fee := strconv.FormatInt(tx.Value.Fee, 10)
-
-	// Convert salt to hex
-	saltInt, _ := new(big.Int).SetString(tx.Value.Salt, 10)
-	saltHex := fmt.Sprintf("%x", saltInt)
+	fields := transactionEncodingFields(tx)
 
 	// Build encoded string (length-prefixed format)
-	parts := []string{
-		parentCount,
-		strconv.Itoa(len(source)),
-		source,
-		strconv.Itoa(len(destination)),
-		destination,
-		strconv.Itoa(len(amountHex)),
-		amountHex,
-		strconv.Itoa(len(parentHash)),
-		parentHash,
-		strconv.Itoa(len(ordinal)),
-		ordinal,
-		strconv.Itoa(len(fee)),
-		fee,
-		strconv.Itoa(len(saltHex)),
-		saltHex,
+	parts := make([]string, 0, 1+2*len(fields))
+	parts = append(parts, parentCount)
+	for _, field := range fields {
+		parts = append(parts, strconv.Itoa(len(field.value)), field.value)
 	}
 
 	return strings.Join(parts, "")
 }
 
-// kryoSerialize performs Kryo serialization for transaction encoding
-// Matches txEncode.kryoSerialize() from dag4.js
-func kryoSerialize(msg string, setReferences bool) []byte {
-	// UTF-8 length encoding
-	utf8Length := func(value int) []byte {
-		if value>>6 == 0 {
-			return []byte{byte(value | 0x80)}
-		} else if value>>13 == 0 {
-			return []byte{byte(value | 0x40 | 0x80), byte(value >> 6)}
-		} else if value>>20 == 0 {
-			return []byte{
-				byte(value | 0x40 | 0x80),
-				byte((value >> 6) | 0x80),
-				byte(value >> 13),
-			}
-		} else if value>>27 == 0 {
-			return []byte{
-				byte(value | 0x40 | 0x80),
-				byte((value >> 6) | 0x80),
-				byte((value >> 13) | 0x80),
-				byte(value >> 20),
-			}
-		} else {
-			return []byte{
-				byte(value | 0x40 | 0x80),
-				byte((value >> 6) | 0x80),
-				byte((value >> 13) | 0x80),
-				byte((value >> 20) | 0x80),
-				byte(value >> 27),
-			}
-		}
-	}
+// encodedField is one length-prefixed field of the transaction encoding
+type encodedField struct {
+	name  string
+	value string
+}
 
-	// Build serialized message
-	var result []byte
-	result = append(result, 0x03)
-	if setReferences {
-		result = append(result, 0x01)
+// transactionEncodingFields returns the fields encodeTransaction writes, in
+// order
+func transactionEncodingFields(tx *CurrencyTransaction) []encodedField {
+	// Convert salt to hex. An invalid salt is encoded verbatim so the hash
+	// stays deterministic; signing and verification reject such transactions.
+	saltHex := tx.Value.Salt
+	if saltInt, err := ParseSalt(tx.Value.Salt); err == nil {
+		saltHex = saltInt.Text(16)
 	}
 
-	length := len(msg) + 1
-	result = append(result, utf8Length(length)...)
-	result = append(result, []byte(msg)...)
-
-	return result
+	return []encodedField{
+		{name: "source", value: tx.Value.Source},
+		{name: "destination", value: tx.Value.Destination},
+		{name: "amount", value: strconv.FormatInt(tx.Value.Amount, 16)},
+		{name: "parentHash", value: tx.Value.Parent.Hash},
+		{name: "parentOrdinal", value: strconv.Itoa(tx.Value.Parent.Ordinal)},
+		{name: "fee", value: strconv.FormatInt(tx.Value.Fee, 10)},
+		{name: "salt", value: saltHex},
+	}
 }
 
 // CreateCurrencyTransaction creates a metagraph token transaction
 func CreateCurrencyTransaction(params TransferParams, privateKeyHex string, lastRef TransactionReference) (*CurrencyTransaction, error) {
-	// Get source address from private key
-	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
+	// Convert amounts to smallest units
+	amount, fee, err := transferUnits(params)
 	if err != nil {
-		return nil, fmt.Errorf("invalid private key hex: %w", err)
+		return nil, err
 	}
 
-	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
-	publicKeyHex := hex.EncodeToString(privateKey.PubKey().SerializeUncompressed())
-	source := GetAddress(publicKeyHex)
+	return createCurrencyTransactionUnits(params.Destination, amount, fee, params.Salt, privateKeyHex, lastRef)
+}
 
-	// Validate addresses
-	if !IsValidDAGAddress(source) {
-		return nil, ErrInvalidAddress
+// CreateCurrencyTransactionWithSigner is CreateCurrencyTransaction with the
+// key behind a Signer, such as a hardware wallet, KMS key or remote signing
+// service. The source address is derived from the signer's public key.
+func CreateCurrencyTransactionWithSigner(params TransferParams, signer Signer, lastRef TransactionReference) (*CurrencyTransaction, error) {
+	amount, fee, err := transferUnits(params)
+	if err != nil {
+		return nil, err
 	}
-	if !IsValidDAGAddress(params.Destination) {
-		return nil, ErrInvalidAddress
+	return createCurrencyTransactionWithSigner(params.Destination, amount, fee, params.Salt, signer, lastRef)
+}
+
+// transferUnits returns the amount and fee of params in smallest units,
+// taking ExactAmount and ExactFee over their float64 counterparts when set
+func transferUnits(params TransferParams) (amount int64, fee int64, err error) {
+	if params.ExactAmount.units != nil {
+		amount, err = params.ExactAmount.Units()
+	} else {
+		amount, err = TokenToUnitsChecked(params.Amount)
 	}
-	if source == params.Destination {
-		return nil, ErrSameAddress
+	if err != nil {
+		return 0, 0, err
 	}
-
-	// Convert amounts to smallest units
-	amount := TokenToUnits(params.Amount)
-	fee := TokenToUnits(params.Fee)
-
-	// Validate amounts
-	if amount < 1 {
-		return nil, ErrInvalidAmount
+	if params.ExactFee.units != nil {
+		fee, err = params.ExactFee.Units()
+	} else {
+		fee, err = TokenToUnitsChecked(params.Fee)
 	}
-	if fee < 0 {
-		return nil, ErrInvalidFee
+	if err != nil {
+		return 0, 0, err
 	}
+	return amount, fee, nil
+}
 
-	// Generate salt
-	salt := generateSalt()
-
-	// Create transaction
-	tx := &CurrencyTransaction{
-		Value: CurrencyTransactionValue{
-			Source:      source,
-			Destination: params.Destination,
-			Amount:      amount,
-			Fee:         fee,
-			Parent:      lastRef,
-			Salt:        salt,
-		},
-		Proofs: []SignatureProof{},
-	}
+// CreateCurrencyTransactionUnits is CreateCurrencyTransaction with amounts
+// in smallest units. Exchanges should prefer it: a float64 token amount
+// cannot represent every unit above 2^53 units (about 90 million tokens).
+func CreateCurrencyTransactionUnits(params TransferUnitsParams, privateKeyHex string, lastRef TransactionReference) (*CurrencyTransaction, error) {
+	return createCurrencyTransactionUnits(params.Destination, params.AmountUnits, params.FeeUnits, params.Salt, privateKeyHex, lastRef)
+}
 
-	// Encode and hash
-	encoded := encodeTransaction(tx)
-	serialized := kryoSerialize(encoded, false)
-	hashBytes := sha256.Sum256(serialized)
-	hashHex := hex.EncodeToString(hashBytes[:])
+// CreateCurrencyTransactionUnitsWithSigner is CreateCurrencyTransactionUnits
+// with the key behind a Signer
+func CreateCurrencyTransactionUnitsWithSigner(params TransferUnitsParams, signer Signer, lastRef TransactionReference) (*CurrencyTransaction, error) {
+	return createCurrencyTransactionWithSigner(params.Destination, params.AmountUnits, params.FeeUnits, params.Salt, signer, lastRef)
+}
 
-	// Sign
-	signature, err := signHashInternal(hashHex, privateKeyHex)
+// createCurrencyTransactionUnits creates and signs a transaction from amounts
+// already expressed in smallest units. An empty salt means a random one.
+func createCurrencyTransactionUnits(destination string, amount int64, fee int64, salt string, privateKeyHex string, lastRef TransactionReference) (*CurrencyTransaction, error) {
+	signer, err := NewPrivateKeySigner(privateKeyHex)
 	if err != nil {
 		return nil, err
 	}
+	return createCurrencyTransactionWithSigner(destination, amount, fee, salt, signer, lastRef)
+}
 
-	// Create proof
-	publicKeyID := publicKeyHex[2:] // Remove '04' prefix
-	proof := SignatureProof{
-		ID:        publicKeyID,
-		Signature: signature,
+func createCurrencyTransactionWithSigner(destination string, amount int64, fee int64, salt string, signer Signer, lastRef TransactionReference) (*CurrencyTransaction, error) {
+	tx, err := buildSalted(NewTransactionBuilder(GetAddress(signer.PublicKey())), destination, amount, fee, salt, lastRef)
+	if err != nil {
+		return nil, err
 	}
-
-	// Add proof to transaction
-	tx.Proofs = append(tx.Proofs, proof)
-
-	return tx, nil
+	return SignTransaction(tx, signer)
 }
 
 // CreateCurrencyTransactionBatch creates multiple metagraph token transactions (batch)
 func CreateCurrencyTransactionBatch(transfers []TransferParams, privateKeyHex string, lastRef TransactionReference) ([]*CurrencyTransaction, error) {
-	transactions := make([]*CurrencyTransaction, 0, len(transfers))
-	currentRef := TransactionReference{
-		Hash:    lastRef.Hash,
-		Ordinal: lastRef.Ordinal,
+	signer, err := NewPrivateKeySigner(privateKeyHex)
+	if err != nil {
+		return nil, err
 	}
+	return CreateCurrencyTransactionBatchWithSigner(transfers, signer, lastRef)
+}
 
-	for _, transfer := range transfers {
-		tx, err := CreateCurrencyTransaction(transfer, privateKeyHex, currentRef)
-		if err != nil {
+// CreateCurrencyTransactionBatchWithSigner is CreateCurrencyTransactionBatch
+// with the key behind a Signer
+func CreateCurrencyTransactionBatchWithSigner(transfers []TransferParams, signer Signer, lastRef TransactionReference) ([]*CurrencyTransaction, error) {
+	transactions, err := BuildBatch(NewTransactionBuilder(GetAddress(signer.PublicKey())), transfers, lastRef)
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range transactions {
+		if transactions[i], err = SignTransaction(tx, signer); err != nil {
 			return nil, err
 		}
-
-		// Calculate hash for next transaction's parent reference
-		hashResult := HashCurrencyTransaction(tx)
-
-		// Update reference for next transaction
-		currentRef = TransactionReference{
-			Hash:    hashResult.Value,
-			Ordinal: currentRef.Ordinal + 1,
-		}
-
-		transactions = append(transactions, tx)
 	}
-
 	return transactions, nil
 }
 
-// SignCurrencyTransaction adds a signature to an existing currency transaction (for multi-sig)
-func SignCurrencyTransaction(tx *CurrencyTransaction, privateKeyHex string) (*CurrencyTransaction, error) {
-	// Encode and hash
-	encoded := encodeTransaction(tx)
-	serialized := kryoSerialize(encoded, false)
-	hashBytes := sha256.Sum256(serialized)
-	hashHex := hex.EncodeToString(hashBytes[:])
-
-	// Sign
-	signature, err := signHashInternal(hashHex, privateKeyHex)
-	if err != nil {
-		return nil, err
+// CreateCurrencyTransactionBatchMultiSender creates a batch of transactions
+// from several source wallets, each transfer signed by its own Signer.
+// Every sender's transactions are chained separately, starting at its entry
+// in lastRefs, keyed by source address; a sender missing from lastRefs
+// returns ErrMissingLastReference, and a transfer without a Signer
+// ErrMissingSigner. Transactions are returned in the order of transfers,
+// together with each sender's new last reference to pass to the next batch.
+// Nothing is signed unless every transfer builds.
+//
+// Example:
+//
+//	transactions, lastRefs, err := CreateCurrencyTransactionBatchMultiSender(
+//	    []SenderTransfer{
+//	        {TransferParams: TransferParams{Destination: user1, Amount: 10}, Signer: hotWallet1},
+//	        {TransferParams: TransferParams{Destination: user2, Amount: 20}, Signer: hotWallet2},
+//	        {TransferParams: TransferParams{Destination: user3, Amount: 30}, Signer: hotWallet1},
+//	    },
+//	    map[string]TransactionReference{hotAddress1: lastRef1, hotAddress2: lastRef2},
+//	)
+func CreateCurrencyTransactionBatchMultiSender(transfers []SenderTransfer, lastRefs map[string]TransactionReference) ([]*CurrencyTransaction, map[string]TransactionReference, error) {
+	// split the transfers by sender, remembering where each one goes
+	type senderBatch struct {
+		transfers []TransferParams
+		indexes   []int
 	}
-
-	// Get public key
-	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
-	if err != nil {
-		return nil, fmt.Errorf("invalid private key hex: %w", err)
+	batches := map[string]*senderBatch{}
+	var sources []string
+	for i, transfer := range transfers {
+		if transfer.Signer == nil {
+			return nil, nil, fmt.Errorf("%w: transfer %d", ErrMissingSigner, i)
+		}
+		source := GetAddress(transfer.Signer.PublicKey())
+		batch, ok := batches[source]
+		if !ok {
+			if _, ok := lastRefs[source]; !ok {
+				return nil, nil, fmt.Errorf("%w: sender %s", ErrMissingLastReference, RedactAddress(source))
+			}
+			batch = &senderBatch{}
+			batches[source] = batch
+			sources = append(sources, source)
+		}
+		batch.transfers = append(batch.transfers, transfer.TransferParams)
+		batch.indexes = append(batch.indexes, i)
 	}
 
-	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
-	publicKeyHex := hex.EncodeToString(privateKey.PubKey().SerializeUncompressed())
-
-	// Verify signature
-	if !verifyHashInternal(publicKeyHex, hashHex, signature) {
-		return nil, errors.New("sign-verify failed")
+	parents := make(map[string]TransactionReference, len(lastRefs))
+	for address, ref := range lastRefs {
+		parents[address] = ref
 	}
-
-	// Create proof
-	publicKeyID := publicKeyHex[2:] // Remove '04' prefix
-	proof := SignatureProof{
-		ID:        publicKeyID,
-		Signature: signature,
+	transactions := make([]*CurrencyTransaction, len(transfers))
+	for _, source := range sources {
+		batch := batches[source]
+		lastRef := lastRefs[source]
+		built, err := BuildBatch(NewTransactionBuilder(source), batch.transfers, lastRef)
+		if err != nil {
+			return nil, nil, err
+		}
+		for j, tx := range built {
+			transactions[batch.indexes[j]] = tx
+		}
+		parents[source] = *GetTransactionReference(built[len(built)-1], lastRef.Ordinal+len(built))
 	}
-
-	// Create new transaction with updated proofs
-	newTx := &CurrencyTransaction{
-		Value:  tx.Value,
-		Proofs: append([]SignatureProof{}, tx.Proofs...),
+	for i, tx := range transactions {
+		signed, err := SignTransaction(tx, transfers[i].Signer)
+		if err != nil {
+			return nil, nil, err
+		}
+		transactions[i] = signed
 	}
-	newTx.Proofs = append(newTx.Proofs, proof)
+	return transactions, parents, nil
+}
 
-	return newTx, nil
+// SignCurrencyTransaction adds a signature to an existing currency transaction (for multi-sig)
+func SignCurrencyTransaction(tx *CurrencyTransaction, privateKeyHex string) (*CurrencyTransaction, error) {
+	signer, err := NewPrivateKeySigner(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	return CosignTransaction(tx, signer)
 }
 
 // VerifyCurrencyTransaction verifies all signatures on a currency transaction
 func VerifyCurrencyTransaction(tx *CurrencyTransaction) *VerificationResult {
-	// Encode and hash
-	encoded := encodeTransaction(tx)
-	serialized := kryoSerialize(encoded, false)
-	hashBytes := sha256.Sum256(serialized)
-	hashHex := hex.EncodeToString(hashBytes[:])
-
-	validProofs := []SignatureProof{}
-	invalidProofs := []SignatureProof{}
-
-	// Verify each proof
-	for _, proof := range tx.Proofs {
-		publicKey := "04" + proof.ID // Add back '04' prefix
-		isValid := verifyHashInternal(publicKey, hashHex, proof.Signature)
-
-		if isValid {
-			validProofs = append(validProofs, proof)
-		} else {
-			invalidProofs = append(invalidProofs, proof)
-		}
-	}
+	scratch := acquireVerifyScratch()
+	defer releaseVerifyScratch(scratch)
+	return scratch.VerifyCurrencyTransaction(tx)
+}
 
-	return &VerificationResult{
-		IsValid:       len(invalidProofs) == 0 && len(validProofs) > 0,
-		ValidProofs:   validProofs,
-		InvalidProofs: invalidProofs,
-	}
+// VerifyCurrencyTransactionStrict reports whether every signature on a
+// currency transaction is valid. Unlike VerifyCurrencyTransaction it builds no
+// result lists and stops at the first malformed or failing proof, which makes
+// filtering untrusted input cheap.
+func VerifyCurrencyTransactionStrict(tx *CurrencyTransaction) bool {
+	scratch := acquireVerifyScratch()
+	defer releaseVerifyScratch(scratch)
+	return scratch.VerifyCurrencyTransactionStrict(tx)
 }
 
 // EncodeCurrencyTransaction encodes a currency transaction for hashing
@@ -340,7 +460,9 @@ func EncodeCurrencyTransaction(tx *CurrencyTransaction) string {
 	return encodeTransaction(tx)
 }
 
-// HashCurrencyTransaction hashes a currency transaction
+// HashCurrencyTransaction hashes a currency transaction. A transaction
+// encoding to more than MaxKryoStringLength bytes still gets a hash, but
+// SignTransaction rejects it and verification treats it as invalid.
 func HashCurrencyTransaction(tx *CurrencyTransaction) *Hash {
 	encoded := encodeTransaction(tx)
 	serialized := kryoSerialize(encoded, false)
@@ -367,7 +489,7 @@ func signHashInternal(hashHex string, privateKeyHex string) (string, error) {
 	// Parse private key
 	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
 	if err != nil {
-		return "", fmt.Errorf("invalid private key hex: %w", err)
+		return "", invalidPrivateKeyHex(err)
 	}
 
 	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
@@ -386,31 +508,8 @@ func signHashInternal(hashHex string, privateKeyHex string) (string, error) {
 
 // verifyHashInternal verifies a signature on a hash
 func verifyHashInternal(publicKeyHex string, hashHex string, signatureHex string) bool {
-	// Parse public key
-	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
-	if err != nil {
-		return false
-	}
-
-	publicKey, err := btcec.ParsePubKey(publicKeyBytes)
-	if err != nil {
-		return false
-	}
-
-	// Compute digest
-	digest := ComputeDigestFromHash(hashHex)
-
-	// Parse signature
-	signatureBytes, err := hex.DecodeString(signatureHex)
-	if err != nil {
-		return false
-	}
-
-	signature, err := ecdsa.ParseDERSignature(signatureBytes)
-	if err != nil {
-		return false
-	}
-
-	// Verify
-	return signature.Verify(digest, publicKey)
+	scratch := acquireVerifyScratch()
+	defer releaseVerifyScratch(scratch)
+	isValid, _ := scratch.verify(publicKeyHex, hashHex, signatureHex)
+	return isValid
 }
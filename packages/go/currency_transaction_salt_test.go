@@ -0,0 +1,126 @@
+package constellation
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSalt(t *testing.T) {
+	valid := map[string]int64{
+		"0":                    0,
+		"9007199254740991":     9007199254740991,
+		"0x1f":                 31,
+		"0XFF":                 255,
+		"9223372036854775807":  math.MaxInt64,
+		"-1":                   -1,
+		"-0x10":                -16,
+		"-9223372036854775808": math.MinInt64,
+	}
+	for salt, want := range valid {
+		value, err := ParseSalt(salt)
+		require.NoError(t, err, salt)
+		assert.Equal(t, want, value.Int64(), salt)
+		assert.True(t, validSalt(salt), salt)
+	}
+
+	for _, salt := range []string{"", "0x", "-", "--1", "0x-1", "+1", "1_000", " 1", "12a", "0x1g", "9223372036854775808", "0x8000000000000000", "-9223372036854775809"} {
+		_, err := ParseSalt(salt)
+		assert.ErrorIs(t, err, ErrInvalidSalt, salt)
+		assert.False(t, validSalt(salt), salt)
+	}
+}
+
+func TestSaltRoundTrip(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, _ := GenerateKeyPair()
+	signer, err := NewPrivateKeySigner(keyPair.PrivateKey)
+	require.NoError(t, err)
+	builder := NewTransactionBuilder(keyPair.Address)
+
+	t.Run("generated salts encode, sign and decode unchanged", func(t *testing.T) {
+		property := func() bool {
+			tx, err := builder.Build(destination.Address, 1, 0, GenesisReference)
+			if err != nil {
+				return false
+			}
+			salt, err := ParseSalt(tx.Value.Salt)
+			if err != nil || salt.String() != tx.Value.Salt {
+				return false
+			}
+			saltHex := salt.Text(16)
+			if !strings.HasSuffix(EncodeCurrencyTransaction(tx), strconv.Itoa(len(saltHex))+saltHex) {
+				return false
+			}
+
+			signed, err := SignTransaction(tx, signer)
+			if err != nil {
+				return false
+			}
+			data, err := json.Marshal(signed)
+			if err != nil {
+				return false
+			}
+			var decoded CurrencyTransaction
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				return false
+			}
+			return decoded.Value == signed.Value && VerifyCurrencyTransactionStrict(&decoded)
+		}
+		require.NoError(t, quick.Check(property, &quick.Config{MaxCount: 200}))
+	})
+
+	t.Run("any int64 salt hashes the same in decimal and hex", func(t *testing.T) {
+		tx, err := builder.Build(destination.Address, 1, 0, GenesisReference)
+		require.NoError(t, err)
+		property := func(n int64) bool {
+			if n < 0 {
+				n = -(n + 1)
+			}
+			decimal, hexed := *tx, *tx
+			decimal.Value.Salt = strconv.FormatInt(n, 10)
+			hexed.Value.Salt = "0x" + big.NewInt(n).Text(16)
+			return HashCurrencyTransaction(&decimal).Value == HashCurrencyTransaction(&hexed).Value
+		}
+		require.NoError(t, quick.Check(property, nil))
+	})
+
+	t.Run("building normalizes a given hex salt", func(t *testing.T) {
+		unsigned, err := BuildBatch(builder, []TransferParams{{Destination: destination.Address, Amount: 1, Salt: "0x1F"}}, GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, "31", unsigned[0].Value.Salt)
+
+		signed, err := SignTransaction(unsigned[0], signer)
+		require.NoError(t, err)
+		assert.Equal(t, unsigned[0].Value, signed.Value, "signing leaves a built transaction unchanged")
+	})
+
+	t.Run("signing normalizes hex salts and rejects invalid ones", func(t *testing.T) {
+		tx, err := builder.Build(destination.Address, 1, 0, GenesisReference)
+		require.NoError(t, err)
+		hash := HashCurrencyTransaction(tx).Value
+
+		salt, _ := ParseSalt(tx.Value.Salt)
+		tx.Value.Salt = "0x" + salt.Text(16)
+		signed, err := SignTransaction(tx, signer)
+		require.NoError(t, err)
+		assert.Equal(t, salt.String(), signed.Value.Salt)
+		assert.Equal(t, hash, HashCurrencyTransaction(signed).Value)
+
+		tx.Value.Salt = "not-a-salt"
+		_, err = SignTransaction(tx, signer)
+		assert.ErrorIs(t, err, ErrInvalidSalt)
+
+		signed.Value.Salt = "12x"
+		assert.False(t, VerifyCurrencyTransaction(signed).IsValid)
+		assert.False(t, VerifyCurrencyTransactionStrict(signed))
+	})
+}
@@ -1,6 +1,11 @@
 package constellation
 
 import (
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -205,6 +210,64 @@ func TestBatchTransactions(t *testing.T) {
 			t.Errorf("Transaction 2 ordinal = %d, want 7", txns[2].Value.Parent.Ordinal)
 		}
 	})
+
+	t.Run("CreateCurrencyTransactionBatchMultiSender chains each sender separately", func(t *testing.T) {
+		wallet1, _ := GenerateKeyPair()
+		wallet2, _ := GenerateKeyPair()
+		recipient, _ := GenerateKeyPair()
+		signer1, _ := NewPrivateKeySigner(wallet1.PrivateKey)
+		signer2, _ := NewPrivateKeySigner(wallet2.PrivateKey)
+
+		lastRef1 := TransactionReference{Hash: strings.Repeat("a", 64), Ordinal: 5}
+		lastRef2 := TransactionReference{Hash: strings.Repeat("b", 64), Ordinal: 40}
+		transfers := []SenderTransfer{
+			{TransferParams: TransferParams{Destination: recipient.Address, Amount: 1}, Signer: signer1},
+			{TransferParams: TransferParams{Destination: recipient.Address, Amount: 2}, Signer: signer2},
+			{TransferParams: TransferParams{Destination: recipient.Address, Amount: 3}, Signer: signer1},
+		}
+
+		txns, lastRefs, err := CreateCurrencyTransactionBatchMultiSender(transfers, map[string]TransactionReference{
+			wallet1.Address: lastRef1,
+			wallet2.Address: lastRef2,
+		})
+		if err != nil {
+			t.Fatalf("CreateCurrencyTransactionBatchMultiSender failed: %v", err)
+		}
+		if len(txns) != 3 {
+			t.Fatalf("Batch length = %d, want 3", len(txns))
+		}
+		for i, source := range []string{wallet1.Address, wallet2.Address, wallet1.Address} {
+			if txns[i].Value.Source != source {
+				t.Errorf("Transaction %d source = %s, want %s", i, txns[i].Value.Source, source)
+			}
+			if !VerifyCurrencyTransaction(txns[i]).IsValid {
+				t.Errorf("Transaction %d signature invalid", i)
+			}
+		}
+		if txns[0].Value.Parent != lastRef1 || txns[1].Value.Parent != lastRef2 {
+			t.Error("First transactions should chain from each sender's last reference")
+		}
+		if expected := *GetTransactionReference(txns[0], 6); txns[2].Value.Parent != expected {
+			t.Errorf("Transaction 2 parent = %+v, want %+v", txns[2].Value.Parent, expected)
+		}
+		if expected := *GetTransactionReference(txns[2], 7); lastRefs[wallet1.Address] != expected {
+			t.Errorf("Wallet 1 last reference = %+v, want %+v", lastRefs[wallet1.Address], expected)
+		}
+		if expected := *GetTransactionReference(txns[1], 41); lastRefs[wallet2.Address] != expected {
+			t.Errorf("Wallet 2 last reference = %+v, want %+v", lastRefs[wallet2.Address], expected)
+		}
+
+		_, _, err = CreateCurrencyTransactionBatchMultiSender(transfers, map[string]TransactionReference{wallet1.Address: lastRef1})
+		if !errors.Is(err, ErrMissingLastReference) {
+			t.Errorf("Missing sender error = %v, want ErrMissingLastReference", err)
+		}
+
+		unsigned := append(transfers[:1:1], SenderTransfer{TransferParams: transfers[1].TransferParams})
+		_, _, err = CreateCurrencyTransactionBatchMultiSender(unsigned, map[string]TransactionReference{wallet1.Address: lastRef1})
+		if !errors.Is(err, ErrMissingSigner) {
+			t.Errorf("Missing signer error = %v, want ErrMissingSigner", err)
+		}
+	})
 }
 
 func TestTransactionVerification(t *testing.T) {
@@ -386,3 +449,181 @@ func TestTransactionHashing(t *testing.T) {
 		}
 	})
 }
+
+func TestDecodeTransaction(t *testing.T) {
+	keyPair, _ := GenerateKeyPair()
+	keyPair2, _ := GenerateKeyPair()
+	lastRef := TransactionReference{
+		Hash:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		Ordinal: 12,
+	}
+
+	t.Run("reverses EncodeCurrencyTransaction", func(t *testing.T) {
+		for _, params := range []TransferParams{
+			{Destination: keyPair2.Address, Amount: 100, Fee: 0},
+			{Destination: keyPair2.Address, Amount: 0.00000001, Fee: 0.002},
+			{Destination: keyPair2.Address, Amount: 123456.789, Fee: 1},
+		} {
+			tx, err := CreateCurrencyTransaction(params, keyPair.PrivateKey, lastRef)
+			if err != nil {
+				t.Fatalf("Failed to create transaction: %v", err)
+			}
+
+			value, err := DecodeTransaction(EncodeCurrencyTransaction(tx))
+			if err != nil {
+				t.Fatalf("DecodeTransaction failed: %v", err)
+			}
+			if *value != tx.Value {
+				t.Errorf("Decoded value = %+v, want %+v", *value, tx.Value)
+			}
+		}
+	})
+
+	t.Run("decodes an empty genesis parent", func(t *testing.T) {
+		tx, _ := CreateCurrencyTransaction(
+			TransferParams{Destination: keyPair2.Address, Amount: 1},
+			keyPair.PrivateKey,
+			TransactionReference{},
+		)
+		value, err := DecodeTransaction(EncodeCurrencyTransaction(tx))
+		if err != nil {
+			t.Fatalf("DecodeTransaction failed: %v", err)
+		}
+		if *value != tx.Value {
+			t.Errorf("Decoded value = %+v, want %+v", *value, tx.Value)
+		}
+	})
+
+	t.Run("round-trips random transactions", func(t *testing.T) {
+		addresses := []string{keyPair.Address, keyPair2.Address}
+		for i := 0; i < 4; i++ {
+			other, _ := GenerateKeyPair()
+			addresses = append(addresses, other.Address)
+		}
+		// small values make short, digit-leading fields, where length
+		// prefixes are most likely to split more than one way
+		randomInt63 := func(r *rand.Rand) int64 {
+			return r.Int63() >> uint(r.Intn(63))
+		}
+
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 2000; i++ {
+			parent := make([]byte, 32)
+			r.Read(parent)
+			value := CurrencyTransactionValue{
+				Source:      addresses[r.Intn(len(addresses))],
+				Destination: addresses[r.Intn(len(addresses))],
+				Amount:      randomInt63(r),
+				Fee:         randomInt63(r),
+				Parent:      TransactionReference{Hash: hex.EncodeToString(parent), Ordinal: int(randomInt63(r))},
+				Salt:        strconv.FormatInt(randomInt63(r), 10),
+			}
+			if i%10 == 0 {
+				value.Parent.Hash = ""
+			}
+			if i%3 == 0 {
+				value.Salt = strconv.FormatInt(-1-randomInt63(r), 10)
+			}
+
+			encoded := EncodeCurrencyTransaction(&CurrencyTransaction{Value: value})
+			decoded, err := DecodeTransaction(encoded)
+			if err != nil {
+				// the encoding is ambiguous: another transaction encodes to
+				// the same string, so the decoder must refuse to pick one
+				var decodings [][]string
+				splitEncodedFields(encoded[1:], nil, &decodings)
+				if !errors.Is(err, ErrInvalidTransactionEncoding) || len(decodings) < 2 {
+					t.Fatalf("DecodeTransaction(%+v) error = %v", value, err)
+				}
+				continue
+			}
+			if *decoded != value {
+				t.Fatalf("Decoded value = %+v, want %+v", *decoded, value)
+			}
+		}
+	})
+
+	t.Run("rejects malformed encodings", func(t *testing.T) {
+		tx, _ := CreateCurrencyTransaction(
+			TransferParams{Destination: keyPair2.Address, Amount: 1},
+			keyPair.PrivateKey,
+			lastRef,
+		)
+		encoded := EncodeCurrencyTransaction(tx)
+		for _, bad := range []string{
+			"",
+			"3" + encoded[1:],
+			encoded[:len(encoded)-1],
+			encoded + "0",
+			"2" + "40" + keyPair.Address,
+			strings.Replace(encoded, "64"+lastRef.Hash, "63"+lastRef.Hash[1:], 1),
+			strings.Replace(encoded, keyPair2.Address, keyPair2.Address[:39]+"0", 1),
+		} {
+			if _, err := DecodeTransaction(bad); !errors.Is(err, ErrInvalidTransactionEncoding) {
+				t.Errorf("DecodeTransaction(%q) error = %v, want ErrInvalidTransactionEncoding", bad, err)
+			}
+		}
+	})
+}
+
+func TestCreateCurrencyTransactionUnits(t *testing.T) {
+	keyPair, _ := GenerateKeyPair()
+	keyPair2, _ := GenerateKeyPair()
+	lastRef := TransactionReference{
+		Hash:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		Ordinal: 0,
+	}
+
+	t.Run("keeps amounts float64 cannot represent", func(t *testing.T) {
+		const amount = int64(1<<53 + 1)
+		tx, err := CreateCurrencyTransactionUnits(
+			TransferUnitsParams{Destination: keyPair2.Address, AmountUnits: amount, FeeUnits: 3},
+			keyPair.PrivateKey,
+			lastRef,
+		)
+		if err != nil {
+			t.Fatalf("CreateCurrencyTransactionUnits failed: %v", err)
+		}
+		if tx.Value.Amount != amount {
+			t.Errorf("Amount = %d, want %d", tx.Value.Amount, amount)
+		}
+		if tx.Value.Fee != 3 {
+			t.Errorf("Fee = %d, want 3", tx.Value.Fee)
+		}
+		if !VerifyCurrencyTransaction(tx).IsValid {
+			t.Error("Transaction should verify")
+		}
+	})
+
+	t.Run("matches the token-based transaction", func(t *testing.T) {
+		salt := "9007199254740000"
+		byTokens, _ := CreateCurrencyTransaction(
+			TransferParams{Destination: keyPair2.Address, Amount: 100.5, Fee: 0.001, Salt: salt},
+			keyPair.PrivateKey,
+			lastRef,
+		)
+		signer, _ := NewPrivateKeySigner(keyPair.PrivateKey)
+		byUnits, err := CreateCurrencyTransactionUnitsWithSigner(
+			TransferUnitsParams{Destination: keyPair2.Address, AmountUnits: 10050000000, FeeUnits: 100000, Salt: salt},
+			signer,
+			lastRef,
+		)
+		if err != nil {
+			t.Fatalf("CreateCurrencyTransactionUnitsWithSigner failed: %v", err)
+		}
+		if byUnits.Value != byTokens.Value {
+			t.Errorf("Value = %+v, want %+v", byUnits.Value, byTokens.Value)
+		}
+	})
+
+	t.Run("rejects invalid amounts", func(t *testing.T) {
+		_, err := CreateCurrencyTransactionUnits(TransferUnitsParams{Destination: keyPair2.Address}, keyPair.PrivateKey, lastRef)
+		if !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("error = %v, want ErrInvalidAmount", err)
+		}
+		_, err = CreateCurrencyTransactionUnits(TransferUnitsParams{Destination: keyPair2.Address, AmountUnits: 1, FeeUnits: -1}, keyPair.PrivateKey, lastRef)
+		if !errors.Is(err, ErrInvalidFee) {
+			t.Errorf("error = %v, want ErrInvalidFee", err)
+		}
+	})
+}
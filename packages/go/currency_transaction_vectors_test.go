@@ -3,6 +3,7 @@ package constellation
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -185,6 +186,16 @@ func TestTransactionEncoding(t *testing.T) {
 		}
 	})
 
+	t.Run("decodes the encoded string", func(t *testing.T) {
+		value, err := DecodeTransaction(basic.EncodedString)
+		if err != nil {
+			t.Fatalf("Failed to decode transaction: %v", err)
+		}
+		if *value != txValue {
+			t.Errorf("Decoded value mismatch:\ngot:  %+v\nwant: %+v", *value, txValue)
+		}
+	})
+
 	t.Run("validates encoding breakdown", func(t *testing.T) {
 		breakdown := vectors.TestVectors.EncodingBreakdown
 		components := breakdown.Components
@@ -231,6 +242,44 @@ func TestCurrencyTransactionVectorsHashing(t *testing.T) {
 			t.Errorf("Hash mismatch:\ngot:  %s\nwant: %s", hash.Value, basic.TransactionHash)
 		}
 	})
+
+	t.Run("reproduces the vector from a supplied salt", func(t *testing.T) {
+		params := TransferParams{
+			Destination: txValue.Destination,
+			Amount:      float64(txValue.Amount) / 1e8,
+			Fee:         float64(txValue.Fee) / 1e8,
+			Salt:        txValue.Salt,
+		}
+		tx, err := CreateCurrencyTransaction(params, basic.PrivateKeyHex, txValue.Parent)
+		if err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		if tx.Value != txValue {
+			t.Errorf("Value mismatch:\ngot:  %+v\nwant: %+v", tx.Value, txValue)
+		}
+		if hash := HashCurrencyTransaction(tx); hash.Value != basic.TransactionHash {
+			t.Errorf("Hash mismatch:\ngot:  %s\nwant: %s", hash.Value, basic.TransactionHash)
+		}
+
+		again, err := CreateCurrencyTransaction(params, basic.PrivateKeyHex, txValue.Parent)
+		if err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		if again.Proofs[0] != tx.Proofs[0] {
+			t.Error("Signing the same salted params twice should give the same signature")
+		}
+	})
+
+	t.Run("rejects an invalid supplied salt", func(t *testing.T) {
+		_, err := CreateCurrencyTransaction(
+			TransferParams{Destination: txValue.Destination, Amount: 1, Salt: "12ab"},
+			basic.PrivateKeyHex,
+			txValue.Parent,
+		)
+		if !errors.Is(err, ErrInvalidSalt) {
+			t.Errorf("error = %v, want ErrInvalidSalt", err)
+		}
+	})
 }
 
 func TestSignatureVerification(t *testing.T) {
@@ -1,5 +1,7 @@
 package constellation
 
+import "encoding/json"
+
 // TokenDecimals is the token decimals constant (1e-8)
 // Same as DAG_DECIMALS from dag4.js
 const TokenDecimals = 1e-8
@@ -28,6 +30,33 @@ type CurrencyTransactionValue struct {
 	Salt string `json:"salt"`
 }
 
+// UnmarshalJSON decodes a transaction value. Nodes send the salt as a JSON
+// number while the SDK writes it as a string; both are accepted, and the
+// digits are kept exactly as received.
+func (v *CurrencyTransactionValue) UnmarshalJSON(data []byte) error {
+	type plain CurrencyTransactionValue
+	var decoded struct {
+		plain
+		Salt json.RawMessage `json:"salt"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*v = CurrencyTransactionValue(decoded.plain)
+
+	if len(decoded.Salt) == 0 || decoded.Salt[0] != '"' {
+		var number json.Number
+		if len(decoded.Salt) > 0 {
+			if err := json.Unmarshal(decoded.Salt, &number); err != nil {
+				return err
+			}
+		}
+		v.Salt = number.String()
+		return nil
+	}
+	return json.Unmarshal(decoded.Salt, &v.Salt)
+}
+
 // CurrencyTransaction represents a v2 currency transaction for metagraph token transfers
 // A signed currency transaction value
 type CurrencyTransaction = Signed[CurrencyTransactionValue]
@@ -40,4 +69,35 @@ type TransferParams struct {
 	Amount float64
 	// Fee in token units (defaults to 0)
 	Fee float64
+	// ExactAmount, if set, replaces Amount with an exact value, avoiding
+	// float64 rounding
+	ExactAmount Amount
+	// ExactFee, if set, replaces Fee with an exact value
+	ExactFee Amount
+	// Salt, if set, replaces the random salt: signed decimal digits, or hex
+	// after 0x (see ParseSalt). The same params, key and parent then reproduce
+	// the same transaction byte for byte, e.g. for test vectors. Never
+	// reuse a salt for live transfers that must stay distinct.
+	Salt string
+}
+
+// SenderTransfer is a transfer together with the Signer of the wallet it is
+// sent from, for CreateCurrencyTransactionBatchMultiSender
+type SenderTransfer struct {
+	TransferParams
+	Signer Signer
+}
+
+// TransferUnitsParams holds parameters for a token transfer with amounts in
+// smallest units (1e-8), so large amounts are exact instead of rounded
+// through float64
+type TransferUnitsParams struct {
+	// Destination is the destination DAG address
+	Destination string
+	// AmountUnits in smallest units (e.g., 10050000000 for 100.5 tokens)
+	AmountUnits int64
+	// FeeUnits in smallest units (defaults to 0)
+	FeeUnits int64
+	// Salt, if set, replaces the random salt, as in TransferParams
+	Salt string
 }
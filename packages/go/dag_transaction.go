@@ -0,0 +1,26 @@
+package constellation
+
+// DAGTransaction is a transaction of DAG, the Hypergraph's native token, on
+// the global DAG L1. It has the same fields, Kryo encoding, hash and
+// signature as a metagraph CurrencyTransaction, so HashCurrencyTransaction,
+// SignTransaction and VerifyCurrencyTransaction apply to it unchanged.
+type DAGTransaction = CurrencyTransaction
+
+// CreateDAGTransaction creates a DAG transaction on the global DAG L1. The
+// same key pair can send DAG and metagraph tokens; only the node the
+// transaction is posted to differs (see DAGL1Client).
+//
+// Example:
+//
+//	lastRef, err := dagL1.GetLastReference(keyPair.Address)
+//	tx, err := CreateDAGTransaction(TransferParams{Destination: "DAG...", Amount: 25}, keyPair.PrivateKey, *lastRef)
+//	result, err := dagL1.PostTransaction(tx)
+func CreateDAGTransaction(params TransferParams, privateKeyHex string, lastRef TransactionReference) (*DAGTransaction, error) {
+	return CreateCurrencyTransaction(params, privateKeyHex, lastRef)
+}
+
+// CreateDAGTransactionWithSigner is CreateDAGTransaction with the key behind
+// a Signer
+func CreateDAGTransactionWithSigner(params TransferParams, signer Signer, lastRef TransactionReference) (*DAGTransaction, error) {
+	return CreateCurrencyTransactionWithSigner(params, signer, lastRef)
+}
@@ -0,0 +1,135 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dataUpdateVectorsPath is written by cmd/gen-vectors
+var dataUpdateVectorsPath = filepath.Join("..", "..", "shared", "data_update_vectors.json")
+
+// dataUpdateEncoders maps each encoder in the vectors to ToBytes'
+// isDataUpdate
+var dataUpdateEncoders = map[string]bool{"regular": false, "dataUpdate": true}
+
+type dataUpdateVectors struct {
+	Signer struct {
+		PrivateKeyHex string `json:"privateKeyHex"`
+		SignerID      string `json:"signerId"`
+	} `json:"signer"`
+	Vectors []struct {
+		Name          string          `json:"name"`
+		Data          json.RawMessage `json:"data"`
+		CanonicalJSON string          `json:"canonicalJson"`
+		Encoders      map[string]struct {
+			BytesHex string         `json:"bytesHex"`
+			Hash     string         `json:"hash"`
+			Proof    SignatureProof `json:"proof"`
+		} `json:"encoders"`
+	} `json:"vectors"`
+}
+
+func loadDataUpdateVectors(t *testing.T) *dataUpdateVectors {
+	content, err := os.ReadFile(dataUpdateVectorsPath)
+	require.NoError(t, err)
+	var vectors dataUpdateVectors
+	require.NoError(t, json.Unmarshal(content, &vectors))
+	require.NotEmpty(t, vectors.Vectors)
+	return &vectors
+}
+
+func TestDataUpdateVectors(t *testing.T) {
+	vectors := loadDataUpdateVectors(t)
+	signerID, err := GetPublicKeyID(vectors.Signer.PrivateKeyHex)
+	require.NoError(t, err)
+	require.Equal(t, vectors.Signer.SignerID, signerID)
+
+	for _, vector := range vectors.Vectors {
+		vector := vector
+		t.Run(vector.Name, func(t *testing.T) {
+			var data interface{}
+			require.NoError(t, json.Unmarshal(vector.Data, &data))
+
+			canonical, err := Canonicalize(data)
+			require.NoError(t, err)
+			assert.Equal(t, vector.CanonicalJSON, canonical)
+
+			require.Len(t, vector.Encoders, len(dataUpdateEncoders))
+			for encoder, isDataUpdate := range dataUpdateEncoders {
+				expected, ok := vector.Encoders[encoder]
+				require.True(t, ok, encoder)
+
+				encoded, err := ToBytes(data, isDataUpdate)
+				require.NoError(t, err)
+				assert.Equal(t, expected.BytesHex, hex.EncodeToString(encoded), encoder)
+				assert.Equal(t, expected.Hash, HashBytes(encoded).Value, encoder)
+
+				// nonces are deterministic, so signing reproduces the proof
+				sign := Sign
+				if isDataUpdate {
+					sign = SignDataUpdate
+				}
+				proof, err := sign(data, vectors.Signer.PrivateKeyHex)
+				require.NoError(t, err)
+				assert.Equal(t, expected.Proof, *proof, encoder)
+				if isDataUpdate {
+					signed, err := CreateDataTransaction(data, vectors.Signer.PrivateKeyHex)
+					require.NoError(t, err)
+					assert.Equal(t, []SignatureProof{expected.Proof}, signed.Proofs)
+				}
+
+				valid, err := VerifySignature(data, &expected.Proof, isDataUpdate)
+				require.NoError(t, err)
+				assert.True(t, valid, encoder)
+				// a proof is only valid under the encoder it was made with
+				valid, err = VerifySignature(data, &expected.Proof, !isDataUpdate)
+				require.NoError(t, err)
+				assert.False(t, valid, encoder)
+			}
+
+			var decoded interface{}
+			encoded, err := hex.DecodeString(vector.Encoders["dataUpdate"].BytesHex)
+			require.NoError(t, err)
+			require.NoError(t, DecodeDataUpdate(encoded, &decoded))
+			assert.Equal(t, data, decoded)
+		})
+	}
+}
+
+func TestCreateDataTransaction(t *testing.T) {
+	type vote struct {
+		PollID string `json:"pollId"`
+		Option int    `json:"option"`
+		Note   string `json:"note,omitempty"`
+	}
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	value := vote{PollID: "p-1", Option: 2}
+
+	signed, err := CreateDataTransaction(value, keyPair.PrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, value, signed.Value)
+	assert.True(t, Verify(signed, true).IsValid)
+	assert.False(t, Verify(signed, false).IsValid)
+
+	// a typed struct signs like the map a node decodes it into
+	proof, err := SignDataUpdate(map[string]interface{}{"option": 2, "pollId": "p-1"}, keyPair.PrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, *proof, signed.Proofs[0])
+
+	signer, err := NewPrivateKeySigner(keyPair.PrivateKey)
+	require.NoError(t, err)
+	withSigner, err := CreateDataTransactionWithSigner(value, signer)
+	require.NoError(t, err)
+	assert.Equal(t, signed.Proofs, withSigner.Proofs)
+
+	body, err := json.Marshal(signed)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":{"pollId":"p-1","option":2},"proofs":[{"id":"`+proof.ID+`","signature":"`+proof.Signature+`"}]}`, string(body))
+}
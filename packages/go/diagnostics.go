@@ -0,0 +1,336 @@
+package constellation
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recentErrorCapacity is how many errors a client keeps for diagnostics
+const recentErrorCapacity = 20
+
+// Parent statuses reported in ChainDiagnostics
+const (
+	// ParentCurrent means the transaction's parent is the source's last
+	// reference, so the transaction is next in the chain
+	ParentCurrent = "current"
+	// ParentAccepted means the transaction itself is the last reference
+	ParentAccepted = "accepted"
+	// ParentStale means the chain has moved past the parent; the
+	// transaction's ordinal is taken
+	ParentStale = "stale"
+	// ParentAhead means the parent is not accepted yet; the transaction
+	// must wait for it
+	ParentAhead = "ahead"
+	// ParentConflict means another transaction holds the parent's ordinal
+	ParentConflict = "conflict"
+)
+
+// dagAddressPattern matches DAG addresses inside free text such as node
+// responses
+var dagAddressPattern = regexp.MustCompile(`DAG[0-9][1-9A-HJ-NP-Za-km-z]{36}`)
+
+// RecordedError is an error a client returned, kept for diagnostics. DAG
+// addresses in the message are redacted.
+type RecordedError struct {
+	Time       time.Time `json:"time"`
+	Message    string    `json:"message"`
+	StatusCode int       `json:"statusCode,omitempty"`
+}
+
+// DiagnosticsBundle gathers what support needs to investigate a failing
+// transaction. Addresses and signatures are redacted and no key material is
+// included, so the bundle can be attached to a bug report as is.
+type DiagnosticsBundle struct {
+	CapturedAt  time.Time               `json:"capturedAt"`
+	SDKVersion  string                  `json:"sdkVersion"`
+	GoVersion   string                  `json:"goVersion"`
+	Transaction *TransactionDiagnostics `json:"transaction,omitempty"`
+	Encoding    *EncodingDiagnostics    `json:"encoding,omitempty"`
+	Node        *NodeDiagnostics        `json:"node,omitempty"`
+	Chain       *ChainDiagnostics       `json:"chain,omitempty"`
+	// RecentErrors are the client's latest errors, oldest first, if it
+	// keeps them (CurrencyL1Client and PooledCurrencyL1Client do)
+	RecentErrors []RecordedError `json:"recentErrors,omitempty"`
+	// CaptureErrors lists the parts of the bundle that could not be gathered
+	CaptureErrors []string `json:"captureErrors,omitempty"`
+}
+
+// TransactionDiagnostics describes the transaction
+type TransactionDiagnostics struct {
+	Hash            string               `json:"hash"`
+	Source          string               `json:"source"`
+	Destination     string               `json:"destination"`
+	Amount          int64                `json:"amount"`
+	Fee             int64                `json:"fee"`
+	Parent          TransactionReference `json:"parent"`
+	Salt            string               `json:"salt"`
+	SaltValid       bool                 `json:"saltValid"`
+	SignaturesValid bool                 `json:"signaturesValid"`
+	Proofs          []ProofDiagnostics   `json:"proofs"`
+}
+
+// ProofDiagnostics describes one signature on the transaction
+type ProofDiagnostics struct {
+	ID         string `json:"id"`
+	Signature  string `json:"signature"`
+	WellFormed bool   `json:"wellFormed"`
+	Valid      bool   `json:"valid"`
+}
+
+// EncodingDiagnostics breaks down the encoding the transaction hash is
+// computed over, for comparing against another SDK's
+type EncodingDiagnostics struct {
+	ParentCount int `json:"parentCount"`
+	// Fields are the length-prefixed fields in encoding order
+	Fields           []EncodingField `json:"fields"`
+	EncodedLength    int             `json:"encodedLength"`
+	SerializedLength int             `json:"serializedLength"`
+}
+
+// EncodingField is one length-prefixed field of the encoding
+type EncodingField struct {
+	Name   string `json:"name"`
+	Length int    `json:"length"`
+	Value  string `json:"value"`
+}
+
+// NodeDiagnostics reports the node the client talks to, where the client
+// exposes it
+type NodeDiagnostics struct {
+	Healthy *bool  `json:"healthy,omitempty"`
+	State   string `json:"state,omitempty"`
+	Version string `json:"version,omitempty"`
+	ID      string `json:"id,omitempty"`
+}
+
+// ChainDiagnostics compares the transaction's parent with the source's
+// chain on the node
+type ChainDiagnostics struct {
+	LastReference *TransactionReference `json:"lastReference,omitempty"`
+	// ParentStatus is one of ParentCurrent, ParentAccepted, ParentStale,
+	// ParentAhead or ParentConflict
+	ParentStatus string `json:"parentStatus,omitempty"`
+	// Pending reports whether the node's mempool holds the transaction
+	Pending bool `json:"pending"`
+}
+
+// CaptureDiagnostics gathers a support bundle for tx: the redacted
+// transaction, its encoding breakdown, the node's health, the source's last
+// reference and the client's recent errors. It never fails; whatever cannot
+// be gathered is listed in CaptureErrors. Either argument may be nil.
+//
+// Example:
+//
+//	_, err := client.PostTransaction(tx)
+//	if err != nil {
+//	    bundle, _ := constellation.CaptureDiagnostics(tx, client).JSON()
+//	    os.WriteFile("diagnostics.json", bundle, 0o644)
+//	}
+func CaptureDiagnostics(tx *CurrencyTransaction, client CurrencyL1API) *DiagnosticsBundle {
+	bundle := &DiagnosticsBundle{
+		CapturedAt: time.Now().UTC(),
+		SDKVersion: Version,
+		GoVersion:  runtime.Version(),
+	}
+	capture := func(part string, err error) {
+		bundle.CaptureErrors = append(bundle.CaptureErrors, part+": "+redactText(err.Error()))
+	}
+
+	// read the errors first, so the capture's own requests are not among them
+	if source, ok := client.(interface{ RecentErrors() []RecordedError }); ok {
+		bundle.RecentErrors = source.RecentErrors()
+	}
+
+	var hash string
+	if tx != nil {
+		hash = HashCurrencyTransaction(tx).Value
+		bundle.Transaction = transactionDiagnostics(tx, hash)
+		bundle.Encoding = encodingDiagnostics(tx)
+	}
+	if client == nil {
+		return bundle
+	}
+
+	node := &NodeDiagnostics{}
+	if checker, ok := client.(interface{ CheckHealth() bool }); ok {
+		healthy := checker.CheckHealth()
+		node.Healthy = &healthy
+	}
+	if source, ok := client.(interface{ GetNodeInfo() (*NodeInfo, error) }); ok {
+		if info, err := source.GetNodeInfo(); err != nil {
+			capture("node info", err)
+		} else {
+			node.State, node.Version, node.ID = info.State, info.Version, RedactSignature(info.ID)
+		}
+	}
+	if *node != (NodeDiagnostics{}) {
+		bundle.Node = node
+	}
+
+	if tx == nil {
+		return bundle
+	}
+	chain := &ChainDiagnostics{}
+	if lastRef, err := client.GetLastReference(tx.Value.Source); err != nil {
+		capture("last reference", err)
+	} else if lastRef != nil {
+		chain.LastReference = lastRef
+		chain.ParentStatus = parentStatus(tx.Value.Parent, *lastRef, hash)
+	}
+	if pending, err := client.GetPendingTransaction(hash); err != nil {
+		capture("pending transaction", err)
+	} else {
+		chain.Pending = pending != nil
+	}
+	bundle.Chain = chain
+	return bundle
+}
+
+// JSON encodes the bundle, indented for reading
+func (b *DiagnosticsBundle) JSON() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+func transactionDiagnostics(tx *CurrencyTransaction, hash string) *TransactionDiagnostics {
+	result := VerifyCurrencyTransaction(tx)
+	valid := map[SignatureProof]bool{}
+	for _, proof := range result.ValidProofs {
+		valid[proof] = true
+	}
+
+	diagnostics := &TransactionDiagnostics{
+		Hash:            hash,
+		Source:          RedactAddress(tx.Value.Source),
+		Destination:     RedactAddress(tx.Value.Destination),
+		Amount:          tx.Value.Amount,
+		Fee:             tx.Value.Fee,
+		Parent:          tx.Value.Parent,
+		Salt:            tx.Value.Salt,
+		SaltValid:       validSalt(tx.Value.Salt),
+		SignaturesValid: result.IsValid,
+		Proofs:          []ProofDiagnostics{},
+	}
+	for _, proof := range tx.Proofs {
+		diagnostics.Proofs = append(diagnostics.Proofs, ProofDiagnostics{
+			ID:         RedactSignature(proof.ID),
+			Signature:  RedactSignature(proof.Signature),
+			WellFormed: proofLooksValid(proof),
+			Valid:      valid[proof],
+		})
+	}
+	return diagnostics
+}
+
+func encodingDiagnostics(tx *CurrencyTransaction) *EncodingDiagnostics {
+	encoded := encodeTransaction(tx)
+	diagnostics := &EncodingDiagnostics{
+		ParentCount:      2,
+		EncodedLength:    len(encoded),
+		SerializedLength: len(kryoSerialize(encoded, false)),
+	}
+	for _, field := range transactionEncodingFields(tx) {
+		value := field.value
+		if field.name == "source" || field.name == "destination" {
+			value = RedactAddress(value)
+		}
+		diagnostics.Fields = append(diagnostics.Fields, EncodingField{Name: field.name, Length: len(field.value), Value: value})
+	}
+	return diagnostics
+}
+
+// parentStatus places a transaction's parent relative to the source's last
+// reference
+func parentStatus(parent TransactionReference, lastRef TransactionReference, hash string) string {
+	switch {
+	case lastRef.Hash == hash:
+		return ParentAccepted
+	case parent == lastRef:
+		return ParentCurrent
+	case parent.Ordinal < lastRef.Ordinal:
+		return ParentStale
+	case parent.Ordinal > lastRef.Ordinal:
+		return ParentAhead
+	}
+	return ParentConflict
+}
+
+// redactText redacts every DAG address in s
+func redactText(s string) string {
+	return dagAddressPattern.ReplaceAllStringFunc(s, RedactAddress)
+}
+
+// ErrorLog keeps a client's most recent errors, with addresses redacted,
+// for its RecentErrors method. It is safe for concurrent use, and a nil log
+// records nothing.
+//
+// Example:
+//
+//	func (c *MyL1) GetLastReference(address string) (*constellation.TransactionReference, error) {
+//	    ref, err := c.lookup(address)
+//	    return ref, c.errors.Record(err)
+//	}
+//
+//	func (c *MyL1) RecentErrors() []constellation.RecordedError {
+//	    return c.errors.Recent()
+//	}
+type ErrorLog struct {
+	mu      sync.Mutex
+	entries []RecordedError
+	next    int
+}
+
+// NewErrorLog creates an empty error log
+func NewErrorLog() *ErrorLog {
+	return &ErrorLog{entries: make([]RecordedError, 0, recentErrorCapacity)}
+}
+
+// Record adds err to the log and returns it unchanged; nil is not recorded
+func (l *ErrorLog) Record(err error) error {
+	if l == nil || err == nil {
+		return err
+	}
+	entry := RecordedError{Time: time.Now().UTC(), Message: redactText(err.Error())}
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		entry.StatusCode = netErr.StatusCode
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < recentErrorCapacity {
+		l.entries = append(l.entries, entry)
+		return err
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % recentErrorCapacity
+	return err
+}
+
+// Recent returns the recorded errors, oldest first
+func (l *ErrorLog) Recent() []RecordedError {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append(append([]RecordedError{}, l.entries[l.next:]...), l.entries[:l.next]...)
+}
+
+// MergeRecordedErrors combines the errors of several logs, e.g. one per
+// pooled node, keeping the latest 20, oldest first
+func MergeRecordedErrors(logs ...[]RecordedError) []RecordedError {
+	var merged []RecordedError
+	for _, log := range logs {
+		merged = append(merged, log...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	if len(merged) > recentErrorCapacity {
+		merged = merged[len(merged)-recentErrorCapacity:]
+	}
+	return merged
+}
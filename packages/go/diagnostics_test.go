@@ -0,0 +1,112 @@
+package constellation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// erroringL1 fails every lookup and keeps an error log like CurrencyL1Client
+type erroringL1 struct {
+	*SimulatedLedger
+	log *ErrorLog
+}
+
+func (e *erroringL1) GetLastReference(address string) (*TransactionReference, error) {
+	return nil, e.log.Record(NewNetworkError("no last reference for "+address, 503, ""))
+}
+
+func (e *erroringL1) RecentErrors() []RecordedError {
+	return e.log.Recent()
+}
+
+func TestCaptureDiagnostics(t *testing.T) {
+	source, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, _ := GenerateKeyPair()
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(source.Address, 10))
+
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: destination.Address, Amount: 1}, source.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+
+	t.Run("reports the transaction and its chain", func(t *testing.T) {
+		bundle := CaptureDiagnostics(tx, ledger)
+		require.NotNil(t, bundle.Transaction)
+		assert.Equal(t, HashCurrencyTransaction(tx).Value, bundle.Transaction.Hash)
+		assert.True(t, bundle.Transaction.SignaturesValid)
+		assert.True(t, bundle.Transaction.SaltValid)
+		require.Len(t, bundle.Transaction.Proofs, 1)
+		assert.True(t, bundle.Transaction.Proofs[0].Valid)
+
+		require.NotNil(t, bundle.Encoding)
+		assert.Equal(t, len(EncodeCurrencyTransaction(tx)), bundle.Encoding.EncodedLength)
+		require.Len(t, bundle.Encoding.Fields, 7)
+		assert.Equal(t, "amount", bundle.Encoding.Fields[2].Name)
+		assert.Equal(t, fmt.Sprintf("%x", tx.Value.Amount), bundle.Encoding.Fields[2].Value)
+		assert.Equal(t, 40, bundle.Encoding.Fields[0].Length)
+
+		require.NotNil(t, bundle.Chain)
+		assert.Equal(t, ParentCurrent, bundle.Chain.ParentStatus)
+		assert.False(t, bundle.Chain.Pending)
+		assert.Nil(t, bundle.Node)
+		assert.Empty(t, bundle.CaptureErrors)
+
+		_, err := ledger.PostTransaction(tx)
+		require.NoError(t, err)
+		bundle = CaptureDiagnostics(tx, ledger)
+		assert.Equal(t, ParentAccepted, bundle.Chain.ParentStatus)
+		assert.True(t, bundle.Chain.Pending)
+	})
+
+	t.Run("redacts addresses and signatures", func(t *testing.T) {
+		client := &erroringL1{SimulatedLedger: ledger, log: NewErrorLog()}
+		_, _ = client.GetLastReference(source.Address)
+
+		data, err := CaptureDiagnostics(tx, client).JSON()
+		require.NoError(t, err)
+		text := string(data)
+		assert.NotContains(t, text, source.Address)
+		assert.NotContains(t, text, destination.Address)
+		assert.NotContains(t, text, source.PrivateKey)
+		assert.NotContains(t, text, tx.Proofs[0].Signature)
+		assert.Contains(t, text, RedactAddress(source.Address))
+
+		var bundle DiagnosticsBundle
+		require.NoError(t, json.Unmarshal(data, &bundle))
+		require.Len(t, bundle.RecentErrors, 1)
+		assert.Equal(t, 503, bundle.RecentErrors[0].StatusCode)
+		require.Len(t, bundle.CaptureErrors, 1)
+		assert.True(t, strings.HasPrefix(bundle.CaptureErrors[0], "last reference: "))
+	})
+
+	t.Run("accepts a missing transaction or client", func(t *testing.T) {
+		bundle := CaptureDiagnostics(nil, nil)
+		assert.Equal(t, Version, bundle.SDKVersion)
+		assert.Nil(t, bundle.Transaction)
+		assert.NotNil(t, CaptureDiagnostics(tx, nil).Encoding)
+	})
+}
+
+func TestErrorLog(t *testing.T) {
+	log := NewErrorLog()
+	for i := 0; i < recentErrorCapacity+5; i++ {
+		_ = log.Record(fmt.Errorf("error %d", i))
+	}
+	assert.Nil(t, log.Record(nil))
+
+	recent := log.Recent()
+	require.Len(t, recent, recentErrorCapacity)
+	assert.Equal(t, "error 5", recent[0].Message)
+	assert.Equal(t, fmt.Sprintf("error %d", recentErrorCapacity+4), recent[len(recent)-1].Message)
+
+	var nilLog *ErrorLog
+	err := errors.New("not recorded")
+	assert.Equal(t, err, nilLog.Record(err))
+	assert.Empty(t, nilLog.Recent())
+}
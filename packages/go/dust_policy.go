@@ -0,0 +1,117 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDustTransfer indicates a transfer below the dust threshold
+var ErrDustTransfer = errors.New("transfer amount is below the dust threshold")
+
+// DustAction is what a DustPolicy does with transfers below its threshold
+type DustAction int
+
+const (
+	// DustReject fails the batch on the first dust transfer
+	DustReject DustAction = iota
+	// DustAggregate merges dust transfers to the same destination and
+	// defers any total still below the threshold to a later batch
+	DustAggregate
+)
+
+// DustPolicy sets the minimum transfer amount for builders and batches, so
+// payout pipelines do not flood the chain with tiny transactions
+type DustPolicy struct {
+	// Threshold is the minimum transfer amount in smallest units (1e-8).
+	// Zero disables the policy.
+	Threshold int64
+	Action    DustAction
+}
+
+// IsDust reports whether amount, in smallest units, is below the threshold
+func (p DustPolicy) IsDust(amount int64) bool {
+	return p.Threshold > 0 && amount < p.Threshold
+}
+
+// PlanTransfers applies the policy to a batch of transfers. Transfers at or
+// above the threshold are kept as they are. With DustReject, a dust
+// transfer fails the batch. With DustAggregate, dust transfers to the same
+// destination are merged into one transfer, placed where the first of them
+// was, paying the largest of their fees; merged totals still below the
+// threshold are returned as deferred, to be carried into a later batch.
+//
+// Example:
+//
+//	policy := DustPolicy{Threshold: TokenToUnits(0.1), Action: DustAggregate}
+//	planned, deferred, err := policy.PlanTransfers(payouts)
+//	txs, err := BuildBatch(builder, planned, lastRef)
+//	carryOver = append(carryOver, deferred...)
+func (p DustPolicy) PlanTransfers(transfers []TransferParams) (planned []TransferParams, deferred []TransferParams, err error) {
+	type dustGroup struct {
+		position int
+		amount   int64
+		fee      int64
+	}
+	groups := map[string]*dustGroup{}
+	var order []string
+	planned = make([]TransferParams, 0, len(transfers))
+
+	for i, transfer := range transfers {
+		amount, fee, err := transferUnits(transfer)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !p.IsDust(amount) {
+			planned = append(planned, transfer)
+			continue
+		}
+		if p.Action == DustReject {
+			return nil, nil, fmt.Errorf("%w: transfer %d of %s to %s", ErrDustTransfer, i,
+				FormatTokenAmount(amount), RedactAddress(transfer.Destination))
+		}
+
+		group, ok := groups[transfer.Destination]
+		if !ok {
+			group = &dustGroup{position: len(planned)}
+			groups[transfer.Destination] = group
+			order = append(order, transfer.Destination)
+			// reserve the slot; filled or dropped below
+			planned = append(planned, TransferParams{})
+		}
+		if group.amount, err = EffectiveDebit(group.amount, amount); err != nil {
+			return nil, nil, err
+		}
+		if fee > group.fee {
+			group.fee = fee
+		}
+	}
+
+	keep := make([]bool, len(planned))
+	for i := range keep {
+		keep[i] = true
+	}
+	for _, destination := range order {
+		group := groups[destination]
+		merged := TransferParams{
+			Destination: destination,
+			Amount:      UnitsToToken(group.amount),
+			Fee:         UnitsToToken(group.fee),
+			ExactAmount: AmountFromUnits(group.amount),
+			ExactFee:    AmountFromUnits(group.fee),
+		}
+		if p.IsDust(group.amount) {
+			deferred = append(deferred, merged)
+			keep[group.position] = false
+			continue
+		}
+		planned[group.position] = merged
+	}
+
+	result := planned[:0]
+	for i, transfer := range planned {
+		if keep[i] {
+			result = append(result, transfer)
+		}
+	}
+	return result, deferred, nil
+}
@@ -0,0 +1,66 @@
+package constellation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDustTestAddress(t *testing.T) string {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	return kp.Address
+}
+
+func TestDustPolicy(t *testing.T) {
+	a, b, c := newDustTestAddress(t), newDustTestAddress(t), newDustTestAddress(t)
+	transfers := []TransferParams{
+		{Destination: a, Amount: 0.00000001},
+		{Destination: b, Amount: 5},
+		{Destination: a, Amount: 0.09, Fee: 0.00000002},
+		{Destination: c, Amount: 0.00000003},
+		{Destination: a, Amount: 0.01},
+	}
+
+	t.Run("aggregates dust per destination and defers the rest", func(t *testing.T) {
+		policy := DustPolicy{Threshold: TokenToUnits(0.1), Action: DustAggregate}
+		planned, deferred, err := policy.PlanTransfers(transfers)
+		require.NoError(t, err)
+		require.Len(t, planned, 2)
+		assert.Equal(t, a, planned[0].Destination)
+		assert.Equal(t, int64(10000001), TokenToUnits(planned[0].Amount))
+		assert.Equal(t, int64(2), TokenToUnits(planned[0].Fee))
+		assert.Equal(t, transfers[1], planned[1])
+		require.Len(t, deferred, 1)
+		assert.Equal(t, c, deferred[0].Destination)
+		assert.Equal(t, TokenToUnits(transfers[3].Amount), TokenToUnits(deferred[0].Amount))
+	})
+
+	t.Run("rejects dust", func(t *testing.T) {
+		policy := DustPolicy{Threshold: TokenToUnits(0.1)}
+		_, _, err := policy.PlanTransfers(transfers)
+		assert.ErrorIs(t, err, ErrDustTransfer)
+		assert.Contains(t, err.Error(), "transfer 0 of 0.00000001")
+	})
+
+	t.Run("a zero threshold keeps everything", func(t *testing.T) {
+		planned, deferred, err := DustPolicy{}.PlanTransfers(transfers)
+		require.NoError(t, err)
+		assert.Equal(t, transfers, planned)
+		assert.Empty(t, deferred)
+	})
+
+	t.Run("builders enforce a threshold", func(t *testing.T) {
+		builder := NewTransactionBuilder(newDustTestAddress(t)).WithDustThreshold(TokenToUnits(0.1))
+		_, err := builder.Build(a, TokenToUnits(0.05), 0, TransactionReference{})
+		assert.ErrorIs(t, err, ErrDustTransfer)
+
+		tx, err := builder.Build(a, TokenToUnits(0.1), 0, TransactionReference{})
+		require.NoError(t, err)
+		assert.Equal(t, TokenToUnits(0.1), tx.Value.Amount)
+
+		_, err = BuildBatch(builder, transfers, TransactionReference{})
+		assert.ErrorIs(t, err, ErrDustTransfer)
+	})
+}
@@ -0,0 +1,370 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEndpointPoolAlpha         = 0.2
+	defaultEndpointPoolProbeInterval = 30 * time.Second
+	// defaultEndpointLatency stands in for nodes with no measurements yet
+	defaultEndpointLatency = 100 * time.Millisecond
+	// minEndpointShare keeps a trickle of traffic on the worst node, relative
+	// to the best one, so it is never starved of fresh measurements
+	minEndpointShare = 0.01
+	// unhealthyErrorRate is the error rate at which affinity routing moves
+	// a key to the next node on the ring
+	unhealthyErrorRate = 0.5
+)
+
+// ErrNoEndpoints indicates an EndpointPool has no endpoints to route to
+var ErrNoEndpoints = errors.New("endpoint pool is empty")
+
+// EndpointStats is the routing state of one endpoint in an EndpointPool
+type EndpointStats struct {
+	URL string
+	// Latency is the EWMA of request latency
+	Latency time.Duration
+	// ErrorRate is the EWMA of failures, from 0 (healthy) to 1 (failing)
+	ErrorRate float64
+	// Weight is the endpoint's current share of routing, from 0 to 1
+	Weight float64
+	// Routed counts the requests Pick sent to this endpoint
+	Routed int64
+	// Requests and Failures count reported outcomes, including probes
+	Requests int64
+	Failures int64
+	// LastUsed is when the endpoint last reported an outcome
+	LastUsed time.Time
+}
+
+// EndpointProbe checks one endpoint; the pool times it and records the
+// outcome like a regular request
+type EndpointProbe func(ctx context.Context, url string) error
+
+// EndpointPoolConfig holds configuration for an EndpointPool
+type EndpointPoolConfig struct {
+	// URLs are the initial endpoints
+	URLs []string
+	// Alpha is the EWMA smoothing factor; higher reacts faster (default: 0.2)
+	Alpha float64
+	// Probe, when set, checks endpoints that have not been used within
+	// ProbeInterval so slow or failing nodes can recover their weight
+	Probe EndpointProbe
+	// ProbeInterval is the delay between probe rounds (default: 30s)
+	ProbeInterval time.Duration
+}
+
+type endpointState struct {
+	stats    EndpointStats
+	measured bool
+}
+
+// EndpointPool spreads requests over several nodes, weighting each by the
+// EWMA of its latency and error rate. Fast healthy nodes get most of the
+// traffic. A node's weight scales with 1/latency and falls quadratically
+// with its error rate, and no node drops below 1% of the best one. With a
+// Probe configured, idle nodes are checked in the background so a node
+// that recovers wins back traffic.
+//
+// EndpointPool implements the Service lifecycle. Without a Probe it probes
+// nothing, but one goroutine still waits for ctx or Close to finish the
+// service, so Close must still be called.
+//
+// Example:
+//
+//	pool, err := NewEndpointPool(ctx, EndpointPoolConfig{
+//	    URLs:  []string{"http://l1-a:9010", "http://l1-b:9010"},
+//	    Probe: network.HTTPEndpointProbe("/cluster/info", NetworkConfig{Timeout: 5}),
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer pool.Close()
+//
+//	url, _ := pool.Pick()
+//	start := time.Now()
+//	err = doRequest(url)
+//	pool.Report(url, time.Since(start), err)
+type EndpointPool struct {
+	serviceState
+
+	ctx    context.Context
+	config EndpointPoolConfig
+	stop   chan struct{}
+	once   sync.Once
+
+	mu        sync.Mutex
+	rand      *rand.Rand
+	endpoints []*endpointState
+	// ring is built on first use by PickFor and reset by SetEndpoints
+	ring *hashRing
+}
+
+// NewEndpointPool creates a pool and, if a Probe is configured, starts
+// probing in the background
+func NewEndpointPool(ctx context.Context, config EndpointPoolConfig) (*EndpointPool, error) {
+	if len(config.URLs) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	if config.Alpha <= 0 || config.Alpha > 1 {
+		config.Alpha = defaultEndpointPoolAlpha
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = defaultEndpointPoolProbeInterval
+	}
+
+	p := &EndpointPool{
+		ctx:    ctx,
+		config: config,
+		stop:   make(chan struct{}),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	p.SetEndpoints(config.URLs)
+	p.start()
+
+	go p.run()
+	return p, nil
+}
+
+// SetEndpoints replaces the endpoint list, keeping the statistics of
+// endpoints that remain. Pass it to network.EndpointDiscovery's OnChange to
+// follow DNS rotation.
+func (p *EndpointPool) SetEndpoints(urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := make(map[string]*endpointState, len(p.endpoints))
+	for _, e := range p.endpoints {
+		existing[e.stats.URL] = e
+	}
+
+	endpoints := make([]*endpointState, 0, len(urls))
+	seen := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		if e, ok := existing[url]; ok {
+			endpoints = append(endpoints, e)
+		} else {
+			endpoints = append(endpoints, &endpointState{stats: EndpointStats{URL: url}})
+		}
+	}
+	p.endpoints = endpoints
+	p.ring = nil
+}
+
+// URLs returns the endpoints in the pool
+func (p *EndpointPool) URLs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	urls := make([]string, len(p.endpoints))
+	for i, e := range p.endpoints {
+		urls[i] = e.stats.URL
+	}
+	return urls
+}
+
+// Pick chooses an endpoint at random in proportion to its weight
+func (p *EndpointPool) Pick() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.endpoints) == 0 {
+		return "", ErrNoEndpoints
+	}
+
+	weights := p.weightsLocked()
+	target := p.rand.Float64()
+	chosen := p.endpoints[len(p.endpoints)-1]
+	for i, e := range p.endpoints {
+		target -= weights[i]
+		if target < 0 {
+			chosen = e
+			break
+		}
+	}
+	chosen.stats.Routed++
+	return chosen.stats.URL, nil
+}
+
+// Report records the outcome of a request to url. A non-nil err counts as a
+// node failure; callers should pass nil for errors that are the request's
+// fault rather than the node's (e.g. HTTP 400).
+func (p *EndpointPool) Report(url string, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e := p.findLocked(url)
+	if e == nil {
+		return
+	}
+
+	alpha := p.config.Alpha
+	failure := 0.0
+	if err != nil {
+		failure = 1
+		e.stats.Failures++
+	}
+	e.stats.Requests++
+	e.stats.LastUsed = time.Now()
+
+	if !e.measured {
+		e.measured = true
+		e.stats.Latency = latency
+		e.stats.ErrorRate = failure
+		return
+	}
+	e.stats.Latency = time.Duration(alpha*float64(latency) + (1-alpha)*float64(e.stats.Latency))
+	e.stats.ErrorRate = alpha*failure + (1-alpha)*e.stats.ErrorRate
+}
+
+// Stats returns the routing state of every endpoint, fastest-weighted first
+func (p *EndpointPool) Stats() []EndpointStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	weights := p.weightsLocked()
+	stats := make([]EndpointStats, len(p.endpoints))
+	for i, e := range p.endpoints {
+		stats[i] = e.stats
+		stats[i].Weight = weights[i]
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Weight > stats[j].Weight })
+	return stats
+}
+
+// PickFor chooses the endpoint that owns key, e.g. a source address, on the
+// pool's hash ring, skipping endpoints whose error rate has reached
+// unhealthyErrorRate. If every endpoint is unhealthy, the owner is used
+// anyway.
+func (p *EndpointPool) PickFor(key string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.endpoints) == 0 {
+		return "", ErrNoEndpoints
+	}
+	if p.ring == nil {
+		urls := make([]string, len(p.endpoints))
+		for i, e := range p.endpoints {
+			urls[i] = e.stats.URL
+		}
+		p.ring = newHashRing(urls)
+	}
+
+	candidates := p.ring.lookup(key)
+	chosen := p.findLocked(candidates[0])
+	for _, url := range candidates {
+		if e := p.findLocked(url); e.stats.ErrorRate < unhealthyErrorRate {
+			chosen = e
+			break
+		}
+	}
+	chosen.stats.Routed++
+	return chosen.stats.URL, nil
+}
+
+// Close stops background probing
+func (p *EndpointPool) Close() error {
+	p.once.Do(func() { close(p.stop) })
+	<-p.Done()
+	return p.Err()
+}
+
+func (p *EndpointPool) findLocked(url string) *endpointState {
+	for _, e := range p.endpoints {
+		if e.stats.URL == url {
+			return e
+		}
+	}
+	return nil
+}
+
+// weightsLocked returns each endpoint's normalised routing weight
+func (p *EndpointPool) weightsLocked() []float64 {
+	weights := make([]float64, len(p.endpoints))
+	best, total := 0.0, 0.0
+	for i, e := range p.endpoints {
+		latency := defaultEndpointLatency
+		if e.measured && e.stats.Latency > 0 {
+			latency = e.stats.Latency
+		}
+		health := 1 - e.stats.ErrorRate
+		weights[i] = health * health / latency.Seconds()
+		if weights[i] > best {
+			best = weights[i]
+		}
+	}
+	for i := range weights {
+		switch {
+		case best == 0:
+			// every endpoint is failing; spread load evenly
+			weights[i] = 1
+		case weights[i] < best*minEndpointShare:
+			weights[i] = best * minEndpointShare
+		}
+		total += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+func (p *EndpointPool) run() {
+	if p.config.Probe == nil {
+		select {
+		case <-p.ctx.Done():
+			p.finish(p.ctx.Err())
+		case <-p.stop:
+			p.finish(nil)
+		}
+		return
+	}
+
+	ticker := time.NewTicker(p.config.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			p.finish(p.ctx.Err())
+			return
+		case <-p.stop:
+			p.finish(nil)
+			return
+		case <-ticker.C:
+			p.probeIdle()
+		}
+	}
+}
+
+// probeIdle probes every endpoint with no outcome within ProbeInterval
+func (p *EndpointPool) probeIdle() {
+	cutoff := time.Now().Add(-p.config.ProbeInterval)
+
+	p.mu.Lock()
+	var idle []string
+	for _, e := range p.endpoints {
+		if e.stats.LastUsed.Before(cutoff) {
+			idle = append(idle, e.stats.URL)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, url := range idle {
+		started := time.Now()
+		err := p.config.Probe(p.ctx, url)
+		if p.ctx.Err() != nil {
+			return
+		}
+		p.Report(url, time.Since(started), err)
+	}
+}
@@ -0,0 +1,168 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPool(t *testing.T, config EndpointPoolConfig) *EndpointPool {
+	t.Helper()
+	pool, err := NewEndpointPool(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+func statsByURL(pool *EndpointPool) map[string]EndpointStats {
+	byURL := map[string]EndpointStats{}
+	for _, s := range pool.Stats() {
+		byURL[s.URL] = s
+	}
+	return byURL
+}
+
+func TestEndpointPool(t *testing.T) {
+	t.Run("requires endpoints", func(t *testing.T) {
+		_, err := NewEndpointPool(context.Background(), EndpointPoolConfig{})
+		assert.ErrorIs(t, err, ErrNoEndpoints)
+	})
+
+	t.Run("spreads evenly before any measurement", func(t *testing.T) {
+		pool := newTestPool(t, EndpointPoolConfig{URLs: []string{"a", "b"}})
+		stats := statsByURL(pool)
+		assert.InDelta(t, 0.5, stats["a"].Weight, 1e-9)
+		assert.InDelta(t, 0.5, stats["b"].Weight, 1e-9)
+	})
+
+	t.Run("prefers fast healthy nodes", func(t *testing.T) {
+		pool := newTestPool(t, EndpointPoolConfig{URLs: []string{"fast", "slow", "failing"}})
+		for i := 0; i < 20; i++ {
+			pool.Report("fast", 10*time.Millisecond, nil)
+			pool.Report("slow", 200*time.Millisecond, nil)
+			pool.Report("failing", 10*time.Millisecond, errors.New("503"))
+		}
+
+		stats := pool.Stats()
+		assert.Equal(t, "fast", stats[0].URL)
+		assert.Equal(t, "slow", stats[1].URL)
+		assert.Greater(t, stats[0].Weight, 0.9)
+		assert.Greater(t, stats[2].Weight, 0.0, "the worst node keeps a trickle of traffic")
+		assert.Greater(t, stats[2].ErrorRate, 0.9)
+		assert.Equal(t, int64(20), stats[2].Failures)
+
+		picks := map[string]int{}
+		for i := 0; i < 1000; i++ {
+			url, err := pool.Pick()
+			require.NoError(t, err)
+			picks[url]++
+		}
+		assert.Greater(t, picks["fast"], 850)
+		assert.Equal(t, int64(picks["fast"]), statsByURL(pool)["fast"].Routed)
+	})
+
+	t.Run("EWMA recovers after failures stop", func(t *testing.T) {
+		pool := newTestPool(t, EndpointPoolConfig{URLs: []string{"a"}, Alpha: 0.5})
+		pool.Report("a", 10*time.Millisecond, errors.New("timeout"))
+		for i := 0; i < 10; i++ {
+			pool.Report("a", 10*time.Millisecond, nil)
+		}
+		assert.Less(t, statsByURL(pool)["a"].ErrorRate, 0.001)
+	})
+
+	t.Run("SetEndpoints keeps stats of remaining endpoints", func(t *testing.T) {
+		pool := newTestPool(t, EndpointPoolConfig{URLs: []string{"a", "b"}})
+		pool.Report("a", 10*time.Millisecond, nil)
+
+		pool.SetEndpoints([]string{"a", "c", "c", ""})
+		assert.Equal(t, []string{"a", "c"}, pool.URLs())
+		assert.Equal(t, int64(1), statsByURL(pool)["a"].Requests)
+
+		pool.SetEndpoints(nil)
+		_, err := pool.Pick()
+		assert.ErrorIs(t, err, ErrNoEndpoints)
+	})
+
+	t.Run("probes idle endpoints in the background", func(t *testing.T) {
+		var probes int32
+		pool := newTestPool(t, EndpointPoolConfig{
+			URLs:          []string{"a", "b"},
+			ProbeInterval: 5 * time.Millisecond,
+			Probe: func(ctx context.Context, url string) error {
+				atomic.AddInt32(&probes, 1)
+				if url == "b" {
+					return errors.New("down")
+				}
+				return nil
+			},
+		})
+
+		require.Eventually(t, func() bool { return statsByURL(pool)["b"].Failures > 0 }, time.Second, 5*time.Millisecond)
+		assert.Greater(t, atomic.LoadInt32(&probes), int32(1))
+		assert.Zero(t, statsByURL(pool)["a"].Failures)
+		assert.NoError(t, pool.Close())
+	})
+}
+
+func TestEndpointPoolAffinity(t *testing.T) {
+	nodes := []string{"a", "b", "c", "d"}
+	keys := make([]string, 400)
+	for i := range keys {
+		keys[i] = "DAG" + strconv.Itoa(i)
+	}
+
+	t.Run("routes a key to the same node", func(t *testing.T) {
+		pool := newTestPool(t, EndpointPoolConfig{URLs: nodes})
+		counts := map[string]int{}
+		for _, key := range keys {
+			first, err := pool.PickFor(key)
+			require.NoError(t, err)
+			again, _ := pool.PickFor(key)
+			assert.Equal(t, first, again)
+			counts[first]++
+		}
+		for _, node := range nodes {
+			assert.Greater(t, counts[node], 50, "keys should spread over every node")
+		}
+	})
+
+	t.Run("removing a node only moves its keys", func(t *testing.T) {
+		pool := newTestPool(t, EndpointPoolConfig{URLs: nodes})
+		before := map[string]string{}
+		for _, key := range keys {
+			before[key], _ = pool.PickFor(key)
+		}
+
+		pool.SetEndpoints([]string{"a", "b", "c"})
+		for _, key := range keys {
+			after, _ := pool.PickFor(key)
+			if before[key] != "d" {
+				assert.Equal(t, before[key], after, key)
+			} else {
+				assert.NotEqual(t, "d", after)
+			}
+		}
+	})
+
+	t.Run("moves keys off unhealthy nodes", func(t *testing.T) {
+		pool := newTestPool(t, EndpointPoolConfig{URLs: nodes})
+		owner, _ := pool.PickFor("DAG0")
+		pool.Report(owner, time.Millisecond, errors.New("down"))
+
+		fallback, _ := pool.PickFor("DAG0")
+		assert.NotEqual(t, owner, fallback)
+
+		for i := 0; i < 10; i++ {
+			pool.Report(owner, time.Millisecond, nil)
+		}
+		recovered, _ := pool.PickFor("DAG0")
+		assert.Equal(t, owner, recovered)
+		assert.Equal(t, int64(2), statsByURL(pool)[owner].Routed)
+	})
+}
@@ -0,0 +1,99 @@
+package constellation
+
+import "strings"
+
+// EventSink forwards events to external streaming infrastructure
+type EventSink interface {
+	// Send delivers one event; an error means the event was not accepted
+	Send(event Event) error
+}
+
+// SinkHandler adapts a sink into an EventHandler for EventSource.Subscribe.
+// onError, if non-nil, receives events the sink rejected.
+func SinkHandler(sink EventSink, onError func(event Event, err error)) EventHandler {
+	return func(event Event) {
+		if err := sink.Send(event); err != nil && onError != nil {
+			onError(event, err)
+		}
+	}
+}
+
+// eventKey returns the partitioning key for an event: the transaction hash
+// or address it concerns, so related events stay ordered on one partition
+func eventKey(event Event) string {
+	switch e := event.(type) {
+	case DepositDetected:
+		return e.Destination
+	case DepositFinalized:
+		return e.Destination
+	case TxConfirmed:
+		return e.Hash
+	case TxDropped:
+		return e.Hash
+	case BalanceChanged:
+		return e.Address
+	case SnapshotAdvanced:
+		return e.Hash
+	default:
+		return ""
+	}
+}
+
+// NATSPublisher is the subset of *nats.Conn used by NATSSink
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink publishes events to NATS subjects named "<SubjectPrefix>.<EventType>"
+//
+// Example:
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	sink := &NATSSink{Conn: nc, SubjectPrefix: "constellation.events"}
+//	bus.Subscribe(SinkHandler(sink, nil))
+type NATSSink struct {
+	Conn          NATSPublisher
+	SubjectPrefix string
+}
+
+// Send publishes the event envelope to its subject
+func (s *NATSSink) Send(event Event) error {
+	body, err := MarshalEvent(event)
+	if err != nil {
+		return err
+	}
+	subject := string(event.Type())
+	if s.SubjectPrefix != "" {
+		subject = strings.TrimSuffix(s.SubjectPrefix, ".") + "." + subject
+	}
+	return s.Conn.Publish(subject, body)
+}
+
+// KafkaProducer produces a single keyed message to a topic. Wrap your Kafka
+// client of choice, e.g. for segmentio/kafka-go:
+//
+//	type kafkaGo struct{ w *kafka.Writer }
+//
+//	func (k kafkaGo) Produce(topic string, key, value []byte) error {
+//	    return k.w.WriteMessages(context.Background(),
+//	        kafka.Message{Topic: topic, Key: key, Value: value})
+//	}
+type KafkaProducer interface {
+	Produce(topic string, key, value []byte) error
+}
+
+// KafkaSink produces events to a Kafka topic, keyed by the hash or address the
+// event concerns so per-transaction and per-address ordering is preserved
+type KafkaSink struct {
+	Producer KafkaProducer
+	Topic    string
+}
+
+// Send produces the event envelope to the topic
+func (s *KafkaSink) Send(event Event) error {
+	body, err := MarshalEvent(event)
+	if err != nil {
+		return err
+	}
+	return s.Producer.Produce(s.Topic, []byte(eventKey(event)), body)
+}
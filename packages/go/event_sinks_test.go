@@ -0,0 +1,78 @@
+package constellation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	subjects []string
+	bodies   [][]byte
+}
+
+func (p *recordingPublisher) Publish(subject string, data []byte) error {
+	p.subjects = append(p.subjects, subject)
+	p.bodies = append(p.bodies, data)
+	return nil
+}
+
+type recordingProducer struct {
+	topics []string
+	keys   []string
+	err    error
+}
+
+func (p *recordingProducer) Produce(topic string, key, value []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.topics = append(p.topics, topic)
+	p.keys = append(p.keys, string(key))
+	return nil
+}
+
+func TestNATSSink(t *testing.T) {
+	conn := &recordingPublisher{}
+	sink := &NATSSink{Conn: conn, SubjectPrefix: "constellation.events."}
+
+	require.NoError(t, sink.Send(DepositDetected{Hash: "h1", Destination: "DAGx", Amount: 5}))
+
+	assert.Equal(t, []string{"constellation.events.DepositDetected"}, conn.subjects)
+
+	var envelope EventEnvelope
+	require.NoError(t, json.Unmarshal(conn.bodies[0], &envelope))
+	assert.Equal(t, EventDepositDetected, envelope.Type)
+
+	var deposit DepositDetected
+	require.NoError(t, json.Unmarshal(envelope.Data, &deposit))
+	assert.Equal(t, int64(5), deposit.Amount)
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer := &recordingProducer{}
+	sink := &KafkaSink{Producer: producer, Topic: "events"}
+
+	require.NoError(t, sink.Send(TxConfirmed{Hash: "h1"}))
+	require.NoError(t, sink.Send(BalanceChanged{Address: "DAGx"}))
+
+	assert.Equal(t, []string{"events", "events"}, producer.topics)
+	assert.Equal(t, []string{"h1", "DAGx"}, producer.keys)
+}
+
+func TestSinkHandlerReportsErrors(t *testing.T) {
+	producer := &recordingProducer{err: errors.New("broker down")}
+	var failed []Event
+
+	bus := NewEventBus()
+	bus.Subscribe(SinkHandler(&KafkaSink{Producer: producer, Topic: "events"}, func(e Event, err error) {
+		failed = append(failed, e)
+	}))
+	bus.Publish(TxDropped{Hash: "h1"})
+
+	require.Len(t, failed, 1)
+	assert.Equal(t, EventTxDropped, failed[0].Type())
+}
@@ -0,0 +1,189 @@
+package constellation
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of a domain event
+type EventType string
+
+const (
+	EventDepositDetected  EventType = "DepositDetected"
+	EventDepositFinalized EventType = "DepositFinalized"
+	EventTxConfirmed      EventType = "TxConfirmed"
+	EventTxDropped        EventType = "TxDropped"
+	EventBalanceChanged   EventType = "BalanceChanged"
+	EventSnapshotAdvanced EventType = "SnapshotAdvanced"
+)
+
+// Event is a domain event emitted by scanner, watcher and follower components
+type Event interface {
+	// Type returns the event kind
+	Type() EventType
+	// OccurredAt returns when the event was observed
+	OccurredAt() time.Time
+}
+
+// DepositDetected is emitted when an incoming transfer to a watched address is seen
+type DepositDetected struct {
+	Hash        string    `json:"hash"`
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	Amount      int64     `json:"amount"`
+	Ordinal     int64     `json:"ordinal"`
+	At          time.Time `json:"at"`
+}
+
+// DepositFinalized is emitted when a detected deposit has the confirmation
+// depth its ConfirmationPolicy requires and can be credited
+type DepositFinalized struct {
+	Hash        string `json:"hash"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Amount      int64  `json:"amount"`
+	// Ordinal is the snapshot the deposit was detected in
+	Ordinal int64 `json:"ordinal"`
+	// Depth is the confirmation depth the policy required
+	Depth int64 `json:"depth"`
+	// FinalOrdinal is the snapshot that completed the depth
+	FinalOrdinal int64     `json:"finalOrdinal"`
+	At           time.Time `json:"at"`
+}
+
+// TxConfirmed is emitted when a transaction is included in a snapshot
+type TxConfirmed struct {
+	Hash    string    `json:"hash"`
+	Ordinal int64     `json:"ordinal"`
+	At      time.Time `json:"at"`
+}
+
+// TxDropped is emitted when a pending transaction disappears without confirming
+type TxDropped struct {
+	Hash   string    `json:"hash"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// BalanceChanged is emitted when a watched address balance differs from the last observation
+type BalanceChanged struct {
+	Address  string    `json:"address"`
+	Previous int64     `json:"previous"`
+	Current  int64     `json:"current"`
+	Ordinal  int64     `json:"ordinal"`
+	At       time.Time `json:"at"`
+}
+
+// SnapshotAdvanced is emitted when a follower observes a new snapshot
+type SnapshotAdvanced struct {
+	Ordinal int64     `json:"ordinal"`
+	Hash    string    `json:"hash"`
+	At      time.Time `json:"at"`
+}
+
+func (e DepositDetected) Type() EventType  { return EventDepositDetected }
+func (e DepositFinalized) Type() EventType { return EventDepositFinalized }
+func (e TxConfirmed) Type() EventType      { return EventTxConfirmed }
+func (e TxDropped) Type() EventType        { return EventTxDropped }
+func (e BalanceChanged) Type() EventType   { return EventBalanceChanged }
+func (e SnapshotAdvanced) Type() EventType { return EventSnapshotAdvanced }
+
+func (e DepositDetected) OccurredAt() time.Time  { return e.At }
+func (e DepositFinalized) OccurredAt() time.Time { return e.At }
+func (e TxConfirmed) OccurredAt() time.Time      { return e.At }
+func (e TxDropped) OccurredAt() time.Time        { return e.At }
+func (e BalanceChanged) OccurredAt() time.Time   { return e.At }
+func (e SnapshotAdvanced) OccurredAt() time.Time { return e.At }
+
+// EventEnvelope is the wire format used when events leave the process
+// (webhooks, message queues)
+type EventEnvelope struct {
+	Type       EventType       `json:"type"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// MarshalEvent encodes an event in its EventEnvelope wire format
+func MarshalEvent(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(EventEnvelope{
+		Type:       event.Type(),
+		OccurredAt: event.OccurredAt(),
+		Data:       data,
+	})
+}
+
+// EventHandler receives events from an EventSource
+type EventHandler func(Event)
+
+// EventSource is the single subscription interface shared by every
+// event-emitting component, so consumers integrate once
+type EventSource interface {
+	// Subscribe registers handler for all events and returns a function that
+	// removes the subscription
+	Subscribe(handler EventHandler) (unsubscribe func())
+}
+
+// EventBus fans events out to subscribers. Components embed or share one bus
+// and call Publish; consumers call Subscribe.
+//
+// Handlers run synchronously on the publishing goroutine, in subscription
+// order, so a slow handler delays the producer.
+type EventBus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]EventHandler
+	order    []int
+}
+
+// NewEventBus creates an empty EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: map[int]EventHandler{}}
+}
+
+// Subscribe registers handler for all future events
+func (b *EventBus) Subscribe(handler EventHandler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+	b.order = append(b.order, id)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.remove(id) })
+	}
+}
+
+func (b *EventBus) remove(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.handlers, id)
+	for i, existing := range b.order {
+		if existing == id {
+			b.order = append(b.order[:i], b.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Publish delivers event to every current subscriber
+func (b *EventBus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := make([]EventHandler, 0, len(b.order))
+	for _, id := range b.order {
+		handlers = append(handlers, b.handlers[id])
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,59 @@
+package constellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+	t.Run("delivers events to subscribers in order", func(t *testing.T) {
+		bus := NewEventBus()
+		var got []string
+
+		bus.Subscribe(func(e Event) { got = append(got, "a:"+string(e.Type())) })
+		bus.Subscribe(func(e Event) { got = append(got, "b:"+string(e.Type())) })
+
+		bus.Publish(TxConfirmed{Hash: "h", Ordinal: 7, At: time.Now()})
+
+		assert.Equal(t, []string{"a:TxConfirmed", "b:TxConfirmed"}, got)
+	})
+
+	t.Run("unsubscribe stops delivery", func(t *testing.T) {
+		bus := NewEventBus()
+		count := 0
+
+		unsubscribe := bus.Subscribe(func(Event) { count++ })
+		bus.Publish(SnapshotAdvanced{Ordinal: 1})
+		unsubscribe()
+		unsubscribe()
+		bus.Publish(SnapshotAdvanced{Ordinal: 2})
+
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("events report type and time", func(t *testing.T) {
+		at := time.Unix(1700000000, 0)
+		events := []Event{
+			DepositDetected{At: at},
+			DepositFinalized{At: at},
+			TxConfirmed{At: at},
+			TxDropped{At: at},
+			BalanceChanged{At: at},
+			SnapshotAdvanced{At: at},
+		}
+		types := []EventType{
+			EventDepositDetected,
+			EventDepositFinalized,
+			EventTxConfirmed,
+			EventTxDropped,
+			EventBalanceChanged,
+			EventSnapshotAdvanced,
+		}
+		for i, e := range events {
+			assert.Equal(t, types[i], e.Type())
+			assert.Equal(t, at, e.OccurredAt())
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package constellation
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnknownAsset indicates the rate provider has no mapping for an asset
+var ErrUnknownAsset = errors.New("unknown asset for exchange rate provider")
+
+// ExchangeRate is the fiat price of one whole token at a point in time
+type ExchangeRate struct {
+	// Asset is the token symbol, e.g. "DAG"
+	Asset string
+	// Fiat is the lowercase fiat currency code, e.g. "usd"
+	Fiat string
+	// Rate is the price of one token in Fiat
+	Rate float64
+	// At is the time the rate applies to
+	At time.Time
+}
+
+// FiatValue converts an amount in smallest units (1e-8) to its fiat value
+func (r *ExchangeRate) FiatValue(units int64) float64 {
+	return UnitsToToken(units) * r.Rate
+}
+
+// ExchangeRateProvider supplies historical fiat prices so amounts can be
+// annotated with their value at transaction time
+//
+// Rate returns nil (and no error) when no price is available for that time.
+type ExchangeRateProvider interface {
+	Rate(asset string, fiat string, at time.Time) (*ExchangeRate, error)
+}
+
+// NoExchangeRates is the default ExchangeRateProvider; it never has a rate
+type NoExchangeRates struct{}
+
+// Rate always returns nil
+func (NoExchangeRates) Rate(asset string, fiat string, at time.Time) (*ExchangeRate, error) {
+	return nil, nil
+}
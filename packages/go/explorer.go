@@ -0,0 +1,95 @@
+package constellation
+
+import "time"
+
+// ExplorerTransaction is a confirmed transaction as indexed by the block explorer
+type ExplorerTransaction struct {
+	Hash        string `json:"hash"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	// Amount in smallest units (1e-8)
+	Amount int64 `json:"amount"`
+	// Fee in smallest units (1e-8)
+	Fee          int64                `json:"fee"`
+	Parent       TransactionReference `json:"parent"`
+	BlockHash    string               `json:"blockHash"`
+	SnapshotHash string               `json:"snapshotHash"`
+	// SnapshotOrdinal is the global snapshot that confirmed the transaction
+	SnapshotOrdinal int64     `json:"snapshotOrdinal"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// ExplorerSnapshot is a global snapshot as indexed by the block explorer
+type ExplorerSnapshot struct {
+	Hash             string    `json:"hash"`
+	Ordinal          int64     `json:"ordinal"`
+	Height           int64     `json:"height"`
+	SubHeight        int64     `json:"subHeight"`
+	LastSnapshotHash string    `json:"lastSnapshotHash"`
+	Blocks           []string  `json:"blocks"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// BlockReference identifies a DAG block by height and hash
+type BlockReference struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// ExplorerBlock is a DAG block as indexed by the block explorer
+type ExplorerBlock struct {
+	Hash   string `json:"hash"`
+	Height int64  `json:"height"`
+	// Parents are the blocks this block builds on
+	Parents []BlockReference `json:"parent"`
+	// Transactions holds the hashes of the transactions in the block
+	Transactions []string `json:"transactions"`
+	SnapshotHash string   `json:"snapshotHash"`
+	// SnapshotOrdinal is the global snapshot that confirmed the block
+	SnapshotOrdinal int64     `json:"snapshotOrdinal"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// ExplorerAPI is the set of block explorer queries used by dashboards and
+// statement tooling. network.ExplorerClient implements it against the
+// public explorer; ExplorerCache implements it on top of another ExplorerAPI.
+type ExplorerAPI interface {
+	// GetTransactionsByAddress returns up to limit of the most recent
+	// transactions sent or received by address, newest first
+	GetTransactionsByAddress(address string, limit int) ([]ExplorerTransaction, error)
+	// GetSnapshot returns the global snapshot at ordinal, or nil if the
+	// explorer has not indexed it
+	GetSnapshot(ordinal int64) (*ExplorerSnapshot, error)
+	// GetLatestSnapshot returns the most recent global snapshot
+	GetLatestSnapshot() (*ExplorerSnapshot, error)
+}
+
+// ExplorerTransactionLookup looks up a confirmed transaction by hash.
+// network.ExplorerClient and network.GraphQLExplorerClient implement it,
+// and so does an ExplorerCache whose upstream does.
+type ExplorerTransactionLookup interface {
+	// GetTransaction returns the confirmed transaction, or nil if the
+	// explorer has not indexed it
+	GetTransaction(hash string) (*ExplorerTransaction, error)
+}
+
+// ExplorerBlockLookup queries the DAG blocks confirmed in a snapshot, for
+// per-block analytics and fork investigations. network.ExplorerClient and
+// network.GraphQLExplorerClient implement it, and so does an ExplorerCache
+// whose upstream does.
+type ExplorerBlockLookup interface {
+	// GetSnapshotBlocks returns the blocks confirmed in the global snapshot
+	// at ordinal, or nil if the explorer has not indexed that snapshot
+	GetSnapshotBlocks(ordinal int64) ([]ExplorerBlock, error)
+	// GetBlock returns the block, or nil if the explorer has not indexed it
+	GetBlock(hash string) (*ExplorerBlock, error)
+	// GetBlockTransactions returns the transactions in the block, or nil if
+	// the explorer has not indexed it
+	GetBlockTransactions(hash string) ([]ExplorerTransaction, error)
+}
+
+// LatestSnapshotSource reports the most recent global snapshot. Every
+// ExplorerAPI implements it.
+type LatestSnapshotSource interface {
+	GetLatestSnapshot() (*ExplorerSnapshot, error)
+}
@@ -0,0 +1,348 @@
+package constellation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultExplorerCacheTTL = 30 * time.Second
+
+// DefaultExplorerCacheMaxEntries is how many responses a
+// MemoryExplorerCacheStore holds unless given another limit
+const DefaultExplorerCacheMaxEntries = 10000
+
+// ErrExplorerUpstreamRequired indicates an ExplorerCache was configured without an upstream
+var ErrExplorerUpstreamRequired = errors.New("explorer cache requires an upstream ExplorerAPI")
+
+// ErrTransactionLookupUnsupported indicates an explorer that cannot look
+// transactions up by hash
+var ErrTransactionLookupUnsupported = errors.New("explorer does not support transaction lookup by hash")
+
+// ErrBlockLookupUnsupported indicates an explorer that cannot look blocks up
+var ErrBlockLookupUnsupported = errors.New("explorer does not support block lookups")
+
+// ExplorerCacheEntry is one cached explorer response
+type ExplorerCacheEntry struct {
+	// Data is the JSON-encoded response
+	Data json.RawMessage `json:"data"`
+	// Ordinal is the highest snapshot ordinal the response reflects; the
+	// entry is dropped when anything from that ordinal on is invalidated
+	Ordinal int64 `json:"ordinal"`
+	// StoredAt is when the response was cached
+	StoredAt time.Time `json:"storedAt"`
+	// Expires marks entries subject to the cache TTL. Snapshots fetched by
+	// ordinal never change and are kept until invalidated.
+	Expires bool `json:"expires"`
+}
+
+// ExplorerCacheStore holds cached explorer responses. Implement it to keep
+// the mirror in a shared store so several dashboard instances share one
+// cache.
+type ExplorerCacheStore interface {
+	// Get returns the entry for key, or nil if none is stored
+	Get(key string) (*ExplorerCacheEntry, error)
+	// Set stores entry under key, replacing any previous one
+	Set(key string, entry ExplorerCacheEntry) error
+	// DeleteFromOrdinal removes every entry whose Ordinal is at least ordinal
+	DeleteFromOrdinal(ordinal int64) error
+}
+
+// MemoryExplorerCacheStore keeps cached explorer responses in memory, up
+// to a fixed number of entries. When full, storing a new key evicts the
+// oldest entry that expires, or the oldest entry if none does, so queries
+// for addresses no longer polled do not accumulate.
+type MemoryExplorerCacheStore struct {
+	mu         sync.Mutex
+	entries    map[string]ExplorerCacheEntry
+	maxEntries int
+}
+
+// NewMemoryExplorerCacheStore creates an empty MemoryExplorerCacheStore
+// holding up to DefaultExplorerCacheMaxEntries responses
+func NewMemoryExplorerCacheStore() *MemoryExplorerCacheStore {
+	return NewMemoryExplorerCacheStoreWithLimit(DefaultExplorerCacheMaxEntries)
+}
+
+// NewMemoryExplorerCacheStoreWithLimit creates an empty
+// MemoryExplorerCacheStore holding up to maxEntries responses (default:
+// DefaultExplorerCacheMaxEntries)
+func NewMemoryExplorerCacheStoreWithLimit(maxEntries int) *MemoryExplorerCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultExplorerCacheMaxEntries
+	}
+	return &MemoryExplorerCacheStore{entries: map[string]ExplorerCacheEntry{}, maxEntries: maxEntries}
+}
+
+// Get returns the entry for key, or nil if none is stored
+func (s *MemoryExplorerCacheStore) Get(key string) (*ExplorerCacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Set stores entry under key, evicting an older entry when the store is
+// full
+func (s *MemoryExplorerCacheStore) Set(key string, entry ExplorerCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.entries[key] = entry
+	return nil
+}
+
+// evict removes the oldest expiring entry, or the oldest entry if none
+// expires
+func (s *MemoryExplorerCacheStore) evict() {
+	var victim string
+	var oldest ExplorerCacheEntry
+	found := false
+	for key, entry := range s.entries {
+		better := !found ||
+			(entry.Expires && !oldest.Expires) ||
+			(entry.Expires == oldest.Expires && entry.StoredAt.Before(oldest.StoredAt))
+		if better {
+			victim, oldest, found = key, entry, true
+		}
+	}
+	if found {
+		delete(s.entries, victim)
+	}
+}
+
+// DeleteFromOrdinal removes every entry whose Ordinal is at least ordinal
+func (s *MemoryExplorerCacheStore) DeleteFromOrdinal(ordinal int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, entry := range s.entries {
+		if entry.Ordinal >= ordinal {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+// ExplorerCacheConfig holds configuration for an ExplorerCache
+type ExplorerCacheConfig struct {
+	// Upstream answers cache misses, usually a network.ExplorerClient
+	Upstream ExplorerAPI
+	// Store holds cached responses (default: a MemoryExplorerCacheStore)
+	Store ExplorerCacheStore
+	// TTL bounds how stale address transactions and the latest snapshot may
+	// be (default: 30s)
+	TTL time.Duration
+}
+
+// ExplorerCache is a read-only mirror of explorer queries. It answers
+// repeated queries from its store so dashboards polling many addresses do
+// not hit the public explorer on every refresh.
+//
+// Address transactions and the latest snapshot expire after the TTL.
+// Snapshots and their blocks fetched by ordinal, and confirmed
+// transactions and blocks fetched by hash, are immutable and kept until
+// InvalidateFromOrdinal drops them, e.g. after the explorer reindexes.
+//
+// Example:
+//
+//	explorer, _ := NewExplorerClient(NetworkConfig{ExplorerURL: "https://be-mainnet.constellationnetwork.io"})
+//	cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: explorer, TTL: time.Minute})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	txs, err := cache.GetTransactionsByAddress("DAG...", 20)
+type ExplorerCache struct {
+	upstream ExplorerAPI
+	store    ExplorerCacheStore
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewExplorerCache creates an ExplorerCache
+//
+// Returns an error if no Upstream is configured
+func NewExplorerCache(config ExplorerCacheConfig) (*ExplorerCache, error) {
+	if config.Upstream == nil {
+		return nil, ErrExplorerUpstreamRequired
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryExplorerCacheStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultExplorerCacheTTL
+	}
+	return &ExplorerCache{upstream: config.Upstream, store: config.Store, ttl: config.TTL, now: time.Now}, nil
+}
+
+// GetTransactionsByAddress returns cached transactions of address, fetching
+// them when missing or older than the TTL
+func (c *ExplorerCache) GetTransactionsByAddress(address string, limit int) ([]ExplorerTransaction, error) {
+	var txs []ExplorerTransaction
+	key := "transactions:" + address + ":" + strconv.Itoa(limit)
+	err := c.cached(key, &txs, func() (interface{}, int64, bool, error) {
+		fetched, err := c.upstream.GetTransactionsByAddress(address, limit)
+		var ordinal int64
+		for _, tx := range fetched {
+			if tx.SnapshotOrdinal > ordinal {
+				ordinal = tx.SnapshotOrdinal
+			}
+		}
+		return fetched, ordinal, true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// GetSnapshot returns the cached snapshot at ordinal, fetching it when
+// missing. Snapshots the explorer has not indexed are not cached.
+func (c *ExplorerCache) GetSnapshot(ordinal int64) (*ExplorerSnapshot, error) {
+	var snapshot *ExplorerSnapshot
+	err := c.cached("snapshot:"+strconv.FormatInt(ordinal, 10), &snapshot, func() (interface{}, int64, bool, error) {
+		fetched, err := c.upstream.GetSnapshot(ordinal)
+		return fetched, ordinal, false, err
+	})
+	return snapshot, err
+}
+
+// GetLatestSnapshot returns the cached latest snapshot, fetching it when
+// missing or older than the TTL
+func (c *ExplorerCache) GetLatestSnapshot() (*ExplorerSnapshot, error) {
+	var snapshot *ExplorerSnapshot
+	err := c.cached("snapshot:latest", &snapshot, func() (interface{}, int64, bool, error) {
+		fetched, err := c.upstream.GetLatestSnapshot()
+		var ordinal int64
+		if fetched != nil {
+			ordinal = fetched.Ordinal
+		}
+		return fetched, ordinal, true, err
+	})
+	return snapshot, err
+}
+
+// GetTransaction returns the cached confirmed transaction, fetching it when
+// missing. Returns ErrTransactionLookupUnsupported if the upstream cannot
+// look transactions up by hash.
+func (c *ExplorerCache) GetTransaction(hash string) (*ExplorerTransaction, error) {
+	lookup, ok := c.upstream.(ExplorerTransactionLookup)
+	if !ok {
+		return nil, ErrTransactionLookupUnsupported
+	}
+	var tx *ExplorerTransaction
+	err := c.cached("transaction:"+hash, &tx, func() (interface{}, int64, bool, error) {
+		fetched, err := lookup.GetTransaction(hash)
+		var ordinal int64
+		if fetched != nil {
+			ordinal = fetched.SnapshotOrdinal
+		}
+		return fetched, ordinal, false, err
+	})
+	return tx, err
+}
+
+// GetSnapshotBlocks returns the cached blocks of the snapshot at ordinal,
+// fetching them when missing. Returns ErrBlockLookupUnsupported if the
+// upstream cannot look blocks up.
+func (c *ExplorerCache) GetSnapshotBlocks(ordinal int64) ([]ExplorerBlock, error) {
+	lookup, ok := c.upstream.(ExplorerBlockLookup)
+	if !ok {
+		return nil, ErrBlockLookupUnsupported
+	}
+	var blocks []ExplorerBlock
+	err := c.cached("blocks:"+strconv.FormatInt(ordinal, 10), &blocks, func() (interface{}, int64, bool, error) {
+		fetched, err := lookup.GetSnapshotBlocks(ordinal)
+		return fetched, ordinal, false, err
+	})
+	return blocks, err
+}
+
+// GetBlock returns the cached block, fetching it when missing. Returns
+// ErrBlockLookupUnsupported if the upstream cannot look blocks up.
+func (c *ExplorerCache) GetBlock(hash string) (*ExplorerBlock, error) {
+	lookup, ok := c.upstream.(ExplorerBlockLookup)
+	if !ok {
+		return nil, ErrBlockLookupUnsupported
+	}
+	var block *ExplorerBlock
+	err := c.cached("block:"+hash, &block, func() (interface{}, int64, bool, error) {
+		fetched, err := lookup.GetBlock(hash)
+		var ordinal int64
+		if fetched != nil {
+			ordinal = fetched.SnapshotOrdinal
+		}
+		return fetched, ordinal, false, err
+	})
+	return block, err
+}
+
+// GetBlockTransactions returns the cached transactions of a block, fetching
+// them when missing. Returns ErrBlockLookupUnsupported if the upstream
+// cannot look blocks up.
+func (c *ExplorerCache) GetBlockTransactions(hash string) ([]ExplorerTransaction, error) {
+	lookup, ok := c.upstream.(ExplorerBlockLookup)
+	if !ok {
+		return nil, ErrBlockLookupUnsupported
+	}
+	var txs []ExplorerTransaction
+	err := c.cached("block-transactions:"+hash, &txs, func() (interface{}, int64, bool, error) {
+		fetched, err := lookup.GetBlockTransactions(hash)
+		var ordinal int64
+		for _, tx := range fetched {
+			if tx.SnapshotOrdinal > ordinal {
+				ordinal = tx.SnapshotOrdinal
+			}
+		}
+		return fetched, ordinal, false, err
+	})
+	return txs, err
+}
+
+// InvalidateFromOrdinal drops every cached response that reflects snapshot
+// ordinal or later, so the next query refetches it
+func (c *ExplorerCache) InvalidateFromOrdinal(ordinal int64) error {
+	return c.store.DeleteFromOrdinal(ordinal)
+}
+
+// cached decodes the entry for key into result, or calls fetch and stores
+// its value. fetch returns the value, the highest ordinal it reflects and
+// whether the entry expires. Nil values are returned but not stored.
+func (c *ExplorerCache) cached(key string, result interface{}, fetch func() (interface{}, int64, bool, error)) error {
+	entry, err := c.store.Get(key)
+	if err != nil {
+		return fmt.Errorf("explorer cache: %w", err)
+	}
+	if entry != nil && (!entry.Expires || c.now().Sub(entry.StoredAt) < c.ttl) {
+		return json.Unmarshal(entry.Data, result)
+	}
+
+	value, ordinal, expires, err := fetch()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if !isJSONNull(data) {
+		err = c.store.Set(key, ExplorerCacheEntry{Data: data, Ordinal: ordinal, StoredAt: c.now(), Expires: expires})
+		if err != nil {
+			return fmt.Errorf("explorer cache: %w", err)
+		}
+	}
+	return json.Unmarshal(data, result)
+}
+
+func isJSONNull(data []byte) bool {
+	return strings.TrimSpace(string(data)) == "null"
+}
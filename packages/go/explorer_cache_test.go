@@ -0,0 +1,301 @@
+package constellation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExplorer is an in-memory ExplorerAPI that counts upstream calls
+type fakeExplorer struct {
+	transactions map[string][]ExplorerTransaction
+	snapshots    map[int64]*ExplorerSnapshot
+	blocks       map[int64][]ExplorerBlock
+	latest       int64
+	calls        int
+	err          error
+}
+
+func (f *fakeExplorer) GetTransactionsByAddress(address string, limit int) ([]ExplorerTransaction, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	txs := f.transactions[address]
+	if len(txs) > limit {
+		txs = txs[:limit]
+	}
+	return append([]ExplorerTransaction{}, txs...), nil
+}
+
+func (f *fakeExplorer) GetSnapshot(ordinal int64) (*ExplorerSnapshot, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.snapshots[ordinal], nil
+}
+
+func (f *fakeExplorer) GetTransaction(hash string) (*ExplorerTransaction, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, txs := range f.transactions {
+		for _, tx := range txs {
+			if tx.Hash == hash {
+				found := tx
+				return &found, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeExplorer) GetSnapshotBlocks(ordinal int64) ([]ExplorerBlock, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.blocks[ordinal], nil
+}
+
+func (f *fakeExplorer) GetBlock(hash string) (*ExplorerBlock, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, blocks := range f.blocks {
+		for _, block := range blocks {
+			if block.Hash == hash {
+				found := block
+				return &found, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeExplorer) GetBlockTransactions(hash string) ([]ExplorerTransaction, error) {
+	block, err := f.GetBlock(hash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	txs := []ExplorerTransaction{}
+	for _, txHash := range block.Transactions {
+		for _, all := range f.transactions {
+			for _, tx := range all {
+				if tx.Hash == txHash {
+					txs = append(txs, tx)
+				}
+			}
+		}
+	}
+	return txs, nil
+}
+
+func (f *fakeExplorer) GetLatestSnapshot() (*ExplorerSnapshot, error) {
+	return f.GetSnapshot(f.latest)
+}
+
+func newFakeExplorer() *fakeExplorer {
+	return &fakeExplorer{
+		transactions: map[string][]ExplorerTransaction{
+			"DAG0addr": {
+				{Hash: "tx2", Amount: 200, SnapshotOrdinal: 12},
+				{Hash: "tx1", Amount: 100, SnapshotOrdinal: 10},
+			},
+		},
+		snapshots: map[int64]*ExplorerSnapshot{
+			10: {Hash: "s10", Ordinal: 10},
+			12: {Hash: "s12", Ordinal: 12},
+		},
+		blocks: map[int64][]ExplorerBlock{
+			10: {{Hash: "b1", Height: 3, Transactions: []string{"tx1"}, SnapshotOrdinal: 10}},
+			12: {{Hash: "b2", Height: 4, Parents: []BlockReference{{Height: 3, Hash: "b1"}},
+				Transactions: []string{"tx2"}, SnapshotOrdinal: 12}},
+		},
+		latest: 12,
+	}
+}
+
+func TestExplorerCache(t *testing.T) {
+	t.Run("requires an upstream", func(t *testing.T) {
+		_, err := NewExplorerCache(ExplorerCacheConfig{})
+		assert.ErrorIs(t, err, ErrExplorerUpstreamRequired)
+	})
+
+	t.Run("serves repeated queries from the store", func(t *testing.T) {
+		upstream := newFakeExplorer()
+		cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: upstream})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			txs, err := cache.GetTransactionsByAddress("DAG0addr", 10)
+			require.NoError(t, err)
+			require.Len(t, txs, 2)
+			assert.Equal(t, "tx2", txs[0].Hash)
+		}
+		assert.Equal(t, 1, upstream.calls)
+
+		// a different limit is a different query
+		_, err = cache.GetTransactionsByAddress("DAG0addr", 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, upstream.calls)
+	})
+
+	t.Run("refetches expired entries but keeps snapshots by ordinal", func(t *testing.T) {
+		upstream := newFakeExplorer()
+		cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: upstream, TTL: time.Minute})
+		require.NoError(t, err)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+
+		_, err = cache.GetLatestSnapshot()
+		require.NoError(t, err)
+		snapshot, err := cache.GetSnapshot(10)
+		require.NoError(t, err)
+		assert.Equal(t, "s10", snapshot.Hash)
+		assert.Equal(t, 2, upstream.calls)
+
+		now = now.Add(2 * time.Minute)
+		_, err = cache.GetLatestSnapshot()
+		require.NoError(t, err)
+		_, err = cache.GetSnapshot(10)
+		require.NoError(t, err)
+		assert.Equal(t, 3, upstream.calls, "only the latest snapshot should expire")
+	})
+
+	t.Run("invalidates entries from an ordinal", func(t *testing.T) {
+		upstream := newFakeExplorer()
+		cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: upstream})
+		require.NoError(t, err)
+
+		_, err = cache.GetSnapshot(10)
+		require.NoError(t, err)
+		_, err = cache.GetSnapshot(12)
+		require.NoError(t, err)
+		_, err = cache.GetTransactionsByAddress("DAG0addr", 10)
+		require.NoError(t, err)
+		assert.Equal(t, 3, upstream.calls)
+
+		upstream.snapshots[12] = &ExplorerSnapshot{Hash: "s12-reindexed", Ordinal: 12}
+		require.NoError(t, cache.InvalidateFromOrdinal(11))
+
+		_, err = cache.GetSnapshot(10)
+		require.NoError(t, err)
+		assert.Equal(t, 3, upstream.calls, "snapshot 10 predates the invalidated range")
+
+		snapshot, err := cache.GetSnapshot(12)
+		require.NoError(t, err)
+		assert.Equal(t, "s12-reindexed", snapshot.Hash)
+		_, err = cache.GetTransactionsByAddress("DAG0addr", 10)
+		require.NoError(t, err)
+		assert.Equal(t, 5, upstream.calls)
+	})
+
+	t.Run("caches blocks until their snapshot is invalidated", func(t *testing.T) {
+		upstream := newFakeExplorer()
+		cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: upstream, TTL: time.Minute})
+		require.NoError(t, err)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+
+		blocks, err := cache.GetSnapshotBlocks(12)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, "b1", blocks[0].Parents[0].Hash)
+		block, err := cache.GetBlock("b2")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), block.Height)
+		txs, err := cache.GetBlockTransactions("b2")
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, "tx2", txs[0].Hash)
+		calls := upstream.calls
+
+		now = now.Add(2 * time.Minute)
+		_, err = cache.GetSnapshotBlocks(12)
+		require.NoError(t, err)
+		_, err = cache.GetBlock("b2")
+		require.NoError(t, err)
+		_, err = cache.GetBlockTransactions("b2")
+		require.NoError(t, err)
+		assert.Equal(t, calls, upstream.calls, "blocks never expire")
+
+		require.NoError(t, cache.InvalidateFromOrdinal(12))
+		_, err = cache.GetSnapshotBlocks(12)
+		require.NoError(t, err)
+		_, err = cache.GetBlock("b2")
+		require.NoError(t, err)
+		assert.Equal(t, calls+2, upstream.calls)
+
+		blocks, err = cache.GetSnapshotBlocks(99)
+		require.NoError(t, err)
+		assert.Nil(t, blocks, "an unindexed snapshot has no block list")
+	})
+
+	t.Run("reports upstreams without block lookups", func(t *testing.T) {
+		cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: struct{ ExplorerAPI }{newFakeExplorer()}})
+		require.NoError(t, err)
+		_, err = cache.GetSnapshotBlocks(10)
+		assert.ErrorIs(t, err, ErrBlockLookupUnsupported)
+		_, err = cache.GetBlock("b1")
+		assert.ErrorIs(t, err, ErrBlockLookupUnsupported)
+		_, err = cache.GetBlockTransactions("b1")
+		assert.ErrorIs(t, err, ErrBlockLookupUnsupported)
+	})
+
+	t.Run("does not cache missing snapshots or errors", func(t *testing.T) {
+		upstream := newFakeExplorer()
+		cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: upstream})
+		require.NoError(t, err)
+
+		snapshot, err := cache.GetSnapshot(99)
+		require.NoError(t, err)
+		assert.Nil(t, snapshot)
+
+		upstream.snapshots[99] = &ExplorerSnapshot{Hash: "s99", Ordinal: 99}
+		snapshot, err = cache.GetSnapshot(99)
+		require.NoError(t, err)
+		assert.Equal(t, "s99", snapshot.Hash)
+
+		upstream.err = errors.New("explorer down")
+		_, err = cache.GetTransactionsByAddress("DAG0addr", 10)
+		assert.EqualError(t, err, "explorer down")
+	})
+}
+
+func TestMemoryExplorerCacheStore(t *testing.T) {
+	t.Run("evicts expiring entries first when full", func(t *testing.T) {
+		store := NewMemoryExplorerCacheStoreWithLimit(3)
+		start := time.Unix(1700000000, 0)
+		require.NoError(t, store.Set("snapshot:1", ExplorerCacheEntry{StoredAt: start}))
+		require.NoError(t, store.Set("transactions:a", ExplorerCacheEntry{StoredAt: start.Add(time.Second), Expires: true}))
+		require.NoError(t, store.Set("transactions:b", ExplorerCacheEntry{StoredAt: start.Add(2 * time.Second), Expires: true}))
+
+		// replacing a key never evicts
+		require.NoError(t, store.Set("transactions:b", ExplorerCacheEntry{StoredAt: start.Add(3 * time.Second), Expires: true}))
+		require.NoError(t, store.Set("snapshot:2", ExplorerCacheEntry{StoredAt: start.Add(4 * time.Second)}))
+
+		evicted, err := store.Get("transactions:a")
+		require.NoError(t, err)
+		assert.Nil(t, evicted, "the oldest expiring entry goes first")
+		for _, key := range []string{"snapshot:1", "transactions:b", "snapshot:2"} {
+			entry, err := store.Get(key)
+			require.NoError(t, err)
+			assert.NotNil(t, entry, key)
+		}
+
+		require.NoError(t, store.Set("transactions:c", ExplorerCacheEntry{StoredAt: start.Add(5 * time.Second), Expires: true}))
+		require.NoError(t, store.Set("snapshot:3", ExplorerCacheEntry{StoredAt: start.Add(6 * time.Second)}))
+		require.NoError(t, store.Set("snapshot:4", ExplorerCacheEntry{StoredAt: start.Add(7 * time.Second)}))
+		evicted, err = store.Get("snapshot:1")
+		require.NoError(t, err)
+		assert.Nil(t, evicted, "the oldest entry goes once none expires")
+		assert.Len(t, store.entries, 3)
+	})
+}
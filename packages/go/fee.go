@@ -0,0 +1,82 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrFeePrecision indicates a fee with more than 8 decimal places
+	ErrFeePrecision = errors.New("fee has more than 8 decimal places")
+	// ErrFeeExceedsAmount indicates a fee larger than the amount it pays for
+	ErrFeeExceedsAmount = errors.New("fee exceeds amount")
+)
+
+// FeeWarning flags a fee that is valid but probably a mistake
+type FeeWarning string
+
+const (
+	// FeeWarningExceedsAmount means the fee is larger than the transferred amount
+	FeeWarningExceedsAmount FeeWarning = "fee exceeds amount"
+)
+
+// EffectiveDebit returns the amount plus fee in smallest units: what the
+// source balance must cover. Returns ErrAmountOutOfRange on overflow.
+func EffectiveDebit(amount int64, fee int64) (int64, error) {
+	if fee > 0 && amount > math.MaxInt64-fee {
+		return 0, fmt.Errorf("%w: %d + %d", ErrAmountOutOfRange, amount, fee)
+	}
+	return amount + fee, nil
+}
+
+// EffectiveDebit returns the transaction's amount plus fee
+func (v CurrencyTransactionValue) EffectiveDebit() (int64, error) {
+	return EffectiveDebit(v.Amount, v.Fee)
+}
+
+// CheckFee validates a fee against its amount, both in smallest units. It
+// returns ErrInvalidFee for a negative fee and ErrAmountOutOfRange when the
+// debit overflows; suspicious but valid fees come back as warnings.
+func CheckFee(amount int64, fee int64) ([]FeeWarning, error) {
+	if fee < 0 {
+		return nil, ErrInvalidFee
+	}
+	if _, err := EffectiveDebit(amount, fee); err != nil {
+		return nil, err
+	}
+
+	var warnings []FeeWarning
+	if fee > amount {
+		warnings = append(warnings, FeeWarningExceedsAmount)
+	}
+	return warnings, nil
+}
+
+// CheckTransferFee is CheckFee for token amounts, as in TransferParams. It
+// also returns ErrFeePrecision for a fee finer than 1e-8, which conversion to
+// units would silently truncate.
+//
+// Example:
+//
+//	warnings, err := CheckTransferFee(params)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, w := range warnings {
+//	    log.Printf("withdrawal %s: %s", id, w)
+//	}
+func CheckTransferFee(params TransferParams) ([]FeeWarning, error) {
+	// check the units the transaction would be built with
+	amount, fee, err := transferUnits(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.ExactFee.units == nil {
+		// a fee of whole units lands within float error of an integer
+		if _, frac := math.Modf(params.Fee * 1e8); frac > 1e-6 && frac < 1-1e-6 {
+			return nil, fmt.Errorf("%w: %v", ErrFeePrecision, params.Fee)
+		}
+	}
+	return CheckFee(amount, fee)
+}
@@ -0,0 +1,117 @@
+package constellation
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeVectorParity(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "shared", "currency_transaction_vectors.json"))
+	require.NoError(t, err)
+	var vectors struct {
+		TestVectors struct {
+			BasicTransaction struct {
+				PrivateKeyHex string `json:"privateKeyHex"`
+				Transaction   struct {
+					Source      string `json:"source"`
+					Destination string `json:"destination"`
+				} `json:"transaction"`
+				SignerID string `json:"signerId"`
+			} `json:"basicTransaction"`
+			EdgeCases struct {
+				WithFee struct {
+					Amount    int64  `json:"amount"`
+					Fee       int64  `json:"fee"`
+					Hash      string `json:"hash"`
+					Encoded   string `json:"encoded"`
+					Signature string `json:"signature"`
+				} `json:"withFee"`
+			} `json:"edgeCases"`
+		} `json:"testVectors"`
+	}
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	basic := vectors.TestVectors.BasicTransaction
+	withFee := vectors.TestVectors.EdgeCases.WithFee
+
+	salt, _ := new(big.Int).SetString("aa87bee538000", 16)
+	parent := TransactionReference{Hash: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", Ordinal: 0}
+
+	tx, err := NewTransactionBuilder(basic.Transaction.Source).Build(basic.Transaction.Destination, withFee.Amount, withFee.Fee, parent)
+	require.NoError(t, err)
+	tx.Value.Salt = salt.String()
+
+	t.Run("builder encodes the fee like the vector", func(t *testing.T) {
+		assert.Equal(t, withFee.Encoded, EncodeCurrencyTransaction(tx))
+		assert.Equal(t, withFee.Hash, HashCurrencyTransaction(tx).Value)
+	})
+
+	t.Run("vector signature verifies on the built transaction", func(t *testing.T) {
+		vectorSigned := *tx
+		vectorSigned.Proofs = []SignatureProof{{ID: basic.SignerID, Signature: withFee.Signature}}
+		assert.True(t, VerifyCurrencyTransactionStrict(&vectorSigned))
+	})
+
+	t.Run("signer output verifies", func(t *testing.T) {
+		signer, err := NewPrivateKeySigner(basic.PrivateKeyHex)
+		require.NoError(t, err)
+		signed, err := SignTransaction(tx, signer)
+		require.NoError(t, err)
+		assert.Equal(t, withFee.Hash, HashCurrencyTransaction(signed).Value)
+		assert.True(t, VerifyCurrencyTransactionStrict(signed))
+
+		debit, err := signed.Value.EffectiveDebit()
+		require.NoError(t, err)
+		assert.Equal(t, withFee.Amount+withFee.Fee, debit)
+	})
+}
+
+func TestFeeChecks(t *testing.T) {
+	t.Run("effective debit", func(t *testing.T) {
+		debit, err := EffectiveDebit(100, 5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(105), debit)
+
+		_, err = EffectiveDebit(math.MaxInt64, 1)
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+
+		_, err = NewTransactionBuilder("DAG1vTmrhDPkNkUEb5yGbH9i5R9xTDNMFpHQwRvR").Build("DAG4o41NzhfX6DyYBTTXu6sJa6awm36abJpv89jB", math.MaxInt64, 1, GenesisReference)
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	})
+
+	t.Run("warnings and errors", func(t *testing.T) {
+		warnings, err := CheckFee(100, 5)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+
+		warnings, err = CheckFee(100, 500)
+		require.NoError(t, err)
+		assert.Equal(t, []FeeWarning{FeeWarningExceedsAmount}, warnings)
+
+		_, err = CheckFee(100, -1)
+		assert.ErrorIs(t, err, ErrInvalidFee)
+	})
+
+	t.Run("token fee precision", func(t *testing.T) {
+		warnings, err := CheckTransferFee(TransferParams{Amount: 0.001, Fee: 0.29})
+		require.NoError(t, err)
+		assert.Equal(t, []FeeWarning{FeeWarningExceedsAmount}, warnings)
+
+		// compares the units the transaction is built with
+		warnings, err = CheckTransferFee(TransferParams{Amount: 0.29, Fee: 0.29})
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+
+		_, err = CheckTransferFee(TransferParams{Amount: 1, Fee: 0.000000001})
+		assert.ErrorIs(t, err, ErrFeePrecision)
+
+		_, err = CheckTransferFee(TransferParams{Amount: 1, Fee: math.NaN()})
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	})
+}
@@ -0,0 +1,121 @@
+package constellation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+)
+
+// FileDigestSHA256 is the digest algorithm of file signatures
+const FileDigestSHA256 = "SHA-256"
+
+var (
+	// ErrUnsupportedDigestAlgorithm indicates a file signature with an unknown digest algorithm
+	ErrUnsupportedDigestAlgorithm = errors.New("unsupported digest algorithm")
+	// ErrFileHashMismatch indicates a file whose contents differ from the signed hash
+	ErrFileHashMismatch = errors.New("file does not match the signed hash")
+	// ErrFileSignatureInvalid indicates a signature that does not verify against the signer
+	ErrFileSignatureInvalid = errors.New("file signature is invalid")
+)
+
+// FileSignature is a detached proof over a file or document. It is plain
+// JSON, so it can be stored next to the file (e.g. report.pdf.sig.json).
+type FileSignature struct {
+	// DigestAlgorithm is the file hash algorithm (FileDigestSHA256)
+	DigestAlgorithm string `json:"digestAlgorithm"`
+	// FileHash is the hex digest of the file contents
+	FileHash string `json:"fileHash"`
+	// Signature is the DER signature hex, made with the SDK's hash signing
+	// protocol (see SignHash)
+	Signature string `json:"signature"`
+	// SignerID is the signer's public key ID (without 04 prefix)
+	SignerID string `json:"signerId"`
+}
+
+// SignerAddress returns the DAG address of the signer
+func (s *FileSignature) SignerAddress() string {
+	return GetAddress(s.SignerID)
+}
+
+// SignFile creates a detached signature over the file at path
+//
+// Example:
+//
+//	signer, _ := NewPrivateKeySigner(privateKey)
+//	sig, err := SignFile("report.pdf", signer)
+//	// later, anywhere:
+//	err = VerifyFileSignature("report.pdf", sig)
+func SignFile(path string, signer Signer) (*FileSignature, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return SignReader(file, signer)
+}
+
+// SignReader creates a detached signature over everything read from r
+func SignReader(r io.Reader, signer Signer) (*FileSignature, error) {
+	if err := CheckSigningAllowed(); err != nil {
+		return nil, err
+	}
+	hashHex, err := sha256Hex(r)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.SignHash(hashHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSignature{
+		DigestAlgorithm: FileDigestSHA256,
+		FileHash:        hashHex,
+		Signature:       signature,
+		SignerID:        NormalizePublicKeyToID(signer.PublicKey()),
+	}, nil
+}
+
+// VerifyFileSignature checks a detached signature against the file at path.
+// Check SignerAddress too, to know who signed it.
+func VerifyFileSignature(path string, sig *FileSignature) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return VerifyReaderSignature(file, sig)
+}
+
+// VerifyReaderSignature checks a detached signature against everything read
+// from r
+func VerifyReaderSignature(r io.Reader, sig *FileSignature) error {
+	if sig.DigestAlgorithm != FileDigestSHA256 {
+		return ErrUnsupportedDigestAlgorithm
+	}
+
+	hashHex, err := sha256Hex(r)
+	if err != nil {
+		return err
+	}
+	if hashHex != sig.FileHash {
+		return ErrFileHashMismatch
+	}
+
+	if valid, _ := VerifyHash(hashHex, sig.Signature, sig.SignerID); !valid {
+		return ErrFileSignatureInvalid
+	}
+	return nil
+}
+
+// sha256Hex returns the hex SHA-256 of everything read from r
+func sha256Hex(r io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,55 @@
+package constellation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSignature(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	signer, err := NewPrivateKeySigner(keyPair.PrivateKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte("test"), 0o600))
+
+	sig, err := SignFile(path, signer)
+	require.NoError(t, err)
+	assert.Equal(t, FileDigestSHA256, sig.DigestAlgorithm)
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", sig.FileHash)
+	assert.Equal(t, keyPair.Address, sig.SignerAddress())
+
+	t.Run("verifies the file", func(t *testing.T) {
+		assert.NoError(t, VerifyFileSignature(path, sig))
+		assert.NoError(t, VerifyReaderSignature(strings.NewReader("test"), sig))
+	})
+
+	t.Run("agrees with SignHash", func(t *testing.T) {
+		valid, err := VerifyHash(sig.FileHash, sig.Signature, sig.SignerID)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("rejects a changed file", func(t *testing.T) {
+		assert.ErrorIs(t, VerifyReaderSignature(strings.NewReader("test!"), sig), ErrFileHashMismatch)
+	})
+
+	t.Run("rejects a forged signer", func(t *testing.T) {
+		other, _ := GenerateKeyPair()
+		forged := *sig
+		forged.SignerID = NormalizePublicKeyToID(other.PublicKey)
+		assert.ErrorIs(t, VerifyFileSignature(path, &forged), ErrFileSignatureInvalid)
+	})
+
+	t.Run("rejects unknown digest algorithms", func(t *testing.T) {
+		md5 := *sig
+		md5.DigestAlgorithm = "MD5"
+		assert.ErrorIs(t, VerifyFileSignature(path, &md5), ErrUnsupportedDigestAlgorithm)
+	})
+}
@@ -0,0 +1,189 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Default finality tier names (see DefaultFinalityTiers)
+const (
+	FinalityIncluded = "included"
+	FinalitySafe     = "safe"
+	FinalityFinal    = "final"
+)
+
+var (
+	// ErrInvalidFinalityTiers indicates tiers with an empty or repeated
+	// name, or a negative or repeated depth
+	ErrInvalidFinalityTiers = errors.New("invalid finality tiers")
+	// ErrUnknownFinalityTier indicates a tier name Finality was not given
+	ErrUnknownFinalityTier = errors.New("unknown finality tier")
+)
+
+// FinalityTier names a confirmation depth, e.g. "safe" at 1 snapshot
+type FinalityTier struct {
+	Name string
+	// Depth is how many global snapshots must follow the one that included
+	// the transaction
+	Depth int64
+}
+
+// DefaultFinalityTiers returns the tiers NewFinality uses when given none:
+// included in a snapshot, safe one snapshot later, final three later
+func DefaultFinalityTiers() []FinalityTier {
+	return []FinalityTier{
+		{Name: FinalityIncluded, Depth: 0},
+		{Name: FinalitySafe, Depth: 1},
+		{Name: FinalityFinal, Depth: 3},
+	}
+}
+
+// FinalityStatus is how deep a transaction is and which tiers it reached
+type FinalityStatus struct {
+	// Confirmed is false until a snapshot includes the transaction; the
+	// other fields are then zero apart from LatestOrdinal and Next
+	Confirmed bool
+	// Ordinal is the snapshot that included the transaction
+	Ordinal int64
+	// LatestOrdinal is the newest snapshot
+	LatestOrdinal int64
+	// Depth is how many snapshots followed Ordinal
+	Depth int64
+	// Tier is the deepest tier reached, or empty if none
+	Tier string
+	// Next is the next tier to reach, or empty once the deepest is reached
+	Next string
+	// Remaining is how many more snapshots Next needs after confirmation
+	Remaining int64
+}
+
+// Finality classifies transactions by snapshot depth against named tiers,
+// so code asks for "final" instead of hard-coding a depth in several places.
+// Depth counts as ConfirmationPolicy does: the snapshots following the one
+// that included the transaction. A Finality is immutable and safe for
+// concurrent use.
+//
+// Example:
+//
+//	finality, err := NewFinality(
+//	    FinalityTier{Name: "shown", Depth: 0},
+//	    FinalityTier{Name: "credited", Depth: 3},
+//	    FinalityTier{Name: "withdrawable", Depth: 10},
+//	)
+//	status := finality.Classify(tx.SnapshotOrdinal, latest.Ordinal)
+//	if ok, _ := finality.Reached("credited", tx.SnapshotOrdinal, latest.Ordinal); ok {
+//	    credit(tx)
+//	}
+type Finality struct {
+	tiers []FinalityTier
+}
+
+// NewFinality creates a Finality with tiers, in any order (default:
+// DefaultFinalityTiers)
+func NewFinality(tiers ...FinalityTier) (*Finality, error) {
+	if len(tiers) == 0 {
+		tiers = DefaultFinalityTiers()
+	}
+	sorted := append([]FinalityTier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Depth < sorted[j].Depth })
+
+	names := map[string]bool{}
+	for i, tier := range sorted {
+		switch {
+		case tier.Name == "":
+			return nil, fmt.Errorf("%w: tier with no name", ErrInvalidFinalityTiers)
+		case names[tier.Name]:
+			return nil, fmt.Errorf("%w: tier %q repeated", ErrInvalidFinalityTiers, tier.Name)
+		case tier.Depth < 0:
+			return nil, fmt.Errorf("%w: tier %q has a negative depth", ErrInvalidFinalityTiers, tier.Name)
+		case i > 0 && tier.Depth == sorted[i-1].Depth:
+			return nil, fmt.Errorf("%w: tiers %q and %q have the same depth", ErrInvalidFinalityTiers, sorted[i-1].Name, tier.Name)
+		}
+		names[tier.Name] = true
+	}
+	return &Finality{tiers: sorted}, nil
+}
+
+// Tiers returns the tiers, shallowest first
+func (f *Finality) Tiers() []FinalityTier {
+	return append([]FinalityTier(nil), f.tiers...)
+}
+
+// TierDepth returns the depth of the named tier, e.g. for a
+// ConfirmationPolicy's DefaultDepth. Returns ErrUnknownFinalityTier for a
+// name not among the tiers.
+func (f *Finality) TierDepth(name string) (int64, error) {
+	for _, tier := range f.tiers {
+		if tier.Name == name {
+			return tier.Depth, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownFinalityTier, name)
+}
+
+// Classify reports how deep a transaction included in snapshot ordinal is
+// once latestOrdinal is the newest snapshot. An ordinal of zero or less
+// means not yet included.
+func (f *Finality) Classify(ordinal int64, latestOrdinal int64) FinalityStatus {
+	status := FinalityStatus{LatestOrdinal: latestOrdinal}
+	if ordinal <= 0 {
+		if len(f.tiers) > 0 {
+			status.Next = f.tiers[0].Name
+			status.Remaining = f.tiers[0].Depth
+		}
+		return status
+	}
+	status.Confirmed = true
+	status.Ordinal = ordinal
+	if latestOrdinal > ordinal {
+		status.Depth = latestOrdinal - ordinal
+	}
+	for _, tier := range f.tiers {
+		if status.Depth >= tier.Depth {
+			status.Tier = tier.Name
+			continue
+		}
+		status.Next = tier.Name
+		status.Remaining = tier.Depth - status.Depth
+		break
+	}
+	return status
+}
+
+// Reached reports whether a transaction included in snapshot ordinal has
+// reached the named tier once latestOrdinal is the newest snapshot
+func (f *Finality) Reached(tier string, ordinal int64, latestOrdinal int64) (bool, error) {
+	depth, err := f.TierDepth(tier)
+	if err != nil {
+		return false, err
+	}
+	status := f.Classify(ordinal, latestOrdinal)
+	return status.Confirmed && status.Depth >= depth, nil
+}
+
+// Check looks up the transaction hash and the latest snapshot on explorer
+// and classifies it. The explorer must implement ExplorerTransactionLookup.
+// A transaction the explorer has not indexed is reported unconfirmed.
+func (f *Finality) Check(explorer ExplorerAPI, hash string) (FinalityStatus, error) {
+	lookup, ok := explorer.(ExplorerTransactionLookup)
+	if !ok {
+		return FinalityStatus{}, ErrTransactionLookupUnsupported
+	}
+	tx, err := lookup.GetTransaction(hash)
+	if err != nil {
+		return FinalityStatus{}, err
+	}
+	latest, err := explorer.GetLatestSnapshot()
+	if err != nil {
+		return FinalityStatus{}, err
+	}
+	var ordinal, latestOrdinal int64
+	if tx != nil {
+		ordinal = tx.SnapshotOrdinal
+	}
+	if latest != nil {
+		latestOrdinal = latest.Ordinal
+	}
+	return f.Classify(ordinal, latestOrdinal), nil
+}
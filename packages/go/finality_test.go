@@ -0,0 +1,90 @@
+package constellation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinality(t *testing.T) {
+	t.Run("classifies against the default tiers", func(t *testing.T) {
+		finality, err := NewFinality()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultFinalityTiers(), finality.Tiers())
+
+		assert.Equal(t, FinalityStatus{LatestOrdinal: 100, Next: FinalityIncluded}, finality.Classify(0, 100))
+		assert.Equal(t, FinalityStatus{
+			Confirmed: true, Ordinal: 100, LatestOrdinal: 100, Tier: FinalityIncluded, Next: FinalitySafe, Remaining: 1,
+		}, finality.Classify(100, 100))
+		assert.Equal(t, FinalityStatus{
+			Confirmed: true, Ordinal: 100, LatestOrdinal: 102, Depth: 2, Tier: FinalitySafe, Next: FinalityFinal, Remaining: 1,
+		}, finality.Classify(100, 102))
+		assert.Equal(t, FinalityStatus{
+			Confirmed: true, Ordinal: 100, LatestOrdinal: 110, Depth: 10, Tier: FinalityFinal,
+		}, finality.Classify(100, 110))
+		assert.Zero(t, finality.Classify(100, 99).Depth, "a lagging latest snapshot is not a negative depth")
+	})
+
+	t.Run("uses custom tiers in any order", func(t *testing.T) {
+		finality, err := NewFinality(
+			FinalityTier{Name: "withdrawable", Depth: 10},
+			FinalityTier{Name: "credited", Depth: 3},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "credited", finality.Tiers()[0].Name)
+
+		status := finality.Classify(50, 51)
+		assert.Empty(t, status.Tier)
+		assert.Equal(t, "credited", status.Next)
+		assert.Equal(t, int64(2), status.Remaining)
+
+		reached, err := finality.Reached("credited", 50, 53)
+		require.NoError(t, err)
+		assert.True(t, reached)
+		reached, err = finality.Reached("withdrawable", 50, 53)
+		require.NoError(t, err)
+		assert.False(t, reached)
+		reached, err = finality.Reached("credited", 0, 53)
+		require.NoError(t, err)
+		assert.False(t, reached)
+
+		_, err = finality.Reached("final", 50, 53)
+		assert.ErrorIs(t, err, ErrUnknownFinalityTier)
+		depth, err := finality.TierDepth("withdrawable")
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), depth)
+	})
+
+	t.Run("checks a transaction on the explorer", func(t *testing.T) {
+		explorer := &fakeExplorer{
+			transactions: map[string][]ExplorerTransaction{"DAG0addr": {{Hash: "tx1", SnapshotOrdinal: 10}}},
+			snapshots:    map[int64]*ExplorerSnapshot{12: {Hash: "s12", Ordinal: 12}},
+			latest:       12,
+		}
+		finality, err := NewFinality()
+		require.NoError(t, err)
+
+		status, err := finality.Check(explorer, "tx1")
+		require.NoError(t, err)
+		assert.Equal(t, FinalitySafe, status.Tier)
+		status, err = finality.Check(explorer, "unknown")
+		require.NoError(t, err)
+		assert.False(t, status.Confirmed)
+
+		_, err = finality.Check(struct{ ExplorerAPI }{explorer}, "tx1")
+		assert.ErrorIs(t, err, ErrTransactionLookupUnsupported)
+	})
+
+	t.Run("rejects invalid tiers", func(t *testing.T) {
+		for _, tiers := range [][]FinalityTier{
+			{{Depth: 1}},
+			{{Name: "a", Depth: 1}, {Name: "a", Depth: 2}},
+			{{Name: "a", Depth: -1}},
+			{{Name: "a", Depth: 1}, {Name: "b", Depth: 1}},
+		} {
+			_, err := NewFinality(tiers...)
+			assert.ErrorIs(t, err, ErrInvalidFinalityTiers)
+		}
+	})
+}
@@ -1,4 +1,4 @@
-module github.com/Constellation-Labs/metakit-sdk/packages/go
+module github.com/Constellation-Labs/metakit-sdk/packages/go/v2
 
 go 1.18
 
@@ -0,0 +1,64 @@
+package constellation
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// hashRingReplicas is the number of virtual points per node; more points
+// spread keys more evenly across nodes
+const hashRingReplicas = 64
+
+// hashRing maps keys to nodes by consistent hashing, so adding or removing
+// a node only moves the keys that node owned
+type hashRing struct {
+	points []uint64
+	owners map[uint64]string
+}
+
+func newHashRing(nodes []string) *hashRing {
+	ring := &hashRing{
+		points: make([]uint64, 0, len(nodes)*hashRingReplicas),
+		owners: make(map[uint64]string, len(nodes)*hashRingReplicas),
+	}
+	for _, node := range nodes {
+		for i := 0; i < hashRingReplicas; i++ {
+			point := hashRingPoint(node + "#" + strconv.Itoa(i))
+			if _, taken := ring.owners[point]; taken {
+				continue
+			}
+			ring.owners[point] = node
+			ring.points = append(ring.points, point)
+		}
+	}
+	sort.Slice(ring.points, func(i, j int) bool { return ring.points[i] < ring.points[j] })
+	return ring
+}
+
+// lookup returns the nodes in ring order starting from the owner of key,
+// each node once, so callers can fall back to the next one
+func (r *hashRing) lookup(key string) []string {
+	if len(r.points) == 0 {
+		return nil
+	}
+	hash := hashRingPoint(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+
+	var nodes []string
+	seen := map[string]bool{}
+	for i := 0; i < len(r.points); i++ {
+		node := r.owners[r.points[(start+i)%len(r.points)]]
+		if !seen[node] {
+			seen[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func hashRingPoint(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
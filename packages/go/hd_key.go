@@ -0,0 +1,93 @@
+package constellation
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// hardenedKeyOffset marks a hardened BIP32 child index
+const hardenedKeyOffset = 0x80000000
+
+// dagCoinType is the SLIP-44 coin type registered for Constellation
+const dagCoinType = 1137
+
+// ErrInvalidDerivationPath indicates an HD account or index out of range
+var ErrInvalidDerivationPath = errors.New("invalid derivation path")
+
+// errInvalidHDKey indicates a derivation step that produced an invalid key,
+// which BIP32 says to skip; it happens with probability below 2^-127
+var errInvalidHDKey = errors.New("derived key is invalid")
+
+// hdKey is a BIP32 extended private key
+type hdKey struct {
+	key       [32]byte
+	chainCode [32]byte
+}
+
+// dagBIP44Path is the BIP44 path m/44'/1137'/account'/0/index
+func dagBIP44Path(account uint32, index uint32) []uint32 {
+	return []uint32{44 + hardenedKeyOffset, dagCoinType + hardenedKeyOffset, account + hardenedKeyOffset, 0, index}
+}
+
+// deriveHDKey derives the key at path from a BIP32 seed
+func deriveHDKey(seed []byte, path ...uint32) (*hdKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(sum[:32]); overflow || scalar.IsZero() {
+		return nil, errInvalidHDKey
+	}
+	key := &hdKey{}
+	copy(key.key[:], sum[:32])
+	copy(key.chainCode[:], sum[32:])
+
+	for _, index := range path {
+		var err error
+		if key, err = key.child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// child derives the private child key at index
+func (k *hdKey) child(index uint32) (*hdKey, error) {
+	privateKey, _ := btcec.PrivKeyFromBytes(k.key[:])
+
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	if index >= hardenedKeyOffset {
+		mac.Write([]byte{0})
+		mac.Write(k.key[:])
+	} else {
+		mac.Write(privateKey.PubKey().SerializeCompressed())
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	mac.Write(indexBytes[:])
+	sum := mac.Sum(nil)
+
+	var tweak btcec.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return nil, errInvalidHDKey
+	}
+	tweak.Add(&privateKey.Key)
+	if tweak.IsZero() {
+		return nil, errInvalidHDKey
+	}
+
+	child := &hdKey{}
+	tweak.PutBytes(&child.key)
+	copy(child.chainCode[:], sum[32:])
+	return child, nil
+}
+
+// keyPair returns the key pair of the private key
+func (k *hdKey) keyPair() (*KeyPair, error) {
+	return KeyPairFromPrivateKeyBytes(k.key[:])
+}
@@ -0,0 +1,154 @@
+package constellation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrInvalidInvoice indicates invoice fields a wallet cannot pay
+	ErrInvalidInvoice = errors.New("invalid invoice")
+	// ErrInvoiceSignatureInvalid indicates an invoice with a missing or bad
+	// signature from its merchant
+	ErrInvoiceSignatureInvalid = errors.New("invoice is not validly signed by its merchant")
+	// ErrInvoiceUntrustedMerchant indicates an invoice from a merchant the wallet does not trust
+	ErrInvoiceUntrustedMerchant = errors.New("invoice merchant is not trusted")
+	// ErrInvoiceExpired indicates an invoice past its expiry
+	ErrInvoiceExpired = errors.New("invoice has expired")
+)
+
+// Invoice is a payment request a merchant signs and hands to a customer as
+// a Signed[Invoice]. Wallets verify it before paying, and the payment is an
+// ordinary currency transaction to Destination.
+type Invoice struct {
+	// ID is a random identifier the merchant uses to match the payment
+	ID string `json:"id"`
+	// Merchant is the DAG address that signed the invoice
+	Merchant    string `json:"merchant"`
+	Destination string `json:"destination"`
+	// Amount in smallest units (1e-8)
+	Amount    int64     `json:"amount"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TransferParams returns the transfer that pays the invoice. Its Salt is
+// derived from the invoice ID, which binds the payment to this invoice.
+func (i Invoice) TransferParams() TransferParams {
+	return TransferParams{
+		Destination: i.Destination,
+		Amount:      UnitsToToken(i.Amount),
+		ExactAmount: AmountFromUnits(i.Amount),
+		Salt:        i.salt(),
+	}
+}
+
+// PaidBy reports whether tx pays the invoice: it sends at least Amount to
+// Destination with the salt TransferParams sets. A transfer built some
+// other way does not pay the invoice, so one payment cannot settle two
+// invoices to the same destination.
+func (i Invoice) PaidBy(tx *CurrencyTransaction) bool {
+	if tx == nil || tx.Value.Destination != i.Destination || tx.Value.Amount < i.Amount {
+		return false
+	}
+	salt, err := ParseSalt(tx.Value.Salt)
+	return err == nil && salt.String() == i.salt()
+}
+
+// salt is the transaction salt that binds a payment to the invoice ID: the
+// first 8 bytes of its SHA-256 as a non-negative int64
+func (i Invoice) salt() string {
+	sum := sha256.Sum256([]byte(i.ID))
+	return strconv.FormatInt(int64(binary.BigEndian.Uint64(sum[:8])>>1), 10)
+}
+
+// CreateInvoice signs an invoice for amount to destination, valid until
+// expiry
+//
+// Example:
+//
+//	signer, _ := NewPrivateKeySigner(merchantKey)
+//	amount, _ := ParseAmount("12.5")
+//	invoice, err := CreateInvoice(amount, storeAddress, time.Now().Add(15*time.Minute), signer)
+//	if err != nil {
+//	    return err
+//	}
+//	payload, _ := json.Marshal(invoice) // hand to the customer, e.g. as a QR code
+func CreateInvoice(amount Amount, destination string, expiry time.Time, signer Signer) (*Signed[Invoice], error) {
+	units, err := amount.Units()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate invoice id: %w", err)
+	}
+
+	invoice := Invoice{
+		ID:          hex.EncodeToString(id),
+		Merchant:    GetAddress(signer.PublicKey()),
+		Destination: destination,
+		Amount:      units,
+		IssuedAt:    now,
+		ExpiresAt:   expiry.UTC().Truncate(time.Second),
+	}
+	if err := invoice.validate(); err != nil {
+		return nil, err
+	}
+	return CreateSignedObjectWithSigner(invoice, signer, false)
+}
+
+// VerifyInvoice checks that the invoice is well formed, validly signed by
+// its merchant, that the merchant is one of trustedMerchants (by DAG
+// address), and that it is not expired. An empty trustedMerchants returns
+// ErrInvoiceUntrustedMerchant: anyone can sign an invoice, so a valid
+// signature alone proves nothing.
+//
+// Example:
+//
+//	if err := VerifyInvoice(invoice, []string{merchantAddress}); err != nil {
+//	    return err
+//	}
+//	tx, err := CreateCurrencyTransaction(invoice.Value.TransferParams(), privateKey, lastRef)
+func VerifyInvoice(signed *Signed[Invoice], trustedMerchants []string) error {
+	if signed == nil {
+		return ErrInvoiceSignatureInvalid
+	}
+	if err := signed.Value.validate(); err != nil {
+		return err
+	}
+	if !Verify(signed, false).IsValid || !signedByAny(signed.Proofs, []string{signed.Value.Merchant}) {
+		return ErrInvoiceSignatureInvalid
+	}
+	if len(trustedMerchants) == 0 {
+		return fmt.Errorf("%w: no trusted merchants given", ErrInvoiceUntrustedMerchant)
+	}
+	if !signedByAny(signed.Proofs, trustedMerchants) {
+		return ErrInvoiceUntrustedMerchant
+	}
+	if !time.Now().Before(signed.Value.ExpiresAt) {
+		return fmt.Errorf("%w: expired at %s", ErrInvoiceExpired, signed.Value.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (i Invoice) validate() error {
+	switch {
+	case i.ID == "":
+		return fmt.Errorf("%w: missing id", ErrInvalidInvoice)
+	case !IsValidDAGAddress(i.Destination):
+		return fmt.Errorf("%w: %v", ErrInvalidInvoice, ErrInvalidAddress)
+	case i.Amount <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidInvoice)
+	case !i.ExpiresAt.After(i.IssuedAt):
+		return fmt.Errorf("%w: expires before it is issued", ErrInvalidInvoice)
+	}
+	return nil
+}
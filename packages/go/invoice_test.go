@@ -0,0 +1,107 @@
+package constellation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoice(t *testing.T) {
+	merchant, err := GenerateKeyPair()
+	require.NoError(t, err)
+	signer, err := NewPrivateKeySigner(merchant.PrivateKey)
+	require.NoError(t, err)
+	store, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	price, err := ParseAmount("12.5")
+	require.NoError(t, err)
+	invoice, err := CreateInvoice(price, store.Address, time.Now().Add(15*time.Minute), signer)
+	require.NoError(t, err)
+	assert.Equal(t, merchant.Address, invoice.Value.Merchant)
+	assert.Equal(t, TokenToUnits(12.5), invoice.Value.Amount)
+	assert.Len(t, invoice.Value.ID, 32)
+
+	t.Run("verifies after a JSON round trip", func(t *testing.T) {
+		data, err := json.Marshal(invoice)
+		require.NoError(t, err)
+		var received Signed[Invoice]
+		require.NoError(t, json.Unmarshal(data, &received))
+
+		assert.NoError(t, VerifyInvoice(&received, []string{merchant.Address}))
+		assert.ErrorIs(t, VerifyInvoice(&received, nil), ErrInvoiceUntrustedMerchant)
+		assert.ErrorIs(t, VerifyInvoice(&received, []string{store.Address}), ErrInvoiceUntrustedMerchant)
+	})
+
+	t.Run("builds and recognises the payment", func(t *testing.T) {
+		customer, err := GenerateKeyPair()
+		require.NoError(t, err)
+		lastRef := TransactionReference{Hash: strings.Repeat("0", 64)}
+		tx, err := CreateCurrencyTransaction(invoice.Value.TransferParams(), customer.PrivateKey, lastRef)
+		require.NoError(t, err)
+		assert.True(t, invoice.Value.PaidBy(tx))
+
+		short, err := CreateCurrencyTransaction(TransferParams{Destination: store.Address, Amount: 12}, customer.PrivateKey, lastRef)
+		require.NoError(t, err)
+		assert.False(t, invoice.Value.PaidBy(short))
+
+		// the salt binds the payment to one invoice
+		other, err := CreateInvoice(price, store.Address, time.Now().Add(15*time.Minute), signer)
+		require.NoError(t, err)
+		assert.False(t, other.Value.PaidBy(tx))
+		unbound := invoice.Value.TransferParams()
+		unbound.Salt = ""
+		random, err := CreateCurrencyTransaction(unbound, customer.PrivateKey, lastRef)
+		require.NoError(t, err)
+		assert.False(t, invoice.Value.PaidBy(random))
+	})
+
+	t.Run("pays amounts float64 cannot hold exactly", func(t *testing.T) {
+		customer, err := GenerateKeyPair()
+		require.NoError(t, err)
+		small, err := CreateInvoice(AmountFromUnits(59), store.Address, time.Now().Add(time.Minute), signer)
+		require.NoError(t, err)
+		tx, err := CreateCurrencyTransaction(small.Value.TransferParams(), customer.PrivateKey, GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, int64(59), tx.Value.Amount)
+		assert.True(t, small.Value.PaidBy(tx))
+	})
+
+	t.Run("rejects tampered, forged and expired invoices", func(t *testing.T) {
+		tampered := *invoice
+		tampered.Value.Amount = 1
+		assert.ErrorIs(t, VerifyInvoice(&tampered, []string{merchant.Address}), ErrInvoiceSignatureInvalid)
+
+		impostor, err := GenerateKeyPair()
+		require.NoError(t, err)
+		forged, err := CreateSignedObject(invoice.Value, impostor.PrivateKey, false)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifyInvoice(forged, []string{merchant.Address}), ErrInvoiceSignatureInvalid)
+
+		expired := invoice.Value
+		expired.IssuedAt = expired.IssuedAt.Add(-time.Hour)
+		expired.ExpiresAt = expired.IssuedAt.Add(time.Minute)
+		resigned, err := CreateSignedObjectWithSigner(expired, signer, false)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifyInvoice(resigned, []string{merchant.Address}), ErrInvoiceExpired)
+		assert.ErrorIs(t, VerifyInvoice(nil, nil), ErrInvoiceSignatureInvalid)
+	})
+
+	t.Run("rejects invoices a wallet cannot pay", func(t *testing.T) {
+		expiry := time.Now().Add(time.Hour)
+		_, err := CreateInvoice(Amount{}, store.Address, expiry, signer)
+		assert.ErrorIs(t, err, ErrInvalidInvoice)
+		_, err = CreateInvoice(AmountFromUnits(1), "DAGnotanaddress", expiry, signer)
+		assert.ErrorIs(t, err, ErrInvalidInvoice)
+		_, err = CreateInvoice(AmountFromUnits(1), store.Address, time.Now().Add(-time.Hour), signer)
+		assert.ErrorIs(t, err, ErrInvalidInvoice)
+		_, err = CreateInvoice(AmountFromUnits(-1), store.Address, expiry, signer)
+		assert.ErrorIs(t, err, ErrInvalidInvoice)
+		_, err = CreateInvoice(AmountFromUnits(1<<62).Mul(4), store.Address, expiry, signer)
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	})
+}
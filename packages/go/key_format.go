@@ -0,0 +1,65 @@
+package constellation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrInvalidWIF indicates a string is not a valid WIF-encoded private key
+var ErrInvalidWIF = errors.New("invalid WIF private key")
+
+// wifVersion is the version byte of a mainnet secp256k1 WIF key
+const wifVersion = 0x80
+
+// wifCompressedFlag follows the key when it was used with compressed public keys
+const wifCompressedFlag = 0x01
+
+// EncodeWIF encodes a hex private key in Wallet Import Format (base58check,
+// version 0x80). compressed appends the 0x01 suffix some wallets expect; it
+// does not change the DAG address, which always uses the uncompressed key.
+func EncodeWIF(privateKeyHex string, compressed bool) (string, error) {
+	if !IsValidPrivateKey(privateKeyHex) {
+		return "", ErrInvalidPrivateKey
+	}
+	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
+	if err != nil {
+		return "", invalidPrivateKeyHex(err)
+	}
+
+	payload := make([]byte, 0, 1+len(privateKeyBytes)+1+4)
+	payload = append(payload, wifVersion)
+	payload = append(payload, privateKeyBytes...)
+	if compressed {
+		payload = append(payload, wifCompressedFlag)
+	}
+	checksum := doubleSHA256(payload)
+	payload = append(payload, checksum[:4]...)
+	return base58Encode(payload), nil
+}
+
+// KeyPairFromWIF derives a key pair from a WIF-encoded private key
+func KeyPairFromWIF(wif string) (*KeyPair, error) {
+	decoded, ok := base58Decode(wif)
+	defer Zeroize(decoded)
+	if !ok || (len(decoded) != 37 && len(decoded) != 38) {
+		return nil, ErrInvalidWIF
+	}
+
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	expected := doubleSHA256(payload)
+	if !bytes.Equal(checksum, expected[:4]) || payload[0] != wifVersion {
+		return nil, ErrInvalidWIF
+	}
+	if len(payload) == 34 && payload[33] != wifCompressedFlag {
+		return nil, ErrInvalidWIF
+	}
+
+	return KeyPairFromPrivateKeyBytes(payload[1:33])
+}
+
+func doubleSHA256(data []byte) [sha256.Size]byte {
+	first := sha256.Sum256(data)
+	return sha256.Sum256(first[:])
+}
@@ -0,0 +1,55 @@
+package constellation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWIF(t *testing.T) {
+	// Bitcoin wiki WIF test vector
+	const privateKeyHex = "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d"
+
+	t.Run("encodes the uncompressed vector", func(t *testing.T) {
+		wif, err := EncodeWIF(privateKeyHex, false)
+		require.NoError(t, err)
+		assert.Equal(t, "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ", wif)
+	})
+
+	t.Run("encodes the compressed vector", func(t *testing.T) {
+		wif, err := EncodeWIF(privateKeyHex, true)
+		require.NoError(t, err)
+		assert.Equal(t, "KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617", wif)
+	})
+
+	t.Run("round-trips to the same address", func(t *testing.T) {
+		kp, err := GenerateKeyPair()
+		require.NoError(t, err)
+
+		for _, compressed := range []bool{false, true} {
+			wif, err := EncodeWIF(kp.PrivateKey, compressed)
+			require.NoError(t, err)
+
+			decoded, err := KeyPairFromWIF(wif)
+			require.NoError(t, err)
+			assert.Equal(t, kp.PrivateKey, privateKeyHexOf(t, decoded))
+			assert.Equal(t, kp.Address, decoded.Address)
+		}
+	})
+
+	t.Run("rejects a bad checksum", func(t *testing.T) {
+		_, err := KeyPairFromWIF("5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTK")
+		assert.ErrorIs(t, err, ErrInvalidWIF)
+	})
+
+	t.Run("rejects non-base58 input", func(t *testing.T) {
+		_, err := KeyPairFromWIF(privateKeyHex)
+		assert.ErrorIs(t, err, ErrInvalidWIF)
+	})
+
+	t.Run("rejects an invalid private key", func(t *testing.T) {
+		_, err := EncodeWIF("xyz", false)
+		assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+	})
+}
@@ -0,0 +1,246 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+const defaultKeyringAutoLock = 5 * time.Minute
+
+var (
+	// ErrKeyringLocked indicates signing with a Keyring that is locked, or
+	// a key added since it was last unlocked
+	ErrKeyringLocked = errors.New("keyring is locked")
+	// ErrKeyNotInKeyring indicates an address the Keyring holds no key for
+	ErrKeyNotInKeyring = errors.New("address is not in the keyring")
+)
+
+// KeyringConfig holds configuration for a Keyring
+type KeyringConfig struct {
+	// AutoLock is how long the keyring stays unlocked without signing
+	// anything before it locks itself (default: 5 minutes)
+	AutoLock time.Duration
+}
+
+// Keyring holds encrypted keystores for long-running services. Keys are
+// decrypted only between Unlock and Lock, held as bytes that locking wipes,
+// and the keyring locks itself after AutoLock without a signature, so a
+// service left idle does not keep plaintext keys resident. Signer returns a
+// Signer per address that works whenever the keyring is unlocked. It is safe
+// for concurrent use.
+//
+// Example:
+//
+//	keyring := NewKeyring(KeyringConfig{AutoLock: 10 * time.Minute})
+//	address, err := keyring.AddKeyStore(keyStoreJSON) // from EncryptKeyPair
+//	err = keyring.Unlock(password)
+//	signer, err := keyring.Signer(address)
+//	signed, err := SignTransaction(tx, signer)
+//	keyring.Lock()
+type Keyring struct {
+	config KeyringConfig
+
+	mu         sync.Mutex
+	keyStores  map[string][]byte
+	publicKeys map[string]string
+	// secrets holds the decrypted keys; nil while locked
+	secrets map[string]*keySecret
+	timer   *time.Timer
+}
+
+// NewKeyring creates an empty, locked keyring
+func NewKeyring(config KeyringConfig) *Keyring {
+	if config.AutoLock <= 0 {
+		config.AutoLock = defaultKeyringAutoLock
+	}
+	return &Keyring{
+		config:     config,
+		keyStores:  map[string][]byte{},
+		publicKeys: map[string]string{},
+	}
+}
+
+// AddKeyStore adds a V3 JSON keystore written by EncryptKeyPair, returning
+// its DAG address. The keystore must carry the address in the clear and be
+// encrypted with the keyring's password; a key added while the keyring is
+// unlocked can sign after the next Unlock.
+func (k *Keyring) AddKeyStore(keyStore []byte) (string, error) {
+	var header struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyStore, &header); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKeyStore, err)
+	}
+	if !IsValidDAGAddress(header.Address) {
+		return "", fmt.Errorf("%w: no DAG address", ErrInvalidKeyStore)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keyStores[header.Address] = append([]byte(nil), keyStore...)
+	return header.Address, nil
+}
+
+// Addresses returns the addresses of the keyring's keys, sorted
+func (k *Keyring) Addresses() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	addresses := make([]string, 0, len(k.keyStores))
+	for address := range k.keyStores {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// Unlock decrypts every key with password. Each keystore pays its scrypt
+// cost, about a second with StandardScryptParams. It is all or nothing: if
+// any key fails to decrypt, returning ErrKeyStorePassword for a wrong
+// password, the keyring stays locked. Unlocking an unlocked keyring decrypts
+// again, picking up keys added since.
+func (k *Keyring) Unlock(password string) error {
+	k.mu.Lock()
+	keyStores := make(map[string][]byte, len(k.keyStores))
+	for address, keyStore := range k.keyStores {
+		keyStores[address] = keyStore
+	}
+	k.mu.Unlock()
+
+	// decrypt without holding the lock, so signers fail fast meanwhile
+	secrets := make(map[string]*keySecret, len(keyStores))
+	publicKeys := make(map[string]string, len(keyStores))
+	for address, keyStore := range keyStores {
+		secret, publicKey, err := decryptKeyStoreSecret(keyStore, password)
+		if err != nil {
+			destroyKeySecrets(secrets)
+			return fmt.Errorf("unlocking %s: %w", RedactAddress(address), err)
+		}
+		secrets[address] = secret
+		publicKeys[address] = publicKey
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	destroyKeySecrets(k.secrets)
+	k.secrets = secrets
+	for address, publicKey := range publicKeys {
+		k.publicKeys[address] = publicKey
+	}
+	if k.timer == nil {
+		k.timer = time.AfterFunc(k.config.AutoLock, k.Lock)
+	} else {
+		k.timer.Reset(k.config.AutoLock)
+	}
+	return nil
+}
+
+// Lock wipes the decrypted keys; signing then returns ErrKeyringLocked until
+// the next Unlock
+func (k *Keyring) Lock() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	destroyKeySecrets(k.secrets)
+	k.secrets = nil
+	if k.timer != nil {
+		k.timer.Stop()
+	}
+}
+
+// Locked reports whether the keyring is locked
+func (k *Keyring) Locked() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.secrets == nil
+}
+
+// Signer returns the signer for address. Its public key is learned when the
+// key is first decrypted, so the keyring must have been unlocked since the
+// key was added; returns ErrKeyringLocked otherwise and ErrKeyNotInKeyring
+// for an unknown address. The signer stays valid across locks.
+func (k *Keyring) Signer(address string) (*KeyringSigner, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keyStores[address]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotInKeyring, RedactAddress(address))
+	}
+	publicKey, ok := k.publicKeys[address]
+	if !ok {
+		return nil, ErrKeyringLocked
+	}
+	return &KeyringSigner{keyring: k, address: address, publicKey: publicKey}, nil
+}
+
+// secret returns the decrypted key of address and postpones the auto-lock
+func (k *Keyring) secret(address string) (*keySecret, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	secret, ok := k.secrets[address]
+	if !ok {
+		return nil, ErrKeyringLocked
+	}
+	k.timer.Reset(k.config.AutoLock)
+	return secret, nil
+}
+
+// KeyringSigner is the Signer for one key of a Keyring
+type KeyringSigner struct {
+	keyring   *Keyring
+	address   string
+	publicKey string
+}
+
+// PublicKey returns the uncompressed public key hex (with 04 prefix)
+func (s *KeyringSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// Address returns the DAG address of the key
+func (s *KeyringSigner) Address() string {
+	return s.address
+}
+
+// SignHash signs a SHA-256 hash using the Constellation signing protocol.
+// Returns ErrKeyringLocked while the keyring is locked.
+func (s *KeyringSigner) SignHash(hashHex string) (string, error) {
+	signature, err := s.SignDigest(ComputeDigestFromHash(hashHex))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+// SignDigest signs a 32-byte signing digest and returns the DER signature,
+// making KeyringSigner a DigestSigner as well
+func (s *KeyringSigner) SignDigest(digest []byte) ([]byte, error) {
+	if err := CheckSigningAllowed(); err != nil {
+		return nil, err
+	}
+	secret, err := s.keyring.secret(s.address)
+	if err != nil {
+		return nil, err
+	}
+	var signature []byte
+	err = secret.use(func(privateKey *btcec.PrivateKey) error {
+		signature = ecdsa.Sign(privateKey, digest).Serialize()
+		return nil
+	})
+	if errors.Is(err, ErrKeyDestroyed) {
+		// locked while signing
+		return nil, ErrKeyringLocked
+	}
+	return signature, err
+}
+
+func destroyKeySecrets(secrets map[string]*keySecret) {
+	for _, secret := range secrets {
+		secret.destroy()
+	}
+}
@@ -0,0 +1,114 @@
+package constellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ DigestSigner = (*KeyringSigner)(nil)
+
+func TestKeyring(t *testing.T) {
+	const password = "correct horse"
+	newKeyStore := func(t *testing.T) (*KeyPair, []byte) {
+		keyPair, err := GenerateKeyPair()
+		require.NoError(t, err)
+		keyStore, err := EncryptKeyPairWithParams(keyPair, password, LightScryptParams)
+		require.NoError(t, err)
+		return keyPair, keyStore
+	}
+	recipient, err := GenerateKeyPair()
+	require.NoError(t, err)
+	params := TransferParams{Destination: recipient.Address, Amount: 1, Fee: 0}
+
+	t.Run("signs only while unlocked", func(t *testing.T) {
+		keyPair, keyStore := newKeyStore(t)
+		keyring := NewKeyring(KeyringConfig{})
+		address, err := keyring.AddKeyStore(keyStore)
+		require.NoError(t, err)
+		assert.Equal(t, keyPair.Address, address)
+		assert.True(t, keyring.Locked())
+
+		_, err = keyring.Signer(address)
+		assert.ErrorIs(t, err, ErrKeyringLocked)
+		_, err = keyring.Signer(recipient.Address)
+		assert.ErrorIs(t, err, ErrKeyNotInKeyring)
+
+		assert.ErrorIs(t, keyring.Unlock("wrong"), ErrKeyStorePassword)
+		assert.True(t, keyring.Locked())
+
+		require.NoError(t, keyring.Unlock(password))
+		assert.False(t, keyring.Locked())
+		signer, err := keyring.Signer(address)
+		require.NoError(t, err)
+		assert.Equal(t, keyPair.PublicKey, signer.PublicKey())
+		assert.Equal(t, address, signer.Address())
+
+		tx, err := CreateCurrencyTransactionWithSigner(params, signer, GenesisReference)
+		require.NoError(t, err)
+		assert.True(t, VerifyCurrencyTransactionStrict(tx))
+
+		keyring.Lock()
+		assert.True(t, keyring.Locked())
+		_, err = CreateCurrencyTransactionWithSigner(params, signer, GenesisReference)
+		assert.ErrorIs(t, err, ErrKeyringLocked)
+
+		// the signer works again after the next unlock
+		require.NoError(t, keyring.Unlock(password))
+		_, err = signer.SignHash(tx.Value.Parent.Hash)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unlocks all keys or none", func(t *testing.T) {
+		_, first := newKeyStore(t)
+		keyring := NewKeyring(KeyringConfig{})
+		firstAddress, err := keyring.AddKeyStore(first)
+		require.NoError(t, err)
+		require.NoError(t, keyring.Unlock(password))
+
+		// keys added while unlocked sign after the next unlock
+		_, second := newKeyStore(t)
+		secondAddress, err := keyring.AddKeyStore(second)
+		require.NoError(t, err)
+		_, err = keyring.Signer(secondAddress)
+		assert.ErrorIs(t, err, ErrKeyringLocked)
+		require.NoError(t, keyring.Unlock(password))
+		_, err = keyring.Signer(secondAddress)
+		require.NoError(t, err)
+
+		other, _ := GenerateKeyPair()
+		third, err := EncryptKeyPairWithParams(other, "another password", LightScryptParams)
+		require.NoError(t, err)
+		_, err = keyring.AddKeyStore(third)
+		require.NoError(t, err)
+		keyring.Lock()
+		assert.ErrorIs(t, keyring.Unlock(password), ErrKeyStorePassword)
+		assert.True(t, keyring.Locked())
+		assert.Len(t, keyring.Addresses(), 3)
+		assert.Contains(t, keyring.Addresses(), firstAddress)
+	})
+
+	t.Run("locks after the idle timeout", func(t *testing.T) {
+		_, keyStore := newKeyStore(t)
+		keyring := NewKeyring(KeyringConfig{AutoLock: 50 * time.Millisecond})
+		address, err := keyring.AddKeyStore(keyStore)
+		require.NoError(t, err)
+		require.NoError(t, keyring.Unlock(password))
+		signer, err := keyring.Signer(address)
+		require.NoError(t, err)
+
+		assert.Eventually(t, keyring.Locked, time.Second, 10*time.Millisecond)
+		_, err = CreateCurrencyTransactionWithSigner(params, signer, GenesisReference)
+		assert.ErrorIs(t, err, ErrKeyringLocked)
+	})
+
+	t.Run("rejects keystores without an address", func(t *testing.T) {
+		keyring := NewKeyring(KeyringConfig{})
+		_, err := keyring.AddKeyStore([]byte(`{"version":3}`))
+		assert.ErrorIs(t, err, ErrInvalidKeyStore)
+		_, err = keyring.AddKeyStore([]byte(`not json`))
+		assert.ErrorIs(t, err, ErrInvalidKeyStore)
+	})
+}
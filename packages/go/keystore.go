@@ -0,0 +1,290 @@
+package constellation
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidKeyStore indicates data that is not a readable V3 keystore
+	ErrInvalidKeyStore = errors.New("invalid keystore")
+	// ErrKeyStorePassword indicates the keystore password is wrong
+	ErrKeyStorePassword = errors.New("incorrect keystore password")
+	// ErrKeyStoreUnsupported indicates a cipher, KDF or cost the SDK does not
+	// accept
+	ErrKeyStoreUnsupported = errors.New("unsupported keystore")
+)
+
+// ScryptParams are the scrypt costs of an encrypted keystore
+type ScryptParams struct {
+	N int
+	R int
+	P int
+}
+
+var (
+	// StandardScryptParams take about a second and 256 MiB to unlock, the
+	// usual V3 keystore cost
+	StandardScryptParams = ScryptParams{N: 1 << 18, R: 8, P: 1}
+	// LightScryptParams are for devices that cannot spare the memory
+	LightScryptParams = ScryptParams{N: 1 << 12, R: 8, P: 6}
+)
+
+// keyStoreMaxMemory caps the scrypt cost, 128*N*r*p bytes mixed, that
+// DecryptKeyStore and Restore will spend on an untrusted file. Every p
+// lane reads the N*r blocks again, so p multiplies the work.
+const keyStoreMaxMemory = 1 << 30
+
+// keyStoreMaxIterations caps the PBKDF2 iterations of an untrusted file,
+// well above the 262144 Ethereum wallets use
+const keyStoreMaxIterations = 1 << 24
+
+const (
+	keyStoreVersion = 3
+	keyStoreCipher  = "aes-128-ctr"
+	keyStoreKeySize = 32
+)
+
+type keyStoreJSON struct {
+	Version int            `json:"version"`
+	ID      string         `json:"id"`
+	Address string         `json:"address,omitempty"`
+	Crypto  keyStoreCrypto `json:"crypto"`
+}
+
+type keyStoreCrypto struct {
+	Cipher       string               `json:"cipher"`
+	CipherText   string               `json:"ciphertext"`
+	CipherParams keyStoreCipherParams `json:"cipherparams"`
+	KDF          string               `json:"kdf"`
+	KDFParams    keyStoreKDFParams    `json:"kdfparams"`
+	MAC          string               `json:"mac"`
+}
+
+type keyStoreCipherParams struct {
+	IV string `json:"iv"`
+}
+
+// keyStoreKDFParams holds the parameters of both KDFs: n, r and p for
+// scrypt, c and prf for pbkdf2
+type keyStoreKDFParams struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n,omitempty"`
+	R     int    `json:"r,omitempty"`
+	P     int    `json:"p,omitempty"`
+	C     int    `json:"c,omitempty"`
+	PRF   string `json:"prf,omitempty"`
+	Salt  string `json:"salt"`
+}
+
+// EncryptKeyPair encrypts keyPair with password as a V3 (Web3 Secret
+// Storage) JSON keystore: scrypt with StandardScryptParams, AES-128-CTR and
+// a Keccak-256 MAC. The DAG address is stored in the clear so the file can
+// be identified without the password.
+//
+// Example:
+//
+//	keyStore, err := constellation.EncryptKeyPair(keyPair, password)
+//	os.WriteFile("wallet.json", keyStore, 0o600)
+//
+//	keyPair, err := constellation.DecryptKeyStore(keyStore, password)
+func EncryptKeyPair(keyPair *KeyPair, password string) ([]byte, error) {
+	return EncryptKeyPairWithParams(keyPair, password, StandardScryptParams)
+}
+
+// EncryptKeyPairWithParams is EncryptKeyPair with explicit scrypt costs
+func EncryptKeyPairWithParams(keyPair *KeyPair, password string, params ScryptParams) ([]byte, error) {
+	// refuse costs DecryptKeyStore would not read back
+	kdfParams := keyStoreKDFParams{DKLen: keyStoreKeySize, N: params.N, R: params.R, P: params.P}
+	if err := checkKDFParams("scrypt", kdfParams, ErrKeyStoreUnsupported, ErrKeyStoreUnsupported); err != nil {
+		return nil, err
+	}
+	privateKey, err := keyPairScalar(keyPair)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(privateKey)
+	// derive the address again rather than trusting keyPair.Address
+	derived, err := KeyPairFromPrivateKeyBytes(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	defer derived.Destroy()
+
+	random := make([]byte, 32+aes.BlockSize+16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, iv, id := random[:32], random[32:32+aes.BlockSize], random[32+aes.BlockSize:]
+
+	key, err := scryptKey([]byte(password), salt, params.N, params.R, params.P, keyStoreKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyStoreUnsupported, err)
+	}
+	ciphertext, err := keyStoreCTR(key[:16], iv, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// random UUID, version 4
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return json.Marshal(keyStoreJSON{
+		Version: keyStoreVersion,
+		ID:      fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Address: derived.Address,
+		Crypto: keyStoreCrypto{
+			Cipher:       keyStoreCipher,
+			CipherText:   hex.EncodeToString(ciphertext),
+			CipherParams: keyStoreCipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: keyStoreKDFParams{
+				DKLen: keyStoreKeySize,
+				N:     params.N,
+				R:     params.R,
+				P:     params.P,
+				Salt:  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(keccak256(key[16:32], ciphertext)),
+		},
+	})
+}
+
+// DecryptKeyStore decrypts a V3 JSON keystore with password. Keystores
+// using scrypt or PBKDF2-HMAC-SHA256 are accepted, including ones written
+// by Ethereum wallets. Returns ErrKeyStorePassword when the MAC does not
+// match, and ErrInvalidKeyStore when the stored DAG address does not match
+// the decrypted key.
+func DecryptKeyStore(data []byte, password string) (*KeyPair, error) {
+	secret, publicKey, err := decryptKeyStoreSecret(data, password)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{PublicKey: publicKey, Address: GetAddress(publicKey), secret: secret}, nil
+}
+
+// decryptKeyStoreSecret decrypts a keystore straight into a keySecret,
+// wiping the decrypted bytes, and returns the secret's public key
+func decryptKeyStoreSecret(data []byte, password string) (*keySecret, string, error) {
+	var keyStore keyStoreJSON
+	if err := json.Unmarshal(data, &keyStore); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidKeyStore, err)
+	}
+	if keyStore.Version != keyStoreVersion {
+		return nil, "", fmt.Errorf("%w: version %d", ErrKeyStoreUnsupported, keyStore.Version)
+	}
+	crypto := keyStore.Crypto
+	if crypto.Cipher != keyStoreCipher {
+		return nil, "", fmt.Errorf("%w: cipher %q", ErrKeyStoreUnsupported, crypto.Cipher)
+	}
+
+	ciphertext, err1 := hex.DecodeString(crypto.CipherText)
+	iv, err2 := hex.DecodeString(crypto.CipherParams.IV)
+	mac, err3 := hex.DecodeString(crypto.MAC)
+	salt, err4 := hex.DecodeString(crypto.KDFParams.Salt)
+	for _, err := range []error{err1, err2, err3, err4} {
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidKeyStore, err)
+		}
+	}
+	if len(ciphertext) != keyStoreKeySize || len(iv) != aes.BlockSize {
+		return nil, "", fmt.Errorf("%w: malformed ciphertext", ErrInvalidKeyStore)
+	}
+
+	key, err := keyStoreKey(crypto.KDF, crypto.KDFParams, []byte(password), salt)
+	if err != nil {
+		return nil, "", err
+	}
+	defer Zeroize(key)
+	if subtle.ConstantTimeCompare(keccak256(key[16:32], ciphertext), mac) != 1 {
+		return nil, "", ErrKeyStorePassword
+	}
+
+	privateKey, err := keyStoreCTR(key[:16], iv, ciphertext)
+	if err != nil {
+		return nil, "", err
+	}
+	defer Zeroize(privateKey)
+	secret, err := newKeySecret(privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	publicKey, err := secret.publicKey()
+	if err != nil {
+		return nil, "", err
+	}
+	// Ethereum keystores carry a hex address, which is not checked
+	if strings.HasPrefix(keyStore.Address, "DAG") && keyStore.Address != GetAddress(publicKey) {
+		secret.destroy()
+		return nil, "", fmt.Errorf("%w: key does not match address %s", ErrInvalidKeyStore, RedactAddress(keyStore.Address))
+	}
+	return secret, publicKey, nil
+}
+
+// keyStoreKey derives the keystore's encryption and MAC key
+func keyStoreKey(kdf string, params keyStoreKDFParams, password []byte, salt []byte) ([]byte, error) {
+	if err := checkKDFParams(kdf, params, ErrInvalidKeyStore, ErrKeyStoreUnsupported); err != nil {
+		return nil, err
+	}
+	if kdf == "pbkdf2" {
+		return pbkdf2Key(sha256.New, password, salt, params.C, params.DKLen), nil
+	}
+	key, err := scryptKey(password, salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeyStore, err)
+	}
+	return key, nil
+}
+
+// checkKDFParams checks key derivation parameters read from an untrusted
+// keystore or backup before any work is done: a 32-byte key, and a cost
+// within keyStoreMaxMemory or keyStoreMaxIterations. Malformed parameters
+// return invalid, and supported but too costly ones return unsupported.
+func checkKDFParams(kdf string, params keyStoreKDFParams, invalid error, unsupported error) error {
+	if params.DKLen != keyStoreKeySize {
+		return fmt.Errorf("%w: dklen %d", invalid, params.DKLen)
+	}
+	switch kdf {
+	case "scrypt":
+		if params.N <= 1 || params.R < 1 || params.P < 1 {
+			return fmt.Errorf("%w: scrypt n %d, r %d, p %d", invalid, params.N, params.R, params.P)
+		}
+		// divide rather than multiply, so huge parameters cannot overflow
+		limit := uint64(keyStoreMaxMemory) / 128 / uint64(params.N)
+		if uint64(params.R) > limit || uint64(params.P) > limit || uint64(params.R)*uint64(params.P) > limit {
+			return fmt.Errorf("%w: scrypt costs more than %d MiB", unsupported, keyStoreMaxMemory>>20)
+		}
+		return nil
+	case "pbkdf2":
+		if params.PRF != "hmac-sha256" {
+			return fmt.Errorf("%w: pbkdf2 prf %q", unsupported, params.PRF)
+		}
+		if params.C < 1 {
+			return fmt.Errorf("%w: pbkdf2 iterations %d", invalid, params.C)
+		}
+		if params.C > keyStoreMaxIterations {
+			return fmt.Errorf("%w: pbkdf2 iterations %d above %d", unsupported, params.C, keyStoreMaxIterations)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: kdf %q", unsupported, kdf)
+}
+
+func keyStoreCTR(key []byte, iv []byte, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(out, data)
+	return out, nil
+}
@@ -0,0 +1,128 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the PBKDF2 test vector from the Web3 Secret Storage definition
+const web3PBKDF2KeyStore = `{
+	"crypto": {
+		"cipher": "aes-128-ctr",
+		"cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+		"ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+		"kdf": "pbkdf2",
+		"kdfparams": {
+			"c": 262144,
+			"dklen": 32,
+			"prf": "hmac-sha256",
+			"salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"
+		},
+		"mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+	},
+	"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+	"version": 3
+}`
+
+func TestKeyStore(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		data, err := EncryptKeyPairWithParams(keyPair, "correct horse", LightScryptParams)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), keyPair.PrivateKey)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		assert.Equal(t, keyPair.Address, fields["address"])
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, fields["id"])
+
+		decrypted, err := DecryptKeyStore(data, "correct horse")
+		require.NoError(t, err)
+		assert.Equal(t, keyPair.Address, decrypted.Address)
+		assert.Equal(t, keyPair.PrivateKey, privateKeyHexOf(t, decrypted))
+
+		_, err = DecryptKeyStore(data, "wrong")
+		assert.ErrorIs(t, err, ErrKeyStorePassword)
+	})
+
+	t.Run("web3 test vector", func(t *testing.T) {
+		decrypted, err := DecryptKeyStore([]byte(web3PBKDF2KeyStore), "testpassword")
+		require.NoError(t, err)
+		assert.Equal(t, "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d", privateKeyHexOf(t, decrypted))
+		assert.Empty(t, decrypted.PrivateKey, "decrypted without a hex copy")
+	})
+
+	t.Run("address mismatch", func(t *testing.T) {
+		other, err := GenerateKeyPair()
+		require.NoError(t, err)
+		data, err := EncryptKeyPairWithParams(keyPair, "pw", LightScryptParams)
+		require.NoError(t, err)
+
+		var keyStore map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &keyStore))
+		keyStore["address"] = other.Address
+		tampered, err := json.Marshal(keyStore)
+		require.NoError(t, err)
+
+		_, err = DecryptKeyStore(tampered, "pw")
+		assert.ErrorIs(t, err, ErrInvalidKeyStore)
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		_, err := DecryptKeyStore([]byte(`{"version": 1}`), "pw")
+		assert.ErrorIs(t, err, ErrKeyStoreUnsupported)
+
+		huge := `{"version": 3, "crypto": {"cipher": "aes-128-ctr",
+			"cipherparams": {"iv": "00000000000000000000000000000000"},
+			"ciphertext": "0000000000000000000000000000000000000000000000000000000000000000",
+			"kdf": "scrypt", "kdfparams": {"dklen": 32, "n": 16777216, "r": 8, "p": 1, "salt": "00"},
+			"mac": "00"}}`
+		_, err = DecryptKeyStore([]byte(huge), "pw")
+		assert.ErrorIs(t, err, ErrKeyStoreUnsupported)
+
+		for _, kdf := range []string{
+			`"scrypt", "kdfparams": {"dklen": 32, "n": 262144, "r": 8, "p": 64, "salt": "00"}`,
+			`"scrypt", "kdfparams": {"dklen": 32, "n": 2, "r": 8, "p": 9223372036854775807, "salt": "00"}`,
+			`"pbkdf2", "kdfparams": {"dklen": 32, "c": 2000000000, "prf": "hmac-sha256", "salt": "00"}`,
+		} {
+			costly := strings.Replace(huge, `"scrypt", "kdfparams": {"dklen": 32, "n": 16777216, "r": 8, "p": 1, "salt": "00"}`, kdf, 1)
+			_, err = DecryptKeyStore([]byte(costly), "pw")
+			assert.ErrorIs(t, err, ErrKeyStoreUnsupported, kdf)
+		}
+
+		for _, dklen := range []string{"31", "64", "1000000000"} {
+			long := strings.Replace(huge, `"dklen": 32, "n": 16777216`, `"dklen": `+dklen+`, "n": 1024`, 1)
+			_, err = DecryptKeyStore([]byte(long), "pw")
+			assert.ErrorIs(t, err, ErrInvalidKeyStore, dklen)
+		}
+
+		_, err = EncryptKeyPairWithParams(keyPair, "pw", ScryptParams{N: 1 << 18, R: 8, P: 64})
+		assert.ErrorIs(t, err, ErrKeyStoreUnsupported)
+
+		_, err = DecryptKeyStore([]byte("not json"), "pw")
+		assert.ErrorIs(t, err, ErrInvalidKeyStore)
+
+		_, err = EncryptKeyPair(&KeyPair{PrivateKey: "zz"}, "pw")
+		assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+	})
+}
+
+func TestKeyStorePrimitives(t *testing.T) {
+	// RFC 7914 section 12
+	key, err := scryptKey([]byte("password"), []byte("NaCl"), 1024, 8, 16, 64)
+	require.NoError(t, err)
+	assert.Equal(t, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640", hex.EncodeToString(key))
+
+	_, err = scryptKey([]byte("password"), nil, 1000, 8, 1, 32)
+	assert.Error(t, err, "N must be a power of two")
+
+	assert.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(keccak256(nil)))
+	assert.Equal(t, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45", hex.EncodeToString(keccak256([]byte("abc"))))
+}
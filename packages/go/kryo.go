@@ -0,0 +1,132 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxKryoStringLength is the longest string, in bytes, Kryo can serialize:
+// its length header holds the length plus one in a Java int. Currency
+// transactions encode to a few hundred bytes; only hand-built values with
+// huge fields come near it.
+const MaxKryoStringLength = math.MaxInt32 - 1
+
+var (
+	// ErrKryoPayloadTooLarge indicates an encoding longer than
+	// MaxKryoStringLength
+	ErrKryoPayloadTooLarge = errors.New("payload too large for Kryo serialization")
+
+	// errInvalidKryo indicates bytes that are not a single Kryo-serialized
+	// string
+	errInvalidKryo = errors.New("invalid Kryo string")
+)
+
+// kryoStringType is the Kryo class ID of java.lang.String
+const kryoStringType = 0x03
+
+// kryoSerialize performs Kryo serialization for transaction encoding
+// Matches txEncode.kryoSerialize() from dag4.js
+func kryoSerialize(msg string, setReferences bool) []byte {
+	return appendKryoSerialized(nil, msg, setReferences)
+}
+
+// appendKryoSerialized appends the Kryo serialization of msg to dst. The
+// length counts bytes while dag4.js counts UTF-16 code units; encoded
+// transactions are ASCII, where the two agree. A msg longer than
+// MaxKryoStringLength gets a header no Kryo reader accepts but is still
+// hashed deterministically; signing and verification reject it first (see
+// checkKryoLength).
+func appendKryoSerialized(dst []byte, msg string, setReferences bool) []byte {
+	dst = append(dst, kryoStringType)
+	if setReferences {
+		dst = append(dst, 0x01)
+	}
+	dst = appendKryoLength(dst, len(msg)+1)
+	return append(dst, msg...)
+}
+
+// appendKryoLength appends value as Kryo's Output.writeUtf8Length does: 6
+// bits in the first byte, whose 0x80 bit marks a length rather than ASCII
+// text and whose 0x40 bit marks a following byte, then 7 bits per byte with
+// 0x80 marking a following byte, in at most 5 bytes. Values up to
+// math.MaxInt32 round-trip; the fifth byte carries bits 27 to 30.
+func appendKryoLength(dst []byte, value int) []byte {
+	switch {
+	case value>>6 == 0:
+		return append(dst, byte(value|0x80))
+	case value>>13 == 0:
+		return append(dst, byte(value|0x40|0x80), byte(value>>6))
+	case value>>20 == 0:
+		return append(dst, byte(value|0x40|0x80), byte((value>>6)|0x80), byte(value>>13))
+	case value>>27 == 0:
+		return append(dst, byte(value|0x40|0x80), byte((value>>6)|0x80), byte((value>>13)|0x80), byte(value>>20))
+	default:
+		return append(dst, byte(value|0x40|0x80), byte((value>>6)|0x80), byte((value>>13)|0x80), byte((value>>20)|0x80), byte(value>>27))
+	}
+}
+
+// readKryoLength decodes a length written by appendKryoLength, returning
+// it and how many bytes it took. Values that overflow a Java int, which
+// Kryo would read as negative, are rejected.
+func readKryoLength(data []byte) (value int, n int, err error) {
+	if len(data) == 0 {
+		return 0, 0, fmt.Errorf("%w: missing length", errInvalidKryo)
+	}
+	b := data[0]
+	if b&0x80 == 0 {
+		return 0, 0, fmt.Errorf("%w: ASCII-encoded strings are not supported", errInvalidKryo)
+	}
+	decoded := uint64(b & 0x3F)
+	more := b&0x40 != 0
+	for n = 1; more; n++ {
+		if n == len(data) {
+			return 0, 0, fmt.Errorf("%w: truncated length", errInvalidKryo)
+		}
+		b = data[n]
+		decoded |= uint64(b&0x7F) << (6 + 7*(n-1))
+		// the fifth byte is the last whatever its 0x80 bit
+		more = b&0x80 != 0 && n < 4
+	}
+	if decoded > math.MaxInt32 {
+		return 0, 0, fmt.Errorf("%w: length header %d overflows", errInvalidKryo, decoded)
+	}
+	return int(decoded), n, nil
+}
+
+// checkKryoLength returns ErrKryoPayloadTooLarge for an encoding of length
+// bytes that Kryo cannot serialize
+func checkKryoLength(length int) error {
+	if length > MaxKryoStringLength {
+		return fmt.Errorf("%w: %d bytes, at most %d", ErrKryoPayloadTooLarge, length, MaxKryoStringLength)
+	}
+	return nil
+}
+
+// kryoDeserialize decodes bytes written by kryoSerialize with the same
+// setReferences, requiring the string to fill data exactly
+func kryoDeserialize(data []byte, setReferences bool) (string, error) {
+	if len(data) == 0 || data[0] != kryoStringType {
+		return "", fmt.Errorf("%w: not a string", errInvalidKryo)
+	}
+	data = data[1:]
+	if setReferences {
+		if len(data) == 0 || data[0] != 0x01 {
+			return "", fmt.Errorf("%w: missing reference flag", errInvalidKryo)
+		}
+		data = data[1:]
+	}
+
+	value, n, err := readKryoLength(data)
+	if err != nil {
+		return "", err
+	}
+	if value == 0 {
+		return "", fmt.Errorf("%w: null string", errInvalidKryo)
+	}
+	data = data[n:]
+	if len(data) != value-1 {
+		return "", fmt.Errorf("%w: length %d but %d bytes follow", errInvalidKryo, value-1, len(data))
+	}
+	return string(data), nil
+}
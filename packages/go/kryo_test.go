@@ -0,0 +1,145 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var kryoVectorsPath = filepath.Join("..", "..", "shared", "kryo_vectors.json")
+
+type kryoVectors struct {
+	Entries []struct {
+		Name          string `json:"name"`
+		SetReferences bool   `json:"setReferences"`
+		Payload       struct {
+			Repeat string `json:"repeat"`
+			Count  int    `json:"count"`
+			Suffix string `json:"suffix"`
+		} `json:"payload"`
+		Length    int    `json:"length"`
+		HeaderHex string `json:"headerHex"`
+		BytesHex  string `json:"bytesHex"`
+		SHA256    string `json:"sha256"`
+	} `json:"entries"`
+}
+
+func TestKryoCorpus(t *testing.T) {
+	content, err := os.ReadFile(kryoVectorsPath)
+	require.NoError(t, err)
+	var vectors kryoVectors
+	require.NoError(t, json.Unmarshal(content, &vectors))
+	require.NotEmpty(t, vectors.Entries)
+
+	for _, entry := range vectors.Entries {
+		entry := entry
+		t.Run(entry.Name, func(t *testing.T) {
+			payload := strings.Repeat(entry.Payload.Repeat, entry.Payload.Count) + entry.Payload.Suffix
+			require.Len(t, payload, entry.Length)
+
+			serialized := kryoSerialize(payload, entry.SetReferences)
+			assert.Equal(t, entry.HeaderHex, hex.EncodeToString(serialized[:len(serialized)-len(payload)]))
+			if entry.BytesHex != "" {
+				assert.Equal(t, entry.BytesHex, hex.EncodeToString(serialized))
+			}
+			assert.Equal(t, entry.SHA256, HashBytes(serialized).Value)
+
+			decoded, err := kryoDeserialize(serialized, entry.SetReferences)
+			require.NoError(t, err)
+			assert.True(t, decoded == payload, "round trip changed the payload")
+
+			_, err = kryoDeserialize(serialized, !entry.SetReferences)
+			assert.ErrorIs(t, err, errInvalidKryo, "wrong reference flag")
+		})
+	}
+}
+
+func TestKryoLength(t *testing.T) {
+	// the first and last value of every header size
+	for _, tc := range []struct {
+		value int
+		size  int
+	}{
+		{0, 1}, {1, 1}, {1<<6 - 1, 1},
+		{1 << 6, 2}, {1<<13 - 1, 2},
+		{1 << 13, 3}, {1<<20 - 1, 3},
+		{1 << 20, 4}, {1<<27 - 1, 4},
+		{1 << 27, 5}, {math.MaxInt32, 5},
+	} {
+		encoded := appendKryoLength(nil, tc.value)
+		assert.Len(t, encoded, tc.size, "value %d", tc.value)
+
+		value, n, err := readKryoLength(append(encoded, 0xAA))
+		require.NoError(t, err, "value %d", tc.value)
+		assert.Equal(t, tc.value, value)
+		assert.Equal(t, tc.size, n)
+	}
+
+	t.Run("appends after existing bytes", func(t *testing.T) {
+		assert.Equal(t, []byte{0x03, 0xc0, 0x80, 0x01}, appendKryoLength([]byte{0x03}, 1<<13))
+	})
+
+	t.Run("rejects headers beyond a Java int", func(t *testing.T) {
+		// 1<<31 in the full 34 bits five bytes can hold
+		_, _, err := readKryoLength([]byte{0xc0, 0x80, 0x80, 0x80, 0x10})
+		assert.ErrorIs(t, err, errInvalidKryo)
+		_, _, err = readKryoLength([]byte{0xff, 0xff, 0xff, 0xff, 0xff})
+		assert.ErrorIs(t, err, errInvalidKryo)
+	})
+}
+
+func TestKryoMaxLength(t *testing.T) {
+	assert.NoError(t, checkKryoLength(0))
+	assert.NoError(t, checkKryoLength(MaxKryoStringLength))
+	assert.ErrorIs(t, checkKryoLength(MaxKryoStringLength+1), ErrKryoPayloadTooLarge)
+
+	// the longest allowed string's header is the largest a reader accepts
+	value, _, err := readKryoLength(appendKryoLength(nil, MaxKryoStringLength+1))
+	require.NoError(t, err)
+	assert.Equal(t, MaxKryoStringLength+1, value)
+}
+
+func TestKryoDeserializeRejects(t *testing.T) {
+	valid := kryoSerialize("abc", false)
+	require.Equal(t, []byte{0x03, 0x84, 'a', 'b', 'c'}, valid)
+
+	for name, data := range map[string][]byte{
+		"empty":             nil,
+		"wrong type":        {0x04, 0x84, 'a', 'b', 'c'},
+		"missing length":    {0x03},
+		"ASCII encoding":    {0x03, 'a', 'b', 'c' | 0x80},
+		"truncated length":  {0x03, 0xc0},
+		"truncated 3 bytes": {0x03, 0xc0, 0x80},
+		"null":              {0x03, 0x80},
+		"truncated payload": valid[:len(valid)-1],
+		"trailing bytes":    append(append([]byte{}, valid...), 'd'),
+	} {
+		_, err := kryoDeserialize(data, false)
+		assert.ErrorIs(t, err, errInvalidKryo, name)
+	}
+
+	t.Run("requires the reference flag", func(t *testing.T) {
+		_, err := kryoDeserialize([]byte{0x03}, true)
+		assert.ErrorIs(t, err, errInvalidKryo)
+		decoded, err := kryoDeserialize([]byte{0x03, 0x01, 0x84, 'a', 'b', 'c'}, true)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", decoded)
+	})
+
+	t.Run("round-trips transaction encodings", func(t *testing.T) {
+		vectors := loadCurrencyTestVectors(t)
+		basic := vectors.TestVectors.BasicTransaction
+		serialized, err := hex.DecodeString(basic.KryoBytesHex)
+		require.NoError(t, err)
+		decoded, err := kryoDeserialize(serialized, vectors.CryptoParams.KryoSetReferences)
+		require.NoError(t, err)
+		assert.Equal(t, basic.EncodedString, decoded)
+	})
+}
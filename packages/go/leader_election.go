@@ -0,0 +1,258 @@
+package constellation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultLeaderTTL = 15 * time.Second
+
+var (
+	// ErrInvalidLeaderElection indicates a LeaderElectionConfig that cannot
+	// elect a leader
+	ErrInvalidLeaderElection = errors.New("invalid leader election config")
+	// ErrNotLeader indicates work abandoned because this replica does not
+	// hold the lease
+	ErrNotLeader = errors.New("not the leader")
+)
+
+// LeaderElectionConfig holds configuration for a LeaderElection
+type LeaderElectionConfig struct {
+	// Lock grants the lease, e.g. an SQLLeaderLock or RedisLeaderLock
+	// shared by all replicas
+	Lock LeaderLock
+	// Name identifies the singleton, e.g. "withdrawals:" + queue address
+	Name string
+	// Holder identifies this replica (default: host name, process ID and a
+	// random suffix)
+	Holder string
+	// TTL is how long a lease lasts without renewal (default: 15s)
+	TTL time.Duration
+	// RenewInterval is the delay between renewals, and between attempts
+	// while another replica leads (default: TTL/3). It must be below TTL.
+	RenewInterval time.Duration
+	// OnElected, if set, is called when this replica becomes leader
+	OnElected func()
+	// OnDemoted, if set, is called when this replica stops being leader
+	OnDemoted func()
+	// OnError receives lock failures; the election keeps retrying
+	OnError func(err error)
+}
+
+// LeaderElection competes for a LeaderLock lease on behalf of this replica
+// and keeps renewing it while it leads. IsLeader turns false once
+// TTL - RenewInterval has passed since the last renewal started, before the
+// lease can expire and be taken elsewhere, so two replicas never both
+// believe they lead as long as their clocks run at the same rate.
+//
+// Closing the election releases the lease, letting another replica take
+// over at once instead of after the TTL. LeaderElection implements the
+// Service lifecycle.
+//
+// Example:
+//
+//	lock, err := NewSQLLeaderLock(db, "leader_leases", true)
+//	election, err := NewLeaderElection(ctx, LeaderElectionConfig{
+//	    Lock: lock,
+//	    Name: "withdrawals:" + hotWallet.Address,
+//	})
+//	queue, err := NewWithdrawalQueue(ctx, WithdrawalQueueConfig{
+//	    L1:         l1Client,
+//	    PrivateKey: hotWallet.PrivateKey,
+//	    Leader:     election,
+//	})
+type LeaderElection struct {
+	serviceState
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	config LeaderElectionConfig
+
+	mu sync.Mutex
+	// validUntil is when leadership lapses without a renewal; zero when
+	// not leading
+	validUntil time.Time
+	// term counts elections won, so callers can tell a new term from a
+	// continued one
+	term    uint64
+	changed chan struct{}
+	closing bool
+}
+
+// NewLeaderElection creates an election and starts competing for the lease
+func NewLeaderElection(ctx context.Context, config LeaderElectionConfig) (*LeaderElection, error) {
+	if config.TTL <= 0 {
+		config.TTL = defaultLeaderTTL
+	}
+	if config.RenewInterval <= 0 {
+		config.RenewInterval = config.TTL / 3
+	}
+	switch {
+	case config.Lock == nil:
+		return nil, fmt.Errorf("%w: no lock", ErrInvalidLeaderElection)
+	case config.Name == "":
+		return nil, fmt.Errorf("%w: no name", ErrInvalidLeaderElection)
+	case config.RenewInterval >= config.TTL:
+		return nil, fmt.Errorf("%w: renew interval must be below the TTL", ErrInvalidLeaderElection)
+	}
+	if config.Holder == "" {
+		config.Holder = defaultLeaderHolder()
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	e := &LeaderElection{
+		ctx:     runCtx,
+		cancel:  cancel,
+		config:  config,
+		changed: make(chan struct{}),
+	}
+	e.start()
+
+	go e.run(ctx)
+	return e, nil
+}
+
+// defaultLeaderHolder names this process uniquely among replicas
+func defaultLeaderHolder() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Holder returns the name this replica holds the lease under
+func (e *LeaderElection) Holder() string {
+	return e.config.Holder
+}
+
+// IsLeader reports whether this replica holds the lease
+func (e *LeaderElection) IsLeader() bool {
+	_, leading := e.Term()
+	return leading
+}
+
+// Term returns the number of elections this replica has won and whether it
+// currently leads. A changed term means another replica may have led in
+// between.
+func (e *LeaderElection) Term() (term uint64, leading bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.term, time.Now().Before(e.validUntil)
+}
+
+// Close stops competing, releases the lease if held and blocks until the
+// election has stopped
+func (e *LeaderElection) Close() error {
+	e.mu.Lock()
+	e.closing = true
+	e.mu.Unlock()
+
+	e.cancel()
+	<-e.Done()
+	return e.Err()
+}
+
+func (e *LeaderElection) run(parent context.Context) {
+	for {
+		e.attempt()
+
+		timer := time.NewTimer(e.config.RenewInterval)
+		select {
+		case <-e.ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if e.ctx.Err() != nil {
+			break
+		}
+	}
+
+	if e.IsLeader() {
+		if err := e.config.Lock.Release(e.config.Name, e.config.Holder); err != nil {
+			e.reportError(fmt.Errorf("releasing %s: %w", e.config.Name, err))
+		}
+	}
+	e.demote()
+
+	e.mu.Lock()
+	closing := e.closing
+	e.mu.Unlock()
+	if closing {
+		e.finish(nil)
+		return
+	}
+	e.finish(parent.Err())
+}
+
+// attempt takes or renews the lease once
+func (e *LeaderElection) attempt() {
+	started := time.Now()
+	acquired, err := e.config.Lock.Acquire(e.config.Name, e.config.Holder, e.config.TTL)
+	if err != nil {
+		e.reportError(fmt.Errorf("acquiring %s: %w", e.config.Name, err))
+		// the lease may still be valid; it lapses on its own if renewals
+		// keep failing
+		if !e.IsLeader() {
+			e.demote()
+		}
+		return
+	}
+	if !acquired {
+		e.demote()
+		return
+	}
+
+	e.mu.Lock()
+	elected := !started.Before(e.validUntil)
+	e.validUntil = started.Add(e.config.TTL - e.config.RenewInterval)
+	if elected {
+		e.term++
+		e.broadcast()
+	}
+	e.mu.Unlock()
+
+	if elected && e.config.OnElected != nil {
+		e.config.OnElected()
+	}
+}
+
+// demote records that this replica no longer leads, notifying OnDemoted if
+// it did
+func (e *LeaderElection) demote() {
+	e.mu.Lock()
+	wasLeader := !e.validUntil.IsZero()
+	e.validUntil = time.Time{}
+	if wasLeader {
+		e.broadcast()
+	}
+	e.mu.Unlock()
+
+	if wasLeader && e.config.OnDemoted != nil {
+		e.config.OnDemoted()
+	}
+}
+
+// broadcast wakes everyone waiting on leaderChanged; callers hold e.mu
+func (e *LeaderElection) broadcast() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// leaderChanged returns a channel closed at the next election or demotion
+func (e *LeaderElection) leaderChanged() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.changed
+}
+
+func (e *LeaderElection) reportError(err error) {
+	if e.config.OnError != nil {
+		e.config.OnError(err)
+	}
+}
@@ -0,0 +1,187 @@
+package constellation
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis runs the lock scripts against an in-memory key space
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string]memoryLease
+}
+
+func (r *fakeRedis) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, holder := keys[0], args[0].(string)
+	current, ok := r.keys[key]
+	if ok && !time.Now().Before(current.expires) {
+		ok = false
+	}
+	switch script {
+	case redisAcquireScript:
+		millis, _ := strconv.ParseInt(args[1].(string), 10, 64)
+		if ok && current.holder != holder {
+			return int64(0), nil
+		}
+		r.keys[key] = memoryLease{holder: holder, expires: time.Now().Add(time.Duration(millis) * time.Millisecond)}
+		return int64(1), nil
+	case redisReleaseScript:
+		if ok && current.holder == holder {
+			delete(r.keys, key)
+			return int64(1), nil
+		}
+		return int64(0), nil
+	}
+	panic("unexpected script")
+}
+
+func TestLeaderLocks(t *testing.T) {
+	for name, lock := range map[string]LeaderLock{
+		"memory": NewMemoryLeaderLock(),
+		"redis":  NewRedisLeaderLock(&fakeRedis{keys: map[string]memoryLease{}}, "test:"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			acquired, err := lock.Acquire("queue", "a", 50*time.Millisecond)
+			require.NoError(t, err)
+			assert.True(t, acquired)
+			acquired, err = lock.Acquire("queue", "b", time.Second)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+			acquired, err = lock.Acquire("queue", "a", 50*time.Millisecond)
+			require.NoError(t, err)
+			assert.True(t, acquired, "the holder renews")
+
+			require.NoError(t, lock.Release("queue", "b"))
+			acquired, _ = lock.Acquire("queue", "b", time.Second)
+			assert.False(t, acquired, "only the holder releases")
+
+			time.Sleep(60 * time.Millisecond)
+			acquired, _ = lock.Acquire("queue", "b", time.Second)
+			assert.True(t, acquired, "an expired lease is taken over")
+			require.NoError(t, lock.Release("queue", "b"))
+			acquired, _ = lock.Acquire("queue", "a", time.Second)
+			assert.True(t, acquired)
+		})
+	}
+
+	t.Run("SQL lock rejects bad table names", func(t *testing.T) {
+		_, err := NewSQLLeaderLock(nil, "leases; DROP TABLE x", false)
+		assert.ErrorIs(t, err, ErrInvalidTableName)
+		lock, err := NewSQLLeaderLock(nil, "leader_leases", true)
+		require.NoError(t, err)
+		assert.Equal(t, "$5", lock.bind(5))
+	})
+}
+
+func TestLeaderElection(t *testing.T) {
+	newElection := func(t *testing.T, lock LeaderLock, holder string, events chan string) *LeaderElection {
+		election, err := NewLeaderElection(context.Background(), LeaderElectionConfig{
+			Lock:          lock,
+			Name:          "withdrawals",
+			Holder:        holder,
+			TTL:           300 * time.Millisecond,
+			RenewInterval: 20 * time.Millisecond,
+			OnElected:     func() { events <- holder + " elected" },
+			OnDemoted:     func() { events <- holder + " demoted" },
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { election.Close() })
+		return election
+	}
+
+	t.Run("fails over when the leader closes", func(t *testing.T) {
+		lock := NewMemoryLeaderLock()
+		events := make(chan string, 8)
+		first := newElection(t, lock, "a", events)
+		assert.Equal(t, "a elected", <-events)
+		second := newElection(t, lock, "b", events)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.True(t, first.IsLeader())
+		assert.False(t, second.IsLeader())
+
+		require.NoError(t, first.Close())
+		assert.Equal(t, "a demoted", <-events)
+		assert.False(t, first.IsLeader())
+		assert.Equal(t, "b elected", <-events)
+		term, leading := second.Term()
+		assert.True(t, leading)
+		assert.Equal(t, uint64(1), term)
+	})
+
+	t.Run("validates its config", func(t *testing.T) {
+		for _, config := range []LeaderElectionConfig{
+			{Name: "withdrawals"},
+			{Lock: NewMemoryLeaderLock()},
+			{Lock: NewMemoryLeaderLock(), Name: "withdrawals", TTL: time.Second, RenewInterval: time.Second},
+		} {
+			_, err := NewLeaderElection(context.Background(), config)
+			assert.ErrorIs(t, err, ErrInvalidLeaderElection)
+		}
+	})
+}
+
+func TestWithdrawalQueueLeadership(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, err := GenerateKeyPair()
+	require.NoError(t, err)
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+
+	lock := NewMemoryLeaderLock()
+	acquired, err := lock.Acquire("withdrawals", "other-replica", time.Hour)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	election, err := NewLeaderElection(context.Background(), LeaderElectionConfig{
+		Lock:          lock,
+		Name:          "withdrawals",
+		TTL:           300 * time.Millisecond,
+		RenewInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer election.Close()
+
+	receipts := make(chan WithdrawalReceipt, 4)
+	newQueue := func() *WithdrawalQueue {
+		queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+			L1:         ledger,
+			PrivateKey: hot.PrivateKey,
+			Leader:     election,
+			OnReceipt:  func(r WithdrawalReceipt) { receipts <- r },
+		})
+		require.NoError(t, err)
+		return queue
+	}
+
+	t.Run("waits while another replica leads", func(t *testing.T) {
+		queue := newQueue()
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-1", Destination: destination.Address, Amount: 10}))
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 1, queue.Pending())
+
+		require.NoError(t, lock.Release("withdrawals", "other-replica"))
+		receipt := <-receipts
+		assert.Equal(t, WithdrawalSubmitted, receipt.Status)
+		require.NoError(t, queue.Close())
+	})
+
+	t.Run("cancels waiting requests when closed without leading", func(t *testing.T) {
+		require.NoError(t, election.Close())
+		queue := newQueue()
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-2", Destination: destination.Address, Amount: 10}))
+		require.NoError(t, queue.Close())
+		receipt := <-receipts
+		assert.Equal(t, WithdrawalCancelled, receipt.Status)
+		assert.ErrorIs(t, receipt.Err, ErrNotLeader)
+	})
+}
@@ -0,0 +1,217 @@
+package constellation
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LeaderLock grants time-limited leases on named locks, so only one replica
+// at a time runs a singleton such as an address's WithdrawalQueue. A lease
+// not renewed within its TTL expires and another holder may take it.
+type LeaderLock interface {
+	// Acquire takes the lease on name for holder, or renews it if holder
+	// already has it, until ttl from now. It returns false if another
+	// holder's lease has not yet expired.
+	Acquire(name string, holder string, ttl time.Duration) (bool, error)
+	// Release gives up holder's lease on name. Releasing a lease held by
+	// someone else does nothing.
+	Release(name string, holder string) error
+}
+
+// MemoryLeaderLock keeps leases in memory. It only coordinates elections
+// within one process; useful for tests.
+type MemoryLeaderLock struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+}
+
+type memoryLease struct {
+	holder  string
+	expires time.Time
+}
+
+// NewMemoryLeaderLock creates a MemoryLeaderLock without leases
+func NewMemoryLeaderLock() *MemoryLeaderLock {
+	return &MemoryLeaderLock{leases: map[string]memoryLease{}}
+}
+
+// Acquire takes or renews the lease on name for holder
+func (l *MemoryLeaderLock) Acquire(name string, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if lease, ok := l.leases[name]; ok && lease.holder != holder && now.Before(lease.expires) {
+		return false, nil
+	}
+	l.leases[name] = memoryLease{holder: holder, expires: now.Add(ttl)}
+	return true, nil
+}
+
+// Release gives up holder's lease on name
+func (l *MemoryLeaderLock) Release(name string, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lease, ok := l.leases[name]; ok && lease.holder == holder {
+		delete(l.leases, name)
+	}
+	return nil
+}
+
+// SQLLeaderLock keeps leases in a database table via database/sql. Expiry
+// is compared against each replica's clock, so keep clocks synchronized and
+// the TTL well above their skew.
+//
+// Table layout:
+//
+//	CREATE TABLE leader_leases (
+//	    name       VARCHAR(255) PRIMARY KEY,
+//	    holder     VARCHAR(255) NOT NULL,
+//	    expires_at TIMESTAMP    NOT NULL
+//	)
+type SQLLeaderLock struct {
+	db    *sql.DB
+	table string
+	// numbered selects $1-style placeholders (PostgreSQL) instead of ?
+	numbered bool
+}
+
+// NewSQLLeaderLock creates a lock on table. Set numberedPlaceholders for
+// drivers that use $1, $2 (PostgreSQL); leave it false for ? (MySQL,
+// SQLite).
+func NewSQLLeaderLock(db *sql.DB, table string, numberedPlaceholders bool) (*SQLLeaderLock, error) {
+	if !sqlIdentifier.MatchString(table) {
+		return nil, ErrInvalidTableName
+	}
+	return &SQLLeaderLock{db: db, table: table, numbered: numberedPlaceholders}, nil
+}
+
+// CreateTable creates the lease table if it does not exist
+func (l *SQLLeaderLock) CreateTable() error {
+	_, err := l.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name VARCHAR(255) PRIMARY KEY,
+		holder VARCHAR(255) NOT NULL,
+		expires_at TIMESTAMP NOT NULL
+	)`, l.table))
+	return err
+}
+
+// Acquire takes over the lease row if holder owns it or it has expired, and
+// inserts it if there is none. Of two replicas racing to insert, the
+// primary key lets only one win.
+func (l *SQLLeaderLock) Acquire(name string, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	update := fmt.Sprintf("UPDATE %s SET holder = %s, expires_at = %s WHERE name = %s AND (holder = %s OR expires_at < %s)",
+		l.table, l.bind(1), l.bind(2), l.bind(3), l.bind(4), l.bind(5))
+	result, err := l.db.Exec(update, holder, now.Add(ttl), name, holder, now)
+	if err != nil {
+		return false, err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+		return true, nil
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (name, holder, expires_at) VALUES (%s, %s, %s)",
+		l.table, l.bind(1), l.bind(2), l.bind(3))
+	if _, err := l.db.Exec(insert, name, holder, now.Add(ttl)); err != nil {
+		// a row that exists after a failed insert is another holder's lease
+		query := fmt.Sprintf("SELECT 1 FROM %s WHERE name = %s", l.table, l.bind(1))
+		var exists int
+		if lookupErr := l.db.QueryRow(query, name).Scan(&exists); lookupErr == nil {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Release deletes holder's lease row
+func (l *SQLLeaderLock) Release(name string, holder string) error {
+	_, err := l.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = %s AND holder = %s", l.table, l.bind(1), l.bind(2)), name, holder)
+	return err
+}
+
+func (l *SQLLeaderLock) bind(n int) string {
+	if l.numbered {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// RedisScripter runs a Lua script on a Redis server and returns its
+// result. Adapt your Redis client to it; with go-redis:
+//
+//	type scripter struct{ *redis.Client }
+//
+//	func (s scripter) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+//	    return s.Client.Eval(context.Background(), script, keys, args...).Result()
+//	}
+type RedisScripter interface {
+	Eval(script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// redisAcquireScript sets KEYS[1] to the holder ARGV[1] for ARGV[2]
+// milliseconds unless another holder has it
+const redisAcquireScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+return 0`
+
+// redisReleaseScript deletes KEYS[1] if the holder ARGV[1] has it
+const redisReleaseScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+// RedisLeaderLock keeps each lease in a Redis key holding the holder's name
+// and expiring with the lease. Checking and setting the holder happen in
+// one script, so they are atomic on the server, and expiry uses the
+// server's clock only.
+type RedisLeaderLock struct {
+	client RedisScripter
+	prefix string
+}
+
+// NewRedisLeaderLock creates a lock storing leases under keys prefix+name
+// (e.g., prefix "metakit:leader:")
+func NewRedisLeaderLock(client RedisScripter, prefix string) *RedisLeaderLock {
+	return &RedisLeaderLock{client: client, prefix: prefix}
+}
+
+// Acquire takes or renews the lease on name for holder
+func (l *RedisLeaderLock) Acquire(name string, holder string, ttl time.Duration) (bool, error) {
+	millis := ttl.Milliseconds()
+	if millis < 1 {
+		millis = 1
+	}
+	result, err := l.client.Eval(redisAcquireScript, []string{l.prefix + name}, holder, strconv.FormatInt(millis, 10))
+	if err != nil {
+		return false, err
+	}
+	return redisTrue(result), nil
+}
+
+// Release gives up holder's lease on name
+func (l *RedisLeaderLock) Release(name string, holder string) error {
+	_, err := l.client.Eval(redisReleaseScript, []string{l.prefix + name}, holder)
+	return err
+}
+
+// redisTrue reports whether a script returned the integer 1
+func redisTrue(result interface{}) bool {
+	switch n := result.(type) {
+	case int64:
+		return n == 1
+	case int:
+		return n == 1
+	}
+	return false
+}
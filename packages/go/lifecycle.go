@@ -0,0 +1,59 @@
+package constellation
+
+import "sync"
+
+// Service is the lifecycle contract shared by every long-running component
+// (watchers, followers, submitters, dispatchers):
+//
+//   - The constructor takes a context.Context. Cancelling it stops the
+//     component promptly without draining; queued work is abandoned.
+//   - Close stops accepting new work, drains work already accepted, and
+//     blocks until the component has stopped. It is safe to call more than
+//     once and returns the same result as Err.
+//   - Done is closed once the component has fully stopped, whether through
+//     Close, context cancellation, or a fatal error.
+//   - Err returns nil while running and after a clean Close, and the terminal
+//     error otherwise (context.Canceled, context.DeadlineExceeded, or the
+//     failure that stopped the component).
+type Service interface {
+	Close() error
+	Done() <-chan struct{}
+	Err() error
+}
+
+// serviceState tracks the terminal state of a Service. Components embed it
+// and call finish exactly when their last goroutine exits.
+type serviceState struct {
+	done chan struct{}
+	once sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// start prepares the state; call it from the constructor before any goroutine runs
+func (s *serviceState) start() {
+	s.done = make(chan struct{})
+}
+
+// finish records the terminal error and closes Done. Later calls are ignored.
+func (s *serviceState) finish(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// Done is closed once the component has stopped
+func (s *serviceState) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the terminal error, or nil while running or after a clean stop
+func (s *serviceState) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
@@ -0,0 +1,173 @@
+package constellation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Merkle node domain separation prefixes, as in Tessellation's merkle trees
+const (
+	merkleLeafPrefix     = 0x00
+	merkleInternalPrefix = 0x01
+)
+
+var (
+	// ErrEmptyMerkleTree indicates a tree built from no leaves
+	ErrEmptyMerkleTree = errors.New("merkle tree needs at least one leaf")
+	// ErrMerkleLeafNotFound indicates a proof request for a leaf outside the tree
+	ErrMerkleLeafNotFound = errors.New("leaf is not in the merkle tree")
+)
+
+// MerkleTree commits to a list of hashes with a single root. Leaves and
+// nodes are hashed over the hex strings of their inputs with 0x00 / 0x01
+// prefixes, following the hash conventions of snapshots. An unpaired node is
+// promoted to the next level unchanged.
+//
+// Example:
+//
+//	tree, err := MerkleTreeFromData(records, false)
+//	root := tree.Root() // commit on-chain
+//	proof, _ := tree.Proof(42)
+//	ok := VerifyMerkleProof(root, proof)
+type MerkleTree struct {
+	// levels[0] are the hashed leaves; the last level holds the root
+	levels [][]string
+	leaves []string
+}
+
+// MerkleProofStep is one sibling on the path from a leaf to the root
+type MerkleProofStep struct {
+	Hash string `json:"hash"`
+	// Left is true when the sibling is the left input of the parent
+	Left bool `json:"left"`
+}
+
+// MerkleProof proves that Leaf is at Index in a tree of Size leaves
+type MerkleProof struct {
+	// Leaf is the hash committed by the tree, as passed to NewMerkleTree
+	Leaf  string `json:"leaf"`
+	Index int    `json:"index"`
+	// Size is the number of leaves in the tree, which fixes where unpaired
+	// nodes were promoted
+	Size int               `json:"size"`
+	Path []MerkleProofStep `json:"path"`
+}
+
+// NewMerkleTree builds a tree over hex hashes, e.g. HashData results
+func NewMerkleTree(leaves []string) (*MerkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrEmptyMerkleTree
+	}
+
+	level := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = merkleHash(merkleLeafPrefix, leaf)
+	}
+	tree := &MerkleTree{levels: [][]string{level}, leaves: append([]string{}, leaves...)}
+
+	for len(level) > 1 {
+		next := make([]string, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, merkleHash(merkleInternalPrefix, level[i], level[i+1]))
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+	return tree, nil
+}
+
+// MerkleTreeFromData builds a tree over the HashData hash of each item
+func MerkleTreeFromData[T any](items []T, isDataUpdate bool) (*MerkleTree, error) {
+	leaves := make([]string, len(items))
+	for i, item := range items {
+		hash, err := HashData(item, isDataUpdate)
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = hash.Value
+	}
+	return NewMerkleTree(leaves)
+}
+
+// Root returns the root hash
+func (t *MerkleTree) Root() string {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Leaves returns the hashes the tree was built from
+func (t *MerkleTree) Leaves() []string {
+	return append([]string{}, t.leaves...)
+}
+
+// Proof returns the inclusion proof for the leaf at index
+func (t *MerkleTree) Proof(index int) (*MerkleProof, error) {
+	if index < 0 || index >= len(t.leaves) {
+		return nil, ErrMerkleLeafNotFound
+	}
+
+	proof := &MerkleProof{Leaf: t.leaves[index], Index: index, Size: len(t.leaves)}
+	position := index
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := position ^ 1
+		if sibling < len(level) {
+			proof.Path = append(proof.Path, MerkleProofStep{Hash: level[sibling], Left: sibling < position})
+		}
+		position /= 2
+	}
+	return proof, nil
+}
+
+// ProofFor returns the inclusion proof for the first leaf equal to leaf
+func (t *MerkleTree) ProofFor(leaf string) (*MerkleProof, error) {
+	for i, candidate := range t.leaves {
+		if candidate == leaf {
+			return t.Proof(i)
+		}
+	}
+	return nil, ErrMerkleLeafNotFound
+}
+
+// VerifyMerkleProof reports whether proof links its leaf to root at
+// proof.Index. The path must have a sibling exactly where a tree of
+// proof.Size leaves pairs the node, on the side Index puts it. The root
+// does not commit to the size, so a verifier relying on Index should also
+// check Size against the size it expects.
+func VerifyMerkleProof(root string, proof *MerkleProof) bool {
+	if proof == nil || proof.Index < 0 || proof.Index >= proof.Size {
+		return false
+	}
+	current := merkleHash(merkleLeafPrefix, proof.Leaf)
+	position, width, steps := proof.Index, proof.Size, proof.Path
+	for width > 1 {
+		sibling := position ^ 1
+		if sibling < width {
+			if len(steps) == 0 || steps[0].Left != (sibling < position) {
+				return false
+			}
+			if steps[0].Left {
+				current = merkleHash(merkleInternalPrefix, steps[0].Hash, current)
+			} else {
+				current = merkleHash(merkleInternalPrefix, current, steps[0].Hash)
+			}
+			steps = steps[1:]
+		}
+		position /= 2
+		width = (width + 1) / 2
+	}
+	return len(steps) == 0 && current == root
+}
+
+// merkleHash hashes the prefix followed by the hex strings' bytes
+func merkleHash(prefix byte, inputs ...string) string {
+	hasher := sha256.New()
+	hasher.Write([]byte{prefix})
+	for _, input := range inputs {
+		hasher.Write([]byte(input))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
@@ -0,0 +1,121 @@
+package constellation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleTree(t *testing.T) {
+	leaf := func(i int) string { return HashBytes([]byte(fmt.Sprint(i))).Value }
+
+	t.Run("single leaf", func(t *testing.T) {
+		tree, err := NewMerkleTree([]string{leaf(0)})
+		require.NoError(t, err)
+		assert.Equal(t, merkleHash(merkleLeafPrefix, leaf(0)), tree.Root())
+
+		proof, err := tree.Proof(0)
+		require.NoError(t, err)
+		assert.Empty(t, proof.Path)
+		assert.True(t, VerifyMerkleProof(tree.Root(), proof))
+	})
+
+	t.Run("two leaves", func(t *testing.T) {
+		tree, err := NewMerkleTree([]string{leaf(0), leaf(1)})
+		require.NoError(t, err)
+		expected := merkleHash(merkleInternalPrefix, merkleHash(merkleLeafPrefix, leaf(0)), merkleHash(merkleLeafPrefix, leaf(1)))
+		assert.Equal(t, expected, tree.Root())
+	})
+
+	t.Run("every proof verifies for uneven sizes", func(t *testing.T) {
+		for size := 1; size <= 17; size++ {
+			leaves := make([]string, size)
+			for i := range leaves {
+				leaves[i] = leaf(i)
+			}
+			tree, err := NewMerkleTree(leaves)
+			require.NoError(t, err)
+			for i := range leaves {
+				proof, err := tree.Proof(i)
+				require.NoError(t, err)
+				assert.True(t, VerifyMerkleProof(tree.Root(), proof), "size %d leaf %d", size, i)
+			}
+		}
+	})
+
+	t.Run("matches the reference vector", func(t *testing.T) {
+		// five leaves: the fifth is promoted twice before pairing
+		leaves := []string{leaf(0), leaf(1), leaf(2), leaf(3), leaf(4)}
+		tree, err := NewMerkleTree(leaves)
+		require.NoError(t, err)
+		assert.Equal(t, "6a280dbdedfbe0a1ece4e109cd9ee3a758b362e8c4a92093d158649a7152f477", tree.Root())
+
+		proof, err := tree.Proof(3)
+		require.NoError(t, err)
+		assert.Equal(t, []MerkleProofStep{
+			{Hash: "f6aaeb3a96904c0130a9126158731967d8af628896390435deb9000e97b30670", Left: true},
+			{Hash: "7f8f8d6d6dd0afea81c01a94fab28a755e82d77a1b837ced703d11d548451ba8", Left: true},
+			{Hash: "6492f5cdf01f00a7f8d0159c0bf5fa8653cc6985527f3c2739099d4cf41e5cd9", Left: false},
+		}, proof.Path)
+		proof, err = tree.Proof(4)
+		require.NoError(t, err)
+		assert.Equal(t, []MerkleProofStep{{Hash: "45558b515463fd8e0d6523219b0816512ccf151ac68f159b08b2f9492b8dfd10", Left: true}}, proof.Path)
+	})
+
+	t.Run("checks the index against the path", func(t *testing.T) {
+		tree, err := NewMerkleTree([]string{leaf(0), leaf(1), leaf(2), leaf(3), leaf(4)})
+		require.NoError(t, err)
+		for index := 0; index < 5; index++ {
+			proof, err := tree.Proof(index)
+			require.NoError(t, err)
+			assert.Equal(t, 5, proof.Size)
+			for claimed := -1; claimed <= 5; claimed++ {
+				if claimed == index {
+					continue
+				}
+				moved := *proof
+				moved.Index = claimed
+				assert.False(t, VerifyMerkleProof(tree.Root(), &moved), "leaf %d claimed at %d", index, claimed)
+			}
+		}
+
+		proof, err := tree.Proof(4)
+		require.NoError(t, err)
+		proof.Size = 0
+		assert.False(t, VerifyMerkleProof(tree.Root(), proof), "proofs without a size")
+	})
+
+	t.Run("rejects wrong leaves and roots", func(t *testing.T) {
+		tree, err := NewMerkleTree([]string{leaf(0), leaf(1), leaf(2)})
+		require.NoError(t, err)
+		proof, err := tree.ProofFor(leaf(2))
+		require.NoError(t, err)
+		assert.Equal(t, 2, proof.Index)
+
+		forged := *proof
+		forged.Leaf = leaf(3)
+		assert.False(t, VerifyMerkleProof(tree.Root(), &forged))
+		assert.False(t, VerifyMerkleProof(leaf(9), proof))
+		assert.False(t, VerifyMerkleProof(tree.Root(), nil))
+
+		_, err = tree.ProofFor(leaf(3))
+		assert.ErrorIs(t, err, ErrMerkleLeafNotFound)
+		_, err = tree.Proof(3)
+		assert.ErrorIs(t, err, ErrMerkleLeafNotFound)
+	})
+
+	t.Run("builds from data", func(t *testing.T) {
+		records := []map[string]int{{"id": 1}, {"id": 2}}
+		tree, err := MerkleTreeFromData(records, false)
+		require.NoError(t, err)
+		hash, _ := HashData(records[1], false)
+		proof, err := tree.ProofFor(hash.Value)
+		require.NoError(t, err)
+		assert.True(t, VerifyMerkleProof(tree.Root(), proof))
+
+		_, err = NewMerkleTree(nil)
+		assert.ErrorIs(t, err, ErrEmptyMerkleTree)
+	})
+}
@@ -0,0 +1,164 @@
+package constellation
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+// bip39SeedIterations is the PBKDF2 round count BIP39 uses to stretch a
+// mnemonic into a seed
+const bip39SeedIterations = 2048
+
+// ErrInvalidMnemonic indicates a phrase that is not a valid BIP39 English
+// mnemonic
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+var (
+	bip39Words     = strings.Fields(bip39EnglishWords)
+	bip39WordIndex = func() map[string]int {
+		index := make(map[string]int, len(bip39Words))
+		for i, word := range bip39Words {
+			index[word] = i
+		}
+		return index
+	}()
+)
+
+// GenerateMnemonic creates a random 12-word BIP39 mnemonic, the length
+// dag4.js and Stargazer generate
+func GenerateMnemonic() (string, error) {
+	entropy := make([]byte, 16)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %w", err)
+	}
+	return NewMnemonic(entropy)
+}
+
+// NewMnemonic encodes entropy as a BIP39 English mnemonic. Entropy must be
+// 16, 20, 24, 28 or 32 bytes, giving 12 to 24 words.
+func NewMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("%w: entropy must be 16 to 32 bytes in steps of 4", ErrInvalidMnemonic)
+	}
+
+	checksumBits := uint(len(entropy) / 4)
+	checksum := sha256.Sum256(entropy)
+	bits := new(big.Int).SetBytes(entropy)
+	bits.Lsh(bits, checksumBits)
+	bits.Or(bits, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	count := (len(entropy)*8 + int(checksumBits)) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	for i := count - 1; i >= 0; i-- {
+		words[i] = bip39Words[new(big.Int).And(bits, mask).Int64()]
+		bits.Rsh(bits, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// ValidateMnemonic checks that phrase is a BIP39 English mnemonic with a
+// valid checksum
+func ValidateMnemonic(phrase string) error {
+	_, err := mnemonicEntropy(phrase)
+	return err
+}
+
+// mnemonicEntropy decodes phrase back to its entropy, checking the words
+// and the checksum
+func mnemonicEntropy(phrase string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(phrase))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("%w: %d words", ErrInvalidMnemonic, len(words))
+	}
+
+	bits := new(big.Int)
+	for i, word := range words {
+		index, ok := bip39WordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: word %d is not in the wordlist", ErrInvalidMnemonic, i+1)
+		}
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(index)))
+	}
+
+	checksumBits := uint(len(words) / 3)
+	checksum := new(big.Int).And(bits, big.NewInt(int64(1)<<checksumBits-1)).Int64()
+	bits.Rsh(bits, checksumBits)
+
+	entropy := make([]byte, len(words)*4/3)
+	bits.FillBytes(entropy)
+	expected := sha256.Sum256(entropy)
+	if int64(expected[0]>>(8-checksumBits)) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+	return entropy, nil
+}
+
+// MnemonicToSeed derives the 64-byte BIP39 seed of a mnemonic and optional
+// passphrase. Wallets built on dag4.js use an empty passphrase unless the
+// user sets one. BIP39 normalizes the passphrase to Unicode NFKD, which this
+// package leaves to the caller: ASCII passphrases need nothing, others
+// should be passed through norm.NFKD.String (golang.org/x/text) first, or
+// they derive a different wallet than dag4.js and hardware wallets do.
+func MnemonicToSeed(phrase string, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(phrase); err != nil {
+		return nil, err
+	}
+	normalized := strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	return pbkdf2Key(sha512.New, []byte(normalized), []byte("mnemonic"+passphrase), bip39SeedIterations, 64), nil
+}
+
+// KeyPairFromMnemonic derives the key pair dag4.js and Stargazer use for a
+// mnemonic: the first address of the BIP44 DAG path, m/44'/1137'/0'/0/0
+//
+// Example:
+//
+//	phrase, _ := GenerateMnemonic()
+//	keyPair, err := KeyPairFromMnemonic(phrase)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(keyPair.Address)
+func KeyPairFromMnemonic(phrase string) (*KeyPair, error) {
+	return DeriveKeyPair(phrase, 0, 0)
+}
+
+// KeyPairFromMnemonicWithPassphrase is KeyPairFromMnemonic for a mnemonic
+// protected by a BIP39 passphrase, as dag4.js derives it when given one
+func KeyPairFromMnemonicWithPassphrase(phrase string, passphrase string) (*KeyPair, error) {
+	return DeriveKeyPairWithPassphrase(phrase, passphrase, 0, 0)
+}
+
+// pbkdf2Key is PBKDF2 (RFC 8018) with an HMAC of the given hash
+func pbkdf2Key(h func() hash.Hash, password []byte, salt []byte, iterations int, length int) []byte {
+	prf := hmac.New(h, password)
+	var key []byte
+	for block := uint32(1); len(key) < length; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], block)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:length]
+}
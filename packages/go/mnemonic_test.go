@@ -0,0 +1,193 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBIP39Wordlist(t *testing.T) {
+	require.Len(t, bip39Words, 2048)
+	// crc32 of the published english.txt
+	assert.Equal(t, uint32(0xc1dbd296), crc32.ChecksumIEEE([]byte(strings.Join(bip39Words, "\n")+"\n")))
+}
+
+func TestMnemonic(t *testing.T) {
+	// BIP39 reference vectors (passphrase "TREZOR")
+	vectors := []struct{ entropy, mnemonic, seed string }{
+		{"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+		{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607"},
+		{"ffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069"},
+		{"808080808080808080808080808080808080808080808080",
+			"letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always",
+			"107d7c02a5aa6f38c58083ff74f04c607c2d2c0ecc55501dadd72d025b751bc27fe913ffb796f841c49b1d33b610cf0e91d3aa239027f5e99fe4ce9e5088cd65"},
+	}
+
+	t.Run("matches the reference vectors", func(t *testing.T) {
+		for _, v := range vectors {
+			entropy, _ := hex.DecodeString(v.entropy)
+			phrase, err := NewMnemonic(entropy)
+			require.NoError(t, err)
+			assert.Equal(t, v.mnemonic, phrase)
+
+			decoded, err := mnemonicEntropy(phrase)
+			require.NoError(t, err)
+			assert.Equal(t, entropy, decoded)
+
+			seed, err := MnemonicToSeed(v.mnemonic, "TREZOR")
+			require.NoError(t, err)
+			assert.Equal(t, v.seed, hex.EncodeToString(seed))
+		}
+	})
+
+	t.Run("generates 12 valid words", func(t *testing.T) {
+		phrase, err := GenerateMnemonic()
+		require.NoError(t, err)
+		assert.Len(t, strings.Fields(phrase), 12)
+		assert.NoError(t, ValidateMnemonic(phrase))
+	})
+
+	t.Run("rejects invalid phrases", func(t *testing.T) {
+		for _, phrase := range []string{
+			"",
+			"abandon abandon abandon",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon notaword",
+		} {
+			assert.ErrorIs(t, ValidateMnemonic(phrase), ErrInvalidMnemonic, phrase)
+		}
+		_, err := NewMnemonic(make([]byte, 15))
+		assert.ErrorIs(t, err, ErrInvalidMnemonic)
+	})
+
+	t.Run("derives the first BIP44 DAG key", func(t *testing.T) {
+		phrase := vectors[0].mnemonic
+		keyPair, err := KeyPairFromMnemonic("  " + strings.ToUpper(phrase) + "\n")
+		require.NoError(t, err)
+		assert.True(t, IsValidDAGAddress(keyPair.Address))
+
+		seed, err := MnemonicToSeed(phrase, "")
+		require.NoError(t, err)
+		key, err := deriveHDKey(seed, dagBIP44Path(0, 0)...)
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(key.key[:]), privateKeyHexOf(t, keyPair))
+
+		_, err = KeyPairFromMnemonic("not a mnemonic")
+		assert.ErrorIs(t, err, ErrInvalidMnemonic)
+	})
+}
+
+func TestHDKeyDerivation(t *testing.T) {
+	// BIP32 test vector 1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for _, c := range []struct {
+		path []uint32
+		key  string
+	}{
+		{nil, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{[]uint32{hardenedKeyOffset}, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{[]uint32{hardenedKeyOffset, 1}, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{[]uint32{hardenedKeyOffset, 1, 2 + hardenedKeyOffset}, "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+	} {
+		key, err := deriveHDKey(seed, c.path...)
+		require.NoError(t, err)
+		assert.Equal(t, c.key, hex.EncodeToString(key.key[:]), "%v", c.path)
+	}
+}
+
+func TestDeriveKeyPair(t *testing.T) {
+	phrase := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+	seed, err := MnemonicToSeed(phrase, "")
+	require.NoError(t, err)
+
+	batch, err := DeriveKeyPairs(phrase, 0, 0, 3)
+	require.NoError(t, err)
+	require.Len(t, batch, 3)
+
+	seen := map[string]bool{}
+	for i, keyPair := range batch {
+		single, err := DeriveKeyPair(phrase, 0, uint32(i))
+		require.NoError(t, err)
+		assert.Equal(t, single, keyPair)
+
+		key, err := deriveHDKey(seed, dagBIP44Path(0, uint32(i))...)
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(key.key[:]), privateKeyHexOf(t, keyPair))
+		seen[keyPair.Address] = true
+	}
+	assert.Len(t, seen, 3)
+
+	first, err := KeyPairFromMnemonic(phrase)
+	require.NoError(t, err)
+	assert.Equal(t, batch[0], first)
+
+	otherAccount, err := DeriveKeyPair(phrase, 1, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Address, otherAccount.Address)
+
+	for _, c := range []struct {
+		account, index uint32
+		count          int
+	}{
+		{hardenedKeyOffset, 0, 1},
+		{0, hardenedKeyOffset, 1},
+		{0, hardenedKeyOffset - 1, 2},
+		{0, 0, 0},
+	} {
+		_, err := DeriveKeyPairs(phrase, c.account, c.index, c.count)
+		assert.ErrorIs(t, err, ErrInvalidDerivationPath)
+	}
+}
+
+func TestDeriveKeyPairWithPassphrase(t *testing.T) {
+	phrase := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	t.Run("derives from the BIP39 seed of the passphrase", func(t *testing.T) {
+		// the BIP39 reference seed of this phrase with passphrase "TREZOR"
+		seed, err := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+		require.NoError(t, err)
+		key, err := deriveHDKey(seed, dagBIP44Path(0, 0)...)
+		require.NoError(t, err)
+
+		keyPair, err := KeyPairFromMnemonicWithPassphrase(phrase, "TREZOR")
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(key.key[:]), privateKeyHexOf(t, keyPair))
+		assert.Equal(t, "DAG1qjJUzEQjx7HshPbsRBAZBfQZ1jAVd2ScjaeM", keyPair.Address)
+	})
+
+	t.Run("an empty passphrase is the plain mnemonic", func(t *testing.T) {
+		plain, err := DeriveKeyPairs(phrase, 0, 0, 2)
+		require.NoError(t, err)
+		empty, err := DeriveKeyPairsWithPassphrase(phrase, "", 0, 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, plain, empty)
+		assert.Equal(t, "DAG35XRkcBSHPqT8h62hDzzJJ7YerwUPgqDzGm2P", plain[0].Address)
+	})
+
+	t.Run("every passphrase is a different wallet", func(t *testing.T) {
+		seen := map[string]bool{}
+		for _, passphrase := range []string{"", "TREZOR", "trezor", "TREZOR "} {
+			keyPair, err := DeriveKeyPairWithPassphrase(phrase, passphrase, 0, 1)
+			require.NoError(t, err)
+			seen[keyPair.Address] = true
+		}
+		assert.Len(t, seen, 4)
+	})
+
+	t.Run("validates the mnemonic and path", func(t *testing.T) {
+		_, err := KeyPairFromMnemonicWithPassphrase("abandon abandon", "TREZOR")
+		assert.ErrorIs(t, err, ErrInvalidMnemonic)
+		_, err = DeriveKeyPairWithPassphrase(phrase, "TREZOR", hardenedKeyOffset, 0)
+		assert.ErrorIs(t, err, ErrInvalidDerivationPath)
+	})
+}
@@ -0,0 +1,109 @@
+package constellation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multisigVectorsPath is written by cmd/gen-vectors
+var multisigVectorsPath = filepath.Join("..", "..", "shared", "multisig_vectors.json")
+
+type multisigVectors struct {
+	Signers []struct {
+		PrivateKeyHex string `json:"privateKeyHex"`
+		PublicKeyHex  string `json:"publicKeyHex"`
+		SignerID      string `json:"signerId"`
+		Address       string `json:"address"`
+	} `json:"signers"`
+	Scenarios []struct {
+		Name             string                   `json:"name"`
+		Threshold        int                      `json:"threshold"`
+		Authorized       []int                    `json:"authorized"`
+		SignedBy         []int                    `json:"signedBy"`
+		CompressedProofs []int                    `json:"compressedProofs"`
+		Transaction      CurrencyTransactionValue `json:"transaction"`
+		TransactionHash  string                   `json:"transactionHash"`
+		Proofs           []SignatureProof         `json:"proofs"`
+		Expected         struct {
+			AllProofsValid  bool `json:"allProofsValid"`
+			ValidProofs     int  `json:"validProofs"`
+			DistinctSigners int  `json:"distinctSigners"`
+			MeetsThreshold  bool `json:"meetsThreshold"`
+		} `json:"expected"`
+	} `json:"scenarios"`
+}
+
+func TestMultisigVectors(t *testing.T) {
+	data, err := os.ReadFile(multisigVectorsPath)
+	require.NoError(t, err)
+	var vectors multisigVectors
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	require.NotEmpty(t, vectors.Scenarios)
+
+	t.Run("derives the signers", func(t *testing.T) {
+		for _, signer := range vectors.Signers {
+			kp, err := KeyPairFromPrivateKey(signer.PrivateKeyHex)
+			require.NoError(t, err)
+			assert.Equal(t, signer.PublicKeyHex, kp.PublicKey)
+			assert.Equal(t, signer.SignerID, NormalizePublicKeyToID(kp.PublicKey))
+			assert.Equal(t, signer.Address, kp.Address)
+		}
+	})
+
+	for _, scenario := range vectors.Scenarios {
+		scenario := scenario
+		t.Run(scenario.Name, func(t *testing.T) {
+			tx := &CurrencyTransaction{Value: scenario.Transaction, Proofs: scenario.Proofs}
+			assert.Equal(t, scenario.TransactionHash, HashCurrencyTransaction(tx).Value)
+			require.Len(t, scenario.Proofs, len(scenario.SignedBy))
+
+			// nonces are deterministic, so re-signing reproduces each proof
+			compressed := map[int]bool{}
+			for _, position := range scenario.CompressedProofs {
+				compressed[position] = true
+			}
+			for i, index := range scenario.SignedBy {
+				signer := vectors.Signers[index]
+				signature, err := SignHash(scenario.TransactionHash, signer.PrivateKeyHex)
+				require.NoError(t, err)
+				id := signer.SignerID
+				if compressed[i] {
+					id, err = GetPublicKeyHex(signer.PrivateKeyHex, true)
+					require.NoError(t, err)
+				}
+				assert.Equal(t, SignatureProof{ID: id, Signature: signature}, scenario.Proofs[i])
+			}
+
+			result := VerifyCurrencyTransaction(tx)
+			assert.Equal(t, scenario.Expected.AllProofsValid, result.IsValid)
+			assert.Len(t, result.ValidProofs, scenario.Expected.ValidProofs)
+
+			authorized := map[string]bool{}
+			for _, index := range scenario.Authorized {
+				authorized[vectors.Signers[index].SignerID] = true
+			}
+			distinct := map[string]bool{}
+			for _, id := range DistinctSigners(result.ValidProofs) {
+				if authorized[id] {
+					distinct[id] = true
+				}
+			}
+			assert.Equal(t, scenario.Expected.DistinctSigners, len(distinct))
+			assert.Equal(t, scenario.Expected.MeetsThreshold, len(distinct) >= scenario.Threshold)
+
+			// proof order never changes the hash or the outcome
+			reversed := make([]SignatureProof, len(tx.Proofs))
+			for i, proof := range tx.Proofs {
+				reversed[len(reversed)-1-i] = proof
+			}
+			reordered := &CurrencyTransaction{Value: tx.Value, Proofs: reversed}
+			assert.Equal(t, scenario.TransactionHash, HashCurrencyTransaction(reordered).Value)
+			assert.Equal(t, result.IsValid, VerifyCurrencyTransaction(reordered).IsValid)
+		})
+	}
+}
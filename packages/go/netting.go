@@ -0,0 +1,192 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInvalidPairTransfer indicates a transfer the netting planner cannot use
+var ErrInvalidPairTransfer = errors.New("invalid transfer for netting")
+
+// PairTransfer is a transfer between two addresses, in smallest units
+// (1e-8)
+type PairTransfer struct {
+	Source      string
+	Destination string
+	Amount      int64
+}
+
+// NettingPlan is the result of netting a batch of transfers
+type NettingPlan struct {
+	// Transfers are the net transfers, one per address pair at most, in the
+	// order each pair first appeared
+	Transfers []PairTransfer
+	// InputCount is the number of transfers that were netted
+	InputCount int
+	// GrossVolume and NetVolume are the total amounts before and after
+	// netting
+	GrossVolume int64
+	NetVolume   int64
+}
+
+// Sources returns the addresses the net transfers are sent from, in order
+func (p NettingPlan) Sources() []string {
+	var sources []string
+	seen := map[string]bool{}
+	for _, transfer := range p.Transfers {
+		if !seen[transfer.Source] {
+			seen[transfer.Source] = true
+			sources = append(sources, transfer.Source)
+		}
+	}
+	return sources
+}
+
+// TransfersFrom returns the net transfers sent from source as
+// TransferParams for BuildBatch, each paying fee smallest units
+func (p NettingPlan) TransfersFrom(source string, fee int64) []TransferParams {
+	var transfers []TransferParams
+	for _, transfer := range p.Transfers {
+		if transfer.Source == source {
+			transfers = append(transfers, TransferParams{
+				Destination: transfer.Destination,
+				Amount:      UnitsToToken(transfer.Amount),
+				Fee:         UnitsToToken(fee),
+				ExactAmount: AmountFromUnits(transfer.Amount),
+				ExactFee:    AmountFromUnits(fee),
+			})
+		}
+	}
+	return transfers
+}
+
+// NetTransfers nets offsetting transfers between the same two addresses:
+// transfers in the same direction are summed, and A to B is offset against
+// B to A, leaving one transfer of the difference. Pairs that cancel out
+// exactly need no transaction at all. Returns ErrInvalidPairTransfer for a
+// non-positive amount or a transfer to its own source, and
+// ErrAmountOutOfRange when a total overflows.
+//
+// Example:
+//
+//	plan, err := constellation.NetTransfers([]constellation.PairTransfer{
+//	    {Source: a, Destination: b, Amount: constellation.TokenToUnits(10)},
+//	    {Source: b, Destination: a, Amount: constellation.TokenToUnits(4)},
+//	})
+//	// plan.Transfers == [{a, b, 6 DAG}]
+//	for _, source := range plan.Sources() {
+//	    txs, err := constellation.BuildBatch(constellation.NewTransactionBuilder(source),
+//	        plan.TransfersFrom(source, 0), lastRefs[source])
+//	}
+func NetTransfers(transfers []PairTransfer) (NettingPlan, error) {
+	type pairKey struct{ low, high string }
+	// balances hold what low owes high; negative when high owes low
+	balances := map[pairKey]int64{}
+	var order []pairKey
+	plan := NettingPlan{InputCount: len(transfers)}
+
+	for i, transfer := range transfers {
+		if transfer.Amount < 1 || transfer.Source == transfer.Destination {
+			return NettingPlan{}, fmt.Errorf("%w: transfer %d", ErrInvalidPairTransfer, i)
+		}
+		gross, err := EffectiveDebit(plan.GrossVolume, transfer.Amount)
+		if err != nil {
+			return NettingPlan{}, err
+		}
+		plan.GrossVolume = gross
+
+		key, amount := pairKey{transfer.Source, transfer.Destination}, transfer.Amount
+		if key.high < key.low {
+			key, amount = pairKey{transfer.Destination, transfer.Source}, -amount
+		}
+		balance, ok := balances[key]
+		if !ok {
+			order = append(order, key)
+		}
+		// the gross volume bounds every balance, so this cannot overflow
+		balances[key] = balance + amount
+	}
+
+	for _, key := range order {
+		switch balance := balances[key]; {
+		case balance > 0:
+			plan.Transfers = append(plan.Transfers, PairTransfer{Source: key.low, Destination: key.high, Amount: balance})
+			plan.NetVolume += balance
+		case balance < 0:
+			plan.Transfers = append(plan.Transfers, PairTransfer{Source: key.high, Destination: key.low, Amount: -balance})
+			plan.NetVolume -= balance
+		}
+	}
+	return plan, nil
+}
+
+// NettingWindow collects transfers over a batch window so offsetting ones
+// can be netted before anything is built. It is safe for concurrent use.
+//
+// Example:
+//
+//	window := constellation.NewNettingWindow(30 * time.Second)
+//	window.Add(constellation.PairTransfer{Source: a, Destination: b, Amount: amount})
+//	...
+//	if window.Due() {
+//	    plan, err := window.Flush()
+//	}
+type NettingWindow struct {
+	duration time.Duration
+
+	mu      sync.Mutex
+	opened  time.Time
+	pending []PairTransfer
+}
+
+// NewNettingWindow creates a window that is due duration after its first
+// transfer
+func NewNettingWindow(duration time.Duration) *NettingWindow {
+	return &NettingWindow{duration: duration}
+}
+
+// Add queues a transfer for the current window
+func (w *NettingWindow) Add(transfer PairTransfer) error {
+	if transfer.Amount < 1 || transfer.Source == transfer.Destination {
+		return ErrInvalidPairTransfer
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		w.opened = time.Now()
+	}
+	w.pending = append(w.pending, transfer)
+	return nil
+}
+
+// Len returns the number of transfers waiting in the window
+func (w *NettingWindow) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Due reports whether the window has transfers and its duration has passed
+// since the first of them
+func (w *NettingWindow) Due() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending) > 0 && time.Since(w.opened) >= w.duration
+}
+
+// Flush nets the waiting transfers and starts a new window. On error the
+// transfers stay queued.
+func (w *NettingWindow) Flush() (NettingPlan, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	plan, err := NetTransfers(w.pending)
+	if err != nil {
+		return NettingPlan{}, err
+	}
+	w.pending = nil
+	return plan, nil
+}
@@ -0,0 +1,89 @@
+package constellation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetTransfers(t *testing.T) {
+	a, b, c := "DAGaaa", "DAGbbb", "DAGccc"
+
+	t.Run("nets offsetting and sums same-direction transfers", func(t *testing.T) {
+		plan, err := NetTransfers([]PairTransfer{
+			{Source: b, Destination: a, Amount: 100},
+			{Source: a, Destination: c, Amount: 30},
+			{Source: a, Destination: b, Amount: 250},
+			{Source: c, Destination: a, Amount: 30},
+			{Source: b, Destination: c, Amount: 5},
+			{Source: b, Destination: c, Amount: 7},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []PairTransfer{
+			{Source: a, Destination: b, Amount: 150},
+			{Source: b, Destination: c, Amount: 12},
+		}, plan.Transfers)
+		assert.Equal(t, 6, plan.InputCount)
+		assert.Equal(t, int64(422), plan.GrossVolume)
+		assert.Equal(t, int64(162), plan.NetVolume)
+
+		assert.Equal(t, []string{a, b}, plan.Sources())
+		assert.Equal(t, []TransferParams{{
+			Destination: c,
+			Amount:      UnitsToToken(12),
+			Fee:         UnitsToToken(10000000),
+			ExactAmount: AmountFromUnits(12),
+			ExactFee:    AmountFromUnits(10000000),
+		}}, plan.TransfersFrom(b, 10000000))
+		assert.Empty(t, plan.TransfersFrom(c, 0))
+	})
+
+	t.Run("builds the net amounts exactly", func(t *testing.T) {
+		sender, err := GenerateKeyPair()
+		require.NoError(t, err)
+		recipient, err := GenerateKeyPair()
+		require.NoError(t, err)
+		plan, err := NetTransfers([]PairTransfer{{Source: sender.Address, Destination: recipient.Address, Amount: 59}})
+		require.NoError(t, err)
+		txs, err := BuildBatch(NewTransactionBuilder(sender.Address), plan.TransfersFrom(sender.Address, 1), GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, int64(59), txs[0].Value.Amount)
+		assert.Equal(t, int64(1), txs[0].Value.Fee)
+	})
+
+	t.Run("rejects invalid transfers", func(t *testing.T) {
+		_, err := NetTransfers([]PairTransfer{{Source: a, Destination: b, Amount: 0}})
+		assert.ErrorIs(t, err, ErrInvalidPairTransfer)
+		_, err = NetTransfers([]PairTransfer{{Source: a, Destination: a, Amount: 1}})
+		assert.ErrorIs(t, err, ErrInvalidPairTransfer)
+		_, err = NetTransfers([]PairTransfer{
+			{Source: a, Destination: b, Amount: math.MaxInt64},
+			{Source: b, Destination: a, Amount: 1},
+		})
+		assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	})
+}
+
+func TestNettingWindow(t *testing.T) {
+	window := NewNettingWindow(20 * time.Millisecond)
+	assert.False(t, window.Due(), "an empty window is never due")
+
+	require.NoError(t, window.Add(PairTransfer{Source: "DAGa", Destination: "DAGb", Amount: 10}))
+	require.NoError(t, window.Add(PairTransfer{Source: "DAGb", Destination: "DAGa", Amount: 10}))
+	assert.ErrorIs(t, window.Add(PairTransfer{Source: "DAGa", Destination: "DAGb"}), ErrInvalidPairTransfer)
+	assert.Equal(t, 2, window.Len())
+	assert.False(t, window.Due())
+
+	time.Sleep(25 * time.Millisecond)
+	assert.True(t, window.Due())
+
+	plan, err := window.Flush()
+	require.NoError(t, err)
+	assert.Empty(t, plan.Transfers, "the transfers cancel out")
+	assert.Equal(t, 2, plan.InputCount)
+	assert.Equal(t, 0, window.Len())
+	assert.False(t, window.Due())
+}
@@ -0,0 +1,54 @@
+package network
+
+import (
+	"sync"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// bulkLookupConcurrency bounds the in-flight requests of bulk lookups such as
+// GetLastReferences and GetBalances
+const bulkLookupConcurrency = 16
+
+// batchLookup calls fetch for each distinct address with at most concurrency
+// calls in flight
+func batchLookup[T any](op string, addresses []string, concurrency int, fetch func(string) (*T, error)) (map[string]*T, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		results  = make(map[string]*T, len(addresses))
+		failures = map[string]error{}
+		sem      = make(chan struct{}, concurrency)
+		seen     = make(map[string]bool, len(addresses))
+	)
+
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(address string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := fetch(address)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[address] = err
+				return
+			}
+			results[address] = result
+		}(address)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return results, &constellation.BatchLookupError{Op: op, Errors: failures}
+	}
+	return results, nil
+}
@@ -0,0 +1,138 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// DefaultCoinGeckoURL is the public CoinGecko API
+const DefaultCoinGeckoURL = "https://api.coingecko.com/api/v3"
+
+const defaultCoinGeckoTodayTTL = time.Hour
+
+// CoinGeckoConfig holds configuration for a CoinGeckoRateProvider
+type CoinGeckoConfig struct {
+	// BaseURL of the CoinGecko API (default: DefaultCoinGeckoURL)
+	BaseURL string
+	// CoinIDs maps token symbols to CoinGecko coin IDs. DAG is mapped to
+	// "constellation-labs" unless overridden.
+	CoinIDs map[string]string
+	// Timeout in seconds (default: 30)
+	Timeout int
+	// UserAgent identifies the integration to CoinGecko (default: DefaultUserAgent)
+	UserAgent string
+	// RequestID generates the X-Request-ID header for each request (default: random)
+	RequestID func() string
+	// TodayTTL is how long a rate for the current UTC day is cached, since
+	// CoinGecko revises it until the day ends (default: 1h)
+	TodayTTL time.Duration
+}
+
+// CoinGeckoRateProvider is a reference ExchangeRateProvider backed by the
+// CoinGecko daily history endpoint. Rates have daily granularity and are
+// cached per asset, currency and UTC day once found; the current day's rate
+// is refetched after TodayTTL.
+//
+// Example:
+//
+//	rates := NewCoinGeckoRateProvider(CoinGeckoConfig{})
+//	rate, err := rates.Rate("DAG", "usd", paidAt)
+//	if rate != nil {
+//	    fmt.Printf("$%.2f\n", rate.FiatValue(amountUnits))
+//	}
+type CoinGeckoRateProvider struct {
+	client   *HTTPClient
+	coinIDs  map[string]string
+	todayTTL time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]coinGeckoEntry
+}
+
+// coinGeckoEntry is a cached rate; a zero expires never expires
+type coinGeckoEntry struct {
+	rate    *constellation.ExchangeRate
+	expires time.Time
+}
+
+// NewCoinGeckoRateProvider creates a new CoinGeckoRateProvider
+func NewCoinGeckoRateProvider(config CoinGeckoConfig) *CoinGeckoRateProvider {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultCoinGeckoURL
+	}
+	if config.TodayTTL <= 0 {
+		config.TodayTTL = defaultCoinGeckoTodayTTL
+	}
+	coinIDs := map[string]string{"DAG": "constellation-labs"}
+	for symbol, id := range config.CoinIDs {
+		coinIDs[strings.ToUpper(symbol)] = id
+	}
+
+	return &CoinGeckoRateProvider{
+		client: newNetworkHTTPClient(config.BaseURL, constellation.NetworkConfig{
+			Timeout:   config.Timeout,
+			UserAgent: config.UserAgent,
+			RequestID: config.RequestID,
+		}),
+		coinIDs:  coinIDs,
+		todayTTL: config.TodayTTL,
+		now:      time.Now,
+		cache:    map[string]coinGeckoEntry{},
+	}
+}
+
+// Rate returns the daily price of asset in fiat on the UTC day containing at
+func (p *CoinGeckoRateProvider) Rate(asset string, fiat string, at time.Time) (*constellation.ExchangeRate, error) {
+	asset = strings.ToUpper(asset)
+	fiat = strings.ToLower(fiat)
+	coinID, ok := p.coinIDs[asset]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", constellation.ErrUnknownAsset, asset)
+	}
+
+	day := at.UTC().Truncate(24 * time.Hour)
+	key := asset + "/" + fiat + "/" + day.Format("2006-01-02")
+
+	now := p.now()
+	p.mu.Lock()
+	cached, hit := p.cache[key]
+	p.mu.Unlock()
+	if hit && (cached.expires.IsZero() || now.Before(cached.expires)) {
+		return cached.rate, nil
+	}
+
+	var result struct {
+		MarketData *struct {
+			CurrentPrice map[string]float64 `json:"current_price"`
+		} `json:"market_data"`
+	}
+	path := fmt.Sprintf("/coins/%s/history?date=%s&localization=false", coinID, day.Format("02-01-2006"))
+	if err := p.client.Get(path, &result); err != nil {
+		return nil, wrapOp("exchangeRate", "", p.client.endpoint(http.MethodGet, path), err)
+	}
+
+	if result.MarketData == nil {
+		return nil, nil
+	}
+	price, ok := result.MarketData.CurrentPrice[fiat]
+	if !ok {
+		return nil, nil
+	}
+
+	rate := &constellation.ExchangeRate{Asset: asset, Fiat: fiat, Rate: price, At: day}
+	entry := coinGeckoEntry{rate: rate}
+	if !now.UTC().Truncate(24 * time.Hour).After(day) {
+		// the day is not over, so its price may still change
+		entry.expires = now.Add(p.todayTTL)
+	}
+	p.mu.Lock()
+	p.cache[key] = entry
+	p.mu.Unlock()
+	return rate, nil
+}
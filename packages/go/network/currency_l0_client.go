@@ -0,0 +1,101 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// CurrencyL0Client is a client for interacting with metagraph L0 nodes
+//
+// Example:
+//
+//	config := constellation.NetworkConfig{L0URL: "http://localhost:9200"}
+//	client, err := NewCurrencyL0Client(config)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Get the token balance of an address
+//	balance, err := client.GetBalance("DAG...")
+//
+//	// Get a balance as of a specific snapshot
+//	balance, err = client.GetBalanceAt("DAG...", 1520)
+//
+//	// Refresh many addresses at once
+//	balances, err := client.GetBalances(addresses)
+type CurrencyL0Client struct {
+	client *HTTPClient
+}
+
+// NewCurrencyL0Client creates a new CurrencyL0Client
+//
+// Returns an error if L0URL is not provided in the config
+func NewCurrencyL0Client(config constellation.NetworkConfig) (*CurrencyL0Client, error) {
+	if config.L0URL == "" {
+		return nil, constellation.ErrL0URLRequired
+	}
+
+	client := newNetworkHTTPClient(config.L0URL, config)
+	return &CurrencyL0Client{client: client}, nil
+}
+
+// GetBalance gets the token balance of an address at the latest snapshot
+func (c *CurrencyL0Client) GetBalance(address string) (*constellation.BalanceResponse, error) {
+	var result constellation.BalanceResponse
+	path := fmt.Sprintf("/currency/%s/balance", address)
+	if err := c.client.Get(path, &result); err != nil {
+		return nil, wrapOp("getBalance", address, c.client.endpoint(http.MethodGet, path), err)
+	}
+	return &result, nil
+}
+
+// GetBalanceAt gets the token balance of an address as of the snapshot at
+// ordinal, for statements that must reflect a specific point in the chain
+//
+// The balance is read from the snapshot info served by
+// /snapshots/{ordinal}/combined; an address absent from the snapshot has a
+// zero balance. Returns nil if the node does not have that snapshot (not yet
+// produced, or pruned).
+func (c *CurrencyL0Client) GetBalanceAt(address string, ordinal int64) (*constellation.BalanceResponse, error) {
+	var combined []json.RawMessage
+	path := fmt.Sprintf("/snapshots/%d/combined", ordinal)
+	if err := c.client.Get(path, &combined); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, wrapOp("getBalanceAt", address, c.client.endpoint(http.MethodGet, path), err)
+	}
+	if len(combined) != 2 {
+		return nil, wrapOp("getBalanceAt", address, c.client.endpoint(http.MethodGet, path),
+			constellation.NewNetworkError("unexpected combined snapshot response", 0, ""))
+	}
+
+	var info struct {
+		Balances map[string]int64 `json:"balances"`
+	}
+	if err := json.Unmarshal(combined[1], &info); err != nil {
+		return nil, wrapOp("getBalanceAt", address, c.client.endpoint(http.MethodGet, path),
+			constellation.NewNetworkError(fmt.Sprintf("failed to parse snapshot info: %v", err), 0, ""))
+	}
+
+	return &constellation.BalanceResponse{Ordinal: ordinal, Balance: info.Balances[address]}, nil
+}
+
+// GetBalances gets the token balances of many addresses, issuing up to 16
+// requests concurrently
+//
+// The returned map holds every successful lookup. If any lookup fails the
+// error is a *BatchLookupError listing the failed addresses, and the map
+// still contains the rest.
+func (c *CurrencyL0Client) GetBalances(addresses []string) (map[string]*constellation.BalanceResponse, error) {
+	return batchLookup("balance", addresses, bulkLookupConcurrency, c.GetBalance)
+}
+
+// CheckHealth checks the health/availability of the L0 node
+func (c *CurrencyL0Client) CheckHealth() bool {
+	var result interface{}
+	return c.client.Get("/cluster/info", &result) == nil
+}
@@ -0,0 +1,118 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// CurrencyL1Client is a client for interacting with Currency L1 nodes
+//
+// Example:
+//
+//	config := constellation.NetworkConfig{L1URL: "http://localhost:9010"}
+//	client, err := NewCurrencyL1Client(config)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Get last reference for an address
+//	lastRef, err := client.GetLastReference("DAG...")
+//
+//	// Submit a transaction
+//	result, err := client.PostTransaction(signedTx)
+//
+//	// Check transaction status
+//	pending, err := client.GetPendingTransaction(result.Hash)
+type CurrencyL1Client struct {
+	client   *HTTPClient
+	features *featureGate
+	errors   *constellation.ErrorLog
+}
+
+// NewCurrencyL1Client creates a new CurrencyL1Client
+//
+// Returns an error if L1URL is not provided in the config
+func NewCurrencyL1Client(config constellation.NetworkConfig) (*CurrencyL1Client, error) {
+	if config.L1URL == "" {
+		return nil, constellation.ErrL1URLRequired
+	}
+
+	client := newNetworkHTTPClient(config.L1URL, config)
+	return &CurrencyL1Client{client: client, features: &featureGate{client: client}, errors: constellation.NewErrorLog()}, nil
+}
+
+// GetLastReference gets the last accepted transaction reference for an address
+//
+// This is needed to create a new transaction that chains from
+// the address's most recent transaction.
+func (c *CurrencyL1Client) GetLastReference(address string) (*constellation.TransactionReference, error) {
+	var result constellation.TransactionReference
+	path := fmt.Sprintf("/transactions/last-reference/%s", address)
+	if err := c.client.Get(path, &result); err != nil {
+		return nil, c.errors.Record(wrapOp("getLastReference", address, c.client.endpoint(http.MethodGet, path), err))
+	}
+	return &result, nil
+}
+
+// GetLastReferences gets the last accepted transaction reference for many
+// addresses, issuing up to 16 requests concurrently
+//
+// The returned map holds every successful lookup. If any lookup fails the
+// error is a *BatchLookupError listing the failed addresses, and the map
+// still contains the rest.
+func (c *CurrencyL1Client) GetLastReferences(addresses []string) (map[string]*constellation.TransactionReference, error) {
+	return batchLookup("last reference", addresses, bulkLookupConcurrency, c.GetLastReference)
+}
+
+// PostTransaction submits a signed currency transaction to the L1 network
+func (c *CurrencyL1Client) PostTransaction(transaction *constellation.CurrencyTransaction) (*constellation.PostTransactionResponse, error) {
+	var result constellation.PostTransactionResponse
+	if err := c.client.Post("/transactions", transaction, &result); err != nil {
+		return nil, c.errors.Record(wrapOp("postTransaction", transaction.Value.Source, c.client.endpoint(http.MethodPost, "/transactions"), err))
+	}
+	result.Node = c.client.baseURL
+	return &result, nil
+}
+
+// GetPendingTransaction gets a pending transaction by hash
+//
+// Use this to poll for transaction status after submission.
+// Returns nil if the transaction is not found (already confirmed or invalid).
+func (c *CurrencyL1Client) GetPendingTransaction(hash string) (*constellation.PendingTransaction, error) {
+	var result constellation.PendingTransaction
+	path := fmt.Sprintf("/transactions/%s", hash)
+	if err := c.client.Get(path, &result); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, c.errors.Record(wrapOp("getPendingTransaction", "", c.client.endpoint(http.MethodGet, path), err))
+	}
+	return &result, nil
+}
+
+// CheckHealth checks the health/availability of the L1 node
+func (c *CurrencyL1Client) CheckHealth() bool {
+	var result interface{}
+	return c.client.Get("/cluster/info", &result) == nil
+}
+
+// GetNodeInfo gets the node's identity and Tessellation version
+//
+// The result is cached for the lifetime of the client, and a failure for
+// 30 seconds.
+func (c *CurrencyL1Client) GetNodeInfo() (*constellation.NodeInfo, error) {
+	return c.features.nodeInfo()
+}
+
+// SupportsFeature reports whether the connected node serves an optional endpoint
+func (c *CurrencyL1Client) SupportsFeature(feature constellation.Feature) bool {
+	return c.features.supports(feature)
+}
+
+// RecentErrors returns the client's latest errors, oldest first, for
+// CaptureDiagnostics
+func (c *CurrencyL1Client) RecentErrors() []constellation.RecordedError {
+	return c.errors.Recent()
+}
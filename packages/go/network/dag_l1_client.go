@@ -0,0 +1,87 @@
+package network
+
+import constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+
+// DAGL1Client is a client for the global DAG L1, which accepts transactions
+// of the native DAG token. It serves the same transaction endpoints as a
+// Currency L1, so it implements CurrencyL1API and works with the withdrawal
+// queue and the other transaction helpers. DAG balances come from the
+// global L0 (see GlobalL0Client.GetBalance).
+//
+// Example:
+//
+//	config := constellation.NetworkConfig{DAGL1URL: "https://l1-lb-mainnet.constellationnetwork.io"}
+//	client, err := NewDAGL1Client(config)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	lastRef, err := client.GetLastReference(keyPair.Address)
+//	tx, err := constellation.CreateDAGTransaction(params, keyPair.PrivateKey, *lastRef)
+//	result, err := client.PostTransaction(tx)
+type DAGL1Client struct {
+	l1 *CurrencyL1Client
+}
+
+// NewDAGL1Client creates a new DAGL1Client
+//
+// Returns an error if DAGL1URL is not provided in the config
+func NewDAGL1Client(config constellation.NetworkConfig) (*DAGL1Client, error) {
+	if config.DAGL1URL == "" {
+		return nil, constellation.ErrDAGL1URLRequired
+	}
+
+	config.L1URL = config.DAGL1URL
+	l1, err := NewCurrencyL1Client(config)
+	if err != nil {
+		return nil, err
+	}
+	return &DAGL1Client{l1: l1}, nil
+}
+
+// GetLastReference gets the last accepted DAG transaction reference for an
+// address
+func (c *DAGL1Client) GetLastReference(address string) (*constellation.TransactionReference, error) {
+	return c.l1.GetLastReference(address)
+}
+
+// GetLastReferences gets the last accepted DAG transaction reference for
+// many addresses, issuing up to 16 requests concurrently
+func (c *DAGL1Client) GetLastReferences(addresses []string) (map[string]*constellation.TransactionReference, error) {
+	return c.l1.GetLastReferences(addresses)
+}
+
+// PostTransaction submits a signed DAG transaction to the DAG L1 network
+func (c *DAGL1Client) PostTransaction(transaction *constellation.DAGTransaction) (*constellation.PostTransactionResponse, error) {
+	return c.l1.PostTransaction(transaction)
+}
+
+// GetPendingTransaction gets a pending DAG transaction by hash
+//
+// Returns nil if the transaction is not found (already confirmed or invalid).
+func (c *DAGL1Client) GetPendingTransaction(hash string) (*constellation.PendingTransaction, error) {
+	return c.l1.GetPendingTransaction(hash)
+}
+
+// CheckHealth checks the health/availability of the DAG L1 node
+func (c *DAGL1Client) CheckHealth() bool {
+	return c.l1.CheckHealth()
+}
+
+// GetNodeInfo gets the node's identity and Tessellation version
+//
+// The result is cached for the lifetime of the client.
+func (c *DAGL1Client) GetNodeInfo() (*constellation.NodeInfo, error) {
+	return c.l1.GetNodeInfo()
+}
+
+// SupportsFeature reports whether the connected node serves an optional endpoint
+func (c *DAGL1Client) SupportsFeature(feature constellation.Feature) bool {
+	return c.l1.SupportsFeature(feature)
+}
+
+// RecentErrors returns the client's latest errors, oldest first, for
+// CaptureDiagnostics
+func (c *DAGL1Client) RecentErrors() []constellation.RecordedError {
+	return c.l1.RecentErrors()
+}
@@ -0,0 +1,87 @@
+package network
+
+import (
+	"net/http"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// DataL1Client is a client for interacting with Data L1 nodes (metagraphs)
+//
+// Example:
+//
+//	config := constellation.NetworkConfig{DataL1URL: "http://localhost:8080"}
+//	client, err := NewDataL1Client(config)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Estimate fee for data submission
+//	feeInfo, err := client.EstimateFee(signedData)
+//
+//	// Submit data
+//	result, err := client.PostData(signedData)
+type DataL1Client struct {
+	client   *HTTPClient
+	features *featureGate
+}
+
+// NewDataL1Client creates a new DataL1Client
+//
+// Returns an error if DataL1URL is not provided in the config
+func NewDataL1Client(config constellation.NetworkConfig) (*DataL1Client, error) {
+	if config.DataL1URL == "" {
+		return nil, constellation.ErrDataL1URLRequired
+	}
+
+	client := newNetworkHTTPClient(config.DataL1URL, config)
+	return &DataL1Client{client: client, features: &featureGate{client: client}}, nil
+}
+
+// EstimateFee estimates the fee for submitting data
+//
+// Some metagraphs charge fees for data submissions.
+// Call this before PostData to know the required fee.
+// Returns ErrFeatureUnsupported if the node predates the endpoint.
+func (c *DataL1Client) EstimateFee(data interface{}) (*constellation.EstimateFeeResponse, error) {
+	if !c.features.supports(constellation.FeatureEstimateFee) {
+		return nil, constellation.ErrFeatureUnsupported
+	}
+
+	var result constellation.EstimateFeeResponse
+	if err := c.client.Post("/data/estimate-fee", data, &result); err != nil {
+		if isNotFound(err) {
+			return nil, constellation.ErrFeatureUnsupported
+		}
+		return nil, wrapOp("estimateFee", "", c.client.endpoint(http.MethodPost, "/data/estimate-fee"), err)
+	}
+	return &result, nil
+}
+
+// PostData submits signed data to the Data L1 node
+func (c *DataL1Client) PostData(data interface{}) (*constellation.PostDataResponse, error) {
+	var result constellation.PostDataResponse
+	if err := c.client.Post("/data", data, &result); err != nil {
+		return nil, wrapOp("postData", "", c.client.endpoint(http.MethodPost, "/data"), err)
+	}
+	return &result, nil
+}
+
+// CheckHealth checks the health/availability of the Data L1 node
+func (c *DataL1Client) CheckHealth() bool {
+	var result interface{}
+	return c.client.Get("/cluster/info", &result) == nil
+}
+
+// GetNodeInfo gets the node's identity and Tessellation version
+//
+// The result is cached for the lifetime of the client, and a failure for
+// 30 seconds.
+func (c *DataL1Client) GetNodeInfo() (*constellation.NodeInfo, error) {
+	return c.features.nodeInfo()
+}
+
+// SupportsFeature reports whether the connected node serves an optional endpoint
+func (c *DataL1Client) SupportsFeature(feature constellation.Feature) bool {
+	return c.features.supports(feature)
+}
@@ -0,0 +1,275 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const defaultEndpointRefreshInterval = 5 * time.Minute
+
+var (
+	// ErrNoEndpointsDiscovered indicates discovery found no endpoint for any layer
+	ErrNoEndpointsDiscovered = errors.New("no endpoints discovered")
+	// ErrEndpointResolverRequired indicates EndpointDiscovery was configured without a resolver
+	ErrEndpointResolverRequired = errors.New("endpoint discovery requires a resolver")
+	// ErrDomainRequired indicates a DNSEndpointResolver without a domain
+	ErrDomainRequired = errors.New("domain is required for DNS endpoint discovery")
+)
+
+// SRV service names queried by DNSEndpointResolver, e.g. _l1._tcp.<domain>
+const (
+	SRVServiceL0     = "l0"
+	SRVServiceL1     = "l1"
+	SRVServiceDataL1 = "data-l1"
+)
+
+// DNSLookup is the subset of *net.Resolver used for endpoint discovery
+type DNSLookup interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DNSEndpointResolver discovers endpoints from DNS records on Domain:
+//
+//   - SRV records _l0._tcp, _l1._tcp and _data-l1._tcp, each becoming
+//     Scheme://target:port, in the priority and weight order DNS returns
+//   - TXT records on Domain of the form "l0=URL", "l1=URL" or
+//     "data-l1=URL", for endpoints that need a full URL (e.g. a path or
+//     https on a non-default port)
+//
+// A layer with no records is left empty; missing records are not an error.
+//
+// Example:
+//
+//	resolver := &DNSEndpointResolver{Domain: "nodes.example.com"}
+//	endpoints, err := resolver.Resolve(ctx)
+type DNSEndpointResolver struct {
+	// Domain holds the SRV and TXT records
+	Domain string
+	// Scheme is used for SRV endpoints (default: "http")
+	Scheme string
+	// Lookup performs the DNS queries (default: net.DefaultResolver)
+	Lookup DNSLookup
+}
+
+// Resolve queries the SRV and TXT records of Domain
+//
+// Returns ErrNoEndpointsDiscovered if no layer has an endpoint.
+func (r *DNSEndpointResolver) Resolve(ctx context.Context) (*constellation.Endpoints, error) {
+	if r.Domain == "" {
+		return nil, ErrDomainRequired
+	}
+	lookup := r.Lookup
+	if lookup == nil {
+		lookup = net.DefaultResolver
+	}
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	endpoints := &constellation.Endpoints{}
+	layers := map[string]*[]string{
+		SRVServiceL0:     &endpoints.L0,
+		SRVServiceL1:     &endpoints.L1,
+		SRVServiceDataL1: &endpoints.DataL1,
+	}
+
+	for _, service := range []string{SRVServiceL0, SRVServiceL1, SRVServiceDataL1} {
+		_, records, err := lookup.LookupSRV(ctx, service, "tcp", r.Domain)
+		if err != nil && !isDNSNotFound(err) {
+			return nil, fmt.Errorf("SRV lookup _%s._tcp.%s: %w", service, r.Domain, err)
+		}
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			url := scheme + "://" + net.JoinHostPort(host, strconv.Itoa(int(record.Port)))
+			*layers[service] = appendUnique(*layers[service], url)
+		}
+	}
+
+	records, err := lookup.LookupTXT(ctx, r.Domain)
+	if err != nil && !isDNSNotFound(err) {
+		return nil, fmt.Errorf("TXT lookup %s: %w", r.Domain, err)
+	}
+	for _, record := range records {
+		key, value, ok := strings.Cut(strings.TrimSpace(record), "=")
+		if !ok {
+			continue
+		}
+		if layer, known := layers[strings.TrimSpace(key)]; known && value != "" {
+			*layer = appendUnique(*layer, strings.TrimSuffix(strings.TrimSpace(value), "/"))
+		}
+	}
+
+	if len(endpoints.L0) == 0 && len(endpoints.L1) == 0 && len(endpoints.DataL1) == 0 {
+		return nil, ErrNoEndpointsDiscovered
+	}
+	return endpoints, nil
+}
+
+func isDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// EndpointDiscoveryConfig holds configuration for EndpointDiscovery
+type EndpointDiscoveryConfig struct {
+	// Resolver discovers the endpoints, usually a DNSEndpointResolver
+	Resolver constellation.EndpointResolver
+	// RefreshInterval is the delay between refreshes (default: 5m)
+	RefreshInterval time.Duration
+	// OnChange is called after a refresh that changed the endpoints
+	OnChange func(endpoints constellation.Endpoints)
+	// OnError receives refresh failures; the last good endpoints are kept
+	OnError func(err error)
+}
+
+// EndpointDiscovery keeps a periodically refreshed view of the node
+// endpoints, so rotating nodes only needs a DNS change rather than a config
+// redeploy. A failed refresh keeps the previous endpoints.
+//
+// EndpointDiscovery implements the Service lifecycle.
+//
+// Example:
+//
+//	discovery, err := NewEndpointDiscovery(ctx, EndpointDiscoveryConfig{
+//	    Resolver: &DNSEndpointResolver{Domain: "nodes.example.com"},
+//	    OnChange: func(e constellation.Endpoints) { log.Println("L1 nodes now", e.L1) },
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer discovery.Close()
+//
+//	client, err := NewCurrencyL1Client(discovery.NetworkConfig(constellation.NetworkConfig{Timeout: 10}))
+type EndpointDiscovery struct {
+	serviceState
+
+	ctx    context.Context
+	config EndpointDiscoveryConfig
+	stop   chan struct{}
+	once   sync.Once
+
+	mu        sync.RWMutex
+	endpoints constellation.Endpoints
+}
+
+// NewEndpointDiscovery resolves the endpoints once and then refreshes them
+// in the background
+//
+// Returns the resolver's error if the initial resolution fails.
+func NewEndpointDiscovery(ctx context.Context, config EndpointDiscoveryConfig) (*EndpointDiscovery, error) {
+	if config.Resolver == nil {
+		return nil, ErrEndpointResolverRequired
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = defaultEndpointRefreshInterval
+	}
+
+	endpoints, err := config.Resolver.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &EndpointDiscovery{
+		ctx:       ctx,
+		config:    config,
+		stop:      make(chan struct{}),
+		endpoints: *endpoints,
+	}
+	d.start()
+
+	go d.run()
+	return d, nil
+}
+
+// Endpoints returns the most recently discovered endpoints
+func (d *EndpointDiscovery) Endpoints() constellation.Endpoints {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return constellation.Endpoints{
+		L0:     append([]string(nil), d.endpoints.L0...),
+		L1:     append([]string(nil), d.endpoints.L1...),
+		DataL1: append([]string(nil), d.endpoints.DataL1...),
+	}
+}
+
+// NetworkConfig returns base with L0URL, L1URL and DataL1URL set to the
+// first discovered endpoint of each layer that has one
+func (d *EndpointDiscovery) NetworkConfig(base constellation.NetworkConfig) constellation.NetworkConfig {
+	endpoints := d.Endpoints()
+	if len(endpoints.L0) > 0 {
+		base.L0URL = endpoints.L0[0]
+	}
+	if len(endpoints.L1) > 0 {
+		base.L1URL = endpoints.L1[0]
+	}
+	if len(endpoints.DataL1) > 0 {
+		base.DataL1URL = endpoints.DataL1[0]
+	}
+	return base
+}
+
+// Refresh resolves the endpoints now
+func (d *EndpointDiscovery) Refresh() error {
+	endpoints, err := d.config.Resolver.Resolve(d.ctx)
+	if err != nil {
+		if d.config.OnError != nil {
+			d.config.OnError(err)
+		}
+		return err
+	}
+
+	d.mu.Lock()
+	changed := !reflect.DeepEqual(d.endpoints, *endpoints)
+	d.endpoints = *endpoints
+	d.mu.Unlock()
+
+	if changed && d.config.OnChange != nil {
+		d.config.OnChange(d.Endpoints())
+	}
+	return nil
+}
+
+// Close stops refreshing and blocks until the background refresh has stopped
+func (d *EndpointDiscovery) Close() error {
+	d.once.Do(func() { close(d.stop) })
+	<-d.Done()
+	return d.Err()
+}
+
+func (d *EndpointDiscovery) run() {
+	ticker := time.NewTicker(d.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			d.finish(d.ctx.Err())
+			return
+		case <-d.stop:
+			d.finish(nil)
+			return
+		case <-ticker.C:
+			_ = d.Refresh()
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDNS answers SRV and TXT lookups from maps keyed by service and name
+type fakeDNS struct {
+	mu  sync.Mutex
+	srv map[string][]*net.SRV
+	txt map[string][]string
+	err error
+}
+
+func (f *fakeDNS) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", nil, f.err
+	}
+	records, ok := f.srv[service]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return "_" + service + "._" + proto + "." + name, records, nil
+}
+
+func (f *fakeDNS) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.txt[name], nil
+}
+
+func TestDNSEndpointResolver(t *testing.T) {
+	dns := &fakeDNS{
+		srv: map[string][]*net.SRV{
+			"l1": {
+				{Target: "l1-a.nodes.example.com.", Port: 9010, Priority: 10},
+				{Target: "l1-b.nodes.example.com.", Port: 9010, Priority: 20},
+			},
+			"l0": {{Target: "l0.nodes.example.com.", Port: 9000}},
+		},
+		txt: map[string][]string{
+			"nodes.example.com": {
+				"v=spf1 -all",
+				"data-l1=https://data.example.com/",
+				"l1=http://l1-a.nodes.example.com:9010",
+				"l1 = https://l1-c.example.com:8443",
+			},
+		},
+	}
+
+	t.Run("combines SRV and TXT records", func(t *testing.T) {
+		resolver := &DNSEndpointResolver{Domain: "nodes.example.com", Lookup: dns}
+		endpoints, err := resolver.Resolve(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"http://l0.nodes.example.com:9000"}, endpoints.L0)
+		assert.Equal(t, []string{
+			"http://l1-a.nodes.example.com:9010",
+			"http://l1-b.nodes.example.com:9010",
+			"https://l1-c.example.com:8443",
+		}, endpoints.L1)
+		assert.Equal(t, []string{"https://data.example.com"}, endpoints.DataL1)
+	})
+
+	t.Run("uses the configured scheme for SRV records", func(t *testing.T) {
+		resolver := &DNSEndpointResolver{Domain: "nodes.example.com", Scheme: "https", Lookup: dns}
+		endpoints, err := resolver.Resolve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "https://l0.nodes.example.com:9000", endpoints.L0[0])
+	})
+
+	t.Run("requires a domain", func(t *testing.T) {
+		_, err := (&DNSEndpointResolver{Lookup: dns}).Resolve(context.Background())
+		assert.ErrorIs(t, err, ErrDomainRequired)
+	})
+
+	t.Run("reports an empty domain", func(t *testing.T) {
+		resolver := &DNSEndpointResolver{Domain: "empty.example.com", Lookup: &fakeDNS{}}
+		_, err := resolver.Resolve(context.Background())
+		assert.ErrorIs(t, err, ErrNoEndpointsDiscovered)
+	})
+
+	t.Run("fails on DNS errors other than not found", func(t *testing.T) {
+		failing := &fakeDNS{err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}}
+		resolver := &DNSEndpointResolver{Domain: "nodes.example.com", Lookup: failing}
+		_, err := resolver.Resolve(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server misbehaving")
+	})
+}
+
+func TestEndpointDiscovery(t *testing.T) {
+	dns := &fakeDNS{srv: map[string][]*net.SRV{
+		"l1": {{Target: "l1-a.example.com.", Port: 9010}},
+	}}
+	resolver := &DNSEndpointResolver{Domain: "example.com", Lookup: dns}
+
+	t.Run("refreshes and keeps the last good endpoints", func(t *testing.T) {
+		changes := make(chan constellation.Endpoints, 4)
+		errs := make(chan error, 4)
+		discovery, err := NewEndpointDiscovery(context.Background(), EndpointDiscoveryConfig{
+			Resolver:        resolver,
+			RefreshInterval: time.Hour,
+			OnChange:        func(e constellation.Endpoints) { changes <- e },
+			OnError:         func(err error) { errs <- err },
+		})
+		require.NoError(t, err)
+		defer discovery.Close()
+
+		config := discovery.NetworkConfig(constellation.NetworkConfig{Timeout: 5})
+		assert.Equal(t, "http://l1-a.example.com:9010", config.L1URL)
+		assert.Empty(t, config.L0URL)
+		assert.Equal(t, 5, config.Timeout)
+
+		dns.mu.Lock()
+		dns.srv["l1"] = []*net.SRV{{Target: "l1-b.example.com.", Port: 9010}}
+		dns.mu.Unlock()
+		require.NoError(t, discovery.Refresh())
+		assert.Equal(t, []string{"http://l1-b.example.com:9010"}, (<-changes).L1)
+
+		// an unchanged refresh does not call OnChange
+		require.NoError(t, discovery.Refresh())
+		assert.Empty(t, changes)
+
+		dns.mu.Lock()
+		dns.err = errors.New("timeout")
+		dns.mu.Unlock()
+		assert.Error(t, discovery.Refresh())
+		assert.Len(t, errs, 1)
+		assert.Equal(t, []string{"http://l1-b.example.com:9010"}, discovery.Endpoints().L1)
+
+		dns.mu.Lock()
+		dns.err = nil
+		dns.mu.Unlock()
+	})
+
+	t.Run("refreshes periodically", func(t *testing.T) {
+		changes := make(chan constellation.Endpoints, 16)
+		discovery, err := NewEndpointDiscovery(context.Background(), EndpointDiscoveryConfig{
+			Resolver:        resolver,
+			RefreshInterval: 5 * time.Millisecond,
+			OnChange:        func(e constellation.Endpoints) { changes <- e },
+		})
+		require.NoError(t, err)
+
+		dns.mu.Lock()
+		dns.srv["l0"] = []*net.SRV{{Target: "l0.example.com.", Port: 9000}}
+		dns.mu.Unlock()
+
+		select {
+		case e := <-changes:
+			assert.Equal(t, []string{"http://l0.example.com:9000"}, e.L0)
+		case <-time.After(time.Second):
+			t.Fatal("no refresh observed")
+		}
+		assert.NoError(t, discovery.Close())
+	})
+
+	t.Run("fails when the initial resolution fails", func(t *testing.T) {
+		_, err := NewEndpointDiscovery(context.Background(), EndpointDiscoveryConfig{
+			Resolver: &DNSEndpointResolver{Domain: "example.com", Lookup: &fakeDNS{}},
+		})
+		assert.ErrorIs(t, err, ErrNoEndpointsDiscovered)
+	})
+
+	t.Run("stops with the context error when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		discovery, err := NewEndpointDiscovery(ctx, EndpointDiscoveryConfig{Resolver: resolver})
+		require.NoError(t, err)
+		cancel()
+		<-discovery.Done()
+		assert.ErrorIs(t, discovery.Err(), context.Canceled)
+	})
+}
@@ -0,0 +1,88 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRateProviders(t *testing.T) {
+	t.Run("NoExchangeRates never has a rate", func(t *testing.T) {
+		var provider constellation.ExchangeRateProvider = constellation.NoExchangeRates{}
+		rate, err := provider.Rate("DAG", "usd", time.Now())
+		assert.NoError(t, err)
+		assert.Nil(t, rate)
+	})
+
+	t.Run("CoinGecko provider reads and caches daily history", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			assert.Equal(t, "/coins/constellation-labs/history", r.URL.Path)
+			assert.Equal(t, "05-03-2024", r.URL.Query().Get("date"))
+			_, _ = w.Write([]byte(`{"market_data":{"current_price":{"usd":0.125,"eur":0.115}}}`))
+		}))
+		defer server.Close()
+
+		var provider constellation.ExchangeRateProvider = NewCoinGeckoRateProvider(CoinGeckoConfig{BaseURL: server.URL})
+		at := time.Date(2024, 3, 5, 17, 30, 0, 0, time.UTC)
+
+		rate, err := provider.Rate("dag", "USD", at)
+		require.NoError(t, err)
+		require.NotNil(t, rate)
+		assert.Equal(t, 0.125, rate.Rate)
+		assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), rate.At)
+		assert.InDelta(t, 12.5, rate.FiatValue(constellation.TokenToUnits(100)), 1e-9)
+
+		_, err = provider.Rate("DAG", "usd", at.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		rate, err = provider.Rate("DAG", "jpy", at)
+		require.NoError(t, err)
+		assert.Nil(t, rate)
+	})
+
+	t.Run("CoinGecko provider refetches today's rate after TodayTTL", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			_, _ = w.Write([]byte(`{"market_data":{"current_price":{"usd":0.125}}}`))
+		}))
+		defer server.Close()
+
+		provider := NewCoinGeckoRateProvider(CoinGeckoConfig{BaseURL: server.URL, TodayTTL: time.Hour})
+		now := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+		provider.now = func() time.Time { return now }
+
+		_, err := provider.Rate("DAG", "usd", now)
+		require.NoError(t, err)
+		_, err = provider.Rate("DAG", "usd", now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+		now = now.Add(30 * time.Minute)
+		_, err = provider.Rate("DAG", "usd", now)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+		now = now.Add(time.Hour)
+		_, err = provider.Rate("DAG", "usd", now)
+		require.NoError(t, err)
+		_, err = provider.Rate("DAG", "usd", now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "only today's rate expires")
+	})
+
+	t.Run("unknown assets are rejected", func(t *testing.T) {
+		provider := NewCoinGeckoRateProvider(CoinGeckoConfig{BaseURL: "http://127.0.0.1:1"})
+		_, err := provider.Rate("XYZ", "usd", time.Now())
+		assert.ErrorIs(t, err, constellation.ErrUnknownAsset)
+	})
+}
@@ -0,0 +1,158 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// ExplorerClient is a client for the block explorer API
+//
+// Example:
+//
+//	config := constellation.NetworkConfig{ExplorerURL: "https://be-mainnet.constellationnetwork.io"}
+//	client, err := NewExplorerClient(config)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Get the 20 most recent transactions of an address
+//	txs, err := client.GetTransactionsByAddress("DAG...", 20)
+//
+//	// Get a global snapshot
+//	snapshot, err := client.GetSnapshot(1520)
+type ExplorerClient struct {
+	client *HTTPClient
+}
+
+// NewExplorerClient creates a new ExplorerClient
+//
+// Returns an error if ExplorerURL is not provided in the config
+func NewExplorerClient(config constellation.NetworkConfig) (*ExplorerClient, error) {
+	if config.ExplorerURL == "" {
+		return nil, constellation.ErrExplorerURLRequired
+	}
+
+	client := newNetworkHTTPClient(config.ExplorerURL, config)
+	return &ExplorerClient{client: client}, nil
+}
+
+// GetTransactionsByAddress gets up to limit of the most recent transactions
+// of an address, newest first
+func (c *ExplorerClient) GetTransactionsByAddress(address string, limit int) ([]constellation.ExplorerTransaction, error) {
+	var result struct {
+		Data []constellation.ExplorerTransaction `json:"data"`
+	}
+	path := fmt.Sprintf("/addresses/%s/transactions?limit=%d", address, limit)
+	if err := c.client.Get(path, &result); err != nil {
+		if isNotFound(err) {
+			return []constellation.ExplorerTransaction{}, nil
+		}
+		return nil, wrapOp("getTransactionsByAddress", address, c.client.endpoint(http.MethodGet, path), err)
+	}
+	if result.Data == nil {
+		result.Data = []constellation.ExplorerTransaction{}
+	}
+	return result.Data, nil
+}
+
+// GetTransaction gets a confirmed transaction by hash
+//
+// Returns nil if the explorer has not indexed that transaction
+func (c *ExplorerClient) GetTransaction(hash string) (*constellation.ExplorerTransaction, error) {
+	var result struct {
+		Data constellation.ExplorerTransaction `json:"data"`
+	}
+	path := fmt.Sprintf("/transactions/%s", hash)
+	if err := c.client.Get(path, &result); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, wrapOp("getTransaction", "", c.client.endpoint(http.MethodGet, path), err)
+	}
+	return &result.Data, nil
+}
+
+// GetSnapshotBlocks gets the blocks confirmed in the global snapshot at
+// ordinal
+//
+// Returns nil if the explorer has not indexed that snapshot
+func (c *ExplorerClient) GetSnapshotBlocks(ordinal int64) ([]constellation.ExplorerBlock, error) {
+	var result struct {
+		Data []constellation.ExplorerBlock `json:"data"`
+	}
+	path := fmt.Sprintf("/global-snapshots/%d/blocks", ordinal)
+	if err := c.client.Get(path, &result); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, wrapOp("getSnapshotBlocks", "", c.client.endpoint(http.MethodGet, path), err)
+	}
+	if result.Data == nil {
+		result.Data = []constellation.ExplorerBlock{}
+	}
+	return result.Data, nil
+}
+
+// GetBlock gets a block by hash
+//
+// Returns nil if the explorer has not indexed that block
+func (c *ExplorerClient) GetBlock(hash string) (*constellation.ExplorerBlock, error) {
+	var result struct {
+		Data constellation.ExplorerBlock `json:"data"`
+	}
+	path := fmt.Sprintf("/blocks/%s", hash)
+	if err := c.client.Get(path, &result); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, wrapOp("getBlock", "", c.client.endpoint(http.MethodGet, path), err)
+	}
+	return &result.Data, nil
+}
+
+// GetBlockTransactions gets the transactions in a block
+//
+// Returns nil if the explorer has not indexed that block
+func (c *ExplorerClient) GetBlockTransactions(hash string) ([]constellation.ExplorerTransaction, error) {
+	var result struct {
+		Data []constellation.ExplorerTransaction `json:"data"`
+	}
+	path := fmt.Sprintf("/blocks/%s/transactions", hash)
+	if err := c.client.Get(path, &result); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, wrapOp("getBlockTransactions", "", c.client.endpoint(http.MethodGet, path), err)
+	}
+	if result.Data == nil {
+		result.Data = []constellation.ExplorerTransaction{}
+	}
+	return result.Data, nil
+}
+
+// GetSnapshot gets the global snapshot at ordinal
+//
+// Returns nil if the explorer has not indexed that snapshot
+func (c *ExplorerClient) GetSnapshot(ordinal int64) (*constellation.ExplorerSnapshot, error) {
+	return c.getSnapshot("getSnapshot", fmt.Sprintf("/global-snapshots/%d", ordinal))
+}
+
+// GetLatestSnapshot gets the most recent global snapshot
+func (c *ExplorerClient) GetLatestSnapshot() (*constellation.ExplorerSnapshot, error) {
+	return c.getSnapshot("getLatestSnapshot", "/global-snapshots/latest")
+}
+
+func (c *ExplorerClient) getSnapshot(op string, path string) (*constellation.ExplorerSnapshot, error) {
+	var result struct {
+		Data constellation.ExplorerSnapshot `json:"data"`
+	}
+	if err := c.client.Get(path, &result); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, wrapOp(op, "", c.client.endpoint(http.MethodGet, path), err)
+	}
+	return &result.Data, nil
+}
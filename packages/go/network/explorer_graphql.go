@@ -0,0 +1,301 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// graphQLBatchSize bounds the aliased queries sent in one GraphQL request
+const graphQLBatchSize = 25
+
+// graphQLPath is where explorer deployments serve GraphQL
+const graphQLPath = "/graphql"
+
+const explorerTransactionFields = "hash source destination amount fee parent { hash ordinal } " +
+	"blockHash snapshotHash snapshotOrdinal timestamp"
+
+const explorerSnapshotFields = "hash ordinal height subHeight lastSnapshotHash blocks timestamp"
+
+const explorerBlockFields = "hash height parent { height hash } transactions snapshotHash snapshotOrdinal timestamp"
+
+// NewExplorer creates the explorer client selected by config: a
+// GraphQLExplorerClient when ExplorerGraphQL is set, otherwise an
+// ExplorerClient
+func NewExplorer(config constellation.NetworkConfig) (constellation.ExplorerAPI, error) {
+	if config.ExplorerGraphQL {
+		return NewGraphQLExplorerClient(config)
+	}
+	return NewExplorerClient(config)
+}
+
+// GraphQLExplorerClient implements ExplorerAPI against explorer deployments
+// that expose GraphQL. Bulk methods combine many lookups into one request
+// using aliased fields, up to 25 per request.
+//
+// The deployment must serve this schema at ExplorerURL + "/graphql", with
+// the same field names as the REST API:
+//
+//	type Query {
+//	    transactions(address: String!, limit: Int!): [Transaction!]!
+//	    transaction(hash: String!): Transaction
+//	    snapshot(ordinal: Long!): Snapshot
+//	    latestSnapshot: Snapshot
+//	    snapshotBlocks(ordinal: Long!): [Block!]
+//	    block(hash: String!): Block
+//	    blockTransactions(hash: String!): [Transaction!]
+//	}
+//
+// Example:
+//
+//	config := constellation.NetworkConfig{ExplorerURL: "https://explorer.example.com", ExplorerGraphQL: true}
+//	explorer, err := NewExplorer(config)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Fetch transactions of many addresses in a few requests
+//	byAddress, err := explorer.(*GraphQLExplorerClient).GetTransactionsByAddresses(addresses, 20)
+type GraphQLExplorerClient struct {
+	client *HTTPClient
+}
+
+// NewGraphQLExplorerClient creates a new GraphQLExplorerClient
+//
+// Returns an error if ExplorerURL is not provided in the config
+func NewGraphQLExplorerClient(config constellation.NetworkConfig) (*GraphQLExplorerClient, error) {
+	if config.ExplorerURL == "" {
+		return nil, constellation.ErrExplorerURLRequired
+	}
+
+	client := newNetworkHTTPClient(strings.TrimSuffix(config.ExplorerURL, "/"), config)
+	return &GraphQLExplorerClient{client: client}, nil
+}
+
+// GetTransactionsByAddress gets up to limit of the most recent transactions
+// of an address, newest first
+func (c *GraphQLExplorerClient) GetTransactionsByAddress(address string, limit int) ([]constellation.ExplorerTransaction, error) {
+	var result struct {
+		Transactions []constellation.ExplorerTransaction `json:"transactions"`
+	}
+	query := "query($address: String!, $limit: Int!) { transactions(address: $address, limit: $limit) { " +
+		explorerTransactionFields + " } }"
+	variables := map[string]interface{}{"address": address, "limit": limit}
+	if err := c.query(query, variables, &result); err != nil {
+		return nil, wrapOp("getTransactionsByAddress", address, c.client.endpoint(http.MethodPost, graphQLPath), err)
+	}
+	if result.Transactions == nil {
+		result.Transactions = []constellation.ExplorerTransaction{}
+	}
+	return result.Transactions, nil
+}
+
+// GetTransactionsByAddresses gets up to limit transactions for each address,
+// batching the lookups into as few requests as possible
+//
+// The returned map holds every address that was fetched. If a request
+// fails the error is a *BatchLookupError listing the addresses it covered,
+// and the map still contains the rest.
+func (c *GraphQLExplorerClient) GetTransactionsByAddresses(addresses []string, limit int) (map[string][]constellation.ExplorerTransaction, error) {
+	unique := make([]string, 0, len(addresses))
+	seen := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		if !seen[address] {
+			seen[address] = true
+			unique = append(unique, address)
+		}
+	}
+
+	results := make(map[string][]constellation.ExplorerTransaction, len(unique))
+	failures := map[string]error{}
+	for start := 0; start < len(unique); start += graphQLBatchSize {
+		end := start + graphQLBatchSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		chunk := unique[start:end]
+
+		params := make([]string, len(chunk))
+		fields := make([]string, len(chunk))
+		variables := map[string]interface{}{"limit": limit}
+		for i, address := range chunk {
+			params[i] = fmt.Sprintf("$a%d: String!", i)
+			fields[i] = fmt.Sprintf("a%d: transactions(address: $a%d, limit: $limit) { %s }", i, i, explorerTransactionFields)
+			variables["a"+strconv.Itoa(i)] = address
+		}
+		query := "query(" + strings.Join(params, ", ") + ", $limit: Int!) { " + strings.Join(fields, " ") + " }"
+
+		var result map[string][]constellation.ExplorerTransaction
+		if err := c.query(query, variables, &result); err != nil {
+			err = wrapOp("getTransactionsByAddresses", "", c.client.endpoint(http.MethodPost, graphQLPath), err)
+			for _, address := range chunk {
+				failures[address] = err
+			}
+			continue
+		}
+		for i, address := range chunk {
+			txs := result["a"+strconv.Itoa(i)]
+			if txs == nil {
+				txs = []constellation.ExplorerTransaction{}
+			}
+			results[address] = txs
+		}
+	}
+
+	if len(failures) > 0 {
+		return results, &constellation.BatchLookupError{Op: "transactions", Errors: failures}
+	}
+	return results, nil
+}
+
+// GetTransaction gets a confirmed transaction by hash
+//
+// Returns nil if the explorer has not indexed that transaction
+func (c *GraphQLExplorerClient) GetTransaction(hash string) (*constellation.ExplorerTransaction, error) {
+	var result struct {
+		Transaction *constellation.ExplorerTransaction `json:"transaction"`
+	}
+	query := "query($hash: String!) { transaction(hash: $hash) { " + explorerTransactionFields + " } }"
+	if err := c.query(query, map[string]interface{}{"hash": hash}, &result); err != nil {
+		return nil, wrapOp("getTransaction", "", c.client.endpoint(http.MethodPost, graphQLPath), err)
+	}
+	return result.Transaction, nil
+}
+
+// GetSnapshot gets the global snapshot at ordinal
+//
+// Returns nil if the explorer has not indexed that snapshot
+func (c *GraphQLExplorerClient) GetSnapshot(ordinal int64) (*constellation.ExplorerSnapshot, error) {
+	snapshots, err := c.GetSnapshots([]int64{ordinal})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots[ordinal], nil
+}
+
+// GetSnapshots gets the global snapshots at the given ordinals, batching the
+// lookups into as few requests as possible. Snapshots the explorer has not
+// indexed are absent from the map.
+func (c *GraphQLExplorerClient) GetSnapshots(ordinals []int64) (map[int64]*constellation.ExplorerSnapshot, error) {
+	results := make(map[int64]*constellation.ExplorerSnapshot, len(ordinals))
+	for start := 0; start < len(ordinals); start += graphQLBatchSize {
+		end := start + graphQLBatchSize
+		if end > len(ordinals) {
+			end = len(ordinals)
+		}
+		chunk := ordinals[start:end]
+
+		params := make([]string, len(chunk))
+		fields := make([]string, len(chunk))
+		variables := make(map[string]interface{}, len(chunk))
+		for i, ordinal := range chunk {
+			params[i] = fmt.Sprintf("$s%d: Long!", i)
+			fields[i] = fmt.Sprintf("s%d: snapshot(ordinal: $s%d) { %s }", i, i, explorerSnapshotFields)
+			variables["s"+strconv.Itoa(i)] = ordinal
+		}
+		query := "query(" + strings.Join(params, ", ") + ") { " + strings.Join(fields, " ") + " }"
+
+		var result map[string]*constellation.ExplorerSnapshot
+		if err := c.query(query, variables, &result); err != nil {
+			return results, wrapOp("getSnapshots", "", c.client.endpoint(http.MethodPost, graphQLPath), err)
+		}
+		for i, ordinal := range chunk {
+			if snapshot := result["s"+strconv.Itoa(i)]; snapshot != nil {
+				results[ordinal] = snapshot
+			}
+		}
+	}
+	return results, nil
+}
+
+// GetSnapshotBlocks gets the blocks confirmed in the global snapshot at
+// ordinal
+//
+// Returns nil if the explorer has not indexed that snapshot
+func (c *GraphQLExplorerClient) GetSnapshotBlocks(ordinal int64) ([]constellation.ExplorerBlock, error) {
+	var result struct {
+		SnapshotBlocks []constellation.ExplorerBlock `json:"snapshotBlocks"`
+	}
+	query := "query($ordinal: Long!) { snapshotBlocks(ordinal: $ordinal) { " + explorerBlockFields + " } }"
+	if err := c.query(query, map[string]interface{}{"ordinal": ordinal}, &result); err != nil {
+		return nil, wrapOp("getSnapshotBlocks", "", c.client.endpoint(http.MethodPost, graphQLPath), err)
+	}
+	return result.SnapshotBlocks, nil
+}
+
+// GetBlock gets a block by hash
+//
+// Returns nil if the explorer has not indexed that block
+func (c *GraphQLExplorerClient) GetBlock(hash string) (*constellation.ExplorerBlock, error) {
+	var result struct {
+		Block *constellation.ExplorerBlock `json:"block"`
+	}
+	query := "query($block: String!) { block(hash: $block) { " + explorerBlockFields + " } }"
+	if err := c.query(query, map[string]interface{}{"block": hash}, &result); err != nil {
+		return nil, wrapOp("getBlock", "", c.client.endpoint(http.MethodPost, graphQLPath), err)
+	}
+	return result.Block, nil
+}
+
+// GetBlockTransactions gets the transactions in a block
+//
+// Returns nil if the explorer has not indexed that block
+func (c *GraphQLExplorerClient) GetBlockTransactions(hash string) ([]constellation.ExplorerTransaction, error) {
+	var result struct {
+		BlockTransactions []constellation.ExplorerTransaction `json:"blockTransactions"`
+	}
+	query := "query($block: String!) { blockTransactions(hash: $block) { " + explorerTransactionFields + " } }"
+	if err := c.query(query, map[string]interface{}{"block": hash}, &result); err != nil {
+		return nil, wrapOp("getBlockTransactions", "", c.client.endpoint(http.MethodPost, graphQLPath), err)
+	}
+	return result.BlockTransactions, nil
+}
+
+// GetLatestSnapshot gets the most recent global snapshot
+func (c *GraphQLExplorerClient) GetLatestSnapshot() (*constellation.ExplorerSnapshot, error) {
+	var result struct {
+		LatestSnapshot *constellation.ExplorerSnapshot `json:"latestSnapshot"`
+	}
+	query := "query { latestSnapshot { " + explorerSnapshotFields + " } }"
+	if err := c.query(query, nil, &result); err != nil {
+		return nil, wrapOp("getLatestSnapshot", "", c.client.endpoint(http.MethodPost, graphQLPath), err)
+	}
+	return result.LatestSnapshot, nil
+}
+
+// query posts a GraphQL request and decodes its data into result. GraphQL
+// errors are reported as a NetworkError even when the response was 200.
+func (c *GraphQLExplorerClient) query(query string, variables map[string]interface{}, result interface{}) error {
+	body := map[string]interface{}{"query": query}
+	if len(variables) > 0 {
+		body["variables"] = variables
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.client.Post(graphQLPath, body, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i, e := range response.Errors {
+			messages[i] = e.Message
+		}
+		return constellation.NewNetworkError("GraphQL error: "+strings.Join(messages, "; "), 0, "")
+	}
+	if len(response.Data) == 0 {
+		return constellation.NewNetworkError("GraphQL response has no data", 0, "")
+	}
+	if err := json.Unmarshal(response.Data, result); err != nil {
+		return constellation.NewNetworkError(fmt.Sprintf("failed to parse GraphQL data: %v", err), 0, "")
+	}
+	return nil
+}
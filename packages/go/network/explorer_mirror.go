@@ -0,0 +1,164 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const defaultExplorerMirrorLimit = 10
+
+// NewExplorerMirrorHandler serves explorer queries from api, usually an
+// ExplorerCache, using the block explorer's routes and response shape, so
+// dashboards that speak to the explorer can be pointed at the mirror
+// instead. Only GET is served:
+//
+//	/addresses/{address}/transactions?limit=N
+//	/transactions/{hash} (if api implements constellation.ExplorerTransactionLookup)
+//	/global-snapshots/latest
+//	/global-snapshots/{ordinal}
+//	/global-snapshots/{ordinal}/blocks (if api implements constellation.ExplorerBlockLookup)
+//	/blocks/{hash} (if api implements constellation.ExplorerBlockLookup)
+//	/blocks/{hash}/transactions (if api implements constellation.ExplorerBlockLookup)
+//
+// Example:
+//
+//	cache, _ := constellation.NewExplorerCache(constellation.ExplorerCacheConfig{Upstream: explorer})
+//	http.ListenAndServe(":8090", NewExplorerMirrorHandler(cache))
+func NewExplorerMirrorHandler(api constellation.ExplorerAPI) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(parts) == 3 && parts[0] == "addresses" && parts[2] == "transactions":
+			limit := defaultExplorerMirrorLimit
+			if value := r.URL.Query().Get("limit"); value != "" {
+				parsed, err := strconv.Atoi(value)
+				if err != nil || parsed <= 0 {
+					http.Error(w, "invalid limit", http.StatusBadRequest)
+					return
+				}
+				limit = parsed
+			}
+			txs, err := api.GetTransactionsByAddress(parts[1], limit)
+			writeExplorerMirrorResponse(w, txs, err)
+
+		case len(parts) == 2 && parts[0] == "transactions":
+			lookup, ok := api.(constellation.ExplorerTransactionLookup)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			tx, err := lookup.GetTransaction(parts[1])
+			if errors.Is(err, constellation.ErrTransactionLookupUnsupported) {
+				http.NotFound(w, r)
+				return
+			}
+			writeExplorerMirrorResponse(w, tx, err)
+
+		case len(parts) == 2 && parts[0] == "global-snapshots" && parts[1] == "latest":
+			snapshot, err := api.GetLatestSnapshot()
+			writeExplorerMirrorResponse(w, snapshot, err)
+
+		case len(parts) == 2 && parts[0] == "global-snapshots":
+			ordinal, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil || ordinal < 0 {
+				http.Error(w, "invalid ordinal", http.StatusBadRequest)
+				return
+			}
+			snapshot, err := api.GetSnapshot(ordinal)
+			writeExplorerMirrorResponse(w, snapshot, err)
+
+		case len(parts) == 3 && parts[0] == "global-snapshots" && parts[2] == "blocks":
+			lookup, ok := api.(constellation.ExplorerBlockLookup)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			ordinal, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil || ordinal < 0 {
+				http.Error(w, "invalid ordinal", http.StatusBadRequest)
+				return
+			}
+			blocks, err := lookup.GetSnapshotBlocks(ordinal)
+			writeExplorerMirrorBlockResponse(w, r, blocks, err)
+
+		case len(parts) == 2 && parts[0] == "blocks":
+			lookup, ok := api.(constellation.ExplorerBlockLookup)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			block, err := lookup.GetBlock(parts[1])
+			writeExplorerMirrorBlockResponse(w, r, block, err)
+
+		case len(parts) == 3 && parts[0] == "blocks" && parts[2] == "transactions":
+			lookup, ok := api.(constellation.ExplorerBlockLookup)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			txs, err := lookup.GetBlockTransactions(parts[1])
+			writeExplorerMirrorBlockResponse(w, r, txs, err)
+
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// writeExplorerMirrorResponse wraps data in the explorer's {"data": ...}
+// envelope; a nil snapshot or transaction is a 404 and upstream failures
+// are a 502
+func writeExplorerMirrorResponse(w http.ResponseWriter, data interface{}, err error) {
+	if err != nil {
+		http.Error(w, "upstream explorer error", http.StatusBadGateway)
+		return
+	}
+	if snapshot, ok := data.(*constellation.ExplorerSnapshot); ok && snapshot == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if tx, ok := data.(*constellation.ExplorerTransaction); ok && tx == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if block, ok := data.(*constellation.ExplorerBlock); ok && block == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// writeExplorerMirrorBlockResponse is writeExplorerMirrorResponse for block
+// lookups, where an upstream without block support and an unindexed
+// snapshot or block (a nil slice) are both a 404
+func writeExplorerMirrorBlockResponse(w http.ResponseWriter, r *http.Request, data interface{}, err error) {
+	if errors.Is(err, constellation.ErrBlockLookupUnsupported) {
+		http.NotFound(w, r)
+		return
+	}
+	switch list := data.(type) {
+	case []constellation.ExplorerBlock:
+		if list == nil && err == nil {
+			http.NotFound(w, r)
+			return
+		}
+	case []constellation.ExplorerTransaction:
+		if list == nil && err == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	writeExplorerMirrorResponse(w, data, err)
+}
@@ -0,0 +1,361 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplorerClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/addresses/DAG0addr/transactions":
+			assert.Equal(t, "5", r.URL.Query().Get("limit"))
+			w.Write([]byte(`{"data":[{"hash":"tx1","source":"DAG0addr","amount":100,"snapshotOrdinal":10}]}`))
+		case "/global-snapshots/latest":
+			w.Write([]byte(`{"data":{"hash":"s12","ordinal":12}}`))
+		case "/transactions/tx1":
+			w.Write([]byte(`{"data":{"hash":"tx1","blockHash":"b1","snapshotOrdinal":10}}`))
+		case "/global-snapshots/10/blocks":
+			w.Write([]byte(`{"data":[{"hash":"b1","height":3,"parent":[{"height":2,"hash":"b0"}],"transactions":["tx1"],"snapshotOrdinal":10}]}`))
+		case "/global-snapshots/11/blocks":
+			w.Write([]byte(`{"data":[]}`))
+		case "/blocks/b1":
+			w.Write([]byte(`{"data":{"hash":"b1","height":3,"transactions":["tx1"],"snapshotOrdinal":10}}`))
+		case "/blocks/b1/transactions":
+			w.Write([]byte(`{"data":[{"hash":"tx1","blockHash":"b1","snapshotOrdinal":10}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("requires ExplorerURL", func(t *testing.T) {
+		_, err := NewExplorerClient(constellation.NetworkConfig{})
+		assert.ErrorIs(t, err, constellation.ErrExplorerURLRequired)
+	})
+
+	client, err := NewExplorerClient(constellation.NetworkConfig{ExplorerURL: server.URL})
+	require.NoError(t, err)
+
+	t.Run("gets transactions by address", func(t *testing.T) {
+		txs, err := client.GetTransactionsByAddress("DAG0addr", 5)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, int64(100), txs[0].Amount)
+		assert.Equal(t, int64(10), txs[0].SnapshotOrdinal)
+	})
+
+	t.Run("gets the latest snapshot", func(t *testing.T) {
+		snapshot, err := client.GetLatestSnapshot()
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), snapshot.Ordinal)
+	})
+
+	t.Run("returns nil for an unindexed snapshot", func(t *testing.T) {
+		snapshot, err := client.GetSnapshot(99)
+		require.NoError(t, err)
+		assert.Nil(t, snapshot)
+	})
+
+	t.Run("gets a transaction by hash", func(t *testing.T) {
+		tx, err := client.GetTransaction("tx1")
+		require.NoError(t, err)
+		require.NotNil(t, tx)
+		assert.Equal(t, "b1", tx.BlockHash)
+		assert.Equal(t, int64(10), tx.SnapshotOrdinal)
+
+		tx, err = client.GetTransaction("missing")
+		require.NoError(t, err)
+		assert.Nil(t, tx)
+	})
+
+	t.Run("gets the blocks of a snapshot", func(t *testing.T) {
+		blocks, err := client.GetSnapshotBlocks(10)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, []constellation.BlockReference{{Height: 2, Hash: "b0"}}, blocks[0].Parents)
+		assert.Equal(t, []string{"tx1"}, blocks[0].Transactions)
+
+		blocks, err = client.GetSnapshotBlocks(11)
+		require.NoError(t, err)
+		assert.NotNil(t, blocks)
+		assert.Empty(t, blocks)
+
+		blocks, err = client.GetSnapshotBlocks(99)
+		require.NoError(t, err)
+		assert.Nil(t, blocks)
+	})
+
+	t.Run("gets a block and its transactions", func(t *testing.T) {
+		block, err := client.GetBlock("b1")
+		require.NoError(t, err)
+		require.NotNil(t, block)
+		assert.Equal(t, int64(3), block.Height)
+
+		txs, err := client.GetBlockTransactions("b1")
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, "tx1", txs[0].Hash)
+
+		block, err = client.GetBlock("missing")
+		require.NoError(t, err)
+		assert.Nil(t, block)
+		txs, err = client.GetBlockTransactions("missing")
+		require.NoError(t, err)
+		assert.Nil(t, txs)
+	})
+}
+
+func TestExplorerMirrorHandler(t *testing.T) {
+	upstream := newFakeExplorer()
+	cache, err := constellation.NewExplorerCache(constellation.ExplorerCacheConfig{Upstream: upstream})
+	require.NoError(t, err)
+	mirror := httptest.NewServer(NewExplorerMirrorHandler(cache))
+	defer mirror.Close()
+
+	t.Run("an ExplorerClient can read through the mirror", func(t *testing.T) {
+		client, err := NewExplorerClient(constellation.NetworkConfig{ExplorerURL: mirror.URL})
+		require.NoError(t, err)
+
+		txs, err := client.GetTransactionsByAddress("DAG0addr", 1)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, "tx2", txs[0].Hash)
+
+		snapshot, err := client.GetSnapshot(10)
+		require.NoError(t, err)
+		assert.Equal(t, "s10", snapshot.Hash)
+
+		snapshot, err = client.GetSnapshot(99)
+		require.NoError(t, err)
+		assert.Nil(t, snapshot)
+
+		tx, err := client.GetTransaction("tx1")
+		require.NoError(t, err)
+		require.NotNil(t, tx)
+		assert.Equal(t, int64(10), tx.SnapshotOrdinal)
+
+		tx, err = client.GetTransaction("missing")
+		require.NoError(t, err)
+		assert.Nil(t, tx)
+
+		blocks, err := client.GetSnapshotBlocks(12)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, "b2", blocks[0].Hash)
+
+		blocks, err = client.GetSnapshotBlocks(99)
+		require.NoError(t, err)
+		assert.Nil(t, blocks)
+
+		block, err := client.GetBlock("b1")
+		require.NoError(t, err)
+		require.NotNil(t, block)
+		assert.Equal(t, int64(10), block.SnapshotOrdinal)
+
+		txs, err = client.GetBlockTransactions("b1")
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, "tx1", txs[0].Hash)
+
+		block, err = client.GetBlock("missing")
+		require.NoError(t, err)
+		assert.Nil(t, block)
+	})
+
+	t.Run("wraps responses in the explorer envelope", func(t *testing.T) {
+		resp, err := http.Get(mirror.URL + "/global-snapshots/latest")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Contains(t, string(body["data"]), `"ordinal":12`)
+	})
+
+	t.Run("rejects bad requests", func(t *testing.T) {
+		for path, status := range map[string]int{
+			"/global-snapshots/abc":                    http.StatusBadRequest,
+			"/global-snapshots/abc/blocks":             http.StatusBadRequest,
+			"/blocks/missing/transactions":             http.StatusNotFound,
+			"/addresses/DAG0addr/transactions?limit=0": http.StatusBadRequest,
+			"/unknown": http.StatusNotFound,
+		} {
+			resp, err := http.Get(mirror.URL + path)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, status, resp.StatusCode, path)
+		}
+
+		resp, err := http.Post(mirror.URL+"/global-snapshots/latest", "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
+
+func TestGraphQLExplorerClient(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/graphql", r.URL.Path)
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if strings.Contains(body.Query, "latestSnapshot") {
+			w.Write([]byte(`{"data":{"latestSnapshot":{"hash":"s12","ordinal":12}}}`))
+			return
+		}
+		data := map[string]interface{}{}
+		for alias, value := range body.Variables {
+			switch {
+			case value == "fail":
+				w.Write([]byte(`{"errors":[{"message":"boom"}]}`))
+				return
+			case alias == "hash":
+				if value == "missing" {
+					data["transaction"] = nil
+				} else {
+					data["transaction"] = constellation.ExplorerTransaction{Hash: value.(string), SnapshotOrdinal: 7}
+				}
+			case alias == "ordinal":
+				if ordinal := int64(value.(float64)); ordinal < 100 {
+					data["snapshotBlocks"] = []constellation.ExplorerBlock{{Hash: "b1", SnapshotOrdinal: ordinal}}
+				} else {
+					data["snapshotBlocks"] = nil
+				}
+			case alias == "block" && value == "missing":
+				data["block"] = nil
+				data["blockTransactions"] = nil
+			case alias == "block":
+				data["block"] = constellation.ExplorerBlock{Hash: value.(string), Height: 3}
+				data["blockTransactions"] = []constellation.ExplorerTransaction{{Hash: "tx1", BlockHash: value.(string)}}
+			case alias == "address":
+				data["transactions"] = []constellation.ExplorerTransaction{{Hash: "tx-" + value.(string)}}
+			case alias[0] == 'a':
+				data[alias] = []constellation.ExplorerTransaction{{Hash: "tx-" + value.(string), Source: value.(string)}}
+			case alias[0] == 's':
+				if ordinal := int64(value.(float64)); ordinal < 100 {
+					data[alias] = constellation.ExplorerSnapshot{Hash: "snap", Ordinal: ordinal}
+				} else {
+					data[alias] = nil
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	t.Run("NewExplorer selects GraphQL from the config", func(t *testing.T) {
+		explorer, err := NewExplorer(constellation.NetworkConfig{ExplorerURL: server.URL, ExplorerGraphQL: true})
+		require.NoError(t, err)
+		assert.IsType(t, &GraphQLExplorerClient{}, explorer)
+
+		explorer, err = NewExplorer(constellation.NetworkConfig{ExplorerURL: server.URL})
+		require.NoError(t, err)
+		assert.IsType(t, &ExplorerClient{}, explorer)
+	})
+
+	client, err := NewGraphQLExplorerClient(constellation.NetworkConfig{ExplorerURL: server.URL})
+	require.NoError(t, err)
+
+	t.Run("gets transactions by address", func(t *testing.T) {
+		txs, err := client.GetTransactionsByAddress("DAG0addr", 5)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, "tx-DAG0addr", txs[0].Hash)
+	})
+
+	t.Run("batches address lookups", func(t *testing.T) {
+		addresses := make([]string, 30)
+		for i := range addresses {
+			addresses[i] = "DAG" + strings.Repeat("x", i)
+		}
+		addresses = append(addresses, addresses[0])
+
+		requests = 0
+		byAddress, err := client.GetTransactionsByAddresses(addresses, 5)
+		require.NoError(t, err)
+		assert.Len(t, byAddress, 30)
+		assert.Equal(t, "tx-DAGxxx", byAddress["DAGxxx"][0].Hash)
+		assert.Equal(t, 2, requests, "30 addresses should take two requests of at most 25")
+	})
+
+	t.Run("batches snapshot lookups and omits unindexed ones", func(t *testing.T) {
+		requests = 0
+		snapshots, err := client.GetSnapshots([]int64{10, 11, 500})
+		require.NoError(t, err)
+		assert.Equal(t, 1, requests)
+		assert.Len(t, snapshots, 2)
+		assert.Equal(t, int64(11), snapshots[11].Ordinal)
+
+		snapshot, err := client.GetSnapshot(500)
+		require.NoError(t, err)
+		assert.Nil(t, snapshot)
+	})
+
+	t.Run("gets the latest snapshot", func(t *testing.T) {
+		snapshot, err := client.GetLatestSnapshot()
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), snapshot.Ordinal)
+	})
+
+	t.Run("gets a transaction by hash", func(t *testing.T) {
+		tx, err := client.GetTransaction("tx9")
+		require.NoError(t, err)
+		require.NotNil(t, tx)
+		assert.Equal(t, int64(7), tx.SnapshotOrdinal)
+
+		tx, err = client.GetTransaction("missing")
+		require.NoError(t, err)
+		assert.Nil(t, tx)
+	})
+
+	t.Run("gets blocks", func(t *testing.T) {
+		blocks, err := client.GetSnapshotBlocks(10)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, int64(10), blocks[0].SnapshotOrdinal)
+
+		blocks, err = client.GetSnapshotBlocks(500)
+		require.NoError(t, err)
+		assert.Nil(t, blocks)
+
+		block, err := client.GetBlock("b1")
+		require.NoError(t, err)
+		require.NotNil(t, block)
+		assert.Equal(t, int64(3), block.Height)
+
+		txs, err := client.GetBlockTransactions("b1")
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, "b1", txs[0].BlockHash)
+
+		block, err = client.GetBlock("missing")
+		require.NoError(t, err)
+		assert.Nil(t, block)
+		txs, err = client.GetBlockTransactions("missing")
+		require.NoError(t, err)
+		assert.Nil(t, txs)
+	})
+
+	t.Run("reports GraphQL errors per address", func(t *testing.T) {
+		byAddress, err := client.GetTransactionsByAddresses([]string{"fail"}, 5)
+		assert.Empty(t, byAddress)
+
+		var batchErr *constellation.BatchLookupError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, []string{"fail"}, batchErr.Addresses())
+		assert.Contains(t, err.Error(), "GraphQL error: boom")
+	})
+}
@@ -0,0 +1,191 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const (
+	defaultFaucetMaxRetries   = 3
+	defaultFaucetRetryBackoff = 10 * time.Second
+	defaultFaucetMaxRetryWait = time.Minute
+	defaultBalancePollPeriod  = 5 * time.Second
+)
+
+var (
+	// ErrFaucetURLRequired indicates the faucet URL was not configured
+	ErrFaucetURLRequired = errors.New("URL is required for FaucetClient")
+	// ErrFaucetRateLimited indicates the faucet kept rate limiting after all retries
+	ErrFaucetRateLimited = errors.New("faucet rate limit exceeded")
+	// ErrBalanceTimeout indicates funds did not arrive before the wait deadline
+	ErrBalanceTimeout = errors.New("timed out waiting for balance")
+)
+
+// FaucetConfig holds configuration for a testnet faucet
+type FaucetConfig struct {
+	// URL is the faucet base URL (e.g., "https://faucet.constellationnetwork.io/testnet")
+	URL string
+	// Timeout is the request timeout in seconds (default: 30)
+	Timeout int
+	// UserAgent identifies the integration to the faucet operator (default: DefaultUserAgent)
+	UserAgent string
+	// RequestID generates the X-Request-ID header for each request (default: random)
+	RequestID func() string
+	// MaxRetries is the number of retries after a 429 response (default: 3)
+	MaxRetries int
+	// RetryBackoff is the wait between retries when the faucet sends no Retry-After (default: 10s)
+	RetryBackoff time.Duration
+	// MaxRetryWait caps the wait a Retry-After header can ask for (default: 1m)
+	MaxRetryWait time.Duration
+	// Balances is used by RequestAndWait to detect when funds arrive
+	Balances constellation.BalanceSource
+	// PollInterval is how often RequestAndWait checks the balance (default: 5s)
+	PollInterval time.Duration
+}
+
+// FaucetResponse is the response from a faucet drip
+type FaucetResponse struct {
+	// Hash is the hash of the funding transaction, when the faucet reports it
+	Hash string `json:"hash"`
+}
+
+// FaucetClient requests test tokens from an IntegrationNet/TestNet faucet
+//
+// Example:
+//
+//	faucet, err := NewFaucetClient(FaucetConfig{
+//	    URL:      "https://faucet.constellationnetwork.io/testnet",
+//	    Balances: l0Client,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Request funds and block until they show up in the balance
+//	balance, err := faucet.RequestAndWait(keyPair.Address, 2*time.Minute)
+type FaucetClient struct {
+	client *HTTPClient
+	config FaucetConfig
+}
+
+// NewFaucetClient creates a new FaucetClient
+//
+// Returns an error if URL is not provided in the config
+func NewFaucetClient(config FaucetConfig) (*FaucetClient, error) {
+	if config.URL == "" {
+		return nil, ErrFaucetURLRequired
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = defaultFaucetMaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaultFaucetRetryBackoff
+	}
+	if config.MaxRetryWait <= 0 {
+		config.MaxRetryWait = defaultFaucetMaxRetryWait
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultBalancePollPeriod
+	}
+
+	return &FaucetClient{
+		client: newNetworkHTTPClient(config.URL, constellation.NetworkConfig{
+			Timeout:   config.Timeout,
+			UserAgent: config.UserAgent,
+			RequestID: config.RequestID,
+		}),
+		config: config,
+	}, nil
+}
+
+// Request asks the faucet to fund an address
+//
+// 429 responses are retried up to MaxRetries times, honouring the faucet's
+// Retry-After header up to MaxRetryWait. Returns ErrFaucetRateLimited if every
+// attempt is throttled.
+func (c *FaucetClient) Request(address string) (*FaucetResponse, error) {
+	return c.RequestWithContext(context.Background(), address)
+}
+
+// RequestWithContext is Request with a context that cancels the request and
+// any wait between retries
+func (c *FaucetClient) RequestWithContext(ctx context.Context, address string) (*FaucetResponse, error) {
+	if !constellation.IsValidDAGAddress(address) {
+		return nil, constellation.ErrInvalidAddress
+	}
+
+	path := fmt.Sprintf("/faucet/%s", address)
+	for attempt := 0; ; attempt++ {
+		var result FaucetResponse
+		err := c.client.get(ctx, path, &result)
+		if err == nil {
+			return &result, nil
+		}
+
+		var netErr *constellation.NetworkError
+		if !errors.As(err, &netErr) || netErr.StatusCode != 429 {
+			return nil, wrapOp("faucetRequest", address, c.client.endpoint(http.MethodGet, path), err)
+		}
+		if attempt >= c.config.MaxRetries {
+			return nil, wrapOp("faucetRequest", address, c.client.endpoint(http.MethodGet, path), ErrFaucetRateLimited)
+		}
+
+		wait := netErr.RetryAfter
+		if wait <= 0 {
+			wait = c.config.RetryBackoff
+		}
+		if wait > c.config.MaxRetryWait {
+			wait = c.config.MaxRetryWait
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, wrapOp("faucetRequest", address, c.client.endpoint(http.MethodGet, path), ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// WaitForBalance polls until the address balance reaches at least minBalance
+// smallest units, or returns ErrBalanceTimeout after timeout
+func (c *FaucetClient) WaitForBalance(address string, minBalance int64, timeout time.Duration) (*constellation.BalanceResponse, error) {
+	if c.config.Balances == nil {
+		return nil, constellation.ErrL0URLRequired
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		balance, err := c.config.Balances.GetBalance(address)
+		if err == nil && balance.Balance >= minBalance {
+			return balance, nil
+		}
+		if time.Now().Add(c.config.PollInterval).After(deadline) {
+			return nil, ErrBalanceTimeout
+		}
+		time.Sleep(c.config.PollInterval)
+	}
+}
+
+// RequestAndWait requests funds and waits until the balance increases
+func (c *FaucetClient) RequestAndWait(address string, timeout time.Duration) (*constellation.BalanceResponse, error) {
+	if c.config.Balances == nil {
+		return nil, constellation.ErrL0URLRequired
+	}
+
+	var startBalance int64
+	if before, err := c.config.Balances.GetBalance(address); err == nil {
+		startBalance = before.Balance
+	}
+
+	if _, err := c.Request(address); err != nil {
+		return nil, err
+	}
+
+	return c.WaitForBalance(address, startBalance+1, timeout)
+}
@@ -0,0 +1,138 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubBalances struct {
+	calls    int32
+	arriveAt int32
+	amount   int64
+}
+
+func (s *stubBalances) GetBalance(address string) (*constellation.BalanceResponse, error) {
+	n := atomic.AddInt32(&s.calls, 1)
+	if n >= s.arriveAt {
+		return &constellation.BalanceResponse{Ordinal: int64(n), Balance: s.amount}, nil
+	}
+	return &constellation.BalanceResponse{Ordinal: int64(n)}, nil
+}
+
+func TestFaucetClient(t *testing.T) {
+	t.Run("requires URL", func(t *testing.T) {
+		_, err := NewFaucetClient(FaucetConfig{})
+		assert.ErrorIs(t, err, ErrFaucetURLRequired)
+	})
+
+	t.Run("retries after rate limiting", func(t *testing.T) {
+		var hits int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&hits, 1) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(`{"hash":"abc"}`))
+		}))
+		defer server.Close()
+
+		faucet, err := NewFaucetClient(FaucetConfig{URL: server.URL, RetryBackoff: time.Millisecond})
+		require.NoError(t, err)
+
+		keyPair, _ := constellation.GenerateKeyPair()
+		result, err := faucet.Request(keyPair.Address)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", result.Hash)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		faucet, err := NewFaucetClient(FaucetConfig{URL: server.URL, MaxRetries: 1, RetryBackoff: time.Millisecond})
+		require.NoError(t, err)
+
+		keyPair, _ := constellation.GenerateKeyPair()
+		_, err = faucet.Request(keyPair.Address)
+		assert.ErrorIs(t, err, ErrFaucetRateLimited)
+	})
+
+	t.Run("caps the Retry-After wait", func(t *testing.T) {
+		var hits int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&hits, 1) == 1 {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(`{"hash":"abc"}`))
+		}))
+		defer server.Close()
+
+		faucet, err := NewFaucetClient(FaucetConfig{URL: server.URL, MaxRetryWait: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		keyPair, _ := constellation.GenerateKeyPair()
+		start := time.Now()
+		_, err = faucet.Request(keyPair.Address)
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("stops waiting when the context ends", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		faucet, err := NewFaucetClient(FaucetConfig{URL: server.URL})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		keyPair, _ := constellation.GenerateKeyPair()
+		start := time.Now()
+		_, err = faucet.RequestWithContext(ctx, keyPair.Address)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("waits for balance to arrive", func(t *testing.T) {
+		balances := &stubBalances{arriveAt: 3, amount: 500}
+		faucet, err := NewFaucetClient(FaucetConfig{
+			URL:          "http://faucet.invalid",
+			Balances:     balances,
+			PollInterval: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		balance, err := faucet.WaitForBalance("DAG0", 500, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, int64(500), balance.Balance)
+	})
+
+	t.Run("times out when balance never arrives", func(t *testing.T) {
+		balances := &stubBalances{arriveAt: 1 << 30}
+		faucet, err := NewFaucetClient(FaucetConfig{
+			URL:          "http://faucet.invalid",
+			Balances:     balances,
+			PollInterval: 5 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		_, err = faucet.WaitForBalance("DAG0", 1, 20*time.Millisecond)
+		assert.ErrorIs(t, err, ErrBalanceTimeout)
+	})
+}
@@ -0,0 +1,77 @@
+package network
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// nodeInfoRetryInterval is how long a failed /node/info lookup is cached
+// before the next feature query tries again
+const nodeInfoRetryInterval = 30 * time.Second
+
+// featureGate lazily fetches /node/info once per client and answers feature
+// queries from the cached version. A failed lookup is cached for
+// nodeInfoRetryInterval, so a node without /node/info is not asked on every
+// gated call.
+type featureGate struct {
+	client *HTTPClient
+	// now is the clock; nil means time.Now
+	now func() time.Time
+
+	mu       sync.Mutex
+	info     *constellation.NodeInfo
+	err      error
+	failedAt time.Time
+}
+
+// nodeInfo returns the cached node info, fetching it on first use
+func (g *featureGate) nodeInfo() (*constellation.NodeInfo, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.info != nil {
+		return g.info, nil
+	}
+	now := time.Now
+	if g.now != nil {
+		now = g.now
+	}
+	if g.err != nil && now().Sub(g.failedAt) < nodeInfoRetryInterval {
+		return nil, g.err
+	}
+
+	var info constellation.NodeInfo
+	if err := g.client.Get("/node/info", &info); err != nil {
+		g.err = wrapOp("getNodeInfo", "", g.client.endpoint(http.MethodGet, "/node/info"), err)
+		g.failedAt = now()
+		return nil, g.err
+	}
+	g.info = &info
+	g.err = nil
+	return g.info, nil
+}
+
+// supports reports whether the node serves the feature. Nodes that do not
+// expose /node/info or report an unparseable version are assumed to support
+// everything, so the endpoint itself decides.
+func (g *featureGate) supports(feature constellation.Feature) bool {
+	info, err := g.nodeInfo()
+	if err != nil {
+		return true
+	}
+	version, ok := constellation.ParseNodeVersion(info.Version)
+	if !ok {
+		return true
+	}
+	return version.Supports(feature)
+}
+
+// isNotFound reports whether err is a 404 from the node
+func isNotFound(err error) bool {
+	var netErr *constellation.NetworkError
+	return errors.As(err, &netErr) && netErr.StatusCode == 404
+}
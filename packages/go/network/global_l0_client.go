@@ -0,0 +1,101 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// GlobalL0Client is a client for the global L0 network layer, which serves
+// DAG balances and the delegated staking parameters used to estimate
+// validator rewards
+//
+// Example:
+//
+//	config := constellation.NetworkConfig{GlobalL0URL: "https://l0-lb-mainnet.constellationnetwork.io"}
+//	client, err := NewGlobalL0Client(config)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Estimate a validator's rewards from live parameters
+//	estimate, err := constellation.EstimateNodeRewardsFrom(client, peerID)
+type GlobalL0Client struct {
+	client   *HTTPClient
+	features *featureGate
+}
+
+// NewGlobalL0Client creates a new GlobalL0Client
+//
+// Returns an error if GlobalL0URL is not provided in the config
+func NewGlobalL0Client(config constellation.NetworkConfig) (*GlobalL0Client, error) {
+	if config.GlobalL0URL == "" {
+		return nil, constellation.ErrGlobalL0URLRequired
+	}
+
+	client := newNetworkHTTPClient(config.GlobalL0URL, config)
+	return &GlobalL0Client{client: client, features: &featureGate{client: client}}, nil
+}
+
+// GetBalance gets the DAG balance of an address at the latest global
+// snapshot
+func (c *GlobalL0Client) GetBalance(address string) (*constellation.BalanceResponse, error) {
+	var result constellation.BalanceResponse
+	path := fmt.Sprintf("/dag/%s/balance", address)
+	if err := c.client.Get(path, &result); err != nil {
+		return nil, wrapOp("getBalance", address, c.client.endpoint(http.MethodGet, path), err)
+	}
+	return &result, nil
+}
+
+// GetRewardsInfo gets the network-wide delegated staking reward state
+//
+// Returns ErrFeatureUnsupported if the node predates delegated staking.
+func (c *GlobalL0Client) GetRewardsInfo() (*constellation.StakingRewardsInfo, error) {
+	var result constellation.StakingRewardsInfo
+	if err := c.getStaking("getRewardsInfo", "/delegated-stakes/rewards-info", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetNodeParams gets the delegation parameters every validator has published
+//
+// Returns ErrFeatureUnsupported if the node predates delegated staking.
+func (c *GlobalL0Client) GetNodeParams() ([]constellation.NodeParams, error) {
+	var result []constellation.NodeParams
+	if err := c.getStaking("getNodeParams", "/node-params", &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = []constellation.NodeParams{}
+	}
+	return result, nil
+}
+
+func (c *GlobalL0Client) getStaking(op string, path string, result interface{}) error {
+	if !c.features.supports(constellation.FeatureDelegatedStaking) {
+		return constellation.ErrFeatureUnsupported
+	}
+	if err := c.client.Get(path, result); err != nil {
+		if isNotFound(err) {
+			return constellation.ErrFeatureUnsupported
+		}
+		return wrapOp(op, "", c.client.endpoint(http.MethodGet, path), err)
+	}
+	return nil
+}
+
+// CheckHealth checks the health/availability of the global L0 node
+func (c *GlobalL0Client) CheckHealth() bool {
+	var result interface{}
+	return c.client.Get("/cluster/info", &result) == nil
+}
+
+// GetNodeInfo gets the node's identity and Tessellation version
+//
+// The result is cached for the lifetime of the client.
+func (c *GlobalL0Client) GetNodeInfo() (*constellation.NodeInfo, error) {
+	return c.features.nodeInfo()
+}
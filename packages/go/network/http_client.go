@@ -0,0 +1,222 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const defaultTimeout = 30
+
+// DefaultUserAgent is sent when no UserAgent is configured
+const DefaultUserAgent = "metakit-sdk-go/" + constellation.Version
+
+// RequestIDHeader carries the per-request correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// HTTPClient is a simple HTTP client for network operations
+type HTTPClient struct {
+	client    *http.Client
+	baseURL   string
+	userAgent string
+	requestID func() string
+	// begin applies a tenant's rate limit and metrics, if set
+	begin func(ctx context.Context, endpoint string) (func(err error), error)
+}
+
+// NewHTTPClient creates a new HTTP client
+func NewHTTPClient(baseURL string, timeout int) *HTTPClient {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &HTTPClient{
+		client: &http.Client{
+			Timeout:   time.Duration(timeout) * time.Second,
+			Transport: sharedTransport,
+		},
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		userAgent: DefaultUserAgent,
+		requestID: newRequestID,
+	}
+}
+
+// newNetworkHTTPClient creates an HTTP client with the identification
+// settings from a NetworkConfig
+func newNetworkHTTPClient(baseURL string, config constellation.NetworkConfig) *HTTPClient {
+	client := NewHTTPClient(baseURL, config.Timeout).WithIdentity(config.UserAgent, config.RequestID)
+	if config.Transport != nil {
+		client.WithTransport(*config.Transport)
+	}
+	client.begin = config.BeginRequest
+	return client
+}
+
+// WithTransport gives the client its own connection pool tuned by config
+// instead of the pool shared by all SDK clients
+func (c *HTTPClient) WithTransport(config constellation.TransportConfig) *HTTPClient {
+	c.client.Transport = newTransport(config)
+	return c
+}
+
+// WithIdentity sets the User-Agent and the X-Request-ID generator sent with
+// every request. Empty values keep the defaults.
+func (c *HTTPClient) WithIdentity(userAgent string, requestID func() string) *HTTPClient {
+	if userAgent != "" {
+		c.userAgent = userAgent
+	}
+	if requestID != nil {
+		c.requestID = requestID
+	}
+	return c
+}
+
+// newRequestID returns a random 16-byte hex correlation ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// endpoint describes a request for error context, e.g. "GET http://node:9010/transactions"
+func (c *HTTPClient) endpoint(method string, path string) string {
+	return method + " " + c.baseURL + path
+}
+
+// Get makes a GET request
+func (c *HTTPClient) Get(path string, result interface{}) error {
+	return c.get(context.Background(), path, result)
+}
+
+// get makes a GET request that ctx can cancel
+func (c *HTTPClient) get(ctx context.Context, path string, result interface{}) error {
+	url := c.baseURL + path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return constellation.NewNetworkError(err.Error(), 0, "")
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	return c.doRequest(req, result)
+}
+
+// Post makes a POST request
+func (c *HTTPClient) Post(path string, body interface{}, result interface{}) error {
+	url := c.baseURL + path
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return constellation.NewNetworkError(fmt.Sprintf("failed to marshal body: %v", err), 0, "")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return constellation.NewNetworkError(err.Error(), 0, "")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	return c.doRequest(req, result)
+}
+
+func (c *HTTPClient) doRequest(req *http.Request, result interface{}) error {
+	requestID := c.identify(req)
+	err := c.doScoped(req, result)
+	if err != nil {
+		if netErr, ok := err.(*constellation.NetworkError); ok {
+			netErr.RequestID = requestID
+		}
+		return err
+	}
+	return nil
+}
+
+// identify sets the User-Agent and X-Request-ID headers and returns the
+// request ID
+func (c *HTTPClient) identify(req *http.Request) string {
+	requestID := c.requestID()
+	req.Header.Set("User-Agent", c.userAgent)
+	if requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	return requestID
+}
+
+// doScoped makes the request within the client's tenant scope, if any
+func (c *HTTPClient) doScoped(req *http.Request, result interface{}) error {
+	if c.begin == nil {
+		return c.do(req, result)
+	}
+	finish, err := c.begin(req.Context(), req.Method+" "+req.URL.String())
+	if err != nil {
+		return err
+	}
+	err = c.do(req, result)
+	finish(err)
+	return err
+}
+
+func (c *HTTPClient) do(req *http.Request, result interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if err, ok := err.(interface{ Timeout() bool }); ok && err.Timeout() {
+			return constellation.ErrRequestTimeout
+		}
+		return constellation.NewNetworkError(err.Error(), 0, "")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return constellation.NewNetworkError(fmt.Sprintf("failed to read response: %v", err), resp.StatusCode, "")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		netErr := constellation.NewNetworkError(
+			fmt.Sprintf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			resp.StatusCode,
+			string(body),
+		)
+		netErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		return netErr
+	}
+
+	if result != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, result); err != nil {
+			return constellation.NewNetworkError(fmt.Sprintf("failed to unmarshal response: %v", err), 0, string(body))
+		}
+	}
+
+	return nil
+}
+
+// parseRetryAfter parses a Retry-After header in either delay-seconds or HTTP-date form
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
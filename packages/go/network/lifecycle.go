@@ -0,0 +1,41 @@
+package network
+
+import "sync"
+
+// serviceState tracks the terminal state of a constellation.Service.
+// Components embed it and call finish exactly when their last goroutine
+// exits.
+type serviceState struct {
+	done chan struct{}
+	once sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// start prepares the state; call it from the constructor before any goroutine runs
+func (s *serviceState) start() {
+	s.done = make(chan struct{})
+}
+
+// finish records the terminal error and closes Done. Later calls are ignored.
+func (s *serviceState) finish(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// Done is closed once the component has stopped
+func (s *serviceState) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the terminal error, or nil while running or after a clean stop
+func (s *serviceState) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
@@ -0,0 +1,149 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// Default ports of the Euclid development environment
+const (
+	DefaultLocalnetHost         = "localhost"
+	DefaultLocalnetCurrencyPort = 9300
+	DefaultLocalnetDataPort     = 9400
+)
+
+// LocalnetGenesisKeyEnv is the environment variable consulted for the genesis
+// private key when LocalnetConfig.GenesisPrivateKey is empty
+const LocalnetGenesisKeyEnv = "CONSTELLATION_GENESIS_PRIVATE_KEY"
+
+var (
+	// ErrLocalnetNotFound indicates no local L1 node answered on the probed ports
+	ErrLocalnetNotFound = errors.New("no local metagraph found")
+	// ErrNoGenesisKey indicates funding was requested without a genesis key
+	ErrNoGenesisKey = errors.New("genesis private key is required to fund accounts")
+)
+
+// LocalnetConfig holds overrides for local network discovery.
+// Zero values fall back to the Euclid defaults.
+type LocalnetConfig struct {
+	// Host is the hostname running the cluster (default: "localhost")
+	Host string
+	// CurrencyL1Port is the Currency L1 public port (default: 9300)
+	CurrencyL1Port int
+	// DataL1Port is the Data L1 public port (default: 9400)
+	DataL1Port int
+	// GenesisPrivateKey funds test accounts; falls back to $CONSTELLATION_GENESIS_PRIVATE_KEY
+	GenesisPrivateKey string
+	// Timeout is the request timeout in seconds
+	Timeout int
+}
+
+// Localnet is a discovered local development cluster with its clients wired up
+//
+// Example:
+//
+//	local, err := DiscoverLocalnet(LocalnetConfig{})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Fund a fresh test account from the genesis key
+//	keyPair, _ := constellation.GenerateKeyPair()
+//	_, err = local.Fund(keyPair.Address, 1000)
+type Localnet struct {
+	// Config is the resolved network configuration
+	Config constellation.NetworkConfig
+	// CurrencyL1 is the Currency L1 client, nil if the node did not respond
+	CurrencyL1 *CurrencyL1Client
+	// DataL1 is the Data L1 client, nil if the node did not respond
+	DataL1 *DataL1Client
+
+	genesisKey string
+}
+
+// DiscoverLocalnet probes the default local ports and returns clients for
+// every node that responds
+//
+// Returns ErrLocalnetNotFound if neither a Currency L1 nor a Data L1 node is reachable.
+func DiscoverLocalnet(config LocalnetConfig) (*Localnet, error) {
+	host := config.Host
+	if host == "" {
+		host = DefaultLocalnetHost
+	}
+	currencyPort := config.CurrencyL1Port
+	if currencyPort == 0 {
+		currencyPort = DefaultLocalnetCurrencyPort
+	}
+	dataPort := config.DataL1Port
+	if dataPort == 0 {
+		dataPort = DefaultLocalnetDataPort
+	}
+	genesisKey := config.GenesisPrivateKey
+	if genesisKey == "" {
+		genesisKey = os.Getenv(LocalnetGenesisKeyEnv)
+	}
+
+	local := &Localnet{genesisKey: genesisKey}
+
+	currencyConfig := constellation.NetworkConfig{
+		L1URL:   fmt.Sprintf("http://%s:%d", host, currencyPort),
+		Timeout: config.Timeout,
+	}
+	if client, err := NewCurrencyL1Client(currencyConfig); err == nil && client.CheckHealth() {
+		local.CurrencyL1 = client
+		local.Config.L1URL = currencyConfig.L1URL
+	}
+
+	dataConfig := constellation.NetworkConfig{
+		DataL1URL: fmt.Sprintf("http://%s:%d", host, dataPort),
+		Timeout:   config.Timeout,
+	}
+	if client, err := NewDataL1Client(dataConfig); err == nil && client.CheckHealth() {
+		local.DataL1 = client
+		local.Config.DataL1URL = dataConfig.DataL1URL
+	}
+
+	if local.CurrencyL1 == nil && local.DataL1 == nil {
+		return nil, ErrLocalnetNotFound
+	}
+
+	local.Config.Timeout = config.Timeout
+	return local, nil
+}
+
+// Fund sends tokens from the genesis account to address
+//
+// The transaction is chained from the genesis account's last reference and
+// submitted to the local Currency L1.
+func (n *Localnet) Fund(address string, amount float64) (*constellation.PostTransactionResponse, error) {
+	if n.genesisKey == "" {
+		return nil, ErrNoGenesisKey
+	}
+	if n.CurrencyL1 == nil {
+		return nil, constellation.ErrL1URLRequired
+	}
+
+	genesis, err := constellation.KeyPairFromPrivateKey(n.genesisKey)
+	if err != nil {
+		return nil, err
+	}
+
+	lastRef, err := n.CurrencyL1.GetLastReference(genesis.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := constellation.CreateCurrencyTransaction(
+		constellation.TransferParams{Destination: address, Amount: amount},
+		n.genesisKey,
+		*lastRef,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.CurrencyL1.PostTransaction(tx)
+}
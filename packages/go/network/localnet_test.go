@@ -0,0 +1,162 @@
+package network
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// localnetNode is a Currency or Data L1 node that records posted transactions
+type localnetNode struct {
+	*httptest.Server
+	lastRef constellation.TransactionReference
+
+	mu     sync.Mutex
+	posted []*constellation.CurrencyTransaction
+}
+
+func newLocalnetNode(t *testing.T, lastRef constellation.TransactionReference) *localnetNode {
+	node := &localnetNode{lastRef: lastRef}
+	node.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/cluster/info":
+			w.Write([]byte(`[]`))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/transactions/last-reference/"):
+			json.NewEncoder(w).Encode(node.lastRef)
+		case r.Method == http.MethodPost && r.URL.Path == "/transactions":
+			var tx constellation.CurrencyTransaction
+			if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			node.mu.Lock()
+			node.posted = append(node.posted, &tx)
+			node.mu.Unlock()
+			w.Write([]byte(`{"hash":"` + constellation.HashCurrencyTransaction(&tx).Value + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(node.Close)
+	return node
+}
+
+// hostPort splits a test server's URL into the parts LocalnetConfig takes
+func hostPort(t *testing.T, server *httptest.Server) (string, int) {
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	n, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, n
+}
+
+// closedPort returns a port nothing listens on
+func closedPort(t *testing.T) int {
+	server := httptest.NewServer(http.NotFoundHandler())
+	_, port := hostPort(t, server)
+	server.Close()
+	return port
+}
+
+func TestDiscoverLocalnet(t *testing.T) {
+	genesis, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+	recipient, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+	lastRef := constellation.TransactionReference{Hash: constellation.GenesisReference.Hash, Ordinal: 7}
+
+	t.Run("wires up the nodes that answer", func(t *testing.T) {
+		node := newLocalnetNode(t, lastRef)
+		host, port := hostPort(t, node.Server)
+
+		local, err := DiscoverLocalnet(LocalnetConfig{Host: host, CurrencyL1Port: port, DataL1Port: closedPort(t), Timeout: 2})
+		require.NoError(t, err)
+		require.NotNil(t, local.CurrencyL1)
+		assert.Nil(t, local.DataL1)
+		assert.Equal(t, node.URL, local.Config.L1URL)
+		assert.Empty(t, local.Config.DataL1URL)
+		assert.Equal(t, 2, local.Config.Timeout)
+	})
+
+	t.Run("finds a data-only cluster", func(t *testing.T) {
+		node := newLocalnetNode(t, lastRef)
+		host, port := hostPort(t, node.Server)
+
+		local, err := DiscoverLocalnet(LocalnetConfig{
+			Host:              host,
+			CurrencyL1Port:    closedPort(t),
+			DataL1Port:        port,
+			GenesisPrivateKey: genesis.PrivateKey,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, local.CurrencyL1)
+		require.NotNil(t, local.DataL1)
+		assert.Equal(t, node.URL, local.Config.DataL1URL)
+
+		_, err = local.Fund(recipient.Address, 1)
+		assert.ErrorIs(t, err, constellation.ErrL1URLRequired)
+	})
+
+	t.Run("reports a missing cluster", func(t *testing.T) {
+		_, err := DiscoverLocalnet(LocalnetConfig{Host: "127.0.0.1", CurrencyL1Port: closedPort(t), DataL1Port: closedPort(t)})
+		assert.ErrorIs(t, err, ErrLocalnetNotFound)
+	})
+
+	t.Run("funds from the genesis account", func(t *testing.T) {
+		node := newLocalnetNode(t, lastRef)
+		host, port := hostPort(t, node.Server)
+
+		local, err := DiscoverLocalnet(LocalnetConfig{
+			Host:              host,
+			CurrencyL1Port:    port,
+			DataL1Port:        closedPort(t),
+			GenesisPrivateKey: genesis.PrivateKey,
+		})
+		require.NoError(t, err)
+
+		result, err := local.Fund(recipient.Address, 12.5)
+		require.NoError(t, err)
+
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		require.Len(t, node.posted, 1)
+		tx := node.posted[0]
+		assert.Equal(t, constellation.HashCurrencyTransaction(tx).Value, result.Hash)
+		assert.Equal(t, genesis.Address, tx.Value.Source)
+		assert.Equal(t, recipient.Address, tx.Value.Destination)
+		assert.Equal(t, int64(1_250_000_000), tx.Value.Amount)
+		assert.Equal(t, lastRef, tx.Value.Parent)
+		assert.True(t, constellation.VerifyCurrencyTransaction(tx).IsValid)
+	})
+
+	t.Run("reads the genesis key from the environment", func(t *testing.T) {
+		node := newLocalnetNode(t, lastRef)
+		host, port := hostPort(t, node.Server)
+
+		t.Setenv(LocalnetGenesisKeyEnv, "")
+		local, err := DiscoverLocalnet(LocalnetConfig{Host: host, CurrencyL1Port: port, DataL1Port: closedPort(t)})
+		require.NoError(t, err)
+		_, err = local.Fund(recipient.Address, 1)
+		assert.ErrorIs(t, err, ErrNoGenesisKey)
+
+		t.Setenv(LocalnetGenesisKeyEnv, genesis.PrivateKey)
+		local, err = DiscoverLocalnet(LocalnetConfig{Host: host, CurrencyL1Port: port, DataL1Port: closedPort(t)})
+		require.NoError(t, err)
+		_, err = local.Fund(recipient.Address, 1)
+		require.NoError(t, err)
+
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		require.Len(t, node.posted, 1)
+		assert.Equal(t, genesis.Address, node.posted[0].Value.Source)
+	})
+}
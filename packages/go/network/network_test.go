@@ -0,0 +1,655 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statsByURL(pool *constellation.EndpointPool) map[string]constellation.EndpointStats {
+	byURL := map[string]constellation.EndpointStats{}
+	for _, s := range pool.Stats() {
+		byURL[s.URL] = s
+	}
+	return byURL
+}
+
+func TestCurrencyL1ClientRequiresL1URL(t *testing.T) {
+	config := constellation.NetworkConfig{}
+	_, err := NewCurrencyL1Client(config)
+	assert.ErrorIs(t, err, constellation.ErrL1URLRequired)
+}
+
+func TestCurrencyL1ClientCreatesWithValidConfig(t *testing.T) {
+	config := constellation.NetworkConfig{L1URL: "http://localhost:9010"}
+	client, err := NewCurrencyL1Client(config)
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestCurrencyL1ClientAcceptsOptionalTimeout(t *testing.T) {
+	config := constellation.NetworkConfig{
+		L1URL:   "http://localhost:9010",
+		Timeout: 5,
+	}
+	client, err := NewCurrencyL1Client(config)
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestDataL1ClientRequiresDataL1URL(t *testing.T) {
+	config := constellation.NetworkConfig{}
+	_, err := NewDataL1Client(config)
+	assert.ErrorIs(t, err, constellation.ErrDataL1URLRequired)
+}
+
+func TestDataL1ClientCreatesWithValidConfig(t *testing.T) {
+	config := constellation.NetworkConfig{DataL1URL: "http://localhost:8080"}
+	client, err := NewDataL1Client(config)
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestDataL1ClientAcceptsOptionalTimeout(t *testing.T) {
+	config := constellation.NetworkConfig{
+		DataL1URL: "http://localhost:8080",
+		Timeout:   10,
+	}
+	client, err := NewDataL1Client(config)
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestNetworkErrorMessage(t *testing.T) {
+	err := constellation.NewNetworkError("Connection failed", 0, "")
+	assert.Equal(t, "Connection failed", err.Error())
+	assert.Equal(t, 0, err.StatusCode)
+}
+
+func TestNetworkErrorWithStatusCode(t *testing.T) {
+	err := constellation.NewNetworkError("Not found", 404, "")
+	assert.Contains(t, err.Error(), "Not found")
+	assert.Contains(t, err.Error(), "404")
+	assert.Equal(t, 404, err.StatusCode)
+}
+
+func TestNetworkErrorWithResponseBody(t *testing.T) {
+	err := constellation.NewNetworkError("Bad request", 400, `{"error":"invalid"}`)
+	assert.Equal(t, 400, err.StatusCode)
+	assert.Equal(t, `{"error":"invalid"}`, err.Response)
+}
+
+func TestCombinedConfigBothURLs(t *testing.T) {
+	config := constellation.NetworkConfig{
+		L1URL:     "http://localhost:9010",
+		DataL1URL: "http://localhost:8080",
+		Timeout:   30,
+	}
+
+	l1Client, err := NewCurrencyL1Client(config)
+	assert.NoError(t, err)
+	assert.NotNil(t, l1Client)
+
+	dataClient, err := NewDataL1Client(config)
+	assert.NoError(t, err)
+	assert.NotNil(t, dataClient)
+}
+
+func TestCurrencyL1ClientGetLastReferences(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		address := strings.TrimPrefix(r.URL.Path, "/transactions/last-reference/")
+		if address == "DAGbroken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"hash":"` + address + `","ordinal":3}`))
+	}))
+	defer server.Close()
+
+	client, err := NewCurrencyL1Client(constellation.NetworkConfig{L1URL: server.URL})
+	require.NoError(t, err)
+
+	var addresses []string
+	for i := 0; i < 50; i++ {
+		addresses = append(addresses, fmt.Sprintf("DAG%02d", i))
+	}
+	addresses = append(addresses, "DAG00", "DAGbroken")
+
+	refs, err := client.GetLastReferences(addresses)
+	var batchErr *constellation.BatchLookupError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 1)
+	assert.Contains(t, batchErr.Errors, "DAGbroken")
+
+	assert.Len(t, refs, 50)
+	assert.Equal(t, &constellation.TransactionReference{Hash: "DAG07", Ordinal: 3}, refs["DAG07"])
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(bulkLookupConcurrency))
+}
+
+func TestCurrencyL0ClientGetBalances(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/currency/"), "/balance")
+		switch address {
+		case "DAGmissing":
+			w.WriteHeader(http.StatusNotFound)
+		case "DAGdown":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"ordinal":12,"balance":` + strings.TrimPrefix(address, "DAG") + `}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewCurrencyL0Client(constellation.NetworkConfig{L0URL: server.URL})
+	require.NoError(t, err)
+
+	t.Run("returns every balance", func(t *testing.T) {
+		balances, err := client.GetBalances([]string{"DAG1", "DAG2", "DAG300"})
+		require.NoError(t, err)
+		assert.Equal(t, &constellation.BalanceResponse{Ordinal: 12, Balance: 300}, balances["DAG300"])
+		assert.Len(t, balances, 3)
+	})
+
+	t.Run("reports partial results", func(t *testing.T) {
+		balances, err := client.GetBalances([]string{"DAG5", "DAGmissing", "DAGdown"})
+		var batchErr *constellation.BatchLookupError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, "balance", batchErr.Op)
+		assert.Equal(t, []string{"DAGdown", "DAGmissing"}, batchErr.Addresses())
+		assert.Contains(t, err.Error(), "balance lookup failed for 2 address(es)")
+		assert.Equal(t, int64(5), balances["DAG5"].Balance)
+		assert.Len(t, balances, 1)
+	})
+}
+
+func TestCurrencyL0ClientGetBalanceAt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/snapshots/100/combined":
+			_, _ = w.Write([]byte(`[{"value":{"ordinal":100},"proofs":[]},{"balances":{"DAGalice":2500,"DAGbob":10}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewCurrencyL0Client(constellation.NetworkConfig{L0URL: server.URL})
+	require.NoError(t, err)
+
+	balance, err := client.GetBalanceAt("DAGalice", 100)
+	require.NoError(t, err)
+	assert.Equal(t, &constellation.BalanceResponse{Ordinal: 100, Balance: 2500}, balance)
+
+	balance, err = client.GetBalanceAt("DAGcarol", 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), balance.Balance)
+
+	balance, err = client.GetBalanceAt("DAGalice", 99)
+	require.NoError(t, err)
+	assert.Nil(t, balance)
+}
+
+func TestGlobalL0ClientStaking(t *testing.T) {
+	t.Run("requires GlobalL0URL", func(t *testing.T) {
+		_, err := NewGlobalL0Client(constellation.NetworkConfig{})
+		assert.ErrorIs(t, err, constellation.ErrGlobalL0URLRequired)
+	})
+
+	t.Run("estimates rewards from live parameters", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/node/info", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"version":"3.1.0"}`))
+		})
+		mux.HandleFunc("/delegated-stakes/rewards-info", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"epochsPerYear":730,"totalDelegatedAmount":100000000000,"totalRewardPerEpoch":1000000000}`))
+		})
+		mux.HandleFunc("/node-params", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"peerId":"peer1","name":"node one","rewardFraction":0.1,"totalAmountDelegated":25000000000}]`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client, err := NewGlobalL0Client(constellation.NetworkConfig{GlobalL0URL: server.URL})
+		require.NoError(t, err)
+		estimate, err := constellation.EstimateNodeRewardsFrom(client, "peer1")
+		require.NoError(t, err)
+		require.NotNil(t, estimate)
+		assert.Equal(t, constellation.TokenToUnits(2.5), estimate.RewardPerEpoch)
+		assert.Equal(t, constellation.TokenToUnits(4.5), estimate.DelegatorsPerDay)
+	})
+
+	t.Run("reports nodes without delegated staking", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"version":"2.8.1"}`))
+		}))
+		defer server.Close()
+
+		client, err := NewGlobalL0Client(constellation.NetworkConfig{GlobalL0URL: server.URL})
+		require.NoError(t, err)
+		_, err = client.GetRewardsInfo()
+		assert.ErrorIs(t, err, constellation.ErrFeatureUnsupported)
+		_, err = client.GetNodeParams()
+		assert.ErrorIs(t, err, constellation.ErrFeatureUnsupported)
+	})
+}
+
+var (
+	_ constellation.CurrencyL1API = (*DAGL1Client)(nil)
+	_ constellation.BalanceSource = (*GlobalL0Client)(nil)
+)
+
+func TestDAGL1Client(t *testing.T) {
+	t.Run("requires DAGL1URL", func(t *testing.T) {
+		_, err := NewDAGL1Client(constellation.NetworkConfig{L1URL: "http://localhost:9010"})
+		assert.ErrorIs(t, err, constellation.ErrDAGL1URLRequired)
+	})
+
+	t.Run("sends DAG with the same key pair", func(t *testing.T) {
+		keyPair, err := constellation.GenerateKeyPair()
+		require.NoError(t, err)
+		recipient, err := constellation.GenerateKeyPair()
+		require.NoError(t, err)
+		lastRef := constellation.TransactionReference{Hash: strings.Repeat("ab", 32), Ordinal: 7}
+
+		var posted constellation.DAGTransaction
+		mux := http.NewServeMux()
+		mux.HandleFunc("/transactions/last-reference/"+keyPair.Address, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(lastRef)
+		})
+		mux.HandleFunc("/transactions", func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+			_, _ = w.Write([]byte(`{"hash":"` + constellation.HashCurrencyTransaction(&posted).Value + `"}`))
+		})
+		mux.HandleFunc("/dag/"+keyPair.Address+"/balance", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"ordinal":900,"balance":2500000000}`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		l1, err := NewDAGL1Client(constellation.NetworkConfig{DAGL1URL: server.URL})
+		require.NoError(t, err)
+		l0, err := NewGlobalL0Client(constellation.NetworkConfig{GlobalL0URL: server.URL})
+		require.NoError(t, err)
+
+		balance, err := l0.GetBalance(keyPair.Address)
+		require.NoError(t, err)
+		assert.Equal(t, &constellation.BalanceResponse{Ordinal: 900, Balance: 2500000000}, balance)
+
+		ref, err := l1.GetLastReference(keyPair.Address)
+		require.NoError(t, err)
+		tx, err := constellation.CreateDAGTransaction(constellation.TransferParams{Destination: recipient.Address, Amount: 12.5, Fee: 0.001}, keyPair.PrivateKey, *ref)
+		require.NoError(t, err)
+		assert.Equal(t, lastRef, tx.Value.Parent)
+		assert.True(t, constellation.VerifyCurrencyTransactionStrict(tx))
+
+		result, err := l1.PostTransaction(tx)
+		require.NoError(t, err)
+		assert.Equal(t, constellation.HashCurrencyTransaction(tx).Value, result.Hash)
+		assert.Equal(t, server.URL, result.Node)
+		assert.True(t, constellation.VerifyCurrencyTransactionStrict(&posted))
+		assert.Equal(t, constellation.TokenToUnits(12.5), posted.Value.Amount)
+	})
+}
+
+func TestClientIdentificationHeaders(t *testing.T) {
+	var userAgent, requestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		requestID = r.Header.Get(RequestIDHeader)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	t.Run("defaults", func(t *testing.T) {
+		client, err := NewCurrencyL1Client(constellation.NetworkConfig{L1URL: server.URL})
+		require.NoError(t, err)
+
+		_, err = client.GetLastReference("DAG1")
+		var netErr *constellation.NetworkError
+		require.ErrorAs(t, err, &netErr)
+		assert.Equal(t, DefaultUserAgent, userAgent)
+		assert.Len(t, requestID, 32)
+		assert.Equal(t, requestID, netErr.RequestID)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		var n int
+		client, err := NewCurrencyL0Client(constellation.NetworkConfig{
+			L0URL:     server.URL,
+			UserAgent: "payouts/1.4",
+			RequestID: func() string { n++; return fmt.Sprintf("payout-%d", n) },
+		})
+		require.NoError(t, err)
+
+		_, err = client.GetBalance("DAG1")
+		require.Error(t, err)
+		assert.Equal(t, "payouts/1.4", userAgent)
+		assert.Equal(t, "payout-1", requestID)
+
+		_, _ = client.GetBalance("DAG1")
+		assert.Equal(t, "payout-2", requestID)
+	})
+
+	t.Run("every client", func(t *testing.T) {
+		var mu sync.Mutex
+		seen := map[string]string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			seen[r.URL.Path] = r.Header.Get("User-Agent") + " " + r.Header.Get(RequestIDHeader)
+			mu.Unlock()
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+		requestID := func() string { return "req-1" }
+		keyPair, _ := constellation.GenerateKeyPair()
+
+		faucet, err := NewFaucetClient(FaucetConfig{URL: server.URL + "/faucet", UserAgent: "ua/faucet", RequestID: requestID})
+		require.NoError(t, err)
+		_, _ = faucet.Request(keyPair.Address)
+
+		rates := NewCoinGeckoRateProvider(CoinGeckoConfig{BaseURL: server.URL + "/coingecko", UserAgent: "ua/coingecko", RequestID: requestID})
+		_, _ = rates.Rate("DAG", "usd", time.Now())
+
+		dispatcher, err := NewWebhookDispatcher(context.Background(), WebhookConfig{
+			Endpoints:   []WebhookEndpoint{{URL: server.URL + "/webhook"}},
+			MaxAttempts: 1,
+			UserAgent:   "ua/webhook",
+			RequestID:   requestID,
+		})
+		require.NoError(t, err)
+		dispatcher.Handle(constellation.TxConfirmed{Hash: "abc"})
+		require.NoError(t, dispatcher.Close())
+
+		subscriber, err := NewSnapshotSubscriber(context.Background(), SnapshotSubscriberConfig{
+			StreamURL: server.URL + "/stream",
+			Poll:      &lockedExplorer{fakeExplorer: newFakeExplorer()},
+			Network:   constellation.NetworkConfig{UserAgent: "ua/stream", RequestID: requestID},
+		})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return seen["/stream"] != ""
+		}, time.Second, time.Millisecond)
+		require.NoError(t, subscriber.Close())
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "ua/faucet req-1", seen["/faucet/faucet/"+keyPair.Address])
+		assert.Equal(t, "ua/coingecko req-1", seen["/coingecko/coins/constellation-labs/history"])
+		assert.Equal(t, "ua/webhook req-1", seen["/webhook"])
+		assert.Equal(t, "ua/stream req-1", seen["/stream"])
+	})
+}
+
+func TestClientErrorsCarryOperationContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"parent not found"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewCurrencyL1Client(constellation.NetworkConfig{L1URL: server.URL})
+	require.NoError(t, err)
+
+	_, err = client.GetLastReference("DAG6eH4ryz7jyQAJpQi2tQ3jueiZP3f3wD5iAu6K")
+
+	var opErr *constellation.OpError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "getLastReference", opErr.Op)
+	assert.Equal(t, "DAG6…Au6K", opErr.Address)
+	assert.Equal(t, "GET "+server.URL+"/transactions/last-reference/DAG6…Au6K", opErr.Endpoint)
+
+	var netErr *constellation.NetworkError
+	require.ErrorAs(t, err, &netErr)
+	assert.Equal(t, 400, netErr.StatusCode)
+}
+
+func TestPooledCurrencyL1Client(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hash":"abc","ordinal":7}`))
+	}))
+	defer healthy.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	newClient := func(t *testing.T, urls ...string) (*PooledCurrencyL1Client, *constellation.EndpointPool) {
+		pool, err := constellation.NewEndpointPool(context.Background(), constellation.EndpointPoolConfig{URLs: urls})
+		require.NoError(t, err)
+		t.Cleanup(func() { pool.Close() })
+		client, err := NewPooledCurrencyL1Client(pool, constellation.NetworkConfig{Timeout: 5})
+		require.NoError(t, err)
+		return client, pool
+	}
+
+	t.Run("routes around failing nodes", func(t *testing.T) {
+		client, pool := newClient(t, healthy.URL, broken.URL)
+
+		successes := 0
+		for i := 0; i < 40; i++ {
+			if ref, err := client.GetLastReference("DAG0addr"); err == nil {
+				assert.Equal(t, 7, int(ref.Ordinal))
+				successes++
+			}
+		}
+		assert.Greater(t, successes, 30)
+
+		stats := statsByURL(pool)
+		assert.Zero(t, stats[healthy.URL].Failures)
+		assert.Equal(t, stats[broken.URL].Requests, stats[broken.URL].Failures)
+		assert.Greater(t, stats[healthy.URL].Weight, stats[broken.URL].Weight)
+	})
+
+	t.Run("does not blame nodes for rejected requests", func(t *testing.T) {
+		client, pool := newClient(t, rejecting.URL)
+
+		_, err := client.GetLastReference("DAG0addr")
+		var netErr *constellation.NetworkError
+		require.ErrorAs(t, err, &netErr)
+		assert.Equal(t, http.StatusBadRequest, netErr.StatusCode)
+
+		stats := statsByURL(pool)[rejecting.URL]
+		assert.Equal(t, int64(1), stats.Requests)
+		assert.Zero(t, stats.Failures)
+	})
+
+	t.Run("probes over HTTP", func(t *testing.T) {
+		probe := HTTPEndpointProbe("/cluster/info", constellation.NetworkConfig{Timeout: 5})
+		assert.NoError(t, probe(context.Background(), healthy.URL))
+		assert.Error(t, probe(context.Background(), broken.URL))
+	})
+}
+
+func TestPooledCurrencyL1ClientSourceAffinity(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]map[string]bool{} // node -> sources
+	newNode := func() *httptest.Server {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var tx constellation.CurrencyTransaction
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&tx))
+			mu.Lock()
+			if received[server.URL] == nil {
+				received[server.URL] = map[string]bool{}
+			}
+			received[server.URL][tx.Value.Source] = true
+			mu.Unlock()
+			w.Write([]byte(`{"hash":"h"}`))
+		}))
+		return server
+	}
+	nodes := []*httptest.Server{newNode(), newNode(), newNode()}
+	urls := make([]string, len(nodes))
+	for i, node := range nodes {
+		defer node.Close()
+		urls[i] = node.URL
+	}
+
+	pool, err := constellation.NewEndpointPool(context.Background(), constellation.EndpointPoolConfig{URLs: urls})
+	require.NoError(t, err)
+	defer pool.Close()
+	client, err := NewPooledCurrencyL1Client(pool, constellation.NetworkConfig{Timeout: 5})
+	require.NoError(t, err)
+	client.WithSourceAffinity()
+
+	destination, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+	for i := 0; i < 8; i++ {
+		kp, err := constellation.GenerateKeyPair()
+		require.NoError(t, err)
+		ref := constellation.TransactionReference{Hash: strings.Repeat("0", 64), Ordinal: 0}
+		for j := 0; j < 5; j++ {
+			tx, err := constellation.CreateCurrencyTransaction(constellation.TransferParams{Destination: destination.Address, Amount: 1}, kp.PrivateKey, ref)
+			require.NoError(t, err)
+			_, err = client.PostTransaction(tx)
+			require.NoError(t, err)
+			ref = *constellation.GetTransactionReference(tx, ref.Ordinal+1)
+		}
+	}
+
+	seen := map[string]string{}
+	for node, sources := range received {
+		for source := range sources {
+			if other, ok := seen[source]; ok {
+				t.Errorf("%s submitted to both %s and %s", source, other, node)
+			}
+			seen[source] = node
+		}
+	}
+	assert.Len(t, seen, 8)
+}
+
+// consistencyNode is a fake L1 node whose last reference can lag behind
+// the transactions it has accepted
+type consistencyNode struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	lastRef  constellation.TransactionReference
+	pending  map[string]bool
+	lagReads int
+}
+
+func newConsistencyNode(t *testing.T) *consistencyNode {
+	node := &consistencyNode{pending: map[string]bool{}}
+	node.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost:
+			var tx constellation.CurrencyTransaction
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&tx))
+			hash := constellation.HashCurrencyTransaction(&tx).Value
+			node.pending[hash] = true
+			node.lastRef = constellation.TransactionReference{Hash: hash, Ordinal: tx.Value.Parent.Ordinal + 1}
+			json.NewEncoder(w).Encode(constellation.PostTransactionResponse{Hash: hash})
+		case strings.HasPrefix(r.URL.Path, "/transactions/last-reference/"):
+			if node.lagReads > 0 {
+				node.lagReads--
+				json.NewEncoder(w).Encode(constellation.TransactionReference{Hash: "stale"})
+				return
+			}
+			json.NewEncoder(w).Encode(node.lastRef)
+		default:
+			hash := strings.TrimPrefix(r.URL.Path, "/transactions/")
+			if !node.pending[hash] {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(constellation.PendingTransaction{Hash: hash, Status: constellation.StatusWaiting})
+		}
+	}))
+	t.Cleanup(node.server.Close)
+	return node
+}
+
+func TestPooledCurrencyL1ClientReadConsistency(t *testing.T) {
+	kp, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+	destination, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+	tx, err := constellation.CreateCurrencyTransaction(constellation.TransferParams{Destination: destination.Address, Amount: 1}, kp.PrivateKey,
+		constellation.TransactionReference{Hash: strings.Repeat("0", 64), Ordinal: 0})
+	require.NoError(t, err)
+
+	t.Run("pinned reads go to the submission node", func(t *testing.T) {
+		nodes := []*consistencyNode{newConsistencyNode(t), newConsistencyNode(t), newConsistencyNode(t)}
+		pool, err := constellation.NewEndpointPool(context.Background(), constellation.EndpointPoolConfig{
+			URLs: []string{nodes[0].server.URL, nodes[1].server.URL, nodes[2].server.URL},
+		})
+		require.NoError(t, err)
+		defer pool.Close()
+		client, err := NewPooledCurrencyL1Client(pool, constellation.NetworkConfig{Timeout: 5})
+		require.NoError(t, err)
+		client.WithReadConsistency(constellation.ReadConsistencyConfig{Mode: constellation.ConsistencyPinned})
+
+		result, err := client.PostTransaction(tx)
+		require.NoError(t, err)
+		assert.Contains(t, []string{nodes[0].server.URL, nodes[1].server.URL, nodes[2].server.URL}, result.Node)
+
+		for i := 0; i < 20; i++ {
+			ref, err := client.GetLastReference(kp.Address)
+			require.NoError(t, err)
+			assert.Equal(t, 1, ref.Ordinal)
+
+			pending, err := client.GetPendingTransaction(result.Hash)
+			require.NoError(t, err)
+			require.NotNil(t, pending)
+		}
+	})
+
+	t.Run("wait polls until the reference reflects the submission", func(t *testing.T) {
+		node := newConsistencyNode(t)
+		pool, err := constellation.NewEndpointPool(context.Background(), constellation.EndpointPoolConfig{URLs: []string{node.server.URL}})
+		require.NoError(t, err)
+		defer pool.Close()
+		client, err := NewPooledCurrencyL1Client(pool, constellation.NetworkConfig{Timeout: 5})
+		require.NoError(t, err)
+		client.WithReadConsistency(constellation.ReadConsistencyConfig{Mode: constellation.ConsistencyWait, PollInterval: time.Millisecond})
+
+		_, err = client.PostTransaction(tx)
+		require.NoError(t, err)
+		node.mu.Lock()
+		node.lagReads = 3
+		node.mu.Unlock()
+
+		ref, err := client.GetLastReference(kp.Address)
+		require.NoError(t, err)
+		assert.Equal(t, 1, ref.Ordinal)
+
+		// once caught up, later reads do not wait
+		node.mu.Lock()
+		node.lagReads = 1
+		node.mu.Unlock()
+		ref, err = client.GetLastReference(kp.Address)
+		require.NoError(t, err)
+		assert.Equal(t, "stale", ref.Hash)
+	})
+}
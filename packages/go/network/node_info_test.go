@@ -0,0 +1,127 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNodeVersion(t *testing.T) {
+	t.Run("parses plain and prefixed versions", func(t *testing.T) {
+		v, ok := constellation.ParseNodeVersion("2.8.1")
+		require.True(t, ok)
+		assert.Equal(t, constellation.NodeVersion{Major: 2, Minor: 8, Patch: 1}, v)
+
+		v, ok = constellation.ParseNodeVersion("v3.0.0-rc.2")
+		require.True(t, ok)
+		assert.Equal(t, constellation.NodeVersion{Major: 3}, v)
+
+		v, ok = constellation.ParseNodeVersion("2.9")
+		require.True(t, ok)
+		assert.Equal(t, constellation.NodeVersion{Major: 2, Minor: 9}, v)
+	})
+
+	t.Run("rejects malformed versions", func(t *testing.T) {
+		for _, input := range []string{"", "latest", "1.2.3.4", "1.x.0"} {
+			_, ok := constellation.ParseNodeVersion(input)
+			assert.False(t, ok, input)
+		}
+	})
+
+	t.Run("gates features by minimum version", func(t *testing.T) {
+		old := constellation.NodeVersion{Major: 2, Minor: 2, Patch: 9}
+		assert.False(t, old.Supports(constellation.FeatureEstimateFee))
+		assert.False(t, old.Supports(constellation.FeatureDelegatedStaking))
+
+		current := constellation.NodeVersion{Major: 3, Minor: 1}
+		assert.True(t, current.Supports(constellation.FeatureEstimateFee))
+		assert.True(t, current.Supports(constellation.FeatureDelegatedStaking))
+		assert.True(t, current.Supports(constellation.Feature("unknown")))
+	})
+}
+
+func newNodeInfoServer(t *testing.T, version string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/node/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"state":"Ready","id":"abc","version":"` + version + `"}`))
+	})
+	mux.HandleFunc("/data/estimate-fee", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"fee":100,"address":"DAG0"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDataL1ClientFeatureNegotiation(t *testing.T) {
+	t.Run("estimate fee is rejected on old nodes", func(t *testing.T) {
+		server := newNodeInfoServer(t, "2.1.0")
+		client, err := NewDataL1Client(constellation.NetworkConfig{DataL1URL: server.URL})
+		require.NoError(t, err)
+
+		info, err := client.GetNodeInfo()
+		require.NoError(t, err)
+		assert.Equal(t, "2.1.0", info.Version)
+
+		_, err = client.EstimateFee(map[string]interface{}{})
+		assert.ErrorIs(t, err, constellation.ErrFeatureUnsupported)
+	})
+
+	t.Run("estimate fee is served on current nodes", func(t *testing.T) {
+		server := newNodeInfoServer(t, "3.2.0")
+		client, err := NewDataL1Client(constellation.NetworkConfig{DataL1URL: server.URL})
+		require.NoError(t, err)
+
+		fee, err := client.EstimateFee(map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), fee.Fee)
+	})
+
+	t.Run("a failed lookup is retried only after a while", func(t *testing.T) {
+		var lookups int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/node/info" {
+				atomic.AddInt32(&lookups, 1)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"fee":100,"address":"DAG0"}`))
+		}))
+		defer server.Close()
+
+		client, err := NewDataL1Client(constellation.NetworkConfig{DataL1URL: server.URL})
+		require.NoError(t, err)
+		now := time.Now()
+		client.features.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			_, err = client.EstimateFee(map[string]interface{}{})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+
+		now = now.Add(nodeInfoRetryInterval)
+		_, err = client.EstimateFee(map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+	})
+
+	t.Run("missing endpoint maps to unsupported", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		client, err := NewDataL1Client(constellation.NetworkConfig{DataL1URL: server.URL})
+		require.NoError(t, err)
+
+		_, err = client.EstimateFee(map[string]interface{}{})
+		assert.ErrorIs(t, err, constellation.ErrFeatureUnsupported)
+	})
+}
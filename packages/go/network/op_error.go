@@ -0,0 +1,21 @@
+package network
+
+import (
+	"strings"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// wrapOp wraps err in a constellation.OpError, redacting address wherever
+// it appears; nil stays nil
+func wrapOp(op string, address string, endpoint string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if address != "" {
+		short := constellation.RedactAddress(address)
+		endpoint = strings.ReplaceAll(endpoint, address, short)
+		address = short
+	}
+	return &constellation.OpError{Op: op, Address: address, Endpoint: endpoint, Err: err}
+}
@@ -0,0 +1,54 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+func TestOpError(t *testing.T) {
+	t.Run("formats operation context and unwraps", func(t *testing.T) {
+		cause := constellation.NewNetworkError("HTTP 400: Bad Request", 400, `{"error":"parent not found"}`)
+		err := wrapOp("postTransaction", "DAG6eH4ryz7jyQAJpQi2tQ3jueiZP3f3wD5iAu6K", "POST http://node:9010/transactions", cause)
+
+		assert.Equal(t, "postTransaction DAG6…Au6K POST http://node:9010/transactions -> HTTP 400: Bad Request (status: 400)", err.Error())
+		assert.NotContains(t, err.Error(), "DAG6eH4ryz7jyQAJpQi2tQ3jueiZP3f3wD5iAu6K")
+
+		var netErr *constellation.NetworkError
+		assert.True(t, errors.As(err, &netErr))
+		assert.Equal(t, 400, netErr.StatusCode)
+
+		var opErr *constellation.OpError
+		assert.True(t, errors.As(err, &opErr))
+		assert.Equal(t, "postTransaction", opErr.Op)
+	})
+
+	t.Run("keeps sentinels matchable", func(t *testing.T) {
+		err := wrapOp("getLastReference", "", "", constellation.ErrRequestTimeout)
+		assert.ErrorIs(t, err, constellation.ErrRequestTimeout)
+		assert.Equal(t, "getLastReference -> request timeout", err.Error())
+	})
+
+	t.Run("is not a NetworkError to type assertions", func(t *testing.T) {
+		err := wrapOp("getLastReference", "", "", constellation.NewNetworkError("HTTP 503", 503, ""))
+		_, ok := err.(*constellation.NetworkError)
+		assert.False(t, ok, "callers must migrate to errors.As")
+		assert.Equal(t, 503, errors.Unwrap(err).(*constellation.NetworkError).StatusCode)
+	})
+
+	t.Run("redacts the address", func(t *testing.T) {
+		kp, err := constellation.GenerateKeyPair()
+		require.NoError(t, err)
+		err = wrapOp("getBalance", kp.Address, "GET http://node/currency/"+kp.Address+"/balance", constellation.ErrRequestTimeout)
+		assert.NotContains(t, err.Error(), kp.Address)
+		assert.Contains(t, err.Error(), constellation.RedactAddress(kp.Address))
+	})
+
+	t.Run("nil stays nil", func(t *testing.T) {
+		assert.NoError(t, wrapOp("postData", "", "", nil))
+	})
+}
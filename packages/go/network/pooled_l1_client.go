@@ -0,0 +1,302 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// HTTPEndpointProbe returns an EndpointProbe that GETs path on each
+// endpoint, e.g. "/cluster/info", using the identity and timeout of config
+func HTTPEndpointProbe(path string, config constellation.NetworkConfig) constellation.EndpointProbe {
+	return func(ctx context.Context, url string) error {
+		client := newNetworkHTTPClient(url, config)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.baseURL+path, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		return client.doRequest(req, nil)
+	}
+}
+
+// PooledCurrencyL1Client implements CurrencyL1API over an EndpointPool,
+// sending each request to the node the pool picks and reporting its latency
+// and outcome back to the pool
+//
+// Only failures that point at the node count against it: timeouts,
+// connection errors, HTTP 429 and 5xx. A 4xx rejection of a transaction is
+// the transaction's fault and does not lower the node's weight.
+//
+// Example:
+//
+//	pool, _ := constellation.NewEndpointPool(ctx, constellation.EndpointPoolConfig{URLs: discovery.Endpoints().L1})
+//	client, err := NewPooledCurrencyL1Client(pool, constellation.NetworkConfig{Timeout: 10})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	lastRef, err := client.GetLastReference("DAG...")
+type PooledCurrencyL1Client struct {
+	pool     *constellation.EndpointPool
+	config   constellation.NetworkConfig
+	affinity bool
+
+	mu          sync.Mutex
+	clients     map[string]*CurrencyL1Client
+	consistency constellation.ReadConsistencyConfig
+	// pinnedSources and pinnedTxs route ConsistencyPinned reads by source
+	// address and transaction hash
+	pinnedSources map[string]nodePin
+	pinnedTxs     map[string]nodePin
+	// expected holds each source's latest submission for ConsistencyWait
+	expected map[string]constellation.TransactionReference
+}
+
+// nodePin routes reads to the node that accepted a submission until it expires
+type nodePin struct {
+	url   string
+	until time.Time
+}
+
+// NewPooledCurrencyL1Client creates a client routing through pool. L1URL in
+// config is ignored; the other settings apply to every node.
+//
+// Returns ErrNoEndpoints if pool is nil
+func NewPooledCurrencyL1Client(pool *constellation.EndpointPool, config constellation.NetworkConfig) (*PooledCurrencyL1Client, error) {
+	if pool == nil {
+		return nil, constellation.ErrNoEndpoints
+	}
+	return &PooledCurrencyL1Client{
+		pool:          pool,
+		config:        config,
+		clients:       map[string]*CurrencyL1Client{},
+		consistency:   consistencyDefaults(constellation.ReadConsistencyConfig{}),
+		pinnedSources: map[string]nodePin{},
+		pinnedTxs:     map[string]nodePin{},
+		expected:      map[string]constellation.TransactionReference{},
+	}, nil
+}
+
+// WithSourceAffinity routes every submission from the same source address,
+// and that address's last-reference lookups, to the same node by consistent
+// hashing over the pool. Chained transactions then reach one mempool in
+// order instead of racing across nodes, which otherwise causes spurious
+// "parent not found" rejections. A node whose error rate reaches 50% hands
+// its addresses to the next node on the ring until it recovers; adding or
+// removing a node only moves the addresses it owned.
+func (c *PooledCurrencyL1Client) WithSourceAffinity() *PooledCurrencyL1Client {
+	c.affinity = true
+	return c
+}
+
+// Pool returns the pool the client routes through
+func (c *PooledCurrencyL1Client) Pool() *constellation.EndpointPool {
+	return c.pool
+}
+
+// WithReadConsistency makes reads after a submission reflect it, either by
+// pinning them to the node that accepted the submission or by waiting for
+// the reference to catch up (see ReadConsistencyMode)
+func (c *PooledCurrencyL1Client) WithReadConsistency(config constellation.ReadConsistencyConfig) *PooledCurrencyL1Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consistency = consistencyDefaults(config)
+	return c
+}
+
+// GetLastReference gets the last accepted transaction reference for an address
+//
+// With ConsistencyWait, a lookup for an address this client submitted from
+// waits until the node reports that submission, or returns the reference it
+// saw with ErrReadNotConsistent.
+func (c *PooledCurrencyL1Client) GetLastReference(address string) (*constellation.TransactionReference, error) {
+	c.mu.Lock()
+	expected, waiting := c.expected[address]
+	consistency := c.consistency
+	c.mu.Unlock()
+
+	if consistency.Mode != constellation.ConsistencyWait || !waiting {
+		return c.getLastReference(address)
+	}
+
+	ref, err := waitForLastReference(c.getLastReference, address, expected, consistency.WaitTimeout, consistency.PollInterval)
+	if err == nil {
+		c.mu.Lock()
+		if current, ok := c.expected[address]; ok && current.Ordinal <= ref.Ordinal {
+			delete(c.expected, address)
+		}
+		c.mu.Unlock()
+	}
+	return ref, err
+}
+
+func (c *PooledCurrencyL1Client) getLastReference(address string) (*constellation.TransactionReference, error) {
+	url, err := c.pickRead(address, c.pinnedSources, address)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *constellation.TransactionReference
+	err = c.callNode(url, func(client *CurrencyL1Client) (err error) {
+		result, err = client.GetLastReference(address)
+		return err
+	})
+	return result, err
+}
+
+// PostTransaction submits a signed currency transaction
+func (c *PooledCurrencyL1Client) PostTransaction(transaction *constellation.CurrencyTransaction) (*constellation.PostTransactionResponse, error) {
+	url, err := c.pick(transaction.Value.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *constellation.PostTransactionResponse
+	err = c.callNode(url, func(client *CurrencyL1Client) (err error) {
+		result, err = client.PostTransaction(transaction)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.recordWrite(url, transaction, result.Hash)
+	return result, nil
+}
+
+// GetPendingTransaction gets a pending transaction by hash
+//
+// Returns nil if the node picked does not hold the transaction. With
+// ConsistencyPinned, transactions this client submitted are looked up on
+// the node that accepted them.
+func (c *PooledCurrencyL1Client) GetPendingTransaction(hash string) (*constellation.PendingTransaction, error) {
+	url, err := c.pickRead("", c.pinnedTxs, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *constellation.PendingTransaction
+	err = c.callNode(url, func(client *CurrencyL1Client) (err error) {
+		result, err = client.GetPendingTransaction(hash)
+		return err
+	})
+	return result, err
+}
+
+// pick chooses the node for a request about address: its owner on the hash
+// ring with source affinity, otherwise a weighted pick
+func (c *PooledCurrencyL1Client) pick(address string) (string, error) {
+	if c.affinity && address != "" {
+		return c.pool.PickFor(address)
+	}
+	return c.pool.Pick()
+}
+
+// pickRead returns the node pinned for key in pins under ConsistencyPinned,
+// and otherwise picks a node for address
+func (c *PooledCurrencyL1Client) pickRead(address string, pins map[string]nodePin, key string) (string, error) {
+	c.mu.Lock()
+	if c.consistency.Mode == constellation.ConsistencyPinned {
+		if pin, ok := pins[key]; ok {
+			if time.Now().Before(pin.until) {
+				c.mu.Unlock()
+				return pin.url, nil
+			}
+			delete(pins, key)
+		}
+	}
+	c.mu.Unlock()
+	return c.pick(address)
+}
+
+// recordWrite remembers an accepted submission for later consistent reads
+func (c *PooledCurrencyL1Client) recordWrite(url string, transaction *constellation.CurrencyTransaction, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	source := transaction.Value.Source
+	switch c.consistency.Mode {
+	case constellation.ConsistencyPinned:
+		now := time.Now()
+		for key, pin := range c.pinnedSources {
+			if now.After(pin.until) {
+				delete(c.pinnedSources, key)
+			}
+		}
+		for key, pin := range c.pinnedTxs {
+			if now.After(pin.until) {
+				delete(c.pinnedTxs, key)
+			}
+		}
+		pin := nodePin{url: url, until: now.Add(c.consistency.PinFor)}
+		c.pinnedSources[source] = pin
+		c.pinnedTxs[hash] = pin
+	case constellation.ConsistencyWait:
+		ref := constellation.TransactionReference{Hash: hash, Ordinal: transaction.Value.Parent.Ordinal + 1}
+		if current, ok := c.expected[source]; !ok || current.Ordinal < ref.Ordinal {
+			c.expected[source] = ref
+		}
+	}
+}
+
+// callNode runs call against the node at url and reports the outcome
+func (c *PooledCurrencyL1Client) callNode(url string, call func(client *CurrencyL1Client) error) error {
+	client, err := c.clientFor(url)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	err = call(client)
+	c.pool.Report(url, time.Since(started), nodeFailure(err))
+	return err
+}
+
+// RecentErrors returns the latest errors across the pool's nodes, oldest
+// first, for CaptureDiagnostics
+func (c *PooledCurrencyL1Client) RecentErrors() []constellation.RecordedError {
+	c.mu.Lock()
+	logs := make([][]constellation.RecordedError, 0, len(c.clients))
+	for _, client := range c.clients {
+		logs = append(logs, client.RecentErrors())
+	}
+	c.mu.Unlock()
+	return constellation.MergeRecordedErrors(logs...)
+}
+
+func (c *PooledCurrencyL1Client) clientFor(url string) (*CurrencyL1Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[url]; ok {
+		return client, nil
+	}
+	config := c.config
+	config.L1URL = url
+	client, err := NewCurrencyL1Client(config)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[url] = client
+	return client, nil
+}
+
+// nodeFailure returns err if it indicates an unhealthy node rather than a
+// rejected request
+func nodeFailure(err error) error {
+	if err == nil || errors.Is(err, constellation.ErrRequestTimeout) {
+		return err
+	}
+	var netErr *constellation.NetworkError
+	if errors.As(err, &netErr) {
+		if netErr.StatusCode == 0 || netErr.StatusCode == http.StatusTooManyRequests || netErr.StatusCode >= 500 {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package network
+
+import (
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const (
+	defaultConsistencyPinFor       = time.Minute
+	defaultConsistencyWaitTimeout  = 30 * time.Second
+	defaultConsistencyPollInterval = 500 * time.Millisecond
+)
+
+func consistencyDefaults(c constellation.ReadConsistencyConfig) constellation.ReadConsistencyConfig {
+	if c.Mode == "" {
+		c.Mode = constellation.ConsistencyEventual
+	}
+	if c.PinFor <= 0 {
+		c.PinFor = defaultConsistencyPinFor
+	}
+	if c.WaitTimeout <= 0 {
+		c.WaitTimeout = defaultConsistencyWaitTimeout
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultConsistencyPollInterval
+	}
+	return c
+}
+
+// waitForLastReference is constellation.WaitForLastReference over a lookup
+// function, so a client can wait on its own uncached lookups
+func waitForLastReference(get func(address string) (*constellation.TransactionReference, error), address string,
+	expected constellation.TransactionReference, timeout time.Duration, interval time.Duration) (*constellation.TransactionReference, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		ref, err := get(address)
+		if err != nil {
+			return nil, err
+		}
+		if ref.Ordinal >= expected.Ordinal {
+			return ref, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return ref, constellation.ErrReadNotConsistent
+		}
+		time.Sleep(interval)
+	}
+}
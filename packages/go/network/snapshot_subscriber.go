@@ -0,0 +1,396 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const (
+	defaultSnapshotPollInterval   = 5 * time.Second
+	defaultStreamRetryInterval    = time.Minute
+	defaultLongPollTimeout        = time.Minute
+	snapshotStreamEvent           = "snapshot"
+	eventStreamContentType        = "text/event-stream"
+	longPollAfterParameter        = "after"
+	maxSnapshotStreamEventPayload = 1 << 20
+)
+
+// ErrSnapshotSourceRequired indicates a SnapshotSubscriber was configured without a polling source
+var ErrSnapshotSourceRequired = errors.New("snapshot subscriber requires a polling source")
+
+// errStreamUnavailable means the endpoint does not stream; the subscriber polls instead
+var errStreamUnavailable = errors.New("snapshot stream unavailable")
+
+// SnapshotStreamMode selects how a SnapshotSubscriber consumes its StreamURL
+type SnapshotStreamMode string
+
+const (
+	// StreamSSE reads server-sent events, one snapshot JSON object per event
+	StreamSSE SnapshotStreamMode = "sse"
+	// StreamLongPoll repeatedly requests StreamURL?after={ordinal}; the server
+	// holds the request until a later snapshot exists (200 with the snapshot
+	// JSON) or its own timeout passes (204)
+	StreamLongPoll SnapshotStreamMode = "long-poll"
+)
+
+// SnapshotSubscriberConfig holds configuration for a SnapshotSubscriber
+type SnapshotSubscriberConfig struct {
+	// StreamURL is the node or explorer streaming endpoint. Leave empty to
+	// always poll.
+	StreamURL string
+	// StreamMode selects server-sent events or long-polling (default: StreamSSE)
+	StreamMode SnapshotStreamMode
+	// Poll is queried when streaming is unavailable, usually an ExplorerClient
+	Poll constellation.LatestSnapshotSource
+	// PollInterval is the delay between polls (default: 5s)
+	PollInterval time.Duration
+	// StreamRetryInterval is how long to poll before trying the stream again
+	// (default: 1m)
+	StreamRetryInterval time.Duration
+	// LongPollTimeout bounds each long-poll request (default: 1m)
+	LongPollTimeout time.Duration
+	// FromOrdinal suppresses snapshots at or below this ordinal, e.g. the
+	// last checkpoint
+	FromOrdinal int64
+	// Network supplies UserAgent, RequestID and Transport for streaming
+	// requests
+	Network constellation.NetworkConfig
+	// OnError receives stream and poll failures; the subscriber keeps running
+	OnError func(err error)
+}
+
+// SnapshotSubscriber publishes a SnapshotAdvanced event whenever a newer
+// global snapshot appears. It reads a server-sent events or long-poll
+// endpoint where the node or explorer offers one. When the endpoint is
+// missing, does not stream, or drops the connection, it polls instead and
+// tries the stream again after StreamRetryInterval.
+//
+// Events are published on the embedded EventBus, so watchers and followers
+// subscribe to it like any other EventSource. Ordinals only move forward; a
+// snapshot seen by both the stream and a poll is published once. Polling
+// reports the latest snapshot only, so ordinals may be skipped.
+//
+// SnapshotSubscriber implements the Service lifecycle.
+//
+// Example:
+//
+//	explorer, _ := NewExplorerClient(constellation.NetworkConfig{ExplorerURL: "https://be-mainnet.constellationnetwork.io"})
+//	subscriber, err := NewSnapshotSubscriber(ctx, SnapshotSubscriberConfig{
+//	    StreamURL: "https://explorer.example.com/global-snapshots/stream",
+//	    Poll:      explorer,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer subscriber.Close()
+//
+//	subscriber.Subscribe(func(e constellation.Event) {
+//	    log.Println("snapshot", e.(constellation.SnapshotAdvanced).Ordinal)
+//	})
+type SnapshotSubscriber struct {
+	serviceState
+	*constellation.EventBus
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	config SnapshotSubscriberConfig
+	client *HTTPClient
+
+	mu        sync.Mutex
+	closing   bool
+	streaming bool
+	last      int64
+}
+
+// NewSnapshotSubscriber creates a subscriber and starts following snapshots
+func NewSnapshotSubscriber(ctx context.Context, config SnapshotSubscriberConfig) (*SnapshotSubscriber, error) {
+	if config.Poll == nil {
+		return nil, ErrSnapshotSourceRequired
+	}
+	if config.StreamMode == "" {
+		config.StreamMode = StreamSSE
+	}
+	if config.StreamMode != StreamSSE && config.StreamMode != StreamLongPoll {
+		return nil, fmt.Errorf("unknown snapshot stream mode %q", config.StreamMode)
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultSnapshotPollInterval
+	}
+	if config.StreamRetryInterval <= 0 {
+		config.StreamRetryInterval = defaultStreamRetryInterval
+	}
+	if config.LongPollTimeout <= 0 {
+		config.LongPollTimeout = defaultLongPollTimeout
+	}
+
+	// streaming responses never finish, so the client has no overall timeout
+	client := newNetworkHTTPClient("", config.Network)
+	client.client.Timeout = 0
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s := &SnapshotSubscriber{
+		EventBus: constellation.NewEventBus(),
+		ctx:      runCtx,
+		cancel:   cancel,
+		config:   config,
+		client:   client,
+		last:     config.FromOrdinal,
+	}
+	s.start()
+
+	go s.run(ctx)
+	return s, nil
+}
+
+// Streaming reports whether snapshots currently arrive over the stream
+// rather than by polling
+func (s *SnapshotSubscriber) Streaming() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streaming
+}
+
+// LastOrdinal returns the ordinal of the last published snapshot
+func (s *SnapshotSubscriber) LastOrdinal() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Close stops following and blocks until the subscriber has stopped
+func (s *SnapshotSubscriber) Close() error {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+
+	s.cancel()
+	<-s.Done()
+	return s.Err()
+}
+
+func (s *SnapshotSubscriber) run(parent context.Context) {
+	var nextStream time.Time
+	for {
+		if s.config.StreamURL != "" && !time.Now().Before(nextStream) {
+			s.setStreaming(true)
+			err := s.stream()
+			s.setStreaming(false)
+			if err != nil && s.ctx.Err() == nil {
+				s.reportError(err)
+			}
+			nextStream = time.Now().Add(s.config.StreamRetryInterval)
+		}
+		if s.ctx.Err() != nil {
+			break
+		}
+
+		s.poll()
+
+		timer := time.NewTimer(s.config.PollInterval)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if s.ctx.Err() != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	closing := s.closing
+	s.mu.Unlock()
+	if closing {
+		s.finish(nil)
+		return
+	}
+	s.finish(parent.Err())
+}
+
+func (s *SnapshotSubscriber) setStreaming(streaming bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streaming = streaming
+}
+
+func (s *SnapshotSubscriber) poll() {
+	snapshot, err := s.config.Poll.GetLatestSnapshot()
+	if err != nil {
+		s.reportError(err)
+		return
+	}
+	if snapshot != nil {
+		s.observe(snapshot)
+	}
+}
+
+// observe publishes snapshot if it is newer than anything seen so far
+func (s *SnapshotSubscriber) observe(snapshot *constellation.ExplorerSnapshot) {
+	s.mu.Lock()
+	if snapshot.Ordinal <= s.last {
+		s.mu.Unlock()
+		return
+	}
+	s.last = snapshot.Ordinal
+	s.mu.Unlock()
+
+	s.Publish(constellation.SnapshotAdvanced{Ordinal: snapshot.Ordinal, Hash: snapshot.Hash, At: time.Now()})
+}
+
+func (s *SnapshotSubscriber) reportError(err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(err)
+	}
+}
+
+// stream consumes the streaming endpoint until it fails or the subscriber stops
+func (s *SnapshotSubscriber) stream() error {
+	if s.config.StreamMode == StreamLongPoll {
+		return s.longPoll()
+	}
+	return s.readEvents()
+}
+
+// readEvents consumes a server-sent events stream
+func (s *SnapshotSubscriber) readEvents() error {
+	resp, err := s.get(s.ctx, s.config.StreamURL, eventStreamContentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), eventStreamContentType) {
+		return fmt.Errorf("%w: GET %s returned %d %s", errStreamUnavailable, s.config.StreamURL,
+			resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var event string
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("snapshot stream closed: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data.Len() > 0 && (event == "" || event == snapshotStreamEvent) {
+				s.observeJSON([]byte(data.String()))
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// comment, used as a keep-alive
+		default:
+			field, value := line, ""
+			if i := strings.IndexByte(line, ':'); i >= 0 {
+				field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+			}
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				if data.Len()+len(value) > maxSnapshotStreamEventPayload {
+					return fmt.Errorf("snapshot stream event exceeds %d bytes", maxSnapshotStreamEventPayload)
+				}
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+			}
+		}
+	}
+}
+
+// longPoll repeatedly asks for the snapshot after the last one seen
+func (s *SnapshotSubscriber) longPoll() error {
+	streamURL, err := url.Parse(s.config.StreamURL)
+	if err != nil {
+		return err
+	}
+
+	for s.ctx.Err() == nil {
+		query := streamURL.Query()
+		query.Set(longPollAfterParameter, strconv.FormatInt(s.LastOrdinal(), 10))
+		streamURL.RawQuery = query.Encode()
+
+		if err := s.longPollOnce(streamURL.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SnapshotSubscriber) longPollOnce(requestURL string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.LongPollTimeout)
+	defer cancel()
+
+	resp, err := s.get(ctx, requestURL, "application/json")
+	if err != nil {
+		if s.ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxSnapshotStreamEventPayload))
+		if err != nil {
+			return err
+		}
+		s.observeJSON(body)
+		return nil
+	default:
+		return fmt.Errorf("%w: GET %s returned %d", errStreamUnavailable, requestURL, resp.StatusCode)
+	}
+}
+
+func (s *SnapshotSubscriber) get(ctx context.Context, requestURL string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	s.client.identify(req)
+	return s.client.client.Do(req)
+}
+
+// observeJSON publishes a snapshot received as JSON, either bare or in the
+// explorer's {"data": ...} envelope
+func (s *SnapshotSubscriber) observeJSON(data []byte) {
+	var envelope struct {
+		Data *constellation.ExplorerSnapshot `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Data != nil {
+		s.observe(envelope.Data)
+		return
+	}
+
+	var snapshot constellation.ExplorerSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		s.reportError(fmt.Errorf("invalid snapshot event: %w", err))
+		return
+	}
+	s.observe(&snapshot)
+}
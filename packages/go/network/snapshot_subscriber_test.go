@@ -0,0 +1,288 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExplorer is an in-memory constellation.ExplorerAPI that counts upstream calls
+type fakeExplorer struct {
+	transactions map[string][]constellation.ExplorerTransaction
+	snapshots    map[int64]*constellation.ExplorerSnapshot
+	blocks       map[int64][]constellation.ExplorerBlock
+	latest       int64
+	calls        int
+	err          error
+}
+
+func (f *fakeExplorer) GetTransactionsByAddress(address string, limit int) ([]constellation.ExplorerTransaction, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	txs := f.transactions[address]
+	if len(txs) > limit {
+		txs = txs[:limit]
+	}
+	return append([]constellation.ExplorerTransaction{}, txs...), nil
+}
+
+func (f *fakeExplorer) GetSnapshot(ordinal int64) (*constellation.ExplorerSnapshot, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.snapshots[ordinal], nil
+}
+
+func (f *fakeExplorer) GetTransaction(hash string) (*constellation.ExplorerTransaction, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, txs := range f.transactions {
+		for _, tx := range txs {
+			if tx.Hash == hash {
+				found := tx
+				return &found, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeExplorer) GetSnapshotBlocks(ordinal int64) ([]constellation.ExplorerBlock, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.blocks[ordinal], nil
+}
+
+func (f *fakeExplorer) GetBlock(hash string) (*constellation.ExplorerBlock, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, blocks := range f.blocks {
+		for _, block := range blocks {
+			if block.Hash == hash {
+				found := block
+				return &found, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeExplorer) GetBlockTransactions(hash string) ([]constellation.ExplorerTransaction, error) {
+	block, err := f.GetBlock(hash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	txs := []constellation.ExplorerTransaction{}
+	for _, txHash := range block.Transactions {
+		for _, all := range f.transactions {
+			for _, tx := range all {
+				if tx.Hash == txHash {
+					txs = append(txs, tx)
+				}
+			}
+		}
+	}
+	return txs, nil
+}
+
+func (f *fakeExplorer) GetLatestSnapshot() (*constellation.ExplorerSnapshot, error) {
+	return f.GetSnapshot(f.latest)
+}
+
+func newFakeExplorer() *fakeExplorer {
+	return &fakeExplorer{
+		transactions: map[string][]constellation.ExplorerTransaction{
+			"DAG0addr": {
+				{Hash: "tx2", Amount: 200, SnapshotOrdinal: 12},
+				{Hash: "tx1", Amount: 100, SnapshotOrdinal: 10},
+			},
+		},
+		snapshots: map[int64]*constellation.ExplorerSnapshot{
+			10: {Hash: "s10", Ordinal: 10},
+			12: {Hash: "s12", Ordinal: 12},
+		},
+		blocks: map[int64][]constellation.ExplorerBlock{
+			10: {{Hash: "b1", Height: 3, Transactions: []string{"tx1"}, SnapshotOrdinal: 10}},
+			12: {{Hash: "b2", Height: 4, Parents: []constellation.BlockReference{{Height: 3, Hash: "b1"}},
+				Transactions: []string{"tx2"}, SnapshotOrdinal: 12}},
+		},
+		latest: 12,
+	}
+}
+
+// collectOrdinals records the ordinals of SnapshotAdvanced events
+type collectOrdinals struct {
+	mu       sync.Mutex
+	ordinals []int64
+}
+
+func (c *collectOrdinals) handle(e constellation.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ordinals = append(c.ordinals, e.(constellation.SnapshotAdvanced).Ordinal)
+}
+
+func (c *collectOrdinals) get() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int64{}, c.ordinals...)
+}
+
+// lockedExplorer guards fakeExplorer for use from the subscriber goroutine
+type lockedExplorer struct {
+	mu sync.Mutex
+	*fakeExplorer
+}
+
+func (l *lockedExplorer) GetLatestSnapshot() (*constellation.ExplorerSnapshot, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fakeExplorer.GetLatestSnapshot()
+}
+
+func (l *lockedExplorer) setLatest(ordinal int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.snapshots[ordinal] = &constellation.ExplorerSnapshot{Ordinal: ordinal}
+	l.latest = ordinal
+}
+
+func TestSnapshotSubscriber(t *testing.T) {
+	t.Run("requires a polling source", func(t *testing.T) {
+		_, err := NewSnapshotSubscriber(context.Background(), SnapshotSubscriberConfig{})
+		assert.ErrorIs(t, err, ErrSnapshotSourceRequired)
+	})
+
+	t.Run("reads server-sent events", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": keep-alive\n\n")
+			fmt.Fprint(w, "event: snapshot\ndata: {\"ordinal\":13,\"hash\":\"s13\"}\n\n")
+			fmt.Fprint(w, "event: other\ndata: {\"ordinal\":99}\n\n")
+			fmt.Fprint(w, "data: {\"data\":{\"ordinal\":14,\"hash\":\"s14\"}}\n\n")
+			fmt.Fprint(w, "data: {\"ordinal\":14}\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		var got collectOrdinals
+		subscriber, err := NewSnapshotSubscriber(context.Background(), SnapshotSubscriberConfig{
+			StreamURL:    server.URL,
+			Poll:         &lockedExplorer{fakeExplorer: newFakeExplorer()},
+			PollInterval: 10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		subscriber.Subscribe(got.handle)
+
+		require.Eventually(t, func() bool { return len(got.get()) == 2 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []int64{13, 14}, got.get())
+		assert.True(t, subscriber.Streaming())
+
+		require.NoError(t, subscriber.Close())
+		assert.False(t, subscriber.Streaming())
+	})
+
+	t.Run("long-polls after the last ordinal", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Query().Get("after") {
+			case "12":
+				fmt.Fprint(w, `{"ordinal":13,"hash":"s13"}`)
+			case "13":
+				fmt.Fprint(w, `{"ordinal":14,"hash":"s14"}`)
+			default:
+				time.Sleep(5 * time.Millisecond)
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		defer server.Close()
+
+		var got collectOrdinals
+		subscriber, err := NewSnapshotSubscriber(context.Background(), SnapshotSubscriberConfig{
+			StreamURL:   server.URL + "/snapshots/stream",
+			StreamMode:  StreamLongPoll,
+			Poll:        &lockedExplorer{fakeExplorer: newFakeExplorer()},
+			FromOrdinal: 12,
+		})
+		require.NoError(t, err)
+		subscriber.Subscribe(got.handle)
+
+		require.Eventually(t, func() bool { return subscriber.LastOrdinal() == 14 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []int64{13, 14}, got.get())
+		require.NoError(t, subscriber.Close())
+	})
+
+	t.Run("falls back to polling when streaming is unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		explorer := &lockedExplorer{fakeExplorer: newFakeExplorer()}
+		var errs []error
+		var errsMu sync.Mutex
+		var got collectOrdinals
+		subscriber, err := NewSnapshotSubscriber(context.Background(), SnapshotSubscriberConfig{
+			StreamURL:    server.URL,
+			Poll:         explorer,
+			PollInterval: 5 * time.Millisecond,
+			OnError: func(err error) {
+				errsMu.Lock()
+				defer errsMu.Unlock()
+				errs = append(errs, err)
+			},
+		})
+		require.NoError(t, err)
+		subscriber.Subscribe(got.handle)
+
+		require.Eventually(t, func() bool { return subscriber.LastOrdinal() == 12 }, time.Second, 5*time.Millisecond)
+		explorer.setLatest(15)
+		require.Eventually(t, func() bool { return subscriber.LastOrdinal() == 15 }, time.Second, 5*time.Millisecond)
+
+		assert.Equal(t, []int64{12, 15}, got.get())
+		assert.False(t, subscriber.Streaming())
+		errsMu.Lock()
+		require.NotEmpty(t, errs)
+		assert.ErrorIs(t, errs[0], errStreamUnavailable)
+		errsMu.Unlock()
+		require.NoError(t, subscriber.Close())
+	})
+
+	t.Run("stops with the context error when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		subscriber, err := NewSnapshotSubscriber(ctx, SnapshotSubscriberConfig{
+			Poll:         &lockedExplorer{fakeExplorer: newFakeExplorer()},
+			PollInterval: time.Hour,
+		})
+		require.NoError(t, err)
+
+		cancel()
+		select {
+		case <-subscriber.Done():
+		case <-time.After(time.Second):
+			t.Fatal("subscriber did not stop")
+		}
+		assert.ErrorIs(t, subscriber.Err(), context.Canceled)
+	})
+}
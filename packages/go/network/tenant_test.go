@@ -0,0 +1,83 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantNetworkConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cluster/info" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"hash":"` + constellation.GenesisReference.Hash + `","ordinal":0}`))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var metrics []constellation.RequestMetric
+	manager := constellation.NewTenantManager(constellation.TenantManagerConfig{
+		Network: constellation.NetworkConfig{L1URL: server.URL},
+		OnRequest: func(metric constellation.RequestMetric) {
+			mu.Lock()
+			defer mu.Unlock()
+			metrics = append(metrics, metric)
+		},
+	})
+
+	keyPair, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+	acme, err := manager.AddTenant(constellation.TenantConfig{
+		ID:        "acme",
+		RateLimit: 20,
+		Labels:    map[string]string{"plan": "pro"},
+	})
+	require.NoError(t, err)
+	globex, err := manager.AddTenant(constellation.TenantConfig{ID: "globex"})
+	require.NoError(t, err)
+
+	t.Run("rate limits and labels requests per tenant", func(t *testing.T) {
+		acmeL1, err := NewCurrencyL1Client(acme.NetworkConfig())
+		require.NoError(t, err)
+		globexL1, err := NewCurrencyL1Client(globex.NetworkConfig())
+		require.NoError(t, err)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := acmeL1.GetLastReference(keyPair.Address)
+			require.NoError(t, err)
+		}
+		// the burst of 1 spaces the other two requests 50ms apart
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+		start = time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := globexL1.GetLastReference(keyPair.Address)
+			require.NoError(t, err)
+		}
+		assert.Less(t, time.Since(start), 90*time.Millisecond)
+		assert.False(t, globexL1.CheckHealth())
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, metrics, 7)
+		assert.Equal(t, "acme", metrics[0].Tenant)
+		assert.Equal(t, map[string]string{"plan": "pro"}, metrics[0].Labels)
+		assert.Zero(t, metrics[0].Waited)
+		assert.Positive(t, metrics[2].Waited)
+		assert.Equal(t, "GET "+server.URL+"/transactions/last-reference/"+keyPair.Address, metrics[0].Endpoint)
+		assert.Equal(t, "globex", metrics[6].Tenant)
+		assert.Empty(t, metrics[6].Labels)
+		var netErr *constellation.NetworkError
+		require.ErrorAs(t, metrics[6].Err, &netErr)
+		assert.Equal(t, http.StatusServiceUnavailable, netErr.StatusCode)
+	})
+}
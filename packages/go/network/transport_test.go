@@ -0,0 +1,79 @@
+package network
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConnCountingServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		_, _ = w.Write([]byte(`{"hash":"h","ordinal":1}`))
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, &conns
+}
+
+func hammer(t *testing.T, client *CurrencyL1Client, workers, requests int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < requests; i++ {
+				_, err := client.GetLastReference("DAG1")
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTransportConnectionReuse(t *testing.T) {
+	t.Run("default transport keeps a connection per concurrent caller", func(t *testing.T) {
+		server, conns := newConnCountingServer(t)
+		client, err := NewCurrencyL1Client(constellation.NetworkConfig{L1URL: server.URL})
+		require.NoError(t, err)
+
+		hammer(t, client, 16, 20)
+		assert.LessOrEqual(t, atomic.LoadInt32(conns), int32(16))
+	})
+
+	t.Run("keep-alives can be disabled", func(t *testing.T) {
+		server, conns := newConnCountingServer(t)
+		client, err := NewCurrencyL1Client(constellation.NetworkConfig{
+			L1URL:     server.URL,
+			Transport: &constellation.TransportConfig{DisableKeepAlives: true},
+		})
+		require.NoError(t, err)
+
+		hammer(t, client, 2, 5)
+		assert.Equal(t, int32(10), atomic.LoadInt32(conns))
+	})
+
+	t.Run("zero fields fall back to defaults", func(t *testing.T) {
+		transport := newTransport(constellation.TransportConfig{MaxConnsPerHost: 4})
+		defaults := constellation.DefaultTransportConfig()
+		assert.Equal(t, defaults.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 4, transport.MaxConnsPerHost)
+		assert.True(t, transport.ForceAttemptHTTP2)
+	})
+}
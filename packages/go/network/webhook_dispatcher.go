@@ -0,0 +1,338 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+// Webhook request headers
+const (
+	// WebhookHMACHeader carries "sha256=<hex>" of the body keyed with the endpoint secret
+	WebhookHMACHeader = "X-Webhook-Signature"
+	// WebhookSignerHeader carries the public key ID of the DAG signing key
+	WebhookSignerHeader = "X-Constellation-Signer"
+	// WebhookSignatureHeader carries the DER signature hex over the body hash
+	WebhookSignatureHeader = "X-Constellation-Signature"
+)
+
+const (
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookBackoff     = time.Second
+	defaultWebhookQueueSize   = 1024
+)
+
+var (
+	// ErrNoWebhookEndpoints indicates the dispatcher was configured without endpoints
+	ErrNoWebhookEndpoints = errors.New("at least one webhook endpoint is required")
+	// ErrDispatcherClosed indicates an event was handed to a closed dispatcher
+	ErrDispatcherClosed = errors.New("webhook dispatcher is closed")
+	// ErrWebhookQueueFull indicates the delivery queue overflowed
+	ErrWebhookQueueFull = errors.New("webhook queue is full")
+)
+
+// WebhookEndpoint is a single webhook receiver
+type WebhookEndpoint struct {
+	// URL receives a POST with the EventEnvelope JSON body
+	URL string
+	// Secret, when set, signs the body with HMAC-SHA256 in WebhookHMACHeader
+	Secret string
+}
+
+// WebhookDelivery describes one event bound for one endpoint
+type WebhookDelivery struct {
+	Endpoint WebhookEndpoint
+	Event    constellation.Event
+	Body     []byte
+	Attempts int
+}
+
+// WebhookConfig holds configuration for a WebhookDispatcher
+type WebhookConfig struct {
+	// Endpoints receive every event
+	Endpoints []WebhookEndpoint
+	// SigningKey, when set, signs each body with this DAG private key
+	SigningKey string
+	// MaxAttempts is the number of delivery attempts per endpoint (default: 5)
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on each retry (default: 1s)
+	Backoff time.Duration
+	// QueueSize bounds the number of undelivered events per endpoint
+	// (default: 1024)
+	QueueSize int
+	// Timeout is the request timeout in seconds (default: 30)
+	Timeout int
+	// UserAgent identifies the sender to receivers (default: DefaultUserAgent)
+	UserAgent string
+	// RequestID generates the X-Request-ID header for each delivery attempt
+	// (default: random)
+	RequestID func() string
+	// DeadLetter receives deliveries that exhausted their attempts or
+	// overflowed the queue. It is called from every endpoint's worker, so it
+	// must be safe for concurrent use.
+	DeadLetter func(delivery WebhookDelivery, err error)
+}
+
+// WebhookDispatcher delivers events to HTTP webhooks with signing, retries
+// and dead-letter handling. Each endpoint has its own queue and worker, so
+// an endpoint that is down or slow delays only its own deliveries. It
+// implements the Service lifecycle: cancelling the context dead-letters
+// whatever is still queued, while Close drains it.
+//
+// Example:
+//
+//	dispatcher, err := NewWebhookDispatcher(ctx, WebhookConfig{
+//	    Endpoints:  []WebhookEndpoint{{URL: "https://example.com/hooks", Secret: secret}},
+//	    DeadLetter: func(d WebhookDelivery, err error) { log.Printf("dropped %s: %v", d.Event.Type(), err) },
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer dispatcher.Close()
+//
+//	unsubscribe := bus.Subscribe(dispatcher.Handle)
+type WebhookDispatcher struct {
+	serviceState
+
+	ctx    context.Context
+	config WebhookConfig
+	client *HTTPClient
+	signer string
+
+	mu     sync.RWMutex
+	closed bool
+	// queues holds one queue per endpoint, in Endpoints order
+	queues []chan WebhookDelivery
+}
+
+// NewWebhookDispatcher creates a dispatcher and starts a delivery worker per
+// endpoint
+func NewWebhookDispatcher(ctx context.Context, config WebhookConfig) (*WebhookDispatcher, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, ErrNoWebhookEndpoints
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaultWebhookBackoff
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultWebhookQueueSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	d := &WebhookDispatcher{
+		ctx:    ctx,
+		config: config,
+		client: newNetworkHTTPClient("", constellation.NetworkConfig{
+			Timeout:   config.Timeout,
+			UserAgent: config.UserAgent,
+			RequestID: config.RequestID,
+		}),
+	}
+	for range config.Endpoints {
+		d.queues = append(d.queues, make(chan WebhookDelivery, config.QueueSize))
+	}
+	d.start()
+
+	if config.SigningKey != "" {
+		id, err := constellation.GetPublicKeyID(config.SigningKey)
+		if err != nil {
+			return nil, err
+		}
+		d.signer = id
+	}
+
+	go d.run()
+	return d, nil
+}
+
+// Handle queues an event for delivery to every endpoint. It is an
+// EventHandler, so it can be passed straight to EventSource.Subscribe.
+func (d *WebhookDispatcher) Handle(event constellation.Event) {
+	body, err := constellation.MarshalEvent(event)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for i, endpoint := range d.config.Endpoints {
+		delivery := WebhookDelivery{Endpoint: endpoint, Event: event, Body: body}
+		switch {
+		case err != nil:
+			d.deadLetter(delivery, err)
+		case d.closed:
+			d.deadLetter(delivery, ErrDispatcherClosed)
+		default:
+			select {
+			case d.queues[i] <- delivery:
+			default:
+				d.deadLetter(delivery, ErrWebhookQueueFull)
+			}
+		}
+	}
+}
+
+// Close stops accepting events and blocks until queued deliveries finish
+func (d *WebhookDispatcher) Close() error {
+	d.stopIntake()
+	<-d.Done()
+	return d.Err()
+}
+
+func (d *WebhookDispatcher) stopIntake() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) run() {
+	var wg sync.WaitGroup
+	errs := make([]error, len(d.queues))
+	for i := range d.queues {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = d.work(d.queues[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			d.finish(err)
+			return
+		}
+	}
+	d.finish(nil)
+}
+
+// work delivers one endpoint's queue in order until it is closed and
+// drained, or the context ends
+func (d *WebhookDispatcher) work(queue chan WebhookDelivery) error {
+	for {
+		select {
+		case <-d.ctx.Done():
+			d.abandon(queue)
+			return d.ctx.Err()
+		case delivery, ok := <-queue:
+			if !ok {
+				return nil
+			}
+			d.deliver(delivery)
+		}
+	}
+}
+
+// abandon stops intake and dead-letters everything still in queue
+func (d *WebhookDispatcher) abandon(queue chan WebhookDelivery) {
+	d.stopIntake()
+	for delivery := range queue {
+		d.deadLetter(delivery, d.ctx.Err())
+	}
+}
+
+func (d *WebhookDispatcher) deliver(delivery WebhookDelivery) {
+	backoff := d.config.Backoff
+	var lastErr error
+
+	for delivery.Attempts < d.config.MaxAttempts {
+		if delivery.Attempts > 0 {
+			select {
+			case <-d.ctx.Done():
+				d.deadLetter(delivery, d.ctx.Err())
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		delivery.Attempts++
+
+		lastErr = d.post(delivery)
+		if lastErr == nil {
+			return
+		}
+		var netErr *constellation.NetworkError
+		if errors.As(lastErr, &netErr) && netErr.StatusCode >= 400 && netErr.StatusCode < 500 && netErr.StatusCode != 429 {
+			break
+		}
+	}
+
+	d.deadLetter(delivery, lastErr)
+}
+
+func (d *WebhookDispatcher) post(delivery WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return constellation.NewNetworkError(err.Error(), 0, "")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if delivery.Endpoint.Secret != "" {
+		req.Header.Set(WebhookHMACHeader, webhookHMAC(delivery.Body, delivery.Endpoint.Secret))
+	}
+	if d.signer != "" {
+		signature, err := constellation.SignNotification(delivery.Body, d.config.SigningKey)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(WebhookSignerHeader, d.signer)
+		req.Header.Set(WebhookSignatureHeader, signature)
+	}
+
+	d.client.identify(req)
+	resp, err := d.client.client.Do(req)
+	if err != nil {
+		return constellation.NewNetworkError(err.Error(), 0, "")
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return constellation.NewNetworkError(
+			fmt.Sprintf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			resp.StatusCode,
+			"",
+		)
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) deadLetter(delivery WebhookDelivery, err error) {
+	if d.config.DeadLetter != nil {
+		d.config.DeadLetter(delivery, err)
+	}
+}
+
+func webhookHMAC(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookHMAC checks a WebhookHMACHeader value against the body and secret
+func VerifyWebhookHMAC(body []byte, secret string, header string) bool {
+	expected := webhookHMAC(body, secret)
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(header)))
+}
+
+// VerifyWebhookSignature checks the DAG-key signature headers against the body
+func VerifyWebhookSignature(body []byte, signerID string, signatureHex string) (bool, error) {
+	return constellation.VerifyHash(constellation.HashBytes(body).Value, signatureHex, signerID)
+}
@@ -0,0 +1,169 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	t.Run("requires endpoints", func(t *testing.T) {
+		_, err := NewWebhookDispatcher(context.Background(), WebhookConfig{})
+		assert.ErrorIs(t, err, ErrNoWebhookEndpoints)
+	})
+
+	t.Run("retries and signs deliveries", func(t *testing.T) {
+		keyPair, err := constellation.GenerateKeyPair()
+		require.NoError(t, err)
+
+		var attempts int32
+		var mu sync.Mutex
+		var body []byte
+		var headers http.Header
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			mu.Lock()
+			body, _ = io.ReadAll(r.Body)
+			headers = r.Header.Clone()
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		dispatcher, err := NewWebhookDispatcher(context.Background(), WebhookConfig{
+			Endpoints:  []WebhookEndpoint{{URL: server.URL, Secret: "s3cret"}},
+			SigningKey: keyPair.PrivateKey,
+			Backoff:    time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		dispatcher.Handle(constellation.TxConfirmed{Hash: "abc", Ordinal: 3})
+		require.NoError(t, dispatcher.Close())
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+		assert.Contains(t, string(body), `"type":"TxConfirmed"`)
+		assert.True(t, VerifyWebhookHMAC(body, "s3cret", headers.Get(WebhookHMACHeader)))
+		assert.False(t, VerifyWebhookHMAC(body, "wrong", headers.Get(WebhookHMACHeader)))
+
+		assert.Equal(t, keyPair.PublicKey[2:], headers.Get(WebhookSignerHeader))
+		valid, err := VerifyWebhookSignature(body, headers.Get(WebhookSignerHeader), headers.Get(WebhookSignatureHeader))
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("dead letters permanent failures", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		var dead []WebhookDelivery
+		dispatcher, err := NewWebhookDispatcher(context.Background(), WebhookConfig{
+			Endpoints:  []WebhookEndpoint{{URL: server.URL}},
+			Backoff:    time.Millisecond,
+			DeadLetter: func(d WebhookDelivery, err error) { dead = append(dead, d) },
+		})
+		require.NoError(t, err)
+
+		dispatcher.Handle(constellation.TxDropped{Hash: "abc"})
+		require.NoError(t, dispatcher.Close())
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+		require.Len(t, dead, 1)
+		assert.Equal(t, constellation.EventTxDropped, dead[0].Event.Type())
+	})
+
+	t.Run("dead letters events after close", func(t *testing.T) {
+		var dead []error
+		dispatcher, err := NewWebhookDispatcher(context.Background(), WebhookConfig{
+			Endpoints:  []WebhookEndpoint{{URL: "http://127.0.0.1:0"}},
+			DeadLetter: func(d WebhookDelivery, err error) { dead = append(dead, err) },
+		})
+		require.NoError(t, err)
+		require.NoError(t, dispatcher.Close())
+
+		dispatcher.Handle(constellation.SnapshotAdvanced{Ordinal: 1})
+		assert.Equal(t, []error{ErrDispatcherClosed}, dead)
+	})
+
+	t.Run("a stalled endpoint does not hold up the others", func(t *testing.T) {
+		release := make(chan struct{})
+		stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer stalled.Close()
+		defer close(release)
+
+		var delivered int32
+		healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&delivered, 1)
+		}))
+		defer healthy.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		dispatcher, err := NewWebhookDispatcher(ctx, WebhookConfig{
+			Endpoints: []WebhookEndpoint{{URL: stalled.URL}, {URL: healthy.URL}},
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			dispatcher.Handle(constellation.SnapshotAdvanced{Ordinal: int64(i)})
+		}
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&delivered) == 3 }, 5*time.Second, time.Millisecond)
+	})
+
+	t.Run("context cancellation abandons queued work", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		var mu sync.Mutex
+		var dead []error
+		ctx, cancel := context.WithCancel(context.Background())
+		dispatcher, err := NewWebhookDispatcher(ctx, WebhookConfig{
+			Endpoints: []WebhookEndpoint{{URL: server.URL}},
+			DeadLetter: func(d WebhookDelivery, err error) {
+				mu.Lock()
+				dead = append(dead, err)
+				mu.Unlock()
+			},
+		})
+		require.NoError(t, err)
+
+		dispatcher.Handle(constellation.TxConfirmed{Hash: "a"})
+		dispatcher.Handle(constellation.TxConfirmed{Hash: "b"})
+		cancel()
+
+		select {
+		case <-dispatcher.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("dispatcher did not stop after cancellation")
+		}
+		assert.ErrorIs(t, dispatcher.Err(), context.Canceled)
+		assert.ErrorIs(t, dispatcher.Close(), context.Canceled)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, dead, 2)
+	})
+}
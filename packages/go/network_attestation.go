@@ -0,0 +1,154 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAttestationSignatureInvalid indicates a signed artifact with a missing or bad signature
+	ErrAttestationSignatureInvalid = errors.New("attestation signature is invalid")
+	// ErrNotSignedByNode indicates a signed artifact with no signature from the expected node
+	ErrNotSignedByNode = errors.New("not signed by the expected node")
+	// ErrNotSignedByOwner indicates a signed artifact with no signature from the expected address
+	ErrNotSignedByOwner = errors.New("not signed by the expected owner")
+	// ErrOwnershipProofMismatch indicates an ownership proof for a different owner, subject or audience
+	ErrOwnershipProofMismatch = errors.New("ownership proof does not match the expected claim")
+	// ErrOwnershipProofExpired indicates an ownership proof issued too long ago, or in the future
+	ErrOwnershipProofExpired = errors.New("ownership proof is outside its validity window")
+	// ErrOwnershipClaimIncomplete indicates a claim without the audience
+	// that binds a proof to the verifying service
+	ErrOwnershipClaimIncomplete = errors.New("ownership claim has no audience")
+)
+
+// ownershipProofClockSkew is how far in the future an ownership proof may
+// be issued, to allow for clock drift between the signer and the verifier
+const ownershipProofClockSkew = time.Minute
+
+// DefaultOwnershipProofMaxAge is how long ago an ownership proof may have
+// been issued when the claim sets no MaxAge
+const DefaultOwnershipProofMaxAge = 5 * time.Minute
+
+// VerifySignedByNode checks that every signature on signed is valid and
+// that one was made by the node with peerID. A peer ID is the node's public
+// key without the 04 prefix, as reported by /node/info; a 04-prefixed key
+// is accepted too. Use it to authenticate cluster messages and other
+// artifacts a node signs with its own key.
+//
+// Example:
+//
+//	var message Signed[map[string]interface{}]
+//	_ = json.Unmarshal(body, &message)
+//	if err := VerifySignedByNode(&message, info.ID, false); err != nil {
+//	    return err
+//	}
+func VerifySignedByNode[T any](signed *Signed[T], peerID string, isDataUpdate bool) error {
+	if signed == nil || !Verify(signed, isDataUpdate).IsValid {
+		return ErrAttestationSignatureInvalid
+	}
+	peerID = NormalizePublicKeyToID(peerID)
+	for _, proof := range signed.Proofs {
+		if strings.EqualFold(NormalizePublicKeyToID(proof.ID), peerID) {
+			return nil
+		}
+	}
+	return ErrNotSignedByNode
+}
+
+// VerifySignedByAddress checks that every signature on signed is valid and
+// that one was made by the key behind the DAG address, e.g. a metagraph
+// owner or a node operator's wallet
+func VerifySignedByAddress[T any](signed *Signed[T], address string, isDataUpdate bool) error {
+	if signed == nil || !Verify(signed, isDataUpdate).IsValid {
+		return ErrAttestationSignatureInvalid
+	}
+	if !signedByAny(signed.Proofs, []string{address}) {
+		return ErrNotSignedByOwner
+	}
+	return nil
+}
+
+// OwnershipProof claims that the holder of a DAG address controls a
+// subject, such as a metagraph ID or a node's peer ID. Signed by the owner,
+// it lets a third-party service authenticate the owner without handling
+// their key. Audience and Nonce bind the proof to one service and one
+// challenge so it cannot be replayed elsewhere.
+type OwnershipProof struct {
+	Owner    string    `json:"owner"`
+	Subject  string    `json:"subject"`
+	Audience string    `json:"audience"`
+	Nonce    string    `json:"nonce"`
+	IssuedAt time.Time `json:"issuedAt"`
+}
+
+// OwnershipClaim is what a verifier expects an ownership proof to state.
+// Audience is required, and every field must match the proof exactly, so a
+// proof made for one service or challenge is not accepted by another.
+type OwnershipClaim struct {
+	Owner    string
+	Subject  string
+	Audience string
+	// Nonce is the challenge the service issued; an empty Nonce only
+	// matches proofs made without one
+	Nonce string
+	// MaxAge rejects proofs issued longer ago than this (default:
+	// DefaultOwnershipProofMaxAge)
+	MaxAge time.Duration
+}
+
+// CreateOwnershipProof signs a proof that the signer's address controls
+// subject, for the given audience and challenge nonce
+//
+// Example:
+//
+//	signer, _ := NewPrivateKeySigner(ownerKey)
+//	proof, err := CreateOwnershipProof("DAG...metagraphID", "https://indexer.example.com", challenge, signer)
+func CreateOwnershipProof(subject string, audience string, nonce string, signer Signer) (*Signed[OwnershipProof], error) {
+	proof := OwnershipProof{
+		Owner:    GetAddress(signer.PublicKey()),
+		Subject:  subject,
+		Audience: audience,
+		Nonce:    nonce,
+		IssuedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	return CreateSignedObjectWithSigner(proof, signer, false)
+}
+
+// VerifyOwnershipProof checks that the proof is validly signed by its
+// owner, states the expected claim, and is within claim.MaxAge. Returns
+// ErrOwnershipClaimIncomplete for a claim with no Audience.
+func VerifyOwnershipProof(signed *Signed[OwnershipProof], claim OwnershipClaim) error {
+	if claim.Audience == "" {
+		return ErrOwnershipClaimIncomplete
+	}
+	if signed == nil {
+		return ErrAttestationSignatureInvalid
+	}
+	if err := VerifySignedByAddress(signed, signed.Value.Owner, false); err != nil {
+		return err
+	}
+
+	proof := signed.Value
+	switch {
+	case proof.Owner != claim.Owner:
+		return fmt.Errorf("%w: owner is %s", ErrOwnershipProofMismatch, RedactAddress(proof.Owner))
+	case proof.Subject != claim.Subject:
+		return fmt.Errorf("%w: subject is %q", ErrOwnershipProofMismatch, proof.Subject)
+	case proof.Audience != claim.Audience:
+		return fmt.Errorf("%w: audience is %q", ErrOwnershipProofMismatch, proof.Audience)
+	case proof.Nonce != claim.Nonce:
+		return fmt.Errorf("%w: nonce differs", ErrOwnershipProofMismatch)
+	}
+
+	maxAge := claim.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultOwnershipProofMaxAge
+	}
+	now := time.Now()
+	if proof.IssuedAt.After(now.Add(ownershipProofClockSkew)) || now.Sub(proof.IssuedAt) > maxAge {
+		return fmt.Errorf("%w: issued at %s", ErrOwnershipProofExpired, proof.IssuedAt.Format(time.RFC3339))
+	}
+	return nil
+}
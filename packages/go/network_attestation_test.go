@@ -0,0 +1,106 @@
+package constellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignedByNode(t *testing.T) {
+	node, err := GenerateKeyPair()
+	require.NoError(t, err)
+	other, err := GenerateKeyPair()
+	require.NoError(t, err)
+	message := map[string]interface{}{"kind": "join", "session": "42"}
+
+	signed, err := CreateSignedObject(message, node.PrivateKey, false)
+	require.NoError(t, err)
+	peerID := NormalizePublicKeyToID(node.PublicKey)
+
+	t.Run("accepts the node's signature by peer ID or public key", func(t *testing.T) {
+		assert.NoError(t, VerifySignedByNode(signed, peerID, false))
+		assert.NoError(t, VerifySignedByNode(signed, node.PublicKey, false))
+		assert.NoError(t, VerifySignedByAddress(signed, node.Address, false))
+	})
+
+	t.Run("rejects other signers", func(t *testing.T) {
+		assert.ErrorIs(t, VerifySignedByNode(signed, NormalizePublicKeyToID(other.PublicKey), false), ErrNotSignedByNode)
+		assert.ErrorIs(t, VerifySignedByAddress(signed, other.Address, false), ErrNotSignedByOwner)
+	})
+
+	t.Run("rejects tampered messages", func(t *testing.T) {
+		tampered := &Signed[map[string]interface{}]{
+			Value:  map[string]interface{}{"kind": "join", "session": "43"},
+			Proofs: signed.Proofs,
+		}
+		assert.ErrorIs(t, VerifySignedByNode(tampered, peerID, false), ErrAttestationSignatureInvalid)
+		assert.ErrorIs(t, VerifySignedByNode[map[string]interface{}](nil, peerID, false), ErrAttestationSignatureInvalid)
+	})
+}
+
+func TestOwnershipProof(t *testing.T) {
+	owner, err := GenerateKeyPair()
+	require.NoError(t, err)
+	signer, err := NewPrivateKeySigner(owner.PrivateKey)
+	require.NoError(t, err)
+
+	proof, err := CreateOwnershipProof("metagraph-1", "indexer", "challenge-7", signer)
+	require.NoError(t, err)
+	assert.Equal(t, owner.Address, proof.Value.Owner)
+	claim := OwnershipClaim{Owner: owner.Address, Subject: "metagraph-1", Audience: "indexer", Nonce: "challenge-7", MaxAge: time.Minute}
+
+	t.Run("accepts a matching proof", func(t *testing.T) {
+		assert.NoError(t, VerifyOwnershipProof(proof, claim))
+	})
+
+	t.Run("rejects a different claim", func(t *testing.T) {
+		for name, modify := range map[string]func(*OwnershipClaim){
+			"owner":    func(c *OwnershipClaim) { c.Owner = "DAG0other" },
+			"subject":  func(c *OwnershipClaim) { c.Subject = "metagraph-2" },
+			"audience": func(c *OwnershipClaim) { c.Audience = "someone-else" },
+			"nonce":    func(c *OwnershipClaim) { c.Nonce = "challenge-8" },
+			"no nonce": func(c *OwnershipClaim) { c.Nonce = "" },
+		} {
+			changed := claim
+			modify(&changed)
+			assert.ErrorIs(t, VerifyOwnershipProof(proof, changed), ErrOwnershipProofMismatch, name)
+		}
+	})
+
+	t.Run("requires an audience", func(t *testing.T) {
+		open := claim
+		open.Audience = ""
+		assert.ErrorIs(t, VerifyOwnershipProof(proof, open), ErrOwnershipClaimIncomplete)
+	})
+
+	t.Run("bounds the age by default", func(t *testing.T) {
+		value := proof.Value
+		value.IssuedAt = time.Now().Add(-DefaultOwnershipProofMaxAge - time.Minute).UTC().Truncate(time.Second)
+		resigned, err := CreateSignedObjectWithSigner(value, signer, false)
+		require.NoError(t, err)
+		unbounded := claim
+		unbounded.MaxAge = 0
+		assert.ErrorIs(t, VerifyOwnershipProof(resigned, unbounded), ErrOwnershipProofExpired)
+	})
+
+	t.Run("rejects stale and future proofs", func(t *testing.T) {
+		for _, issuedAt := range []time.Time{time.Now().Add(-time.Hour), time.Now().Add(time.Hour)} {
+			value := proof.Value
+			value.IssuedAt = issuedAt.UTC().Truncate(time.Second)
+			resigned, err := CreateSignedObjectWithSigner(value, signer, false)
+			require.NoError(t, err)
+			assert.ErrorIs(t, VerifyOwnershipProof(resigned, claim), ErrOwnershipProofExpired)
+		}
+	})
+
+	t.Run("rejects a proof signed by someone other than the owner", func(t *testing.T) {
+		impostor, err := GenerateKeyPair()
+		require.NoError(t, err)
+		forged, err := CreateSignedObject(proof.Value, impostor.PrivateKey, false)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifyOwnershipProof(forged, claim), ErrNotSignedByOwner)
+		assert.ErrorIs(t, VerifyOwnershipProof(nil, claim), ErrAttestationSignatureInvalid)
+	})
+}
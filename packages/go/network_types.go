@@ -1,18 +1,75 @@
 package constellation
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // NetworkConfig holds configuration for connecting to L1 nodes
 type NetworkConfig struct {
 	// L1URL is the Currency L1 endpoint URL (e.g., "http://localhost:9010")
 	L1URL string
+	// DAGL1URL is the global DAG L1 endpoint URL, for native DAG transactions
+	// (e.g., "https://l1-lb-mainnet.constellationnetwork.io")
+	DAGL1URL string
 	// DataL1URL is the Data L1 endpoint URL (e.g., "http://localhost:8080")
 	DataL1URL string
+	// L0URL is the metagraph L0 endpoint URL (e.g., "http://localhost:9200")
+	L0URL string
+	// GlobalL0URL is the global L0 endpoint URL
+	// (e.g., "https://l0-lb-mainnet.constellationnetwork.io")
+	GlobalL0URL string
+	// ExplorerURL is the block explorer API URL
+	// (e.g., "https://be-mainnet.constellationnetwork.io")
+	ExplorerURL string
+	// ExplorerGraphQL selects the GraphQL API served at ExplorerURL +
+	// "/graphql" instead of the REST routes (see network.NewExplorer)
+	ExplorerGraphQL bool
 	// Timeout is the request timeout in seconds (default: 30)
 	Timeout int
+	// UserAgent identifies the integration to node operators (default: network.DefaultUserAgent)
+	UserAgent string
+	// RequestID generates the X-Request-ID correlation header for each
+	// request (default: random 16-byte hex)
+	RequestID func() string
+	// Transport tunes connection reuse (default: a pool shared by all
+	// clients, see DefaultTransportConfig)
+	Transport *TransportConfig
+
+	// scope is set on configs from Tenant.NetworkConfig
+	scope *tenantScope
+}
+
+// TransportConfig tunes connection reuse for SDK clients
+//
+// The zero value of each field selects the default from
+// DefaultTransportConfig.
+type TransportConfig struct {
+	// MaxIdleConns caps idle connections across all hosts (default: 100)
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections kept per node (default: 32).
+	// Go's own default is 2, which makes concurrent callers open and discard
+	// a connection per request.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps total connections per node (default: 0, unlimited)
+	MaxConnsPerHost int
+	// IdleConnTimeout closes idle connections after this long (default: 90s)
+	IdleConnTimeout time.Duration
+	// DisableHTTP2 keeps connections on HTTP/1.1 even when the node offers h2
+	DisableHTTP2 bool
+	// DisableKeepAlives opens a new connection for every request
+	DisableKeepAlives bool
+}
+
+// DefaultTransportConfig returns the transport settings used when none are configured
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+	}
 }
 
 // RequestOptions holds options for individual requests
@@ -44,6 +101,17 @@ type PendingTransaction struct {
 type PostTransactionResponse struct {
 	// Hash is the transaction hash
 	Hash string `json:"hash"`
+	// Node is the URL of the node that accepted the transaction, when the
+	// client knows it; not part of the node's response
+	Node string `json:"-"`
+}
+
+// BalanceResponse is the balance of an address at a snapshot
+type BalanceResponse struct {
+	// Ordinal is the snapshot ordinal the balance was read at
+	Ordinal int64 `json:"ordinal"`
+	// Balance is the balance in smallest units
+	Balance int64 `json:"balance"`
 }
 
 // EstimateFeeResponse is the response from estimating data transaction fee
@@ -65,6 +133,11 @@ type NetworkError struct {
 	Message    string
 	StatusCode int
 	Response   string
+	// RetryAfter is the server-requested backoff from a Retry-After header, if any
+	RetryAfter time.Duration
+	// RequestID is the X-Request-ID sent with the failed request, for
+	// correlating with node logs
+	RequestID string
 }
 
 func (e *NetworkError) Error() string {
@@ -85,7 +158,41 @@ func NewNetworkError(message string, statusCode int, response string) *NetworkEr
 
 // Common network errors
 var (
-	ErrL1URLRequired     = errors.New("L1URL is required for CurrencyL1Client")
-	ErrDataL1URLRequired = errors.New("DataL1URL is required for DataL1Client")
-	ErrRequestTimeout    = errors.New("request timeout")
+	ErrL1URLRequired       = errors.New("L1URL is required for CurrencyL1Client")
+	ErrDataL1URLRequired   = errors.New("DataL1URL is required for DataL1Client")
+	ErrDAGL1URLRequired    = errors.New("DAGL1URL is required for DAGL1Client")
+	ErrL0URLRequired       = errors.New("L0URL is required for CurrencyL0Client")
+	ErrExplorerURLRequired = errors.New("ExplorerURL is required for ExplorerClient")
+	ErrGlobalL0URLRequired = errors.New("GlobalL0URL is required for GlobalL0Client")
+	ErrRequestTimeout      = errors.New("request timeout")
+	// ErrFeatureUnsupported indicates the node's Tessellation version does not serve the endpoint
+	ErrFeatureUnsupported = errors.New("endpoint not supported by node version")
 )
+
+// CurrencyL1API is the set of Currency L1 operations used by higher-level
+// helpers. CurrencyL1Client and DAGL1Client implement it; tests and simulations can
+// substitute an in-memory implementation.
+type CurrencyL1API interface {
+	GetLastReference(address string) (*TransactionReference, error)
+	PostTransaction(transaction *CurrencyTransaction) (*PostTransactionResponse, error)
+	GetPendingTransaction(hash string) (*PendingTransaction, error)
+}
+
+// BalanceSource looks up the current balance of an address.
+// CurrencyL0Client implements this interface, and GlobalL0Client for DAG.
+type BalanceSource interface {
+	GetBalance(address string) (*BalanceResponse, error)
+}
+
+// Endpoints lists the node URLs serving each layer
+type Endpoints struct {
+	L0     []string
+	L1     []string
+	DataL1 []string
+}
+
+// EndpointResolver discovers the current node endpoints.
+// network.DNSEndpointResolver implements this interface.
+type EndpointResolver interface {
+	Resolve(ctx context.Context) (*Endpoints, error)
+}
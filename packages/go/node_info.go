@@ -0,0 +1,115 @@
+package constellation
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NodeInfo is the response from a node's /node/info endpoint
+type NodeInfo struct {
+	// State is the node state (e.g., "Ready")
+	State string `json:"state"`
+	// ID is the node's peer ID (public key without 04 prefix)
+	ID string `json:"id"`
+	// Host is the node's advertised host
+	Host string `json:"host"`
+	// PublicPort is the node's public HTTP port
+	PublicPort int `json:"publicPort"`
+	// P2PPort is the node's peer-to-peer port
+	P2PPort int `json:"p2pPort"`
+	// Session is the node session token
+	Session string `json:"session"`
+	// Version is the Tessellation release the node is running (e.g., "2.8.1")
+	Version string `json:"version"`
+}
+
+// Feature identifies an optional node endpoint that is not available on every
+// Tessellation release
+type Feature string
+
+const (
+	// FeatureEstimateFee is the Data L1 /data/estimate-fee endpoint
+	FeatureEstimateFee Feature = "estimate-fee"
+	// FeatureDelegatedStaking is the delegated staking route family
+	FeatureDelegatedStaking Feature = "delegated-staking"
+)
+
+// featureMinVersions maps each optional feature to the first Tessellation
+// release that serves it
+var featureMinVersions = map[Feature]string{
+	FeatureEstimateFee:      "2.3.0",
+	FeatureDelegatedStaking: "3.0.0",
+}
+
+// NodeVersion is a parsed Tessellation release version
+type NodeVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseNodeVersion parses a version string such as "2.8.1", "v3.0.0" or
+// "2.8.1-rc.2". Pre-release and build suffixes are ignored.
+func ParseNodeVersion(version string) (NodeVersion, bool) {
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return NodeVersion{}, false
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return NodeVersion{}, false
+	}
+
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return NodeVersion{}, false
+		}
+		nums[i] = n
+	}
+
+	return NodeVersion{Major: nums[0], Minor: nums[1], Patch: nums[2]}, true
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is older than, equal to,
+// or newer than other
+func (v NodeVersion) Compare(other NodeVersion) int {
+	switch {
+	case v.Major != other.Major:
+		return compareInts(v.Major, other.Major)
+	case v.Minor != other.Minor:
+		return compareInts(v.Minor, other.Minor)
+	default:
+		return compareInts(v.Patch, other.Patch)
+	}
+}
+
+func (v NodeVersion) String() string {
+	return strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// Supports reports whether a node running this version serves the feature.
+// Unknown features are assumed to be supported.
+func (v NodeVersion) Supports(feature Feature) bool {
+	minVersion, ok := featureMinVersions[feature]
+	if !ok {
+		return true
+	}
+	required, _ := ParseNodeVersion(minVersion)
+	return v.Compare(required) >= 0
+}
@@ -0,0 +1,33 @@
+package constellation
+
+import "strings"
+
+// OpError adds operation context to an error returned by an SDK client, so
+// logs read "postTransaction DAG8…f3wD POST http://node:9010/transactions ->
+// HTTP 400: Bad Request" rather than a bare message. Use errors.Is and
+// errors.As to inspect the underlying error.
+type OpError struct {
+	// Op is the client operation, e.g. "postTransaction"
+	Op string
+	// Address is the redacted DAG address the operation concerned, if any
+	Address string
+	// Endpoint is the request method and URL, if a request was made
+	Endpoint string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *OpError) Error() string {
+	parts := []string{e.Op}
+	if e.Address != "" {
+		parts = append(parts, e.Address)
+	}
+	if e.Endpoint != "" {
+		parts = append(parts, e.Endpoint)
+	}
+	return strings.Join(parts, " ") + " -> " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,955 @@
+package constellation
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+var (
+	// ErrInvalidP12 indicates data that is not a readable PKCS#12 keystore
+	ErrInvalidP12 = errors.New("invalid PKCS#12 keystore")
+	// ErrP12Password indicates the keystore password is wrong
+	ErrP12Password = errors.New("incorrect PKCS#12 password")
+	// ErrP12AliasNotFound indicates the keystore has no key under the alias
+	ErrP12AliasNotFound = errors.New("alias not found in PKCS#12 keystore")
+	// ErrP12Unsupported indicates an algorithm or key type the SDK cannot read
+	ErrP12Unsupported = errors.New("unsupported PKCS#12 content")
+)
+
+// p12Iterations is the PBKDF2 and MAC iteration count ExportP12 uses, the
+// same default as the JDK keytool
+const p12Iterations = 10000
+
+var (
+	oidP12Data          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidP12EncryptedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidP12KeyBag          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidP12ShroudedKeyBag  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidP12CertBag         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidP12X509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidP12FriendlyName    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidP12LocalKeyID      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+
+	oidPBEWithSHA1And3DES = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBES2              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1       = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA512     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC         = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidECPublicKey   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1     = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidECDSAWithSHA2 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidCommonName    = asn1.ObjectIdentifier{2, 5, 4, 3}
+)
+
+type p12PFX struct {
+	Version  int
+	AuthSafe p12ContentInfo
+	MacData  p12MacData `asn1:"optional"`
+}
+
+type p12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type p12MacData struct {
+	Mac        p12DigestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type p12DigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type p12EncryptedData struct {
+	Version     int
+	ContentInfo p12EncryptedContentInfo
+}
+
+type p12EncryptedContentInfo struct {
+	ContentType      asn1.ObjectIdentifier
+	Algorithm        pkix.AlgorithmIdentifier
+	EncryptedContent asn1.RawValue `asn1:"tag:0,optional"`
+}
+
+type p12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue  `asn1:"tag:0,explicit"`
+	Attributes []p12Attribute `asn1:"set,optional"`
+}
+
+type p12Attribute struct {
+	ID     asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type p12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type p12EncryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type p12PBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type p12PBES2Params struct {
+	KDF    pkix.AlgorithmIdentifier
+	Scheme pkix.AlgorithmIdentifier
+}
+
+type p12PBKDF2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type p12Certificate struct {
+	TBS       asn1.RawValue
+	Algorithm pkix.AlgorithmIdentifier
+	Signature asn1.BitString
+}
+
+type p12TBSCertificate struct {
+	Version   int `asn1:"tag:0,explicit"`
+	Serial    *big.Int
+	Algorithm pkix.AlgorithmIdentifier
+	Issuer    pkix.RDNSequence
+	Validity  p12Validity
+	Subject   pkix.RDNSequence
+	PublicKey p12PublicKeyInfo
+}
+
+type p12Validity struct {
+	NotBefore time.Time
+	NotAfter  time.Time `asn1:"generalized"`
+}
+
+type p12PublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// p12Key is a private key found in a keystore, with the alias it is stored
+// under
+type p12Key struct {
+	alias      string
+	localKeyID []byte
+	privateKey []byte
+}
+
+// LoadP12 reads the secp256k1 key stored under alias in a PKCS#12 (.p12)
+// keystore, such as the node keys Tessellation generates. Aliases match
+// case-insensitively; an empty alias selects the only key in the file.
+// Stores written by the JDK, BouncyCastle and OpenSSL are supported: keys
+// encrypted with PBES2 (PBKDF2 and AES or 3DES) or with
+// pbeWithSHAAnd3-KeyTripleDES-CBC, and MACs over SHA-1 or SHA-2.
+// Certificates encrypted with RC2 are skipped, since only the key is read.
+//
+// Example:
+//
+//	keyPair, err := constellation.LoadP12("node.p12", "alias", os.Getenv("CL_PASSWORD"))
+//	if err != nil {
+//		return err
+//	}
+//	signer, _ := keyPair.Signer()
+func LoadP12(path string, alias string, password string) (*KeyPair, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeP12(data, alias, password)
+}
+
+// DecodeP12 is LoadP12 for a keystore already in memory
+func DecodeP12(data []byte, alias string, password string) (*KeyPair, error) {
+	keys, err := decodeP12Keys(data, password)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range keys {
+			Zeroize(keys[i].privateKey)
+		}
+	}()
+
+	var found *p12Key
+	for i := range keys {
+		if alias == "" || strings.EqualFold(keys[i].alias, alias) {
+			if found != nil && alias == "" {
+				return nil, fmt.Errorf("%w: %d keys, an alias is required", ErrP12AliasNotFound, len(keys))
+			}
+			if found != nil {
+				return nil, fmt.Errorf("%w: %q names more than one key", ErrP12AliasNotFound, alias)
+			}
+			found = &keys[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %q", ErrP12AliasNotFound, alias)
+	}
+	return KeyPairFromPrivateKeyBytes(found.privateKey)
+}
+
+// ExportP12 writes keyPair to a new PKCS#12 file under alias, with a
+// self-signed certificate so Java keystores treat it as a private key entry.
+// The key is encrypted with PBES2 (PBKDF2-HMAC-SHA256, AES-256-CBC) and the
+// file is protected by an HMAC-SHA256 MAC, both derived from password.
+// The file is created with mode 0600 and an existing file is never
+// overwritten.
+func ExportP12(path string, keyPair *KeyPair, alias string, password string) error {
+	data, err := EncodeP12(keyPair, alias, password)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// EncodeP12 is ExportP12 returning the keystore bytes
+func EncodeP12(keyPair *KeyPair, alias string, password string) ([]byte, error) {
+	privateKeyBytes, err := keyPairScalar(keyPair)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(privateKeyBytes)
+	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
+	defer privateKey.Zero()
+
+	cert, err := p12SelfSignedCertificate(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	localKeyID := sha1.Sum(cert)
+	attributes, err := p12BagAttributes(alias, localKeyID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	certBag, err := asn1.Marshal(p12CertBag{ID: oidP12X509Certificate, Data: cert})
+	if err != nil {
+		return nil, err
+	}
+	certContents, err := p12SafeContents(p12SafeBag{
+		ID:         oidP12CertBag,
+		Value:      p12Explicit(certBag),
+		Attributes: attributes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pkcs8, err := marshalPKCS8PrivateKey(privateKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	shrouded, err := p12EncryptPBES2(pkcs8, password)
+	if err != nil {
+		return nil, err
+	}
+	keyContents, err := p12SafeContents(p12SafeBag{
+		ID:         oidP12ShroudedKeyBag,
+		Value:      p12Explicit(shrouded),
+		Attributes: attributes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authSafe, err := asn1.Marshal([]p12ContentInfo{
+		{ContentType: oidP12Data, Content: p12Explicit(certContents)},
+		{ContentType: oidP12Data, Content: p12Explicit(keyContents)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	authSafeContent, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	macSalt := make([]byte, 16)
+	if _, err := rand.Read(macSalt); err != nil {
+		return nil, err
+	}
+	macKey := p12KDF(sha256.New, 3, macSalt, p12Iterations, p12BMPPassword(password), sha256.Size)
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(authSafe)
+
+	return asn1.Marshal(p12PFX{
+		Version:  3,
+		AuthSafe: p12ContentInfo{ContentType: oidP12Data, Content: p12Explicit(authSafeContent)},
+		MacData: p12MacData{
+			Mac: p12DigestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+				Digest:    mac.Sum(nil),
+			},
+			MacSalt:    macSalt,
+			Iterations: p12Iterations,
+		},
+	})
+}
+
+// decodeP12Keys verifies the keystore MAC and returns every private key in it
+func decodeP12Keys(data []byte, password string) ([]p12Key, error) {
+	var pfx p12PFX
+	if err := p12Unmarshal(data, &pfx); err != nil {
+		return nil, err
+	}
+	if pfx.Version != 3 || !pfx.AuthSafe.ContentType.Equal(oidP12Data) {
+		return nil, fmt.Errorf("%w: authenticated safe is not plain data", ErrP12Unsupported)
+	}
+	var authSafe []byte
+	if err := p12Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, err
+	}
+	if pfx.MacData.Mac.Algorithm.Algorithm != nil {
+		if err := p12VerifyMAC(pfx.MacData, authSafe, password); err != nil {
+			return nil, err
+		}
+	}
+
+	var contents []p12ContentInfo
+	if err := p12Unmarshal(authSafe, &contents); err != nil {
+		return nil, err
+	}
+
+	var keys []p12Key
+	aliases := map[string]string{}
+	for _, content := range contents {
+		var safeContents []byte
+		switch {
+		case content.ContentType.Equal(oidP12Data):
+			if err := p12Unmarshal(content.Content.Bytes, &safeContents); err != nil {
+				return nil, err
+			}
+		case content.ContentType.Equal(oidP12EncryptedData):
+			var encrypted p12EncryptedData
+			if err := p12Unmarshal(content.Content.Bytes, &encrypted); err != nil {
+				return nil, err
+			}
+			ciphertext, err := p12OctetString(encrypted.ContentInfo.EncryptedContent)
+			if err != nil {
+				return nil, err
+			}
+			safeContents, err = p12Decrypt(encrypted.ContentInfo.Algorithm, ciphertext, password)
+			if errors.Is(err, ErrP12Unsupported) {
+				// usually the certificates, encrypted with RC2
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+
+		var bags []p12SafeBag
+		if err := p12Unmarshal(safeContents, &bags); err != nil {
+			return nil, err
+		}
+		for _, bag := range bags {
+			alias, localKeyID, err := p12ParseAttributes(bag.Attributes)
+			if err != nil {
+				return nil, err
+			}
+			var pkcs8 []byte
+			switch {
+			case bag.ID.Equal(oidP12CertBag):
+				if alias != "" && localKeyID != nil {
+					aliases[string(localKeyID)] = alias
+				}
+				continue
+			case bag.ID.Equal(oidP12KeyBag):
+				pkcs8 = bag.Value.Bytes
+			case bag.ID.Equal(oidP12ShroudedKeyBag):
+				var info p12EncryptedPrivateKeyInfo
+				if err := p12Unmarshal(bag.Value.Bytes, &info); err != nil {
+					return nil, err
+				}
+				if pkcs8, err = p12Decrypt(info.Algorithm, info.EncryptedData, password); err != nil {
+					return nil, err
+				}
+			default:
+				continue
+			}
+			privateKey, err := parsePKCS8PrivateKey(pkcs8, ErrInvalidP12, ErrP12Unsupported)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, p12Key{alias: alias, localKeyID: localKeyID, privateKey: privateKey})
+		}
+	}
+
+	// some stores name only the certificate; it shares the key's local key ID
+	for i := range keys {
+		if keys[i].alias == "" && keys[i].localKeyID != nil {
+			keys[i].alias = aliases[string(keys[i].localKeyID)]
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no private keys", ErrP12AliasNotFound)
+	}
+	return keys, nil
+}
+
+func p12VerifyMAC(macData p12MacData, authSafe []byte, password string) error {
+	h, err := p12Hash(macData.Mac.Algorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	if err := p12CheckIterations("MAC", macData.Iterations); err != nil {
+		return err
+	}
+	key := p12KDF(h, 3, macData.MacSalt, macData.Iterations, p12BMPPassword(password), h().Size())
+	mac := hmac.New(h, key)
+	mac.Write(authSafe)
+	if !hmac.Equal(mac.Sum(nil), macData.Mac.Digest) {
+		return ErrP12Password
+	}
+	return nil
+}
+
+// p12CheckIterations rejects an iteration count read from an untrusted file
+// before it reaches a KDF: below 1 is malformed, and above
+// keyStoreMaxIterations would keep the caller busy for hours
+func p12CheckIterations(what string, iterations int) error {
+	if iterations < 1 {
+		return fmt.Errorf("%w: %s iterations %d", ErrInvalidP12, what, iterations)
+	}
+	if iterations > keyStoreMaxIterations {
+		return fmt.Errorf("%w: %s iterations %d above %d", ErrP12Unsupported, what, iterations, keyStoreMaxIterations)
+	}
+	return nil
+}
+
+func p12ParseAttributes(attributes []p12Attribute) (alias string, localKeyID []byte, err error) {
+	for _, attribute := range attributes {
+		switch {
+		case attribute.ID.Equal(oidP12FriendlyName):
+			var value asn1.RawValue
+			if err := p12Unmarshal(attribute.Values.Bytes, &value); err != nil {
+				return "", nil, err
+			}
+			if value.Tag != asn1.TagBMPString || len(value.Bytes)%2 != 0 {
+				return "", nil, fmt.Errorf("%w: friendly name is not a BMPString", ErrInvalidP12)
+			}
+			units := make([]uint16, len(value.Bytes)/2)
+			for i := range units {
+				units[i] = uint16(value.Bytes[2*i])<<8 | uint16(value.Bytes[2*i+1])
+			}
+			alias = string(utf16.Decode(units))
+		case attribute.ID.Equal(oidP12LocalKeyID):
+			if err := p12Unmarshal(attribute.Values.Bytes, &localKeyID); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	return alias, localKeyID, nil
+}
+
+// p12Decrypt decrypts data encrypted with a PKCS#12 or PBES2 password-based
+// scheme
+func p12Decrypt(algorithm pkix.AlgorithmIdentifier, ciphertext []byte, password string) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+
+	switch {
+	case algorithm.Algorithm.Equal(oidPBEWithSHA1And3DES):
+		var params p12PBEParams
+		if err := p12Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		if err := p12CheckIterations("PBE", params.Iterations); err != nil {
+			return nil, err
+		}
+		bmpPassword := p12BMPPassword(password)
+		key := p12KDF(sha1.New, 1, params.Salt, params.Iterations, bmpPassword, 24)
+		iv = p12KDF(sha1.New, 2, params.Salt, params.Iterations, bmpPassword, des.BlockSize)
+		var err error
+		if block, err = des.NewTripleDESCipher(key); err != nil {
+			return nil, err
+		}
+
+	case algorithm.Algorithm.Equal(oidPBES2):
+		var params p12PBES2Params
+		if err := p12Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		if !params.KDF.Algorithm.Equal(oidPBKDF2) {
+			return nil, fmt.Errorf("%w: key derivation %v", ErrP12Unsupported, params.KDF.Algorithm)
+		}
+		var kdf p12PBKDF2Params
+		if err := p12Unmarshal(params.KDF.Parameters.FullBytes, &kdf); err != nil {
+			return nil, err
+		}
+		if err := p12CheckIterations("PBKDF2", kdf.Iterations); err != nil {
+			return nil, err
+		}
+		prf := sha1.New
+		switch {
+		case kdf.PRF.Algorithm == nil || kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+			prf = sha256.New
+		case kdf.PRF.Algorithm.Equal(oidHMACWithSHA512):
+			prf = sha512.New
+		default:
+			return nil, fmt.Errorf("%w: PBKDF2 PRF %v", ErrP12Unsupported, kdf.PRF.Algorithm)
+		}
+
+		var keyLength int
+		scheme := params.Scheme.Algorithm
+		switch {
+		case scheme.Equal(oidAES128CBC):
+			keyLength = 16
+		case scheme.Equal(oidAES192CBC):
+			keyLength = 24
+		case scheme.Equal(oidAES256CBC):
+			keyLength = 32
+		case scheme.Equal(oidDESEDE3CBC):
+			keyLength = 24
+		default:
+			return nil, fmt.Errorf("%w: cipher %v", ErrP12Unsupported, scheme)
+		}
+		if err := p12Unmarshal(params.Scheme.Parameters.FullBytes, &iv); err != nil {
+			return nil, err
+		}
+
+		key := pbkdf2Key(prf, []byte(password), kdf.Salt, kdf.Iterations, keyLength)
+		var err error
+		if scheme.Equal(oidDESEDE3CBC) {
+			block, err = des.NewTripleDESCipher(key)
+		} else {
+			block, err = aes.NewCipher(key)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: encryption %v", ErrP12Unsupported, algorithm.Algorithm)
+	}
+
+	size := block.BlockSize()
+	if len(iv) != size || len(ciphertext) == 0 || len(ciphertext)%size != 0 {
+		return nil, fmt.Errorf("%w: malformed ciphertext", ErrInvalidP12)
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// a wrong password shows up as bad padding when the store has no MAC
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > size {
+		return nil, ErrP12Password
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, ErrP12Password
+		}
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// p12EncryptPBES2 encrypts a PKCS#8 key as an EncryptedPrivateKeyInfo with
+// PBKDF2-HMAC-SHA256 and AES-256-CBC
+func p12EncryptPBES2(plaintext []byte, password string) ([]byte, error) {
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	prf, err := asn1.Marshal(pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue})
+	if err != nil {
+		return nil, err
+	}
+	kdfParams, err := asn1.Marshal(struct {
+		Salt       []byte
+		Iterations int
+		PRF        asn1.RawValue
+	}{salt, p12Iterations, asn1.RawValue{FullBytes: prf}})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(p12PBES2Params{
+		KDF:    pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		Scheme: pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(pbkdf2Key(sha256.New, []byte(password), salt, p12Iterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	return asn1.Marshal(p12EncryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: ciphertext,
+	})
+}
+
+// p12SelfSignedCertificate issues an X.509 certificate for the key, named
+// after its DAG address and signed by itself with ECDSA-SHA256
+func p12SelfSignedCertificate(privateKey *btcec.PrivateKey) ([]byte, error) {
+	curve, err := asn1.Marshal(oidSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, err
+	}
+	publicKey := privateKey.PubKey().SerializeUncompressed()
+	name := pkix.RDNSequence{{{Type: oidCommonName, Value: GetAddress(hex.EncodeToString(publicKey))}}}
+	signatureAlgorithm := pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA2}
+
+	tbs, err := asn1.Marshal(p12TBSCertificate{
+		Version:   2,
+		Serial:    serial,
+		Algorithm: signatureAlgorithm,
+		Issuer:    name,
+		Validity: p12Validity{
+			NotBefore: time.Now().UTC().Truncate(time.Second),
+			// RFC 5280's value for a certificate with no expiry
+			NotAfter: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+		Subject: name,
+		PublicKey: p12PublicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidECPublicKey, Parameters: asn1.RawValue{FullBytes: curve}},
+			PublicKey: asn1.BitString{Bytes: publicKey, BitLength: len(publicKey) * 8},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(tbs)
+	signature := ecdsa.Sign(privateKey, digest[:]).Serialize()
+	return asn1.Marshal(p12Certificate{
+		TBS:       asn1.RawValue{FullBytes: tbs},
+		Algorithm: signatureAlgorithm,
+		Signature: asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+}
+
+func p12BagAttributes(alias string, localKeyID []byte) ([]p12Attribute, error) {
+	var attributes []p12Attribute
+	if alias != "" {
+		name, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: p12BMPString(alias)})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, p12Attribute{ID: oidP12FriendlyName, Values: p12Set(name)})
+	}
+	id, err := asn1.Marshal(localKeyID)
+	if err != nil {
+		return nil, err
+	}
+	return append(attributes, p12Attribute{ID: oidP12LocalKeyID, Values: p12Set(id)}), nil
+}
+
+// p12SafeContents encodes bags as the OCTET STRING content of a data
+// ContentInfo
+func p12SafeContents(bags ...p12SafeBag) ([]byte, error) {
+	contents, err := asn1.Marshal(bags)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(contents)
+}
+
+// p12Explicit wraps an encoded value in a [0] EXPLICIT tag
+func p12Explicit(encoded []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: encoded}
+}
+
+func p12Set(encoded []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: encoded}
+}
+
+func p12Hash(algorithm asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case algorithm.Equal(oidSHA1):
+		return sha1.New, nil
+	case algorithm.Equal(oidSHA256):
+		return sha256.New, nil
+	case algorithm.Equal(oidSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: MAC digest %v", ErrP12Unsupported, algorithm)
+}
+
+// p12OctetString returns the contents of an implicitly tagged OCTET STRING,
+// joining the segments of a BER constructed encoding
+func p12OctetString(value asn1.RawValue) ([]byte, error) {
+	if !value.IsCompound {
+		return value.Bytes, nil
+	}
+	var joined []byte
+	for rest := value.Bytes; len(rest) > 0; {
+		var segment []byte
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &segment); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidP12, err)
+		}
+		joined = append(joined, segment...)
+	}
+	return joined, nil
+}
+
+// p12Unmarshal parses a complete DER or BER value into v
+func p12Unmarshal(data []byte, v interface{}) error {
+	der, err := berToDER(data)
+	if err != nil {
+		return err
+	}
+	rest, err := asn1.Unmarshal(der, v)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidP12, err)
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("%w: trailing data", ErrInvalidP12)
+	}
+	return nil
+}
+
+// p12KDF is the PKCS#12 key derivation function (RFC 7292 appendix B.2).
+// id selects the purpose: 1 for keys, 2 for IVs and 3 for MAC keys.
+func p12KDF(h func() hash.Hash, id byte, salt []byte, iterations int, password []byte, length int) []byte {
+	digest := h()
+	u, v := digest.Size(), digest.BlockSize()
+
+	fill := func(data []byte) []byte {
+		if len(data) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(data)+v-1)/v))
+		for i := range out {
+			out[i] = data[i%len(data)]
+		}
+		return out
+	}
+	input := append(fill(salt), fill(password)...)
+	diversifier := bytes.Repeat([]byte{id}, v)
+
+	var key []byte
+	for len(key) < length {
+		digest.Reset()
+		digest.Write(diversifier)
+		digest.Write(input)
+		a := digest.Sum(nil)
+		for i := 1; i < iterations; i++ {
+			digest.Reset()
+			digest.Write(a)
+			a = digest.Sum(a[:0])
+		}
+		key = append(key, a...)
+
+		// I_j = (I_j + B + 1) mod 2^(8v) for each v-byte block of the input
+		b := make([]byte, v)
+		for i := range b {
+			b[i] = a[i%u]
+		}
+		for j := 0; j < len(input); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(input[j+k]) + int(b[k]) + carry
+				input[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return key[:length]
+}
+
+// p12BMPPassword encodes a password as the null-terminated big-endian
+// UTF-16 string the PKCS#12 KDF expects
+func p12BMPPassword(password string) []byte {
+	return append(p12BMPString(password), 0, 0)
+}
+
+func p12BMPString(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, 2*len(units))
+	for _, unit := range units {
+		out = append(out, byte(unit>>8), byte(unit))
+	}
+	return out
+}
+
+// berMaxDepth bounds the nesting berToDER accepts
+const berMaxDepth = 32
+
+// berToDER rewrites BER indefinite lengths as definite lengths and joins
+// constructed OCTET STRINGs, as written by BouncyCastle, so encoding/asn1
+// can parse the result. DER input is returned unchanged.
+func berToDER(data []byte) ([]byte, error) {
+	out, rest, err := berConvert(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, rest...), nil
+}
+
+// berConvert converts the first element of data, returning it and the
+// bytes after it
+func berConvert(data []byte, depth int) (out []byte, rest []byte, err error) {
+	invalid := fmt.Errorf("%w: malformed ASN.1", ErrInvalidP12)
+	if depth > berMaxDepth || len(data) < 2 {
+		return nil, nil, invalid
+	}
+
+	// identifier octets, including high tag numbers
+	idLength := 1
+	if data[0]&0x1f == 0x1f {
+		for idLength < len(data) && data[idLength]&0x80 != 0 {
+			idLength++
+		}
+		idLength++
+	}
+	if idLength >= len(data) {
+		return nil, nil, invalid
+	}
+	identifier, constructed := data[:idLength], data[0]&0x20 != 0
+	data = data[idLength:]
+
+	indefinite := data[0] == 0x80
+	var length int
+	switch {
+	case indefinite:
+		if !constructed {
+			return nil, nil, invalid
+		}
+		data = data[1:]
+	case data[0] < 0x80:
+		length, data = int(data[0]), data[1:]
+	default:
+		n := int(data[0] & 0x7f)
+		if n > 4 || n >= len(data) {
+			return nil, nil, invalid
+		}
+		for _, b := range data[1 : 1+n] {
+			length = length<<8 | int(b)
+		}
+		data = data[1+n:]
+	}
+	if !indefinite && length > len(data) {
+		return nil, nil, invalid
+	}
+
+	if !constructed {
+		return berElement(identifier, data[:length]), data[length:], nil
+	}
+
+	inner := data
+	if !indefinite {
+		inner, rest = data[:length], data[length:]
+	}
+	var body []byte
+	for {
+		if !indefinite && len(inner) == 0 {
+			break
+		}
+		if indefinite && len(inner) >= 2 && inner[0] == 0 && inner[1] == 0 {
+			rest = inner[2:]
+			break
+		}
+		var child []byte
+		if child, inner, err = berConvert(inner, depth+1); err != nil {
+			return nil, nil, err
+		}
+		body = append(body, child...)
+	}
+
+	// a constructed OCTET STRING becomes a primitive one holding the
+	// concatenated segments
+	if identifier[0] == 0x24 {
+		var joined []byte
+		for segments := body; len(segments) > 0; {
+			var segment []byte
+			if segments, err = asn1.Unmarshal(segments, &segment); err != nil {
+				return nil, nil, invalid
+			}
+			joined = append(joined, segment...)
+		}
+		return berElement([]byte{0x04}, joined), rest, nil
+	}
+	return berElement(identifier, body), rest, nil
+}
+
+func berElement(identifier []byte, content []byte) []byte {
+	out := append([]byte{}, identifier...)
+	switch n := len(content); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n <= 0xff:
+		out = append(out, 0x81, byte(n))
+	case n <= 0xffff:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	case n <= 0xffffff:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, content...)
+}
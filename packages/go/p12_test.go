@@ -0,0 +1,190 @@
+package constellation
+
+import (
+	"crypto/sha1"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the key in testdata/node-*.p12, written by OpenSSL under alias NodeAlias
+// with password storepass
+const p12FixtureKey = "e77aabceef85d2baba15a033a89b36c4561971c3853dbe5e17702f9bde13f46e"
+
+func TestLoadP12(t *testing.T) {
+	fixtures := map[string]string{
+		// 3DES key, RC2 certificates and a SHA-1 MAC, as BouncyCastle writes
+		"legacy": "testdata/node-legacy.p12",
+		// PBES2 with AES-256 and a SHA-256 MAC, the OpenSSL 3 and JDK default
+		"pbes2": "testdata/node-pbes2.p12",
+	}
+	for name, path := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			keyPair, err := LoadP12(path, "nodealias", "storepass")
+			require.NoError(t, err)
+			assert.Equal(t, p12FixtureKey, privateKeyHexOf(t, keyPair))
+
+			_, err = LoadP12(path, "", "wrong")
+			assert.ErrorIs(t, err, ErrP12Password)
+
+			_, err = LoadP12(path, "other", "storepass")
+			assert.ErrorIs(t, err, ErrP12AliasNotFound)
+		})
+	}
+
+	t.Run("BER encoding", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/node-legacy.p12")
+		require.NoError(t, err)
+
+		keyPair, err := DecodeP12(berEncodePFX(t, data), "NodeAlias", "storepass")
+		require.NoError(t, err)
+		assert.Equal(t, p12FixtureKey, privateKeyHexOf(t, keyPair))
+	})
+}
+
+func TestExportP12(t *testing.T) {
+	keyPair, err := KeyPairFromPrivateKey(p12FixtureKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "exported.p12")
+	require.NoError(t, ExportP12(path, keyPair, "validator", "secret"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := LoadP12(path, "Validator", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, keyPair.Address, loaded.Address)
+	assert.Equal(t, p12FixtureKey, privateKeyHexOf(t, loaded))
+
+	_, err = LoadP12(path, "validator", "wrong")
+	assert.ErrorIs(t, err, ErrP12Password)
+
+	assert.Error(t, ExportP12(path, keyPair, "validator", "secret"), "existing files are not overwritten")
+
+	_, err = EncodeP12(&KeyPair{PrivateKey: "zz"}, "validator", "secret")
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+}
+
+func TestP12IterationLimits(t *testing.T) {
+	keyPair, err := KeyPairFromPrivateKey(p12FixtureKey)
+	require.NoError(t, err)
+	data, err := EncodeP12(keyPair, "validator", "secret")
+	require.NoError(t, err)
+
+	t.Run("MAC", func(t *testing.T) {
+		for iterations, want := range map[int]error{0: ErrInvalidP12, -1: ErrInvalidP12, keyStoreMaxIterations + 1: ErrP12Unsupported} {
+			var pfx p12PFX
+			_, err := asn1.Unmarshal(data, &pfx)
+			require.NoError(t, err)
+			pfx.MacData.Iterations = iterations
+			crafted, err := asn1.Marshal(pfx)
+			require.NoError(t, err)
+
+			_, err = DecodeP12(crafted, "validator", "secret")
+			assert.ErrorIs(t, err, want, "iterations %d", iterations)
+		}
+	})
+
+	t.Run("PBE", func(t *testing.T) {
+		params, err := asn1.Marshal(p12PBEParams{Salt: []byte("salt"), Iterations: keyStoreMaxIterations + 1})
+		require.NoError(t, err)
+		algorithm := pkix.AlgorithmIdentifier{Algorithm: oidPBEWithSHA1And3DES, Parameters: asn1.RawValue{FullBytes: params}}
+		_, err = p12Decrypt(algorithm, make([]byte, 8), "secret")
+		assert.ErrorIs(t, err, ErrP12Unsupported)
+	})
+
+	t.Run("PBKDF2", func(t *testing.T) {
+		kdf, err := asn1.Marshal(p12PBKDF2Params{Salt: []byte("salt"), Iterations: 0})
+		require.NoError(t, err)
+		iv, err := asn1.Marshal(make([]byte, 16))
+		require.NoError(t, err)
+		params, err := asn1.Marshal(p12PBES2Params{
+			KDF:    pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdf}},
+			Scheme: pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: iv}},
+		})
+		require.NoError(t, err)
+		algorithm := pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}}
+		_, err = p12Decrypt(algorithm, make([]byte, 16), "secret")
+		assert.ErrorIs(t, err, ErrInvalidP12)
+	})
+}
+
+func TestP12KDF(t *testing.T) {
+	// checked against `openssl kdf ... PKCS12KDF`
+	salt := []byte{0x0a, 0x58, 0xcf, 0x64, 0x53, 0x0d, 0x82, 0x3f}
+	password := p12BMPPassword("smeg")
+	assert.Equal(t, "8aaae6297b6cb04642ab5b077851284eb7128f1a2a7fbca3",
+		hex.EncodeToString(p12KDF(sha1.New, 1, salt, 1, password, 24)))
+	assert.Equal(t, "79993dfe048d3b76",
+		hex.EncodeToString(p12KDF(sha1.New, 2, salt, 1, password, 8)))
+}
+
+func TestBERToDER(t *testing.T) {
+	// SEQUENCE (indefinite) { OCTET STRING (constructed, indefinite) { "ab", "c" } }
+	ber := []byte{0x30, 0x80, 0x24, 0x80, 0x04, 0x02, 'a', 'b', 0x04, 0x01, 'c', 0x00, 0x00, 0x00, 0x00}
+	der, err := berToDER(ber)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x30, 0x05, 0x04, 0x03, 'a', 'b', 'c'}, der)
+
+	unchanged := []byte{0x30, 0x03, 0x02, 0x01, 0x03}
+	der, err = berToDER(unchanged)
+	require.NoError(t, err)
+	assert.Equal(t, unchanged, der)
+
+	_, err = berToDER([]byte{0x30, 0x80, 0x02, 0x01})
+	assert.ErrorIs(t, err, ErrInvalidP12)
+}
+
+// berEncodePFX re-encodes a DER PFX the way BouncyCastle does: indefinite
+// lengths and the authenticated safe split into constructed OCTET STRING
+// segments
+func berEncodePFX(t *testing.T, der []byte) []byte {
+	var pfx struct {
+		Version  int
+		AuthSafe struct {
+			ContentType asn1.ObjectIdentifier
+			Content     asn1.RawValue `asn1:"tag:0,explicit"`
+		}
+		MacData asn1.RawValue
+	}
+	_, err := asn1.Unmarshal(der, &pfx)
+	require.NoError(t, err)
+	var content []byte
+	_, err = asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &content)
+	require.NoError(t, err)
+
+	segments := []byte{0x24, 0x80}
+	for len(content) > 0 {
+		n := 100
+		if n > len(content) {
+			n = len(content)
+		}
+		segment, err := asn1.Marshal(content[:n])
+		require.NoError(t, err)
+		segments = append(segments, segment...)
+		content = content[n:]
+	}
+	segments = append(segments, 0, 0)
+
+	version, err := asn1.Marshal(pfx.Version)
+	require.NoError(t, err)
+	contentType, err := asn1.Marshal(pfx.AuthSafe.ContentType)
+	require.NoError(t, err)
+
+	out := append([]byte{0x30, 0x80}, version...)
+	out = append(out, 0x30, 0x80)
+	out = append(out, contentType...)
+	out = append(out, 0xa0, 0x80)
+	out = append(out, segments...)
+	out = append(out, 0, 0, 0, 0)
+	out = append(out, pfx.MacData.FullBytes...)
+	return append(out, 0, 0)
+}
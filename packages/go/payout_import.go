@@ -0,0 +1,267 @@
+package constellation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+var (
+	// ErrInvalidPayoutCSV indicates a payout CSV whose header lacks a
+	// required column or names a column twice
+	ErrInvalidPayoutCSV = errors.New("invalid payout CSV")
+	// ErrDuplicateReferenceID indicates a payout row reusing an earlier
+	// row's reference ID
+	ErrDuplicateReferenceID = errors.New("duplicate payout reference ID")
+)
+
+// PayoutCSVMapping names the columns of a payout CSV. Header names are
+// matched case-insensitively, ignoring surrounding spaces; other columns
+// are ignored.
+type PayoutCSVMapping struct {
+	// Address is the destination DAG address column (default: "address")
+	Address string
+	// Amount is the token amount column, an exact decimal such as "100.5"
+	// (default: "amount")
+	Amount string
+	// Fee is the token fee column (default: "fee"). Optional: without the
+	// column, or with an empty cell, a payout pays no fee.
+	Fee string
+	// ReferenceID is the column of the caller's reference for each payout
+	// (default: "reference_id"). Optional; non-empty IDs must be unique.
+	ReferenceID string
+	// Comma is the field delimiter (default: ',')
+	Comma rune
+}
+
+// ImportedTransfer is a valid payout CSV row
+type ImportedTransfer struct {
+	TransferParams
+	// ReferenceID is the row's reference ID, if any
+	ReferenceID string
+	// Line is the row's line in the file, from 1
+	Line int
+	// Warnings flag a suspicious but accepted fee (see CheckFee)
+	Warnings []FeeWarning
+}
+
+// PayoutRowError is a payout CSV row that was rejected
+type PayoutRowError struct {
+	// Line is the row's line in the file, from 1
+	Line int
+	// ReferenceID is the row's reference ID, if it could be read
+	ReferenceID string
+	// Err is the reason, e.g. ErrInvalidAddress or ErrInvalidTokenAmount
+	Err error
+}
+
+func (e *PayoutRowError) Error() string {
+	if e.ReferenceID != "" {
+		return fmt.Sprintf("line %d (%s): %v", e.Line, e.ReferenceID, e.Err)
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *PayoutRowError) Unwrap() error {
+	return e.Err
+}
+
+// PayoutImport is the result of ImportTransfersCSV
+type PayoutImport struct {
+	// Transfers are the valid rows, in file order
+	Transfers []ImportedTransfer
+	// Errors are the rejected rows, in file order
+	Errors []*PayoutRowError
+}
+
+// TransferParams returns the valid rows' transfers, in file order, for
+// DustPolicy.PlanTransfers or BuildBatch
+func (i *PayoutImport) TransferParams() []TransferParams {
+	transfers := make([]TransferParams, len(i.Transfers))
+	for j, transfer := range i.Transfers {
+		transfers[j] = transfer.TransferParams
+	}
+	return transfers
+}
+
+// ImportTransfersCSV reads a payout CSV one row at a time, validating each
+// row: a valid DAG address, a positive amount and a non-negative fee with at
+// most 8 decimal places, and a reference ID not used by an earlier row. Valid
+// rows become transfers; invalid rows are reported with their line numbers
+// and skipped, so one bad row does not hold up the rest of the payout.
+// Returns an error only when the header is unusable or reading fails.
+//
+// Example:
+//
+//	imported, err := constellation.ImportTransfersCSV(file, constellation.PayoutCSVMapping{Address: "wallet"})
+//	if err != nil {
+//	    return err
+//	}
+//	for _, rowErr := range imported.Errors {
+//	    log.Println(rowErr) // line 7 (payout-42): invalid DAG address
+//	}
+//	planned, deferred, err := dustPolicy.PlanTransfers(imported.TransferParams())
+//	txs, err := constellation.BuildBatch(builder, planned, lastRef)
+func ImportTransfersCSV(reader io.Reader, mapping PayoutCSVMapping) (*PayoutImport, error) {
+	csvReader := csv.NewReader(reader)
+	if mapping.Comma != 0 {
+		csvReader.Comma = mapping.Comma
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: no header", ErrInvalidPayoutCSV)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayoutCSV, err)
+	}
+	columns, err := mapPayoutColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PayoutImport{}
+	seen := map[string]int{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Errors = append(result.Errors, &PayoutRowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		transfer, err := parsePayoutRow(record, columns)
+		transfer.Line = line
+		if err == nil && transfer.ReferenceID != "" {
+			if first, ok := seen[transfer.ReferenceID]; ok {
+				err = fmt.Errorf("%w: first used on line %d", ErrDuplicateReferenceID, first)
+			} else {
+				seen[transfer.ReferenceID] = line
+			}
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, &PayoutRowError{Line: line, ReferenceID: transfer.ReferenceID, Err: err})
+			continue
+		}
+		result.Transfers = append(result.Transfers, transfer)
+	}
+}
+
+// payoutColumns holds the field index of each mapped column, -1 when an
+// optional column is absent
+type payoutColumns struct {
+	address, amount, fee, referenceID int
+}
+
+func mapPayoutColumns(header []string, mapping PayoutCSVMapping) (payoutColumns, error) {
+	indexes := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if i == 0 {
+			// spreadsheet exports often start with a UTF-8 byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if _, ok := indexes[name]; ok {
+			return payoutColumns{}, fmt.Errorf("%w: column %q appears twice", ErrInvalidPayoutCSV, name)
+		}
+		indexes[name] = i
+	}
+	find := func(name string, fallback string, required bool) (int, error) {
+		if name == "" {
+			name = fallback
+		}
+		index, ok := indexes[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if required {
+				return 0, fmt.Errorf("%w: missing column %q", ErrInvalidPayoutCSV, name)
+			}
+			return -1, nil
+		}
+		return index, nil
+	}
+
+	var columns payoutColumns
+	var err error
+	if columns.address, err = find(mapping.Address, "address", true); err != nil {
+		return payoutColumns{}, err
+	}
+	if columns.amount, err = find(mapping.Amount, "amount", true); err != nil {
+		return payoutColumns{}, err
+	}
+	columns.fee, _ = find(mapping.Fee, "fee", false)
+	columns.referenceID, _ = find(mapping.ReferenceID, "reference_id", false)
+	return columns, nil
+}
+
+// parsePayoutRow validates one row. The reference ID is filled in even when
+// the row is invalid, to identify it in the error.
+func parsePayoutRow(record []string, columns payoutColumns) (ImportedTransfer, error) {
+	field := func(index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	transfer := ImportedTransfer{ReferenceID: field(columns.referenceID)}
+	if columns.address >= len(record) || columns.amount >= len(record) {
+		return transfer, fmt.Errorf("row has %d fields", len(record))
+	}
+	address := field(columns.address)
+	if !IsValidDAGAddress(address) {
+		return transfer, fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+	amount, err := ParseTokenAmount(field(columns.amount))
+	if err != nil {
+		return transfer, err
+	}
+	if amount <= 0 {
+		return transfer, ErrInvalidAmount
+	}
+	var fee int64
+	if text := field(columns.fee); text != "" {
+		if fee, err = ParseTokenAmount(text); err != nil {
+			return transfer, err
+		}
+	}
+	warnings, err := CheckFee(amount, fee)
+	if err != nil {
+		return transfer, err
+	}
+
+	// ExactAmount and ExactFee are what gets signed; the float64 fields are
+	// the nearest token amounts, for display
+	transfer.TransferParams = TransferParams{
+		Destination: address,
+		ExactAmount: AmountFromUnits(amount),
+		ExactFee:    AmountFromUnits(fee),
+	}
+	transfer.Amount, _ = exactTokenAmount(amount)
+	transfer.Fee, _ = exactTokenAmount(fee)
+	transfer.Warnings = warnings
+	return transfer, nil
+}
+
+// exactTokenAmount converts units to a token amount that TokenToUnits maps
+// back to the same units, reporting false above 2^53 units or so, where
+// float64 cannot hold every unit. UnitsToToken can land just below, which
+// the floor in TokenToUnits would turn into one unit less (e.g. 59 units).
+func exactTokenAmount(units int64) (float64, bool) {
+	amount := UnitsToToken(units)
+	for TokenToUnits(amount) < units {
+		amount = math.Nextafter(amount, math.Inf(1))
+	}
+	return amount, TokenToUnits(amount) == units
+}
@@ -0,0 +1,135 @@
+package constellation
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTransfersCSV(t *testing.T) {
+	alice, err := GenerateKeyPair()
+	require.NoError(t, err)
+	bob, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	t.Run("imports valid rows and reports the rest by line", func(t *testing.T) {
+		file := strings.Join([]string{
+			"reference_id,address,amount,fee,note",
+			"p-1," + alice.Address + ",100.5,0.001,first",
+			"p-2,DAGnope,1,,bad address",
+			"",
+			"p-3," + bob.Address + ",0.00000059,,",
+			"p-4," + bob.Address + ",1.123456789,,too precise",
+			"p-1," + bob.Address + ",2,,reused ID",
+			"p-5," + bob.Address + ",0,,zero",
+			"p-6," + alice.Address + ",1,2,fee above amount",
+		}, "\n")
+		imported, err := ImportTransfersCSV(strings.NewReader(file), PayoutCSVMapping{})
+		require.NoError(t, err)
+
+		require.Len(t, imported.Transfers, 3)
+		assert.Equal(t, ImportedTransfer{
+			TransferParams: TransferParams{
+				Destination: alice.Address,
+				Amount:      100.5,
+				Fee:         0.001,
+				ExactAmount: AmountFromUnits(10050000000),
+				ExactFee:    AmountFromUnits(100000),
+			},
+			ReferenceID: "p-1",
+			Line:        2,
+		}, imported.Transfers[0])
+		assert.Equal(t, 5, imported.Transfers[1].Line)
+		assert.Equal(t, []FeeWarning{FeeWarningExceedsAmount}, imported.Transfers[2].Warnings)
+
+		require.Len(t, imported.Errors, 4)
+		for i, expected := range []struct {
+			line int
+			err  error
+		}{
+			{3, ErrInvalidAddress},
+			{6, ErrInvalidTokenAmount},
+			{7, ErrDuplicateReferenceID},
+			{8, ErrInvalidAmount},
+		} {
+			assert.Equal(t, expected.line, imported.Errors[i].Line)
+			assert.ErrorIs(t, imported.Errors[i], expected.err)
+		}
+		assert.Equal(t, "p-1", imported.Errors[2].ReferenceID)
+		assert.Contains(t, imported.Errors[2].Error(), "line 7 (p-1)")
+	})
+
+	t.Run("amounts survive conversion to units exactly", func(t *testing.T) {
+		file := "address,amount\n" + bob.Address + ",0.00000059\n" + bob.Address + ",12345678.99999999\n" + bob.Address + ",123456789.00000001\n"
+		imported, err := ImportTransfersCSV(strings.NewReader(file), PayoutCSVMapping{})
+		require.NoError(t, err)
+		require.Empty(t, imported.Errors)
+
+		txs, err := BuildBatch(NewTransactionBuilder(alice.Address), imported.TransferParams(), GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, int64(59), txs[0].Value.Amount)
+		assert.Equal(t, int64(1234567899999999), txs[1].Value.Amount)
+		assert.Equal(t, int64(12345678900000001), txs[2].Value.Amount)
+	})
+
+	t.Run("feeds the dust planner", func(t *testing.T) {
+		file := "address,amount\n" + alice.Address + ",0.05\n" + alice.Address + ",0.06\n" + bob.Address + ",3\n"
+		imported, err := ImportTransfersCSV(strings.NewReader(file), PayoutCSVMapping{})
+		require.NoError(t, err)
+
+		policy := DustPolicy{Threshold: TokenToUnits(0.1), Action: DustAggregate}
+		planned, deferred, err := policy.PlanTransfers(imported.TransferParams())
+		require.NoError(t, err)
+		assert.Empty(t, deferred)
+		require.Len(t, planned, 2)
+		assert.Equal(t, bob.Address, planned[1].Destination)
+	})
+
+	t.Run("maps custom columns", func(t *testing.T) {
+		file := "\ufeffWallet ; Tokens ; Payout\n" + alice.Address + " ; 7 ; w-9\n"
+		imported, err := ImportTransfersCSV(strings.NewReader(file), PayoutCSVMapping{
+			Address:     "wallet",
+			Amount:      "TOKENS",
+			ReferenceID: "payout",
+			Comma:       ';',
+		})
+		require.NoError(t, err)
+		require.Len(t, imported.Transfers, 1)
+		assert.Equal(t, "w-9", imported.Transfers[0].ReferenceID)
+		assert.Equal(t, 7.0, imported.Transfers[0].Amount)
+		assert.Zero(t, imported.Transfers[0].Fee)
+	})
+
+	t.Run("reports malformed rows", func(t *testing.T) {
+		file := "address,amount,reference_id\n" + alice.Address + "\n\"unterminated,1,x\n"
+		imported, err := ImportTransfersCSV(strings.NewReader(file), PayoutCSVMapping{})
+		require.NoError(t, err)
+		assert.Empty(t, imported.Transfers)
+		require.Len(t, imported.Errors, 2)
+		assert.Equal(t, 2, imported.Errors[0].Line)
+		assert.Equal(t, 3, imported.Errors[1].Line)
+	})
+
+	t.Run("rejects unusable headers", func(t *testing.T) {
+		for name, file := range map[string]string{
+			"empty":           "",
+			"missing amount":  "address,fee\n",
+			"repeated column": "address,amount,Amount\n",
+		} {
+			_, err := ImportTransfersCSV(strings.NewReader(file), PayoutCSVMapping{})
+			assert.ErrorIs(t, err, ErrInvalidPayoutCSV, name)
+		}
+	})
+
+	t.Run("returns read errors", func(t *testing.T) {
+		failure := errors.New("disk gone")
+		reader := io.MultiReader(strings.NewReader("address,amount\n"+alice.Address+",1\n"), iotest.ErrReader(failure))
+		_, err := ImportTransfersCSV(reader, PayoutCSVMapping{})
+		assert.ErrorIs(t, err, failure)
+	})
+}
@@ -0,0 +1,211 @@
+package constellation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PayoutReport summarizes a payout run for finance sign-off: one entry per
+// withdrawal with its outcome, and totals per status. It is built from the
+// run's receipts alone, so the same receipts always give the same report.
+type PayoutReport struct {
+	// RunID is the caller's reference for the run
+	RunID string `json:"runId"`
+	// Source is the hot wallet address the run paid from
+	Source string `json:"source"`
+	// StartedAt is when the first request was taken up
+	StartedAt time.Time `json:"startedAt"`
+	// FinishedAt is when the last request was finished
+	FinishedAt time.Time           `json:"finishedAt"`
+	Entries    []PayoutReportEntry `json:"entries"`
+	Totals     PayoutReportTotals  `json:"totals"`
+}
+
+// PayoutReportEntry is the outcome of one withdrawal
+type PayoutReportEntry struct {
+	ID          string `json:"id"`
+	Destination string `json:"destination"`
+	// Amount in smallest units (1e-8)
+	Amount int64 `json:"amount"`
+	// Fee in smallest units (1e-8)
+	Fee    int64            `json:"fee"`
+	Status WithdrawalStatus `json:"status"`
+	// Hash is empty when no transaction was built
+	Hash string `json:"hash,omitempty"`
+	// Ordinal is the transaction's position in the source's chain, when
+	// one was built
+	Ordinal int `json:"ordinal,omitempty"`
+	// Node is the L1 node that accepted the transaction, when known
+	Node        string    `json:"node,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	ProcessedAt time.Time `json:"processedAt"`
+	// DurationMillis is how long the request took to process
+	DurationMillis int64 `json:"durationMs"`
+	// Error is the rejection or failure reason
+	Error    string       `json:"error,omitempty"`
+	Warnings []FeeWarning `json:"warnings,omitempty"`
+}
+
+// PayoutReportTotals counts entries per status. Amounts are in smallest
+// units (1e-8) and cover submitted withdrawals only.
+type PayoutReportTotals struct {
+	Count     int `json:"count"`
+	Submitted int `json:"submitted"`
+	Rejected  int `json:"rejected"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	// Unknown counts submissions whose outcome must be reconciled by hash
+	Unknown         int   `json:"unknown"`
+	SubmittedAmount int64 `json:"submittedAmount"`
+	SubmittedFee    int64 `json:"submittedFee"`
+}
+
+// NewPayoutReport builds the report of a run from its receipts, kept in the
+// order given (WithdrawalQueueConfig.OnReceipt delivers them in processing
+// order). Times are converted to UTC.
+//
+// Example:
+//
+//	var receipts []WithdrawalReceipt
+//	queue, _ := NewWithdrawalQueue(ctx, WithdrawalQueueConfig{
+//	    // ...
+//	    OnReceipt: func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+//	})
+//	// enqueue the run, then
+//	queue.Close()
+//	report := NewPayoutReport("payout-2024-06-01", queue.Source(), receipts)
+//	data, err := report.JSON()
+//	err = report.WriteCSV(csvFile)
+func NewPayoutReport(runID string, source string, receipts []WithdrawalReceipt) *PayoutReport {
+	report := &PayoutReport{
+		RunID:   runID,
+		Source:  source,
+		Entries: make([]PayoutReportEntry, 0, len(receipts)),
+	}
+	for _, receipt := range receipts {
+		entry := PayoutReportEntry{
+			ID:          receipt.Request.ID,
+			Destination: receipt.Request.Destination,
+			Amount:      receipt.Request.Amount,
+			Fee:         receipt.Request.Fee,
+			Status:      receipt.Status,
+			Hash:        receipt.Hash,
+			Node:        receipt.Node,
+			StartedAt:   receipt.StartedAt.UTC(),
+			ProcessedAt: receipt.ProcessedAt.UTC(),
+			Warnings:    receipt.Warnings,
+		}
+		if receipt.Hash != "" {
+			entry.Ordinal = receipt.Parent.Ordinal + 1
+		}
+		if !receipt.StartedAt.IsZero() && !receipt.ProcessedAt.IsZero() {
+			entry.DurationMillis = receipt.ProcessedAt.Sub(receipt.StartedAt).Milliseconds()
+		}
+		if receipt.Err != nil {
+			entry.Error = receipt.Err.Error()
+		}
+		report.Entries = append(report.Entries, entry)
+
+		if !entry.StartedAt.IsZero() && (report.StartedAt.IsZero() || entry.StartedAt.Before(report.StartedAt)) {
+			report.StartedAt = entry.StartedAt
+		}
+		if entry.ProcessedAt.After(report.FinishedAt) {
+			report.FinishedAt = entry.ProcessedAt
+		}
+
+		totals := &report.Totals
+		totals.Count++
+		switch receipt.Status {
+		case WithdrawalSubmitted:
+			totals.Submitted++
+			totals.SubmittedAmount += entry.Amount
+			totals.SubmittedFee += entry.Fee
+		case WithdrawalRejected:
+			totals.Rejected++
+		case WithdrawalFailed:
+			totals.Failed++
+		case WithdrawalCancelled:
+			totals.Cancelled++
+		case WithdrawalUnknown:
+			totals.Unknown++
+		}
+	}
+	return report
+}
+
+// JSON encodes the report, indented for reading
+func (r *PayoutReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// payoutReportColumns is the CSV header of WriteCSV
+var payoutReportColumns = []string{
+	"id", "destination", "amount", "fee", "status", "hash", "ordinal", "node",
+	"started_at", "processed_at", "duration_ms", "error", "warnings",
+}
+
+// WriteCSV writes one row per entry under a header. Amounts are exact
+// decimal token amounts, as ImportTransfersCSV reads them, and times are
+// RFC 3339 with nanoseconds; totals are left to the spreadsheet. Cells a
+// spreadsheet would run as a formula, such as a withdrawal ID or error
+// starting with "=", are prefixed with a single quote.
+func (r *PayoutReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(payoutReportColumns); err != nil {
+		return err
+	}
+	for _, entry := range r.Entries {
+		ordinal := ""
+		if entry.Hash != "" {
+			ordinal = strconv.Itoa(entry.Ordinal)
+		}
+		warnings := make([]string, len(entry.Warnings))
+		for i, warning := range entry.Warnings {
+			warnings[i] = string(warning)
+		}
+		row := []string{
+			entry.ID,
+			entry.Destination,
+			FormatTokenAmount(entry.Amount),
+			FormatTokenAmount(entry.Fee),
+			string(entry.Status),
+			entry.Hash,
+			ordinal,
+			entry.Node,
+			formatReportTime(entry.StartedAt),
+			formatReportTime(entry.ProcessedAt),
+			strconv.FormatInt(entry.DurationMillis, 10),
+			entry.Error,
+			strings.Join(warnings, "; "),
+		}
+		for i, cell := range row {
+			row[i] = escapeCSVFormula(cell)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeCSVFormula quotes a cell that spreadsheets would otherwise read as
+// a formula (CSV injection)
+func escapeCSVFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// formatReportTime formats t for WriteCSV; the zero time is empty
+func formatReportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
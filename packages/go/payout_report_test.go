@@ -0,0 +1,134 @@
+package constellation
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayoutReport(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	alice, _ := GenerateKeyPair()
+	bob, _ := GenerateKeyPair()
+
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+
+	var receipts []WithdrawalReceipt
+	queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+		L1:         ledger,
+		PrivateKey: hot.PrivateKey,
+		Policies:   []WithdrawalPolicy{MaxAmountPolicy(TokenToUnits(50))},
+		OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+	})
+	require.NoError(t, err)
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-1", Destination: alice.Address, Amount: TokenToUnits(10), Fee: 5}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-2", Destination: bob.Address, Amount: TokenToUnits(60)}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-3", Destination: bob.Address, Amount: 1, Fee: 2}))
+	require.NoError(t, queue.Close())
+
+	report := NewPayoutReport("run-1", queue.Source(), receipts)
+
+	t.Run("records every outcome", func(t *testing.T) {
+		assert.Equal(t, "run-1", report.RunID)
+		assert.Equal(t, hot.Address, report.Source)
+		require.Len(t, report.Entries, 3)
+
+		first := report.Entries[0]
+		assert.Equal(t, WithdrawalSubmitted, first.Status)
+		assert.Equal(t, receipts[0].Hash, first.Hash)
+		assert.Equal(t, 1, first.Ordinal)
+		assert.False(t, first.StartedAt.After(first.ProcessedAt))
+		assert.Equal(t, time.UTC, first.ProcessedAt.Location())
+
+		rejected := report.Entries[1]
+		assert.Equal(t, WithdrawalRejected, rejected.Status)
+		assert.Empty(t, rejected.Hash)
+		assert.Zero(t, rejected.Ordinal)
+		assert.Equal(t, ErrWithdrawalAmountExceeded.Error(), rejected.Error)
+
+		assert.Equal(t, 2, report.Entries[2].Ordinal)
+		assert.Equal(t, []FeeWarning{FeeWarningExceedsAmount}, report.Entries[2].Warnings)
+
+		assert.Equal(t, PayoutReportTotals{
+			Count:           3,
+			Submitted:       2,
+			Rejected:        1,
+			SubmittedAmount: TokenToUnits(10) + 1,
+			SubmittedFee:    7,
+		}, report.Totals)
+		assert.Equal(t, report.Entries[0].StartedAt, report.StartedAt)
+		assert.Equal(t, report.Entries[2].ProcessedAt, report.FinishedAt)
+	})
+
+	t.Run("is reproducible from the receipts", func(t *testing.T) {
+		data, err := report.JSON()
+		require.NoError(t, err)
+		again, err := NewPayoutReport("run-1", queue.Source(), receipts).JSON()
+		require.NoError(t, err)
+		assert.Equal(t, string(data), string(again))
+
+		var decoded PayoutReport
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, report, &decoded)
+	})
+
+	t.Run("exports CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteCSV(&buf))
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		assert.Equal(t, payoutReportColumns, rows[0])
+		assert.Equal(t, []string{"w-1", alice.Address, "10", "0.00000005", "Submitted", receipts[0].Hash, "1"}, rows[1][:7])
+		assert.Equal(t, "", rows[2][6])
+		assert.Equal(t, ErrWithdrawalAmountExceeded.Error(), rows[2][11])
+		assert.Equal(t, string(FeeWarningExceedsAmount), rows[3][12])
+	})
+
+	t.Run("keeps receipts that never started", func(t *testing.T) {
+		report := NewPayoutReport("run-2", hot.Address, []WithdrawalReceipt{{
+			Request: WithdrawalRequest{ID: "w-9"},
+			Status:  WithdrawalFailed,
+			Err:     errors.New("boom"),
+		}})
+		assert.Zero(t, report.Entries[0].DurationMillis)
+		assert.True(t, report.StartedAt.IsZero())
+		assert.Equal(t, 1, report.Totals.Failed)
+
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteCSV(&buf))
+		assert.Contains(t, buf.String(), "w-9,,0,0,Failed,,,,,,0,boom,")
+	})
+
+	t.Run("escapes formulas in CSV cells", func(t *testing.T) {
+		report := NewPayoutReport("run-3", hot.Address, []WithdrawalReceipt{{
+			Request: WithdrawalRequest{ID: "=HYPERLINK(\"http://evil\")"},
+			Status:  WithdrawalFailed,
+			Err:     errors.New("@SUM(A1)"),
+		}, {
+			Request: WithdrawalRequest{ID: "\t+1"},
+			Status:  WithdrawalFailed,
+			Err:     errors.New("-2+3"),
+		}})
+
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteCSV(&buf))
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, "'=HYPERLINK(\"http://evil\")", rows[1][0])
+		assert.Equal(t, "'@SUM(A1)", rows[1][11])
+		assert.Equal(t, "'\t+1", rows[2][0])
+		assert.Equal(t, "'-2+3", rows[2][11])
+		assert.Equal(t, "0", rows[2][2], "plain cells are unchanged")
+	})
+}
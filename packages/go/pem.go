@@ -0,0 +1,210 @@
+package constellation
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+var (
+	// ErrInvalidPEM indicates data that holds no readable PEM private key
+	ErrInvalidPEM = errors.New("invalid PEM private key")
+	// ErrPEMUnsupported indicates a PEM key the SDK cannot read: another
+	// curve or key type, or an encrypted key
+	ErrPEMUnsupported = errors.New("unsupported PEM private key")
+)
+
+const (
+	pemPKCS8Type = "PRIVATE KEY"
+	pemSEC1Type  = "EC PRIVATE KEY"
+)
+
+// pkcs8PrivateKeyInfo is a PKCS#8 PrivateKeyInfo (RFC 5208)
+type pkcs8PrivateKeyInfo struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// sec1PrivateKey is a SEC1 ECPrivateKey (RFC 5915)
+type sec1PrivateKey struct {
+	Version    int
+	PrivateKey []byte
+	Curve      asn1.ObjectIdentifier `asn1:"tag:0,explicit,optional"`
+	PublicKey  asn1.BitString        `asn1:"tag:1,explicit,optional"`
+}
+
+// KeyPairFromPEM reads a secp256k1 private key in PEM, either PKCS#8
+// ("PRIVATE KEY", from openssl genpkey or pkcs8 -topk8 -nocrypt) or SEC1
+// ("EC PRIVATE KEY", from openssl ecparam -genkey). Other blocks, such as
+// the EC PARAMETERS openssl writes first, are skipped. Encrypted keys return
+// ErrPEMUnsupported; decrypt them with openssl first.
+//
+// Example:
+//
+//	// openssl ecparam -name secp256k1 -genkey -noout -out node.pem
+//	data, err := os.ReadFile("node.pem")
+//	keyPair, err := constellation.KeyPairFromPEM(data)
+func KeyPairFromPEM(data []byte) (*KeyPair, error) {
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("%w: no private key block", ErrInvalidPEM)
+		}
+
+		var privateKey []byte
+		var err error
+		switch {
+		case block.Type == "ENCRYPTED PRIVATE KEY" || block.Headers["Proc-Type"] != "":
+			return nil, fmt.Errorf("%w: key is encrypted", ErrPEMUnsupported)
+		case block.Type == pemPKCS8Type:
+			privateKey, err = parsePKCS8PrivateKey(block.Bytes, ErrInvalidPEM, ErrPEMUnsupported)
+		case block.Type == pemSEC1Type:
+			privateKey, err = parseSEC1PrivateKey(block.Bytes, nil, ErrInvalidPEM, ErrPEMUnsupported)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer Zeroize(privateKey)
+		return KeyPairFromPrivateKeyBytes(privateKey)
+	}
+}
+
+// ExportPEM encodes keyPair's private key as an unencrypted PKCS#8 PEM
+// block ("PRIVATE KEY"), which openssl and most key tooling read
+func ExportPEM(keyPair *KeyPair) ([]byte, error) {
+	privateKey, err := keyPairScalar(keyPair)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(privateKey)
+	der, err := marshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPKCS8Type, Bytes: der}), nil
+}
+
+// ExportSEC1PEM encodes keyPair's private key as a SEC1 PEM block ("EC
+// PRIVATE KEY"), the format of openssl ecparam -genkey
+func ExportSEC1PEM(keyPair *KeyPair) ([]byte, error) {
+	privateKey, err := keyPairScalar(keyPair)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(privateKey)
+	der, err := marshalSEC1PrivateKey(privateKey, true)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemSEC1Type, Bytes: der}), nil
+}
+
+// keyPairScalar returns a copy of keyPair's validated 32-byte private key,
+// for the caller to Zeroize
+func keyPairScalar(keyPair *KeyPair) ([]byte, error) {
+	if keyPair == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	return keyPair.PrivateKeyBytes()
+}
+
+// marshalPKCS8PrivateKey encodes a secp256k1 private key as PKCS#8, with
+// the curve in the algorithm parameters and the public key included
+func marshalPKCS8PrivateKey(privateKey []byte) ([]byte, error) {
+	curve, err := asn1.Marshal(oidSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	sec1, err := marshalSEC1PrivateKey(privateKey, false)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8PrivateKeyInfo{
+		Algorithm:  pkix.AlgorithmIdentifier{Algorithm: oidECPublicKey, Parameters: asn1.RawValue{FullBytes: curve}},
+		PrivateKey: sec1,
+	})
+}
+
+// marshalSEC1PrivateKey encodes a secp256k1 private key as SEC1 with its
+// public key. withCurve names the curve, which PKCS#8 leaves to the
+// algorithm parameters.
+func marshalSEC1PrivateKey(privateKey []byte, withCurve bool) ([]byte, error) {
+	_, publicKey := btcec.PrivKeyFromBytes(privateKey)
+	key := sec1PrivateKey{
+		Version:    1,
+		PrivateKey: privateKey,
+		PublicKey:  asn1.BitString{Bytes: publicKey.SerializeUncompressed(), BitLength: 65 * 8},
+	}
+	if withCurve {
+		key.Curve = oidSecp256k1
+	}
+	return asn1.Marshal(key)
+}
+
+// parsePKCS8PrivateKey returns the 32-byte scalar of a PKCS#8 secp256k1
+// key. Errors wrap invalid for malformed data and unsupported for another
+// key type or curve, so each container reports its own errors.
+func parsePKCS8PrivateKey(der []byte, invalid error, unsupported error) ([]byte, error) {
+	var info pkcs8PrivateKeyInfo
+	if err := unmarshalKeyDER(der, &info, invalid); err != nil {
+		return nil, err
+	}
+	if !info.Algorithm.Algorithm.Equal(oidECPublicKey) {
+		return nil, fmt.Errorf("%w: key algorithm %v", unsupported, info.Algorithm.Algorithm)
+	}
+	var curve asn1.ObjectIdentifier
+	if len(info.Algorithm.Parameters.FullBytes) > 0 {
+		if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &curve); err != nil {
+			return nil, fmt.Errorf("%w: %v", invalid, err)
+		}
+	}
+	return parseSEC1PrivateKey(info.PrivateKey, curve, invalid, unsupported)
+}
+
+// parseSEC1PrivateKey returns the 32-byte scalar of a SEC1 secp256k1 key.
+// curve, when set, comes from the enclosing PKCS#8 algorithm parameters
+// and takes precedence over the key's own.
+func parseSEC1PrivateKey(der []byte, curve asn1.ObjectIdentifier, invalid error, unsupported error) ([]byte, error) {
+	var key sec1PrivateKey
+	if err := unmarshalKeyDER(der, &key, invalid); err != nil {
+		return nil, err
+	}
+	if curve == nil {
+		curve = key.Curve
+	}
+	if !curve.Equal(oidSecp256k1) {
+		return nil, fmt.Errorf("%w: key curve %v is not secp256k1", unsupported, curve)
+	}
+
+	scalar := bytes.TrimLeft(key.PrivateKey, "\x00")
+	if len(scalar) > 32 {
+		return nil, fmt.Errorf("%w: private key too long", invalid)
+	}
+	return append(make([]byte, 32-len(scalar)), scalar...), nil
+}
+
+// unmarshalKeyDER parses a complete DER or BER value into v, wrapping
+// invalid on failure
+func unmarshalKeyDER(data []byte, v interface{}, invalid error) error {
+	der, err := berToDER(data)
+	if err != nil {
+		return fmt.Errorf("%w: malformed ASN.1", invalid)
+	}
+	rest, err := asn1.Unmarshal(der, v)
+	if err != nil {
+		return fmt.Errorf("%w: %v", invalid, err)
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("%w: trailing data", invalid)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// ErrKeyDestroyed indicates a key pair or signer whose private key Destroy
+// has wiped
+var ErrKeyDestroyed = errors.New("private key has been destroyed")
+
+// keySecret holds a private key as raw bytes that destroy overwrites. Go
+// cannot clear strings, so keys held only here do not linger on the heap
+// the way hex keys do.
+type keySecret struct {
+	mu        sync.RWMutex
+	key       [32]byte
+	destroyed bool
+}
+
+// newKeySecret copies a 32-byte secp256k1 private key
+func newKeySecret(privateKey []byte) (*keySecret, error) {
+	if len(privateKey) != 32 {
+		return nil, fmt.Errorf("%w: must be 32 bytes", ErrInvalidPrivateKey)
+	}
+	var scalar btcec.ModNScalar
+	overflow := scalar.SetByteSlice(privateKey)
+	zero := scalar.IsZero()
+	scalar.Zero()
+	if overflow || zero {
+		return nil, fmt.Errorf("%w: out of range for secp256k1", ErrInvalidPrivateKey)
+	}
+
+	secret := &keySecret{}
+	copy(secret.key[:], privateKey)
+	return secret, nil
+}
+
+// newKeySecretFromHex decodes a hex private key into a keySecret
+func newKeySecretFromHex(privateKeyHex string) (*keySecret, error) {
+	var privateKey [32]byte
+	defer Zeroize(privateKey[:])
+	if len(privateKeyHex) != 2*len(privateKey) {
+		return nil, ErrInvalidPrivateKey
+	}
+	// decode in place; hex.Decode would need a []byte copy of the string
+	for i := range privateKey {
+		b, ok := hexByteAt(privateKeyHex, i)
+		if !ok {
+			return nil, fmt.Errorf("%w: not hex", ErrInvalidPrivateKey)
+		}
+		privateKey[i] = b
+	}
+	return newKeySecret(privateKey[:])
+}
+
+// use calls fn with the private key, which is wiped when fn returns and
+// must not be retained
+func (s *keySecret) use(fn func(privateKey *btcec.PrivateKey) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.destroyed {
+		return ErrKeyDestroyed
+	}
+	privateKey, _ := btcec.PrivKeyFromBytes(s.key[:])
+	defer privateKey.Zero()
+	return fn(privateKey)
+}
+
+// publicKey returns the uncompressed public key hex (with 04 prefix)
+func (s *keySecret) publicKey() (string, error) {
+	var publicKey string
+	err := s.use(func(privateKey *btcec.PrivateKey) error {
+		publicKey = hex.EncodeToString(privateKey.PubKey().SerializeUncompressed())
+		return nil
+	})
+	return publicKey, err
+}
+
+// bytes returns a copy of the private key for the caller to Zeroize
+func (s *keySecret) bytes() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.destroyed {
+		return nil, ErrKeyDestroyed
+	}
+	return append([]byte{}, s.key[:]...), nil
+}
+
+func (s *keySecret) destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	Zeroize(s.key[:])
+	s.destroyed = true
+}
+
+// Zeroize overwrites b with zeros, e.g. a private key read from a file once
+// it has been passed to KeyPairFromPrivateKeyBytes
+func Zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// KeyPairFromPrivateKeyBytes derives a key pair from a raw 32-byte private
+// key. The key pair keeps its own copy of the key, which Destroy wipes, so
+// the caller can Zeroize privateKey straight away. No hex copy is made:
+// PrivateKey is left empty, so sign through kp.Signer() and export with the
+// functions that take a *KeyPair (ExportPEM, EncryptKeyPair, EncodeP12)
+// rather than APIs taking a hex key.
+//
+// Example:
+//
+//	raw, err := vault.ReadKey("hot-wallet")
+//	kp, err := constellation.KeyPairFromPrivateKeyBytes(raw)
+//	constellation.Zeroize(raw)
+//	defer kp.Destroy()
+//
+//	signer, err := kp.Signer()
+//	tx, err := constellation.CreateCurrencyTransactionWithSigner(params, signer, lastRef)
+func KeyPairFromPrivateKeyBytes(privateKey []byte) (*KeyPair, error) {
+	secret, err := newKeySecret(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyHex, err := secret.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		PublicKey: publicKeyHex,
+		Address:   GetAddress(publicKeyHex),
+		secret:    secret,
+	}, nil
+}
+
+// PrivateKeyBytes returns a copy of the raw private key; Zeroize it after
+// use. Returns ErrKeyDestroyed after Destroy.
+func (kp *KeyPair) PrivateKeyBytes() ([]byte, error) {
+	secret, err := kp.keySecret()
+	if err != nil {
+		return nil, err
+	}
+	return secret.bytes()
+}
+
+// keyPairSecretMu guards the secret of key pairs built as struct literals,
+// which is created on first use. KeyPair is copied by value too often to
+// carry a mutex of its own.
+var keyPairSecretMu sync.Mutex
+
+// keySecret returns the key pair's secret, creating it from PrivateKey
+// the first time for a key pair that was not built by a constructor
+func (kp *KeyPair) keySecret() (*keySecret, error) {
+	keyPairSecretMu.Lock()
+	defer keyPairSecretMu.Unlock()
+	if kp.secret == nil {
+		secret, err := newKeySecretFromHex(kp.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		kp.secret = secret
+	}
+	return kp.secret, nil
+}
+
+// Signer returns a signer for the key pair. It shares the key pair's
+// private key bytes, so Destroy on either disables both. It is safe for
+// concurrent use.
+func (kp *KeyPair) Signer() (*PrivateKeySigner, error) {
+	secret, err := kp.keySecret()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := secret.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKeySigner{secret: secret, publicKey: publicKey}, nil
+}
+
+// Destroy wipes the key pair's private key bytes and clears PrivateKey.
+// Signers from kp.Signer() stop working. A hex PrivateKey string cannot be
+// overwritten, only dropped; create key pairs with
+// KeyPairFromPrivateKeyBytes to keep the key out of strings entirely.
+func (kp *KeyPair) Destroy() {
+	keyPairSecretMu.Lock()
+	if kp.secret == nil {
+		kp.secret = &keySecret{}
+	}
+	secret := kp.secret
+	kp.PrivateKey = ""
+	keyPairSecretMu.Unlock()
+	secret.destroy()
+}
@@ -0,0 +1,148 @@
+package constellation
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPairFromPrivateKeyBytes(t *testing.T) {
+	reference, err := KeyPairFromPrivateKey(p12FixtureKey)
+	require.NoError(t, err)
+	raw, err := hex.DecodeString(p12FixtureKey)
+	require.NoError(t, err)
+
+	kp, err := KeyPairFromPrivateKeyBytes(raw)
+	require.NoError(t, err)
+	Zeroize(raw)
+	assert.Equal(t, make([]byte, 32), raw)
+
+	t.Run("derives the same key pair without a hex copy", func(t *testing.T) {
+		assert.Equal(t, reference.Address, kp.Address)
+		assert.Equal(t, reference.PublicKey, kp.PublicKey)
+		assert.Empty(t, kp.PrivateKey)
+
+		privateKey, err := kp.PrivateKeyBytes()
+		require.NoError(t, err)
+		assert.Equal(t, p12FixtureKey, hex.EncodeToString(privateKey))
+	})
+
+	t.Run("signs and exports from the bytes", func(t *testing.T) {
+		signer, err := kp.Signer()
+		require.NoError(t, err)
+		assert.Equal(t, kp.Address, signer.Address())
+
+		other, err := GenerateKeyPair()
+		require.NoError(t, err)
+		tx, err := CreateCurrencyTransactionWithSigner(TransferParams{Destination: other.Address, Amount: 1}, signer, GenesisReference)
+		require.NoError(t, err)
+		assert.True(t, VerifyCurrencyTransaction(tx).IsValid)
+
+		exported, err := ExportPEM(kp)
+		require.NoError(t, err)
+		expected, err := ExportPEM(reference)
+		require.NoError(t, err)
+		assert.Equal(t, expected, exported)
+	})
+
+	t.Run("rejects invalid keys", func(t *testing.T) {
+		for _, invalid := range [][]byte{
+			nil,
+			make([]byte, 31),
+			make([]byte, 32),
+			bytes.Repeat([]byte{0xff}, 32), // above the curve order
+		} {
+			_, err := KeyPairFromPrivateKeyBytes(invalid)
+			assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+		}
+	})
+}
+
+func TestKeyPairDestroy(t *testing.T) {
+	t.Run("wipes the key and its signers", func(t *testing.T) {
+		kp, err := GenerateKeyPair()
+		require.NoError(t, err)
+		raw, err := kp.PrivateKeyBytes()
+		require.NoError(t, err)
+		kp, err = KeyPairFromPrivateKeyBytes(raw)
+		require.NoError(t, err)
+		signer, err := kp.Signer()
+		require.NoError(t, err)
+		secret := kp.secret
+
+		kp.Destroy()
+		assert.Equal(t, [32]byte{}, secret.key)
+
+		_, err = kp.PrivateKeyBytes()
+		assert.ErrorIs(t, err, ErrKeyDestroyed)
+		_, err = kp.Signer()
+		assert.ErrorIs(t, err, ErrKeyDestroyed)
+		_, err = signer.SignHash(HashBytes([]byte("x")).Value)
+		assert.ErrorIs(t, err, ErrKeyDestroyed)
+		_, err = ExportPEM(kp)
+		assert.ErrorIs(t, err, ErrKeyDestroyed)
+		_, err = EncryptKeyPairWithParams(kp, "password", LightScryptParams)
+		assert.ErrorIs(t, err, ErrKeyDestroyed)
+
+		// the address stays usable for reads
+		assert.True(t, IsValidDAGAddress(kp.Address))
+	})
+
+	t.Run("drops hex keys", func(t *testing.T) {
+		kp, err := GenerateKeyPair()
+		require.NoError(t, err)
+		kp.Destroy()
+		assert.Empty(t, kp.PrivateKey)
+		_, err = kp.Signer()
+		assert.ErrorIs(t, err, ErrKeyDestroyed)
+	})
+
+	t.Run("shares one secret among concurrent signers", func(t *testing.T) {
+		generated, err := GenerateKeyPair()
+		require.NoError(t, err)
+		// a literal key pair creates its secret on first use
+		kp := &KeyPair{PrivateKey: generated.PrivateKey, PublicKey: generated.PublicKey, Address: generated.Address}
+
+		signers := make([]*PrivateKeySigner, 8)
+		var wg sync.WaitGroup
+		for i := range signers {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				signers[i], _ = kp.Signer()
+			}(i)
+		}
+		wg.Wait()
+
+		kp.Destroy()
+		for _, signer := range signers {
+			require.NotNil(t, signer)
+			_, err := signer.SignDigest(make([]byte, 32))
+			assert.ErrorIs(t, err, ErrKeyDestroyed)
+		}
+	})
+
+	t.Run("signer destroy", func(t *testing.T) {
+		kp, err := GenerateKeyPair()
+		require.NoError(t, err)
+		signer, err := NewPrivateKeySigner(kp.PrivateKey)
+		require.NoError(t, err)
+		signer.Destroy()
+		_, err = signer.SignDigest(make([]byte, 32))
+		assert.ErrorIs(t, err, ErrKeyDestroyed)
+	})
+}
+
+// privateKeyHexOf returns kp's private key as hex, for comparing key pairs
+// imported without a hex copy
+func privateKeyHexOf(t *testing.T, kp *KeyPair) string {
+	t.Helper()
+	privateKey, err := kp.PrivateKeyBytes()
+	require.NoError(t, err)
+	defer Zeroize(privateKey)
+	return hex.EncodeToString(privateKey)
+}
@@ -0,0 +1,66 @@
+package constellation
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrReadNotConsistent indicates a read did not reflect an earlier write
+// within the wait timeout
+var ErrReadNotConsistent = errors.New("read does not yet reflect the submitted transaction")
+
+// ReadConsistencyMode selects how reads after a submission are served
+type ReadConsistencyMode string
+
+const (
+	// ConsistencyEventual reads from any node; a read right after a
+	// submission may not reflect it yet
+	ConsistencyEventual ReadConsistencyMode = "eventual"
+	// ConsistencyPinned sends a source address's last-reference lookups,
+	// and pending lookups of its transactions, to the node that accepted
+	// its latest submission
+	ConsistencyPinned ReadConsistencyMode = "pinned"
+	// ConsistencyWait reads from any node but polls a source address's
+	// last reference until it reflects the latest submission
+	ConsistencyWait ReadConsistencyMode = "wait"
+)
+
+// ReadConsistencyConfig configures read-after-write behaviour
+type ReadConsistencyConfig struct {
+	// Mode selects the strategy (default: ConsistencyEventual)
+	Mode ReadConsistencyMode
+	// PinFor is how long reads stay pinned after a submission (default: 1m)
+	PinFor time.Duration
+	// WaitTimeout bounds ConsistencyWait polling (default: 30s)
+	WaitTimeout time.Duration
+	// PollInterval is the delay between ConsistencyWait polls (default: 500ms)
+	PollInterval time.Duration
+}
+
+// WaitForLastReference polls l1 until the last reference of address is at
+// least expected.Ordinal, e.g. the reference of a transaction just submitted
+//
+// Returns the reference once it catches up, or the last one seen together
+// with ErrReadNotConsistent when timeout passes first.
+//
+// Example:
+//
+//	result, _ := client.PostTransaction(tx)
+//	ref, err := WaitForLastReference(client, tx.Value.Source, *GetTransactionReference(tx, lastRef.Ordinal+1),
+//	    30*time.Second, 500*time.Millisecond)
+func WaitForLastReference(l1 CurrencyL1API, address string, expected TransactionReference, timeout time.Duration, interval time.Duration) (*TransactionReference, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		ref, err := l1.GetLastReference(address)
+		if err != nil {
+			return nil, err
+		}
+		if ref.Ordinal >= expected.Ordinal {
+			return ref, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return ref, ErrReadNotConsistent
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,45 @@
+package constellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// laggingL1 reports a stale last reference for the first lag lookups
+type laggingL1 struct {
+	CurrencyL1API
+	lag     int
+	lookups int
+	ref     TransactionReference
+}
+
+func (l *laggingL1) GetLastReference(address string) (*TransactionReference, error) {
+	l.lookups++
+	if l.lookups <= l.lag {
+		return &TransactionReference{Hash: "stale", Ordinal: l.ref.Ordinal - 1}, nil
+	}
+	ref := l.ref
+	return &ref, nil
+}
+
+func TestWaitForLastReference(t *testing.T) {
+	expected := TransactionReference{Hash: "new", Ordinal: 5}
+
+	t.Run("waits until the reference catches up", func(t *testing.T) {
+		l1 := &laggingL1{lag: 3, ref: expected}
+		ref, err := WaitForLastReference(l1, "DAG0addr", expected, time.Second, time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, expected, *ref)
+		assert.Equal(t, 4, l1.lookups)
+	})
+
+	t.Run("returns the stale reference after the timeout", func(t *testing.T) {
+		l1 := &laggingL1{lag: 1000, ref: expected}
+		ref, err := WaitForLastReference(l1, "DAG0addr", expected, 20*time.Millisecond, 5*time.Millisecond)
+		assert.ErrorIs(t, err, ErrReadNotConsistent)
+		assert.Equal(t, "stale", ref.Hash)
+	})
+}
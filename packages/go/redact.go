@@ -0,0 +1,59 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// redacted replaces secret values in output
+const redacted = "[REDACTED]"
+
+// Redact hides a secret such as a private key. Nothing of the value is
+// kept; only whether it was set.
+func Redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// RedactAddress shortens a DAG address to its first and last four
+// characters, e.g. "DAG8…f3wD", enough to tell addresses apart in logs
+func RedactAddress(address string) string {
+	if len(address) <= 10 {
+		return address
+	}
+	return address[:4] + "…" + address[len(address)-4:]
+}
+
+// RedactSignature shortens a signature hex to a prefix and its length,
+// e.g. "30450221…(142 chars)"
+func RedactSignature(signatureHex string) string {
+	if len(signatureHex) <= 8 {
+		return signatureHex
+	}
+	return signatureHex[:8] + "…(" + strconv.Itoa(len(signatureHex)) + " chars)"
+}
+
+// String formats the key pair without its private key, so logging a
+// KeyPair never leaks it
+func (k KeyPair) String() string {
+	return fmt.Sprintf("KeyPair{Address: %s, PublicKey: %s, PrivateKey: %s}", k.Address, k.PublicKey, Redact(k.PrivateKey))
+}
+
+// GoString is String for the %#v verb
+func (k KeyPair) GoString() string {
+	return k.String()
+}
+
+// invalidPrivateKeyHex reports a private key that failed hex decoding
+// without echoing any of its characters (hex errors quote the bad byte)
+func invalidPrivateKeyHex(err error) error {
+	reason := "not hex"
+	if errors.Is(err, hex.ErrLength) {
+		reason = "odd length"
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidPrivateKey, reason)
+}
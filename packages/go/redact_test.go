@@ -0,0 +1,63 @@
+package constellation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactionHelpers(t *testing.T) {
+	assert.Equal(t, "[REDACTED]", Redact(strings.Repeat("ab", 32)))
+	assert.Equal(t, "", Redact(""))
+	assert.Equal(t, "DAG6…Au6K", RedactAddress("DAG6eH4ryz7jyQAJpQi2tQ3jueiZP3f3wD5iAu6K"))
+	assert.Equal(t, "short", RedactAddress("short"))
+	assert.Equal(t, "30440220…(140 chars)", RedactSignature("30440220"+strings.Repeat("0", 132)))
+}
+
+func TestNoSecretsInOutput(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	t.Run("formatting a key pair hides the private key", func(t *testing.T) {
+		wrapper := struct{ Key *KeyPair }{kp}
+		for _, out := range []string{
+			fmt.Sprint(kp), fmt.Sprintf("%v", *kp), fmt.Sprintf("%+v", kp),
+			fmt.Sprintf("%#v", *kp), fmt.Sprintf("%+v", wrapper), fmt.Sprintf("%v", []KeyPair{*kp}),
+		} {
+			assert.NotContains(t, out, kp.PrivateKey)
+			assert.Contains(t, out, kp.Address)
+		}
+	})
+
+	t.Run("errors for malformed keys do not echo them", func(t *testing.T) {
+		// a key with a single bad character: hex errors would otherwise quote it
+		badKey := kp.PrivateKey[:63] + "g"
+		oddKey := kp.PrivateKey[:63]
+
+		for _, key := range []string{badKey, oddKey} {
+			_, err := SignHash(strings.Repeat("ab", 32), key)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+			assert.NotContains(t, err.Error(), key[:16])
+			assert.NotContains(t, err.Error(), "'g'")
+
+			_, err = GetPublicKeyHex(key, false)
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), "'g'")
+		}
+	})
+
+	t.Run("verification errors do not echo the signature", func(t *testing.T) {
+		hash := strings.Repeat("ab", 32)
+		signature, err := SignHash(hash, kp.PrivateKey)
+		require.NoError(t, err)
+
+		corrupted := "31" + signature[2:]
+		_, err = VerifyHash(hash, corrupted, kp.PublicKey)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), corrupted[8:40])
+	})
+}
@@ -0,0 +1,110 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrRefundNotIncoming indicates a deposit that was not sent to the refunding address
+	ErrRefundNotIncoming = errors.New("transaction was not received by the refunding address")
+	// ErrRefundNotConfirmed indicates a deposit without enough confirmations to refund
+	ErrRefundNotConfirmed = errors.New("transaction does not have enough confirmations to refund")
+	// ErrRefundExceedsCap indicates a refund above the policy's amount cap
+	ErrRefundExceedsCap = errors.New("refund exceeds the amount cap")
+	// ErrRefundTransactionNotFound indicates a deposit the explorer has not indexed
+	ErrRefundTransactionNotFound = errors.New("transaction to refund was not found")
+)
+
+// RefundPolicy holds the safeguards applied when refunding a deposit
+type RefundPolicy struct {
+	// MinConfirmations is how many global snapshots must follow the one
+	// that confirmed the deposit
+	MinConfirmations int64
+	// MaxAmount caps the refunded amount in smallest units (1e-8); larger
+	// deposits are rejected for manual review. Zero means no cap.
+	MaxAmount int64
+	// Fee is the refund transaction's fee in smallest units (1e-8)
+	Fee int64
+	// DeductFee takes the fee out of the refunded amount instead of
+	// charging it on top
+	DeductFee bool
+}
+
+// BuildRefund builds an unsigned transaction returning a confirmed deposit
+// to the address it came from. The refund is sent from builder.Source(),
+// which must be the deposit's destination, and is chained from parent, the
+// refunding address's last reference. latestOrdinal is the most recent
+// global snapshot, used to count confirmations.
+//
+// Example:
+//
+//	latest, _ := explorer.GetLatestSnapshot()
+//	lastRef, _ := l1.GetLastReference(depositAddress)
+//	policy := RefundPolicy{MinConfirmations: 10, MaxAmount: TokenToUnits(5000)}
+//	refund, err := BuildRefund(deposit, latest.Ordinal, NewTransactionBuilder(depositAddress), *lastRef, policy)
+func BuildRefund(deposit ExplorerTransaction, latestOrdinal int64, builder Builder, parent TransactionReference, policy RefundPolicy) (*CurrencyTransaction, error) {
+	if deposit.Destination != builder.Source() {
+		return nil, fmt.Errorf("%w: sent to %s", ErrRefundNotIncoming, RedactAddress(deposit.Destination))
+	}
+	if deposit.SnapshotOrdinal <= 0 {
+		return nil, fmt.Errorf("%w: not yet in a snapshot", ErrRefundNotConfirmed)
+	}
+	if confirmations := latestOrdinal - deposit.SnapshotOrdinal; confirmations < policy.MinConfirmations {
+		return nil, fmt.Errorf("%w: %d of %d", ErrRefundNotConfirmed, confirmations, policy.MinConfirmations)
+	}
+
+	amount := deposit.Amount
+	if policy.DeductFee {
+		amount -= policy.Fee
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: deposit of %s does not cover the fee",
+			ErrFeeExceedsAmount, FormatTokenAmount(deposit.Amount))
+	}
+	if policy.MaxAmount > 0 && amount > policy.MaxAmount {
+		return nil, fmt.Errorf("%w: %s above %s", ErrRefundExceedsCap,
+			FormatTokenAmount(amount), FormatTokenAmount(policy.MaxAmount))
+	}
+	return builder.Build(deposit.Source, amount, policy.Fee, parent)
+}
+
+// PrepareRefund looks a deposit up by hash and returns a signed refund
+// ready to submit. The refund is sent from the signer's address and chained
+// from its last reference on l1. The explorer must implement
+// ExplorerTransactionLookup.
+//
+// PrepareRefund does not track which deposits were already refunded;
+// callers must record the deposit hash before submitting.
+func PrepareRefund(explorer ExplorerAPI, l1 CurrencyL1API, signer Signer, depositHash string, policy RefundPolicy) (*CurrencyTransaction, error) {
+	lookup, ok := explorer.(ExplorerTransactionLookup)
+	if !ok {
+		return nil, ErrTransactionLookupUnsupported
+	}
+	deposit, err := lookup.GetTransaction(depositHash)
+	if err != nil {
+		return nil, err
+	}
+	if deposit == nil {
+		return nil, ErrRefundTransactionNotFound
+	}
+	latest, err := explorer.GetLatestSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	var latestOrdinal int64
+	if latest != nil {
+		latestOrdinal = latest.Ordinal
+	}
+
+	builder := NewTransactionBuilder(GetAddress(signer.PublicKey()))
+	lastRef, err := l1.GetLastReference(builder.Source())
+	if err != nil {
+		return nil, err
+	}
+	refund, err := BuildRefund(*deposit, latestOrdinal, builder, *lastRef, policy)
+	if err != nil {
+		return nil, err
+	}
+	return SignTransaction(refund, signer)
+}
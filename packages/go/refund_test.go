@@ -0,0 +1,77 @@
+package constellation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefund(t *testing.T) {
+	exchange, err := GenerateKeyPair()
+	require.NoError(t, err)
+	customer, err := GenerateKeyPair()
+	require.NoError(t, err)
+	signer, err := NewPrivateKeySigner(exchange.PrivateKey)
+	require.NoError(t, err)
+
+	deposit := ExplorerTransaction{
+		Hash: "deposit1", Source: customer.Address, Destination: exchange.Address,
+		Amount: TokenToUnits(100), SnapshotOrdinal: 10,
+	}
+	explorer := newFakeExplorer()
+	explorer.transactions[exchange.Address] = []ExplorerTransaction{deposit}
+	ledger := NewSimulatedLedger()
+	builder := NewTransactionBuilder(exchange.Address)
+	parent := TransactionReference{Hash: "parent", Ordinal: 4}
+
+	t.Run("returns the deposit to its source", func(t *testing.T) {
+		refund, err := BuildRefund(deposit, 12, builder, parent, RefundPolicy{MinConfirmations: 2, Fee: 1})
+		require.NoError(t, err)
+		assert.Equal(t, customer.Address, refund.Value.Destination)
+		assert.Equal(t, exchange.Address, refund.Value.Source)
+		assert.Equal(t, TokenToUnits(100), refund.Value.Amount)
+		assert.Equal(t, int64(1), refund.Value.Fee)
+		assert.Equal(t, parent, refund.Value.Parent)
+
+		refund, err = BuildRefund(deposit, 12, builder, parent, RefundPolicy{Fee: 1, DeductFee: true})
+		require.NoError(t, err)
+		assert.Equal(t, TokenToUnits(100)-1, refund.Value.Amount)
+	})
+
+	t.Run("applies the safeguards", func(t *testing.T) {
+		_, err := BuildRefund(deposit, 11, builder, parent, RefundPolicy{MinConfirmations: 2})
+		assert.ErrorIs(t, err, ErrRefundNotConfirmed)
+
+		pending := deposit
+		pending.SnapshotOrdinal = 0
+		_, err = BuildRefund(pending, 12, builder, parent, RefundPolicy{})
+		assert.ErrorIs(t, err, ErrRefundNotConfirmed)
+
+		_, err = BuildRefund(deposit, 12, builder, parent, RefundPolicy{MaxAmount: TokenToUnits(50)})
+		assert.ErrorIs(t, err, ErrRefundExceedsCap)
+
+		_, err = BuildRefund(deposit, 12, NewTransactionBuilder(customer.Address), parent, RefundPolicy{})
+		assert.ErrorIs(t, err, ErrRefundNotIncoming)
+
+		_, err = BuildRefund(deposit, 12, builder, parent, RefundPolicy{Fee: TokenToUnits(100), DeductFee: true})
+		assert.ErrorIs(t, err, ErrFeeExceedsAmount)
+	})
+
+	t.Run("prepares a signed refund from live lookups", func(t *testing.T) {
+		refund, err := PrepareRefund(explorer, ledger, signer, "deposit1", RefundPolicy{MinConfirmations: 2})
+		require.NoError(t, err)
+		assert.True(t, VerifyCurrencyTransaction(refund).IsValid)
+		assert.Equal(t, customer.Address, refund.Value.Destination)
+
+		ledger.Credit(exchange.Address, TokenToUnits(100))
+		_, err = ledger.PostTransaction(refund)
+		assert.NoError(t, err)
+
+		_, err = PrepareRefund(explorer, ledger, signer, "unknown", RefundPolicy{})
+		assert.ErrorIs(t, err, ErrRefundTransactionNotFound)
+
+		_, err = PrepareRefund(struct{ ExplorerAPI }{explorer}, ledger, signer, "deposit1", RefundPolicy{})
+		assert.ErrorIs(t, err, ErrTransactionLookupUnsupported)
+	})
+}
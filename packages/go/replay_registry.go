@@ -0,0 +1,326 @@
+package constellation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrReplayDetected indicates a withdrawal or deposit that a ReplayRegistry
+// has already seen processed
+var ErrReplayDetected = errors.New("already processed")
+
+// ReplayRegistry remembers which keys, such as transaction hashes or
+// withdrawal IDs, have been processed, so each is processed exactly once
+// across restarts and replicas sharing the registry
+type ReplayRegistry interface {
+	// Claim records key as processed. It returns true if this call recorded
+	// it and false if it had already been claimed.
+	Claim(key string) (bool, error)
+	// Release forgets key so it can be claimed again, e.g. after processing
+	// it failed
+	Release(key string) error
+}
+
+// WithdrawalReplayKey returns the key a WithdrawalQueue claims for a
+// request ID
+func WithdrawalReplayKey(id string) string {
+	return "withdrawal:" + id
+}
+
+// DepositReplayKey returns the key ReplayFilter claims for an event of
+// eventType about the transaction hash
+func DepositReplayKey(eventType EventType, hash string) string {
+	return string(eventType) + ":" + hash
+}
+
+// MemoryReplayRegistry keeps claims in memory. Useful for tests and
+// single-process services that replay from a checkpoint on restart.
+type MemoryReplayRegistry struct {
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+// NewMemoryReplayRegistry creates an empty MemoryReplayRegistry
+func NewMemoryReplayRegistry() *MemoryReplayRegistry {
+	return &MemoryReplayRegistry{keys: map[string]time.Time{}}
+}
+
+// Claim records key, returning false if it was already claimed
+func (r *MemoryReplayRegistry) Claim(key string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[key]; ok {
+		return false, nil
+	}
+	r.keys[key] = time.Now()
+	return true, nil
+}
+
+// Release forgets key
+func (r *MemoryReplayRegistry) Release(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, key)
+	return nil
+}
+
+// FileReplayRegistry keeps claims in a JSON file, mapping each key to when
+// it was claimed. Writes replace the file atomically, like
+// FileCheckpointStore. It survives restarts but is not safe to share
+// between processes; use SQLReplayRegistry for replicas.
+//
+// The file is read once and cached, but every Claim and Release rewrites
+// it whole, so they take time in proportion to the claims kept. Call Prune
+// periodically to drop claims too old to be replayed; for large volumes use
+// SQLReplayRegistry.
+type FileReplayRegistry struct {
+	path string
+	mu   sync.Mutex
+	// keys caches the file's claims once read
+	keys map[string]time.Time
+}
+
+// NewFileReplayRegistry creates a registry backed by the file at path. The
+// file is created on the first Claim.
+func NewFileReplayRegistry(path string) *FileReplayRegistry {
+	return &FileReplayRegistry{path: path}
+}
+
+// Claim records key, returning false if it was already claimed
+func (r *FileReplayRegistry) Claim(key string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys, err := r.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := keys[key]; ok {
+		return false, nil
+	}
+	keys[key] = time.Now().UTC()
+	if err := r.write(keys); err != nil {
+		delete(keys, key)
+		return false, err
+	}
+	return true, nil
+}
+
+// Release forgets key
+func (r *FileReplayRegistry) Release(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys, err := r.load()
+	if err != nil {
+		return err
+	}
+	claimedAt, ok := keys[key]
+	if !ok {
+		return nil
+	}
+	delete(keys, key)
+	if err := r.write(keys); err != nil {
+		keys[key] = claimedAt
+		return err
+	}
+	return nil
+}
+
+// Prune forgets claims made before cutoff and returns how many it dropped.
+// A pruned key can be claimed again, so cutoff must be older than anything
+// that may still be replayed, e.g. the deposits a tracker could rescan from
+// its checkpoint.
+func (r *FileReplayRegistry) Prune(cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys, err := r.load()
+	if err != nil {
+		return 0, err
+	}
+	kept := make(map[string]time.Time, len(keys))
+	for key, claimedAt := range keys {
+		if !claimedAt.Before(cutoff) {
+			kept[key] = claimedAt
+		}
+	}
+	pruned := len(keys) - len(kept)
+	if pruned == 0 {
+		return 0, nil
+	}
+	if err := r.write(kept); err != nil {
+		return 0, err
+	}
+	r.keys = kept
+	return pruned, nil
+}
+
+// load returns the cached claims, reading the file the first time
+func (r *FileReplayRegistry) load() (map[string]time.Time, error) {
+	if r.keys != nil {
+		return r.keys, nil
+	}
+	keys := map[string]time.Time{}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read replay registry: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("failed to parse replay registry: %w", err)
+		}
+	}
+	if keys == nil {
+		keys = map[string]time.Time{}
+	}
+	r.keys = keys
+	return keys, nil
+}
+
+func (r *FileReplayRegistry) write(keys map[string]time.Time) error {
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write replay registry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write replay registry: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write replay registry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write replay registry: %w", err)
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// SQLReplayRegistry keeps claims in a database table via database/sql, so
+// replicas sharing the database never process the same key twice. The
+// table's primary key arbitrates: of two concurrent claims, only one insert
+// succeeds.
+//
+// Table layout:
+//
+//	CREATE TABLE replay_keys (
+//	    replay_key VARCHAR(255) PRIMARY KEY,
+//	    claimed_at TIMESTAMP    NOT NULL
+//	)
+type SQLReplayRegistry struct {
+	db    *sql.DB
+	table string
+	// numbered selects $1-style placeholders (PostgreSQL) instead of ?
+	numbered bool
+}
+
+// NewSQLReplayRegistry creates a registry on table. Set numberedPlaceholders
+// for drivers that use $1, $2 (PostgreSQL); leave it false for ? (MySQL,
+// SQLite).
+func NewSQLReplayRegistry(db *sql.DB, table string, numberedPlaceholders bool) (*SQLReplayRegistry, error) {
+	if !sqlIdentifier.MatchString(table) {
+		return nil, ErrInvalidTableName
+	}
+	return &SQLReplayRegistry{db: db, table: table, numbered: numberedPlaceholders}, nil
+}
+
+// CreateTable creates the registry table if it does not exist
+func (r *SQLReplayRegistry) CreateTable() error {
+	_, err := r.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		replay_key VARCHAR(255) PRIMARY KEY,
+		claimed_at TIMESTAMP NOT NULL
+	)`, r.table))
+	return err
+}
+
+// Claim inserts key, returning false if a row for it already exists
+func (r *SQLReplayRegistry) Claim(key string) (bool, error) {
+	insert := fmt.Sprintf("INSERT INTO %s (replay_key, claimed_at) VALUES (%s, %s)", r.table, r.bind(1), r.bind(2))
+	_, err := r.db.Exec(insert, key, time.Now().UTC())
+	if err == nil {
+		return true, nil
+	}
+
+	// drivers report unique violations differently; a row that exists after
+	// a failed insert means another claim won
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE replay_key = %s", r.table, r.bind(1))
+	var exists int
+	if lookupErr := r.db.QueryRow(query, key).Scan(&exists); lookupErr == nil {
+		return false, nil
+	}
+	return false, err
+}
+
+// Release deletes key
+func (r *SQLReplayRegistry) Release(key string) error {
+	_, err := r.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE replay_key = %s", r.table, r.bind(1)), key)
+	return err
+}
+
+func (r *SQLReplayRegistry) bind(n int) string {
+	if r.numbered {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// ReplayFilter returns an EventHandler that passes each deposit to next at
+// most once, claiming it in registry first. DepositFinalized events are
+// claimed by hash, as are DepositDetected events once they carry a snapshot
+// ordinal; a detection without one passes, since it is detected again when
+// included. Other events always pass. Registry errors drop the event and go
+// to onError, if set.
+//
+// The claim is taken before next runs, so a crash in between skips the
+// deposit instead of repeating it. Handlers that must not lose deposits
+// should record them durably and Release the key on failure.
+//
+// Example:
+//
+//	registry, _ := NewSQLReplayRegistry(db, "replay_keys", true)
+//	tracker.Subscribe(ReplayFilter(registry, func(e Event) {
+//	    credit(e.(DepositFinalized))
+//	}, logError))
+func ReplayFilter(registry ReplayRegistry, next EventHandler, onError func(err error)) EventHandler {
+	return func(event Event) {
+		var key string
+		switch e := event.(type) {
+		case DepositDetected:
+			if e.Ordinal > 0 {
+				key = DepositReplayKey(e.Type(), e.Hash)
+			}
+		case DepositFinalized:
+			key = DepositReplayKey(e.Type(), e.Hash)
+		}
+		if key == "" {
+			next(event)
+			return
+		}
+
+		first, err := registry.Claim(key)
+		if err != nil {
+			if onError != nil {
+				onError(fmt.Errorf("claiming %s: %w", key, err))
+			}
+			return
+		}
+		if first {
+			next(event)
+		}
+	}
+}
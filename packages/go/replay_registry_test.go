@@ -0,0 +1,194 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReplayRegistry fails every claim
+type failingReplayRegistry struct{}
+
+func (failingReplayRegistry) Claim(string) (bool, error) { return false, errors.New("db down") }
+func (failingReplayRegistry) Release(string) error       { return nil }
+
+func TestReplayRegistries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay.json")
+	for name, registry := range map[string]ReplayRegistry{
+		"memory": NewMemoryReplayRegistry(),
+		"file":   NewFileReplayRegistry(path),
+	} {
+		t.Run(name, func(t *testing.T) {
+			first, err := registry.Claim("withdrawal:w-1")
+			require.NoError(t, err)
+			assert.True(t, first)
+			first, err = registry.Claim("withdrawal:w-1")
+			require.NoError(t, err)
+			assert.False(t, first)
+
+			require.NoError(t, registry.Release("withdrawal:w-1"))
+			require.NoError(t, registry.Release("withdrawal:unknown"))
+			first, err = registry.Claim("withdrawal:w-1")
+			require.NoError(t, err)
+			assert.True(t, first)
+		})
+	}
+
+	t.Run("file claims survive reopening", func(t *testing.T) {
+		first, err := NewFileReplayRegistry(path).Claim("withdrawal:w-1")
+		require.NoError(t, err)
+		assert.False(t, first)
+	})
+
+	t.Run("file registry prunes old claims", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "replay.json")
+		registry := NewFileReplayRegistry(path)
+		first, err := registry.Claim("withdrawal:old")
+		require.NoError(t, err)
+		require.True(t, first)
+		cutoff := time.Now().Add(time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+		first, err = registry.Claim("withdrawal:new")
+		require.NoError(t, err)
+		require.True(t, first)
+
+		pruned, err := registry.Prune(cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, 1, pruned)
+
+		reopened := NewFileReplayRegistry(path)
+		first, err = reopened.Claim("withdrawal:new")
+		require.NoError(t, err)
+		assert.False(t, first, "recent claims are kept")
+		first, err = reopened.Claim("withdrawal:old")
+		require.NoError(t, err)
+		assert.True(t, first, "pruned claims can be claimed again")
+	})
+
+	t.Run("SQL registry rejects bad table names", func(t *testing.T) {
+		_, err := NewSQLReplayRegistry(nil, "replay; DROP TABLE x", false)
+		assert.ErrorIs(t, err, ErrInvalidTableName)
+		registry, err := NewSQLReplayRegistry(nil, "replay_keys", true)
+		require.NoError(t, err)
+		assert.Equal(t, "$2", registry.bind(2))
+	})
+}
+
+func TestWithdrawalQueueReplayProtection(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, err := GenerateKeyPair()
+	require.NoError(t, err)
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+	registry := NewMemoryReplayRegistry()
+
+	// run processes requests on a fresh queue, as after a restart
+	run := func(requests ...WithdrawalRequest) []WithdrawalReceipt {
+		var receipts []WithdrawalReceipt
+		queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+			L1:         ledger,
+			PrivateKey: hot.PrivateKey,
+			Replay:     registry,
+			Policies:   []WithdrawalPolicy{MaxAmountPolicy(50)},
+			OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+		})
+		require.NoError(t, err)
+		for _, request := range requests {
+			require.NoError(t, queue.Enqueue(request))
+		}
+		require.NoError(t, queue.Close())
+		return receipts
+	}
+
+	receipts := run(
+		WithdrawalRequest{ID: "w-1", Destination: destination.Address, Amount: 10},
+		WithdrawalRequest{ID: "w-2", Destination: destination.Address, Amount: 60},
+	)
+	assert.Equal(t, WithdrawalSubmitted, receipts[0].Status)
+	assert.Equal(t, WithdrawalRejected, receipts[1].Status)
+
+	receipts = run(
+		WithdrawalRequest{ID: "w-1", Destination: destination.Address, Amount: 10},
+		WithdrawalRequest{ID: "w-3", Destination: destination.Address, Amount: 5},
+		WithdrawalRequest{Destination: destination.Address, Amount: 1},
+		WithdrawalRequest{Destination: destination.Address, Amount: 1},
+	)
+	require.Len(t, receipts, 4)
+	assert.Equal(t, WithdrawalRejected, receipts[0].Status)
+	assert.ErrorIs(t, receipts[0].Err, ErrReplayDetected)
+	for _, receipt := range receipts[1:] {
+		assert.Equal(t, WithdrawalSubmitted, receipt.Status)
+	}
+
+	// a policy rejection never claimed the ID
+	first, err := registry.Claim(WithdrawalReplayKey("w-2"))
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	t.Run("keeps the claim when the submission outcome is unknown", func(t *testing.T) {
+		for status, expected := range map[int]WithdrawalStatus{400: WithdrawalFailed, 503: WithdrawalUnknown} {
+			id := fmt.Sprintf("w-%d", status)
+			var receipts []WithdrawalReceipt
+			queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+				L1:         failingL1{SimulatedLedger: ledger, err: NewNetworkError("HTTP error", status, "")},
+				PrivateKey: hot.PrivateKey,
+				Replay:     registry,
+				OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+			})
+			require.NoError(t, err)
+			require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: id, Destination: destination.Address, Amount: 1}))
+			require.NoError(t, queue.Close())
+			assert.Equal(t, expected, receipts[0].Status)
+
+			released, err := registry.Claim(WithdrawalReplayKey(id))
+			require.NoError(t, err)
+			assert.Equal(t, status == 400, released, "only a definitive rejection releases the claim")
+		}
+	})
+}
+
+// failingL1 fails every submission with err
+type failingL1 struct {
+	*SimulatedLedger
+	err error
+}
+
+func (l failingL1) PostTransaction(*CurrencyTransaction) (*PostTransactionResponse, error) {
+	return nil, l.err
+}
+
+func TestReplayFilter(t *testing.T) {
+	registry := NewMemoryReplayRegistry()
+	var handled []Event
+	var failures []error
+	filter := ReplayFilter(registry, func(e Event) { handled = append(handled, e) }, nil)
+
+	filter(DepositDetected{Hash: "a"})
+	filter(DepositDetected{Hash: "a", Ordinal: 5})
+	filter(DepositDetected{Hash: "a", Ordinal: 5})
+	filter(DepositFinalized{Hash: "a", Ordinal: 5})
+	filter(DepositFinalized{Hash: "a", Ordinal: 5})
+	filter(SnapshotAdvanced{Ordinal: 6})
+	filter(SnapshotAdvanced{Ordinal: 6})
+	assert.Equal(t, []Event{
+		DepositDetected{Hash: "a"},
+		DepositDetected{Hash: "a", Ordinal: 5},
+		DepositFinalized{Hash: "a", Ordinal: 5},
+		SnapshotAdvanced{Ordinal: 6},
+		SnapshotAdvanced{Ordinal: 6},
+	}, handled)
+
+	failing := ReplayFilter(failingReplayRegistry{}, func(e Event) { handled = append(handled, e) },
+		func(err error) { failures = append(failures, err) })
+	failing(DepositFinalized{Hash: "b"})
+	assert.Len(t, handled, 5)
+	require.Len(t, failures, 1)
+	assert.ErrorContains(t, failures[0], "db down")
+}
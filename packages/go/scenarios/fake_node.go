@@ -0,0 +1,20 @@
+package scenarios
+
+import constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+
+// Errors reported by FakeNode
+var (
+	ErrParentMismatch      = constellation.ErrParentMismatch
+	ErrInsufficientBalance = constellation.ErrInsufficientBalance
+)
+
+// FakeNode is an in-memory Currency L1 and balance source for running
+// scenarios without a network. It is the SDK's SimulatedLedger: a pending
+// transaction is reported as Waiting on the first poll and confirmed on that
+// same poll, so a Confirm step observes one snapshot round-trip.
+type FakeNode = constellation.SimulatedLedger
+
+// NewFakeNode creates an empty FakeNode
+func NewFakeNode() *FakeNode {
+	return constellation.NewSimulatedLedger()
+}
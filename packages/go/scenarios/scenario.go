@@ -0,0 +1,162 @@
+// Package scenarios runs scripted end-to-end flows against a Currency L1 node
+// (real or fake) and reports the outcome of every step.
+//
+// It is used to certify the SDK against each Tessellation release: the same
+// scenarios run against FakeNode in CI and against IntegrationNet/TestNet
+// before a release, with results written as JUnit XML.
+package scenarios
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+const (
+	defaultConfirmTimeout = 2 * time.Minute
+	defaultPollInterval   = 5 * time.Second
+)
+
+// ErrUnknownAccount indicates a step referenced an account missing from the Env
+var ErrUnknownAccount = errors.New("unknown scenario account")
+
+// Env is the network a scenario runs against
+type Env struct {
+	// L1 is the Currency L1 node (a *constellation.CurrencyL1Client or a FakeNode)
+	L1 constellation.CurrencyL1API
+	// Balances looks up account balances. Without an Explorer, Confirm steps
+	// check that the recipient's balance rose by the transferred amount.
+	Balances constellation.BalanceSource
+	// Explorer, when set, is where Confirm steps look for the confirmed
+	// transaction, e.g. a *network.ExplorerClient
+	Explorer constellation.ExplorerTransactionLookup
+	// Fund tops up an address, e.g. network.Localnet.Fund, a faucet, or FakeNode.Fund
+	Fund func(address string, amount float64) error
+	// Accounts are the named key pairs steps refer to
+	Accounts map[string]*constellation.KeyPair
+	// ConfirmTimeout bounds how long Confirm steps wait (default: 2m)
+	ConfirmTimeout time.Duration
+	// PollInterval is how often Confirm steps poll (default: 5s)
+	PollInterval time.Duration
+}
+
+// account returns the named key pair
+func (e *Env) account(name string) (*constellation.KeyPair, error) {
+	kp, ok := e.Accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAccount, name)
+	}
+	return kp, nil
+}
+
+// Context carries state between the steps of one scenario run
+type Context struct {
+	// Env is the environment the scenario runs against
+	Env *Env
+	// LastTransaction is the most recently submitted transaction
+	LastTransaction *constellation.CurrencyTransaction
+	// LastHash is the hash reported for LastTransaction
+	LastHash string
+
+	// recipientBalance is the recipient's confirmed balance before
+	// LastTransaction was submitted, or -1 if it was not read
+	recipientBalance int64
+}
+
+// Step is a single named action in a scenario
+type Step struct {
+	Name string
+	Run  func(ctx *Context) error
+}
+
+// Scenario is an ordered list of steps. A failing step skips the rest.
+type Scenario struct {
+	Name  string
+	Steps []Step
+}
+
+// StepResult is the outcome of one step
+type StepResult struct {
+	Name     string
+	Duration time.Duration
+	// Err is the step failure, nil on success
+	Err error
+	// Skipped is true when an earlier step in the scenario failed
+	Skipped bool
+}
+
+// ScenarioResult is the outcome of one scenario
+type ScenarioResult struct {
+	Name     string
+	Duration time.Duration
+	Steps    []StepResult
+}
+
+// Passed reports whether every step in the scenario succeeded
+func (r *ScenarioResult) Passed() bool {
+	for _, step := range r.Steps {
+		if step.Err != nil || step.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// Report collects the results of a run
+type Report struct {
+	Scenarios []ScenarioResult
+}
+
+// Passed reports whether every scenario passed
+func (r *Report) Passed() bool {
+	for i := range r.Scenarios {
+		if !r.Scenarios[i].Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Run executes the scenarios in order against env
+func Run(env *Env, scenarios ...Scenario) *Report {
+	if env.ConfirmTimeout <= 0 {
+		env.ConfirmTimeout = defaultConfirmTimeout
+	}
+	if env.PollInterval <= 0 {
+		env.PollInterval = defaultPollInterval
+	}
+
+	report := &Report{}
+	for _, scenario := range scenarios {
+		report.Scenarios = append(report.Scenarios, runScenario(env, scenario))
+	}
+	return report
+}
+
+func runScenario(env *Env, scenario Scenario) ScenarioResult {
+	result := ScenarioResult{Name: scenario.Name}
+	ctx := &Context{Env: env}
+	start := time.Now()
+	failed := false
+
+	for _, step := range scenario.Steps {
+		if failed {
+			result.Steps = append(result.Steps, StepResult{Name: step.Name, Skipped: true})
+			continue
+		}
+
+		stepStart := time.Now()
+		err := step.Run(ctx)
+		result.Steps = append(result.Steps, StepResult{
+			Name:     step.Name,
+			Duration: time.Since(stepStart),
+			Err:      err,
+		})
+		failed = err != nil
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
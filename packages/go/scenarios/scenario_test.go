@@ -0,0 +1,140 @@
+package scenarios
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeEnv(t *testing.T) (*Env, *FakeNode) {
+	t.Helper()
+	alice, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+	bob, err := constellation.GenerateKeyPair()
+	require.NoError(t, err)
+
+	node := NewFakeNode()
+	return &Env{
+		L1:             node,
+		Balances:       node,
+		Fund:           node.Fund,
+		Accounts:       map[string]*constellation.KeyPair{"alice": alice, "bob": bob},
+		ConfirmTimeout: time.Second,
+		PollInterval:   time.Millisecond,
+	}, node
+}
+
+func TestStandardFlowAgainstFakeNode(t *testing.T) {
+	env, node := newFakeEnv(t)
+
+	report := Run(env, StandardFlow())
+	for _, step := range report.Scenarios[0].Steps {
+		assert.NoError(t, step.Err, step.Name)
+	}
+	require.True(t, report.Passed())
+
+	bob, err := node.GetBalance(env.Accounts["bob"].Address)
+	require.NoError(t, err)
+	assert.Equal(t, constellation.TokenToUnits(2), bob.Balance)
+}
+
+func TestFailingStepSkipsRemainder(t *testing.T) {
+	env, _ := newFakeEnv(t)
+
+	report := Run(env, Scenario{
+		Name: "unfunded",
+		Steps: []Step{
+			Transfer("alice", "bob", 1),
+			Confirm(),
+		},
+	})
+
+	steps := report.Scenarios[0].Steps
+	assert.True(t, errors.Is(steps[0].Err, ErrInsufficientBalance))
+	assert.True(t, steps[1].Skipped)
+	assert.False(t, report.Passed())
+}
+
+func TestWriteJUnit(t *testing.T) {
+	env, _ := newFakeEnv(t)
+	report := Run(env, StandardFlow(), Scenario{
+		Name:  "bad account",
+		Steps: []Step{Transfer("carol", "bob", 1)},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, `<testsuites tests="6" failures="1" skipped="0">`)
+	assert.Contains(t, out, `<testsuite name="standard flow"`)
+	assert.Contains(t, out, `unknown scenario account: carol`)
+}
+
+// droppingNode forgets every pending transaction without confirming it, as a
+// node does when it drops a transaction
+type droppingNode struct{ *FakeNode }
+
+func (droppingNode) GetPendingTransaction(string) (*constellation.PendingTransaction, error) {
+	return nil, nil
+}
+
+// indexedExplorer reports the transactions in hashes as confirmed
+type indexedExplorer struct{ hashes map[string]bool }
+
+func (e indexedExplorer) GetTransaction(hash string) (*constellation.ExplorerTransaction, error) {
+	if !e.hashes[hash] {
+		return nil, nil
+	}
+	return &constellation.ExplorerTransaction{Hash: hash}, nil
+}
+
+func TestConfirm(t *testing.T) {
+	flow := Scenario{
+		Name:  "transfer",
+		Steps: []Step{Fund("alice", 10), Transfer("alice", "bob", 1), Confirm()},
+	}
+
+	t.Run("a dropped transaction is not confirmed", func(t *testing.T) {
+		env, node := newFakeEnv(t)
+		env.L1 = droppingNode{node}
+
+		steps := Run(env, flow).Scenarios[0].Steps
+		assert.NoError(t, steps[1].Err)
+		assert.ErrorIs(t, steps[2].Err, ErrNotConfirmed)
+	})
+
+	t.Run("confirms through the explorer", func(t *testing.T) {
+		env, node := newFakeEnv(t)
+		explorer := indexedExplorer{hashes: map[string]bool{}}
+		env.L1 = droppingNode{node}
+		env.Explorer = explorer
+
+		report := Run(env, Scenario{
+			Name: "transfer",
+			Steps: []Step{
+				Fund("alice", 10),
+				Transfer("alice", "bob", 1),
+				{Name: "index", Run: func(ctx *Context) error {
+					explorer.hashes[ctx.LastHash] = true
+					return nil
+				}},
+				Confirm(),
+			},
+		})
+		assert.True(t, report.Passed())
+	})
+
+	t.Run("needs an explorer or balances", func(t *testing.T) {
+		env, _ := newFakeEnv(t)
+		env.Balances = nil
+
+		steps := Run(env, flow).Scenarios[0].Steps
+		assert.ErrorIs(t, steps[2].Err, ErrNoConfirmationSource)
+	})
+}
@@ -0,0 +1,224 @@
+package scenarios
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	constellation "github.com/Constellation-Labs/metakit-sdk/packages/go/v2"
+)
+
+var (
+	// ErrNoTransaction indicates a step needed a previously submitted transaction
+	ErrNoTransaction = errors.New("no transaction submitted earlier in the scenario")
+	// ErrNotConfirmed indicates a transaction was still pending after ConfirmTimeout
+	ErrNotConfirmed = errors.New("transaction not confirmed before timeout")
+	// ErrNoConfirmationSource indicates a Confirm step ran in an env with
+	// neither an Explorer nor Balances to check the transaction against
+	ErrNoConfirmationSource = errors.New("env has no explorer or balance source to confirm with")
+)
+
+// Fund tops up the named account and waits for the balance to reflect it
+func Fund(account string, amount float64) Step {
+	return Step{
+		Name: fmt.Sprintf("fund %s with %g", account, amount),
+		Run: func(ctx *Context) error {
+			kp, err := ctx.Env.account(account)
+			if err != nil {
+				return err
+			}
+			if ctx.Env.Fund == nil {
+				return errors.New("env has no funding source")
+			}
+			if err := ctx.Env.Fund(kp.Address, amount); err != nil {
+				return err
+			}
+			if ctx.Env.Balances == nil {
+				return nil
+			}
+
+			want := constellation.TokenToUnits(amount)
+			return poll(ctx.Env, func() (bool, error) {
+				balance, err := ctx.Env.Balances.GetBalance(kp.Address)
+				if err != nil {
+					return false, nil
+				}
+				return balance.Balance >= want, nil
+			})
+		},
+	}
+}
+
+// Transfer sends amount tokens between two named accounts
+func Transfer(from, to string, amount float64) Step {
+	return Step{
+		Name: fmt.Sprintf("transfer %g from %s to %s", amount, from, to),
+		Run: func(ctx *Context) error {
+			sender, err := ctx.Env.account(from)
+			if err != nil {
+				return err
+			}
+			recipient, err := ctx.Env.account(to)
+			if err != nil {
+				return err
+			}
+
+			tx, err := buildTransfer(ctx, sender, recipient, amount)
+			if err != nil {
+				return err
+			}
+			return submit(ctx, tx)
+		},
+	}
+}
+
+// MultiSigTransfer sends amount tokens from one account with additional
+// co-signer proofs, verifying every proof locally before submission
+func MultiSigTransfer(from, to string, amount float64, cosigners ...string) Step {
+	return Step{
+		Name: fmt.Sprintf("multisig transfer %g from %s to %s", amount, from, to),
+		Run: func(ctx *Context) error {
+			sender, err := ctx.Env.account(from)
+			if err != nil {
+				return err
+			}
+			recipient, err := ctx.Env.account(to)
+			if err != nil {
+				return err
+			}
+
+			tx, err := buildTransfer(ctx, sender, recipient, amount)
+			if err != nil {
+				return err
+			}
+			for _, name := range cosigners {
+				cosigner, err := ctx.Env.account(name)
+				if err != nil {
+					return err
+				}
+				tx, err = constellation.SignCurrencyTransaction(tx, cosigner.PrivateKey)
+				if err != nil {
+					return err
+				}
+			}
+
+			result := constellation.VerifyCurrencyTransaction(tx)
+			if !result.IsValid || len(result.ValidProofs) != len(cosigners)+1 {
+				return fmt.Errorf("multisig verification failed: %d valid, %d invalid",
+					len(result.ValidProofs), len(result.InvalidProofs))
+			}
+			return submit(ctx, tx)
+		},
+	}
+}
+
+// Confirm waits until the last submitted transaction is confirmed: found on
+// the Explorer when the env has one, otherwise credited to the recipient's
+// balance. A transaction that leaves the pending pool without either, e.g.
+// because the node dropped it, fails with ErrNotConfirmed.
+func Confirm() Step {
+	return Step{
+		Name: "confirm last transaction",
+		Run: func(ctx *Context) error {
+			if ctx.LastHash == "" {
+				return ErrNoTransaction
+			}
+			if ctx.Env.Explorer == nil && (ctx.Env.Balances == nil || ctx.recipientBalance < 0) {
+				return ErrNoConfirmationSource
+			}
+			err := poll(ctx.Env, func() (bool, error) {
+				pending, err := ctx.Env.L1.GetPendingTransaction(ctx.LastHash)
+				if err != nil {
+					return false, err
+				}
+				if pending != nil {
+					return false, nil
+				}
+				return confirmed(ctx)
+			})
+			if err == ErrNotConfirmed {
+				return fmt.Errorf("%w: %s", err, ctx.LastHash)
+			}
+			return err
+		},
+	}
+}
+
+// confirmed checks the last transaction against the Explorer or, without
+// one, the recipient's balance. Lookup errors are retried until the timeout.
+func confirmed(ctx *Context) (bool, error) {
+	if ctx.Env.Explorer != nil {
+		tx, err := ctx.Env.Explorer.GetTransaction(ctx.LastHash)
+		return err == nil && tx != nil, nil
+	}
+	balance, err := ctx.Env.Balances.GetBalance(ctx.LastTransaction.Value.Destination)
+	if err != nil {
+		return false, nil
+	}
+	return balance.Balance >= ctx.recipientBalance+ctx.LastTransaction.Value.Amount, nil
+}
+
+// StandardFlow is the fund → transfer → multisig → confirm certification flow.
+// The env must define the accounts "alice" and "bob".
+func StandardFlow() Scenario {
+	return Scenario{
+		Name: "standard flow",
+		Steps: []Step{
+			Fund("alice", 10),
+			Transfer("alice", "bob", 1),
+			Confirm(),
+			MultiSigTransfer("alice", "bob", 1, "bob"),
+			Confirm(),
+		},
+	}
+}
+
+func buildTransfer(ctx *Context, sender, recipient *constellation.KeyPair, amount float64) (*constellation.CurrencyTransaction, error) {
+	lastRef, err := ctx.Env.L1.GetLastReference(sender.Address)
+	if err != nil {
+		return nil, err
+	}
+	return constellation.CreateCurrencyTransaction(
+		constellation.TransferParams{Destination: recipient.Address, Amount: amount},
+		sender.PrivateKey,
+		*lastRef,
+	)
+}
+
+func submit(ctx *Context, tx *constellation.CurrencyTransaction) error {
+	recipientBalance := int64(-1)
+	if ctx.Env.Balances != nil {
+		balance, err := ctx.Env.Balances.GetBalance(tx.Value.Destination)
+		if err != nil {
+			return err
+		}
+		recipientBalance = balance.Balance
+	}
+	response, err := ctx.Env.L1.PostTransaction(tx)
+	if err != nil {
+		return err
+	}
+	ctx.LastTransaction = tx
+	ctx.LastHash = response.Hash
+	ctx.recipientBalance = recipientBalance
+	return nil
+}
+
+// poll calls check every PollInterval until it reports done, returns an
+// error, or ConfirmTimeout elapses
+func poll(env *Env, check func() (bool, error)) error {
+	deadline := time.Now().Add(env.ConfirmTimeout)
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrNotConfirmed
+		}
+		time.Sleep(env.PollInterval)
+	}
+}
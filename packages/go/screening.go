@@ -0,0 +1,163 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrScreeningDenied indicates a withdrawal whose destination screening
+	// refused
+	ErrScreeningDenied = errors.New("destination denied by screening")
+	// ErrScreeningReview indicates a withdrawal held for manual compliance
+	// review
+	ErrScreeningReview = errors.New("destination held for screening review")
+	// ErrScreeningUnavailable indicates a screening provider that failed or
+	// gave no usable decision
+	ErrScreeningUnavailable = errors.New("screening unavailable")
+)
+
+// ScreeningDecision is a ScreeningProvider's verdict on a withdrawal
+type ScreeningDecision string
+
+const (
+	// ScreeningAllow lets the withdrawal be signed
+	ScreeningAllow ScreeningDecision = "allow"
+	// ScreeningDeny refuses the withdrawal
+	ScreeningDeny ScreeningDecision = "deny"
+	// ScreeningReview holds the withdrawal until someone clears it
+	ScreeningReview ScreeningDecision = "review"
+)
+
+// ScreeningRequest is the withdrawal a ScreeningProvider is asked about
+type ScreeningRequest struct {
+	// ID is the withdrawal's reference, to match decisions to cases
+	ID          string
+	Destination string
+	// Amount in smallest units (1e-8)
+	Amount int64
+}
+
+// ScreeningResult is a ScreeningProvider's answer
+type ScreeningResult struct {
+	Decision ScreeningDecision
+	// Reason explains a deny or review decision, e.g. the list matched
+	Reason string
+	// CaseID is the provider's reference for the decision, if any
+	CaseID string
+}
+
+// ScreeningProvider checks a withdrawal's destination before it is signed:
+// an internal sanctions list, a chain-analysis API or a compliance service.
+// ScreeningList implements this interface.
+type ScreeningProvider interface {
+	Screen(request ScreeningRequest) (ScreeningResult, error)
+}
+
+// ScreeningError is a withdrawal ScreeningPolicy refused. It unwraps to
+// ErrScreeningDenied or ErrScreeningReview.
+type ScreeningError struct {
+	Result ScreeningResult
+}
+
+func (e *ScreeningError) Error() string {
+	message := e.Unwrap().Error()
+	if e.Result.Reason != "" {
+		message += ": " + e.Result.Reason
+	}
+	if e.Result.CaseID != "" {
+		message += " (case " + e.Result.CaseID + ")"
+	}
+	return message
+}
+
+func (e *ScreeningError) Unwrap() error {
+	if e.Result.Decision == ScreeningReview {
+		return ErrScreeningReview
+	}
+	return ErrScreeningDenied
+}
+
+// ScreeningPolicy screens each withdrawal with provider before it is
+// signed. A deny or review decision rejects the request with a
+// *ScreeningError. Nothing retries a reviewed request: once the review
+// clears and the provider allows the destination, the caller must call
+// Enqueue again. Screening fails closed: a provider error or an unknown
+// decision rejects the request with ErrScreeningUnavailable.
+//
+// Example:
+//
+//	screening := NewScreeningList()
+//	screening.Deny("DAG...", "sanctions list 2024-06")
+//	queue, err := NewWithdrawalQueue(ctx, WithdrawalQueueConfig{
+//	    // ...
+//	    Policies: []WithdrawalPolicy{ScreeningPolicy(screening)},
+//	})
+func ScreeningPolicy(provider ScreeningProvider) WithdrawalPolicy {
+	return func(request WithdrawalRequest) error {
+		result, err := provider.Screen(ScreeningRequest{
+			ID:          request.ID,
+			Destination: request.Destination,
+			Amount:      request.Amount,
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrScreeningUnavailable, err)
+		}
+		switch result.Decision {
+		case ScreeningAllow:
+			return nil
+		case ScreeningDeny, ScreeningReview:
+			return &ScreeningError{Result: result}
+		default:
+			return fmt.Errorf("%w: unknown decision %q", ErrScreeningUnavailable, result.Decision)
+		}
+	}
+}
+
+// ScreeningList is an in-memory ScreeningProvider of denied addresses and
+// addresses to review, such as an internal watch list. Addresses on neither
+// list are allowed. It is safe for concurrent use, so lists can be updated
+// while a queue screens against them.
+type ScreeningList struct {
+	mu      sync.RWMutex
+	entries map[string]ScreeningResult
+}
+
+// NewScreeningList creates an empty list
+func NewScreeningList() *ScreeningList {
+	return &ScreeningList{entries: map[string]ScreeningResult{}}
+}
+
+// Deny refuses withdrawals to address
+func (l *ScreeningList) Deny(address string, reason string) {
+	l.set(address, ScreeningResult{Decision: ScreeningDeny, Reason: reason})
+}
+
+// Review holds withdrawals to address for review
+func (l *ScreeningList) Review(address string, reason string) {
+	l.set(address, ScreeningResult{Decision: ScreeningReview, Reason: reason})
+}
+
+// Clear removes address from the lists, allowing it again
+func (l *ScreeningList) Clear(address string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, address)
+}
+
+func (l *ScreeningList) set(address string, result ScreeningResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[address] = result
+}
+
+// Screen returns the list's decision for the request's destination
+func (l *ScreeningList) Screen(request ScreeningRequest) (ScreeningResult, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if result, ok := l.entries[request.Destination]; ok {
+		return result, nil
+	}
+	return ScreeningResult{Decision: ScreeningAllow}, nil
+}
@@ -0,0 +1,98 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ScreeningProvider = (*ScreeningList)(nil)
+
+// screeningFunc adapts a function to ScreeningProvider
+type screeningFunc func(request ScreeningRequest) (ScreeningResult, error)
+
+func (f screeningFunc) Screen(request ScreeningRequest) (ScreeningResult, error) {
+	return f(request)
+}
+
+func TestScreeningPolicy(t *testing.T) {
+	t.Run("maps decisions to rejections", func(t *testing.T) {
+		var asked ScreeningRequest
+		provider := screeningFunc(func(request ScreeningRequest) (ScreeningResult, error) {
+			asked = request
+			switch request.ID {
+			case "deny":
+				return ScreeningResult{Decision: ScreeningDeny, Reason: "sanctioned", CaseID: "c-1"}, nil
+			case "review":
+				return ScreeningResult{Decision: ScreeningReview, Reason: "high risk"}, nil
+			case "unknown":
+				return ScreeningResult{Decision: "maybe"}, nil
+			case "down":
+				return ScreeningResult{}, errors.New("timeout")
+			}
+			return ScreeningResult{Decision: ScreeningAllow}, nil
+		})
+		policy := ScreeningPolicy(provider)
+
+		assert.NoError(t, policy(WithdrawalRequest{ID: "ok", Destination: "DAG1", Amount: 5}))
+		assert.Equal(t, ScreeningRequest{ID: "ok", Destination: "DAG1", Amount: 5}, asked)
+
+		err := policy(WithdrawalRequest{ID: "deny"})
+		assert.ErrorIs(t, err, ErrScreeningDenied)
+		assert.EqualError(t, err, "destination denied by screening: sanctioned (case c-1)")
+		var screeningErr *ScreeningError
+		require.ErrorAs(t, err, &screeningErr)
+		assert.Equal(t, "c-1", screeningErr.Result.CaseID)
+
+		err = policy(WithdrawalRequest{ID: "review"})
+		assert.ErrorIs(t, err, ErrScreeningReview)
+		assert.NotErrorIs(t, err, ErrScreeningDenied)
+
+		// fails closed
+		assert.ErrorIs(t, policy(WithdrawalRequest{ID: "unknown"}), ErrScreeningUnavailable)
+		assert.ErrorIs(t, policy(WithdrawalRequest{ID: "down"}), ErrScreeningUnavailable)
+	})
+
+	t.Run("rejects before signing in the queue", func(t *testing.T) {
+		hot, err := GenerateKeyPair()
+		require.NoError(t, err)
+		alice, _ := GenerateKeyPair()
+		mallory, _ := GenerateKeyPair()
+		ledger := NewSimulatedLedger()
+		require.NoError(t, ledger.Fund(hot.Address, 100))
+
+		list := NewScreeningList()
+		list.Deny(mallory.Address, "watch list")
+		list.Review(alice.Address, "first withdrawal")
+
+		var receipts []WithdrawalReceipt
+		queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+			L1:         ledger,
+			PrivateKey: hot.PrivateKey,
+			Policies:   []WithdrawalPolicy{ScreeningPolicy(list)},
+			OnReceipt: func(r WithdrawalReceipt) {
+				receipts = append(receipts, r)
+				if r.Request.ID == "w-1" {
+					// cleared by compliance, then retried
+					list.Clear(alice.Address)
+				}
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-1", Destination: alice.Address, Amount: 1}))
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-2", Destination: mallory.Address, Amount: 1}))
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-1", Destination: alice.Address, Amount: 1}))
+		require.NoError(t, queue.Close())
+
+		require.Len(t, receipts, 3)
+		assert.Equal(t, WithdrawalRejected, receipts[0].Status)
+		assert.ErrorIs(t, receipts[0].Err, ErrScreeningReview)
+		assert.Empty(t, receipts[0].Hash)
+		assert.Equal(t, WithdrawalRejected, receipts[1].Status)
+		assert.ErrorIs(t, receipts[1].Err, ErrScreeningDenied)
+		assert.Equal(t, WithdrawalSubmitted, receipts[2].Status)
+	})
+}
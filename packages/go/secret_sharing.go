@@ -0,0 +1,232 @@
+package constellation
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidShareParameters indicates a share count or threshold
+	// SplitPrivateKey cannot use
+	ErrInvalidShareParameters = errors.New("invalid secret sharing parameters")
+	// ErrInvalidShare indicates a share that is malformed, fails its
+	// checksum, or belongs to a different key or split than the others
+	ErrInvalidShare = errors.New("invalid key share")
+	// ErrNotEnoughShares indicates fewer distinct shares than the threshold
+	// they were split with
+	ErrNotEnoughShares = errors.New("not enough key shares")
+)
+
+const (
+	shareVersion = 0x01
+	// shareLength is version, threshold, index, key ID, key share and
+	// checksum
+	shareLength = 1 + 1 + 1 + 4 + 32 + 4
+	// maxShares is limited by share indexes being non-zero bytes
+	maxShares = 255
+)
+
+// SplitPrivateKey splits a hex private key into n shares, any threshold of
+// which rebuild it with CombineShares while fewer reveal nothing about it
+// (Shamir's secret sharing over GF(256)). Each share is a hex string that
+// also records the threshold, its index, a checksum against typos and an ID
+// of the key, so shares of different keys or splits cannot be mixed by
+// mistake. The ID is derived from the address, which it reveals nothing
+// beyond.
+//
+// Requires 2 <= threshold <= n <= 255. Splitting the same key twice gives
+// unrelated shares; keep shares of one split together.
+//
+// Example:
+//
+//	shares, err := constellation.SplitPrivateKey(keyPair.PrivateKey, 5, 3)
+//	// give shares[0]..shares[4] to five custodians
+//
+//	privateKey, err := constellation.CombineShares([]string{shares[4], shares[0], shares[2]})
+func SplitPrivateKey(privateKeyHex string, n int, threshold int) ([]string, error) {
+	if threshold < 2 || threshold > n || n > maxShares {
+		return nil, fmt.Errorf("%w: need 2 <= threshold <= n <= %d, got threshold %d of %d", ErrInvalidShareParameters, maxShares, threshold, n)
+	}
+	kp, err := KeyPairFromPrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := kp.PrivateKeyBytes()
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(secret)
+	keyID := shareKeyID(kp.Address)
+
+	// one random polynomial per key byte, of degree threshold-1, whose
+	// constant term is the byte
+	coefficients := make([]byte, len(secret)*(threshold-1))
+	defer Zeroize(coefficients)
+	if _, err := rand.Read(coefficients); err != nil {
+		return nil, err
+	}
+
+	shares := make([]string, n)
+	share := make([]byte, 0, shareLength)
+	defer Zeroize(share[:cap(share)])
+	for i := range shares {
+		x := byte(i + 1)
+		share = append(share[:0], shareVersion, byte(threshold), x)
+		share = append(share, keyID[:]...)
+		for j, constant := range secret {
+			// Horner's rule from the highest coefficient down
+			var y byte
+			polynomial := coefficients[j*(threshold-1) : (j+1)*(threshold-1)]
+			for k := len(polynomial) - 1; k >= 0; k-- {
+				y = gfMul(y, x) ^ polynomial[k]
+			}
+			share = append(share, gfMul(y, x)^constant)
+		}
+		checksum := sha256.Sum256(share)
+		share = append(share, checksum[:4]...)
+		shares[i] = hex.EncodeToString(share)
+	}
+	return shares, nil
+}
+
+// CombineShares rebuilds the hex private key from shares made by
+// SplitPrivateKey, in any order. Needs at least the threshold the key was
+// split with; shares beyond it are checked for consistency but not needed,
+// and an exact duplicate is ignored. Returns ErrNotEnoughShares for too few
+// distinct shares and ErrInvalidShare for a damaged share or shares of
+// different keys or splits.
+func CombineShares(shares []string) (string, error) {
+	if len(shares) == 0 {
+		return "", ErrNotEnoughShares
+	}
+
+	var (
+		threshold int
+		keyID     []byte
+		parsed    []keyShare
+		decoded   [][]byte
+	)
+	defer func() {
+		for _, share := range decoded {
+			Zeroize(share)
+		}
+	}()
+	seen := map[byte][]byte{}
+	for i, encoded := range shares {
+		share, err := hex.DecodeString(encoded)
+		if err != nil || len(share) != shareLength {
+			return "", fmt.Errorf("%w: share %d is malformed", ErrInvalidShare, i)
+		}
+		decoded = append(decoded, share)
+		body, checksum := share[:shareLength-4], share[shareLength-4:]
+		expected := sha256.Sum256(body)
+		switch {
+		case !bytes.Equal(checksum, expected[:4]):
+			return "", fmt.Errorf("%w: share %d fails its checksum", ErrInvalidShare, i)
+		case body[0] != shareVersion || body[1] < 2 || body[2] == 0:
+			return "", fmt.Errorf("%w: share %d is malformed", ErrInvalidShare, i)
+		case keyID == nil:
+			threshold, keyID = int(body[1]), body[3:7]
+		case int(body[1]) != threshold || !bytes.Equal(body[3:7], keyID):
+			return "", fmt.Errorf("%w: share %d is from a different key or split", ErrInvalidShare, i)
+		}
+
+		x, y := body[2], body[7:]
+		if previous, ok := seen[x]; ok {
+			if !bytes.Equal(previous, y) {
+				return "", fmt.Errorf("%w: share %d conflicts with another share of index %d", ErrInvalidShare, i, x)
+			}
+			continue
+		}
+		seen[x] = y
+		parsed = append(parsed, keyShare{x: x, y: y})
+	}
+	if len(parsed) < threshold {
+		return "", fmt.Errorf("%w: have %d of %d", ErrNotEnoughShares, len(parsed), threshold)
+	}
+
+	// the key is the polynomials' value at x = 0
+	used := parsed[:threshold]
+	secret := make([]byte, 32)
+	defer Zeroize(secret)
+	for k := range secret {
+		secret[k] = interpolateShares(used, 0, k)
+	}
+
+	kp, err := KeyPairFromPrivateKeyBytes(secret)
+	if err != nil {
+		return "", fmt.Errorf("%w: shares do not combine to a valid key", ErrInvalidShare)
+	}
+	defer kp.Destroy()
+	if id := shareKeyID(kp.Address); !bytes.Equal(id[:], keyID) {
+		return "", fmt.Errorf("%w: shares do not combine to their key", ErrInvalidShare)
+	}
+	// any share beyond the threshold must lie on the same polynomials
+	for _, extra := range parsed[threshold:] {
+		for k := range secret {
+			if interpolateShares(used, extra.x, k) != extra.y[k] {
+				return "", fmt.Errorf("%w: the share of index %d does not match the others", ErrInvalidShare, extra.x)
+			}
+		}
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// keyShare is a decoded share: the polynomials' values y at index x
+type keyShare struct {
+	x byte
+	y []byte
+}
+
+// interpolateShares evaluates at x the polynomial for key byte k that
+// passes through shares (Lagrange interpolation)
+func interpolateShares(shares []keyShare, x byte, k int) byte {
+	var y byte
+	for i, share := range shares {
+		// product over j != i of (x - x_j) / (x_i - x_j); subtraction is
+		// XOR in GF(256)
+		basis := byte(1)
+		for j, other := range shares {
+			if i != j {
+				basis = gfMul(basis, gfMul(other.x^x, gfInverse(other.x^share.x)))
+			}
+		}
+		y ^= gfMul(share.y[k], basis)
+	}
+	return y
+}
+
+// shareKeyID identifies the key shares belong to
+func shareKeyID(address string) [4]byte {
+	digest := sha256.Sum256([]byte("metakit-key-share:" + address))
+	var id [4]byte
+	copy(id[:], digest[:4])
+	return id
+}
+
+// gfMul multiplies in GF(256) with the AES polynomial x^8+x^4+x^3+x+1,
+// without branches or table lookups that depend on the operands
+func gfMul(a, b byte) byte {
+	var product byte
+	for i := 0; i < 8; i++ {
+		product ^= -(b & 1) & a
+		carry := -(a >> 7)
+		a = a<<1 ^ 0x1B&carry
+		b >>= 1
+	}
+	return product
+}
+
+// gfInverse returns a^254, the multiplicative inverse of a non-zero a
+func gfInverse(a byte) byte {
+	result := byte(1)
+	for i := 0; i < 7; i++ {
+		a = gfMul(a, a)
+		result = gfMul(result, a)
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package constellation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPrivateKey(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	shares, err := SplitPrivateKey(kp.PrivateKey, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	t.Run("any threshold of shares rebuild the key", func(t *testing.T) {
+		for _, subset := range [][]int{{0, 1, 2}, {4, 0, 2}, {1, 3, 4}, {2, 3, 4}, {0, 1, 2, 3, 4}} {
+			picked := make([]string, len(subset))
+			for i, index := range subset {
+				picked[i] = shares[index]
+			}
+			privateKey, err := CombineShares(picked)
+			require.NoError(t, err, subset)
+			assert.Equal(t, kp.PrivateKey, privateKey, subset)
+		}
+	})
+
+	t.Run("fewer shares are not enough", func(t *testing.T) {
+		_, err := CombineShares(shares[:2])
+		assert.ErrorIs(t, err, ErrNotEnoughShares)
+		_, err = CombineShares(nil)
+		assert.ErrorIs(t, err, ErrNotEnoughShares)
+		// a duplicate does not count twice
+		_, err = CombineShares([]string{shares[0], shares[1], shares[0]})
+		assert.ErrorIs(t, err, ErrNotEnoughShares)
+	})
+
+	t.Run("splits differently every time", func(t *testing.T) {
+		again, err := SplitPrivateKey(kp.PrivateKey, 5, 3)
+		require.NoError(t, err)
+		assert.NotEqual(t, shares[0], again[0])
+
+		// shares of different splits are not mixed
+		_, err = CombineShares([]string{shares[0], shares[1], again[2]})
+		assert.ErrorIs(t, err, ErrInvalidShare)
+	})
+
+	t.Run("rejects damaged and foreign shares", func(t *testing.T) {
+		damaged := []byte(shares[1])
+		damaged[20] ^= 0x01
+		_, err := CombineShares([]string{shares[0], string(damaged), shares[2]})
+		assert.ErrorIs(t, err, ErrInvalidShare)
+
+		_, err = CombineShares([]string{shares[0], "not hex", shares[2]})
+		assert.ErrorIs(t, err, ErrInvalidShare)
+
+		other, err := GenerateKeyPair()
+		require.NoError(t, err)
+		foreign, err := SplitPrivateKey(other.PrivateKey, 5, 3)
+		require.NoError(t, err)
+		_, err = CombineShares([]string{shares[0], shares[1], foreign[2]})
+		assert.ErrorIs(t, err, ErrInvalidShare)
+	})
+
+	t.Run("supports the full range of parameters", func(t *testing.T) {
+		for _, params := range [][2]int{{2, 2}, {255, 2}, {255, 255}} {
+			n, threshold := params[0], params[1]
+			split, err := SplitPrivateKey(kp.PrivateKey, n, threshold)
+			require.NoError(t, err, params)
+			privateKey, err := CombineShares(split[n-threshold:])
+			require.NoError(t, err, params)
+			assert.Equal(t, kp.PrivateKey, privateKey, params)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, params := range [][2]int{{5, 1}, {3, 4}, {256, 3}, {0, 0}} {
+			_, err := SplitPrivateKey(kp.PrivateKey, params[0], params[1])
+			assert.ErrorIs(t, err, ErrInvalidShareParameters, params)
+		}
+		_, err := SplitPrivateKey("nope", 3, 2)
+		assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+	})
+}
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), gfMul(byte(a), gfInverse(byte(a))), a)
+	}
+	// the AES field's known product, FIPS-197 section 4.2
+	assert.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+}
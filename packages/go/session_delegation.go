@@ -0,0 +1,131 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDelegationInvalid indicates a delegation with a missing or bad
+	// signature from its primary address
+	ErrDelegationInvalid = errors.New("session delegation is not validly signed by its primary address")
+	// ErrDelegationExpired indicates a delegation used outside its validity window
+	ErrDelegationExpired = errors.New("session delegation is not valid at this time")
+	// ErrDelegationScope indicates an operation the delegation does not authorize
+	ErrDelegationScope = errors.New("operation is outside the session delegation's scopes")
+	// ErrNotSignedBySession indicates a signed object with no valid signature
+	// from the delegated session key
+	ErrNotSignedBySession = errors.New("not validly signed by the delegated session key")
+)
+
+// SessionScopeAll is a delegation scope that allows every operation
+const SessionScopeAll = "*"
+
+// SessionDelegation authorizes a session key to sign on behalf of a
+// primary DAG address for a limited time. A web backend holds only the
+// session key and presents the delegation, signed by the primary key,
+// alongside every object it signs.
+type SessionDelegation struct {
+	// Primary is the DAG address that granted the delegation
+	Primary string `json:"primary"`
+	// SessionKey is the session public key ID (without 04 prefix)
+	SessionKey string `json:"sessionKey"`
+	// Scopes names the operations the session key may sign, or holds
+	// SessionScopeAll; empty allows none
+	Scopes    []string  `json:"scopes,omitempty"`
+	NotBefore time.Time `json:"notBefore"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Allows reports whether the delegation authorizes scope. A delegation with
+// no scopes allows nothing, and only SessionScopeAll allows every scope.
+func (d SessionDelegation) Allows(scope string) bool {
+	for _, allowed := range d.Scopes {
+		if allowed == scope || allowed == SessionScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSessionDelegation signs a delegation from primary to the session
+// public key for scopes, valid from now for ttl. At least one scope is
+// required; pass SessionScopeAll for an unrestricted session, otherwise
+// ErrDelegationScope is returned.
+//
+// Example:
+//
+//	session, _ := GenerateKeyPair()
+//	primary, _ := NewPrivateKeySigner(primaryKey)
+//	delegation, err := CreateSessionDelegation(primary, session.PublicKey, 24*time.Hour, "orders:place")
+//
+//	// the backend keeps session.PrivateKey and delegation, never primaryKey
+func CreateSessionDelegation(primary Signer, sessionPublicKey string, ttl time.Duration, scopes ...string) (*Signed[SessionDelegation], error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("%w: ttl must be positive", ErrDelegationExpired)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: no scopes given", ErrDelegationScope)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	delegation := SessionDelegation{
+		Primary:    GetAddress(primary.PublicKey()),
+		SessionKey: NormalizePublicKeyToID(sessionPublicKey),
+		Scopes:     scopes,
+		NotBefore:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	return CreateSignedObjectWithSigner(delegation, primary, false)
+}
+
+// VerifySessionDelegation checks that the delegation is validly signed by
+// its primary address and is valid now
+func VerifySessionDelegation(delegation *Signed[SessionDelegation]) error {
+	if delegation == nil || !Verify(delegation, false).IsValid ||
+		!signedByAny(delegation.Proofs, []string{delegation.Value.Primary}) {
+		return ErrDelegationInvalid
+	}
+	now := time.Now()
+	if now.Before(delegation.Value.NotBefore) || !now.Before(delegation.Value.ExpiresAt) {
+		return fmt.Errorf("%w: valid %s to %s", ErrDelegationExpired,
+			delegation.Value.NotBefore.Format(time.RFC3339), delegation.Value.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// VerifyDelegatedSignature checks that signed carries a valid signature from
+// the delegation's session key, that the delegation is valid now and that
+// it allows scope. It returns the primary address the object was signed on
+// behalf of.
+//
+// Example:
+//
+//	primary, err := VerifyDelegatedSignature(order, delegation, "orders:place", true)
+//	if err != nil {
+//	    return err
+//	}
+//	// treat order as signed by primary
+func VerifyDelegatedSignature[T any](signed *Signed[T], delegation *Signed[SessionDelegation], scope string, isDataUpdate bool) (string, error) {
+	if err := VerifySessionDelegation(delegation); err != nil {
+		return "", err
+	}
+	if !delegation.Value.Allows(scope) {
+		return "", fmt.Errorf("%w: %q", ErrDelegationScope, scope)
+	}
+	if signed == nil {
+		return "", ErrNotSignedBySession
+	}
+
+	result := Verify(signed, isDataUpdate)
+	if !result.IsValid {
+		return "", ErrNotSignedBySession
+	}
+	for _, proof := range result.ValidProofs {
+		if strings.EqualFold(NormalizePublicKeyToID(proof.ID), delegation.Value.SessionKey) {
+			return delegation.Value.Primary, nil
+		}
+	}
+	return "", ErrNotSignedBySession
+}
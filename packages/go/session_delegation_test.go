@@ -0,0 +1,76 @@
+package constellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionDelegation(t *testing.T) {
+	primaryKeys, err := GenerateKeyPair()
+	require.NoError(t, err)
+	primary, err := NewPrivateKeySigner(primaryKeys.PrivateKey)
+	require.NoError(t, err)
+	session, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	delegation, err := CreateSessionDelegation(primary, session.PublicKey, time.Hour, "orders:place")
+	require.NoError(t, err)
+	order := map[string]interface{}{"item": "coffee", "quantity": 2}
+	signed, err := CreateSignedObject(order, session.PrivateKey, true)
+	require.NoError(t, err)
+
+	t.Run("resolves the primary address", func(t *testing.T) {
+		require.NoError(t, VerifySessionDelegation(delegation))
+		address, err := VerifyDelegatedSignature(signed, delegation, "orders:place", true)
+		require.NoError(t, err)
+		assert.Equal(t, primaryKeys.Address, address)
+	})
+
+	t.Run("enforces scopes", func(t *testing.T) {
+		_, err := VerifyDelegatedSignature(signed, delegation, "withdrawals:create", true)
+		assert.ErrorIs(t, err, ErrDelegationScope)
+
+		_, err = CreateSessionDelegation(primary, session.PublicKey, time.Hour)
+		assert.ErrorIs(t, err, ErrDelegationScope)
+		assert.False(t, SessionDelegation{}.Allows("orders:place"), "no scopes allow nothing")
+		assert.False(t, delegation.Value.Allows(""))
+
+		unrestricted, err := CreateSessionDelegation(primary, session.PublicKey, time.Hour, SessionScopeAll)
+		require.NoError(t, err)
+		_, err = VerifyDelegatedSignature(signed, unrestricted, "withdrawals:create", true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects objects not signed by the session key", func(t *testing.T) {
+		other, err := CreateSignedObject(order, primaryKeys.PrivateKey, true)
+		require.NoError(t, err)
+		_, err = VerifyDelegatedSignature(other, delegation, "orders:place", true)
+		assert.ErrorIs(t, err, ErrNotSignedBySession)
+
+		tampered := &Signed[map[string]interface{}]{Value: map[string]interface{}{"item": "tea"}, Proofs: signed.Proofs}
+		_, err = VerifyDelegatedSignature(tampered, delegation, "orders:place", true)
+		assert.ErrorIs(t, err, ErrNotSignedBySession)
+	})
+
+	t.Run("rejects expired and forged delegations", func(t *testing.T) {
+		expired := delegation.Value
+		expired.NotBefore = expired.NotBefore.Add(-2 * time.Hour)
+		expired.ExpiresAt = expired.NotBefore.Add(time.Hour)
+		resigned, err := CreateSignedObjectWithSigner(expired, primary, false)
+		require.NoError(t, err)
+		_, err = VerifyDelegatedSignature(signed, resigned, "orders:place", true)
+		assert.ErrorIs(t, err, ErrDelegationExpired)
+
+		// the session key cannot delegate to itself on the primary's behalf
+		forged, err := CreateSignedObject(delegation.Value, session.PrivateKey, false)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifySessionDelegation(forged), ErrDelegationInvalid)
+		assert.ErrorIs(t, VerifySessionDelegation(nil), ErrDelegationInvalid)
+
+		_, err = CreateSessionDelegation(primary, session.PublicKey, 0)
+		assert.Error(t, err)
+	})
+}
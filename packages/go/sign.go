@@ -2,7 +2,6 @@ package constellation
 
 import (
 	"encoding/hex"
-	"fmt"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
@@ -62,12 +61,46 @@ func SignDataUpdate(data interface{}, privateKeyHex string) (*SignatureProof, er
 	}, nil
 }
 
-// SignHash signs a pre-computed SHA-256 hash
+// SignWithSigner signs data with a Signer, as Sign or SignDataUpdate would
+// with the signer's private key
+func SignWithSigner(data interface{}, signer Signer, isDataUpdate bool) (*SignatureProof, error) {
+	if err := CheckSigningAllowed(); err != nil {
+		return nil, err
+	}
+	bytes, err := ToBytes(data, isDataUpdate)
+	if err != nil {
+		return nil, err
+	}
+	hash := HashBytes(bytes)
+
+	signature, err := signer.SignHash(hash.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignatureProof{
+		ID:        NormalizePublicKeyToID(signer.PublicKey()),
+		Signature: signature,
+	}, nil
+}
+
+// SignNotification signs the SHA-256 hash of a notification body, such as a
+// webhook payload. Unlike SignHash it still signs while signing is frozen,
+// since a notification authorizes no transfer.
+func SignNotification(body []byte, privateKeyHex string) (string, error) {
+	return signHashInternal(HashBytes(body).Value, privateKeyHex)
+}
+
+// SignHash signs a pre-computed SHA-256 hash. Returns a *SigningFrozenError
+// while signing is frozen (see FreezeSigning).
 func SignHash(hashHex string, privateKeyHex string) (string, error) {
+	if err := CheckSigningAllowed(); err != nil {
+		return "", err
+	}
 	// Parse private key
 	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
 	if err != nil {
-		return "", fmt.Errorf("invalid private key hex: %w", err)
+		return "", invalidPrivateKeyHex(err)
 	}
 
 	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
@@ -0,0 +1,96 @@
+package constellation
+
+// Structural limits of a DER-encoded secp256k1 signature
+const (
+	minDERSignatureLen = 8
+	maxDERIntegerLen   = 33
+)
+
+// signatureLooksValid performs a cheap structural check of a hex-encoded DER
+// signature: even hex length within bounds, SEQUENCE tag and length, and two
+// INTEGER elements whose lengths add up. It rejects garbage before any hex
+// decoding or curve arithmetic; a true result does not mean the signature
+// verifies.
+func signatureLooksValid(signatureHex string) bool {
+	n := len(signatureHex)
+	if n%2 != 0 || n < 2*minDERSignatureLen || n > 2*maxDERSignatureLen {
+		return false
+	}
+
+	sequenceTag, ok := hexByteAt(signatureHex, 0)
+	if !ok || sequenceTag != 0x30 {
+		return false
+	}
+	sequenceLen, ok := hexByteAt(signatureHex, 1)
+	if !ok || int(sequenceLen)+2 != n/2 {
+		return false
+	}
+
+	rTag, ok := hexByteAt(signatureHex, 2)
+	if !ok || rTag != 0x02 {
+		return false
+	}
+	rLen, ok := hexByteAt(signatureHex, 3)
+	if !ok || rLen == 0 || rLen > maxDERIntegerLen {
+		return false
+	}
+
+	sOffset := 4 + int(rLen)
+	if sOffset+2 > n/2 {
+		return false
+	}
+	sTag, ok := hexByteAt(signatureHex, sOffset)
+	if !ok || sTag != 0x02 {
+		return false
+	}
+	sLen, ok := hexByteAt(signatureHex, sOffset+1)
+	if !ok || sLen == 0 || sLen > maxDERIntegerLen {
+		return false
+	}
+
+	return sOffset+2+int(sLen) == n/2
+}
+
+// publicKeyLooksValid checks that a proof ID is 128 hex characters, the
+// uncompressed public key without its 04 prefix, or a compressed key with
+// its 02/03 prefix
+func publicKeyLooksValid(publicKeyID string) bool {
+	if len(publicKeyID) != 128 && !isCompressedPublicKey(publicKeyID) {
+		return false
+	}
+	for i := 0; i < len(publicKeyID); i++ {
+		if fromHexChar(publicKeyID[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// proofLooksValid applies both structural checks to a proof
+func proofLooksValid(proof SignatureProof) bool {
+	return publicKeyLooksValid(proof.ID) && signatureLooksValid(proof.Signature)
+}
+
+// hexByteAt decodes the byte at index i of a hex string
+func hexByteAt(s string, i int) (byte, bool) {
+	if 2*i+1 >= len(s) {
+		return 0, false
+	}
+	hi, lo := fromHexChar(s[2*i]), fromHexChar(s[2*i+1])
+	if hi < 0 || lo < 0 {
+		return 0, false
+	}
+	return byte(hi<<4 | lo), true
+}
+
+func fromHexChar(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c - 'a' + 10)
+	case 'A' <= c && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
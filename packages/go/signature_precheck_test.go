@@ -0,0 +1,77 @@
+package constellation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignaturePrecheck(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	dest, _ := GenerateKeyPair()
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: dest.Address, Amount: 1}, kp.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+	proof := tx.Proofs[0]
+
+	t.Run("accepts real signatures", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			signature, err := SignHash(strings.Repeat("ab", 32), kp.PrivateKey)
+			require.NoError(t, err)
+			assert.True(t, signatureLooksValid(signature), signature)
+		}
+		assert.True(t, proofLooksValid(proof))
+	})
+
+	t.Run("rejects malformed signatures", func(t *testing.T) {
+		cases := map[string]string{
+			"empty":            "",
+			"odd length":       proof.Signature[:len(proof.Signature)-1],
+			"non-hex":          strings.Repeat("zz", 70),
+			"wrong tag":        "31" + proof.Signature[2:],
+			"truncated":        proof.Signature[:len(proof.Signature)-2],
+			"trailing garbage": proof.Signature + "00",
+			"too long":         strings.Repeat("30", 100),
+		}
+		for name, signature := range cases {
+			assert.False(t, signatureLooksValid(signature), name)
+		}
+	})
+
+	t.Run("rejects malformed public key IDs", func(t *testing.T) {
+		assert.False(t, publicKeyLooksValid(""))
+		assert.False(t, publicKeyLooksValid("04"+proof.ID))
+		assert.False(t, publicKeyLooksValid(strings.Repeat("g", 128)))
+	})
+}
+
+func TestVerifyCurrencyTransactionStrict(t *testing.T) {
+	kp1, _ := GenerateKeyPair()
+	kp2, _ := GenerateKeyPair()
+	dest, _ := GenerateKeyPair()
+
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: dest.Address, Amount: 1}, kp1.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+	tx, err = SignCurrencyTransaction(tx, kp2.PrivateKey)
+	require.NoError(t, err)
+
+	assert.True(t, VerifyCurrencyTransactionStrict(tx))
+
+	noProofs := &CurrencyTransaction{Value: tx.Value}
+	assert.False(t, VerifyCurrencyTransactionStrict(noProofs))
+
+	garbage := &CurrencyTransaction{Value: tx.Value, Proofs: append([]SignatureProof{}, tx.Proofs...)}
+	garbage.Proofs[1].Signature = "not a signature"
+	assert.False(t, VerifyCurrencyTransactionStrict(garbage))
+
+	result := VerifyCurrencyTransaction(garbage)
+	assert.False(t, result.IsValid)
+	assert.Len(t, result.ValidProofs, 1)
+	assert.Len(t, result.InvalidProofs, 1)
+
+	tampered := *tx
+	tampered.Value.Amount++
+	assert.False(t, VerifyCurrencyTransactionStrict(&tampered))
+}
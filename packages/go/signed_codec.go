@@ -0,0 +1,102 @@
+package constellation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ErrMissingSignedValue indicates signed JSON without a "value" field
+var ErrMissingSignedValue = errors.New("signed object has no value")
+
+// RawSigned is a Signed[T] decoded from JSON that keeps the value exactly as
+// received. Decoding into T and re-encoding can reorder fields, drop unknown
+// ones or reformat numbers (large integers, exponents), which changes the
+// canonical hash and breaks the signatures. RawSigned marshals and verifies
+// the received bytes instead, so a received object round-trips unchanged.
+//
+// Value is decoded for reading only; changes to it are not marshaled or
+// verified. Build new objects with CreateSignedObject.
+//
+// Example:
+//
+//	var received RawSigned[MyUpdate]
+//	if err := json.Unmarshal(body, &received); err != nil {
+//	    return err
+//	}
+//	if !received.Verify(true).IsValid {
+//	    return ErrInvalidSignature
+//	}
+//	forwarded, _ := json.Marshal(received) // same value bytes, same hash
+type RawSigned[T any] struct {
+	Signed[T]
+	raw json.RawMessage
+}
+
+// UnmarshalJSON decodes the value into T and keeps its raw bytes
+func (s *RawSigned[T]) UnmarshalJSON(data []byte) error {
+	var envelope struct {
+		Value  json.RawMessage  `json:"value"`
+		Proofs []SignatureProof `json:"proofs"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+	if len(envelope.Value) == 0 || bytes.Equal(envelope.Value, []byte("null")) {
+		return ErrMissingSignedValue
+	}
+
+	var value T
+	if err := json.Unmarshal(envelope.Value, &value); err != nil {
+		return err
+	}
+	if envelope.Proofs == nil {
+		envelope.Proofs = []SignatureProof{}
+	}
+
+	s.Signed = Signed[T]{Value: value, Proofs: envelope.Proofs}
+	s.raw = append(json.RawMessage{}, envelope.Value...)
+	return nil
+}
+
+// MarshalJSON encodes the received value bytes with the current proofs. An
+// object that was not decoded from JSON encodes Value as usual.
+func (s RawSigned[T]) MarshalJSON() ([]byte, error) {
+	signed, err := s.rawSigned()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(signed)
+}
+
+// RawValue returns the value bytes as received, or nil if the object was not
+// decoded from JSON
+func (s *RawSigned[T]) RawValue() json.RawMessage {
+	return s.raw
+}
+
+// Verify verifies the proofs against the received value bytes
+func (s *RawSigned[T]) Verify(isDataUpdate bool) *VerificationResult {
+	signed, err := s.rawSigned()
+	if err != nil {
+		return &VerificationResult{
+			IsValid:       false,
+			ValidProofs:   []SignatureProof{},
+			InvalidProofs: s.Proofs,
+		}
+	}
+	return Verify(signed, isDataUpdate)
+}
+
+// rawSigned returns the object with the value as raw JSON
+func (s *RawSigned[T]) rawSigned() (*Signed[json.RawMessage], error) {
+	value := s.raw
+	if value == nil {
+		encoded, err := json.Marshal(s.Value)
+		if err != nil {
+			return nil, err
+		}
+		value = encoded
+	}
+	return &Signed[json.RawMessage]{Value: value, Proofs: s.Proofs}, nil
+}
@@ -0,0 +1,97 @@
+package constellation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyTransactionSaltFormats(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, _ := GenerateKeyPair()
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: destination.Address, Amount: 1}, keyPair.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+	hash := HashCurrencyTransaction(tx).Value
+
+	sdkJSON, err := json.Marshal(tx)
+	require.NoError(t, err)
+	// nodes send the salt as a number
+	nodeJSON := []byte(`{"value":{"source":"` + tx.Value.Source + `","destination":"` + tx.Value.Destination +
+		`","amount":100000000,"fee":0,"parent":{"hash":"` + tx.Value.Parent.Hash + `","ordinal":0},"salt":` + tx.Value.Salt +
+		`},"proofs":[{"id":"` + tx.Proofs[0].ID + `","signature":"` + tx.Proofs[0].Signature + `"}]}`)
+
+	for name, data := range map[string][]byte{"string salt": sdkJSON, "number salt": nodeJSON} {
+		t.Run(name, func(t *testing.T) {
+			var decoded CurrencyTransaction
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tx.Value, decoded.Value)
+			assert.Equal(t, hash, HashCurrencyTransaction(&decoded).Value)
+			assert.True(t, VerifyCurrencyTransactionStrict(&decoded))
+		})
+	}
+
+	var bad CurrencyTransaction
+	assert.Error(t, json.Unmarshal([]byte(`{"value":{"salt":true}}`), &bad))
+}
+
+// partialUpdate knows only some of the fields the sender signed
+type partialUpdate struct {
+	Name  string  `json:"name"`
+	Count float64 `json:"count"`
+}
+
+func TestRawSignedRoundTrip(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	// field order, an unknown field and an integer beyond float64 precision
+	value := json.RawMessage(`{"name":"sensor-1","count":12345678901234567891,"extra":{"z":1,"a":[1e2,0.5]}}`)
+	signed, err := CreateSignedObject(value, keyPair.PrivateKey, true)
+	require.NoError(t, err)
+	wire, err := json.Marshal(signed)
+	require.NoError(t, err)
+
+	t.Run("plain Signed[T] loses the signed bytes", func(t *testing.T) {
+		var decoded Signed[partialUpdate]
+		require.NoError(t, json.Unmarshal(wire, &decoded))
+		assert.False(t, Verify(&decoded, true).IsValid)
+	})
+
+	t.Run("RawSigned verifies and re-encodes the received bytes", func(t *testing.T) {
+		var received RawSigned[partialUpdate]
+		require.NoError(t, json.Unmarshal(wire, &received))
+		assert.Equal(t, "sensor-1", received.Value.Name)
+		assert.True(t, received.Verify(true).IsValid)
+
+		again, err := json.Marshal(received)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(wire), string(again))
+
+		var forwarded Signed[json.RawMessage]
+		require.NoError(t, json.Unmarshal(again, &forwarded))
+		assert.True(t, Verify(&forwarded, true).IsValid)
+
+		canonicalBefore, _ := CanonicalizeBytes(value)
+		canonicalAfter, _ := CanonicalizeBytes(received.RawValue())
+		assert.Equal(t, canonicalBefore, canonicalAfter)
+	})
+
+	t.Run("objects not decoded from JSON encode Value", func(t *testing.T) {
+		built, err := CreateSignedObject(partialUpdate{Name: "a", Count: 2}, keyPair.PrivateKey, false)
+		require.NoError(t, err)
+		wrapped := RawSigned[partialUpdate]{Signed: *built}
+		assert.Nil(t, wrapped.RawValue())
+		assert.True(t, wrapped.Verify(false).IsValid)
+		data, err := json.Marshal(wrapped)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"value":{"name":"a","count":2},"proofs":[{"id":"`+built.Proofs[0].ID+`","signature":"`+built.Proofs[0].Signature+`"}]}`, string(data))
+	})
+
+	t.Run("rejects a missing value", func(t *testing.T) {
+		var received RawSigned[partialUpdate]
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"proofs":[]}`), &received), ErrMissingSignedValue)
+	})
+}
@@ -21,6 +21,61 @@ func CreateSignedObject[T any](value T, privateKeyHex string, isDataUpdate bool)
 	}, nil
 }
 
+// CreateSignedObjectWithSigner creates a signed object with a single
+// signature from a Signer
+func CreateSignedObjectWithSigner[T any](value T, signer Signer, isDataUpdate bool) (*Signed[T], error) {
+	proof, err := SignWithSigner(value, signer, isDataUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signed[T]{
+		Value:  value,
+		Proofs: []SignatureProof{*proof},
+	}, nil
+}
+
+// CreateDataTransaction signs value as a metagraph DataUpdate, the payload
+// a Data L1 node accepts: the canonical JSON (RFC 8785, sorted keys, UTF-8)
+// is base64 encoded behind the Constellation prefix, hashed and signed, as
+// dag4.js dataSign does. It is CreateSignedObject with isDataUpdate set;
+// value can be any type that marshals to JSON, so struct tags name the
+// fields.
+//
+// Example:
+//
+//	type Vote struct {
+//	    PollID string `json:"pollId"`
+//	    Option int    `json:"option"`
+//	}
+//	signed, err := CreateDataTransaction(Vote{PollID: "p-1", Option: 2}, privateKey)
+//	if err != nil {
+//	    return err
+//	}
+//	result, err := dataL1Client.PostData(signed)
+func CreateDataTransaction[T any](value T, privateKeyHex string) (*Signed[T], error) {
+	return CreateSignedObject(value, privateKeyHex, true)
+}
+
+// CreateDataTransactionWithSigner is CreateDataTransaction with the key
+// behind a Signer
+func CreateDataTransactionWithSigner[T any](value T, signer Signer) (*Signed[T], error) {
+	return CreateSignedObjectWithSigner(value, signer, true)
+}
+
+// signedByAny reports whether a proof was made by one of the DAG addresses
+func signedByAny(proofs []SignatureProof, addresses []string) bool {
+	for _, proof := range proofs {
+		signer := GetAddress(proof.ID)
+		for _, address := range addresses {
+			if signer == address {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // AddSignature adds an additional signature to an existing signed object
 func AddSignature[T any](signed *Signed[T], privateKeyHex string, isDataUpdate bool) (*Signed[T], error) {
 	var newProof *SignatureProof
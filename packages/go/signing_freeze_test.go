@@ -0,0 +1,125 @@
+package constellation
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeSigning(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	other, err := GenerateKeyPair()
+	require.NoError(t, err)
+	signer, err := NewPrivateKeySigner(kp.PrivateKey)
+	require.NoError(t, err)
+	params := TransferParams{Destination: other.Address, Amount: 1}
+
+	// signed before the freeze
+	tx, err := CreateCurrencyTransaction(params, kp.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+
+	FreezeSigning("incident drill")
+	t.Cleanup(UnfreezeSigning)
+
+	t.Run("rejects every signing path", func(t *testing.T) {
+		_, err := Sign(map[string]string{"a": "b"}, kp.PrivateKey)
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+		_, err = SignHash(HashBytes([]byte("x")).Value, kp.PrivateKey)
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+		_, err = CreateCurrencyTransaction(params, kp.PrivateKey, GenesisReference)
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+		_, err = SignWithSigner(map[string]string{"a": "b"}, signer, true)
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+		_, err = signer.SignDigest(make([]byte, 32))
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+		_, err = NewDigestSigner(signer).SignHash(HashBytes([]byte("x")).Value)
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+		_, err = SignReader(bytes.NewReader([]byte("artifact")), signer)
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+	})
+
+	t.Run("rejects custom signers in SignTransaction", func(t *testing.T) {
+		unsigned, err := NewTransactionBuilder(kp.Address).Build(other.Address, 1, 0, GenesisReference)
+		require.NoError(t, err)
+		_, err = SignTransaction(unsigned, compressedSigner{signer})
+		assert.ErrorIs(t, err, ErrSigningFrozen)
+	})
+
+	t.Run("reports the freeze", func(t *testing.T) {
+		var frozen *SigningFrozenError
+		require.True(t, errors.As(CheckSigningAllowed(), &frozen))
+		assert.True(t, frozen.Global)
+		assert.Equal(t, "incident drill", frozen.Reason)
+		assert.False(t, frozen.Since.IsZero())
+		assert.Contains(t, frozen.Error(), "incident drill")
+	})
+
+	t.Run("keeps reads and verification working", func(t *testing.T) {
+		assert.True(t, VerifyCurrencyTransaction(tx).IsValid)
+		assert.Equal(t, kp.Address, signer.Address())
+
+		ledger := NewSimulatedLedger()
+		ledger.Fund(kp.Address, 100)
+		_, err := ledger.PostTransaction(tx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unfreezes", func(t *testing.T) {
+		UnfreezeSigning()
+		assert.NoError(t, CheckSigningAllowed())
+		_, err := CreateCurrencyTransaction(params, kp.PrivateKey, GenesisReference)
+		assert.NoError(t, err)
+	})
+}
+
+func TestFreezableSigner(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	inner, err := NewPrivateKeySigner(kp.PrivateKey)
+	require.NoError(t, err)
+	hash := HashBytes([]byte("payload")).Value
+
+	signer := NewFreezableSigner(inner)
+	_, err = signer.SignHash(hash)
+	require.NoError(t, err)
+
+	signer.Freeze("limit breached")
+	assert.True(t, signer.Frozen())
+	assert.Equal(t, inner.PublicKey(), signer.PublicKey())
+
+	_, err = signer.SignHash(hash)
+	var frozen *SigningFrozenError
+	require.True(t, errors.As(err, &frozen))
+	assert.False(t, frozen.Global)
+	assert.Equal(t, "limit breached", frozen.Reason)
+	_, err = signer.SignDigest(ComputeDigestFromHash(hash))
+	assert.ErrorIs(t, err, ErrSigningFrozen)
+
+	// other signers are unaffected
+	_, err = inner.SignHash(hash)
+	assert.NoError(t, err)
+
+	signer.Unfreeze()
+	assert.False(t, signer.Frozen())
+	_, err = signer.SignHash(hash)
+	assert.NoError(t, err)
+	digestSignature, err := signer.SignDigest(ComputeDigestFromHash(hash))
+	require.NoError(t, err)
+	hashSignature, err := inner.SignHash(hash)
+	require.NoError(t, err)
+	// signatures are deterministic, so both paths agree
+	assert.Equal(t, hashSignature, hex.EncodeToString(digestSignature))
+
+	t.Run("honors the global freeze", func(t *testing.T) {
+		FreezeSigning("")
+		t.Cleanup(UnfreezeSigning)
+		_, err := signer.SignHash(hash)
+		require.True(t, errors.As(err, &frozen))
+		assert.True(t, frozen.Global)
+	})
+}
@@ -0,0 +1,175 @@
+package constellation
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrParentMismatch indicates a transaction did not chain from the source's last reference
+	ErrParentMismatch = errors.New("transaction parent does not match last reference")
+	// ErrInsufficientBalance indicates the source cannot cover amount plus fee
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
+
+// GenesisReference is the parent reference of an address with no transactions
+var GenesisReference = TransactionReference{
+	Hash:    "0000000000000000000000000000000000000000000000000000000000000000",
+	Ordinal: 0,
+}
+
+// SimulatedLedger is an in-memory Currency L1 and balance source. It lets the
+// withdrawal queue, scenarios and load tests run end to end without a node.
+//
+// Submitted transactions are signature-checked, parent-checked and
+// balance-checked (including pending debits) like a real node. Pending
+// transactions confirm either when Snapshot is called or, to mimic one
+// snapshot round-trip, on the first GetPendingTransaction poll, which reports
+// them as Waiting.
+type SimulatedLedger struct {
+	mu       sync.Mutex
+	ordinal  int64
+	balances map[string]int64
+	lastRefs map[string]TransactionReference
+	pending  map[string]*CurrencyTransaction
+	order    []string
+}
+
+// NewSimulatedLedger creates an empty SimulatedLedger
+func NewSimulatedLedger() *SimulatedLedger {
+	return &SimulatedLedger{
+		balances: map[string]int64{},
+		lastRefs: map[string]TransactionReference{},
+		pending:  map[string]*CurrencyTransaction{},
+	}
+}
+
+// Fund credits amount tokens to address
+func (l *SimulatedLedger) Fund(address string, amount float64) error {
+	units, err := TokenToUnitsChecked(amount)
+	if err != nil {
+		return err
+	}
+	l.Credit(address, units)
+	return nil
+}
+
+// Credit adds units (smallest units) to the confirmed balance of address
+func (l *SimulatedLedger) Credit(address string, units int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.balances[address] += units
+}
+
+// GetBalance returns the confirmed balance of address
+func (l *SimulatedLedger) GetBalance(address string) (*BalanceResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return &BalanceResponse{Ordinal: l.ordinal, Balance: l.balances[address]}, nil
+}
+
+// GetLastReference returns the reference of the last accepted transaction
+func (l *SimulatedLedger) GetLastReference(address string) (*TransactionReference, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ref := l.lastRef(address)
+	return &ref, nil
+}
+
+// PostTransaction validates and accepts a transaction into the pending pool
+func (l *SimulatedLedger) PostTransaction(tx *CurrencyTransaction) (*PostTransactionResponse, error) {
+	if !VerifyCurrencyTransaction(tx).IsValid {
+		return nil, ErrInvalidSignature
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	source := tx.Value.Source
+	if tx.Value.Parent != l.lastRef(source) {
+		return nil, ErrParentMismatch
+	}
+	if l.balances[source]-l.pendingDebits(source) < tx.Value.Amount+tx.Value.Fee {
+		return nil, ErrInsufficientBalance
+	}
+
+	hash := HashCurrencyTransaction(tx).Value
+	l.pending[hash] = tx
+	l.order = append(l.order, hash)
+	l.lastRefs[source] = TransactionReference{
+		Hash:    hash,
+		Ordinal: tx.Value.Parent.Ordinal + 1,
+	}
+	return &PostTransactionResponse{Hash: hash}, nil
+}
+
+// GetPendingTransaction reports a pending transaction and confirms it
+func (l *SimulatedLedger) GetPendingTransaction(hash string) (*PendingTransaction, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tx, ok := l.pending[hash]
+	if !ok {
+		return nil, nil
+	}
+	l.confirm(hash)
+
+	return &PendingTransaction{
+		Hash:        hash,
+		Status:      StatusWaiting,
+		Transaction: *tx,
+	}, nil
+}
+
+// Snapshot confirms every pending transaction in submission order and
+// returns the new snapshot ordinal
+func (l *SimulatedLedger) Snapshot() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, hash := range append([]string(nil), l.order...) {
+		l.confirm(hash)
+	}
+	l.ordinal++
+	return l.ordinal
+}
+
+// PendingCount returns the number of unconfirmed transactions
+func (l *SimulatedLedger) PendingCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.pending)
+}
+
+func (l *SimulatedLedger) confirm(hash string) {
+	tx, ok := l.pending[hash]
+	if !ok {
+		return
+	}
+	delete(l.pending, hash)
+	for i, h := range l.order {
+		if h == hash {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+	l.balances[tx.Value.Source] -= tx.Value.Amount + tx.Value.Fee
+	l.balances[tx.Value.Destination] += tx.Value.Amount
+}
+
+func (l *SimulatedLedger) pendingDebits(address string) int64 {
+	var total int64
+	for _, tx := range l.pending {
+		if tx.Value.Source == address {
+			total += tx.Value.Amount + tx.Value.Fee
+		}
+	}
+	return total
+}
+
+func (l *SimulatedLedger) lastRef(address string) TransactionReference {
+	if ref, ok := l.lastRefs[address]; ok {
+		return ref
+	}
+	return GenesisReference
+}
@@ -0,0 +1,179 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const daysPerYear = 365
+
+// rewardFractionScale is the precision RewardFraction is applied at
+const rewardFractionScale = 1e8
+
+// ErrInvalidRewardParameters indicates staking parameters an estimate
+// cannot be computed from
+var ErrInvalidRewardParameters = errors.New("invalid staking reward parameters")
+
+// StakingRewardsInfo is the network-wide delegated staking reward state
+// served by global L0 nodes at /delegated-stakes/rewards-info
+type StakingRewardsInfo struct {
+	// EpochsPerYear is how many reward epochs the network runs per year
+	EpochsPerYear int64 `json:"epochsPerYear"`
+	// TotalDelegatedAmount is the stake delegated across all validators, in
+	// smallest units (1e-8)
+	TotalDelegatedAmount int64 `json:"totalDelegatedAmount"`
+	// TotalRewardPerEpoch is the reward shared by all delegated stake each
+	// epoch, in smallest units (1e-8)
+	TotalRewardPerEpoch int64 `json:"totalRewardPerEpoch"`
+}
+
+// EpochsPerDay is the average number of reward epochs per day
+func (i StakingRewardsInfo) EpochsPerDay() float64 {
+	return float64(i.EpochsPerYear) / daysPerYear
+}
+
+// NodeParams is a validator's published delegation parameters, as served by
+// global L0 nodes at /node-params
+type NodeParams struct {
+	// PeerID is the node's peer ID (public key without 04 prefix)
+	PeerID string `json:"peerId"`
+	Name   string `json:"name"`
+	// RewardFraction is the share of delegator rewards the operator keeps,
+	// from 0 to 1
+	RewardFraction float64 `json:"rewardFraction"`
+	// TotalAmountDelegated is the stake delegated to the node, in smallest
+	// units (1e-8)
+	TotalAmountDelegated int64 `json:"totalAmountDelegated"`
+}
+
+// StakingParamsSource serves live delegated staking parameters.
+// GlobalL0Client implements this interface.
+type StakingParamsSource interface {
+	GetRewardsInfo() (*StakingRewardsInfo, error)
+	GetNodeParams() ([]NodeParams, error)
+}
+
+// RewardEstimate is the expected reward earned by a stake. Amounts are in
+// smallest units (1e-8) and rounded down.
+type RewardEstimate struct {
+	// Stake is the delegated stake the estimate is for
+	Stake int64
+	// Share is the stake's fraction of all delegated stake
+	Share float64
+	// RewardPerEpoch is what the stake earns each epoch, before the
+	// operator's cut
+	RewardPerEpoch int64
+	// OperatorPerEpoch is the operator's cut of RewardPerEpoch
+	OperatorPerEpoch int64
+	// DelegatorsPerEpoch is what remains of RewardPerEpoch for delegators
+	DelegatorsPerEpoch int64
+	RewardPerDay       int64
+	OperatorPerDay     int64
+	DelegatorsPerDay   int64
+	// DelegatorAPR is the delegators' annual return after the operator's
+	// cut, as a fraction of the stake (0.08 is 8%)
+	DelegatorAPR float64
+}
+
+// EstimateRewards estimates the rewards earned by stake delegated to a
+// validator that keeps rewardFraction of them
+//
+// The stake must already be counted in info.TotalDelegatedAmount. To
+// estimate a delegation that has not been made yet, add it to
+// TotalDelegatedAmount first.
+//
+// Example:
+//
+//	info, _ := l0.GetRewardsInfo()
+//	estimate, err := EstimateRewards(*info, TokenToUnits(250000), 0.05)
+//	fmt.Println(FormatTokenAmount(estimate.OperatorPerDay), "DAG per day to the operator")
+func EstimateRewards(info StakingRewardsInfo, stake int64, rewardFraction float64) (*RewardEstimate, error) {
+	switch {
+	case info.EpochsPerYear <= 0:
+		return nil, fmt.Errorf("%w: epochsPerYear must be positive", ErrInvalidRewardParameters)
+	case info.TotalDelegatedAmount <= 0:
+		return nil, fmt.Errorf("%w: totalDelegatedAmount must be positive", ErrInvalidRewardParameters)
+	case info.TotalRewardPerEpoch < 0:
+		return nil, fmt.Errorf("%w: totalRewardPerEpoch is negative", ErrInvalidRewardParameters)
+	case stake < 0 || stake > info.TotalDelegatedAmount:
+		return nil, fmt.Errorf("%w: stake %d is outside the delegated total %d",
+			ErrInvalidRewardParameters, stake, info.TotalDelegatedAmount)
+	case !(rewardFraction >= 0 && rewardFraction <= 1):
+		return nil, fmt.Errorf("%w: reward fraction %v is outside 0..1", ErrInvalidRewardParameters, rewardFraction)
+	}
+
+	// every amount is gross = totalRewardPerEpoch * stake scaled by exact
+	// ratios, so rounding happens once per figure
+	gross := new(big.Int).Mul(big.NewInt(info.TotalRewardPerEpoch), big.NewInt(stake))
+	operatorGross := new(big.Int).Mul(gross, big.NewInt(int64(math.Round(rewardFraction*rewardFractionScale))))
+	perYear := big.NewInt(info.EpochsPerYear)
+
+	estimate := &RewardEstimate{
+		Stake: stake,
+		Share: float64(stake) / float64(info.TotalDelegatedAmount),
+	}
+	var err error
+	if estimate.RewardPerEpoch, err = divUnits(gross, info.TotalDelegatedAmount); err != nil {
+		return nil, err
+	}
+	if estimate.OperatorPerEpoch, err = divUnits(operatorGross, info.TotalDelegatedAmount, rewardFractionScale); err != nil {
+		return nil, err
+	}
+	estimate.DelegatorsPerEpoch = estimate.RewardPerEpoch - estimate.OperatorPerEpoch
+
+	if estimate.RewardPerDay, err = divUnits(new(big.Int).Mul(gross, perYear), info.TotalDelegatedAmount, daysPerYear); err != nil {
+		return nil, err
+	}
+	estimate.OperatorPerDay, err = divUnits(new(big.Int).Mul(operatorGross, perYear),
+		info.TotalDelegatedAmount, rewardFractionScale, daysPerYear)
+	if err != nil {
+		return nil, err
+	}
+	estimate.DelegatorsPerDay = estimate.RewardPerDay - estimate.OperatorPerDay
+
+	annualRate := float64(info.TotalRewardPerEpoch) * float64(info.EpochsPerYear) / float64(info.TotalDelegatedAmount)
+	estimate.DelegatorAPR = annualRate * (1 - rewardFraction)
+	return estimate, nil
+}
+
+// EstimateNodeRewards estimates the rewards of a validator from its
+// published parameters
+func EstimateNodeRewards(info StakingRewardsInfo, node NodeParams) (*RewardEstimate, error) {
+	return EstimateRewards(info, node.TotalAmountDelegated, node.RewardFraction)
+}
+
+// EstimateNodeRewardsFrom estimates the rewards of the validator with peerID
+// from live parameters
+//
+// Returns nil if the validator has not published delegation parameters.
+func EstimateNodeRewardsFrom(source StakingParamsSource, peerID string) (*RewardEstimate, error) {
+	info, err := source.GetRewardsInfo()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := source.GetNodeParams()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.PeerID == peerID {
+			return EstimateNodeRewards(*info, node)
+		}
+	}
+	return nil, nil
+}
+
+// divUnits divides numerator by the product of denominators, rounding down
+func divUnits(numerator *big.Int, denominators ...int64) (int64, error) {
+	divisor := big.NewInt(1)
+	for _, d := range denominators {
+		divisor.Mul(divisor, big.NewInt(d))
+	}
+	quotient := new(big.Int).Quo(numerator, divisor)
+	if !quotient.IsInt64() {
+		return 0, fmt.Errorf("%w: reward estimate overflows", ErrAmountOutOfRange)
+	}
+	return quotient.Int64(), nil
+}
@@ -0,0 +1,94 @@
+package constellation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStakingSource serves fixed staking parameters
+type fakeStakingSource struct {
+	info  StakingRewardsInfo
+	nodes []NodeParams
+	err   error
+}
+
+func (f *fakeStakingSource) GetRewardsInfo() (*StakingRewardsInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	info := f.info
+	return &info, nil
+}
+
+func (f *fakeStakingSource) GetNodeParams() ([]NodeParams, error) {
+	return f.nodes, f.err
+}
+
+func TestEstimateRewards(t *testing.T) {
+	info := StakingRewardsInfo{
+		EpochsPerYear:        730,
+		TotalDelegatedAmount: TokenToUnits(1000),
+		TotalRewardPerEpoch:  TokenToUnits(10),
+	}
+
+	t.Run("splits the stake's share between operator and delegators", func(t *testing.T) {
+		estimate, err := EstimateRewards(info, TokenToUnits(250), 0.1)
+		require.NoError(t, err)
+		assert.Equal(t, 0.25, estimate.Share)
+		assert.Equal(t, TokenToUnits(2.5), estimate.RewardPerEpoch)
+		assert.Equal(t, TokenToUnits(0.25), estimate.OperatorPerEpoch)
+		assert.Equal(t, TokenToUnits(2.25), estimate.DelegatorsPerEpoch)
+		assert.Equal(t, TokenToUnits(5), estimate.RewardPerDay)
+		assert.Equal(t, TokenToUnits(0.5), estimate.OperatorPerDay)
+		assert.Equal(t, TokenToUnits(4.5), estimate.DelegatorsPerDay)
+		assert.InDelta(t, 6.57, estimate.DelegatorAPR, 1e-9)
+		assert.Equal(t, 2.0, info.EpochsPerDay())
+	})
+
+	t.Run("rounds each figure down once", func(t *testing.T) {
+		estimate, err := EstimateRewards(StakingRewardsInfo{EpochsPerYear: 365, TotalDelegatedAmount: 3, TotalRewardPerEpoch: 10}, 1, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), estimate.RewardPerEpoch)
+		assert.Equal(t, int64(3), estimate.RewardPerDay)
+		assert.Zero(t, estimate.OperatorPerEpoch)
+	})
+
+	t.Run("rejects unusable parameters", func(t *testing.T) {
+		for name, c := range map[string]struct {
+			info     StakingRewardsInfo
+			stake    int64
+			fraction float64
+		}{
+			"no epochs":          {StakingRewardsInfo{TotalDelegatedAmount: 1}, 1, 0},
+			"nothing delegated":  {StakingRewardsInfo{EpochsPerYear: 1}, 0, 0},
+			"negative reward":    {StakingRewardsInfo{EpochsPerYear: 1, TotalDelegatedAmount: 1, TotalRewardPerEpoch: -1}, 1, 0},
+			"stake above total":  {info, info.TotalDelegatedAmount + 1, 0},
+			"negative stake":     {info, -1, 0},
+			"fraction above one": {info, 1, 1.5},
+		} {
+			_, err := EstimateRewards(c.info, c.stake, c.fraction)
+			assert.ErrorIs(t, err, ErrInvalidRewardParameters, name)
+		}
+	})
+
+	t.Run("estimates a validator from live parameters", func(t *testing.T) {
+		source := &fakeStakingSource{info: info, nodes: []NodeParams{
+			{PeerID: "peer1", RewardFraction: 0.05, TotalAmountDelegated: TokenToUnits(500)},
+		}}
+		estimate, err := EstimateNodeRewardsFrom(source, "peer1")
+		require.NoError(t, err)
+		assert.Equal(t, TokenToUnits(5), estimate.RewardPerEpoch)
+		assert.Equal(t, TokenToUnits(0.25), estimate.OperatorPerEpoch)
+
+		estimate, err = EstimateNodeRewardsFrom(source, "unknown")
+		require.NoError(t, err)
+		assert.Nil(t, estimate)
+
+		down := errors.New("down")
+		_, err = EstimateNodeRewardsFrom(&fakeStakingSource{err: down}, "peer1")
+		assert.ErrorIs(t, err, down)
+	})
+}
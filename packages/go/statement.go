@@ -0,0 +1,163 @@
+package constellation
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+)
+
+// SnapshotMetadata identifies the snapshot a transaction was confirmed in
+type SnapshotMetadata struct {
+	Ordinal   int64
+	Hash      string
+	Timestamp time.Time
+}
+
+// Statement is the data behind a payment statement: a confirmed transaction
+// and the snapshot that includes it
+type Statement struct {
+	Hash        string
+	Source      string
+	Destination string
+	// Amount in smallest units (1e-8)
+	Amount int64
+	// Fee in smallest units (1e-8)
+	Fee      int64
+	Parent   TransactionReference
+	Snapshot SnapshotMetadata
+	// Signers are the distinct public key IDs of the transaction's proofs,
+	// normalized to the uncompressed form (see DistinctSigners)
+	Signers []string
+	// Rate optionally annotates the amount with its fiat value
+	Rate *ExchangeRate
+	// GeneratedAt is when the statement was produced
+	GeneratedAt time.Time
+}
+
+// NewStatement builds a Statement for a confirmed transaction
+func NewStatement(tx *CurrencyTransaction, snapshot SnapshotMetadata) *Statement {
+	return &Statement{
+		Hash:        HashCurrencyTransaction(tx).Value,
+		Source:      tx.Value.Source,
+		Destination: tx.Value.Destination,
+		Amount:      tx.Value.Amount,
+		Fee:         tx.Value.Fee,
+		Parent:      tx.Value.Parent,
+		Snapshot:    snapshot,
+		Signers:     DistinctSigners(tx.Proofs),
+		GeneratedAt: time.Now().UTC(),
+	}
+}
+
+// StatementRenderer turns a Statement into a shareable document
+type StatementRenderer interface {
+	Render(w io.Writer, statement *Statement) error
+}
+
+// DefaultTextStatementTemplate is the plain-text statement layout
+const DefaultTextStatementTemplate = `PAYMENT STATEMENT
+=================
+
+Transaction   {{.Hash}}
+From          {{.Source}}
+To            {{.Destination}}
+Amount        {{tokens .Amount}}{{with .Rate}} ({{fiat $.Amount .}}){{end}}
+Fee           {{tokens .Fee}}
+Parent        {{.Parent.Hash}} (ordinal {{.Parent.Ordinal}})
+
+Snapshot      {{.Snapshot.Ordinal}}{{if .Snapshot.Hash}} {{.Snapshot.Hash}}{{end}}
+{{- if not .Snapshot.Timestamp.IsZero}}
+Confirmed at  {{timestamp .Snapshot.Timestamp}}{{end}}
+{{range .Signers}}
+Signed by     {{.}}{{end}}
+
+Generated {{timestamp .GeneratedAt}}
+`
+
+// DefaultHTMLStatementTemplate is the HTML statement layout
+const DefaultHTMLStatementTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Payment statement {{.Hash}}</title></head>
+<body>
+<h1>Payment statement</h1>
+<table>
+<tr><th>Transaction</th><td><code>{{.Hash}}</code></td></tr>
+<tr><th>From</th><td><code>{{.Source}}</code></td></tr>
+<tr><th>To</th><td><code>{{.Destination}}</code></td></tr>
+<tr><th>Amount</th><td>{{tokens .Amount}}{{with .Rate}} ({{fiat $.Amount .}}){{end}}</td></tr>
+<tr><th>Fee</th><td>{{tokens .Fee}}</td></tr>
+<tr><th>Parent</th><td><code>{{.Parent.Hash}}</code> (ordinal {{.Parent.Ordinal}})</td></tr>
+<tr><th>Snapshot</th><td>{{.Snapshot.Ordinal}}{{if .Snapshot.Hash}} <code>{{.Snapshot.Hash}}</code>{{end}}</td></tr>
+{{- if not .Snapshot.Timestamp.IsZero}}
+<tr><th>Confirmed at</th><td>{{timestamp .Snapshot.Timestamp}}</td></tr>{{end}}
+{{- range .Signers}}
+<tr><th>Signed by</th><td><code>{{.}}</code></td></tr>{{end}}
+</table>
+<p><small>Generated {{timestamp .GeneratedAt}}</small></p>
+</body>
+</html>
+`
+
+// statementFuncs are available to every statement template
+var statementFuncs = map[string]interface{}{
+	// tokens formats smallest units as a token amount
+	"tokens": func(units int64) string {
+		return FormatTokenAmount(units)
+	},
+	// fiat formats smallest units at an exchange rate
+	"fiat": func(units int64, rate *ExchangeRate) string {
+		return strconv.FormatFloat(rate.FiatValue(units), 'f', 2, 64) + " " + rate.Fiat
+	},
+	"timestamp": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
+
+// TextStatementRenderer renders statements with a text/template
+type TextStatementRenderer struct {
+	tmpl *texttemplate.Template
+}
+
+// NewTextStatementRenderer parses a text template; an empty string selects
+// DefaultTextStatementTemplate
+func NewTextStatementRenderer(tmpl string) (*TextStatementRenderer, error) {
+	if tmpl == "" {
+		tmpl = DefaultTextStatementTemplate
+	}
+	parsed, err := texttemplate.New("statement").Funcs(statementFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return &TextStatementRenderer{tmpl: parsed}, nil
+}
+
+// Render writes the statement to w
+func (r *TextStatementRenderer) Render(w io.Writer, statement *Statement) error {
+	return r.tmpl.Execute(w, statement)
+}
+
+// HTMLStatementRenderer renders statements with an html/template, escaping
+// all values
+type HTMLStatementRenderer struct {
+	tmpl *htmltemplate.Template
+}
+
+// NewHTMLStatementRenderer parses an HTML template; an empty string selects
+// DefaultHTMLStatementTemplate
+func NewHTMLStatementRenderer(tmpl string) (*HTMLStatementRenderer, error) {
+	if tmpl == "" {
+		tmpl = DefaultHTMLStatementTemplate
+	}
+	parsed, err := htmltemplate.New("statement").Funcs(statementFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return &HTMLStatementRenderer{tmpl: parsed}, nil
+}
+
+// Render writes the statement to w
+func (r *HTMLStatementRenderer) Render(w io.Writer, statement *Statement) error {
+	return r.tmpl.Execute(w, statement)
+}
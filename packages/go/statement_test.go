@@ -0,0 +1,76 @@
+package constellation
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementRenderers(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	dest, _ := GenerateKeyPair()
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: dest.Address, Amount: 12.5, Fee: 0.001}, kp.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+
+	confirmedAt := time.Date(2024, 3, 5, 17, 30, 0, 0, time.UTC)
+	statement := NewStatement(tx, SnapshotMetadata{Ordinal: 1520, Hash: "abc123", Timestamp: confirmedAt})
+	statement.Rate = &ExchangeRate{Asset: "DAG", Fiat: "usd", Rate: 0.1}
+
+	t.Run("text", func(t *testing.T) {
+		renderer, err := NewTextStatementRenderer("")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, renderer.Render(&buf, statement))
+		out := buf.String()
+
+		assert.Contains(t, out, "Transaction   "+HashCurrencyTransaction(tx).Value)
+		assert.Contains(t, out, "Amount        12.5 (1.25 usd)")
+		assert.Contains(t, out, "Fee           0.001")
+		assert.Contains(t, out, "Snapshot      1520 abc123")
+		assert.Contains(t, out, "Confirmed at  2024-03-05T17:30:00Z")
+		assert.Contains(t, out, "Signed by     "+kp.PublicKey[2:])
+	})
+
+	t.Run("html escapes values", func(t *testing.T) {
+		renderer, err := NewHTMLStatementRenderer("")
+		require.NoError(t, err)
+
+		hostile := *statement
+		hostile.Snapshot.Hash = "<script>"
+		var buf bytes.Buffer
+		require.NoError(t, renderer.Render(&buf, &hostile))
+
+		assert.Contains(t, buf.String(), "<h1>Payment statement</h1>")
+		assert.NotContains(t, buf.String(), "<script>")
+	})
+
+	t.Run("custom templates", func(t *testing.T) {
+		var renderer StatementRenderer
+		renderer, err := NewTextStatementRenderer(`{{.Destination}} received {{tokens .Amount}} DAG`)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, renderer.Render(&buf, statement))
+		assert.Equal(t, dest.Address+" received 12.5 DAG", buf.String())
+
+		_, err = NewHTMLStatementRenderer("{{.Missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("formats amounts exactly", func(t *testing.T) {
+		renderer, err := NewTextStatementRenderer(`{{tokens .Amount}}`)
+		require.NoError(t, err)
+
+		// 2^53 + 1 units has no exact float64 representation
+		large := *statement
+		large.Amount = 9007199254740993
+		var buf bytes.Buffer
+		require.NoError(t, renderer.Render(&buf, &large))
+		assert.Equal(t, "90071992.54740993", buf.String())
+	})
+}
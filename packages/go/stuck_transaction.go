@@ -0,0 +1,299 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoAnalyzerNodes indicates a TransactionAnalyzer without nodes
+var ErrNoAnalyzerNodes = errors.New("transaction analyzer needs at least one node")
+
+// explorerDiagnosisLimit is how many of the source's recent transactions are
+// searched on the explorer
+const explorerDiagnosisLimit = 100
+
+// TransactionDiagnosisKind explains why a transaction is, or is not,
+// confirming
+type TransactionDiagnosisKind string
+
+const (
+	// DiagnosisConfirmed means the transaction is already confirmed
+	DiagnosisConfirmed TransactionDiagnosisKind = "AlreadyConfirmed"
+	// DiagnosisPending means the transaction is in a mempool and its parent
+	// is accepted or pending; it only needs time
+	DiagnosisPending TransactionDiagnosisKind = "Pending"
+	// DiagnosisOrphanedParent means the parent is neither accepted nor
+	// pending anywhere, so the transaction can never be accepted
+	DiagnosisOrphanedParent TransactionDiagnosisKind = "OrphanedParent"
+	// DiagnosisNeverPropagated means no node has the transaction, its
+	// ordinal is still free and the explorer, if any, has not confirmed it;
+	// it was lost before reaching the network
+	DiagnosisNeverPropagated TransactionDiagnosisKind = "NeverPropagated"
+	// DiagnosisRejected means another transaction took the ordinal
+	DiagnosisRejected TransactionDiagnosisKind = "Rejected"
+	// DiagnosisUndetermined means the ordinal is consumed but, without an
+	// explorer, it is unknown by which transaction, or that no node holds a
+	// transaction known only by hash and no explorer can look it up
+	DiagnosisUndetermined TransactionDiagnosisKind = "Undetermined"
+)
+
+// TransactionDiagnosis is the result of DiagnoseTransaction
+type TransactionDiagnosis struct {
+	Hash   string
+	Kind   TransactionDiagnosisKind
+	Reason string
+	// Transaction is the diagnosed transaction, if a node or the caller had it
+	Transaction *CurrencyTransaction
+	// SeenBy lists the nodes whose mempool holds the transaction
+	SeenBy []string
+	// MissingFrom lists the nodes that answered but do not hold it
+	MissingFrom []string
+	// LastReference is the most advanced last reference any node reported
+	// for the source
+	LastReference *TransactionReference
+	// ConflictingHash is the transaction that took the ordinal, if known
+	ConflictingHash string
+	// SnapshotOrdinal is the confirming snapshot, if the explorer knows it
+	SnapshotOrdinal int64
+	// NodeErrors holds the nodes that could not be queried
+	NodeErrors map[string]error
+}
+
+// TransactionAnalyzer explains why transactions are not confirming by
+// cross-checking several nodes' mempools, the source's parent chain and,
+// optionally, the block explorer
+//
+// Example:
+//
+//	analyzer := &TransactionAnalyzer{
+//	    Nodes:    map[string]CurrencyL1API{"node-1": l1a, "node-2": l1b},
+//	    Explorer: explorer,
+//	}
+//	diagnosis, err := analyzer.DiagnoseTransaction(hash)
+//	fmt.Println(diagnosis.Kind, diagnosis.Reason)
+type TransactionAnalyzer struct {
+	// Nodes are the L1 nodes to query, keyed by a name used in the diagnosis
+	Nodes map[string]CurrencyL1API
+	// Explorer is optional; it tells confirmed transactions apart from
+	// rejected or lost ones once no node holds them. Looking a transaction
+	// up by hash needs an explorer implementing ExplorerTransactionLookup.
+	Explorer ExplorerAPI
+}
+
+// DiagnoseTransaction diagnoses a transaction by hash. Only nodes still
+// holding the transaction can say what it was; when none does, use
+// DiagnoseSignedTransaction for a full diagnosis.
+func (a *TransactionAnalyzer) DiagnoseTransaction(hash string) (*TransactionDiagnosis, error) {
+	return a.diagnose(hash, nil)
+}
+
+// DiagnoseSignedTransaction diagnoses a transaction the caller still holds
+func (a *TransactionAnalyzer) DiagnoseSignedTransaction(tx *CurrencyTransaction) (*TransactionDiagnosis, error) {
+	return a.diagnose(HashCurrencyTransaction(tx).Value, tx)
+}
+
+func (a *TransactionAnalyzer) diagnose(hash string, tx *CurrencyTransaction) (*TransactionDiagnosis, error) {
+	if len(a.Nodes) == 0 {
+		return nil, ErrNoAnalyzerNodes
+	}
+	diagnosis := &TransactionDiagnosis{Hash: hash, Transaction: tx, NodeErrors: map[string]error{}}
+
+	names := make([]string, 0, len(a.Nodes))
+	for name := range a.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pending, err := a.Nodes[name].GetPendingTransaction(hash)
+		switch {
+		case err != nil:
+			diagnosis.NodeErrors[name] = err
+		case pending == nil:
+			diagnosis.MissingFrom = append(diagnosis.MissingFrom, name)
+		default:
+			diagnosis.SeenBy = append(diagnosis.SeenBy, name)
+			if diagnosis.Transaction == nil {
+				found := pending.Transaction
+				diagnosis.Transaction = &found
+			}
+		}
+	}
+	if len(diagnosis.NodeErrors) == len(names) {
+		return nil, diagnosis.NodeErrors[names[0]]
+	}
+
+	if diagnosis.Transaction == nil {
+		// a confirmed transaction leaves the mempool too
+		lookup, ok := a.Explorer.(ExplorerTransactionLookup)
+		if !ok {
+			diagnosis.Kind = DiagnosisUndetermined
+			diagnosis.Reason = "no node holds the transaction; configure an Explorer or pass it to DiagnoseSignedTransaction"
+			return diagnosis, nil
+		}
+		confirmed, err := lookup.GetTransaction(hash)
+		if err != nil {
+			return nil, err
+		}
+		if confirmed != nil {
+			diagnosis.confirmed(confirmed.SnapshotOrdinal)
+			return diagnosis, nil
+		}
+		diagnosis.Kind = DiagnosisNeverPropagated
+		diagnosis.Reason = "neither a node nor the explorer knows the transaction; resubmit it"
+		return diagnosis, nil
+	}
+
+	source := diagnosis.Transaction.Value.Source
+	parent := diagnosis.Transaction.Value.Parent
+	ordinal := parent.Ordinal + 1
+
+	for _, name := range names {
+		if diagnosis.NodeErrors[name] != nil {
+			continue
+		}
+		ref, err := a.Nodes[name].GetLastReference(source)
+		if err != nil {
+			diagnosis.NodeErrors[name] = err
+			continue
+		}
+		if diagnosis.LastReference == nil || ref.Ordinal > diagnosis.LastReference.Ordinal {
+			diagnosis.LastReference = ref
+		}
+	}
+	if diagnosis.LastReference == nil {
+		return nil, diagnosis.NodeErrors[names[0]]
+	}
+	last := *diagnosis.LastReference
+
+	if last.Ordinal >= ordinal {
+		return a.diagnoseConsumed(diagnosis, source, parent, ordinal)
+	}
+
+	switch {
+	case parent == last:
+		// parent accepted; the transaction only has to reach a node
+	case parent.Ordinal == last.Ordinal:
+		diagnosis.Kind = DiagnosisOrphanedParent
+		diagnosis.ConflictingHash = last.Hash
+		diagnosis.Reason = fmt.Sprintf("parent %s lost ordinal %d to %s", parent.Hash, parent.Ordinal, last.Hash)
+		return diagnosis, nil
+	default:
+		if !a.anyPending(names, parent.Hash) {
+			diagnosis.Kind = DiagnosisOrphanedParent
+			diagnosis.Reason = fmt.Sprintf("parent %s at ordinal %d is neither accepted nor pending; see DiagnoseChain", parent.Hash, parent.Ordinal)
+			return diagnosis, nil
+		}
+	}
+
+	if len(diagnosis.SeenBy) == 0 {
+		if a.Explorer != nil {
+			confirmed, err := a.findConfirmed(source, hash)
+			if err != nil {
+				return nil, err
+			}
+			if confirmed != nil {
+				// the nodes' last reference lags the explorer
+				diagnosis.confirmed(confirmed.SnapshotOrdinal)
+				return diagnosis, nil
+			}
+		}
+		diagnosis.Kind = DiagnosisNeverPropagated
+		diagnosis.Reason = fmt.Sprintf("ordinal %d is free but no node holds the transaction; resubmit it", ordinal)
+		return diagnosis, nil
+	}
+	diagnosis.Kind = DiagnosisPending
+	if parent == last {
+		diagnosis.Reason = "parent is accepted; waiting for a snapshot"
+	} else {
+		diagnosis.Reason = fmt.Sprintf("waiting for pending parent %s", parent.Hash)
+	}
+	return diagnosis, nil
+}
+
+// diagnoseConsumed handles a transaction whose ordinal the source's chain
+// has already passed
+func (a *TransactionAnalyzer) diagnoseConsumed(diagnosis *TransactionDiagnosis, source string, parent TransactionReference, ordinal int) (*TransactionDiagnosis, error) {
+	last := *diagnosis.LastReference
+	if last.Ordinal == ordinal {
+		if last.Hash == diagnosis.Hash {
+			diagnosis.Kind = DiagnosisConfirmed
+			diagnosis.Reason = "transaction is the source's last reference"
+		} else {
+			diagnosis.Kind = DiagnosisRejected
+			diagnosis.ConflictingHash = last.Hash
+			diagnosis.Reason = fmt.Sprintf("ordinal %d was taken by %s", ordinal, last.Hash)
+		}
+		if a.Explorer == nil {
+			return diagnosis, nil
+		}
+	}
+
+	if a.Explorer == nil {
+		diagnosis.Kind = DiagnosisUndetermined
+		diagnosis.Reason = fmt.Sprintf("ordinal %d is consumed; configure an Explorer to tell whether by this transaction", ordinal)
+		return diagnosis, nil
+	}
+
+	history, err := a.Explorer.GetTransactionsByAddress(source, explorerDiagnosisLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, confirmed := range history {
+		switch {
+		case confirmed.Hash == diagnosis.Hash:
+			diagnosis.confirmed(confirmed.SnapshotOrdinal)
+			return diagnosis, nil
+		case confirmed.Source == source && confirmed.Parent == parent:
+			diagnosis.Kind = DiagnosisRejected
+			diagnosis.ConflictingHash = confirmed.Hash
+			diagnosis.SnapshotOrdinal = confirmed.SnapshotOrdinal
+			diagnosis.Reason = fmt.Sprintf("ordinal %d was taken by %s", ordinal, confirmed.Hash)
+			return diagnosis, nil
+		}
+	}
+
+	if diagnosis.Kind == "" {
+		diagnosis.Kind = DiagnosisUndetermined
+		diagnosis.Reason = fmt.Sprintf("ordinal %d is consumed but the explorer's recent history does not show by which transaction", ordinal)
+	}
+	return diagnosis, nil
+}
+
+// findConfirmed looks hash up on the explorer, by hash when it supports
+// that and otherwise in source's recent history; nil if not confirmed
+func (a *TransactionAnalyzer) findConfirmed(source string, hash string) (*ExplorerTransaction, error) {
+	if lookup, ok := a.Explorer.(ExplorerTransactionLookup); ok {
+		return lookup.GetTransaction(hash)
+	}
+	history, err := a.Explorer.GetTransactionsByAddress(source, explorerDiagnosisLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, confirmed := range history {
+		if confirmed.Hash == hash {
+			found := confirmed
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// confirmed marks the diagnosis confirmed in snapshot
+func (d *TransactionDiagnosis) confirmed(snapshot int64) {
+	d.Kind = DiagnosisConfirmed
+	d.ConflictingHash = ""
+	d.SnapshotOrdinal = snapshot
+	d.Reason = fmt.Sprintf("confirmed in snapshot %d", snapshot)
+}
+
+// anyPending reports whether any node's mempool holds hash
+func (a *TransactionAnalyzer) anyPending(names []string, hash string) bool {
+	for _, name := range names {
+		if pending, err := a.Nodes[name].GetPendingTransaction(hash); err == nil && pending != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package constellation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// poolNode is a CurrencyL1API with a fixed last reference and mempool
+type poolNode struct {
+	CurrencyL1API
+	last TransactionReference
+	pool map[string]*CurrencyTransaction
+	err  error
+}
+
+func (n *poolNode) GetLastReference(address string) (*TransactionReference, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	ref := n.last
+	return &ref, nil
+}
+
+func (n *poolNode) GetPendingTransaction(hash string) (*PendingTransaction, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	tx, ok := n.pool[hash]
+	if !ok {
+		return nil, nil
+	}
+	return &PendingTransaction{Hash: hash, Status: StatusWaiting, Transaction: *tx}, nil
+}
+
+func TestTransactionAnalyzer(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	destination, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	genesis := TransactionReference{Hash: "0000000000000000000000000000000000000000000000000000000000000000", Ordinal: 4}
+	batch, err := CreateCurrencyTransactionBatch([]TransferParams{
+		{Destination: destination.Address, Amount: 1},
+		{Destination: destination.Address, Amount: 2},
+	}, keyPair.PrivateKey, genesis)
+	require.NoError(t, err)
+	first, second := batch[0], batch[1]
+	firstRef := *GetTransactionReference(first, 5)
+
+	t.Run("pending behind an accepted parent", func(t *testing.T) {
+		analyzer := &TransactionAnalyzer{Nodes: map[string]CurrencyL1API{
+			"a": &poolNode{last: genesis, pool: map[string]*CurrencyTransaction{firstRef.Hash: first}},
+			"b": &poolNode{last: genesis},
+		}}
+		diagnosis, err := analyzer.DiagnoseTransaction(firstRef.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisPending, diagnosis.Kind)
+		assert.Equal(t, []string{"a"}, diagnosis.SeenBy)
+		assert.Equal(t, []string{"b"}, diagnosis.MissingFrom)
+	})
+
+	t.Run("orphaned parent", func(t *testing.T) {
+		hash := HashCurrencyTransaction(second).Value
+		analyzer := &TransactionAnalyzer{Nodes: map[string]CurrencyL1API{
+			"a": &poolNode{last: genesis, pool: map[string]*CurrencyTransaction{hash: second}},
+		}}
+		diagnosis, err := analyzer.DiagnoseTransaction(hash)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisOrphanedParent, diagnosis.Kind)
+		assert.Equal(t, second.Value, diagnosis.Transaction.Value)
+	})
+
+	t.Run("never propagated", func(t *testing.T) {
+		analyzer := &TransactionAnalyzer{Nodes: map[string]CurrencyL1API{"a": &poolNode{last: genesis}}}
+		diagnosis, err := analyzer.DiagnoseSignedTransaction(first)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisNeverPropagated, diagnosis.Kind)
+
+		// by hash alone, only an explorer can rule out confirmation
+		diagnosis, err = analyzer.DiagnoseTransaction(firstRef.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisUndetermined, diagnosis.Kind)
+		assert.Nil(t, diagnosis.Transaction)
+
+		analyzer.Explorer = &fakeExplorer{}
+		diagnosis, err = analyzer.DiagnoseTransaction(firstRef.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisNeverPropagated, diagnosis.Kind)
+	})
+
+	t.Run("confirmed and gone from every mempool", func(t *testing.T) {
+		explorer := &fakeExplorer{transactions: map[string][]ExplorerTransaction{
+			keyPair.Address: {{Hash: firstRef.Hash, Source: keyPair.Address, Parent: genesis, SnapshotOrdinal: 42}},
+		}}
+		analyzer := &TransactionAnalyzer{Nodes: map[string]CurrencyL1API{"a": &poolNode{last: genesis}}, Explorer: explorer}
+		diagnosis, err := analyzer.DiagnoseTransaction(firstRef.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisConfirmed, diagnosis.Kind)
+		assert.Equal(t, int64(42), diagnosis.SnapshotOrdinal)
+
+		// a node lagging the explorer does not make it look lost
+		diagnosis, err = analyzer.DiagnoseSignedTransaction(first)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisConfirmed, diagnosis.Kind)
+	})
+
+	t.Run("already confirmed", func(t *testing.T) {
+		analyzer := &TransactionAnalyzer{Nodes: map[string]CurrencyL1API{"a": &poolNode{last: firstRef}}}
+		diagnosis, err := analyzer.DiagnoseSignedTransaction(first)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisConfirmed, diagnosis.Kind)
+	})
+
+	t.Run("rejected by a conflicting transaction", func(t *testing.T) {
+		analyzer := &TransactionAnalyzer{Nodes: map[string]CurrencyL1API{"a": &poolNode{last: TransactionReference{Hash: "other", Ordinal: 5}}}}
+		diagnosis, err := analyzer.DiagnoseSignedTransaction(first)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisRejected, diagnosis.Kind)
+		assert.Equal(t, "other", diagnosis.ConflictingHash)
+	})
+
+	t.Run("explorer settles consumed ordinals", func(t *testing.T) {
+		nodes := map[string]CurrencyL1API{"a": &poolNode{last: TransactionReference{Hash: "later", Ordinal: 9}}}
+
+		analyzer := &TransactionAnalyzer{Nodes: nodes}
+		diagnosis, err := analyzer.DiagnoseSignedTransaction(first)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisUndetermined, diagnosis.Kind)
+
+		explorer := &fakeExplorer{transactions: map[string][]ExplorerTransaction{
+			keyPair.Address: {{Hash: firstRef.Hash, Source: keyPair.Address, Parent: genesis, SnapshotOrdinal: 42}},
+		}}
+		analyzer.Explorer = explorer
+		diagnosis, err = analyzer.DiagnoseSignedTransaction(first)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisConfirmed, diagnosis.Kind)
+		assert.Equal(t, int64(42), diagnosis.SnapshotOrdinal)
+
+		explorer.transactions[keyPair.Address] = []ExplorerTransaction{{Hash: "winner", Source: keyPair.Address, Parent: genesis}}
+		diagnosis, err = analyzer.DiagnoseSignedTransaction(first)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisRejected, diagnosis.Kind)
+		assert.Equal(t, "winner", diagnosis.ConflictingHash)
+	})
+
+	t.Run("tolerates unreachable nodes", func(t *testing.T) {
+		down := errors.New("connection refused")
+		analyzer := &TransactionAnalyzer{Nodes: map[string]CurrencyL1API{
+			"a": &poolNode{last: genesis, pool: map[string]*CurrencyTransaction{firstRef.Hash: first}},
+			"b": &poolNode{err: down},
+		}}
+		diagnosis, err := analyzer.DiagnoseTransaction(firstRef.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, DiagnosisPending, diagnosis.Kind)
+		assert.Equal(t, down, diagnosis.NodeErrors["b"])
+
+		analyzer.Nodes = map[string]CurrencyL1API{"b": &poolNode{err: down}}
+		_, err = analyzer.DiagnoseTransaction(firstRef.Hash)
+		assert.Equal(t, down, err)
+
+		_, err = (&TransactionAnalyzer{}).DiagnoseTransaction(firstRef.Hash)
+		assert.ErrorIs(t, err, ErrNoAnalyzerNodes)
+	})
+}
@@ -0,0 +1,448 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSweepInterval = time.Minute
+	defaultSweepTimeout  = 15 * time.Minute
+)
+
+var (
+	// ErrInvalidSweepConfig indicates a SweeperConfig that cannot sweep
+	ErrInvalidSweepConfig = errors.New("invalid sweep config")
+	// ErrSweepNotFound indicates approving or rejecting a sweep that is not
+	// awaiting approval
+	ErrSweepNotFound = errors.New("sweep not awaiting approval")
+	// ErrNoSweepQueue indicates a sweep from a wallet no queue sends from
+	ErrNoSweepQueue = errors.New("no withdrawal queue for sweep source")
+	// ErrSweepStale indicates approving a sweep whose wallet is no longer
+	// above Threshold
+	ErrSweepStale = errors.New("sweep no longer covered by the balance")
+)
+
+// Sweep moves a hot wallet's excess balance to the cold address
+type Sweep struct {
+	// ID identifies the sweep, for approvals and receipts
+	ID          string
+	Source      string
+	Destination string
+	// Amount in smallest units (1e-8)
+	Amount int64
+	// Fee in smallest units (1e-8)
+	Fee int64
+	// Balance is the hot wallet's balance the sweep was sized from
+	Balance int64
+	// ProposedAt is when the sweeper built the sweep
+	ProposedAt time.Time
+}
+
+// WithdrawalRequest returns the sweep as a request for the hot wallet's
+// WithdrawalQueue
+func (s Sweep) WithdrawalRequest() WithdrawalRequest {
+	return WithdrawalRequest{
+		ID:          s.ID,
+		Destination: s.Destination,
+		Amount:      s.Amount,
+		Fee:         s.Fee,
+		Origin:      "sweeper",
+	}
+}
+
+// SweeperConfig holds configuration for a Sweeper
+type SweeperConfig struct {
+	// Balances looks up hot wallet balances, e.g. a CurrencyL0Client
+	Balances BalanceSource
+	// Wallets are the hot wallet addresses to watch
+	Wallets []string
+	// ColdAddress receives the sweeps
+	ColdAddress string
+	// Threshold is the balance in smallest units above which a wallet is
+	// swept
+	Threshold int64
+	// Target is the balance in smallest units a sweep leaves behind
+	// (default: Threshold). Setting it below Threshold adds hysteresis: a
+	// swept wallet has room to receive before it is swept again.
+	Target int64
+	// MinSweep skips sweeps smaller than this many smallest units
+	// (default: 0, any amount)
+	MinSweep int64
+	// Fee per sweep in smallest units, paid by the hot wallet
+	Fee int64
+	// Interval is the delay between balance checks (default: 1m)
+	Interval time.Duration
+	// Cooldown is the least time between two sweeps of the same wallet
+	// (default: 0)
+	Cooldown time.Duration
+	// SweepTimeout is how long a submitted sweep holds its wallet. A sweep
+	// that failed after Submit returned, e.g. one the queue rejected, never
+	// lowers the balance, so after this long the wallet is swept again
+	// (default: 15m).
+	SweepTimeout time.Duration
+	// Schedule, if set, reports whether sweeps may be proposed at a given
+	// time, e.g. within business hours. Checks outside it do nothing.
+	Schedule func(now time.Time) bool
+	// RequireApproval holds each sweep until Approve is called
+	RequireApproval bool
+	// Submit sends a sweep on, e.g. EnqueueSweeps. A failed sweep is
+	// proposed again at the next check.
+	Submit func(sweep Sweep) error
+	// OnError receives balance lookup and Submit failures; the sweeper keeps
+	// running
+	OnError func(err error)
+}
+
+// sweepWallet is the sweep state of one hot wallet
+type sweepWallet struct {
+	// swept is set once a sweep is proposed and cleared when the balance
+	// is seen at or below Threshold, so one excess is swept once
+	swept     bool
+	lastSweep time.Time
+	// submittedAt is when the sweep was submitted, zero while it awaits
+	// approval; swept expires SweepTimeout after it
+	submittedAt time.Time
+	// pending is the ID of the wallet's sweep awaiting approval, if any
+	pending string
+}
+
+// Sweeper keeps hot wallet balances below a threshold by sweeping the excess
+// to a cold address. Every Interval it checks each wallet's balance, and a
+// wallet above Threshold gets a Sweep down to Target. Sweeps go to Submit,
+// or wait in Pending for Approve when RequireApproval is set.
+//
+// A wallet is swept once per excess: it is not swept again until its
+// balance has been seen at or below Threshold, so a sweep that has not yet
+// confirmed is not repeated. A sweep still not reflected in the balance
+// after SweepTimeout is assumed lost and the wallet is swept again. Cooldown
+// and Schedule further limit how often and when sweeps happen.
+//
+// A wallet has at most one sweep awaiting approval, and it is dropped once
+// the balance is seen at or below Threshold. Approve re-reads the balance
+// and never submits more than the current excess.
+//
+// Sweeper implements the Service lifecycle.
+//
+// Example:
+//
+//	sweeper, err := NewSweeper(ctx, SweeperConfig{
+//	    Balances:        l0Client,
+//	    Wallets:         []string{hotQueue.Source()},
+//	    ColdAddress:     "DAG...",
+//	    Threshold:       TokenToUnits(50_000),
+//	    Target:          TokenToUnits(20_000),
+//	    RequireApproval: true,
+//	    Submit:          EnqueueSweeps(hotQueue),
+//	})
+//	// later, from the ops console
+//	for _, sweep := range sweeper.Pending() {
+//	    err = sweeper.Approve(sweep.ID)
+//	}
+type Sweeper struct {
+	serviceState
+
+	ctx    context.Context
+	config SweeperConfig
+
+	// checkMu serializes checks from the loop and from Check
+	checkMu sync.Mutex
+
+	mu      sync.Mutex
+	closing bool
+	cancel  context.CancelFunc
+	wallets map[string]*sweepWallet
+	pending map[string]Sweep
+}
+
+// NewSweeper creates a sweeper and starts checking balances
+func NewSweeper(ctx context.Context, config SweeperConfig) (*Sweeper, error) {
+	switch {
+	case config.Balances == nil:
+		return nil, fmt.Errorf("%w: no balance source", ErrInvalidSweepConfig)
+	case config.Submit == nil:
+		return nil, fmt.Errorf("%w: no Submit", ErrInvalidSweepConfig)
+	case len(config.Wallets) == 0:
+		return nil, fmt.Errorf("%w: no wallets", ErrInvalidSweepConfig)
+	case !IsValidDAGAddress(config.ColdAddress):
+		return nil, fmt.Errorf("%w: cold address: %v", ErrInvalidSweepConfig, ErrInvalidAddress)
+	case config.Threshold <= 0:
+		return nil, fmt.Errorf("%w: threshold must be positive", ErrInvalidSweepConfig)
+	case config.Target < 0 || config.Target > config.Threshold:
+		return nil, fmt.Errorf("%w: target must be between 0 and the threshold", ErrInvalidSweepConfig)
+	case config.Fee < 0:
+		return nil, fmt.Errorf("%w: negative fee", ErrInvalidSweepConfig)
+	}
+	if config.Target == 0 {
+		config.Target = config.Threshold
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultSweepInterval
+	}
+	if config.SweepTimeout <= 0 {
+		config.SweepTimeout = defaultSweepTimeout
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s := &Sweeper{
+		ctx:     runCtx,
+		config:  config,
+		cancel:  cancel,
+		wallets: map[string]*sweepWallet{},
+		pending: map[string]Sweep{},
+	}
+	for _, wallet := range config.Wallets {
+		s.wallets[wallet] = &sweepWallet{}
+	}
+	s.start()
+
+	go s.run(ctx)
+	return s, nil
+}
+
+// Close stops checking balances and blocks until the sweeper has stopped.
+// Sweeps awaiting approval are dropped.
+func (s *Sweeper) Close() error {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+
+	s.cancel()
+	<-s.Done()
+	return s.Err()
+}
+
+func (s *Sweeper) run(parent context.Context) {
+	for {
+		s.Check()
+
+		timer := time.NewTimer(s.config.Interval)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if s.ctx.Err() != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	closing := s.closing
+	s.mu.Unlock()
+	if closing {
+		s.finish(nil)
+		return
+	}
+	s.finish(parent.Err())
+}
+
+// Check checks every wallet now instead of waiting for the next Interval,
+// and returns the sweeps it proposed
+func (s *Sweeper) Check() []Sweep {
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+
+	now := time.Now()
+	if s.config.Schedule != nil && !s.config.Schedule(now) {
+		return nil
+	}
+	var proposed []Sweep
+	for _, wallet := range s.config.Wallets {
+		if s.ctx.Err() != nil {
+			break
+		}
+		balance, err := s.config.Balances.GetBalance(wallet)
+		if err != nil {
+			s.reportError(fmt.Errorf("checking %s: %w", RedactAddress(wallet), err))
+			continue
+		}
+		var units int64
+		if balance != nil {
+			units = balance.Balance
+		}
+		if sweep, ok := s.propose(wallet, units, now); ok {
+			proposed = append(proposed, sweep)
+		}
+	}
+	return proposed
+}
+
+// propose sizes a sweep of wallet and submits it or holds it for approval
+func (s *Sweeper) propose(wallet string, balance int64, now time.Time) (Sweep, bool) {
+	s.mu.Lock()
+	state := s.wallets[wallet]
+	if balance <= s.config.Threshold {
+		state.swept = false
+		s.dropPending(state)
+		s.mu.Unlock()
+		return Sweep{}, false
+	}
+	if state.swept && !state.submittedAt.IsZero() && now.Sub(state.submittedAt) >= s.config.SweepTimeout {
+		state.swept = false
+	}
+	amount := s.excess(balance)
+	if state.swept || now.Sub(state.lastSweep) < s.config.Cooldown ||
+		amount <= 0 || amount < s.config.MinSweep {
+		s.mu.Unlock()
+		return Sweep{}, false
+	}
+
+	sweep := Sweep{
+		ID:          fmt.Sprintf("sweep-%s-%d", wallet, now.UnixNano()),
+		Source:      wallet,
+		Destination: s.config.ColdAddress,
+		Amount:      amount,
+		Fee:         s.config.Fee,
+		Balance:     balance,
+		ProposedAt:  now,
+	}
+	state.swept = true
+	state.lastSweep = now
+	state.submittedAt = time.Time{}
+	if s.config.RequireApproval {
+		s.dropPending(state)
+		s.pending[sweep.ID] = sweep
+		state.pending = sweep.ID
+		s.mu.Unlock()
+		return sweep, true
+	}
+	s.mu.Unlock()
+
+	s.submit(sweep)
+	return sweep, true
+}
+
+// excess is the amount a sweep of balance moves, after the fee
+func (s *Sweeper) excess(balance int64) int64 {
+	return balance - s.config.Target - s.config.Fee
+}
+
+// dropPending removes the wallet's sweep awaiting approval. Callers hold mu.
+func (s *Sweeper) dropPending(state *sweepWallet) {
+	if state.pending != "" {
+		delete(s.pending, state.pending)
+		state.pending = ""
+	}
+}
+
+// submit hands sweep to Submit, re-arming its wallet if that fails and
+// starting its SweepTimeout otherwise
+func (s *Sweeper) submit(sweep Sweep) error {
+	err := s.config.Submit(sweep)
+	if err != nil {
+		s.rearm(sweep.Source)
+		s.reportError(fmt.Errorf("submitting %s: %w", sweep.ID, err))
+		return err
+	}
+	s.mu.Lock()
+	s.wallets[sweep.Source].submittedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Sweeper) rearm(wallet string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wallets[wallet].swept = false
+	s.wallets[wallet].lastSweep = time.Time{}
+}
+
+// Pending returns the sweeps awaiting approval, oldest first
+func (s *Sweeper) Pending() []Sweep {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sweeps := make([]Sweep, 0, len(s.pending))
+	for _, sweep := range s.pending {
+		sweeps = append(sweeps, sweep)
+	}
+	sort.Slice(sweeps, func(i, j int) bool {
+		return sweeps[i].ProposedAt.Before(sweeps[j].ProposedAt)
+	})
+	return sweeps
+}
+
+// Approve submits a sweep awaiting approval. It re-reads the wallet's
+// balance first: a sweep larger than the current excess is shrunk to it, and
+// one whose wallet is no longer above Threshold, or whose excess fell below
+// MinSweep, is dropped with ErrSweepStale. Returns ErrSweepNotFound for an
+// unknown ID, the lookup error with the sweep still pending if the balance
+// cannot be read, and Submit's error if submitting fails.
+func (s *Sweeper) Approve(id string) error {
+	s.mu.Lock()
+	sweep, ok := s.pending[id]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSweepNotFound, id)
+	}
+	balance, err := s.config.Balances.GetBalance(sweep.Source)
+	if err != nil {
+		return fmt.Errorf("checking %s: %w", RedactAddress(sweep.Source), err)
+	}
+	var units int64
+	if balance != nil {
+		units = balance.Balance
+	}
+
+	if sweep, ok = s.take(id); !ok {
+		return fmt.Errorf("%w: %s", ErrSweepNotFound, id)
+	}
+	amount := s.excess(units)
+	if units <= s.config.Threshold || amount <= 0 || amount < s.config.MinSweep {
+		s.rearm(sweep.Source)
+		return fmt.Errorf("%w: %s has %d units", ErrSweepStale, id, units)
+	}
+	if amount < sweep.Amount {
+		sweep.Amount = amount
+		sweep.Balance = units
+	}
+	return s.submit(sweep)
+}
+
+// Reject drops a sweep awaiting approval. Its wallet is proposed for
+// sweeping again at the next check if it is still above Threshold.
+func (s *Sweeper) Reject(id string) error {
+	sweep, ok := s.take(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSweepNotFound, id)
+	}
+	s.rearm(sweep.Source)
+	return nil
+}
+
+func (s *Sweeper) take(id string) (Sweep, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sweep, ok := s.pending[id]
+	if ok {
+		s.dropPending(s.wallets[sweep.Source])
+	}
+	return sweep, ok
+}
+
+func (s *Sweeper) reportError(err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(err)
+	}
+}
+
+// EnqueueSweeps returns a Sweeper Submit that enqueues each sweep on the
+// queue sending from the sweep's source, so the queue signs and chains it
+// with the wallet's other withdrawals
+func EnqueueSweeps(queues ...*WithdrawalQueue) func(sweep Sweep) error {
+	bySource := make(map[string]*WithdrawalQueue, len(queues))
+	for _, queue := range queues {
+		bySource[queue.Source()] = queue
+	}
+	return func(sweep Sweep) error {
+		queue, ok := bySource[sweep.Source]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNoSweepQueue, RedactAddress(sweep.Source))
+		}
+		return queue.Enqueue(sweep.WithdrawalRequest())
+	}
+}
@@ -0,0 +1,243 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingBalances counts balance lookups, so tests can wait for a
+// sweeper's first check
+type countingBalances struct {
+	BalanceSource
+	lookups int32
+}
+
+func (c *countingBalances) GetBalance(address string) (*BalanceResponse, error) {
+	atomic.AddInt32(&c.lookups, 1)
+	return c.BalanceSource.GetBalance(address)
+}
+
+func TestSweeper(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	cold, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	// newSweeper starts a sweeper over ledger and waits for its first check
+	newSweeper := func(t *testing.T, ledger *SimulatedLedger, config SweeperConfig) *Sweeper {
+		balances := &countingBalances{BalanceSource: ledger}
+		config.Balances = balances
+		config.Wallets = []string{hot.Address}
+		config.ColdAddress = cold.Address
+		config.Interval = time.Hour
+		sweeper, err := NewSweeper(context.Background(), config)
+		require.NoError(t, err)
+		t.Cleanup(func() { sweeper.Close() })
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&balances.lookups) > 0 }, time.Second, time.Millisecond)
+		return sweeper
+	}
+
+	t.Run("sweeps down to the target once per excess", func(t *testing.T) {
+		ledger := NewSimulatedLedger()
+		ledger.Credit(hot.Address, 150)
+		receipts := make(chan WithdrawalReceipt, 2)
+		queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+			L1:         ledger,
+			PrivateKey: hot.PrivateKey,
+			OnReceipt:  func(r WithdrawalReceipt) { receipts <- r },
+		})
+		require.NoError(t, err)
+
+		sweeper := newSweeper(t, ledger, SweeperConfig{
+			Threshold: 100,
+			Target:    40,
+			Fee:       1,
+			Submit:    EnqueueSweeps(queue),
+		})
+		// the first check swept 150 - 40 - 1; the sweep is still pending, so
+		// the unchanged balance is not swept again
+		assert.Empty(t, sweeper.Check())
+		receipt := <-receipts
+		assert.Equal(t, WithdrawalSubmitted, receipt.Status)
+		assert.Equal(t, int64(109), receipt.Request.Amount)
+		ledger.Snapshot()
+		assert.Empty(t, sweeper.Check())
+
+		// a deposit within the hysteresis band is left alone
+		ledger.Credit(hot.Address, 50)
+		assert.Empty(t, sweeper.Check())
+		ledger.Credit(hot.Address, 20)
+		sweeps := sweeper.Check()
+		require.Len(t, sweeps, 1)
+		assert.Equal(t, int64(110), sweeps[0].Balance)
+		assert.Equal(t, int64(69), sweeps[0].Amount)
+		assert.Equal(t, cold.Address, sweeps[0].Destination)
+
+		require.NoError(t, queue.Close())
+		assert.Equal(t, WithdrawalSubmitted, (<-receipts).Status)
+		ledger.Snapshot()
+		balance, _ := ledger.GetBalance(hot.Address)
+		assert.Equal(t, int64(40), balance.Balance)
+		balance, _ = ledger.GetBalance(cold.Address)
+		assert.Equal(t, int64(178), balance.Balance)
+	})
+
+	t.Run("holds sweeps for approval", func(t *testing.T) {
+		ledger := NewSimulatedLedger()
+		ledger.Credit(hot.Address, 500)
+		var submitted []Sweep
+		sweeper := newSweeper(t, ledger, SweeperConfig{
+			Threshold:       100,
+			RequireApproval: true,
+			Submit: func(sweep Sweep) error {
+				submitted = append(submitted, sweep)
+				return nil
+			},
+		})
+
+		pending := sweeper.Pending()
+		require.Len(t, pending, 1)
+		assert.Equal(t, int64(400), pending[0].Amount)
+		assert.Empty(t, submitted)
+
+		// a rejected sweep is proposed again
+		require.NoError(t, sweeper.Reject(pending[0].ID))
+		assert.ErrorIs(t, sweeper.Reject(pending[0].ID), ErrSweepNotFound)
+		require.Len(t, sweeper.Check(), 1)
+		pending = sweeper.Pending()
+		require.Len(t, pending, 1)
+
+		require.NoError(t, sweeper.Approve(pending[0].ID))
+		assert.Empty(t, sweeper.Pending())
+		require.Len(t, submitted, 1)
+		assert.Equal(t, pending[0], submitted[0])
+		assert.ErrorIs(t, sweeper.Approve(pending[0].ID), ErrSweepNotFound)
+	})
+
+	t.Run("keeps one pending sweep per wallet and re-checks it on approval", func(t *testing.T) {
+		ledger := NewSimulatedLedger()
+		ledger.Credit(hot.Address, 500)
+		var submitted []Sweep
+		sweeper := newSweeper(t, ledger, SweeperConfig{
+			Threshold:       100,
+			RequireApproval: true,
+			Submit: func(sweep Sweep) error {
+				submitted = append(submitted, sweep)
+				return nil
+			},
+		})
+		require.Len(t, sweeper.Pending(), 1)
+
+		// the excess is spent before approval: the stale sweep is dropped and
+		// the next excess gets the wallet's only pending sweep
+		ledger.Credit(hot.Address, -450)
+		assert.Empty(t, sweeper.Check())
+		assert.Empty(t, sweeper.Pending())
+		ledger.Credit(hot.Address, 250)
+		require.Len(t, sweeper.Check(), 1)
+		pending := sweeper.Pending()
+		require.Len(t, pending, 1)
+		assert.Equal(t, int64(200), pending[0].Amount)
+
+		// approval sends no more than the current excess
+		ledger.Credit(hot.Address, -50)
+		require.NoError(t, sweeper.Approve(pending[0].ID))
+		require.Len(t, submitted, 1)
+		assert.Equal(t, int64(150), submitted[0].Amount)
+		assert.Equal(t, int64(250), submitted[0].Balance)
+
+		// and nothing once the excess is gone between checks
+		ledger.Credit(hot.Address, -250)
+		assert.Empty(t, sweeper.Check())
+		ledger.Credit(hot.Address, 300)
+		require.Len(t, sweeper.Check(), 1)
+		pending = sweeper.Pending()
+		require.Len(t, pending, 1)
+		ledger.Credit(hot.Address, -250)
+		assert.ErrorIs(t, sweeper.Approve(pending[0].ID), ErrSweepStale)
+		assert.Empty(t, sweeper.Pending())
+		assert.Len(t, submitted, 1)
+	})
+
+	t.Run("retries failed submissions and honours the schedule and cooldown", func(t *testing.T) {
+		ledger := NewSimulatedLedger()
+		open := int32(1)
+		var failures []error
+		fail := true
+		sweeper := newSweeper(t, ledger, SweeperConfig{
+			Threshold: 100,
+			MinSweep:  10,
+			Cooldown:  time.Hour,
+			Schedule:  func(time.Time) bool { return atomic.LoadInt32(&open) == 1 },
+			Submit: func(sweep Sweep) error {
+				if fail {
+					return errors.New("queue unavailable")
+				}
+				return nil
+			},
+			OnError: func(err error) { failures = append(failures, err) },
+		})
+
+		atomic.StoreInt32(&open, 0)
+		ledger.Credit(hot.Address, 105)
+		assert.Empty(t, sweeper.Check(), "outside the schedule")
+		atomic.StoreInt32(&open, 1)
+		assert.Empty(t, sweeper.Check(), "below the minimum sweep")
+
+		ledger.Credit(hot.Address, 100)
+		require.Len(t, sweeper.Check(), 1)
+		require.Len(t, failures, 1)
+		assert.ErrorContains(t, failures[0], "queue unavailable")
+
+		fail = false
+		require.Len(t, sweeper.Check(), 1)
+		// the sweep lands and the wallet refills, but the cooldown holds
+		ledger.Credit(hot.Address, -150)
+		assert.Empty(t, sweeper.Check())
+		ledger.Credit(hot.Address, 150)
+		assert.Empty(t, sweeper.Check())
+	})
+
+	t.Run("sweeps again once a lost sweep times out", func(t *testing.T) {
+		ledger := NewSimulatedLedger()
+		ledger.Credit(hot.Address, 150)
+		var submitted int32
+		sweeper := newSweeper(t, ledger, SweeperConfig{
+			Threshold:    100,
+			SweepTimeout: 50 * time.Millisecond,
+			Submit: func(Sweep) error {
+				// accepted, but never lands, like a sweep the queue rejects
+				atomic.AddInt32(&submitted, 1)
+				return nil
+			},
+		})
+		assert.Empty(t, sweeper.Check())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&submitted))
+
+		time.Sleep(60 * time.Millisecond)
+		require.Len(t, sweeper.Check(), 1)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&submitted))
+		assert.Empty(t, sweeper.Check())
+	})
+
+	t.Run("validates its config", func(t *testing.T) {
+		submit := func(Sweep) error { return nil }
+		ledger := NewSimulatedLedger()
+		for _, config := range []SweeperConfig{
+			{Wallets: []string{hot.Address}, ColdAddress: cold.Address, Threshold: 1, Submit: submit},
+			{Balances: ledger, Wallets: []string{hot.Address}, ColdAddress: "DAGnope", Threshold: 1, Submit: submit},
+			{Balances: ledger, Wallets: []string{hot.Address}, ColdAddress: cold.Address, Threshold: 10, Target: 11, Submit: submit},
+			{Balances: ledger, Wallets: []string{hot.Address}, ColdAddress: cold.Address, Threshold: 1},
+			{Balances: ledger, ColdAddress: cold.Address, Threshold: 1, Submit: submit},
+		} {
+			_, err := NewSweeper(context.Background(), config)
+			assert.ErrorIs(t, err, ErrInvalidSweepConfig)
+		}
+	})
+}
@@ -0,0 +1,309 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTenantIDRequired indicates a TenantConfig without an ID
+	ErrTenantIDRequired = errors.New("tenant ID is required")
+	// ErrTenantExists indicates adding a tenant whose ID is taken
+	ErrTenantExists = errors.New("tenant already exists")
+	// ErrUnknownTenant indicates a tenant ID the TenantManager does not know
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrTenantKeyAccess indicates a key outside the tenant's keyring
+	ErrTenantKeyAccess = errors.New("key is not in the tenant's keyring")
+)
+
+// TenantConfig holds the settings of one tenant
+type TenantConfig struct {
+	// ID identifies the tenant in metrics and lookups
+	ID string
+	// RateLimit caps the tenant's requests per second across all of its
+	// clients (default: 0, unlimited)
+	RateLimit float64
+	// Burst is how many requests may exceed RateLimit at once (default: 1)
+	Burst int
+	// Labels are attached to every RequestMetric of the tenant, e.g. its
+	// plan or region
+	Labels map[string]string
+	// Policies run before a tenant withdrawal queue's own policies
+	Policies []WithdrawalPolicy
+	// Keyring holds the tenant's keys. Tenant signers come only from it.
+	Keyring *Keyring
+}
+
+// RequestMetric describes one request made by a tenant's client
+type RequestMetric struct {
+	Tenant string
+	// Labels are the tenant's labels, shared between metrics; do not modify
+	Labels map[string]string
+	// Endpoint is the method and URL, e.g. "GET http://node:9010/transactions"
+	Endpoint string
+	Duration time.Duration
+	// Waited is how long the request was held by the tenant's rate limit
+	Waited time.Duration
+	// Err is the request's error, such as a *NetworkError, or nil
+	Err error
+}
+
+// TenantManagerConfig holds configuration for a TenantManager
+type TenantManagerConfig struct {
+	// Network is the node configuration shared by all tenants
+	Network NetworkConfig
+	// OnRequest, if set, receives a RequestMetric for every request of
+	// every tenant. It is called from the requesting goroutine.
+	OnRequest func(metric RequestMetric)
+}
+
+// TenantManager serves many tenants from one process, such as the customers
+// of a SaaS platform. Each Tenant gets network clients with its own rate
+// limit and metric labels, its own withdrawal policies, and signers only
+// from its own keyring, so one tenant can neither starve nor sign for
+// another. Clients still share the connection pool. It is safe for
+// concurrent use.
+//
+// Example:
+//
+//	manager := NewTenantManager(TenantManagerConfig{
+//	    Network:   NetworkConfig{L1URL: "http://localhost:9010"},
+//	    OnRequest: func(m RequestMetric) { requests.WithLabelValues(m.Tenant).Inc() },
+//	})
+//	tenant, err := manager.AddTenant(TenantConfig{ID: "acme", RateLimit: 20, Keyring: acmeKeyring})
+//	l1, err := network.NewCurrencyL1Client(tenant.NetworkConfig())
+//	signer, err := tenant.Signer(hotWallet)
+type TenantManager struct {
+	config TenantManagerConfig
+
+	mu      sync.RWMutex
+	tenants map[string]*Tenant
+}
+
+// NewTenantManager creates a manager without tenants
+func NewTenantManager(config TenantManagerConfig) *TenantManager {
+	return &TenantManager{config: config, tenants: map[string]*Tenant{}}
+}
+
+// AddTenant registers a tenant. Returns ErrTenantExists if the ID is taken.
+func (m *TenantManager) AddTenant(config TenantConfig) (*Tenant, error) {
+	if config.ID == "" {
+		return nil, ErrTenantIDRequired
+	}
+	labels := make(map[string]string, len(config.Labels))
+	for name, value := range config.Labels {
+		labels[name] = value
+	}
+	config.Labels = labels
+	config.Policies = append([]WithdrawalPolicy(nil), config.Policies...)
+
+	tenant := &Tenant{manager: m, config: config}
+	tenant.scope = &tenantScope{
+		tenant:    config.ID,
+		labels:    labels,
+		limiter:   newRateLimiter(config.RateLimit, config.Burst),
+		onRequest: m.config.OnRequest,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tenants[config.ID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrTenantExists, config.ID)
+	}
+	m.tenants[config.ID] = tenant
+	return tenant, nil
+}
+
+// Tenant returns the tenant with id, or ErrUnknownTenant
+func (m *TenantManager) Tenant(id string) (*Tenant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tenant, ok := m.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+	}
+	return tenant, nil
+}
+
+// Tenants returns the IDs of all tenants, sorted
+func (m *TenantManager) Tenants() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.tenants))
+	for id := range m.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// RemoveTenant forgets the tenant and locks its keyring. Clients already
+// built from its NetworkConfig keep working.
+func (m *TenantManager) RemoveTenant(id string) {
+	m.mu.Lock()
+	tenant, ok := m.tenants[id]
+	delete(m.tenants, id)
+	m.mu.Unlock()
+	if ok && tenant.config.Keyring != nil {
+		tenant.config.Keyring.Lock()
+	}
+}
+
+// networkConfig returns the shared network configuration scoped to tenant
+func (m *TenantManager) networkConfig(tenant *Tenant) NetworkConfig {
+	config := m.config.Network
+	config.scope = tenant.scope
+	return config
+}
+
+// Tenant is one tenant of a TenantManager
+type Tenant struct {
+	manager *TenantManager
+	config  TenantConfig
+	scope   *tenantScope
+}
+
+// ID returns the tenant's ID
+func (t *Tenant) ID() string {
+	return t.config.ID
+}
+
+// NetworkConfig returns the manager's network configuration scoped to the
+// tenant. Clients built from it, such as network.NewCurrencyL1Client(config), count
+// against the tenant's rate limit and report its metrics.
+func (t *Tenant) NetworkConfig() NetworkConfig {
+	return t.manager.networkConfig(t)
+}
+
+// Signer returns the signer for address from the tenant's keyring. Returns
+// ErrTenantKeyAccess if the tenant has no keyring or the address is not in
+// it.
+func (t *Tenant) Signer(address string) (*KeyringSigner, error) {
+	if t.config.Keyring == nil {
+		return nil, fmt.Errorf("%w: tenant %s has no keyring", ErrTenantKeyAccess, t.config.ID)
+	}
+	signer, err := t.config.Keyring.Signer(address)
+	if errors.Is(err, ErrKeyNotInKeyring) {
+		return nil, fmt.Errorf("%w: %s", ErrTenantKeyAccess, RedactAddress(address))
+	}
+	return signer, err
+}
+
+// NewWithdrawalQueue creates a withdrawal queue for the tenant. The queue
+// must sign with a signer from Tenant.Signer, not a raw PrivateKey, and the
+// tenant's policies run before config.Policies.
+func (t *Tenant) NewWithdrawalQueue(ctx context.Context, config WithdrawalQueueConfig) (*WithdrawalQueue, error) {
+	signer, ok := config.Signer.(*KeyringSigner)
+	if !ok || t.config.Keyring == nil || signer.keyring != t.config.Keyring {
+		return nil, fmt.Errorf("%w: use a signer from Tenant.Signer", ErrTenantKeyAccess)
+	}
+	config.PrivateKey = ""
+	config.Policies = append(append([]WithdrawalPolicy(nil), t.config.Policies...), config.Policies...)
+	return NewWithdrawalQueue(ctx, config)
+}
+
+// BeginRequest is called by HTTP clients built from the config before each
+// request to endpoint, e.g. "GET http://node:9010/node/info". For a config
+// from Tenant.NetworkConfig it waits for the tenant's rate limit and
+// returns a function to call with the request's result, which reports its
+// RequestMetric; other configs never wait. If ctx ends first, its error is
+// returned.
+func (c NetworkConfig) BeginRequest(ctx context.Context, endpoint string) (func(err error), error) {
+	if c.scope == nil {
+		return func(error) {}, nil
+	}
+	return c.scope.begin(ctx, endpoint)
+}
+
+// tenantScope is carried by a tenant's NetworkConfig into its HTTP clients
+type tenantScope struct {
+	tenant    string
+	labels    map[string]string
+	limiter   *rateLimiter
+	onRequest func(metric RequestMetric)
+}
+
+// begin waits for the tenant's rate limit and returns a function that
+// reports the finished request. If ctx ends first, the request is reported
+// with ctx's error, which begin returns.
+func (s *tenantScope) begin(ctx context.Context, endpoint string) (func(err error), error) {
+	waited, err := s.limiter.wait(ctx)
+	start := time.Now()
+	finish := func(err error) {
+		if s.onRequest == nil {
+			return
+		}
+		s.onRequest(RequestMetric{
+			Tenant:   s.tenant,
+			Labels:   s.labels,
+			Endpoint: endpoint,
+			Duration: time.Since(start),
+			Waited:   waited,
+			Err:      err,
+		})
+	}
+	if err != nil {
+		finish(err)
+		return nil, err
+	}
+	return finish, nil
+}
+
+// rateLimiter is a token bucket; a nil limiter never waits
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes a token, sleeping until one is available or ctx ends, and
+// returns how long it slept. A token not waited for is given back.
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	// reserve the token now, so concurrent callers queue behind each other
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return 0, nil
+	}
+	start := time.Now()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return time.Since(start), ctx.Err()
+	}
+}
@@ -0,0 +1,167 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantManager(t *testing.T) {
+	var mu sync.Mutex
+	var metrics []RequestMetric
+	manager := NewTenantManager(TenantManagerConfig{
+		Network: NetworkConfig{L1URL: "http://localhost:9010"},
+		OnRequest: func(metric RequestMetric) {
+			mu.Lock()
+			defer mu.Unlock()
+			metrics = append(metrics, metric)
+		},
+	})
+
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	keyStore, err := EncryptKeyPairWithParams(keyPair, "pw", LightScryptParams)
+	require.NoError(t, err)
+	acmeKeys := NewKeyring(KeyringConfig{})
+	_, err = acmeKeys.AddKeyStore(keyStore)
+	require.NoError(t, err)
+	require.NoError(t, acmeKeys.Unlock("pw"))
+
+	acme, err := manager.AddTenant(TenantConfig{
+		ID:        "acme",
+		RateLimit: 20,
+		Labels:    map[string]string{"plan": "pro"},
+		Keyring:   acmeKeys,
+		Policies: []WithdrawalPolicy{func(request WithdrawalRequest) error {
+			return errors.New("acme withdrawals paused")
+		}},
+	})
+	require.NoError(t, err)
+	globex, err := manager.AddTenant(TenantConfig{ID: "globex"})
+	require.NoError(t, err)
+
+	t.Run("looks tenants up by ID", func(t *testing.T) {
+		_, err := manager.AddTenant(TenantConfig{ID: "acme"})
+		assert.ErrorIs(t, err, ErrTenantExists)
+		_, err = manager.AddTenant(TenantConfig{})
+		assert.ErrorIs(t, err, ErrTenantIDRequired)
+		found, err := manager.Tenant("globex")
+		require.NoError(t, err)
+		assert.Same(t, globex, found)
+		_, err = manager.Tenant("initech")
+		assert.ErrorIs(t, err, ErrUnknownTenant)
+		assert.Equal(t, []string{"acme", "globex"}, manager.Tenants())
+	})
+
+	t.Run("rate limits and labels requests per tenant", func(t *testing.T) {
+		request := func(config NetworkConfig, err error) {
+			finish, beginErr := config.BeginRequest(context.Background(), "GET http://localhost:9010/node/info")
+			require.NoError(t, beginErr)
+			finish(err)
+		}
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			request(acme.NetworkConfig(), nil)
+		}
+		// the burst of 1 spaces the other two requests 50ms apart
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+		start = time.Now()
+		for i := 0; i < 3; i++ {
+			request(globex.NetworkConfig(), nil)
+		}
+		request(globex.NetworkConfig(), NewNetworkError("HTTP 503", 503, ""))
+		assert.Less(t, time.Since(start), 90*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, metrics, 7)
+		assert.Equal(t, "acme", metrics[0].Tenant)
+		assert.Equal(t, map[string]string{"plan": "pro"}, metrics[0].Labels)
+		assert.Zero(t, metrics[0].Waited)
+		assert.Positive(t, metrics[2].Waited)
+		assert.Equal(t, "GET http://localhost:9010/node/info", metrics[0].Endpoint)
+		assert.Equal(t, "globex", metrics[6].Tenant)
+		assert.Empty(t, metrics[6].Labels)
+		var netErr *NetworkError
+		require.ErrorAs(t, metrics[6].Err, &netErr)
+		assert.Equal(t, 503, netErr.StatusCode)
+	})
+
+	t.Run("does not limit configs without a tenant", func(t *testing.T) {
+		finish, err := NetworkConfig{}.BeginRequest(context.Background(), "GET http://localhost:9010/node/info")
+		require.NoError(t, err)
+		finish(nil)
+	})
+
+	t.Run("stops waiting for the rate limit when the request is cancelled", func(t *testing.T) {
+		limiter := newRateLimiter(1, 1)
+		_, err := limiter.wait(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = limiter.wait(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		// the cancelled wait gave its token back
+		limiter.mu.Lock()
+		assert.Greater(t, limiter.tokens, -0.5)
+		limiter.mu.Unlock()
+	})
+
+	t.Run("serves signers only from the tenant's keyring", func(t *testing.T) {
+		signer, err := acme.Signer(keyPair.Address)
+		require.NoError(t, err)
+		assert.Equal(t, keyPair.PublicKey, signer.PublicKey())
+
+		_, err = globex.Signer(keyPair.Address)
+		assert.ErrorIs(t, err, ErrTenantKeyAccess)
+		other, _ := GenerateKeyPair()
+		_, err = acme.Signer(other.Address)
+		assert.ErrorIs(t, err, ErrTenantKeyAccess)
+
+		ledger := NewSimulatedLedger()
+		_, err = globex.NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{L1: ledger, Signer: signer})
+		assert.ErrorIs(t, err, ErrTenantKeyAccess)
+		_, err = acme.NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{L1: ledger, PrivateKey: keyPair.PrivateKey})
+		assert.ErrorIs(t, err, ErrTenantKeyAccess)
+	})
+
+	t.Run("applies the tenant's policies first", func(t *testing.T) {
+		signer, err := acme.Signer(keyPair.Address)
+		require.NoError(t, err)
+		destination, err := GenerateKeyPair()
+		require.NoError(t, err)
+		ledger := NewSimulatedLedger()
+		require.NoError(t, ledger.Fund(keyPair.Address, 100))
+
+		var receipts []WithdrawalReceipt
+		queue, err := acme.NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+			L1:        ledger,
+			Signer:    signer,
+			OnReceipt: func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+		})
+		require.NoError(t, err)
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w-1", Destination: destination.Address, Amount: 1}))
+		require.NoError(t, queue.Close())
+
+		require.Len(t, receipts, 1)
+		assert.Equal(t, WithdrawalRejected, receipts[0].Status)
+		assert.EqualError(t, receipts[0].Err, "acme withdrawals paused")
+	})
+
+	t.Run("removing a tenant locks its keyring", func(t *testing.T) {
+		manager.RemoveTenant("acme")
+		assert.True(t, acmeKeys.Locked())
+		_, err := manager.Tenant("acme")
+		assert.ErrorIs(t, err, ErrUnknownTenant)
+	})
+}
@@ -0,0 +1,128 @@
+package constellation
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tinyGoForbidden are the packages the transaction signing path must not
+// call into: reflection-heavy encoding, fmt formatting and regexp
+var tinyGoForbidden = map[string]bool{
+	"encoding/json": true,
+	"fmt":           true,
+	"reflect":       true,
+	"regexp":        true,
+}
+
+// TestTinyGoSigningPath audits the currency transaction path for TinyGo:
+// starting from the functions the README lists, it follows every static call
+// within this package and fails on a call into tinyGoForbidden. Error paths
+// may use fmt.Errorf, which TinyGo supports.
+func TestTinyGoSigningPath(t *testing.T) {
+	fset := token.NewFileSet()
+	names, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+		files = append(files, file)
+	}
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("constellation", fset, files, info)
+	require.NoError(t, err)
+
+	decls := map[types.Object]*ast.FuncDecl{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				if fn.Recv == nil {
+					decls[pkg.Scope().Lookup(fn.Name.Name)] = fn
+				} else {
+					decls[methodObject(pkg, fn)] = fn
+				}
+			}
+		}
+	}
+
+	roots := []string{"CreateCurrencyTransaction", "SignCurrencyTransaction", "VerifyCurrencyTransaction", "HashCurrencyTransaction", "IsValidDAGAddress"}
+	var queue []types.Object
+	for _, root := range roots {
+		queue = append(queue, pkg.Scope().Lookup(root))
+	}
+	// signing goes through the Signer interface, so follow the implementation
+	signer := pkg.Scope().Lookup("PrivateKeySigner").Type().(*types.Named)
+	for i := 0; i < signer.NumMethods(); i++ {
+		queue = append(queue, signer.Method(i))
+	}
+	seen := map[types.Object]bool{}
+	var violations []string
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+		fn := decls[obj]
+		if seen[obj] || fn == nil {
+			continue
+		}
+		seen[obj] = true
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			used, ok := info.Uses[ident].(*types.Func)
+			if !ok || used.Pkg() == nil {
+				return true
+			}
+			path := used.Pkg().Path()
+			switch {
+			case used.Pkg() == pkg:
+				queue = append(queue, used)
+			case tinyGoForbidden[path] && !(path == "fmt" && used.Name() == "Errorf"):
+				violations = append(violations, fset.Position(ident.Pos()).String()+": "+fn.Name.Name+" calls "+path+"."+used.Name())
+			}
+			return true
+		})
+	}
+	sort.Strings(violations)
+	assert.Empty(t, violations)
+}
+
+// methodObject finds the types.Func of a method declaration
+func methodObject(pkg *types.Package, fn *ast.FuncDecl) types.Object {
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	named, ok := pkg.Scope().Lookup(ident.Name).Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+	for i := 0; i < named.NumMethods(); i++ {
+		if named.Method(i).Name() == fn.Name.Name {
+			return named.Method(i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package constellation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenToUnitsChecked(t *testing.T) {
+	units, err := TokenToUnitsChecked(100.5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10050000000), units)
+
+	units, err = TokenToUnitsChecked(92233720368.5)
+	require.NoError(t, err)
+	assert.Equal(t, TokenToUnits(92233720368.5), units)
+
+	for _, amount := range []float64{1e11, -1e11, 1e300, math.Inf(1), math.Inf(-1), math.NaN()} {
+		_, err := TokenToUnitsChecked(amount)
+		assert.ErrorIs(t, err, ErrAmountOutOfRange, "%v", amount)
+	}
+
+	keyPair, _ := GenerateKeyPair()
+	destination, _ := GenerateKeyPair()
+	_, err = CreateCurrencyTransaction(TransferParams{Destination: destination.Address, Amount: 1e300}, keyPair.PrivateKey, GenesisReference)
+	assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	_, err = CreateCurrencyTransactionBatch([]TransferParams{{Destination: destination.Address, Amount: 1, Fee: math.Inf(1)}}, keyPair.PrivateKey, GenesisReference)
+	assert.ErrorIs(t, err, ErrAmountOutOfRange)
+	assert.ErrorIs(t, NewSimulatedLedger().Fund(keyPair.Address, math.NaN()), ErrAmountOutOfRange)
+}
+
+func TestParseTokenAmount(t *testing.T) {
+	valid := map[string]int64{
+		"0":                    0,
+		"1":                    100000000,
+		"100.5":                10050000000,
+		"0.00000001":           1,
+		".5":                   50000000,
+		"-2.25":                -225000000,
+		"0.1":                  10000000, // float64 0.1*1e8 is fine, 0.29*1e8 is not
+		"0.29":                 29000000,
+		"92233720368.54775807": math.MaxInt64,
+	}
+	for amount, want := range valid {
+		units, err := ParseTokenAmount(amount)
+		require.NoError(t, err, amount)
+		assert.Equal(t, want, units, amount)
+	}
+	assert.Equal(t, int64(28999999), TokenToUnits(0.29), "float conversion rounds down")
+
+	for _, amount := range []string{"", ".", "1.", "-", "1.123456789", "1e5", "0x10", "1,5", " 1", "+1"} {
+		_, err := ParseTokenAmount(amount)
+		assert.ErrorIs(t, err, ErrInvalidTokenAmount, amount)
+	}
+	_, err := ParseTokenAmount("92233720368.54775808")
+	assert.ErrorIs(t, err, ErrAmountOutOfRange)
+
+	for _, units := range []int64{0, 1, 50000000, 10050000000, -225000000, math.MaxInt64, math.MinInt64} {
+		formatted := FormatTokenAmount(units)
+		parsed, err := ParseTokenAmount(formatted)
+		require.NoError(t, err, formatted)
+		assert.Equal(t, units, parsed, formatted)
+	}
+	assert.Equal(t, "100.5", FormatTokenAmount(10050000000))
+	assert.Equal(t, "0.00000001", FormatTokenAmount(1))
+	assert.Equal(t, "-2.25", FormatTokenAmount(-225000000))
+}
@@ -0,0 +1,123 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidTransactionEncoding indicates a string that is not the encoding
+// of a currency transaction
+var ErrInvalidTransactionEncoding = errors.New("invalid transaction encoding")
+
+// DecodeTransaction parses a string produced by EncodeCurrencyTransaction
+// back into the transaction value it encodes, so an auditor can check what
+// a wallet actually signed. The salt is returned in decimal, as the SDK
+// writes it.
+//
+// Length prefixes are bare decimal numbers, so a prefix followed by a value
+// starting with a digit can be split more than one way. Only splits where
+// every field is in the canonical form the encoder writes are accepted
+// (addresses of DAGAddressLength, a 64-character or empty parent hash, and
+// numbers without leading zeros), and an encoding that still decodes more
+// than one way is rejected.
+//
+// Example:
+//
+//	value, err := DecodeTransaction(signedEncoding)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(value.Destination, UnitsToToken(value.Amount))
+func DecodeTransaction(encoded string) (*CurrencyTransactionValue, error) {
+	if len(encoded) == 0 || encoded[0] != '2' {
+		return nil, fmt.Errorf("%w: missing parent count", ErrInvalidTransactionEncoding)
+	}
+
+	var decodings [][]string
+	splitEncodedFields(encoded[1:], nil, &decodings)
+	switch len(decodings) {
+	case 0:
+		return nil, fmt.Errorf("%w: fields do not parse", ErrInvalidTransactionEncoding)
+	case 1:
+	default:
+		return nil, fmt.Errorf("%w: ambiguous field lengths", ErrInvalidTransactionEncoding)
+	}
+
+	fields := decodings[0]
+	amount, _ := strconv.ParseInt(fields[2], 16, 64)
+	ordinal, _ := strconv.Atoi(fields[4])
+	fee, _ := strconv.ParseInt(fields[5], 10, 64)
+	salt, _ := strconv.ParseInt(fields[6], 16, 64)
+	return &CurrencyTransactionValue{
+		Source:      fields[0],
+		Destination: fields[1],
+		Amount:      amount,
+		Fee:         fee,
+		Parent:      TransactionReference{Hash: fields[3], Ordinal: ordinal},
+		Salt:        strconv.FormatInt(salt, 10),
+	}, nil
+}
+
+// encodedFieldValid reports whether value is field i of the transaction
+// encoding in the form encodeTransaction writes it
+func encodedFieldValid(i int, value string) bool {
+	switch i {
+	case 0, 1: // source, destination, always DAGAddressLength characters
+		return IsValidDAGAddress(value)
+	case 2: // amount, hex
+		n, err := strconv.ParseInt(value, 16, 64)
+		return err == nil && strconv.FormatInt(n, 16) == value
+	case 3: // parent hash: a SHA-256 hex digest, or empty for genesis
+		if len(value) != 0 && len(value) != 64 {
+			return false
+		}
+		for _, c := range value {
+			if !isHexChar(c) {
+				return false
+			}
+		}
+		return true
+	case 4: // parent ordinal
+		n, err := strconv.Atoi(value)
+		return err == nil && strconv.Itoa(n) == value
+	case 5: // fee
+		n, err := strconv.ParseInt(value, 10, 64)
+		return err == nil && strconv.FormatInt(n, 10) == value
+	case 6: // salt, signed hex
+		n, err := strconv.ParseInt(value, 16, 64)
+		return err == nil && strconv.FormatInt(n, 16) == value
+	}
+	return false
+}
+
+// splitEncodedFields appends to decodings every way rest splits into the
+// remaining length-prefixed fields after fields. It stops once two are
+// found, since that is already ambiguous.
+func splitEncodedFields(rest string, fields []string, decodings *[][]string) {
+	if len(*decodings) > 1 {
+		return
+	}
+	i := len(fields)
+	if i == 7 {
+		if rest == "" {
+			*decodings = append(*decodings, append([]string(nil), fields...))
+		}
+		return
+	}
+
+	for digits := 1; digits <= len(rest) && digits <= len(strconv.Itoa(len(rest))); digits++ {
+		prefix := rest[:digits]
+		length, err := strconv.Atoi(prefix)
+		if err != nil || strconv.Itoa(length) != prefix {
+			return
+		}
+		if length > len(rest)-digits {
+			return
+		}
+		value := rest[digits : digits+length]
+		if encodedFieldValid(i, value) {
+			splitEncodedFields(rest[digits+length:], append(fields, value), decodings)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package constellation
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// MemoVersion identifies the memo sidecar encryption scheme:
+// secp256k1 ECDH with an ephemeral key, HKDF-SHA256 and AES-256-GCM
+const MemoVersion = "dag-memo-v1"
+
+var (
+	// ErrMemoRecipientMismatch indicates a recipient key that does not own
+	// the transaction's destination address
+	ErrMemoRecipientMismatch = errors.New("memo recipient key does not match the transaction destination")
+	// ErrMemoSenderMismatch indicates a memo not signed by the transaction's source address
+	ErrMemoSenderMismatch = errors.New("memo is not signed by the transaction source")
+	// ErrMemoTransactionMismatch indicates a memo bound to a different transaction
+	ErrMemoTransactionMismatch = errors.New("memo is bound to a different transaction")
+	// ErrMemoAlreadyPublished indicates a memo for a transaction that
+	// already has one from a different sender
+	ErrMemoAlreadyPublished = errors.New("a memo from another sender is already published for the transaction")
+	// ErrMemoDecryptFailed indicates a memo that cannot be decrypted with the given key
+	ErrMemoDecryptFailed = errors.New("memo cannot be decrypted")
+)
+
+// TransactionMemo is an encrypted off-chain memo for a currency
+// transaction. Currency transactions carry no memo field, so wallets
+// exchange this sidecar instead: the memo is encrypted to the recipient's
+// public key and bound to the transaction hash, and the sidecar is signed
+// by the sender as a Signed[TransactionMemo].
+type TransactionMemo struct {
+	Version string `json:"version"`
+	// TxHash is the hash of the transaction the memo belongs to
+	TxHash    string `json:"txHash"`
+	Sender    string `json:"sender"`
+	Recipient string `json:"recipient"`
+	// EphemeralKey is the compressed public key hex of the one-time
+	// encryption key
+	EphemeralKey string `json:"ephemeralKey"`
+	// Nonce is the AES-GCM nonce hex
+	Nonce string `json:"nonce"`
+	// Ciphertext is the sealed memo hex; the transaction hash is bound as
+	// additional data
+	Ciphertext string `json:"ciphertext"`
+}
+
+// MemoStore publishes and fetches memo sidecars, e.g. on a wallet backend
+// or a shared indexer. MemoryMemoStore implements it.
+type MemoStore interface {
+	// PublishMemo stores memo under its transaction hash. It must not let
+	// a memo from another sender replace a published one.
+	PublishMemo(memo *Signed[TransactionMemo]) error
+	// FetchMemo returns the memo for txHash, or nil if none is published
+	FetchMemo(txHash string) (*Signed[TransactionMemo], error)
+}
+
+// EncryptTransactionMemo encrypts memo to the transaction's recipient and
+// signs the sidecar with the sender's key. recipientPublicKey must own the
+// transaction's destination address and sender its source address.
+//
+// Example:
+//
+//	memo, err := EncryptTransactionMemo(tx, "invoice 2024-113", recipientPublicKey, signer)
+//	if err != nil {
+//	    return err
+//	}
+//	err = store.PublishMemo(memo)
+func EncryptTransactionMemo(tx *CurrencyTransaction, memo string, recipientPublicKey string, sender Signer) (*Signed[TransactionMemo], error) {
+	recipientPublicKey = NormalizePublicKey(recipientPublicKey)
+	if GetAddress(recipientPublicKey) != tx.Value.Destination {
+		return nil, ErrMemoRecipientMismatch
+	}
+	if GetAddress(sender.PublicKey()) != tx.Value.Source {
+		return nil, ErrMemoSenderMismatch
+	}
+	recipient, err := parsePublicKeyHex(recipientPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash := HashCurrencyTransaction(tx).Value
+	sealed, err := sealToPublicKey(recipient, []byte(MemoVersion+":"+txHash), []byte(memo), []byte(txHash))
+	if err != nil {
+		return nil, err
+	}
+
+	value := TransactionMemo{
+		Version:      MemoVersion,
+		TxHash:       txHash,
+		Sender:       tx.Value.Source,
+		Recipient:    tx.Value.Destination,
+		EphemeralKey: sealed.ephemeralKey,
+		Nonce:        sealed.nonce,
+		Ciphertext:   sealed.ciphertext,
+	}
+	return CreateSignedObjectWithSigner(value, sender, false)
+}
+
+// VerifyTransactionMemo checks that the memo is validly signed by its
+// sender and, if tx is not nil, that it is bound to tx and its addresses
+func VerifyTransactionMemo(memo *Signed[TransactionMemo], tx *CurrencyTransaction) error {
+	if memo == nil || !Verify(memo, false).IsValid || !signedByAny(memo.Proofs, []string{memo.Value.Sender}) {
+		return ErrMemoSenderMismatch
+	}
+	if tx == nil {
+		return nil
+	}
+	if memo.Value.TxHash != HashCurrencyTransaction(tx).Value ||
+		memo.Value.Sender != tx.Value.Source || memo.Value.Recipient != tx.Value.Destination {
+		return ErrMemoTransactionMismatch
+	}
+	return nil
+}
+
+// DecryptTransactionMemo verifies the memo's signature and decrypts it with
+// the recipient's private key
+func DecryptTransactionMemo(memo *Signed[TransactionMemo], recipientPrivateKey string) (string, error) {
+	if err := VerifyTransactionMemo(memo, nil); err != nil {
+		return "", err
+	}
+	if memo.Value.Version != MemoVersion {
+		return "", fmt.Errorf("%w: unsupported version %q", ErrMemoDecryptFailed, memo.Value.Version)
+	}
+	privateKey, err := parsePrivateKeyHex(recipientPrivateKey)
+	if err != nil {
+		return "", err
+	}
+	sealed := sealedBox{
+		ephemeralKey: memo.Value.EphemeralKey,
+		nonce:        memo.Value.Nonce,
+		ciphertext:   memo.Value.Ciphertext,
+	}
+	plaintext, err := sealed.open(privateKey, []byte(MemoVersion+":"+memo.Value.TxHash), []byte(memo.Value.TxHash))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMemoDecryptFailed, err)
+	}
+	return string(plaintext), nil
+}
+
+// sealedBox is plaintext encrypted to a public key: secp256k1 ECDH with an
+// ephemeral key, HKDF-SHA256 salted with the ephemeral key and AES-256-GCM.
+// Fields are hex.
+type sealedBox struct {
+	ephemeralKey string
+	nonce        string
+	ciphertext   string
+}
+
+// sealToPublicKey encrypts plaintext to recipient. info binds the derived
+// key to its use and aad is authenticated along with the ciphertext.
+func sealToPublicKey(recipient *btcec.PublicKey, info []byte, plaintext []byte, aad []byte) (sealedBox, error) {
+	ephemeral, err := btcec.NewPrivateKey()
+	if err != nil {
+		return sealedBox{}, err
+	}
+	ephemeralKey := ephemeral.PubKey().SerializeCompressed()
+	aead, err := sealedBoxCipher(btcec.GenerateSharedSecret(ephemeral, recipient), ephemeralKey, info)
+	if err != nil {
+		return sealedBox{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return sealedBox{}, err
+	}
+	return sealedBox{
+		ephemeralKey: hex.EncodeToString(ephemeralKey),
+		nonce:        hex.EncodeToString(nonce),
+		ciphertext:   hex.EncodeToString(aead.Seal(nil, nonce, plaintext, aad)),
+	}, nil
+}
+
+// open decrypts the box with the recipient's private key, given the info
+// and aad it was sealed with
+func (b sealedBox) open(privateKey *btcec.PrivateKey, info []byte, aad []byte) ([]byte, error) {
+	ephemeralKey, err := hex.DecodeString(b.ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := btcec.ParsePubKey(ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(b.nonce)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := hex.DecodeString(b.ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := sealedBoxCipher(btcec.GenerateSharedSecret(privateKey, ephemeral), ephemeralKey, info)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("bad nonce length")
+	}
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
+
+// sealedBoxCipher derives the AES-256-GCM key of a sealed box from the ECDH
+// shared secret with HKDF-SHA256, salted with the ephemeral key
+func sealedBoxCipher(sharedSecret []byte, ephemeralKey []byte, info []byte) (cipher.AEAD, error) {
+	key := hkdfSHA256(sharedSecret, ephemeralKey, info, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// parsePrivateKeyHex parses a private key hex
+func parsePrivateKeyHex(privateKeyHex string) (*btcec.PrivateKey, error) {
+	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
+	if err != nil {
+		return nil, invalidPrivateKeyHex(err)
+	}
+	defer Zeroize(privateKeyBytes)
+	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
+	return privateKey, nil
+}
+
+// parsePublicKeyHex parses a compressed or uncompressed public key hex
+func parsePublicKeyHex(publicKeyHex string) (*btcec.PublicKey, error) {
+	publicKeyBytes, err := hex.DecodeString(NormalizePublicKey(publicKeyHex))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	publicKey, err := btcec.ParsePubKey(publicKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	return publicKey, nil
+}
+
+// hkdfSHA256 is HKDF (RFC 5869) with SHA-256, for length up to 255*32 bytes
+func hkdfSHA256(secret []byte, salt []byte, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	var okm, previous []byte
+	for counter := byte(1); len(okm) < length; counter++ {
+		expand := hmac.New(sha256.New, prk)
+		expand.Write(previous)
+		expand.Write(info)
+		expand.Write([]byte{counter})
+		previous = expand.Sum(nil)
+		okm = append(okm, previous...)
+	}
+	return okm[:length]
+}
+
+// MemoryMemoStore keeps memo sidecars in memory
+type MemoryMemoStore struct {
+	mu    sync.Mutex
+	memos map[string]*Signed[TransactionMemo]
+}
+
+// NewMemoryMemoStore creates an empty MemoryMemoStore
+func NewMemoryMemoStore() *MemoryMemoStore {
+	return &MemoryMemoStore{memos: map[string]*Signed[TransactionMemo]{}}
+}
+
+// PublishMemo stores memo under its transaction hash after checking its
+// signature. The store does not see the transaction, so the first sender
+// to publish for a hash owns it: that sender may replace its memo, and a
+// memo signed by any other key returns ErrMemoAlreadyPublished. Readers
+// still check the memo against the transaction with VerifyTransactionMemo.
+func (s *MemoryMemoStore) PublishMemo(memo *Signed[TransactionMemo]) error {
+	if err := VerifyTransactionMemo(memo, nil); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.memos[memo.Value.TxHash]; ok && existing.Value.Sender != memo.Value.Sender {
+		return ErrMemoAlreadyPublished
+	}
+	s.memos[memo.Value.TxHash] = memo
+	return nil
+}
+
+// FetchMemo returns the memo for txHash, or nil if none is published
+func (s *MemoryMemoStore) FetchMemo(txHash string) (*Signed[TransactionMemo], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.memos[txHash], nil
+}
@@ -0,0 +1,113 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHKDFSHA256(t *testing.T) {
+	// RFC 5869 test case 1
+	secret, _ := hex.DecodeString(strings.Repeat("0b", 22))
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	okm := hkdfSHA256(secret, salt, info, 42)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(okm))
+}
+
+func TestTransactionMemo(t *testing.T) {
+	sender, err := GenerateKeyPair()
+	require.NoError(t, err)
+	recipient, err := GenerateKeyPair()
+	require.NoError(t, err)
+	signer, err := NewPrivateKeySigner(sender.PrivateKey)
+	require.NoError(t, err)
+
+	lastRef := TransactionReference{Hash: strings.Repeat("0", 64), Ordinal: 0}
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: recipient.Address, Amount: 5}, sender.PrivateKey, lastRef)
+	require.NoError(t, err)
+
+	memo, err := EncryptTransactionMemo(tx, "invoice 2024-113", recipient.PublicKey, signer)
+	require.NoError(t, err)
+	assert.Equal(t, MemoVersion, memo.Value.Version)
+	assert.Equal(t, HashCurrencyTransaction(tx).Value, memo.Value.TxHash)
+	assert.NotContains(t, memo.Value.Ciphertext, hex.EncodeToString([]byte("invoice")))
+
+	t.Run("the recipient decrypts it", func(t *testing.T) {
+		require.NoError(t, VerifyTransactionMemo(memo, tx))
+		plaintext, err := DecryptTransactionMemo(memo, recipient.PrivateKey)
+		require.NoError(t, err)
+		assert.Equal(t, "invoice 2024-113", plaintext)
+	})
+
+	t.Run("nobody else can", func(t *testing.T) {
+		_, err := DecryptTransactionMemo(memo, sender.PrivateKey)
+		assert.ErrorIs(t, err, ErrMemoDecryptFailed)
+	})
+
+	t.Run("is bound to its transaction", func(t *testing.T) {
+		other, err := CreateCurrencyTransaction(TransferParams{Destination: recipient.Address, Amount: 6}, sender.PrivateKey, lastRef)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifyTransactionMemo(memo, other), ErrMemoTransactionMismatch)
+
+		// moving the ciphertext to another transaction breaks decryption
+		moved := memo.Value
+		moved.TxHash = HashCurrencyTransaction(other).Value
+		resigned, err := CreateSignedObjectWithSigner(moved, signer, false)
+		require.NoError(t, err)
+		_, err = DecryptTransactionMemo(resigned, recipient.PrivateKey)
+		assert.ErrorIs(t, err, ErrMemoDecryptFailed)
+	})
+
+	t.Run("checks the parties", func(t *testing.T) {
+		_, err := EncryptTransactionMemo(tx, "memo", sender.PublicKey, signer)
+		assert.ErrorIs(t, err, ErrMemoRecipientMismatch)
+
+		impostor, err := NewPrivateKeySigner(recipient.PrivateKey)
+		require.NoError(t, err)
+		_, err = EncryptTransactionMemo(tx, "memo", recipient.PublicKey, impostor)
+		assert.ErrorIs(t, err, ErrMemoSenderMismatch)
+
+		forged, err := CreateSignedObject(memo.Value, recipient.PrivateKey, false)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifyTransactionMemo(forged, tx), ErrMemoSenderMismatch)
+	})
+
+	t.Run("publishes and fetches through a store", func(t *testing.T) {
+		store := NewMemoryMemoStore()
+		require.NoError(t, store.PublishMemo(memo))
+		fetched, err := store.FetchMemo(memo.Value.TxHash)
+		require.NoError(t, err)
+		assert.Equal(t, memo, fetched)
+
+		missing, err := store.FetchMemo("unknown")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+
+		tampered := &Signed[TransactionMemo]{Value: memo.Value, Proofs: nil}
+		assert.Error(t, store.PublishMemo(tampered))
+	})
+
+	t.Run("keeps the first sender's memo", func(t *testing.T) {
+		store := NewMemoryMemoStore()
+		require.NoError(t, store.PublishMemo(memo))
+
+		// a validly signed memo from another key claiming the same transaction
+		value := memo.Value
+		value.Sender = recipient.Address
+		overwrite, err := CreateSignedObject(value, recipient.PrivateKey, false)
+		require.NoError(t, err)
+		assert.ErrorIs(t, store.PublishMemo(overwrite), ErrMemoAlreadyPublished)
+
+		fetched, err := store.FetchMemo(memo.Value.TxHash)
+		require.NoError(t, err)
+		assert.Equal(t, memo, fetched)
+
+		updated, err := EncryptTransactionMemo(tx, "corrected memo", recipient.PublicKey, signer)
+		require.NoError(t, err)
+		assert.NoError(t, store.PublishMemo(updated), "the sender may replace its own memo")
+	})
+}
@@ -0,0 +1,326 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// errSignVerifyFailed indicates a signature that does not verify against the
+// signer's own public key
+var errSignVerifyFailed = errors.New("sign-verify failed")
+
+// ErrSignerNotSource indicates a signer whose address is not the source of
+// the transaction it was asked to sign
+var ErrSignerNotSource = errors.New("signer is not the transaction source")
+
+// The transaction flow is split into three roles so each can run in its own
+// process or privilege domain:
+//
+//   - a Builder turns transfers into unsigned, chained transactions and
+//     needs only the source address;
+//   - a Signer holds the key and signs transaction hashes;
+//   - a Submitter posts signed transactions to the network.
+//
+// CreateCurrencyTransaction, CreateCurrencyTransactionBatch and
+// SignCurrencyTransaction are these roles composed in one process; their
+// WithSigner variants and SignTransaction take any Signer instead of a
+// private key.
+
+// Builder builds unsigned currency transactions for one source address
+type Builder interface {
+	// Source returns the address the transactions are sent from
+	Source() string
+	// Build creates an unsigned transaction chained from parent. Amount and
+	// fee are in smallest units (1e-8).
+	Build(destination string, amount int64, fee int64, parent TransactionReference) (*CurrencyTransaction, error)
+}
+
+// Signer signs transaction hashes and signing digests with one key.
+// Implementations may keep the key in another process, an HSM or a remote
+// service. One that can only sign digests implements DigestSigner and is
+// wrapped with NewDigestSigner, which derives SignHash.
+type Signer interface {
+	// PublicKey returns the uncompressed public key hex (with 04 prefix)
+	PublicKey() string
+	// SignDigest signs a 32-byte signing digest with secp256k1 ECDSA and
+	// returns the DER signature
+	SignDigest(digest []byte) ([]byte, error)
+	// SignHash signs a SHA-256 hash (hex) using the Constellation signing
+	// protocol and returns the DER signature hex
+	SignHash(hashHex string) (string, error)
+}
+
+// DigestSigner signs the 32-byte Constellation signing digest directly.
+// Hardware wallets, KMS backends and remote signers usually work at this
+// level; wrap one with NewDigestSigner to use it wherever a Signer is taken.
+// Every Signer is a DigestSigner.
+type DigestSigner interface {
+	// PublicKey returns the uncompressed public key hex (with 04 prefix)
+	PublicKey() string
+	// SignDigest signs digest with secp256k1 ECDSA and returns the DER
+	// signature
+	SignDigest(digest []byte) ([]byte, error)
+}
+
+// NewDigestSigner adapts a DigestSigner to a Signer, adding SignHash by
+// computing the signing digest of each hash (see ComputeDigestFromHash)
+//
+// Example:
+//
+//	signer := NewDigestSigner(hsmKey)
+//	tx, err := CreateCurrencyTransactionWithSigner(params, signer, lastRef)
+func NewDigestSigner(signer DigestSigner) Signer {
+	return digestSigner{signer}
+}
+
+type digestSigner struct {
+	DigestSigner
+}
+
+func (s digestSigner) SignHash(hashHex string) (string, error) {
+	if err := CheckSigningAllowed(); err != nil {
+		return "", err
+	}
+	signature, err := s.SignDigest(ComputeDigestFromHash(hashHex))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+// Submitter posts signed transactions to the network. Every CurrencyL1API,
+// including CurrencyL1Client, PooledCurrencyL1Client and SimulatedLedger, is
+// a Submitter.
+type Submitter interface {
+	PostTransaction(transaction *CurrencyTransaction) (*PostTransactionResponse, error)
+}
+
+// TransactionBuilder is the Builder for a DAG address
+//
+// Example:
+//
+//	builder := NewTransactionBuilder(hotWalletAddress)            // build host: no key
+//	unsigned, err := BuildBatch(builder, transfers, lastRef)
+//	signed, err := SignTransaction(unsigned[0], signer)         // signing host
+//	_, err = submitter.PostTransaction(signed)                  // submit host
+type TransactionBuilder struct {
+	source        string
+	dustThreshold int64
+}
+
+// NewTransactionBuilder creates a builder for transactions from source
+func NewTransactionBuilder(source string) *TransactionBuilder {
+	return &TransactionBuilder{source: source}
+}
+
+// WithDustThreshold makes Build reject amounts below threshold, in
+// smallest units (1e-8), with ErrDustTransfer. Use DustPolicy.PlanTransfers
+// to aggregate dust before building instead.
+func (b *TransactionBuilder) WithDustThreshold(threshold int64) *TransactionBuilder {
+	b.dustThreshold = threshold
+	return b
+}
+
+// Source returns the address the transactions are sent from
+func (b *TransactionBuilder) Source() string {
+	return b.source
+}
+
+// Build creates an unsigned transaction with a fresh salt
+func (b *TransactionBuilder) Build(destination string, amount int64, fee int64, parent TransactionReference) (*CurrencyTransaction, error) {
+	if !IsValidDAGAddress(b.source) {
+		return nil, ErrInvalidAddress
+	}
+	if !IsValidDAGAddress(destination) {
+		return nil, ErrInvalidAddress
+	}
+	if b.source == destination {
+		return nil, ErrSameAddress
+	}
+	if amount < 1 {
+		return nil, ErrInvalidAmount
+	}
+	if amount < b.dustThreshold {
+		return nil, fmt.Errorf("%w: %s", ErrDustTransfer, FormatTokenAmount(amount))
+	}
+	if fee < 0 {
+		return nil, ErrInvalidFee
+	}
+	if _, err := EffectiveDebit(amount, fee); err != nil {
+		return nil, err
+	}
+
+	return &CurrencyTransaction{
+		Value: CurrencyTransactionValue{
+			Source:      b.source,
+			Destination: destination,
+			Amount:      amount,
+			Fee:         fee,
+			Parent:      parent,
+			Salt:        generateSalt(),
+		},
+		Proofs: []SignatureProof{},
+	}, nil
+}
+
+// BuildBatch builds unsigned transactions for transfers, each chained from
+// the previous one starting at lastRef. Transaction hashes do not cover
+// proofs, so the chain stays valid once the transactions are signed. A
+// transfer's Salt, if set, replaces the salt the builder generates and is
+// stored in decimal.
+func BuildBatch(builder Builder, transfers []TransferParams, lastRef TransactionReference) ([]*CurrencyTransaction, error) {
+	transactions := make([]*CurrencyTransaction, 0, len(transfers))
+	parent := lastRef
+	for _, transfer := range transfers {
+		amount, fee, err := transferUnits(transfer)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := buildSalted(builder, transfer.Destination, amount, fee, transfer.Salt, parent)
+		if err != nil {
+			return nil, err
+		}
+		parent = *GetTransactionReference(tx, parent.Ordinal+1)
+		transactions = append(transactions, tx)
+	}
+	return transactions, nil
+}
+
+// buildSalted builds a transaction with builder, replacing its salt with
+// salt, in decimal, when one is given
+func buildSalted(builder Builder, destination string, amount int64, fee int64, salt string, parent TransactionReference) (*CurrencyTransaction, error) {
+	var value *big.Int
+	if salt != "" {
+		var err error
+		if value, err = ParseSalt(salt); err != nil {
+			return nil, err
+		}
+	}
+	tx, err := builder.Build(destination, amount, fee, parent)
+	if err != nil || value == nil {
+		return tx, err
+	}
+	// store the decimal form nodes expect, so unsigned transactions match
+	// what SignTransaction produces
+	tx.Value.Salt = value.String()
+	return tx, nil
+}
+
+// PrivateKeySigner is the Signer for an in-memory private key. The key is
+// held as bytes, which Destroy wipes.
+type PrivateKeySigner struct {
+	secret    *keySecret
+	publicKey string
+}
+
+// NewPrivateKeySigner creates a signer from a hex private key
+func NewPrivateKeySigner(privateKeyHex string) (*PrivateKeySigner, error) {
+	secret, err := newKeySecretFromHex(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := secret.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKeySigner{secret: secret, publicKey: publicKey}, nil
+}
+
+// PublicKey returns the uncompressed public key hex (with 04 prefix)
+func (s *PrivateKeySigner) PublicKey() string {
+	return s.publicKey
+}
+
+// Address returns the DAG address of the key
+func (s *PrivateKeySigner) Address() string {
+	return GetAddress(s.publicKey)
+}
+
+// SignHash signs a SHA-256 hash using the Constellation signing protocol
+func (s *PrivateKeySigner) SignHash(hashHex string) (string, error) {
+	signature, err := s.SignDigest(ComputeDigestFromHash(hashHex))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+// SignDigest signs a 32-byte signing digest and returns the DER signature,
+// making PrivateKeySigner a DigestSigner as well
+func (s *PrivateKeySigner) SignDigest(digest []byte) ([]byte, error) {
+	if err := CheckSigningAllowed(); err != nil {
+		return nil, err
+	}
+	var signature []byte
+	err := s.secret.use(func(privateKey *btcec.PrivateKey) error {
+		signature = ecdsa.Sign(privateKey, digest).Serialize()
+		return nil
+	})
+	return signature, err
+}
+
+// Destroy wipes the signer's private key; signing then returns
+// ErrKeyDestroyed. A signer from KeyPair.Signer shares its key pair's key.
+func (s *PrivateKeySigner) Destroy() {
+	s.secret.destroy()
+}
+
+// SignTransaction returns a copy of tx with the signer's proof appended. The
+// signer's address must be the transaction's source, otherwise
+// ErrSignerNotSource is returned, so a misrouted signer cannot sign for a
+// wallet it does not own; co-signers of a multi-signature transaction use
+// CosignTransaction. The signature is verified before it is added, so a
+// faulty remote signer cannot produce a transaction the network would
+// reject. A hex salt is rewritten in decimal; returns ErrInvalidSalt for a
+// salt ParseSalt rejects, ErrKryoPayloadTooLarge for a transaction whose
+// fields encode to more than MaxKryoStringLength bytes, and a
+// *SigningFrozenError while signing is frozen, whatever the signer.
+func SignTransaction(tx *CurrencyTransaction, signer Signer) (*CurrencyTransaction, error) {
+	if source := GetAddress(signer.PublicKey()); source != tx.Value.Source {
+		return nil, fmt.Errorf("%w: signer is %s, source %s", ErrSignerNotSource, RedactAddress(source), RedactAddress(tx.Value.Source))
+	}
+	return CosignTransaction(tx, signer)
+}
+
+// CosignTransaction is SignTransaction for any signer of a multi-signature
+// transaction: the signer's address is not checked against the source
+func CosignTransaction(tx *CurrencyTransaction, signer Signer) (*CurrencyTransaction, error) {
+	if err := CheckSigningAllowed(); err != nil {
+		return nil, err
+	}
+	salt, err := ParseSalt(tx.Value.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKryoLength(len(encodeTransaction(tx))); err != nil {
+		return nil, err
+	}
+	hashHex := HashCurrencyTransaction(tx).Value
+
+	signature, err := signer.SignHash(hashHex)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyHex := NormalizePublicKey(signer.PublicKey())
+	if !verifyHashInternal(publicKeyHex, hashHex, signature) {
+		return nil, errSignVerifyFailed
+	}
+
+	signed := &CurrencyTransaction{
+		Value:  tx.Value,
+		Proofs: append([]SignatureProof{}, tx.Proofs...),
+	}
+	// nodes expect decimal salts; the hash covers the number, not its form
+	signed.Value.Salt = salt.String()
+	signed.Proofs = append(signed.Proofs, SignatureProof{
+		ID:        NormalizePublicKeyToID(publicKeyHex),
+		Signature: signature,
+	})
+	return signed, nil
+}
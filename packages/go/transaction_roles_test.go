@@ -0,0 +1,218 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Submitter = CurrencyL1API(nil)
+
+// remoteSigner stands in for a signer in another process
+type remoteSigner struct {
+	inner Signer
+	calls int
+	err   error
+}
+
+func (r *remoteSigner) PublicKey() string { return r.inner.PublicKey() }
+
+func (r *remoteSigner) SignHash(hashHex string) (string, error) {
+	r.calls++
+	if r.err != nil {
+		return "", r.err
+	}
+	return r.inner.SignHash(hashHex)
+}
+
+func (r *remoteSigner) SignDigest(digest []byte) ([]byte, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.inner.SignDigest(digest)
+}
+
+func TestTransactionRoles(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	alice, _ := GenerateKeyPair()
+
+	keySigner, err := NewPrivateKeySigner(hot.PrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, hot.PublicKey, keySigner.PublicKey())
+	assert.Equal(t, hot.Address, keySigner.Address())
+
+	t.Run("build, sign and submit in separate roles", func(t *testing.T) {
+		builder := NewTransactionBuilder(hot.Address)
+		unsigned, err := BuildBatch(builder, []TransferParams{
+			{Destination: alice.Address, Amount: 1},
+			{Destination: alice.Address, Amount: 2},
+		}, GenesisReference)
+		require.NoError(t, err)
+		require.Len(t, unsigned, 2)
+		assert.Empty(t, unsigned[0].Proofs)
+
+		signer := &remoteSigner{inner: keySigner}
+		ledger := NewSimulatedLedger()
+		require.NoError(t, ledger.Fund(hot.Address, 10))
+		var submitter Submitter = ledger
+
+		for _, tx := range unsigned {
+			signed, err := SignTransaction(tx, signer)
+			require.NoError(t, err)
+			assert.True(t, VerifyCurrencyTransactionStrict(signed))
+			_, err = submitter.PostTransaction(signed)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, signer.calls)
+		assert.Empty(t, unsigned[0].Proofs, "signing returns a copy")
+	})
+
+	t.Run("signs only for the source unless co-signing", func(t *testing.T) {
+		other, err := NewPrivateKeySigner(alice.PrivateKey)
+		require.NoError(t, err)
+		tx, err := NewTransactionBuilder(hot.Address).Build(alice.Address, 1, 0, GenesisReference)
+		require.NoError(t, err)
+
+		_, err = SignTransaction(tx, other)
+		assert.ErrorIs(t, err, ErrSignerNotSource)
+
+		signed, err := SignTransaction(tx, keySigner)
+		require.NoError(t, err)
+		cosigned, err := CosignTransaction(signed, other)
+		require.NoError(t, err)
+		assert.Len(t, cosigned.Proofs, 2)
+		assert.True(t, VerifyCurrencyTransaction(cosigned).IsValid)
+	})
+
+	t.Run("rejects signatures that do not verify", func(t *testing.T) {
+		other, err := NewPrivateKeySigner(alice.PrivateKey)
+		require.NoError(t, err)
+		wrongKey := &mismatchedSigner{publicKey: keySigner.PublicKey(), signer: other}
+
+		tx, err := NewTransactionBuilder(hot.Address).Build(alice.Address, 1, 0, GenesisReference)
+		require.NoError(t, err)
+		_, err = SignTransaction(tx, wrongKey)
+		assert.Error(t, err)
+
+		down := errors.New("signer unavailable")
+		_, err = SignTransaction(tx, &remoteSigner{inner: keySigner, err: down})
+		assert.ErrorIs(t, err, down)
+	})
+
+	t.Run("builder validates like CreateCurrencyTransaction", func(t *testing.T) {
+		builder := NewTransactionBuilder(hot.Address)
+		_, err := builder.Build(hot.Address, 1, 0, GenesisReference)
+		assert.ErrorIs(t, err, ErrSameAddress)
+		_, err = builder.Build(alice.Address, 0, 0, GenesisReference)
+		assert.ErrorIs(t, err, ErrInvalidAmount)
+		_, err = builder.Build(alice.Address, 1, -1, GenesisReference)
+		assert.ErrorIs(t, err, ErrInvalidFee)
+		_, err = NewTransactionBuilder("DAGbad").Build(alice.Address, 1, 0, GenesisReference)
+		assert.ErrorIs(t, err, ErrInvalidAddress)
+	})
+}
+
+// mismatchedSigner claims one public key but signs with another
+type mismatchedSigner struct {
+	publicKey string
+	signer    Signer
+}
+
+func (m *mismatchedSigner) PublicKey() string { return m.publicKey }
+
+func (m *mismatchedSigner) SignHash(hashHex string) (string, error) {
+	return m.signer.SignHash(hashHex)
+}
+
+func (m *mismatchedSigner) SignDigest(digest []byte) ([]byte, error) {
+	return m.signer.SignDigest(digest)
+}
+
+// hardwareSigner stands in for a device that only signs raw digests
+type hardwareSigner struct {
+	key     *PrivateKeySigner
+	digests [][]byte
+}
+
+func (h *hardwareSigner) PublicKey() string { return h.key.PublicKey() }
+
+func (h *hardwareSigner) SignDigest(digest []byte) ([]byte, error) {
+	h.digests = append(h.digests, digest)
+	return h.key.SignDigest(digest)
+}
+
+func TestDigestSigner(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	require.NoError(t, err)
+	recipient, _ := GenerateKeyPair()
+	key, err := NewPrivateKeySigner(keyPair.PrivateKey)
+	require.NoError(t, err)
+
+	device := &hardwareSigner{key: key}
+	signer := NewDigestSigner(device)
+
+	t.Run("matches the private key signer", func(t *testing.T) {
+		hash := HashCurrencyTransaction(&CurrencyTransaction{}).Value
+		viaDigest, err := signer.SignHash(hash)
+		require.NoError(t, err)
+		direct, err := key.SignHash(hash)
+		require.NoError(t, err)
+		assert.Equal(t, direct, viaDigest, "RFC 6979 signatures are deterministic")
+		assert.Equal(t, ComputeDigestFromHash(hash), device.digests[len(device.digests)-1])
+	})
+
+	t.Run("creates transactions", func(t *testing.T) {
+		params := TransferParams{Destination: recipient.Address, Amount: 1.5, Fee: 0.001}
+		tx, err := CreateCurrencyTransactionWithSigner(params, signer, GenesisReference)
+		require.NoError(t, err)
+		assert.Equal(t, keyPair.Address, tx.Value.Source)
+		assert.True(t, VerifyCurrencyTransaction(tx).IsValid)
+
+		batch, err := CreateCurrencyTransactionBatchWithSigner([]TransferParams{params, params}, signer, GenesisReference)
+		require.NoError(t, err)
+		require.Len(t, batch, 2)
+		for _, tx := range batch {
+			assert.True(t, VerifyCurrencyTransactionStrict(tx))
+		}
+	})
+
+	t.Run("signs for the withdrawal queue", func(t *testing.T) {
+		ledger := NewSimulatedLedger()
+		require.NoError(t, ledger.Fund(keyPair.Address, 10))
+
+		var receipts []WithdrawalReceipt
+		queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+			L1:        ledger,
+			Signer:    signer,
+			OnReceipt: func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+		})
+		require.NoError(t, err)
+		assert.Equal(t, keyPair.Address, queue.Source())
+
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w", Destination: recipient.Address, Amount: TokenToUnits(1)}))
+		require.NoError(t, queue.Close())
+		require.Len(t, receipts, 1)
+		assert.Equal(t, WithdrawalSubmitted, receipts[0].Status)
+	})
+
+	t.Run("device errors surface", func(t *testing.T) {
+		failing := NewDigestSigner(&failingDigestSigner{publicKey: key.PublicKey()})
+		_, err := CreateCurrencyTransactionWithSigner(TransferParams{Destination: recipient.Address, Amount: 1}, failing, GenesisReference)
+		assert.ErrorIs(t, err, errDeviceLocked)
+	})
+}
+
+var errDeviceLocked = errors.New("device locked")
+
+type failingDigestSigner struct {
+	publicKey string
+}
+
+func (f *failingDigestSigner) PublicKey() string { return f.publicKey }
+
+func (f *failingDigestSigner) SignDigest([]byte) ([]byte, error) { return nil, errDeviceLocked }
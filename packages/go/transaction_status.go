@@ -0,0 +1,113 @@
+package constellation
+
+import "time"
+
+// TransactionState is where a transaction is in its lifecycle
+type TransactionState string
+
+const (
+	// TxStateWaiting means the transaction is in the L1 mempool, waiting to
+	// be picked into a block
+	TxStateWaiting TransactionState = "Waiting"
+	// TxStatePending means the L1 is including the transaction in a block
+	TxStatePending TransactionState = "Pending"
+	// TxStateInBlock means the transaction is in an accepted block that no
+	// indexed snapshot confirms yet
+	TxStateInBlock TransactionState = "InBlock"
+	// TxStateConfirmed means a global snapshot confirmed the transaction
+	TxStateConfirmed TransactionState = "ConfirmedAtOrdinal"
+	// TxStateNotFound means neither the L1 nor the explorer knows the
+	// transaction
+	TxStateNotFound TransactionState = "NotFound"
+)
+
+// TransactionStatusReport is the combined L1 and explorer view of a
+// transaction
+type TransactionStatusReport struct {
+	Hash  string
+	State TransactionState
+	// Transaction is the transaction as held by the L1 mempool, if it is
+	// still there
+	Transaction *CurrencyTransaction
+	// BlockHash is the block containing the transaction, if known
+	BlockHash string
+	// SnapshotOrdinal and SnapshotHash identify the confirming snapshot
+	SnapshotOrdinal int64
+	SnapshotHash    string
+	// Timestamp is when the confirming snapshot was made
+	Timestamp time.Time
+}
+
+// GetTransactionStatus combines an L1 pending-pool lookup and an explorer
+// lookup into one status. explorer may be nil, in which case transactions
+// that left the mempool are reported as NotFound. When the explorer omits
+// the confirmation time and also implements ExplorerAPI, the confirming
+// snapshot is fetched for its timestamp.
+//
+// Example:
+//
+//	report, err := GetTransactionStatus(l1, explorer, hash)
+//	if err != nil {
+//	    return err
+//	}
+//	if report.State == TxStateConfirmed {
+//	    fmt.Println("confirmed at", report.SnapshotOrdinal, report.Timestamp)
+//	}
+func GetTransactionStatus(l1 CurrencyL1API, explorer ExplorerTransactionLookup, hash string) (*TransactionStatusReport, error) {
+	report := &TransactionStatusReport{Hash: hash, State: TxStateNotFound}
+
+	pending, err := l1.GetPendingTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		tx := pending.Transaction
+		report.Transaction = &tx
+		switch pending.Status {
+		case StatusWaiting:
+			report.State = TxStateWaiting
+			return report, nil
+		case StatusInProgress:
+			report.State = TxStatePending
+			return report, nil
+		default:
+			// accepted into a block; the explorer knows whether a snapshot has it
+			report.State = TxStateInBlock
+		}
+	}
+
+	if explorer == nil {
+		return report, nil
+	}
+	confirmed, err := explorer.GetTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	if confirmed == nil {
+		return report, nil
+	}
+
+	report.BlockHash = confirmed.BlockHash
+	if confirmed.SnapshotOrdinal == 0 && confirmed.SnapshotHash == "" {
+		report.State = TxStateInBlock
+		return report, nil
+	}
+	report.State = TxStateConfirmed
+	report.SnapshotOrdinal = confirmed.SnapshotOrdinal
+	report.SnapshotHash = confirmed.SnapshotHash
+	report.Timestamp = confirmed.Timestamp
+
+	if api, ok := explorer.(ExplorerAPI); ok && report.Timestamp.IsZero() {
+		snapshot, err := api.GetSnapshot(confirmed.SnapshotOrdinal)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			report.Timestamp = snapshot.Timestamp
+			if report.SnapshotHash == "" {
+				report.SnapshotHash = snapshot.Hash
+			}
+		}
+	}
+	return report, nil
+}
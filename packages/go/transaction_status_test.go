@@ -0,0 +1,99 @@
+package constellation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusL1 serves pending transactions with fixed statuses
+type statusL1 struct {
+	CurrencyL1API
+	statuses map[string]TransactionStatus
+}
+
+func (s *statusL1) GetPendingTransaction(hash string) (*PendingTransaction, error) {
+	status, ok := s.statuses[hash]
+	if !ok {
+		return nil, nil
+	}
+	return &PendingTransaction{Hash: hash, Status: status}, nil
+}
+
+func TestGetTransactionStatus(t *testing.T) {
+	confirmedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	l1 := &statusL1{statuses: map[string]TransactionStatus{
+		"waiting":  StatusWaiting,
+		"building": StatusInProgress,
+		"accepted": StatusAccepted,
+	}}
+	explorer := newFakeExplorer()
+	explorer.transactions["DAG0addr"] = append(explorer.transactions["DAG0addr"],
+		ExplorerTransaction{Hash: "timed", BlockHash: "b1", SnapshotHash: "s9", SnapshotOrdinal: 9, Timestamp: confirmedAt},
+		ExplorerTransaction{Hash: "blocked", BlockHash: "b2"},
+	)
+	explorer.snapshots[10].Timestamp = confirmedAt
+
+	cases := []struct {
+		hash  string
+		state TransactionState
+	}{
+		{"waiting", TxStateWaiting},
+		{"building", TxStatePending},
+		{"accepted", TxStateInBlock},
+		{"blocked", TxStateInBlock},
+		{"timed", TxStateConfirmed},
+		{"tx1", TxStateConfirmed},
+		{"unknown", TxStateNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.hash, func(t *testing.T) {
+			report, err := GetTransactionStatus(l1, explorer, c.hash)
+			require.NoError(t, err)
+			assert.Equal(t, c.state, report.State)
+		})
+	}
+
+	t.Run("reports the confirming snapshot", func(t *testing.T) {
+		report, err := GetTransactionStatus(l1, explorer, "timed")
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), report.SnapshotOrdinal)
+		assert.Equal(t, "s9", report.SnapshotHash)
+		assert.Equal(t, "b1", report.BlockHash)
+		assert.Equal(t, confirmedAt, report.Timestamp)
+
+		// tx1 has no timestamp; it comes from snapshot 10
+		report, err = GetTransactionStatus(l1, explorer, "tx1")
+		require.NoError(t, err)
+		assert.Equal(t, confirmedAt, report.Timestamp)
+		assert.Equal(t, "s10", report.SnapshotHash)
+	})
+
+	t.Run("works through an ExplorerCache", func(t *testing.T) {
+		cache, err := NewExplorerCache(ExplorerCacheConfig{Upstream: explorer})
+		require.NoError(t, err)
+		report, err := GetTransactionStatus(l1, cache, "timed")
+		require.NoError(t, err)
+		assert.Equal(t, TxStateConfirmed, report.State)
+
+		calls := explorer.calls
+		_, err = cache.GetTransaction("timed")
+		require.NoError(t, err)
+		assert.Equal(t, calls, explorer.calls, "confirmed transactions are cached")
+	})
+
+	t.Run("without an explorer", func(t *testing.T) {
+		report, err := GetTransactionStatus(l1, nil, "tx1")
+		require.NoError(t, err)
+		assert.Equal(t, TxStateNotFound, report.State)
+	})
+
+	t.Run("propagates explorer errors", func(t *testing.T) {
+		down := errors.New("down")
+		_, err := GetTransactionStatus(l1, &fakeExplorer{err: down}, "tx1")
+		assert.ErrorIs(t, err, down)
+	})
+}
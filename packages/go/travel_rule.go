@@ -0,0 +1,272 @@
+package constellation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// TravelRuleVersion identifies the travel rule envelope scheme: the data is
+// JSON sealed like a TransactionMemo, with secp256k1 ECDH, HKDF-SHA256 and
+// AES-256-GCM
+const TravelRuleVersion = "dag-travel-rule-v1"
+
+var (
+	// ErrInvalidTravelRuleData indicates travel rule data that is incomplete
+	// or does not describe its transaction
+	ErrInvalidTravelRuleData = errors.New("invalid travel rule data")
+	// ErrTravelRuleSignatureInvalid indicates an envelope with a missing or
+	// bad signature from its originating VASP
+	ErrTravelRuleSignatureInvalid = errors.New("travel rule envelope is not validly signed by its originating VASP")
+	// ErrTravelRuleUntrustedVASP indicates an envelope from a VASP the
+	// receiver does not trust
+	ErrTravelRuleUntrustedVASP = errors.New("travel rule envelope is from an untrusted VASP")
+	// ErrTravelRuleTransactionMismatch indicates an envelope bound to a
+	// different transaction
+	ErrTravelRuleTransactionMismatch = errors.New("travel rule envelope is bound to a different transaction")
+	// ErrTravelRuleDecryptFailed indicates an envelope that cannot be
+	// decrypted with the given key
+	ErrTravelRuleDecryptFailed = errors.New("travel rule envelope cannot be decrypted")
+)
+
+// TravelRuleParty is the originator or beneficiary of a transfer, with the
+// fields of an IVMS 101 natural or legal person that VASPs commonly exchange
+type TravelRuleParty struct {
+	// Name is the person's full name or the legal person's registered name
+	Name string `json:"name"`
+	// Address is the party's DAG address in the transfer
+	Address string `json:"address"`
+	// AccountNumber is the party's account or customer ID at its VASP
+	AccountNumber     string `json:"accountNumber,omitempty"`
+	GeographicAddress string `json:"geographicAddress,omitempty"`
+	// NationalID is a national identity, passport or LEI number
+	NationalID  string `json:"nationalId,omitempty"`
+	DateOfBirth string `json:"dateOfBirth,omitempty"`
+	// VASP names the party's service provider, e.g. by its LEI
+	VASP string `json:"vasp,omitempty"`
+}
+
+// TravelRuleData is the originator and beneficiary information a VASP
+// sends the counterparty VASP for a transfer
+type TravelRuleData struct {
+	// TxHash is the hash of the transfer the data describes
+	TxHash      string          `json:"txHash"`
+	Originator  TravelRuleParty `json:"originator"`
+	Beneficiary TravelRuleParty `json:"beneficiary"`
+	// Amount in smallest units (1e-8)
+	Amount int64 `json:"amount"`
+}
+
+// NewTravelRuleData builds the travel rule data of tx. The parties'
+// addresses default to the transaction's source and destination, and both
+// parties need a name.
+//
+// Example:
+//
+//	data, err := NewTravelRuleData(tx,
+//	    TravelRuleParty{Name: "Alice Example", AccountNumber: "c-1042", VASP: "ORIGINATOR LEI"},
+//	    TravelRuleParty{Name: "Bob Example", VASP: "BENEFICIARY LEI"},
+//	)
+func NewTravelRuleData(tx *CurrencyTransaction, originator TravelRuleParty, beneficiary TravelRuleParty) (*TravelRuleData, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("%w: no transaction", ErrInvalidTravelRuleData)
+	}
+	if originator.Address == "" {
+		originator.Address = tx.Value.Source
+	}
+	if beneficiary.Address == "" {
+		beneficiary.Address = tx.Value.Destination
+	}
+	data := &TravelRuleData{
+		TxHash:      HashCurrencyTransaction(tx).Value,
+		Originator:  originator,
+		Beneficiary: beneficiary,
+		Amount:      tx.Value.Amount,
+	}
+	if err := data.MatchesTransaction(tx); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MatchesTransaction checks that the data is complete and describes tx: its
+// hash, addresses and amount
+func (d *TravelRuleData) MatchesTransaction(tx *CurrencyTransaction) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+	switch {
+	case d.TxHash != HashCurrencyTransaction(tx).Value:
+		return fmt.Errorf("%w: transaction hash differs", ErrInvalidTravelRuleData)
+	case d.Originator.Address != tx.Value.Source:
+		return fmt.Errorf("%w: originator address is not the transaction source", ErrInvalidTravelRuleData)
+	case d.Beneficiary.Address != tx.Value.Destination:
+		return fmt.Errorf("%w: beneficiary address is not the transaction destination", ErrInvalidTravelRuleData)
+	case d.Amount != tx.Value.Amount:
+		return fmt.Errorf("%w: amount differs", ErrInvalidTravelRuleData)
+	}
+	return nil
+}
+
+func (d *TravelRuleData) validate() error {
+	switch {
+	case d.TxHash == "":
+		return fmt.Errorf("%w: missing transaction hash", ErrInvalidTravelRuleData)
+	case d.Originator.Name == "":
+		return fmt.Errorf("%w: missing originator name", ErrInvalidTravelRuleData)
+	case d.Beneficiary.Name == "":
+		return fmt.Errorf("%w: missing beneficiary name", ErrInvalidTravelRuleData)
+	case !IsValidDAGAddress(d.Originator.Address):
+		return fmt.Errorf("%w: originator: %v", ErrInvalidTravelRuleData, ErrInvalidAddress)
+	case !IsValidDAGAddress(d.Beneficiary.Address):
+		return fmt.Errorf("%w: beneficiary: %v", ErrInvalidTravelRuleData, ErrInvalidAddress)
+	case d.Amount <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidTravelRuleData)
+	}
+	return nil
+}
+
+// TravelRuleEnvelope is travel rule data encrypted to the counterparty
+// VASP's key and bound to the transaction hash. It travels off-chain, signed
+// by the originating VASP as a Signed[TravelRuleEnvelope].
+type TravelRuleEnvelope struct {
+	Version string `json:"version"`
+	// TxHash is the hash of the transaction the data describes
+	TxHash string `json:"txHash"`
+	// OriginatorVASP is the DAG address of the key that signed the envelope
+	OriginatorVASP string `json:"originatorVasp"`
+	// BeneficiaryVASP is the DAG address of the key the data is encrypted to
+	BeneficiaryVASP string `json:"beneficiaryVasp"`
+	// EphemeralKey is the compressed public key hex of the one-time
+	// encryption key
+	EphemeralKey string `json:"ephemeralKey"`
+	// Nonce is the AES-GCM nonce hex
+	Nonce string `json:"nonce"`
+	// Ciphertext is the sealed TravelRuleData JSON hex; the other fields are
+	// bound as additional data
+	Ciphertext string `json:"ciphertext"`
+}
+
+// additionalData is the envelope's cleartext fields, authenticated with the
+// ciphertext
+func (e TravelRuleEnvelope) additionalData() []byte {
+	return []byte(strconv.Quote(e.Version) + strconv.Quote(e.TxHash) +
+		strconv.Quote(e.OriginatorVASP) + strconv.Quote(e.BeneficiaryVASP))
+}
+
+// SealTravelRuleEnvelope encrypts data to the counterparty VASP's public key
+// and signs the envelope with the originating VASP's key. The VASP keys are
+// identity keys agreed between the VASPs, not the transfer's addresses.
+//
+// Example:
+//
+//	envelope, err := SealTravelRuleEnvelope(data, counterpartyPublicKey, vaspSigner)
+//	if err != nil {
+//	    return err
+//	}
+//	payload, _ := json.Marshal(envelope) // deliver to the counterparty VASP
+func SealTravelRuleEnvelope(data *TravelRuleData, counterpartyPublicKey string, signer Signer) (*Signed[TravelRuleEnvelope], error) {
+	if data == nil {
+		return nil, fmt.Errorf("%w: no data", ErrInvalidTravelRuleData)
+	}
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	counterparty, err := parsePublicKeyHex(counterpartyPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := TravelRuleEnvelope{
+		Version:         TravelRuleVersion,
+		TxHash:          data.TxHash,
+		OriginatorVASP:  GetAddress(signer.PublicKey()),
+		BeneficiaryVASP: GetAddress(NormalizePublicKey(counterpartyPublicKey)),
+	}
+	sealed, err := sealToPublicKey(counterparty, []byte(TravelRuleVersion+":"+data.TxHash), plaintext, envelope.additionalData())
+	Zeroize(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	envelope.EphemeralKey = sealed.ephemeralKey
+	envelope.Nonce = sealed.nonce
+	envelope.Ciphertext = sealed.ciphertext
+	return CreateSignedObjectWithSigner(envelope, signer, false)
+}
+
+// VerifyTravelRuleEnvelope checks that the envelope is validly signed by
+// its originating VASP and, if tx is not nil, that it is bound to tx. A
+// non-empty trustedVASPs also requires the originating VASP to be one of
+// those DAG addresses.
+func VerifyTravelRuleEnvelope(envelope *Signed[TravelRuleEnvelope], tx *CurrencyTransaction, trustedVASPs []string) error {
+	if envelope == nil || !Verify(envelope, false).IsValid ||
+		!signedByAny(envelope.Proofs, []string{envelope.Value.OriginatorVASP}) {
+		return ErrTravelRuleSignatureInvalid
+	}
+	if len(trustedVASPs) > 0 && !signedByAny(envelope.Proofs, trustedVASPs) {
+		return ErrTravelRuleUntrustedVASP
+	}
+	if tx != nil && envelope.Value.TxHash != HashCurrencyTransaction(tx).Value {
+		return ErrTravelRuleTransactionMismatch
+	}
+	return nil
+}
+
+// OpenTravelRuleEnvelope verifies the envelope's signature, checks that the
+// originating VASP is one of trustedVASPs (DAG addresses), and decrypts it
+// with the counterparty VASP's private key. trustedVASPs is required: an
+// empty list returns ErrTravelRuleUntrustedVASP, since anyone can seal an
+// envelope to a published key. The data is checked to be bound to the
+// envelope's transaction hash; use VerifyTravelRuleEnvelope and
+// MatchesTransaction to check it against the transaction itself.
+//
+// Example:
+//
+//	if err := VerifyTravelRuleEnvelope(envelope, tx, trustedVASPs); err != nil {
+//	    return err
+//	}
+//	data, err := OpenTravelRuleEnvelope(envelope, vaspPrivateKey, trustedVASPs)
+//	if err != nil {
+//	    return err
+//	}
+//	err = data.MatchesTransaction(tx)
+func OpenTravelRuleEnvelope(envelope *Signed[TravelRuleEnvelope], privateKeyHex string, trustedVASPs []string) (*TravelRuleData, error) {
+	if len(trustedVASPs) == 0 {
+		return nil, fmt.Errorf("%w: no trusted VASPs given", ErrTravelRuleUntrustedVASP)
+	}
+	if err := VerifyTravelRuleEnvelope(envelope, nil, trustedVASPs); err != nil {
+		return nil, err
+	}
+	value := envelope.Value
+	if value.Version != TravelRuleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrTravelRuleDecryptFailed, value.Version)
+	}
+	privateKey, err := parsePrivateKeyHex(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	sealed := sealedBox{
+		ephemeralKey: value.EphemeralKey,
+		nonce:        value.Nonce,
+		ciphertext:   value.Ciphertext,
+	}
+	plaintext, err := sealed.open(privateKey, []byte(TravelRuleVersion+":"+value.TxHash), value.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTravelRuleDecryptFailed, err)
+	}
+	defer Zeroize(plaintext)
+
+	var data TravelRuleData
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTravelRuleData, err)
+	}
+	if data.TxHash != value.TxHash {
+		return nil, ErrTravelRuleTransactionMismatch
+	}
+	return &data, nil
+}
@@ -0,0 +1,93 @@
+package constellation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTravelRuleEnvelope(t *testing.T) {
+	customer, err := GenerateKeyPair()
+	require.NoError(t, err)
+	beneficiary, err := GenerateKeyPair()
+	require.NoError(t, err)
+	originatorVASP, err := GenerateKeyPair()
+	require.NoError(t, err)
+	counterpartyVASP, err := GenerateKeyPair()
+	require.NoError(t, err)
+	vaspSigner, err := NewPrivateKeySigner(originatorVASP.PrivateKey)
+	require.NoError(t, err)
+
+	lastRef := TransactionReference{Hash: strings.Repeat("0", 64), Ordinal: 0}
+	tx, err := CreateCurrencyTransaction(TransferParams{Destination: beneficiary.Address, Amount: 1500}, customer.PrivateKey, lastRef)
+	require.NoError(t, err)
+	other, err := CreateCurrencyTransaction(TransferParams{Destination: beneficiary.Address, Amount: 1}, customer.PrivateKey, lastRef)
+	require.NoError(t, err)
+
+	data, err := NewTravelRuleData(tx,
+		TravelRuleParty{Name: "Alice Example", AccountNumber: "c-1042", DateOfBirth: "1990-04-01"},
+		TravelRuleParty{Name: "Bob Example", VASP: "5493001KJTIIGC8Y1R12"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, customer.Address, data.Originator.Address)
+	assert.Equal(t, beneficiary.Address, data.Beneficiary.Address)
+	assert.Equal(t, tx.Value.Amount, data.Amount)
+
+	envelope, err := SealTravelRuleEnvelope(data, counterpartyVASP.PublicKey, vaspSigner)
+	require.NoError(t, err)
+	assert.Equal(t, TravelRuleVersion, envelope.Value.Version)
+	assert.Equal(t, originatorVASP.Address, envelope.Value.OriginatorVASP)
+	assert.Equal(t, counterpartyVASP.Address, envelope.Value.BeneficiaryVASP)
+	assert.NotContains(t, envelope.Value.Ciphertext, "Alice")
+
+	trusted := []string{originatorVASP.Address}
+
+	t.Run("the counterparty opens it", func(t *testing.T) {
+		require.NoError(t, VerifyTravelRuleEnvelope(envelope, tx, []string{originatorVASP.Address}))
+		opened, err := OpenTravelRuleEnvelope(envelope, counterpartyVASP.PrivateKey, trusted)
+		require.NoError(t, err)
+		assert.Equal(t, data, opened)
+		assert.NoError(t, opened.MatchesTransaction(tx))
+		assert.ErrorIs(t, opened.MatchesTransaction(other), ErrInvalidTravelRuleData)
+	})
+
+	t.Run("only from a trusted VASP", func(t *testing.T) {
+		_, err := OpenTravelRuleEnvelope(envelope, counterpartyVASP.PrivateKey, nil)
+		assert.ErrorIs(t, err, ErrTravelRuleUntrustedVASP)
+		_, err = OpenTravelRuleEnvelope(envelope, counterpartyVASP.PrivateKey, []string{customer.Address})
+		assert.ErrorIs(t, err, ErrTravelRuleUntrustedVASP)
+	})
+
+	t.Run("nobody else can", func(t *testing.T) {
+		_, err := OpenTravelRuleEnvelope(envelope, beneficiary.PrivateKey, trusted)
+		assert.ErrorIs(t, err, ErrTravelRuleDecryptFailed)
+	})
+
+	t.Run("is bound to its transaction and VASPs", func(t *testing.T) {
+		assert.ErrorIs(t, VerifyTravelRuleEnvelope(envelope, other, nil), ErrTravelRuleTransactionMismatch)
+		assert.ErrorIs(t, VerifyTravelRuleEnvelope(envelope, tx, []string{customer.Address}), ErrTravelRuleUntrustedVASP)
+
+		tampered := *envelope
+		tampered.Value.TxHash = HashCurrencyTransaction(other).Value
+		assert.ErrorIs(t, VerifyTravelRuleEnvelope(&tampered, nil, nil), ErrTravelRuleSignatureInvalid)
+
+		// re-signing a moved envelope breaks decryption
+		resigned, err := CreateSignedObjectWithSigner(tampered.Value, vaspSigner, false)
+		require.NoError(t, err)
+		_, err = OpenTravelRuleEnvelope(resigned, counterpartyVASP.PrivateKey, trusted)
+		assert.ErrorIs(t, err, ErrTravelRuleDecryptFailed)
+	})
+
+	t.Run("rejects incomplete or mismatched data", func(t *testing.T) {
+		_, err := NewTravelRuleData(tx, TravelRuleParty{}, TravelRuleParty{Name: "Bob Example"})
+		assert.ErrorContains(t, err, "missing originator name")
+		_, err = NewTravelRuleData(tx, TravelRuleParty{Name: "Alice Example", Address: beneficiary.Address}, TravelRuleParty{Name: "Bob Example"})
+		assert.ErrorIs(t, err, ErrInvalidTravelRuleData)
+		_, err = SealTravelRuleEnvelope(&TravelRuleData{}, counterpartyVASP.PublicKey, vaspSigner)
+		assert.ErrorIs(t, err, ErrInvalidTravelRuleData)
+		_, err = SealTravelRuleEnvelope(data, "04abcd", vaspSigner)
+		assert.ErrorIs(t, err, ErrInvalidPublicKey)
+	})
+}
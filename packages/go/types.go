@@ -12,7 +12,8 @@ const ConstellationPrefix = "\x19Constellation Signed Data:\n"
 
 // SignatureProof contains the signer's public key ID and signature
 type SignatureProof struct {
-	// ID is the public key hex (uncompressed, without 04 prefix) - 128 characters
+	// ID is the public key hex (uncompressed, without 04 prefix) - 128 characters.
+	// Compressed keys (66 characters, 02/03 prefix) are accepted when verifying.
 	ID string `json:"id"`
 	// Signature is the DER-encoded ECDSA signature in hex format
 	Signature string `json:"signature"`
@@ -34,6 +35,10 @@ type KeyPair struct {
 	PublicKey string
 	// Address is the DAG address derived from the public key
 	Address string
+
+	// secret holds the private key bytes for KeyPairFromPrivateKeyBytes and
+	// Signer; nil otherwise
+	secret *keySecret
 }
 
 // Hash holds a hash result with both hex string and raw bytes
@@ -0,0 +1,179 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vectorManifestPath lists the currency transaction vectors of every
+// transaction format, see shared/vectors/README.md
+var vectorManifestPath = filepath.Join("..", "..", "shared", "vectors", "manifest.json")
+
+type vectorManifest struct {
+	Versions []vectorVersion `json:"versions"`
+}
+
+type vectorVersion struct {
+	ID                   string `json:"id"`
+	Description          string `json:"description"`
+	CurrencyTransactions string `json:"currencyTransactions"`
+}
+
+// historicalVectors is a currency transaction vectors file. Only
+// basicTransaction is required, since vectors captured from the network
+// carry no private keys and may omit the other sections.
+type historicalVectors struct {
+	CryptoParams struct {
+		KryoSetReferences bool `json:"kryoSetReferences"`
+	} `json:"cryptoParams"`
+	TestVectors struct {
+		BasicTransaction struct {
+			PublicKeyHex    string                       `json:"publicKeyHex"`
+			Address         string                       `json:"address"`
+			Transaction     testCurrencyTransactionValue `json:"transaction"`
+			EncodedString   string                       `json:"encodedString"`
+			KryoBytesHex    string                       `json:"kryoBytesHex"`
+			TransactionHash string                       `json:"transactionHash"`
+			Signature       string                       `json:"signature"`
+			SignerID        string                       `json:"signerId"`
+		} `json:"basicTransaction"`
+		MultiSignature *struct {
+			TransactionHash string           `json:"transactionHash"`
+			Proofs          []SignatureProof `json:"proofs"`
+		} `json:"multiSignature"`
+		TransactionChaining *struct {
+			Transactions []struct {
+				Hash          string `json:"hash"`
+				Ordinal       int64  `json:"ordinal"`
+				ParentHash    string `json:"parentHash"`
+				ParentOrdinal int64  `json:"parentOrdinal"`
+			} `json:"transactions"`
+		} `json:"transactionChaining"`
+		EdgeCases map[string]struct {
+			Encoded   string `json:"encoded"`
+			Hash      string `json:"hash"`
+			Signature string `json:"signature"`
+		} `json:"edgeCases"`
+	} `json:"testVectors"`
+}
+
+// loadVectorVersions reads the manifest and every vectors file it lists
+func loadVectorVersions(t *testing.T) ([]vectorVersion, []*historicalVectors) {
+	data, err := os.ReadFile(vectorManifestPath)
+	require.NoError(t, err)
+	var manifest vectorManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.NotEmpty(t, manifest.Versions, "manifest lists no versions")
+
+	vectors := make([]*historicalVectors, len(manifest.Versions))
+	for i, version := range manifest.Versions {
+		require.NotEmpty(t, version.ID, "manifest entry %d has no id", i)
+		path := filepath.Join(filepath.Dir(vectorManifestPath), filepath.FromSlash(version.CurrencyTransactions))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, version.ID)
+		vectors[i] = &historicalVectors{}
+		require.NoError(t, json.Unmarshal(data, vectors[i]), version.ID)
+	}
+	return manifest.Versions, vectors
+}
+
+// TestHistoricalVectors runs the currency transaction conformance suite
+// against the vectors of every transaction format in the manifest
+func TestHistoricalVectors(t *testing.T) {
+	versions, vectors := loadVectorVersions(t)
+	for i, version := range versions {
+		t.Run(version.ID, func(t *testing.T) {
+			runCurrencyTransactionConformance(t, vectors[i])
+		})
+	}
+}
+
+func runCurrencyTransactionConformance(t *testing.T, vectors *historicalVectors) {
+	setReferences := vectors.CryptoParams.KryoSetReferences
+	basic := vectors.TestVectors.BasicTransaction
+	tx := &CurrencyTransaction{
+		Value:  basic.Transaction.toCurrencyTransactionValue(),
+		Proofs: []SignatureProof{{ID: basic.SignerID, Signature: basic.Signature}},
+	}
+
+	t.Run("derives the source address", func(t *testing.T) {
+		if basic.PublicKeyHex == "" {
+			t.Skip("no public key")
+		}
+		assert.Equal(t, basic.Transaction.Source, GetAddress(basic.PublicKeyHex))
+		if basic.Address != "" {
+			assert.Equal(t, basic.Address, GetAddress(basic.PublicKeyHex))
+		}
+	})
+
+	t.Run("encodes and hashes the transaction", func(t *testing.T) {
+		encoded := encodeTransaction(tx)
+		if basic.EncodedString != "" {
+			assert.Equal(t, basic.EncodedString, encoded)
+		}
+		serialized := kryoSerialize(encoded, setReferences)
+		if basic.KryoBytesHex != "" {
+			assert.Equal(t, basic.KryoBytesHex, hex.EncodeToString(serialized))
+		}
+		assert.Equal(t, basic.TransactionHash, HashBytes(serialized).Value)
+		if !setReferences {
+			assert.Equal(t, basic.TransactionHash, HashCurrencyTransaction(tx).Value)
+		}
+	})
+
+	t.Run("verifies the signature", func(t *testing.T) {
+		valid, err := VerifyHash(basic.TransactionHash, basic.Signature, basic.SignerID)
+		require.NoError(t, err)
+		assert.True(t, valid)
+		if !setReferences {
+			assert.True(t, VerifyCurrencyTransaction(tx).IsValid)
+		}
+	})
+
+	t.Run("verifies multiple signatures", func(t *testing.T) {
+		multiSig := vectors.TestVectors.MultiSignature
+		if multiSig == nil {
+			t.Skip("no multi-signature vectors")
+		}
+		require.Equal(t, basic.TransactionHash, multiSig.TransactionHash)
+		for _, proof := range multiSig.Proofs {
+			valid, err := VerifyHash(multiSig.TransactionHash, proof.Signature, proof.ID)
+			require.NoError(t, err)
+			assert.True(t, valid, proof.ID)
+		}
+	})
+
+	t.Run("chains transactions", func(t *testing.T) {
+		chaining := vectors.TestVectors.TransactionChaining
+		if chaining == nil {
+			t.Skip("no chaining vectors")
+		}
+		chain := chaining.Transactions
+		for i := 1; i < len(chain); i++ {
+			assert.Equal(t, chain[i-1].Hash, chain[i].ParentHash)
+			assert.Equal(t, chain[i-1].Ordinal, chain[i].ParentOrdinal)
+			assert.Equal(t, chain[i].ParentOrdinal+1, chain[i].Ordinal)
+		}
+	})
+
+	t.Run("hashes and verifies edge cases", func(t *testing.T) {
+		if len(vectors.TestVectors.EdgeCases) == 0 {
+			t.Skip("no edge case vectors")
+		}
+		for name, edgeCase := range vectors.TestVectors.EdgeCases {
+			if edgeCase.Encoded == "" {
+				continue
+			}
+			assert.Equal(t, edgeCase.Hash, HashBytes(kryoSerialize(edgeCase.Encoded, setReferences)).Value, name)
+			valid, err := VerifyHash(edgeCase.Hash, edgeCase.Signature, basic.SignerID)
+			require.NoError(t, err, name)
+			assert.True(t, valid, name)
+		}
+	})
+}
@@ -1,12 +1,5 @@
 package constellation
 
-import (
-	"encoding/hex"
-
-	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
-)
-
 // Verify verifies a signed object
 func Verify[T any](signed *Signed[T], isDataUpdate bool) *VerificationResult {
 	// Compute the hash that should have been signed
@@ -41,34 +34,9 @@ func Verify[T any](signed *Signed[T], isDataUpdate bool) *VerificationResult {
 
 // VerifyHash verifies a signature against a SHA-256 hash
 func VerifyHash(hashHex string, signatureHex string, publicKeyID string) (bool, error) {
-	// Normalize and parse public key
-	fullPublicKey := NormalizePublicKey(publicKeyID)
-	publicKeyBytes, err := hex.DecodeString(fullPublicKey)
-	if err != nil {
-		return false, err
-	}
-
-	publicKey, err := btcec.ParsePubKey(publicKeyBytes)
-	if err != nil {
-		return false, err
-	}
-
-	// Parse signature
-	signatureBytes, err := hex.DecodeString(signatureHex)
-	if err != nil {
-		return false, err
-	}
-
-	signature, err := ecdsa.ParseDERSignature(signatureBytes)
-	if err != nil {
-		return false, err
-	}
-
-	// Compute signing digest
-	digest := ComputeDigestFromHash(hashHex)
-
-	// Verify signature
-	return signature.Verify(digest, publicKey), nil
+	scratch := acquireVerifyScratch()
+	defer releaseVerifyScratch(scratch)
+	return scratch.VerifyHash(hashHex, signatureHex, publicKeyID)
 }
 
 // VerifySignature verifies a single signature proof against data
@@ -80,3 +48,20 @@ func VerifySignature(data interface{}, proof *SignatureProof, isDataUpdate bool)
 	hash := HashBytes(bytes)
 	return VerifyHash(hash.Value, proof.Signature, proof.ID)
 }
+
+// DistinctSigners returns the signer IDs of proofs without duplicates, in
+// the order first seen. IDs are normalized with NormalizePublicKeyToID, so a
+// key that signs under both its compressed and uncompressed ID counts once.
+// Count these, never raw proof IDs, toward a multi-signature threshold.
+func DistinctSigners(proofs []SignatureProof) []string {
+	signers := make([]string, 0, len(proofs))
+	seen := make(map[string]bool, len(proofs))
+	for _, proof := range proofs {
+		id := NormalizePublicKeyToID(proof.ID)
+		if !seen[id] {
+			seen[id] = true
+			signers = append(signers, id)
+		}
+	}
+	return signers
+}
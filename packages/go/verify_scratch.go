@@ -0,0 +1,189 @@
+package constellation
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// maxDERSignatureLen is the longest DER-encoded secp256k1 signature
+const maxDERSignatureLen = 72
+
+// VerifyScratch holds reusable buffers for hex decoding, transaction
+// serialization and digest computation. Indexers verifying full snapshot
+// archives can keep one per goroutine to avoid allocating those buffers on
+// every signature.
+//
+// A VerifyScratch is not safe for concurrent use. The package-level Verify*
+// functions draw one from an internal pool, so callers that do not manage
+// their own get most of the benefit automatically.
+//
+// Example:
+//
+//	scratch := NewVerifyScratch()
+//	for _, tx := range snapshot.Transactions {
+//	    if !scratch.VerifyCurrencyTransaction(tx).IsValid {
+//	        rejected++
+//	    }
+//	}
+type VerifyScratch struct {
+	publicKey   [65]byte
+	signature   [maxDERSignatureLen]byte
+	hashHex     [64]byte
+	digest      [sha512.Size]byte
+	digestInput []byte
+	serialized  []byte
+}
+
+var verifyScratchPool = sync.Pool{
+	New: func() interface{} { return NewVerifyScratch() },
+}
+
+// NewVerifyScratch creates a VerifyScratch
+func NewVerifyScratch() *VerifyScratch {
+	return &VerifyScratch{
+		digestInput: make([]byte, 0, 64),
+		serialized:  make([]byte, 0, 512),
+	}
+}
+
+// VerifyHash verifies a signature against a SHA-256 hash, like the
+// package-level VerifyHash
+func (s *VerifyScratch) VerifyHash(hashHex string, signatureHex string, publicKeyID string) (bool, error) {
+	return s.verify(NormalizePublicKey(publicKeyID), hashHex, signatureHex)
+}
+
+// VerifyCurrencyTransaction verifies all signatures on a currency transaction,
+// like the package-level VerifyCurrencyTransaction
+func (s *VerifyScratch) VerifyCurrencyTransaction(tx *CurrencyTransaction) *VerificationResult {
+	hashHex, ok := s.hashCurrencyTransaction(tx)
+	if !ok || !validSalt(tx.Value.Salt) {
+		return &VerificationResult{
+			IsValid:       false,
+			ValidProofs:   []SignatureProof{},
+			InvalidProofs: tx.Proofs,
+		}
+	}
+
+	validProofs := []SignatureProof{}
+	invalidProofs := []SignatureProof{}
+
+	for _, proof := range tx.Proofs {
+		isValid := proofLooksValid(proof)
+		if isValid {
+			isValid, _ = s.verify(proofPublicKey(proof.ID), hashHex, proof.Signature)
+		}
+		if isValid {
+			validProofs = append(validProofs, proof)
+		} else {
+			invalidProofs = append(invalidProofs, proof)
+		}
+	}
+
+	return &VerificationResult{
+		IsValid:       len(invalidProofs) == 0 && len(validProofs) > 0,
+		ValidProofs:   validProofs,
+		InvalidProofs: invalidProofs,
+	}
+}
+
+// VerifyCurrencyTransactionStrict reports whether every proof on tx is valid,
+// like the package-level VerifyCurrencyTransactionStrict
+func (s *VerifyScratch) VerifyCurrencyTransactionStrict(tx *CurrencyTransaction) bool {
+	if len(tx.Proofs) == 0 || !validSalt(tx.Value.Salt) {
+		return false
+	}
+	for _, proof := range tx.Proofs {
+		if !proofLooksValid(proof) {
+			return false
+		}
+	}
+
+	hashHex, ok := s.hashCurrencyTransaction(tx)
+	if !ok {
+		return false
+	}
+	for _, proof := range tx.Proofs {
+		if isValid, _ := s.verify(proofPublicKey(proof.ID), hashHex, proof.Signature); !isValid {
+			return false
+		}
+	}
+	return true
+}
+
+// hashCurrencyTransaction hashes tx, reporting false when its encoding is
+// too large for Kryo, so no signature over it can be valid
+func (s *VerifyScratch) hashCurrencyTransaction(tx *CurrencyTransaction) (string, bool) {
+	encoded := encodeTransaction(tx)
+	if checkKryoLength(len(encoded)) != nil {
+		return "", false
+	}
+	s.serialized = appendKryoSerialized(s.serialized[:0], encoded, false)
+	hashBytes := sha256.Sum256(s.serialized)
+	hex.Encode(s.hashHex[:], hashBytes[:])
+	return string(s.hashHex[:]), true
+}
+
+// proofPublicKey returns the public key hex btcec parses for a proof ID:
+// compressed IDs as they are, uncompressed IDs with the 04 prefix restored
+func proofPublicKey(id string) string {
+	if isCompressedPublicKey(id) {
+		return id
+	}
+	return "04" + id
+}
+
+// verify checks a DER signature over the Constellation digest of hashHex
+func (s *VerifyScratch) verify(publicKeyHex string, hashHex string, signatureHex string) (bool, error) {
+	publicKeyBytes, err := decodeHexInto(s.publicKey[:], publicKeyHex)
+	if err != nil {
+		return false, err
+	}
+	publicKey, err := btcec.ParsePubKey(publicKeyBytes)
+	if err != nil {
+		return false, err
+	}
+
+	signatureBytes, err := decodeHexInto(s.signature[:], signatureHex)
+	if err != nil {
+		return false, err
+	}
+	signature, err := ecdsa.ParseDERSignature(signatureBytes)
+	if err != nil {
+		return false, err
+	}
+
+	return signature.Verify(s.computeDigest(hashHex), publicKey), nil
+}
+
+// computeDigest is ComputeDigestFromHash without the intermediate allocations
+func (s *VerifyScratch) computeDigest(hashHex string) []byte {
+	s.digestInput = append(s.digestInput[:0], hashHex...)
+	s.digest = sha512.Sum512(s.digestInput)
+	return s.digest[:32]
+}
+
+// decodeHexInto decodes src into buf when it fits, falling back to a fresh
+// allocation for oversized input so errors match hex.DecodeString
+func decodeHexInto(buf []byte, src string) ([]byte, error) {
+	if len(src) > 2*len(buf) {
+		return hex.DecodeString(src)
+	}
+	n, err := hex.Decode(buf, []byte(src))
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func acquireVerifyScratch() *VerifyScratch {
+	return verifyScratchPool.Get().(*VerifyScratch)
+}
+
+func releaseVerifyScratch(s *VerifyScratch) {
+	verifyScratchPool.Put(s)
+}
@@ -0,0 +1,65 @@
+package constellation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyScratch(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+	dest, _ := GenerateKeyPair()
+
+	txs, err := CreateCurrencyTransactionBatch([]TransferParams{
+		{Destination: dest.Address, Amount: 1},
+		{Destination: dest.Address, Amount: 2},
+		{Destination: dest.Address, Amount: 3},
+	}, kp.PrivateKey, GenesisReference)
+	require.NoError(t, err)
+
+	t.Run("reused scratch verifies every transaction", func(t *testing.T) {
+		scratch := NewVerifyScratch()
+		for _, tx := range txs {
+			result := scratch.VerifyCurrencyTransaction(tx)
+			assert.True(t, result.IsValid)
+			assert.Len(t, result.ValidProofs, 1)
+		}
+	})
+
+	t.Run("detects tampered transactions after a valid one", func(t *testing.T) {
+		scratch := NewVerifyScratch()
+		require.True(t, scratch.VerifyCurrencyTransaction(txs[0]).IsValid)
+
+		tampered := *txs[1]
+		tampered.Value.Amount++
+		result := scratch.VerifyCurrencyTransaction(&tampered)
+		assert.False(t, result.IsValid)
+		assert.Len(t, result.InvalidProofs, 1)
+	})
+
+	t.Run("VerifyHash matches the package-level function", func(t *testing.T) {
+		hash, _ := HashData(map[string]string{"k": "v"}, false)
+		signature, err := SignHash(hash.Value, kp.PrivateKey)
+		require.NoError(t, err)
+
+		scratch := NewVerifyScratch()
+		ok, err := scratch.VerifyHash(hash.Value, signature, kp.PublicKey)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, scratchErr := scratch.VerifyHash(hash.Value, "zz", kp.PublicKey)
+		_, pkgErr := VerifyHash(hash.Value, "zz", kp.PublicKey)
+		assert.Error(t, scratchErr)
+		assert.Equal(t, pkgErr, scratchErr)
+	})
+
+	t.Run("oversized hex input is rejected, not truncated", func(t *testing.T) {
+		scratch := NewVerifyScratch()
+		ok, err := scratch.VerifyHash(strings.Repeat("a", 64), strings.Repeat("30", 200), kp.PublicKey)
+		assert.False(t, ok)
+		assert.Error(t, err)
+	})
+}
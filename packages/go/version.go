@@ -0,0 +1,6 @@
+package constellation
+
+// Version is the SDK release version. It follows semantic versioning: exported
+// identifiers recorded in testdata/api.txt are only removed or changed in a
+// new major version, which gets a new module path (…/packages/go/v2).
+const Version = "2.0.0"
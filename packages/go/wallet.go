@@ -4,12 +4,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 )
 
-const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+const base58Alphabet = Base58Alphabet
 
 // GenerateKeyPair creates a new random key pair
 func GenerateKeyPair() (*KeyPair, error) {
@@ -18,7 +19,15 @@ func GenerateKeyPair() (*KeyPair, error) {
 		return nil, fmt.Errorf("failed to generate private key: %w", err)
 	}
 
-	privateKeyHex := hex.EncodeToString(privateKey.Serialize())
+	defer privateKey.Zero()
+	privateKeyBytes := privateKey.Serialize()
+	defer Zeroize(privateKeyBytes)
+	secret, err := newKeySecret(privateKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKeyHex := hex.EncodeToString(privateKeyBytes)
 	publicKeyHex := hex.EncodeToString(privateKey.PubKey().SerializeUncompressed())
 	address := GetAddress(publicKeyHex)
 
@@ -26,6 +35,7 @@ func GenerateKeyPair() (*KeyPair, error) {
 		PrivateKey: privateKeyHex,
 		PublicKey:  publicKeyHex,
 		Address:    address,
+		secret:     secret,
 	}, nil
 }
 
@@ -37,10 +47,17 @@ func KeyPairFromPrivateKey(privateKeyHex string) (*KeyPair, error) {
 
 	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
 	if err != nil {
-		return nil, fmt.Errorf("invalid hex string: %w", err)
+		return nil, invalidPrivateKeyHex(err)
+	}
+
+	defer Zeroize(privateKeyBytes)
+	secret, err := newKeySecret(privateKeyBytes)
+	if err != nil {
+		return nil, err
 	}
 
 	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
+	defer privateKey.Zero()
 	publicKeyHex := hex.EncodeToString(privateKey.PubKey().SerializeUncompressed())
 	address := GetAddress(publicKeyHex)
 
@@ -48,14 +65,90 @@ func KeyPairFromPrivateKey(privateKeyHex string) (*KeyPair, error) {
 		PrivateKey: privateKeyHex,
 		PublicKey:  publicKeyHex,
 		Address:    address,
+		secret:     secret,
 	}, nil
 }
 
+// DeriveKeyPair derives the key pair at the BIP44 DAG path
+// m/44'/1137'/account'/0/index from a BIP39 mnemonic, matching the addresses
+// dag4.js derives from the same phrase. Account and index must be below
+// 2^31.
+//
+// Example:
+//
+//	// the first three addresses of the default account
+//	for i := uint32(0); i < 3; i++ {
+//	    keyPair, err := DeriveKeyPair(phrase, 0, i)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(keyPair.Address)
+//	}
+func DeriveKeyPair(mnemonic string, account uint32, index uint32) (*KeyPair, error) {
+	return DeriveKeyPairWithPassphrase(mnemonic, "", account, index)
+}
+
+// DeriveKeyPairWithPassphrase is DeriveKeyPair for a mnemonic protected by
+// a BIP39 passphrase (the "25th word" of hardware wallets). Every passphrase
+// derives a different, valid wallet, so a mistyped one is not an error; check
+// the address against one you expect. An empty passphrase is DeriveKeyPair.
+//
+// Example:
+//
+//	keyPair, err := DeriveKeyPairWithPassphrase(phrase, passphrase, 0, 0)
+//	if err == nil && keyPair.Address != expectedAddress {
+//	    return errors.New("wrong passphrase")
+//	}
+func DeriveKeyPairWithPassphrase(mnemonic string, passphrase string, account uint32, index uint32) (*KeyPair, error) {
+	keyPairs, err := DeriveKeyPairsWithPassphrase(mnemonic, passphrase, account, index, 1)
+	if err != nil {
+		return nil, err
+	}
+	return keyPairs[0], nil
+}
+
+// DeriveKeyPairs derives count consecutive key pairs of an account starting
+// at index. It stretches the mnemonic once, so it is much faster than
+// calling DeriveKeyPair in a loop.
+func DeriveKeyPairs(mnemonic string, account uint32, index uint32, count int) ([]*KeyPair, error) {
+	return DeriveKeyPairsWithPassphrase(mnemonic, "", account, index, count)
+}
+
+// DeriveKeyPairsWithPassphrase is DeriveKeyPairs for a mnemonic protected by
+// a BIP39 passphrase
+func DeriveKeyPairsWithPassphrase(mnemonic string, passphrase string, account uint32, index uint32, count int) ([]*KeyPair, error) {
+	if account >= hardenedKeyOffset || index >= hardenedKeyOffset || count < 1 ||
+		uint64(index)+uint64(count) > hardenedKeyOffset {
+		return nil, ErrInvalidDerivationPath
+	}
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	path := dagBIP44Path(account, 0)
+	chain, err := deriveHDKey(seed, path[:len(path)-1]...)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPairs := make([]*KeyPair, count)
+	for i := range keyPairs {
+		key, err := chain.child(index + uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		if keyPairs[i], err = key.keyPair(); err != nil {
+			return nil, err
+		}
+	}
+	return keyPairs, nil
+}
+
 // GetPublicKeyHex returns the public key hex from a private key
 func GetPublicKeyHex(privateKeyHex string, compressed bool) (string, error) {
 	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
 	if err != nil {
-		return "", fmt.Errorf("invalid hex string: %w", err)
+		return "", invalidPrivateKeyHex(err)
 	}
 
 	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
@@ -76,12 +169,13 @@ func GetPublicKeyID(privateKeyHex string) (string, error) {
 	return NormalizePublicKeyToID(publicKey), nil
 }
 
-// GetAddress derives a DAG address from a public key
+// GetAddress derives a DAG address from a public key, uncompressed (with or
+// without the 04 prefix) or compressed (02/03 prefix)
 func GetAddress(publicKeyHex string) string {
 	// PKCS prefix for X.509 DER encoding (secp256k1)
 	pkcsPrefix := "3056301006072a8648ce3d020106052b8104000a034200"
 
-	// Normalize public key to include 04 prefix
+	// Normalize public key to uncompressed with the 04 prefix
 	normalizedKey := NormalizePublicKey(publicKeyHex)
 
 	// Prepend PKCS prefix
@@ -110,7 +204,7 @@ func GetAddress(publicKeyHex string) string {
 	parity := digitSum % 9
 
 	// Return with DAG prefix, parity, and last36
-	return fmt.Sprintf("DAG%d%s", parity, last36)
+	return "DAG" + strconv.Itoa(parity) + last36
 }
 
 // IsValidPrivateKey validates that a private key is correctly formatted
@@ -126,8 +220,14 @@ func IsValidPrivateKey(privateKeyHex string) bool {
 	return true
 }
 
-// IsValidPublicKey validates that a public key is correctly formatted
+// IsValidPublicKey validates that a public key is correctly formatted:
+// uncompressed with or without the 04 prefix, or compressed with a 02/03
+// prefix. Compressed keys must also be a point on the curve.
 func IsValidPublicKey(publicKeyHex string) bool {
+	if isCompressedPublicKey(publicKeyHex) {
+		_, ok := decompressPublicKey(publicKeyHex)
+		return ok
+	}
 	// With 04 prefix: 130 chars, without: 128 chars
 	if len(publicKeyHex) != 128 && len(publicKeyHex) != 130 {
 		return false
@@ -140,22 +240,54 @@ func IsValidPublicKey(publicKeyHex string) bool {
 	return true
 }
 
-// NormalizePublicKey ensures the public key has the 04 prefix
+// NormalizePublicKey ensures the public key is uncompressed with the 04
+// prefix. Compressed keys are decompressed; keys that are not on the curve
+// are returned unchanged.
 func NormalizePublicKey(publicKeyHex string) string {
 	if len(publicKeyHex) == 128 {
 		return "04" + publicKeyHex
 	}
+	if uncompressed, ok := decompressPublicKey(publicKeyHex); ok {
+		return uncompressed
+	}
 	return publicKeyHex
 }
 
-// NormalizePublicKeyToID returns the public key without the 04 prefix
+// NormalizePublicKeyToID returns the public key without the 04 prefix,
+// decompressing compressed keys first
 func NormalizePublicKeyToID(publicKeyHex string) string {
+	if uncompressed, ok := decompressPublicKey(publicKeyHex); ok {
+		publicKeyHex = uncompressed
+	}
 	if len(publicKeyHex) == 130 && strings.HasPrefix(publicKeyHex, "04") {
 		return publicKeyHex[2:]
 	}
 	return publicKeyHex
 }
 
+// isCompressedPublicKey reports whether a key has the shape of a compressed
+// public key: 66 hex characters with a 02 or 03 prefix
+func isCompressedPublicKey(publicKeyHex string) bool {
+	return len(publicKeyHex) == 66 && publicKeyHex[0] == '0' && (publicKeyHex[1] == '2' || publicKeyHex[1] == '3')
+}
+
+// decompressPublicKey returns the uncompressed hex (with 04 prefix) of a
+// compressed public key
+func decompressPublicKey(publicKeyHex string) (string, bool) {
+	if !isCompressedPublicKey(publicKeyHex) {
+		return "", false
+	}
+	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		return "", false
+	}
+	publicKey, err := btcec.ParsePubKey(publicKeyBytes)
+	if err != nil {
+		return "", false
+	}
+	return hex.EncodeToString(publicKey.SerializeUncompressed()), true
+}
+
 func isHexChar(c rune) bool {
 	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
 }
@@ -206,3 +338,35 @@ func base58Encode(data []byte) string {
 
 	return string(result)
 }
+
+// base58Decode decodes a Bitcoin/Constellation alphabet string; ok is false
+// when s contains a character outside the alphabet
+func base58Decode(s string) (decoded []byte, ok bool) {
+	leadingZeros := 0
+	for leadingZeros < len(s) && s[leadingZeros] == '1' {
+		leadingZeros++
+	}
+
+	var bytes []byte
+	for i := 0; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, false
+		}
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	result := make([]byte, leadingZeros, leadingZeros+len(bytes))
+	for i := len(bytes) - 1; i >= 0; i-- {
+		result = append(result, bytes[i])
+	}
+	return result, true
+}
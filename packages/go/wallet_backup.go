@@ -0,0 +1,301 @@
+package constellation
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidBackup indicates data that is not a readable wallet backup,
+	// or a backup whose keys do not match its accounts
+	ErrInvalidBackup = errors.New("invalid wallet backup")
+	// ErrBackupPassphrase indicates a wrong passphrase or a corrupted backup
+	ErrBackupPassphrase = errors.New("incorrect backup passphrase or corrupted backup")
+	// ErrBackupPassphraseRequired indicates Backup was called without a
+	// passphrase
+	ErrBackupPassphraseRequired = errors.New("backup passphrase is required")
+	// ErrBackupUnsupported indicates a backup version or cipher the SDK does
+	// not read
+	ErrBackupUnsupported = errors.New("unsupported wallet backup")
+)
+
+const (
+	backupFormat  = "metakit-wallet-backup"
+	backupVersion = 1
+	backupCipher  = "aes-256-gcm"
+)
+
+// WalletBackup is what a wallet application saves and restores: its
+// accounts, address book and scanner checkpoints, and only when explicitly
+// requested, its secrets
+type WalletBackup struct {
+	CreatedAt   time.Time             `json:"createdAt"`
+	Accounts    []BackupAccount       `json:"accounts"`
+	AddressBook []AddressBookEntry    `json:"addressBook,omitempty"`
+	Checkpoints map[string]Checkpoint `json:"checkpoints,omitempty"`
+	// Secrets is written only with BackupOptions.IncludeSecrets
+	Secrets *BackupSecrets `json:"secrets,omitempty"`
+}
+
+// BackupAccount describes one wallet account
+type BackupAccount struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	PublicKey string `json:"publicKey,omitempty"`
+	// HDPath is where the account's key derives from the wallet mnemonic;
+	// nil for imported keys
+	HDPath   *HDPath           `json:"hdPath,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// HDPath is a DAG BIP44 derivation path, m/44'/1137'/account'/0/index, as
+// used by DeriveKeyPair
+type HDPath struct {
+	Account uint32 `json:"account"`
+	Index   uint32 `json:"index"`
+}
+
+// String formats the full path, e.g. m/44'/1137'/0'/0/3
+func (p HDPath) String() string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0/%d", dagCoinType, p.Account, p.Index)
+}
+
+// AddressBookEntry is a saved counterparty address
+type AddressBookEntry struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Memo    string `json:"memo,omitempty"`
+}
+
+// BackupSecrets holds key material, included only on request
+type BackupSecrets struct {
+	// Mnemonic is the BIP39 phrase accounts with an HDPath derive from
+	Mnemonic string `json:"mnemonic,omitempty"`
+	// MnemonicPassphrase is the mnemonic's BIP39 passphrase, if it has one
+	MnemonicPassphrase string `json:"mnemonicPassphrase,omitempty"`
+	// PrivateKeys maps addresses of imported accounts to their hex keys
+	PrivateKeys map[string]string `json:"privateKeys,omitempty"`
+}
+
+// BackupOptions controls what Backup writes
+type BackupOptions struct {
+	// IncludeSecrets writes WalletBackup.Secrets. Without it the secrets
+	// are dropped, so a backup never holds keys by accident.
+	IncludeSecrets bool
+	// Scrypt sets the passphrase stretching cost (default:
+	// StandardScryptParams)
+	Scrypt ScryptParams
+}
+
+// backupEnvelope is the encrypted backup file
+type backupEnvelope struct {
+	Format     string            `json:"format"`
+	Version    int               `json:"version"`
+	Cipher     string            `json:"cipher"`
+	KDF        string            `json:"kdf"`
+	KDFParams  keyStoreKDFParams `json:"kdfparams"`
+	Nonce      []byte            `json:"nonce"`
+	CipherText []byte            `json:"ciphertext"`
+}
+
+// Backup encrypts a wallet backup with passphrase (scrypt and AES-256-GCM).
+// Secrets are written only with options.IncludeSecrets; the passphrase is
+// required either way, since addresses and checkpoints are private too.
+// CreatedAt is set when zero.
+//
+// Example:
+//
+//	data, err := constellation.Backup(constellation.WalletBackup{
+//	    Accounts: []constellation.BackupAccount{
+//	        {Name: "main", Address: keyPair.Address, HDPath: &constellation.HDPath{Account: 0, Index: 0}},
+//	    },
+//	    AddressBook: []constellation.AddressBookEntry{{Name: "exchange", Address: "DAG..."}},
+//	    Secrets:     &constellation.BackupSecrets{Mnemonic: phrase},
+//	}, passphrase, constellation.BackupOptions{IncludeSecrets: true})
+//
+//	restored, err := constellation.Restore(data, passphrase)
+func Backup(backup WalletBackup, passphrase string, options BackupOptions) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrBackupPassphraseRequired
+	}
+	if !options.IncludeSecrets {
+		backup.Secrets = nil
+	}
+	if err := validateBackup(&backup); err != nil {
+		return nil, err
+	}
+	if backup.CreatedAt.IsZero() {
+		backup.CreatedAt = time.Now().UTC()
+	}
+	params := options.Scrypt
+	if params == (ScryptParams{}) {
+		params = StandardScryptParams
+	}
+	// refuse costs Restore would not read back
+	kdfParams := keyStoreKDFParams{DKLen: 32, N: params.N, R: params.R, P: params.P}
+	if err := checkKDFParams("scrypt", kdfParams, ErrBackupUnsupported, ErrBackupUnsupported); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(backup)
+	if err != nil {
+		return nil, err
+	}
+	random := make([]byte, 32+12)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, nonce := random[:32], random[32:]
+
+	key, err := scryptKey([]byte(passphrase), salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupUnsupported, err)
+	}
+	aead, err := backupAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(backupEnvelope{
+		Format:  backupFormat,
+		Version: backupVersion,
+		Cipher:  backupCipher,
+		KDF:     "scrypt",
+		KDFParams: keyStoreKDFParams{
+			DKLen: 32,
+			N:     params.N,
+			R:     params.R,
+			P:     params.P,
+			Salt:  hex.EncodeToString(salt),
+		},
+		Nonce:      nonce,
+		CipherText: aead.Seal(nil, nonce, plaintext, []byte(backupFormat)),
+	})
+}
+
+// Restore decrypts a backup written by Backup. Returns ErrBackupPassphrase
+// for a wrong passphrase, and ErrInvalidBackup when an included key or
+// mnemonic does not derive its account's address.
+func Restore(data []byte, passphrase string) (*WalletBackup, error) {
+	var envelope backupEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Format != backupFormat {
+		return nil, fmt.Errorf("%w: not a wallet backup", ErrInvalidBackup)
+	}
+	if envelope.Version != backupVersion || envelope.Cipher != backupCipher {
+		return nil, fmt.Errorf("%w: version %d, cipher %q", ErrBackupUnsupported, envelope.Version, envelope.Cipher)
+	}
+
+	params := envelope.KDFParams
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || envelope.KDF != "scrypt" {
+		return nil, fmt.Errorf("%w: malformed key derivation", ErrInvalidBackup)
+	}
+	if err := checkKDFParams(envelope.KDF, params, ErrInvalidBackup, ErrBackupUnsupported); err != nil {
+		return nil, err
+	}
+	key, err := scryptKey([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	aead, err := backupAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed nonce", ErrInvalidBackup)
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.CipherText, []byte(backupFormat))
+	if err != nil {
+		return nil, ErrBackupPassphrase
+	}
+
+	var backup WalletBackup
+	if err := json.Unmarshal(plaintext, &backup); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if err := validateBackup(&backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// BackupCheckpoints reads the named checkpoints from store for a backup;
+// names with no checkpoint are skipped
+func BackupCheckpoints(store CheckpointStore, names ...string) (map[string]Checkpoint, error) {
+	checkpoints := map[string]Checkpoint{}
+	for _, name := range names {
+		checkpoint, err := store.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil {
+			checkpoints[name] = *checkpoint
+		}
+	}
+	return checkpoints, nil
+}
+
+// RestoreCheckpoints saves a backup's checkpoints to store
+func RestoreCheckpoints(store CheckpointStore, checkpoints map[string]Checkpoint) error {
+	for name, checkpoint := range checkpoints {
+		if err := store.Save(name, checkpoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBackup checks that every address is valid and that included
+// secrets derive the addresses they belong to
+func validateBackup(backup *WalletBackup) error {
+	for _, account := range backup.Accounts {
+		if !IsValidDAGAddress(account.Address) {
+			return fmt.Errorf("%w: account %q has an invalid address", ErrInvalidBackup, account.Name)
+		}
+	}
+	for _, entry := range backup.AddressBook {
+		if !IsValidDAGAddress(entry.Address) {
+			return fmt.Errorf("%w: address book entry %q has an invalid address", ErrInvalidBackup, entry.Name)
+		}
+	}
+	if backup.Secrets == nil {
+		return nil
+	}
+
+	for address, privateKey := range backup.Secrets.PrivateKeys {
+		keyPair, err := KeyPairFromPrivateKey(privateKey)
+		if err != nil || keyPair.Address != address {
+			return fmt.Errorf("%w: private key does not match %s", ErrInvalidBackup, RedactAddress(address))
+		}
+	}
+	if backup.Secrets.Mnemonic == "" {
+		return nil
+	}
+	for _, account := range backup.Accounts {
+		if account.HDPath == nil {
+			continue
+		}
+		keyPair, err := DeriveKeyPairWithPassphrase(backup.Secrets.Mnemonic, backup.Secrets.MnemonicPassphrase, account.HDPath.Account, account.HDPath.Index)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if keyPair.Address != account.Address {
+			return fmt.Errorf("%w: %s does not derive %s", ErrInvalidBackup, account.HDPath, RedactAddress(account.Address))
+		}
+	}
+	return nil
+}
+
+func backupAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
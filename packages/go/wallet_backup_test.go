@@ -0,0 +1,139 @@
+package constellation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletBackup(t *testing.T) {
+	phrase := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	derived, err := DeriveKeyPair(phrase, 0, 1)
+	require.NoError(t, err)
+	imported, _ := GenerateKeyPair()
+	friend, _ := GenerateKeyPair()
+	// light costs keep the test fast
+	options := BackupOptions{Scrypt: LightScryptParams}
+
+	wallet := WalletBackup{
+		Accounts: []BackupAccount{
+			{Name: "main", Address: derived.Address, PublicKey: derived.PublicKey, HDPath: &HDPath{Account: 0, Index: 1}},
+			{Name: "cold", Address: imported.Address, Metadata: map[string]string{"color": "blue"}},
+		},
+		AddressBook: []AddressBookEntry{{Name: "friend", Address: friend.Address, Memo: "rent"}},
+		Checkpoints: map[string]Checkpoint{"deposit-scanner": {Ordinal: 42, Hash: "abc", UpdatedAt: time.Unix(1700000000, 0).UTC()}},
+		Secrets: &BackupSecrets{
+			Mnemonic:    phrase,
+			PrivateKeys: map[string]string{imported.Address: imported.PrivateKey},
+		},
+	}
+
+	t.Run("omits secrets unless requested", func(t *testing.T) {
+		data, err := Backup(wallet, "correct horse", options)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), derived.Address)
+
+		restored, err := Restore(data, "correct horse")
+		require.NoError(t, err)
+		assert.Nil(t, restored.Secrets)
+		assert.Equal(t, wallet.Accounts, restored.Accounts)
+		assert.Equal(t, wallet.AddressBook, restored.AddressBook)
+		assert.Equal(t, wallet.Checkpoints, restored.Checkpoints)
+		assert.False(t, restored.CreatedAt.IsZero())
+		assert.Equal(t, "m/44'/1137'/0'/0/1", restored.Accounts[0].HDPath.String())
+		assert.NotNil(t, wallet.Secrets, "the caller's backup is not modified")
+	})
+
+	t.Run("includes secrets on request", func(t *testing.T) {
+		withSecrets := options
+		withSecrets.IncludeSecrets = true
+		data, err := Backup(wallet, "correct horse", withSecrets)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), imported.PrivateKey)
+
+		restored, err := Restore(data, "correct horse")
+		require.NoError(t, err)
+		require.NotNil(t, restored.Secrets)
+		assert.Equal(t, phrase, restored.Secrets.Mnemonic)
+		assert.Equal(t, imported.PrivateKey, restored.Secrets.PrivateKeys[imported.Address])
+	})
+
+	t.Run("rejects a wrong passphrase or tampering", func(t *testing.T) {
+		data, err := Backup(wallet, "correct horse", options)
+		require.NoError(t, err)
+		_, err = Restore(data, "wrong")
+		assert.ErrorIs(t, err, ErrBackupPassphrase)
+
+		var envelope map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &envelope))
+		envelope["ciphertext"] = strings.Repeat("A", len(envelope["ciphertext"].(string)))
+		tampered, _ := json.Marshal(envelope)
+		_, err = Restore(tampered, "correct horse")
+		assert.ErrorIs(t, err, ErrBackupPassphrase)
+
+		_, err = Restore([]byte(`{"version":3}`), "correct horse")
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+	})
+
+	t.Run("checks key derivation parameters before deriving", func(t *testing.T) {
+		data, err := Backup(wallet, "correct horse", options)
+		require.NoError(t, err)
+		var envelope map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &envelope))
+		params := envelope["kdfparams"].(map[string]interface{})
+
+		params["p"] = 1 << 20
+		costly, _ := json.Marshal(envelope)
+		_, err = Restore(costly, "correct horse")
+		assert.ErrorIs(t, err, ErrBackupUnsupported)
+
+		params["p"], params["dklen"] = LightScryptParams.P, 64
+		long, _ := json.Marshal(envelope)
+		_, err = Restore(long, "correct horse")
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+
+		_, err = Backup(wallet, "correct horse", BackupOptions{Scrypt: ScryptParams{N: 1 << 18, R: 8, P: 64}})
+		assert.ErrorIs(t, err, ErrBackupUnsupported)
+	})
+
+	t.Run("checks secrets against accounts", func(t *testing.T) {
+		mismatched := wallet
+		mismatched.Accounts = []BackupAccount{{Name: "main", Address: friend.Address, HDPath: &HDPath{Account: 0, Index: 1}}}
+		_, err := Backup(mismatched, "correct horse", BackupOptions{IncludeSecrets: true, Scrypt: LightScryptParams})
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+
+		_, err = Backup(wallet, "", options)
+		assert.ErrorIs(t, err, ErrBackupPassphraseRequired)
+	})
+
+	t.Run("derives accounts with the mnemonic passphrase", func(t *testing.T) {
+		protected, err := DeriveKeyPairWithPassphrase(phrase, "25th word", 0, 0)
+		require.NoError(t, err)
+		withPassphrase := WalletBackup{
+			Accounts: []BackupAccount{{Name: "main", Address: protected.Address, HDPath: &HDPath{}}},
+			Secrets:  &BackupSecrets{Mnemonic: phrase, MnemonicPassphrase: "25th word"},
+		}
+		withSecrets := BackupOptions{IncludeSecrets: true, Scrypt: LightScryptParams}
+		data, err := Backup(withPassphrase, "correct horse", withSecrets)
+		require.NoError(t, err)
+		restored, err := Restore(data, "correct horse")
+		require.NoError(t, err)
+		assert.Equal(t, "25th word", restored.Secrets.MnemonicPassphrase)
+
+		withPassphrase.Secrets = &BackupSecrets{Mnemonic: phrase}
+		_, err = Backup(withPassphrase, "correct horse", withSecrets)
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+	})
+
+	t.Run("checkpoints round trip through a store", func(t *testing.T) {
+		store := NewMemoryCheckpointStore()
+		require.NoError(t, RestoreCheckpoints(store, wallet.Checkpoints))
+		checkpoints, err := BackupCheckpoints(store, "deposit-scanner", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, wallet.Checkpoints, checkpoints)
+	})
+}
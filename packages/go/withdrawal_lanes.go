@@ -0,0 +1,139 @@
+package constellation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidWithdrawalPriority indicates a request with an unknown priority
+var ErrInvalidWithdrawalPriority = errors.New("invalid withdrawal priority")
+
+// WithdrawalPriority is the lane a withdrawal request waits in
+type WithdrawalPriority int
+
+const (
+	// WithdrawalNormal is the default lane, for ordinary customer withdrawals
+	WithdrawalNormal WithdrawalPriority = iota
+	// WithdrawalUrgent is for time-sensitive withdrawals
+	WithdrawalUrgent
+	// WithdrawalBulk is for large payout runs that can wait
+	WithdrawalBulk
+)
+
+// withdrawalPriorities lists the lanes from most to least urgent
+var withdrawalPriorities = [...]WithdrawalPriority{WithdrawalUrgent, WithdrawalNormal, WithdrawalBulk}
+
+// defaultLaneWeights are the requests each lane may take per scheduling
+// round while the others are waiting
+var defaultLaneWeights = map[WithdrawalPriority]int{
+	WithdrawalUrgent: 8,
+	WithdrawalNormal: 4,
+	WithdrawalBulk:   1,
+}
+
+func (p WithdrawalPriority) String() string {
+	switch p {
+	case WithdrawalNormal:
+		return "normal"
+	case WithdrawalUrgent:
+		return "urgent"
+	case WithdrawalBulk:
+		return "bulk"
+	}
+	return fmt.Sprintf("WithdrawalPriority(%d)", int(p))
+}
+
+func (p WithdrawalPriority) valid() bool {
+	return p == WithdrawalNormal || p == WithdrawalUrgent || p == WithdrawalBulk
+}
+
+// withdrawalLanes schedules waiting requests by weighted round robin across
+// priority lanes, and round robin across origins within a lane, so neither
+// a bulk payout nor one busy origin can starve the rest. Not safe for
+// concurrent use; the queue guards it with its mutex.
+type withdrawalLanes struct {
+	lanes   map[WithdrawalPriority]*withdrawalLane
+	weights map[WithdrawalPriority]int
+	credits map[WithdrawalPriority]int
+	size    int
+}
+
+// withdrawalLane holds one priority's requests, per origin in arrival order
+type withdrawalLane struct {
+	origins []string
+	pending map[string][]WithdrawalRequest
+}
+
+func newWithdrawalLanes(weights map[WithdrawalPriority]int) *withdrawalLanes {
+	l := &withdrawalLanes{
+		lanes:   map[WithdrawalPriority]*withdrawalLane{},
+		weights: map[WithdrawalPriority]int{},
+		credits: map[WithdrawalPriority]int{},
+	}
+	for _, priority := range withdrawalPriorities {
+		l.lanes[priority] = &withdrawalLane{pending: map[string][]WithdrawalRequest{}}
+		l.weights[priority] = defaultLaneWeights[priority]
+		if weights[priority] > 0 {
+			l.weights[priority] = weights[priority]
+		}
+	}
+	l.refill()
+	return l
+}
+
+// Len returns the number of waiting requests
+func (l *withdrawalLanes) Len() int {
+	return l.size
+}
+
+// Push adds a request behind the others from its origin in its lane
+func (l *withdrawalLanes) Push(request WithdrawalRequest) {
+	lane := l.lanes[request.Priority]
+	if len(lane.pending[request.Origin]) == 0 {
+		lane.origins = append(lane.origins, request.Origin)
+	}
+	lane.pending[request.Origin] = append(lane.pending[request.Origin], request)
+	l.size++
+}
+
+// Pop removes the next request to process
+func (l *withdrawalLanes) Pop() (WithdrawalRequest, bool) {
+	if l.size == 0 {
+		return WithdrawalRequest{}, false
+	}
+	for {
+		for _, priority := range withdrawalPriorities {
+			lane := l.lanes[priority]
+			if len(lane.origins) > 0 && l.credits[priority] > 0 {
+				l.credits[priority]--
+				l.size--
+				return lane.pop(), true
+			}
+		}
+		// every lane with work has used its share of this round
+		l.refill()
+	}
+}
+
+func (l *withdrawalLanes) refill() {
+	for priority, weight := range l.weights {
+		l.credits[priority] = weight
+	}
+}
+
+// pop takes the oldest request of the lane's next origin and moves that
+// origin to the back of the rotation
+func (lane *withdrawalLane) pop() WithdrawalRequest {
+	origin := lane.origins[0]
+	lane.origins = lane.origins[1:]
+
+	requests := lane.pending[origin]
+	request := requests[0]
+	if len(requests) > 1 {
+		lane.pending[origin] = requests[1:]
+		lane.origins = append(lane.origins, origin)
+	} else {
+		delete(lane.pending, origin)
+	}
+	return request
+}
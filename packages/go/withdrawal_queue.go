@@ -0,0 +1,524 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultWithdrawalQueueSize = 1024
+
+var (
+	// ErrWithdrawalQueueClosed indicates a request was enqueued after Close
+	ErrWithdrawalQueueClosed = errors.New("withdrawal queue is closed")
+	// ErrWithdrawalQueueFull indicates the queue is at capacity
+	ErrWithdrawalQueueFull = errors.New("withdrawal queue is full")
+	// ErrWithdrawalAmountExceeded indicates a request above the configured maximum
+	ErrWithdrawalAmountExceeded = errors.New("withdrawal amount exceeds limit")
+	// ErrWithdrawalDeadlineExceeded indicates a request that missed its deadline
+	ErrWithdrawalDeadlineExceeded = errors.New("withdrawal deadline exceeded")
+)
+
+// WithdrawalRequest is a single outgoing payment
+type WithdrawalRequest struct {
+	// ID is the caller's reference, echoed in the receipt
+	ID string
+	// Destination is the recipient DAG address
+	Destination string
+	// Amount in smallest units (1e-8)
+	Amount int64
+	// Fee in smallest units (1e-8)
+	Fee int64
+	// Deadline, if set, is when the request is cancelled if it has not been
+	// submitted. Its ordinal goes to the next request instead. A submission
+	// still in flight at the deadline is abandoned and reported as
+	// WithdrawalUnknown.
+	Deadline time.Time
+	// Priority selects the lane the request waits in (default:
+	// WithdrawalNormal)
+	Priority WithdrawalPriority
+	// Origin identifies who submitted the request, such as a customer or a
+	// payout job. Origins in the same lane take turns.
+	Origin string
+}
+
+// WithdrawalStatus is the outcome recorded in a receipt
+type WithdrawalStatus string
+
+const (
+	// WithdrawalSubmitted means the transaction was accepted by the L1 node
+	WithdrawalSubmitted WithdrawalStatus = "Submitted"
+	// WithdrawalRejected means a policy refused the request; nothing was signed
+	WithdrawalRejected WithdrawalStatus = "Rejected"
+	// WithdrawalFailed means signing failed, or the node definitively
+	// rejected the transaction
+	WithdrawalFailed WithdrawalStatus = "Failed"
+	// WithdrawalCancelled means the request's deadline passed before its
+	// transaction was submitted; nothing reached the L1 node
+	WithdrawalCancelled WithdrawalStatus = "Cancelled"
+	// WithdrawalUnknown means the transaction was submitted but its outcome
+	// is unknown: the deadline passed while the node was still answering, or
+	// the submission timed out, lost its connection or got a 5xx. It may
+	// yet land: reconcile it by Hash before retrying, or the withdrawal may
+	// be paid twice.
+	WithdrawalUnknown WithdrawalStatus = "Unknown"
+)
+
+// WithdrawalReceipt records what happened to one request
+type WithdrawalReceipt struct {
+	Request WithdrawalRequest
+	Status  WithdrawalStatus
+	// Hash is the transaction hash when a transaction was built
+	Hash string
+	// Parent is the reference the transaction chained from
+	Parent TransactionReference
+	// Err is the rejection or failure reason
+	Err error
+	// Warnings flag a suspicious but accepted fee (see CheckFee)
+	Warnings []FeeWarning
+	// Node is the URL of the L1 node that accepted the transaction, when
+	// the L1 client reports it (CurrencyL1Client and
+	// PooledCurrencyL1Client do)
+	Node string
+	// StartedAt is when the queue took the request up
+	StartedAt time.Time
+	// ProcessedAt is when the queue finished with the request
+	ProcessedAt time.Time
+}
+
+// WithdrawalPolicy vets a request before it is signed; a non-nil error rejects it
+type WithdrawalPolicy func(request WithdrawalRequest) error
+
+// FeeSanityPolicy rejects requests whose fee exceeds their amount, turning
+// the FeeWarningExceedsAmount receipt warning into a rejection
+func FeeSanityPolicy() WithdrawalPolicy {
+	return func(request WithdrawalRequest) error {
+		if request.Fee > request.Amount {
+			return ErrFeeExceedsAmount
+		}
+		return nil
+	}
+}
+
+// MaxAmountPolicy rejects requests above maxUnits
+func MaxAmountPolicy(maxUnits int64) WithdrawalPolicy {
+	return func(request WithdrawalRequest) error {
+		if request.Amount > maxUnits {
+			return ErrWithdrawalAmountExceeded
+		}
+		return nil
+	}
+}
+
+// WithdrawalQueueConfig holds configuration for a WithdrawalQueue
+type WithdrawalQueueConfig struct {
+	// L1 receives the transactions. Use a SimulatedLedger to run the full
+	// pipeline without a network.
+	L1 CurrencyL1API
+	// PrivateKey is the hot wallet key that signs every withdrawal
+	PrivateKey string
+	// Signer signs instead of PrivateKey when set, keeping the hot wallet
+	// key in an HSM, KMS or remote signer
+	Signer Signer
+	// Policies run in order before signing
+	Policies []WithdrawalPolicy
+	// Replay, if set, is claimed for each request ID before signing, so a
+	// request re-enqueued after a restart or by another replica is rejected
+	// with ErrReplayDetected instead of paid twice. The claim is released
+	// only when nothing reached the node or the node definitively rejected
+	// the transaction (HTTP 4xx); a WithdrawalUnknown submission keeps it.
+	// Requests without an ID are not checked.
+	Replay ReplayRegistry
+	// Leader, if set, makes the queue a singleton across replicas: requests
+	// wait while another replica leads, and the last reference is re-read
+	// at the start of each term. Requests still waiting when the queue
+	// closes without leading are cancelled with ErrNotLeader.
+	Leader *LeaderElection
+	// OnReceipt is called once per request, in processing order. When ctx
+	// is cancelled, every request still waiting gets a Cancelled receipt
+	// with the context's error.
+	OnReceipt func(receipt WithdrawalReceipt)
+	// QueueSize bounds the number of waiting requests (default: 1024)
+	QueueSize int
+	// LaneWeights sets how many requests each priority lane may take per
+	// scheduling round while other lanes are waiting (default: urgent 8,
+	// normal 4, bulk 1). A lane with no competition runs at full speed.
+	LaneWeights map[WithdrawalPriority]int
+}
+
+// WithdrawalQueue signs and submits withdrawals from one hot wallet
+// sequentially, chaining each transaction from the previous one so a backlog
+// never forks the address's chain. Waiting requests are scheduled by
+// priority lane, by weighted round robin so a bulk payout cannot starve
+// urgent withdrawals or the reverse, and by origin within a lane. Requests
+// with the same priority and origin are processed in order. It implements
+// the Service lifecycle.
+//
+// Example (simulation mode):
+//
+//	ledger := NewSimulatedLedger()
+//	ledger.Fund(hotWallet.Address, 1000)
+//
+//	queue, err := NewWithdrawalQueue(ctx, WithdrawalQueueConfig{
+//	    L1:         ledger,
+//	    PrivateKey: hotWallet.PrivateKey,
+//	    Policies:   []WithdrawalPolicy{MaxAmountPolicy(TokenToUnits(100))},
+//	    OnReceipt:  func(r WithdrawalReceipt) { log.Println(r.Request.ID, r.Status, r.Hash) },
+//	})
+//	queue.Enqueue(WithdrawalRequest{ID: "w-1", Destination: "DAG...", Amount: TokenToUnits(5)})
+//	queue.Close()
+type WithdrawalQueue struct {
+	serviceState
+
+	ctx    context.Context
+	config WithdrawalQueueConfig
+	source string
+
+	signer Signer
+
+	mu      sync.Mutex
+	closed  bool
+	lanes   *withdrawalLanes
+	wake    chan struct{}
+	lastRef *TransactionReference
+	// term is the Leader term lastRef was read in
+	term uint64
+}
+
+// NewWithdrawalQueue creates a queue and starts its worker
+func NewWithdrawalQueue(ctx context.Context, config WithdrawalQueueConfig) (*WithdrawalQueue, error) {
+	if config.L1 == nil {
+		return nil, ErrL1URLRequired
+	}
+	signer := config.Signer
+	if signer == nil {
+		if !IsValidPrivateKey(config.PrivateKey) {
+			return nil, ErrInvalidPrivateKey
+		}
+		keySigner, err := NewPrivateKeySigner(config.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		signer = keySigner
+	}
+	if !IsValidPublicKey(signer.PublicKey()) {
+		return nil, ErrInvalidPublicKey
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultWithdrawalQueueSize
+	}
+
+	q := &WithdrawalQueue{
+		ctx:    ctx,
+		config: config,
+		source: GetAddress(signer.PublicKey()),
+		signer: signer,
+		lanes:  newWithdrawalLanes(config.LaneWeights),
+		wake:   make(chan struct{}, 1),
+	}
+	q.start()
+
+	go q.run()
+	return q, nil
+}
+
+// Source returns the hot wallet address withdrawals are sent from
+func (q *WithdrawalQueue) Source() string {
+	return q.source
+}
+
+// Enqueue adds a request to the back of its lane. Returns
+// ErrInvalidWithdrawalPriority for an unknown priority.
+func (q *WithdrawalQueue) Enqueue(request WithdrawalRequest) error {
+	if !request.Priority.valid() {
+		return ErrInvalidWithdrawalPriority
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrWithdrawalQueueClosed
+	}
+	if q.lanes.Len() >= q.config.QueueSize {
+		return ErrWithdrawalQueueFull
+	}
+	q.lanes.Push(request)
+	q.signal()
+	return nil
+}
+
+// Pending returns the number of requests waiting to be processed
+func (q *WithdrawalQueue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.lanes.Len()
+}
+
+// Close stops accepting requests and blocks until queued ones are processed
+func (q *WithdrawalQueue) Close() error {
+	q.stopIntake()
+	<-q.Done()
+	return q.Err()
+}
+
+func (q *WithdrawalQueue) stopIntake() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.signal()
+}
+
+// signal wakes the worker; callers hold q.mu
+func (q *WithdrawalQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next pops the next request to process. done is true once the queue is
+// closed and drained.
+func (q *WithdrawalQueue) next() (request WithdrawalRequest, ok bool, done bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	request, ok = q.lanes.Pop()
+	return request, ok, !ok && q.closed
+}
+
+func (q *WithdrawalQueue) run() {
+	for {
+		if q.ctx.Err() != nil {
+			q.stopIntake()
+			q.cancelWaiting(q.ctx.Err())
+			q.finish(q.ctx.Err())
+			return
+		}
+
+		if leaderChanged, leading := q.leading(); !leading {
+			if q.isClosed() {
+				q.cancelWaiting(ErrNotLeader)
+				q.finish(nil)
+				return
+			}
+			select {
+			case <-q.ctx.Done():
+			case <-q.wake:
+			case <-leaderChanged:
+			}
+			continue
+		}
+
+		request, ok, done := q.next()
+		if ok {
+			q.emit(q.process(request))
+			continue
+		}
+		if done {
+			q.finish(nil)
+			return
+		}
+
+		select {
+		case <-q.ctx.Done():
+		case <-q.wake:
+		}
+	}
+}
+
+// leading reports whether the queue may submit, forgetting the last
+// reference when a new Leader term began. When it may not, the returned
+// channel is closed once leadership changes.
+func (q *WithdrawalQueue) leading() (<-chan struct{}, bool) {
+	if q.config.Leader == nil {
+		return nil, true
+	}
+	changed := q.config.Leader.leaderChanged()
+	term, leading := q.config.Leader.Term()
+	if !leading {
+		return changed, false
+	}
+	if term != q.term {
+		// another replica may have extended the chain meanwhile
+		q.term = term
+		q.lastRef = nil
+	}
+	return nil, true
+}
+
+func (q *WithdrawalQueue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// cancelWaiting emits a cancelled receipt for every waiting request
+func (q *WithdrawalQueue) cancelWaiting(err error) {
+	for {
+		request, ok, _ := q.next()
+		if !ok {
+			return
+		}
+		q.emit(WithdrawalReceipt{
+			Request:   request,
+			Status:    WithdrawalCancelled,
+			Err:       err,
+			StartedAt: time.Now(),
+		})
+	}
+}
+
+func (q *WithdrawalQueue) process(request WithdrawalRequest) WithdrawalReceipt {
+	receipt := WithdrawalReceipt{Request: request, StartedAt: time.Now()}
+
+	if q.expired(request) {
+		receipt.Status = WithdrawalCancelled
+		receipt.Err = ErrWithdrawalDeadlineExceeded
+		return receipt
+	}
+
+	warnings, err := CheckFee(request.Amount, request.Fee)
+	if err != nil {
+		receipt.Status = WithdrawalRejected
+		receipt.Err = err
+		return receipt
+	}
+	receipt.Warnings = warnings
+
+	for _, policy := range q.config.Policies {
+		if err := policy(request); err != nil {
+			receipt.Status = WithdrawalRejected
+			receipt.Err = err
+			return receipt
+		}
+	}
+
+	if q.lastRef == nil {
+		ref, err := q.config.L1.GetLastReference(q.source)
+		if err != nil {
+			receipt.Status = WithdrawalFailed
+			receipt.Err = err
+			return receipt
+		}
+		q.lastRef = ref
+	}
+	receipt.Parent = *q.lastRef
+
+	claimed, err := q.claim(request)
+	if err != nil {
+		receipt.Status = WithdrawalFailed
+		receipt.Err = err
+		return receipt
+	}
+	if !claimed {
+		receipt.Status = WithdrawalRejected
+		receipt.Err = fmt.Errorf("%w: withdrawal %s", ErrReplayDetected, request.ID)
+		return receipt
+	}
+
+	tx, err := createCurrencyTransactionWithSigner(
+		request.Destination, request.Amount, request.Fee, "", q.signer, *q.lastRef)
+	if err != nil {
+		q.release(request)
+		receipt.Status = WithdrawalFailed
+		receipt.Err = err
+		return receipt
+	}
+	receipt.Hash = HashCurrencyTransaction(tx).Value
+
+	response, err := q.post(request, tx)
+	if err != nil {
+		// The node's view of the chain may differ from ours; re-read it next time
+		q.lastRef = nil
+		receipt.Status = WithdrawalFailed
+		if nodeRejected(err) {
+			q.release(request)
+		} else {
+			// an abandoned or unanswered submission may still land, so its
+			// claim is kept
+			receipt.Status = WithdrawalUnknown
+		}
+		receipt.Err = err
+		return receipt
+	}
+
+	q.lastRef = &TransactionReference{Hash: receipt.Hash, Ordinal: q.lastRef.Ordinal + 1}
+	receipt.Status = WithdrawalSubmitted
+	if response != nil {
+		receipt.Node = response.Node
+	}
+	return receipt
+}
+
+// claim claims request's ID in the replay registry, if there is one
+func (q *WithdrawalQueue) claim(request WithdrawalRequest) (bool, error) {
+	if q.config.Replay == nil || request.ID == "" {
+		return true, nil
+	}
+	return q.config.Replay.Claim(WithdrawalReplayKey(request.ID))
+}
+
+// nodeRejected reports whether a submission error is a definitive
+// rejection, so the transaction cannot land: an HTTP 4xx from the node, or a
+// SimulatedLedger refusal. Timeouts, connection failures and 5xx responses
+// leave the outcome unknown.
+func nodeRejected(err error) bool {
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		return netErr.StatusCode >= 400 && netErr.StatusCode < 500
+	}
+	return errors.Is(err, ErrParentMismatch) ||
+		errors.Is(err, ErrInsufficientBalance) ||
+		errors.Is(err, ErrInvalidSignature)
+}
+
+// release gives up request's claim after it failed before reaching the
+// node, or the node definitively rejected it
+func (q *WithdrawalQueue) release(request WithdrawalRequest) {
+	if q.config.Replay == nil || request.ID == "" {
+		return
+	}
+	// a claim that cannot be released only blocks a retry of this ID
+	_ = q.config.Replay.Release(WithdrawalReplayKey(request.ID))
+}
+
+func (q *WithdrawalQueue) expired(request WithdrawalRequest) bool {
+	return !request.Deadline.IsZero() && !time.Now().Before(request.Deadline)
+}
+
+// post submits tx, giving up at the request's deadline. A submission
+// abandoned this way may still reach the node; re-reading the last reference
+// before the next request re-plans the chain around whichever outcome won.
+func (q *WithdrawalQueue) post(request WithdrawalRequest, tx *CurrencyTransaction) (*PostTransactionResponse, error) {
+	if request.Deadline.IsZero() {
+		return q.config.L1.PostTransaction(tx)
+	}
+
+	type postResult struct {
+		response *PostTransactionResponse
+		err      error
+	}
+	result := make(chan postResult, 1)
+	go func() {
+		response, err := q.config.L1.PostTransaction(tx)
+		result <- postResult{response, err}
+	}()
+
+	timer := time.NewTimer(time.Until(request.Deadline))
+	defer timer.Stop()
+	select {
+	case posted := <-result:
+		return posted.response, posted.err
+	case <-timer.C:
+		return nil, ErrWithdrawalDeadlineExceeded
+	}
+}
+
+func (q *WithdrawalQueue) emit(receipt WithdrawalReceipt) {
+	receipt.ProcessedAt = time.Now()
+	if q.config.OnReceipt != nil {
+		q.config.OnReceipt(receipt)
+	}
+}
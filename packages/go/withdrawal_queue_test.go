@@ -0,0 +1,283 @@
+package constellation
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawalQueueSimulation(t *testing.T) {
+	hot, err := GenerateKeyPair()
+	require.NoError(t, err)
+	alice, _ := GenerateKeyPair()
+	bob, _ := GenerateKeyPair()
+
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+
+	var receipts []WithdrawalReceipt
+	queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+		L1:         ledger,
+		PrivateKey: hot.PrivateKey,
+		Policies:   []WithdrawalPolicy{MaxAmountPolicy(TokenToUnits(50))},
+		OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hot.Address, queue.Source())
+
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "1", Destination: alice.Address, Amount: TokenToUnits(10)}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "2", Destination: bob.Address, Amount: TokenToUnits(60)}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "3", Destination: bob.Address, Amount: TokenToUnits(20)}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "4", Destination: bob.Address, Amount: TokenToUnits(45)}))
+	require.NoError(t, queue.Close())
+
+	assert.ErrorIs(t, queue.Enqueue(WithdrawalRequest{ID: "5"}), ErrWithdrawalQueueClosed)
+
+	require.Len(t, receipts, 4)
+	assert.Equal(t, WithdrawalSubmitted, receipts[0].Status)
+	assert.Equal(t, GenesisReference, receipts[0].Parent)
+
+	assert.Equal(t, WithdrawalRejected, receipts[1].Status)
+	assert.ErrorIs(t, receipts[1].Err, ErrWithdrawalAmountExceeded)
+
+	assert.Equal(t, WithdrawalSubmitted, receipts[2].Status)
+	assert.Equal(t, TransactionReference{Hash: receipts[0].Hash, Ordinal: 1}, receipts[2].Parent)
+
+	// 10 + 20 pending leaves 70, so 45 still fits; the ledger tracks pending debits
+	assert.Equal(t, WithdrawalSubmitted, receipts[3].Status)
+
+	ledger.Snapshot()
+	balance, _ := ledger.GetBalance(bob.Address)
+	assert.Equal(t, TokenToUnits(65), balance.Balance)
+	balance, _ = ledger.GetBalance(hot.Address)
+	assert.Equal(t, TokenToUnits(25), balance.Balance)
+}
+
+func TestWithdrawalQueueRecoversFromRejectedSubmission(t *testing.T) {
+	hot, _ := GenerateKeyPair()
+	alice, _ := GenerateKeyPair()
+
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 10))
+
+	var receipts []WithdrawalReceipt
+	queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+		L1:         ledger,
+		PrivateKey: hot.PrivateKey,
+		OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "1", Destination: alice.Address, Amount: TokenToUnits(50)}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "2", Destination: alice.Address, Amount: TokenToUnits(5)}))
+	require.NoError(t, queue.Close())
+
+	require.Len(t, receipts, 2)
+	assert.Equal(t, WithdrawalFailed, receipts[0].Status)
+	assert.ErrorIs(t, receipts[0].Err, ErrInsufficientBalance)
+	assert.Equal(t, WithdrawalSubmitted, receipts[1].Status)
+	assert.Equal(t, GenesisReference, receipts[1].Parent)
+}
+
+// slowL1 blocks the first PostTransaction until release is closed; the
+// delayed transaction never reaches the ledger
+type slowL1 struct {
+	*SimulatedLedger
+	release chan struct{}
+	calls   int32
+}
+
+func (s *slowL1) PostTransaction(tx *CurrencyTransaction) (*PostTransactionResponse, error) {
+	if atomic.AddInt32(&s.calls, 1) == 1 {
+		<-s.release
+		return nil, errors.New("connection reset")
+	}
+	return s.SimulatedLedger.PostTransaction(tx)
+}
+
+func TestWithdrawalQueueDeadlines(t *testing.T) {
+	hot, _ := GenerateKeyPair()
+	alice, _ := GenerateKeyPair()
+
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+	l1 := &slowL1{SimulatedLedger: ledger, release: make(chan struct{})}
+	defer close(l1.release)
+
+	var receipts []WithdrawalReceipt
+	queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+		L1:         l1,
+		PrivateKey: hot.PrivateKey,
+		OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "hung", Destination: alice.Address, Amount: TokenToUnits(1), Deadline: time.Now().Add(20 * time.Millisecond)}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "expired", Destination: alice.Address, Amount: TokenToUnits(2), Deadline: time.Now().Add(-time.Second)}))
+	require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "next", Destination: alice.Address, Amount: TokenToUnits(3)}))
+	require.NoError(t, queue.Close())
+
+	require.Len(t, receipts, 3)
+	assert.Equal(t, WithdrawalUnknown, receipts[0].Status, "a submission in flight at the deadline may still land")
+	assert.ErrorIs(t, receipts[0].Err, ErrWithdrawalDeadlineExceeded)
+	assert.NotEmpty(t, receipts[0].Hash, "the hash is kept for reconciliation")
+
+	assert.Equal(t, WithdrawalCancelled, receipts[1].Status)
+	assert.Empty(t, receipts[1].Hash, "an expired request is never signed")
+
+	// the cancelled ordinals are re-planned: the next transfer chains from genesis
+	assert.Equal(t, WithdrawalSubmitted, receipts[2].Status)
+	assert.Equal(t, GenesisReference, receipts[2].Parent)
+}
+
+func TestWithdrawalQueueCancelsWaitingRequests(t *testing.T) {
+	hot, _ := GenerateKeyPair()
+	alice, _ := GenerateKeyPair()
+
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+	l1 := &slowL1{SimulatedLedger: ledger, release: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var receipts []WithdrawalReceipt
+	queue, err := NewWithdrawalQueue(ctx, WithdrawalQueueConfig{
+		L1:         l1,
+		PrivateKey: hot.PrivateKey,
+		OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+	})
+	require.NoError(t, err)
+
+	// the first request holds the worker while the rest wait
+	for _, id := range []string{"in-flight", "waiting-1", "waiting-2"} {
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: id, Destination: alice.Address, Amount: TokenToUnits(1)}))
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&l1.calls) == 1 }, time.Second, time.Millisecond)
+	cancel()
+	close(l1.release)
+
+	assert.ErrorIs(t, queue.Close(), context.Canceled)
+	require.Len(t, receipts, 3)
+	assert.Equal(t, "in-flight", receipts[0].Request.ID)
+	for _, receipt := range receipts[1:] {
+		assert.Equal(t, WithdrawalCancelled, receipt.Status, receipt.Request.ID)
+		assert.ErrorIs(t, receipt.Err, context.Canceled)
+	}
+	assert.Zero(t, queue.Pending())
+}
+
+func TestWithdrawalQueueFeeChecks(t *testing.T) {
+	hot, _ := GenerateKeyPair()
+	alice, _ := GenerateKeyPair()
+
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+
+	run := func(policies []WithdrawalPolicy) WithdrawalReceipt {
+		var receipts []WithdrawalReceipt
+		queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+			L1:         ledger,
+			PrivateKey: hot.PrivateKey,
+			Policies:   policies,
+			OnReceipt:  func(r WithdrawalReceipt) { receipts = append(receipts, r) },
+		})
+		require.NoError(t, err)
+		require.NoError(t, queue.Enqueue(WithdrawalRequest{ID: "w", Destination: alice.Address, Amount: 10, Fee: 20}))
+		require.NoError(t, queue.Close())
+		require.Len(t, receipts, 1)
+		return receipts[0]
+	}
+
+	receipt := run(nil)
+	assert.Equal(t, WithdrawalSubmitted, receipt.Status)
+	assert.Equal(t, []FeeWarning{FeeWarningExceedsAmount}, receipt.Warnings)
+
+	receipt = run([]WithdrawalPolicy{FeeSanityPolicy()})
+	assert.Equal(t, WithdrawalRejected, receipt.Status)
+	assert.ErrorIs(t, receipt.Err, ErrFeeExceedsAmount)
+}
+
+func TestWithdrawalLanesScheduling(t *testing.T) {
+	ids := func(lanes *withdrawalLanes) []string {
+		var order []string
+		for {
+			request, ok := lanes.Pop()
+			if !ok {
+				return order
+			}
+			order = append(order, request.ID)
+		}
+	}
+
+	t.Run("weighted round robin across lanes", func(t *testing.T) {
+		lanes := newWithdrawalLanes(map[WithdrawalPriority]int{WithdrawalUrgent: 2})
+		for _, id := range []string{"b1", "b2", "b3"} {
+			lanes.Push(WithdrawalRequest{ID: id, Priority: WithdrawalBulk})
+		}
+		for _, id := range []string{"u1", "u2", "u3", "u4", "u5"} {
+			lanes.Push(WithdrawalRequest{ID: id, Priority: WithdrawalUrgent})
+		}
+		lanes.Push(WithdrawalRequest{ID: "n1"})
+		assert.Equal(t, 9, lanes.Len())
+
+		// urgent takes 2 per round, normal and bulk 4 and 1
+		assert.Equal(t, []string{"u1", "u2", "n1", "b1", "u3", "u4", "b2", "u5", "b3"}, ids(lanes))
+		assert.Equal(t, 0, lanes.Len())
+	})
+
+	t.Run("origins take turns within a lane", func(t *testing.T) {
+		lanes := newWithdrawalLanes(nil)
+		for _, id := range []string{"job-1", "job-2", "job-3"} {
+			lanes.Push(WithdrawalRequest{ID: id, Origin: "payout-job"})
+		}
+		lanes.Push(WithdrawalRequest{ID: "alice-1", Origin: "alice"})
+		lanes.Push(WithdrawalRequest{ID: "bob-1", Origin: "bob"})
+		lanes.Push(WithdrawalRequest{ID: "alice-2", Origin: "alice"})
+
+		assert.Equal(t, []string{"job-1", "alice-1", "bob-1", "job-2", "alice-2", "job-3"}, ids(lanes))
+	})
+}
+
+func TestWithdrawalQueuePriorityLanes(t *testing.T) {
+	hot, _ := GenerateKeyPair()
+	alice, _ := GenerateKeyPair()
+
+	ledger := NewSimulatedLedger()
+	require.NoError(t, ledger.Fund(hot.Address, 100))
+
+	// hold the worker on the first receipt until everything is queued
+	release := make(chan struct{})
+	var order []string
+	queue, err := NewWithdrawalQueue(context.Background(), WithdrawalQueueConfig{
+		L1:         ledger,
+		PrivateKey: hot.PrivateKey,
+		OnReceipt: func(r WithdrawalReceipt) {
+			if len(order) == 0 {
+				<-release
+			}
+			order = append(order, r.Request.ID)
+		},
+	})
+	require.NoError(t, err)
+
+	request := func(id string, priority WithdrawalPriority) WithdrawalRequest {
+		return WithdrawalRequest{ID: id, Destination: alice.Address, Amount: TokenToUnits(1), Priority: priority, Origin: "ops"}
+	}
+	require.NoError(t, queue.Enqueue(request("first", WithdrawalBulk)))
+	require.Eventually(t, func() bool { return queue.Pending() == 0 }, time.Second, time.Millisecond)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, queue.Enqueue(request("bulk", WithdrawalBulk)))
+	}
+	require.NoError(t, queue.Enqueue(request("urgent", WithdrawalUrgent)))
+	assert.ErrorIs(t, queue.Enqueue(request("bad", WithdrawalPriority(7))), ErrInvalidWithdrawalPriority)
+	assert.Equal(t, 4, queue.Pending())
+
+	close(release)
+	require.NoError(t, queue.Close())
+	assert.Equal(t, []string{"first", "urgent", "bulk", "bulk", "bulk"}, order)
+	assert.Equal(t, "urgent", WithdrawalUrgent.String())
+}
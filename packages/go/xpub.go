@@ -0,0 +1,330 @@
+package constellation
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// ErrInvalidXpub indicates a string that is not a BIP32 extended public key
+// of a DAG account
+var ErrInvalidXpub = errors.New("invalid extended public key")
+
+const (
+	// xpubVersion is the BIP32 mainnet public version, "xpub" in Base58
+	xpubVersion = 0x0488B21E
+	// xprvVersion is the BIP32 mainnet private version, "xprv" in Base58
+	xprvVersion = 0x0488ADE4
+	// xpubLength is version, depth, parent fingerprint, child number, chain
+	// code and compressed key, before the checksum
+	xpubLength = 4 + 1 + 4 + 4 + 32 + 33
+	// accountDepth is the depth of m/44'/1137'/account'
+	accountDepth = 3
+)
+
+// ExportAccountXpub exports the extended public key (xpub) of an account,
+// m/44'/1137'/account'. It derives the account's public keys and addresses
+// but cannot sign: give it to deposit servers, which then derive addresses
+// with DeriveAddressesFromXpub without ever holding the mnemonic.
+//
+// An xpub is not secret in the way a private key is, but it links every
+// address of the account; anyone holding it can follow the account's
+// balances. Together with any one private key of the account it also
+// reveals the account's other private keys, so never store it next to one.
+//
+// Example:
+//
+//	// on the signing host
+//	xpub, err := constellation.ExportAccountXpub(phrase, 0)
+//
+//	// on the deposit server
+//	addresses, err := constellation.DeriveAddressesFromXpub(xpub, nextIndex, 100)
+func ExportAccountXpub(mnemonic string, account uint32) (string, error) {
+	return ExportAccountXpubWithPassphrase(mnemonic, "", account)
+}
+
+// ExportAccountXpubWithPassphrase is ExportAccountXpub for a mnemonic
+// protected by a BIP39 passphrase
+func ExportAccountXpubWithPassphrase(mnemonic string, passphrase string, account uint32) (string, error) {
+	if account >= hardenedKeyOffset {
+		return "", ErrInvalidDerivationPath
+	}
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return "", err
+	}
+	path := dagBIP44Path(account, 0)
+	key, err := deriveHDPublicKey(seed, path[:accountDepth]...)
+	if err != nil {
+		return "", err
+	}
+	return key.String(), nil
+}
+
+// DeriveAddressesFromXpub derives count consecutive receive addresses
+// starting at index start from an account xpub made by ExportAccountXpub.
+// They are the addresses of DeriveKeyPairs for the same account and
+// indexes, so funds sent to them are spendable from the mnemonic. Returns
+// ErrInvalidXpub for a malformed xpub, an extended private key, or an xpub
+// of another depth than an account's.
+func DeriveAddressesFromXpub(xpub string, start uint32, count int) ([]string, error) {
+	if start >= hardenedKeyOffset || count < 1 || uint64(start)+uint64(count) > hardenedKeyOffset {
+		return nil, ErrInvalidDerivationPath
+	}
+	account, err := parseHDPublicKey(xpub)
+	if err != nil {
+		return nil, err
+	}
+	if account.depth != accountDepth || account.childNumber < hardenedKeyOffset {
+		return nil, fmt.Errorf("%w: not an account key (depth %d)", ErrInvalidXpub, account.depth)
+	}
+	// the external chain, m/44'/1137'/account'/0
+	chain, err := account.child(0)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, count)
+	for i := range addresses {
+		key, err := chain.child(start + uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = key.address()
+	}
+	return addresses, nil
+}
+
+// hdPublicKey is a BIP32 extended public key
+type hdPublicKey struct {
+	depth byte
+	// parentFingerprint is the first 4 bytes of HASH160 of the parent key
+	parentFingerprint [4]byte
+	childNumber       uint32
+	chainCode         [32]byte
+	key               *btcec.PublicKey
+}
+
+// deriveHDPublicKey derives the extended public key at a non-empty path
+// from a BIP32 seed
+func deriveHDPublicKey(seed []byte, path ...uint32) (*hdPublicKey, error) {
+	parent, err := deriveHDKey(seed, path[:len(path)-1]...)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parent.child(path[len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(key.key[:])
+	defer Zeroize(parent.key[:])
+
+	_, parentPublic := btcec.PrivKeyFromBytes(parent.key[:])
+	_, public := btcec.PrivKeyFromBytes(key.key[:])
+	extended := &hdPublicKey{
+		depth:       byte(len(path)),
+		childNumber: path[len(path)-1],
+		chainCode:   key.chainCode,
+		key:         public,
+	}
+	copy(extended.parentFingerprint[:], hash160(parentPublic.SerializeCompressed()))
+	return extended, nil
+}
+
+// child derives the public child key at a non-hardened index
+func (k *hdPublicKey) child(index uint32) (*hdPublicKey, error) {
+	if index >= hardenedKeyOffset {
+		return nil, ErrInvalidDerivationPath
+	}
+	compressed := k.key.SerializeCompressed()
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	mac.Write(compressed)
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	mac.Write(indexBytes[:])
+	sum := mac.Sum(nil)
+
+	var tweak btcec.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return nil, errInvalidHDKey
+	}
+	// the child key is tweak*G + parent
+	var tweakPoint, parentPoint, point btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&tweak, &tweakPoint)
+	k.key.AsJacobian(&parentPoint)
+	btcec.AddNonConst(&tweakPoint, &parentPoint, &point)
+	if (point.X.IsZero() && point.Y.IsZero()) || point.Z.IsZero() {
+		return nil, errInvalidHDKey
+	}
+	point.ToAffine()
+
+	child := &hdPublicKey{
+		depth:       k.depth + 1,
+		childNumber: index,
+		key:         btcec.NewPublicKey(&point.X, &point.Y),
+	}
+	copy(child.parentFingerprint[:], hash160(compressed))
+	copy(child.chainCode[:], sum[32:])
+	return child, nil
+}
+
+// address returns the DAG address of the key
+func (k *hdPublicKey) address() string {
+	return GetAddress(hex.EncodeToString(k.key.SerializeCompressed()))
+}
+
+// String serializes the key as a Base58Check xpub
+func (k *hdPublicKey) String() string {
+	data := make([]byte, xpubLength, xpubLength+4)
+	binary.BigEndian.PutUint32(data, xpubVersion)
+	data[4] = k.depth
+	copy(data[5:9], k.parentFingerprint[:])
+	binary.BigEndian.PutUint32(data[9:13], k.childNumber)
+	copy(data[13:45], k.chainCode[:])
+	copy(data[45:], k.key.SerializeCompressed())
+	checksum := doubleSHA256(data)
+	return base58Encode(append(data, checksum[:4]...))
+}
+
+// parseHDPublicKey parses a Base58Check xpub
+func parseHDPublicKey(xpub string) (*hdPublicKey, error) {
+	data, ok := base58Decode(xpub)
+	if !ok || len(data) != xpubLength+4 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidXpub)
+	}
+	payload, checksum := data[:xpubLength], data[xpubLength:]
+	expected := doubleSHA256(payload)
+	if !bytes.Equal(checksum, expected[:4]) {
+		return nil, fmt.Errorf("%w: bad checksum", ErrInvalidXpub)
+	}
+	switch binary.BigEndian.Uint32(payload) {
+	case xpubVersion:
+	case xprvVersion:
+		// never echo it, it is a private key
+		return nil, fmt.Errorf("%w: got an extended private key", ErrInvalidXpub)
+	default:
+		return nil, fmt.Errorf("%w: unknown version", ErrInvalidXpub)
+	}
+
+	key := &hdPublicKey{
+		depth:       payload[4],
+		childNumber: binary.BigEndian.Uint32(payload[9:13]),
+	}
+	copy(key.parentFingerprint[:], payload[5:9])
+	copy(key.chainCode[:], payload[13:45])
+	if payload[45] != 0x02 && payload[45] != 0x03 {
+		return nil, fmt.Errorf("%w: key is not compressed", ErrInvalidXpub)
+	}
+	public, err := btcec.ParsePubKey(payload[45:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXpub, err)
+	}
+	key.key = public
+	return key, nil
+}
+
+// hash160 is RIPEMD-160 of SHA-256, which BIP32 fingerprints use
+func hash160(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	return ripemd160(digest[:])
+}
+
+// RIPEMD-160 message word order, rotations and constants for the left and
+// right lines, one row per round
+var (
+	ripemdLeftWords = [80]uint8{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		7, 4, 13, 1, 10, 6, 15, 3, 12, 0, 9, 5, 2, 14, 11, 8,
+		3, 10, 14, 4, 9, 15, 8, 1, 2, 7, 0, 6, 13, 11, 5, 12,
+		1, 9, 11, 10, 0, 8, 12, 4, 13, 3, 7, 15, 14, 5, 6, 2,
+		4, 0, 5, 9, 7, 12, 2, 10, 14, 1, 3, 8, 11, 6, 15, 13,
+	}
+	ripemdRightWords = [80]uint8{
+		5, 14, 7, 0, 9, 2, 11, 4, 13, 6, 15, 8, 1, 10, 3, 12,
+		6, 11, 3, 7, 0, 13, 5, 10, 14, 15, 8, 12, 4, 9, 1, 2,
+		15, 5, 1, 3, 7, 14, 6, 9, 11, 8, 12, 2, 10, 0, 4, 13,
+		8, 6, 4, 1, 3, 11, 15, 0, 5, 12, 2, 13, 9, 7, 10, 14,
+		12, 15, 10, 4, 1, 5, 8, 7, 6, 2, 13, 14, 0, 3, 9, 11,
+	}
+	ripemdLeftRotations = [80]uint8{
+		11, 14, 15, 12, 5, 8, 7, 9, 11, 13, 14, 15, 6, 7, 9, 8,
+		7, 6, 8, 13, 11, 9, 7, 15, 7, 12, 15, 9, 11, 7, 13, 12,
+		11, 13, 6, 7, 14, 9, 13, 15, 14, 8, 13, 6, 5, 12, 7, 5,
+		11, 12, 14, 15, 14, 15, 9, 8, 9, 14, 5, 6, 8, 6, 5, 12,
+		9, 15, 5, 11, 6, 8, 13, 12, 5, 12, 13, 14, 11, 8, 5, 6,
+	}
+	ripemdRightRotations = [80]uint8{
+		8, 9, 9, 11, 13, 15, 15, 5, 7, 7, 8, 11, 14, 14, 12, 6,
+		9, 13, 15, 7, 12, 8, 9, 11, 7, 7, 12, 7, 6, 15, 13, 11,
+		9, 7, 15, 11, 8, 6, 6, 14, 12, 13, 5, 14, 13, 13, 7, 5,
+		15, 5, 8, 11, 14, 14, 6, 14, 6, 9, 12, 9, 12, 5, 15, 8,
+		8, 5, 12, 9, 12, 5, 14, 6, 8, 13, 6, 5, 15, 13, 11, 11,
+	}
+	ripemdLeftConstants  = [5]uint32{0x00000000, 0x5A827999, 0x6ED9EBA1, 0x8F1BBCDC, 0xA953FD4E}
+	ripemdRightConstants = [5]uint32{0x50A28BE6, 0x5C4DD124, 0x6D703EF3, 0x7A6D76E9, 0x00000000}
+)
+
+// ripemd160 is RIPEMD-160, which the standard library lacks
+func ripemd160(data []byte) []byte {
+	h := [5]uint32{0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0}
+
+	// MD4-style padding with a little-endian bit length
+	padded := append(append([]byte{}, data...), 0x80)
+	for len(padded)%64 != 56 {
+		padded = append(padded, 0)
+	}
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(data))*8)
+	padded = append(padded, length[:]...)
+
+	var x [16]uint32
+	for block := padded; len(block) > 0; block = block[64:] {
+		for i := range x {
+			x[i] = binary.LittleEndian.Uint32(block[4*i:])
+		}
+		al, bl, cl, dl, el := h[0], h[1], h[2], h[3], h[4]
+		ar, br, cr, dr, er := h[0], h[1], h[2], h[3], h[4]
+		for j := 0; j < 80; j++ {
+			round := j / 16
+			t := bits.RotateLeft32(al+ripemdF(round, bl, cl, dl)+x[ripemdLeftWords[j]]+ripemdLeftConstants[round],
+				int(ripemdLeftRotations[j])) + el
+			al, el, dl, cl, bl = el, dl, bits.RotateLeft32(cl, 10), bl, t
+
+			// the right line runs the functions in reverse
+			t = bits.RotateLeft32(ar+ripemdF(4-round, br, cr, dr)+x[ripemdRightWords[j]]+ripemdRightConstants[round],
+				int(ripemdRightRotations[j])) + er
+			ar, er, dr, cr, br = er, dr, bits.RotateLeft32(cr, 10), br, t
+		}
+		h[0], h[1], h[2], h[3], h[4] = h[1]+cl+dr, h[2]+dl+er, h[3]+el+ar, h[4]+al+br, h[0]+bl+cr
+	}
+
+	digest := make([]byte, 20)
+	for i, word := range h {
+		binary.LittleEndian.PutUint32(digest[4*i:], word)
+	}
+	return digest
+}
+
+// ripemdF is the boolean function of a RIPEMD-160 round
+func ripemdF(round int, x, y, z uint32) uint32 {
+	switch round {
+	case 0:
+		return x ^ y ^ z
+	case 1:
+		return x&y | ^x&z
+	case 2:
+		return (x | ^y) ^ z
+	case 3:
+		return x&z | y&^z
+	default:
+		return x ^ (y | ^z)
+	}
+}
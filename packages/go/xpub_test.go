@@ -0,0 +1,111 @@
+package constellation
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveAddressesFromXpub(t *testing.T) {
+	phrase, err := GenerateMnemonic()
+	require.NoError(t, err)
+
+	t.Run("matches the addresses of the mnemonic", func(t *testing.T) {
+		for _, account := range []uint32{0, 3} {
+			xpub, err := ExportAccountXpub(phrase, account)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(xpub, "xpub"), xpub)
+
+			addresses, err := DeriveAddressesFromXpub(xpub, 5, 4)
+			require.NoError(t, err)
+			keyPairs, err := DeriveKeyPairs(phrase, account, 5, 4)
+			require.NoError(t, err)
+			for i, keyPair := range keyPairs {
+				assert.Equal(t, keyPair.Address, addresses[i], "account %d index %d", account, 5+i)
+			}
+		}
+	})
+
+	t.Run("passphrases select a different account key", func(t *testing.T) {
+		plain, err := ExportAccountXpub(phrase, 0)
+		require.NoError(t, err)
+		protected, err := ExportAccountXpubWithPassphrase(phrase, "TREZOR", 0)
+		require.NoError(t, err)
+		assert.NotEqual(t, plain, protected)
+
+		addresses, err := DeriveAddressesFromXpub(protected, 0, 1)
+		require.NoError(t, err)
+		keyPair, err := DeriveKeyPairWithPassphrase(phrase, "TREZOR", 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, keyPair.Address, addresses[0])
+	})
+
+	t.Run("rejects bad input", func(t *testing.T) {
+		xpub, err := ExportAccountXpub(phrase, 0)
+		require.NoError(t, err)
+
+		_, err = DeriveAddressesFromXpub(xpub, 0, 0)
+		assert.ErrorIs(t, err, ErrInvalidDerivationPath)
+		_, err = DeriveAddressesFromXpub(xpub, hardenedKeyOffset-1, 2)
+		assert.ErrorIs(t, err, ErrInvalidDerivationPath)
+		_, err = ExportAccountXpub(phrase, hardenedKeyOffset)
+		assert.ErrorIs(t, err, ErrInvalidDerivationPath)
+
+		// a typo breaks the checksum
+		typo := []byte(xpub)
+		typo[40] = map[bool]byte{true: 'b', false: 'a'}[typo[40] == 'a']
+		for name, bad := range map[string]string{
+			"typo":       string(typo),
+			"truncated":  xpub[:len(xpub)-1],
+			"not base58": "xpub0OIl",
+			// BIP32 test vector 1, m/0H: a depth 1 key is not an account
+			"wrong depth": "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+			// BIP32 test vector 1, m
+			"private": "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+		} {
+			_, err := DeriveAddressesFromXpub(bad, 0, 1)
+			assert.ErrorIs(t, err, ErrInvalidXpub, name)
+		}
+	})
+}
+
+func TestHDPublicKey(t *testing.T) {
+	// BIP32 test vector 1
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+
+	key, err := deriveHDPublicKey(seed, 0+hardenedKeyOffset)
+	require.NoError(t, err)
+	assert.Equal(t, "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", key.String())
+
+	parsed, err := parseHDPublicKey(key.String())
+	require.NoError(t, err)
+	assert.Equal(t, key.String(), parsed.String())
+
+	// m/0H/1 derived from the public key alone
+	child, err := parsed.child(1)
+	require.NoError(t, err)
+	assert.Equal(t, "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", child.String())
+
+	_, err = child.child(hardenedKeyOffset)
+	assert.ErrorIs(t, err, ErrInvalidDerivationPath)
+}
+
+func TestRIPEMD160(t *testing.T) {
+	// from the RIPEMD-160 specification
+	for input, expected := range map[string]string{
+		"":                           "9c1185a5c5e9fc54612808977ee8f548b2258d31",
+		"abc":                        "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc",
+		"message digest":             "5d0689ef49d2fae572b881b123a85ffa21595f36",
+		"abcdefghijklmnopqrstuvwxyz": "f71c27109c692c1b56bbdceb5b9d2865b3708dbc",
+		"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq":                         "12a053384a9c0c88e405a06c27dcf49ada62eb2b",
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789":                   "b0e20b6e3116640286ed3a87a5713079b21f5189",
+		"12345678901234567890123456789012345678901234567890123456789012345678901234567890": "9b752e45573d4b39f4dbd3323cab82bf63326bfb",
+		strings.Repeat("a", 1000000):                                                       "52783243c1697bdbe16d37f97f68f08325dc1528",
+	} {
+		assert.Equal(t, expected, hex.EncodeToString(ripemd160([]byte(input))), "%.20q", input)
+	}
+}